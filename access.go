@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+
+	"anyhowhodl/internal/db"
+)
+
+// accessStore returns the store to use for the connected credentials. Viewer
+// credentials (see schema_roles.sql), or ACCESS_ROLE=viewer with any
+// credentials, get a store whose mutating methods all fail with db.ErrReadOnly.
+func accessStore(database *db.DB) (db.Store, db.Role) {
+	role, err := database.CurrentRole(context.Background())
+	if err != nil {
+		slog.Warn("checking database privileges, assuming owner", "err", err)
+	}
+	if strings.EqualFold(os.Getenv("ACCESS_ROLE"), string(db.RoleViewer)) {
+		role = db.RoleViewer
+	}
+	if role == db.RoleViewer {
+		slog.Info("read-only session", "role", role)
+		return db.ReadOnly(database), role
+	}
+	return database, role
+}
+
+// readOnly reports whether this session uses viewer credentials. When true it
+// sets a status bar message, so key handlers can simply return.
+func (a *App) readOnly() bool {
+	if a.role != db.RoleViewer {
+		return false
+	}
+	a.statusBar.SetText(" [yellow]Read-only: viewer access cannot modify data")
+	return true
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/query"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// accountBook is another account's holdings and cash, loaded for the
+// summary's per-account totals
+type accountBook struct {
+	ID       string
+	Name     string
+	Holdings []db.Holding
+	Cash     decimal.Decimal
+}
+
+// loadAccounts reads the accounts and, when there are any besides the main
+// one, the holdings and cash of those not selected. It returns their tickers
+// so quotes are fetched for them too. Without accounts (or the accounts
+// table) there is nothing to load.
+func (a *App) loadAccounts(ctx context.Context) []string {
+	accounts, err := a.db.GetAccounts(ctx)
+	if err != nil {
+		slog.Debug("loading accounts", "err", err)
+	}
+	a.book.accounts = accounts
+	a.book.accountBooks = nil
+	if len(accounts) == 0 {
+		return nil
+	}
+
+	var tickers []string
+	ids := append([]string{""}, accountIDs(accounts)...)
+	for _, id := range ids {
+		if id == a.book.account {
+			continue
+		}
+		store := a.db.ForAccount(id)
+		holdings, err := store.GetHoldings(ctx)
+		if err != nil {
+			slog.Warn("loading account holdings", "account", a.book.accountName(id), "err", err)
+			continue
+		}
+		cash, _ := store.GetAvailableCash(ctx)
+		a.book.accountBooks = append(a.book.accountBooks, accountBook{ID: id, Name: a.book.accountName(id), Holdings: holdings, Cash: cash})
+		for _, h := range holdings {
+			tickers = append(tickers, h.Ticker)
+		}
+	}
+	return tickers
+}
+
+// forEachAccount runs job on the store of the main account and of every
+// other account, for work that must not wait for an account to be opened.
+// Without accounts, or the accounts table, that is only the main one. Errors
+// are joined, each prefixed with its account's name when there are several.
+func forEachAccount(ctx context.Context, store db.Store, job func(name string, store db.Store) error) error {
+	accounts, err := store.GetAccounts(ctx)
+	if err != nil {
+		slog.Debug("loading accounts", "err", err)
+	}
+	var errs []error
+	run := func(id, name string) {
+		err := job(name, store.ForAccount(id))
+		if err != nil && len(accounts) > 0 {
+			err = fmt.Errorf("%s: %w", name, err)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	run("", db.MainAccountName)
+	for _, acct := range accounts {
+		run(acct.ID, acct.Name)
+	}
+	return errors.Join(errs...)
+}
+
+func accountIDs(accounts []db.Account) []string {
+	ids := make([]string, len(accounts))
+	for i, acct := range accounts {
+		ids[i] = acct.ID
+	}
+	return ids
+}
+
+// accountName names the account with id, "" being the main account
+func (b *book) accountName(id string) string {
+	for _, acct := range b.accounts {
+		if acct.ID == id {
+			return acct.Name
+		}
+	}
+	return db.MainAccountName
+}
+
+// totalCash is the cash across all accounts
+func (a *App) totalCash() decimal.Decimal {
+	total := a.book.cash
+	for _, book := range a.book.accountBooks {
+		total = total.Add(book.Cash)
+	}
+	return total
+}
+
+// bookValue values another account's holdings at their quotes, or at cost
+// without one, plus its cash
+func (b *book) bookValue(book accountBook) decimal.Decimal {
+	return b.bookHoldingsValue(book).Add(book.Cash)
+}
+
+func (b *book) bookHoldingsValue(book accountBook) decimal.Decimal {
+	value := decimal.Zero
+	for _, h := range book.Holdings {
+		if q, ok := b.quotes[h.Ticker]; ok {
+			value = value.Add(b.toBase(h.Quantity.Mul(decimal.NewFromFloat(q.Price)), h.Currency))
+		} else {
+			value = value.Add(b.toBase(h.Quantity.Mul(h.AvgCost), h.Currency))
+		}
+	}
+	return value
+}
+
+// totalHoldingsValue is the holdings value across all accounts, so
+// performance snapshots do not depend on the account selected
+func (a *App) totalHoldingsValue() decimal.Decimal {
+	total := a.holdingsView.value
+	for _, book := range a.book.accountBooks {
+		total = total.Add(a.book.bookHoldingsValue(book))
+	}
+	return total
+}
+
+// allHoldings is the holdings of every account, the selected one's first
+func (b *book) allHoldings() []db.Holding {
+	holdings := b.holdings
+	for _, book := range b.accountBooks {
+		holdings = append(slices.Clip(holdings), book.Holdings...)
+	}
+	return holdings
+}
+
+// acrossAccounts labels a figure taken across all accounts, when there are
+// more than the main one
+func (b *book) acrossAccounts() string {
+	if len(b.accounts) == 0 {
+		return ""
+	}
+	return ", all accounts"
+}
+
+// accountsLine is the summary line with each account's total, the selected
+// one in yellow, and the combined total. current is the selected account's
+// total as the first summary line shows it.
+func (b *book) accountsLine(current decimal.Decimal) string {
+	values := map[string]decimal.Decimal{b.account: current}
+	for _, book := range b.accountBooks {
+		values[book.ID] = b.bookValue(book)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(" [teal]Accounts:[white]")
+	combined := decimal.Zero
+	for _, id := range append([]string{""}, accountIDs(b.accounts)...) {
+		value := values[id]
+		combined = combined.Add(value)
+		color := "white"
+		if id == b.account {
+			color = "yellow"
+		}
+		fmt.Fprintf(&sb, " [%s]%s %s%s[white]  |", color, tview.Escape(b.accountName(id)), b.baseSymbol(), b.locale.FormatFixed(value, 2))
+	}
+	fmt.Fprintf(&sb, " All: [yellow]%s%s[white]  [gray](B to switch)[white]", b.baseSymbol(), b.locale.FormatFixed(combined, 2))
+	return sb.String()
+}
+
+// switchAccount scopes holdings, options, and cash to the account with id
+// and reloads
+func (a *App) switchAccount(id string) {
+	a.setAccount(id)
+	a.refreshData()
+	a.statusBar.SetText(fmt.Sprintf(" [green]Account: %s", a.book.accountName(id)))
+}
+
+// setAccount points the store and query service at the account with id
+func (a *App) setAccount(id string) {
+	a.book.account = id
+	a.db = a.db.ForAccount(id)
+	a.query = query.New(a.db, a.yahoo)
+}
+
+// showAccountsView lists the accounts: Enter switches to one, and the last
+// entry adds a new account
+func (a *App) showAccountsView() {
+	ctx := context.Background()
+	accounts, err := a.db.GetAccounts(ctx)
+	if err != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Accounts unavailable (run the accounts migration in schema.sql): %v", err))
+		return
+	}
+	a.book.accounts = accounts
+
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(tcell.ColorDarkSlateGray)
+	ids := append([]string{""}, accountIDs(accounts)...)
+	for i, id := range ids {
+		label := tview.Escape(a.book.accountName(id))
+		if id == a.book.account {
+			label += " [gray](current)"
+			list.SetCurrentItem(i)
+		}
+		list.AddItem(label, "", 0, func() {
+			a.pages.RemovePage("accounts")
+			if id != a.book.account {
+				a.switchAccount(id)
+			}
+		})
+	}
+	list.AddItem("[teal]+ Add account", "", 0, func() {
+		if a.readOnly() {
+			return
+		}
+		a.pages.RemovePage("accounts")
+		a.showAddAccountForm()
+	})
+	list.SetBorder(true).
+		SetTitle(" Accounts ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	a.createModalPage("accounts", list, 40, len(ids)+3)
+}
+
+// showAddAccountForm adds an account and switches to it
+func (a *App) showAddAccountForm() {
+	form := tview.NewForm().
+		AddInputField("Name", "", 30, nil, nil)
+	checks := newFormChecks(form)
+	checks.add(form, 0, requiredCheck(nil), nil)
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		if !checks.valid() {
+			a.statusBar.SetText(" [red]Fix the fields marked in red")
+			return
+		}
+		name := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		if strings.EqualFold(name, db.MainAccountName) {
+			a.statusBar.SetText(fmt.Sprintf(" [red]%s is the name of the main account", db.MainAccountName))
+			return
+		}
+		ctx := context.Background()
+		if err := a.db.AddAccount(ctx, name); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+		a.pages.RemovePage("addaccount")
+		accounts, _ := a.db.GetAccounts(ctx)
+		a.book.accounts = accounts
+		for _, acct := range accounts {
+			if acct.Name == name {
+				a.switchAccount(acct.ID)
+			}
+		}
+	})
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("addaccount")
+	})
+	form.SetBorder(true).SetTitle(" Add Account ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("addaccount", form, 50, 10)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/query"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Rows blink alertFlashes times, alertFlashInterval on and off, when their
+// alert fires
+const (
+	alertFlashes       = 4
+	alertFlashInterval = 400 * time.Millisecond
+)
+
+// loadAlertBell reads whether new alerts ring the terminal bell, off if it
+// cannot be read
+func loadAlertBell(ctx context.Context, store db.Store) bool {
+	on, err := store.GetAlertBell(ctx)
+	if err != nil {
+		slog.Warn("loading alert bell setting", "err", err)
+	}
+	return on
+}
+
+// checkAlerts evaluates the alerts for the loaded portfolio. Rows with an
+// alert that was not firing before blink, and the alert is sent to the
+// notification channels its kind is routed to; alerts already firing when
+// the TUI opened blink and are logged without notifying.
+func (a *App) checkAlerts() {
+	first := a.seenAlerts == nil
+	if first {
+		a.seenAlerts = make(map[string]bool)
+	}
+	a.alerts = query.EvaluateAlerts(a.book.holdings, a.book.options, a.reminders, a.book.quotes, a.now())
+
+	var fired []string
+	var logged []query.Alert
+	for _, alert := range a.alerts {
+		if a.seenAlerts[alert.Key] {
+			continue
+		}
+		a.seenAlerts[alert.Key] = true
+		fired = append(fired, alert.ID)
+		logged = append(logged, alert)
+		if first {
+			slog.Info("alert", "message", alert.Message)
+		} else {
+			a.notifyAlert(alert)
+		}
+	}
+	if len(fired) == 0 {
+		return
+	}
+	if a.role != db.RoleViewer {
+		// Kept for the weekly review; already logged ones keep when they first fired
+		if err := a.query.LogAlerts(context.Background(), logged, a.now()); err != nil {
+			slog.Debug("logging alerts", "err", err)
+		}
+	}
+	a.flashRows(fired)
+}
+
+// flashRows blinks the rows of ids. Rows added while others are blinking
+// restart the blinking for all of them.
+func (a *App) flashRows(ids []string) {
+	if a.flashing == nil {
+		a.flashing = make(map[string]bool)
+	}
+	for _, id := range ids {
+		a.flashing[id] = true
+	}
+	running := a.flashLeft > 0
+	a.flashLeft = alertFlashes * 2
+	a.holdingsView.update()
+	a.optionsView.update()
+	if running || a.app == nil {
+		return
+	}
+
+	a.goSafe("alert flash", func() {
+		for done := false; !done && !a.stopping.Load(); {
+			time.Sleep(alertFlashInterval)
+			a.queueUpdateDraw(func() {
+				a.flashLeft--
+				if a.flashLeft <= 0 {
+					clear(a.flashing)
+					done = true
+				}
+				a.holdingsView.update()
+				a.optionsView.update()
+			})
+		}
+	})
+}
+
+// rowBackground is the background of the row for id, lit while its alert
+// blinks
+func (a *App) rowBackground(id string) tcell.Color {
+	if a.flashing[id] && a.flashLeft%2 == 0 && a.flashLeft > 0 {
+		return tcell.ColorMaroon
+	}
+	return tcell.ColorBlack
+}
+
+// ringBell rings the terminal bell before a draw if an alert asked for it
+func (a *App) ringBell(screen tcell.Screen) bool {
+	if a.bellPending {
+		a.bellPending = false
+		screen.Beep()
+	}
+	return false
+}
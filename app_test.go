@@ -0,0 +1,3365 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/csp"
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/fake"
+	"anyhowhodl/internal/importer"
+	"anyhowhodl/internal/locale"
+	"anyhowhodl/internal/notify"
+	"anyhowhodl/internal/query"
+	"anyhowhodl/internal/webhook"
+	"anyhowhodl/internal/yahoo"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// newTestApp returns an App backed by in-memory fakes, without any widgets
+func newTestApp(store *fake.Store, market *fake.Market) *App {
+	return newApp(store, market)
+}
+
+func TestProcessExpiredOptions(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+	a := newTestApp(store, market)
+
+	lastWeek := time.Now().AddDate(0, 0, -7)
+	store.SetAvailableCash(ctx, decimal.NewFromInt(50000))
+	store.AddOption(ctx, "AAPL", "PUT", "SELL", decimal.NewFromInt(200), lastWeek, 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, decimal.NewFromInt(2), decimal.Zero, "")
+	store.AddOption(ctx, "MSFT", "CALL", "SELL", decimal.NewFromInt(400), lastWeek, 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, decimal.NewFromInt(3), decimal.Zero, "")
+	store.AddOption(ctx, "NOQUOTE", "PUT", "SELL", decimal.NewFromInt(10), lastWeek, 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, decimal.NewFromInt(1), decimal.Zero, "")
+	market.SetPrice("AAPL", 190) // ITM put: assigned
+	market.SetPrice("MSFT", 390) // OTM call: expires
+
+	a.processExpiredOptions(ctx)
+
+	options, _ := store.GetActiveOptions(ctx)
+	status := make(map[string]string)
+	for _, o := range options {
+		status[o.Ticker] = o.Status
+	}
+	if status["AAPL"] != "ASSIGNED" || status["MSFT"] != "EXPIRED" || status["NOQUOTE"] != "ACTIVE" {
+		t.Errorf("statuses = %v", status)
+	}
+
+	h, _ := store.GetHoldingByTicker(ctx, "AAPL")
+	if h == nil || !h.Quantity.Equal(decimal.NewFromInt(100)) || !h.AvgCost.Equal(decimal.NewFromInt(200)) {
+		t.Fatalf("AAPL holding after assignment = %+v", h)
+	}
+
+	// 50000 + premiums (200 + 300 + 100) - 20000 assignment
+	cash, _ := store.GetAvailableCash(ctx)
+	if !cash.Equal(decimal.NewFromInt(30600)) {
+		t.Errorf("cash = %s, want 30600", cash)
+	}
+}
+
+func TestProcessExpiredOptionsAllAccounts(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+	a := newTestApp(store, market)
+	store.AddAccount(ctx, "IRA")
+	accounts, _ := store.GetAccounts(ctx)
+	ira := store.ForAccount(accounts[0].ID)
+
+	lastWeek := time.Now().AddDate(0, 0, -7)
+	ira.SetAvailableCash(ctx, decimal.NewFromInt(50000))
+	ira.AddOption(ctx, "AAPL", "PUT", "SELL", decimal.NewFromInt(200), lastWeek, 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, decimal.NewFromInt(2), decimal.Zero, "")
+	market.SetPrice("AAPL", 190)
+
+	// The main account is selected; the IRA's put is assigned all the same
+	a.processExpiredOptions(ctx)
+	options, _ := ira.GetActiveOptions(ctx)
+	if len(options) != 1 || options[0].Status != "ASSIGNED" {
+		t.Fatalf("IRA options = %+v", options)
+	}
+	if h, _ := ira.GetHoldingByTicker(ctx, "AAPL"); h == nil || !h.Quantity.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("IRA AAPL holding = %+v", h)
+	}
+}
+
+func TestAssignmentFee(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+	a := newTestApp(store, market)
+
+	lastWeek := time.Now().AddDate(0, 0, -7)
+	store.SetAvailableCash(ctx, decimal.NewFromInt(50000))
+	store.SetAssignmentFee(ctx, db.AssignmentFee{PerAssignment: decimal.NewFromInt(15), PerContract: decimal.RequireFromString("0.65")})
+	store.AddOption(ctx, "AAPL", "PUT", "SELL", decimal.NewFromInt(200), lastWeek, 2, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, decimal.NewFromInt(2), decimal.Zero, "")
+	market.SetPrice("AAPL", 190)
+
+	a.processExpiredOptions(ctx)
+
+	options, _ := store.GetActiveOptions(ctx)
+	if len(options) != 1 || options[0].Status != "ASSIGNED" {
+		t.Fatalf("options = %+v", options)
+	}
+	if fee := options[0].CloseFee; !fee.Valid || !fee.Decimal.Equal(decimal.RequireFromString("16.3")) {
+		t.Errorf("close fee = %v, want 16.3", fee)
+	}
+
+	// 50000 + 400 premium - 40000 assignment - 16.30 fee
+	cash, _ := store.GetAvailableCash(ctx)
+	if !cash.Equal(decimal.RequireFromString("10383.7")) {
+		t.Errorf("cash = %s, want 10383.7", cash)
+	}
+
+	premiums, _ := store.GetPremiumsBetween(ctx, lastWeek.AddDate(0, 0, -30), time.Now().AddDate(0, 0, 1))
+	if !premiums.TotalFees.Equal(decimal.RequireFromString("16.3")) {
+		t.Errorf("premium fees = %s, want 16.3", premiums.TotalFees)
+	}
+}
+
+func TestSessionRoundTrip(t *testing.T) {
+	a := newRenderApp(t)
+	a.holdingsView.table.Select(2, 0)
+	a.optionsView.table.Select(3, 0)
+	a.setFocusIndex(1)
+	a.optionsView.weeklyView = false
+	a.optionsView.showExpired = false
+	a.toggleBanner()
+	a.saveSession(context.Background())
+
+	b := newTestApp(a.db.(*fake.Store), a.yahoo.(*fake.Market))
+	b.clock = a.clock
+	b.book.quotes = make(map[string]yahoo.Quote)
+	b.optionsView.weeklyView, b.optionsView.showExpired, b.autoRefresh = true, true, true
+	b.app = tview.NewApplication()
+	b.initWidgets()
+	session := b.loadSession(context.Background())
+	if session == nil {
+		t.Fatal("no session saved")
+	}
+	if b.optionsView.weeklyView || b.optionsView.showExpired || b.autoRefresh || !b.hideBanner {
+		t.Errorf("toggles not restored: weekly=%v expired=%v auto=%v banner hidden=%v", b.optionsView.weeklyView, b.optionsView.showExpired, b.autoRefresh, b.hideBanner)
+	}
+	b.refreshData()
+	b.restoreSession(session)
+
+	if row, _ := b.holdingsView.table.GetSelection(); row != 2 {
+		t.Errorf("holdings row = %d, want 2", row)
+	}
+	if row, _ := b.optionsView.table.GetSelection(); row != 3 {
+		t.Errorf("options row = %d, want 3", row)
+	}
+	if b.focusIndex != 1 || b.app.GetFocus() != b.optionsView.table {
+		t.Errorf("focus = %d, want options table", b.focusIndex)
+	}
+}
+
+func TestReloadIfChanged(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	store := a.db.(*fake.Store)
+
+	stamp, _ := store.GetChangeStamp(ctx)
+	if a.reloadIfChanged(stamp) {
+		t.Error("reloaded without any change")
+	}
+
+	// Another session edits cash
+	store.SetAvailableCash(ctx, decimal.NewFromInt(1234))
+	stamp, _ = store.GetChangeStamp(ctx)
+	if !a.reloadIfChanged(stamp) {
+		t.Fatal("did not reload after another session's change")
+	}
+	if !a.book.cash.Equal(decimal.NewFromInt(1234)) {
+		t.Errorf("cash = %s, want 1234", a.book.cash)
+	}
+	if a.reloadIfChanged(stamp) {
+		t.Error("reloaded twice for the same change")
+	}
+}
+
+func TestTaxYearPremiums(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	store := a.db.(*fake.Store)
+	calendarNet := a.book.premiums.NetPL
+
+	// Sold in the UK tax year containing the fixture date but before the
+	// calendar year, and one sold before either
+	for _, sold := range []time.Time{
+		time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		store.Now = func() time.Time { return sold }
+		store.AddOption(ctx, "AAPL", "PUT", "SELL", decimal.NewFromInt(150), sold.AddDate(0, 1, 0), 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, decimal.NewFromInt(2), decimal.Zero, "")
+	}
+
+	a.refreshData()
+	if !a.book.premiums.NetPL.Equal(calendarNet) {
+		t.Errorf("calendar year net = %s, want %s", a.book.premiums.NetPL, calendarNet)
+	}
+
+	a.book.taxYear, _ = analytics.ParseTaxYear("04-06")
+	a.refreshData()
+	if want := calendarNet.Add(decimal.NewFromInt(200)); !a.book.premiums.NetPL.Equal(want) {
+		t.Errorf("UK tax year net = %s, want %s", a.book.premiums.NetPL, want)
+	}
+	if text := a.optionsView.timeline.GetText(true); !strings.Contains(text, "2025/26 Premiums") {
+		t.Errorf("timeline does not name the tax year: %q", text)
+	}
+}
+
+func TestConfirmRiskCaps(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	store := a.db.(*fake.Store)
+
+	// AAPL is already about half the portfolio
+	store.SetRiskCap(ctx, db.CapScopeTicker, "AAPL", decimal.NewFromInt(60))
+	store.SetTickerSector(ctx, "MSFT", "Tech")
+	store.SetTickerSector(ctx, "NVDA", "Tech")
+	store.SetRiskCap(ctx, db.CapScopeSector, "Tech", decimal.NewFromInt(40))
+	a.loadRiskCaps(ctx)
+
+	saved := false
+	a.confirmRiskCaps("AAPL", 1000, func() { saved = true })
+	if !saved || a.pages.HasPage("riskcap") {
+		t.Error("a small AAPL add within its cap was not saved directly")
+	}
+
+	saved = false
+	a.confirmRiskCaps("NVDA", 20000, func() { saved = true })
+	if saved || !a.pages.HasPage("riskcap") {
+		t.Fatal("an NVDA add breaching the Tech cap was saved without confirmation")
+	}
+	a.pages.RemovePage("riskcap")
+
+	book := a.riskBook()
+	if h, ok := book.Headroom(a.capsForAnalytics(), "MSFT"); !ok || h >= 20000 {
+		t.Errorf("MSFT headroom = %v, %v; want the Tech sector cap below 20000", h, ok)
+	}
+	if _, ok := book.Headroom(a.capsForAnalytics(), "TSLA"); ok {
+		t.Error("TSLA has no cap but reports headroom")
+	}
+}
+
+func TestDailyThetaIncome(t *testing.T) {
+	a := newRenderApp(t)
+	// The refresh's own IV fetch found no chains and stays with the old market
+	market := fake.NewMarket()
+	a.yahoo = market
+
+	// Before any IV loads the stats show the positions still to price
+	if _, priced, shorts := a.book.dailyThetaIncome(a.now()); priced != 0 || shorts != 4 {
+		t.Fatalf("priced %d of %d shorts, want 0 of 4", priced, shorts)
+	}
+
+	market.Chains["AAPL"] = &csp.OptionsData{Calls: []csp.OptionContract{{Strike: 230, ImpliedVolatility: 0.28}}}
+	market.Chains["MSFT"] = &csp.OptionsData{Puts: []csp.OptionContract{{Strike: 380, ImpliedVolatility: 0.25}}}
+	reqs := a.ivRequests()
+	if len(reqs) != 4 {
+		t.Fatalf("%d chain requests, want one per open short", len(reqs))
+	}
+	a.storeOptionIVs(fetchOptionIVs(market, reqs))
+
+	income, priced, shorts := a.book.dailyThetaIncome(a.now())
+	if priced != 2 || shorts != 4 {
+		t.Errorf("priced %d of %d shorts, want 2 of 4", priced, shorts)
+	}
+	if income <= 0 {
+		t.Errorf("short options decay income = %v, want positive", income)
+	}
+	if got := len(a.ivRequests()); got != 2 {
+		t.Errorf("%d chain requests after loading, want 2 still missing", got)
+	}
+
+	a.optionsView.updateTimeline()
+	if text := a.optionsView.timeline.GetText(true); !strings.Contains(text, "/day (2/4)") {
+		t.Errorf("premium stats %q lack the theta income", text)
+	}
+}
+
+func TestOptionOpenInterest(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	market := fake.NewMarket()
+	market.Chains["MSFT"] = &csp.OptionsData{Puts: []csp.OptionContract{{Strike: 380, ImpliedVolatility: 0.25, OpenInterest: 500}}}
+	expiry := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+	a.db.SaveOpenInterest(ctx, []db.OpenInterest{
+		{Ticker: "MSFT", OptionType: "PUT", Expiry: expiry, Strike: 380, Day: a.today().AddDate(0, 0, -3), OpenInterest: 400},
+	})
+
+	ivs := fetchOptionIVs(market, a.ivRequests())
+	oi, err := trackShortOpenInterest(a.query, a.shortContracts(), ivs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.storeOptionOI(oi)
+	a.optionsView.update()
+
+	i := activeOptionIndex(t, a, "MSFT")
+	if got := strings.TrimSpace(a.optionsView.table.GetCell(i+1, 9).Text); got != "500 (+100)" {
+		t.Errorf("MSFT put OI = %q, want 500 (+100)", got)
+	}
+	if got := strings.TrimSpace(a.optionsView.table.GetCell(activeOptionIndex(t, a, "AAPL")+1, 9).Text); got != "-" {
+		t.Errorf("AAPL call OI = %q, want - without a chain", got)
+	}
+	snapshots, _ := a.db.GetOpenInterest(ctx, a.today())
+	if len(snapshots) != 1 || snapshots[0].OpenInterest != 500 {
+		t.Errorf("today's snapshots = %+v, want the MSFT put at 500", snapshots)
+	}
+}
+
+func TestManualPrices(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	store := a.db.(*fake.Store)
+	market := a.yahoo.(*fake.Market)
+
+	store.AddHolding(ctx, "GONE", decimal.NewFromInt(100), decimal.NewFromInt(20), db.DefaultCurrency, renderFixture, decimal.NullDecimal{}, "")
+	a.refreshData()
+	if text := a.summaryView.text.GetText(true); !strings.Contains(text, "1 at cost") {
+		t.Errorf("summary %q does not flag the unpriced holding", text)
+	}
+
+	store.SetManualPrice(ctx, "GONE", decimal.NewFromInt(5))
+	a.refreshData()
+	if q := a.book.quotes["GONE"]; q.Price != 5 {
+		t.Errorf("GONE quote = %v, want the manual 5", q.Price)
+	}
+	if text := a.summaryView.text.GetText(true); !strings.Contains(text, "1 at manual price") || strings.Contains(text, "at cost") {
+		t.Errorf("summary %q does not reflect the manual price", text)
+	}
+	for i, h := range a.book.holdings {
+		if cell := a.holdingsView.table.GetCell(i+1, 4).Text; h.Ticker == "GONE" && !strings.Contains(cell, "manual 0d") {
+			t.Errorf("price cell %q lacks the manual marker", cell)
+		}
+	}
+
+	// Once Yahoo quotes it again the market price wins
+	market.Quotes["GONE"] = yahoo.Quote{Symbol: "GONE", Price: 7}
+	a.refreshData()
+	if _, ok := a.book.manualPrices["GONE"]; ok || a.book.quotes["GONE"].Price != 7 {
+		t.Errorf("GONE = %v, manual %v; want Yahoo's 7", a.book.quotes["GONE"].Price, a.book.manualPrices)
+	}
+
+	// Clearing the price drops it even if Yahoo goes quiet again; the last
+	// Yahoo price stands in instead
+	delete(market.Quotes, "GONE")
+	store.DeleteManualPrice(ctx, "GONE")
+	a.refreshData()
+	if q := a.book.quotes["GONE"]; q.Price != 7 {
+		t.Errorf("GONE = %v after clearing its manual price, want the last-known 7", q.Price)
+	}
+}
+
+func TestStaleSymbols(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	store := a.db.(*fake.Store)
+	market := a.yahoo.(*fake.Market)
+
+	nvdaPrice := func() string {
+		for i, h := range a.book.holdings {
+			if h.Ticker == "NVDA" {
+				return a.holdingsView.table.GetCell(i+1, 4).Text
+			}
+		}
+		t.Fatal("NVDA holding missing")
+		return ""
+	}
+
+	// NVDA stops quoting: it keeps its last price, marked stale
+	delete(market.Quotes, "NVDA")
+	a.refreshData()
+	if cell := nvdaPrice(); !strings.Contains(cell, "128.40 stale") {
+		t.Errorf("NVDA price cell = %q, want the last-known price marked stale", cell)
+	}
+
+	// After repeated failures it is no longer requested
+	for range maxQuoteFailures - 1 {
+		a.refreshData()
+	}
+	if !a.book.isHalted("NVDA") {
+		t.Fatalf("NVDA failures = %d, want halted", a.book.staleSymbols["NVDA"].Failures)
+	}
+	a.refreshData()
+	if a.book.staleSymbols["NVDA"].Failures != maxQuoteFailures {
+		t.Errorf("a halted symbol was fetched again (failures %d)", a.book.staleSymbols["NVDA"].Failures)
+	}
+	if text := a.summaryView.text.GetText(true); !strings.Contains(text, "1 halted") {
+		t.Errorf("summary %q does not flag the halted symbol", text)
+	}
+
+	// An outage where nothing quotes is not counted against anyone
+	market.Err = errors.New("offline")
+	a.refreshData()
+	market.Err = nil
+	if a.book.staleSymbols["AAPL"].Failures != 0 {
+		t.Error("an outage counted as AAPL failing")
+	}
+
+	// A manual retry brings it back once Yahoo has data again
+	market.SetPrice("NVDA", 130)
+	a.retryStaleSymbols()
+	if _, ok := a.book.staleSymbols["NVDA"]; ok || a.book.quotes["NVDA"].Price != 130 {
+		t.Errorf("NVDA after retry = %v, stale %v", a.book.quotes["NVDA"].Price, a.book.staleSymbols["NVDA"])
+	}
+	if s, _ := store.GetStaleSymbols(ctx); len(s) != 0 {
+		t.Errorf("stale symbols after retry = %v, want none", s)
+	}
+}
+
+// modalForm returns the form shown by createModalPage under name
+func modalForm(t *testing.T, a *App, name string) *tview.Form {
+	t.Helper()
+	page, ok := a.pages.GetPage(name).(*tview.Flex)
+	if !ok {
+		t.Fatalf("page %q not shown", name)
+	}
+	return page.GetItem(1).(*tview.Flex).GetItem(1).(*tview.Form)
+}
+
+// pressButton activates a form button as the Enter key would
+func pressButton(form *tview.Form, label string) {
+	i := form.GetButtonIndex(label)
+	form.GetButton(i).InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+}
+
+func TestConfirmPolicyRoll(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	store := a.db.(*fake.Store)
+
+	msft := a.book.options[activeOptionIndex(t, a, "MSFT")]
+	store.AddOptionPolicy(ctx, msft.ID, analytics.PolicyRollAtDTE, decimal.NewFromInt(21), false)
+	policies, _ := store.GetOptionPolicies(ctx)
+	april := time.Date(2026, 4, 17, 0, 0, 0, 0, time.UTC)
+	store.QueuePolicyAction(ctx, db.PolicyAction{
+		PolicyID: policies[0].ID, OptionID: msft.ID, Action: db.PolicyActionRoll,
+		Reason: "18 DTE (roll at 21)", Price: decimal.NewNullDecimal(decimal.RequireFromString("0.80")), RollExpiry: april,
+	})
+	a.refreshData()
+	if text := a.statusBar.GetText(true); !strings.Contains(text, "1 queued action") {
+		t.Errorf("status bar %q does not mention the queued action", text)
+	}
+
+	a.showPolicyActionsView()
+	a.confirmPolicyAction(a.policyActions[0])
+	closeForm := modalForm(t, a, "closeoption")
+	if got := closeForm.GetFormItem(0).(*tview.InputField).GetText(); got != "0.80" {
+		t.Errorf("close premium prefilled %q, want the 0.80 mark", got)
+	}
+	pressButton(closeForm, "Close Position")
+
+	if len(a.policyActions) != 0 {
+		t.Errorf("%d actions still pending after confirming", len(a.policyActions))
+	}
+	addForm := modalForm(t, a, "addoption")
+	if ticker, expiry := addForm.GetFormItem(0).(*tview.InputField).GetText(), addForm.GetFormItem(4).(*tview.InputField).GetText(); ticker != "MSFT" || expiry != "2026-04-17" {
+		t.Errorf("roll form prefilled %s %s, want MSFT 2026-04-17", ticker, expiry)
+	}
+}
+
+// activeOptionIndex finds the active option on ticker
+func activeOptionIndex(t *testing.T, a *App, ticker string) int {
+	t.Helper()
+	for i, o := range a.book.options {
+		if o.Ticker == ticker && o.Status == "ACTIVE" {
+			return i
+		}
+	}
+	t.Fatalf("no active %s option", ticker)
+	return -1
+}
+
+func TestEarningsColumns(t *testing.T) {
+	a := newRenderApp(t)
+	market := a.yahoo.(*fake.Market)
+	market.Earnings["AAPL"] = &yahoo.EarningsDate{Date: time.Date(2026, 3, 5, 21, 0, 0, 0, time.UTC)}
+	market.Earnings["MSFT"] = &yahoo.EarningsDate{Date: time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC), Estimate: true}
+	a.earnings = a.fetchEarningsDates([]string{"AAPL", "MSFT", "NVDA"}, renderFixture)
+	a.holdingsView.update()
+	a.optionsView.update()
+
+	cell := func(table *tview.Table, row, col int) (string, tcell.Color) {
+		c := table.GetCell(row, col)
+		fg, _, _ := c.Style.Decompose()
+		return strings.TrimSpace(c.Text), fg
+	}
+	if got := a.holdingsView.table.GetCell(0, 14).Text; got != " EARNINGS " {
+		t.Fatalf("holdings header = %q", got)
+	}
+	// AAPL reports in 3 days, MSFT's estimate is 22 days out, NVDA has none
+	for i, want := range []struct {
+		text  string
+		color tcell.Color
+	}{{"Mar 05 (3d)", tcell.ColorYellow}, {"Mar 24 (22d)*", tcell.ColorWhite}, {"-", tcell.ColorWhite}} {
+		if text, color := cell(a.holdingsView.table, i+1, 14); text != want.text || color != want.color {
+			t.Errorf("%s earnings = %q in %v, want %q in %v", a.book.holdings[i].Ticker, text, color, want.text, want.color)
+		}
+	}
+
+	// The AAPL call expires after the report; the MSFT put before it
+	if text, color := cell(a.optionsView.table, activeOptionIndex(t, a, "AAPL")+1, 11); text != "Mar 05 (3d) !" || color != tcell.ColorRed {
+		t.Errorf("AAPL call earnings = %q in %v, want flagged red", text, color)
+	}
+	if text, color := cell(a.optionsView.table, activeOptionIndex(t, a, "MSFT")+1, 11); text != "Mar 24 (22d)*" || color != tcell.ColorWhite {
+		t.Errorf("MSFT put earnings = %q in %v, want unflagged", text, color)
+	}
+}
+
+func TestMarginReport(t *testing.T) {
+	a := newRenderApp(t)
+
+	book, assumed := a.marginBook()
+	if assumed == 0 {
+		t.Error("no options valued at the assumed IV before any chain is fetched")
+	}
+	var regT, pm float64
+	tickers := make(map[string]bool)
+	for _, r := range book.Requirements() {
+		tickers[r.Ticker] = true
+		regT += r.RegT
+		pm += r.Portfolio
+	}
+	for _, ticker := range []string{"AAPL", "MSFT", "NVDA", "TSLA"} {
+		if !tickers[ticker] {
+			t.Errorf("no requirement for %s", ticker)
+		}
+	}
+	if pm <= 0 || pm >= regT {
+		t.Errorf("portfolio margin %v should be positive and below Reg-T %v", pm, regT)
+	}
+
+	report := a.buildMarginReport()
+	for _, want := range []string{"Reg-T", "Portfolio margin", "dominant", "assumed 30% IV"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestPayoffReport(t *testing.T) {
+	a := newRenderApp(t)
+
+	// The short AAPL call starts combined with the shares it covers
+	a.showPayoffView(activeOptionIndex(t, a, "AAPL"))
+	state := &payoffState{option: a.book.options[activeOptionIndex(t, a, "AAPL")], shares: true}
+	p := a.payoffFor(state)
+	if p.Shares != 200 || len(p.Legs) != 1 {
+		t.Fatalf("covered call payoff = %+v", p)
+	}
+	report := a.buildPayoffReport(state)
+	for _, want := range []string{"SELL 2 CALL $230.00", "200 shares", "[yellow]X", "Breakeven:[white] $148.40"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+
+	// A naked short put's loss is bounded by the strike
+	state = &payoffState{option: a.book.options[activeOptionIndex(t, a, "MSFT")]}
+	if report := a.buildPayoffReport(state); !strings.Contains(report, "Max loss:[white] [red]-$37,360.00") {
+		t.Errorf("short put report:\n%s", report)
+	}
+}
+
+func TestIVSurfaceHeatmap(t *testing.T) {
+	a := newRenderApp(t)
+	market := a.yahoo.(*fake.Market)
+	expiry := a.now().AddDate(0, 0, 30).Unix()
+	market.Chains["AAPL"] = &csp.OptionsData{
+		UnderlyingPrice: 240,
+		ExpirationDates: []int64{expiry},
+		Puts: []csp.OptionContract{
+			{Strike: 220, Bid: 2, Ask: 2.2, ImpliedVolatility: 0.28},
+			{Strike: 240, Bid: 7, Ask: 7.4, ImpliedVolatility: 0.31},
+		},
+	}
+
+	surface, err := a.query.IVSurface("AAPL")
+	if err != nil {
+		t.Fatalf("IVSurface: %v", err)
+	}
+	header, table := tview.NewTextView(), tview.NewTable()
+	state := &surfaceState{surface: surface}
+	a.fillSurface(header, table, state)
+
+	// Rows are strikes ascending; the 240 put has the highest IV
+	if got := strings.TrimSpace(table.GetCell(2, 1).Text); got != "31.0%" {
+		t.Errorf("240 put cell = %q, want 31.0%%", got)
+	}
+	if _, bg, _ := table.GetCell(2, 1).Style.Decompose(); bg != tcell.ColorRed {
+		t.Error("richest cell not highlighted")
+	}
+	if !strings.Contains(header.GetText(false), "Richest:[white] $240.00 PUT") {
+		t.Errorf("header = %q", header.GetText(false))
+	}
+
+	// By yield the cheaper strike can rank differently, but every quoted cell shows
+	state.yield = true
+	a.fillSurface(header, table, state)
+	if got := table.GetCell(1, 1).Text; !strings.HasSuffix(strings.TrimSpace(got), "%") {
+		t.Errorf("220 put yield cell = %q", got)
+	}
+
+	// No calls are listed
+	state.calls = true
+	a.fillSurface(header, table, state)
+	if !strings.Contains(header.GetText(false), "No quoted contracts") {
+		t.Errorf("calls header = %q", header.GetText(false))
+	}
+}
+
+func TestCustomColumns(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	a.db.SetCustomColumns(ctx, []db.CustomColumn{
+		{Name: "Upside", Table: db.ColumnTableHoldings, Expr: "(target - price) * qty"},
+		{Name: "Ann %", Table: db.ColumnTableOptions, Expr: "premium*100*qty/(strike*100*qty)/dte*365*100"},
+		{Name: "Broken", Table: db.ColumnTableOptions, Expr: "premium +"},
+	})
+	a.refreshData()
+
+	// The broken column is skipped; the others follow the built-in columns
+	if got := a.holdingsView.table.GetCell(0, 15).Text; got != " UPSIDE " {
+		t.Errorf("holdings header = %q", got)
+	}
+	if got := a.optionsView.table.GetCell(0, 13).Text; got != "" {
+		t.Errorf("broken column shown as %q", got)
+	}
+
+	for i, h := range a.book.holdings {
+		got := strings.TrimSpace(a.holdingsView.table.GetCell(i+1, 15).Text)
+		want := "-" // No target price
+		if h.Ticker == "MSFT" {
+			want = "-117.50" // (450 - 452.35) * 50
+		}
+		if got != want {
+			t.Errorf("%s upside = %q, want %q", h.Ticker, got, want)
+		}
+	}
+
+	i := activeOptionIndex(t, a, "MSFT")
+	// 6.40 on a 380 strike over 18 days
+	if got := strings.TrimSpace(a.optionsView.table.GetCell(i+1, 12).Text); got != "34.15" {
+		t.Errorf("MSFT put Ann %% = %q, want 34.15", got)
+	}
+
+	report := a.buildColumnsReport()
+	if !strings.Contains(report, "Upside") || strings.Contains(report, "Broken") {
+		t.Errorf("columns report:\n%s", report)
+	}
+}
+
+func TestHoldingsColumnLayout(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	a.db.SetCustomColumns(ctx, []db.CustomColumn{{Name: "Upside", Table: db.ColumnTableHoldings, Expr: "(target - price) * qty"}})
+	a.refreshData()
+
+	a.showHoldingsLayoutView()
+	_, page := a.pages.GetFrontPage()
+	table := page.(*tview.Flex).GetItem(0).(*tview.Table)
+	key := func(r rune) { table.GetInputCapture()(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)) }
+	headers := func() []string {
+		var got []string
+		for c := range a.holdingsView.table.GetColumnCount() {
+			got = append(got, strings.TrimSpace(a.holdingsView.table.GetCell(0, c).Text))
+		}
+		return got
+	}
+
+	// Hide QTY and move PRICE ahead of AVG COST
+	table.Select(1, 0)
+	key(' ')
+	table.Select(3, 0)
+	key('K')
+	key('K')
+	want := []string{"TICKER", "PRICE", "AVG COST", "ADJ COST", "VALUE", "P/L", "P/L %", "PREM YTD", "DIV/YR",
+		"WEIGHT", "vs HIGH", "vs LOW", "SIGNAL", "EARNINGS", "UPSIDE"}
+	if got := headers(); !slices.Equal(got, want) {
+		t.Errorf("headers = %v, want %v", got, want)
+	}
+	for i, h := range a.book.holdings {
+		if h.Ticker == "MSFT" && strings.TrimSpace(a.holdingsView.table.GetCell(i+1, 14).Text) != "-117.50" {
+			t.Errorf("MSFT upside = %q after the built-in columns", a.holdingsView.table.GetCell(i+1, 14).Text)
+		}
+	}
+
+	// The layout is saved and survives a refresh
+	layout, _ := a.db.GetHoldingsColumns(ctx)
+	if len(layout) != len(holdingHeaders)-2 || layout[0] != "PRICE" || slices.Contains(layout, "QTY") {
+		t.Errorf("saved layout = %v", layout)
+	}
+	a.refreshData()
+	if got := headers(); !slices.Equal(got, want) {
+		t.Errorf("headers after refresh = %v, want %v", got, want)
+	}
+
+	key('R')
+	if layout, _ := a.db.GetHoldingsColumns(ctx); layout != nil {
+		t.Errorf("layout after reset = %v, want the default", layout)
+	}
+	if got := headers(); !slices.Equal(got, append(slices.Clone(holdingHeaders), "UPSIDE")) {
+		t.Errorf("headers after reset = %v", got)
+	}
+}
+
+func TestCSPSignals(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	report := tview.NewTextView()
+
+	save := func(name, weight, expr string) {
+		t.Helper()
+		a.showSignalForm(report)
+		form := modalForm(t, a, "signalform")
+		form.GetFormItem(0).(*tview.InputField).SetText(name)
+		form.GetFormItem(1).(*tview.InputField).SetText(weight)
+		form.GetFormItem(2).(*tview.InputField).SetText(expr)
+		pressButton(form, "Save")
+	}
+
+	save("Oversold", "0.2", "100 - rsi")
+	save("oversold", "0.1", "100 - rsi / 2") // Replaces by name
+	save("Broken", "0.2", "price * 2")       // Not a signal variable
+	save("Zero", "0", "vix")
+	save("RSI", "0.2", "rsi") // Built-in name
+
+	signals, _ := a.db.GetCSPSignals(ctx)
+	if len(signals) != 1 || signals[0].Weight != 0.1 || signals[0].Expr != "100 - rsi / 2" {
+		t.Fatalf("signals = %+v", signals)
+	}
+	if got := a.query.Signals(ctx); len(got) != len(csp.BuiltinSignals())+1 || got[len(got)-1].Name() != "oversold" {
+		t.Errorf("query signals = %v", got)
+	}
+	if got := report.GetText(true); !strings.Contains(got, "100 - rsi / 2") {
+		t.Errorf("signals report:\n%s", got)
+	}
+
+	a.showDeleteSignalForm(report, signals)
+	pressButton(modalForm(t, a, "signaldelete"), "Delete")
+	if signals, _ := a.db.GetCSPSignals(ctx); len(signals) != 0 {
+		t.Errorf("signals after delete = %+v", signals)
+	}
+}
+
+func TestAdjustedCost(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	store := a.db.(*fake.Store)
+	dec := decimal.RequireFromString
+	day := func(offset int) time.Time { return renderFixture.AddDate(0, 0, offset).Truncate(24 * time.Hour) }
+	optionID := func(optionType string, expiry time.Time) string {
+		t.Helper()
+		options, _ := store.GetActiveOptions(ctx)
+		for _, o := range options {
+			if o.Ticker == "AMD" && o.OptionType == optionType && o.ExpiryDate.Equal(expiry) {
+				return o.ID
+			}
+		}
+		t.Fatalf("no AMD %s expiring %s", optionType, expiry)
+		return ""
+	}
+
+	// A call from an earlier cycle, then a put assigned days after expiry
+	// opens the holding and a call is sold and bought back against it
+	store.AddOption(ctx, "AMD", "CALL", "SELL", dec("120"), day(-30), 1, 100, db.SettlementPhysical, db.DefaultCurrency, dec("5"), dec("1"), "")
+	store.AddOption(ctx, "AMD", "PUT", "SELL", dec("100"), day(-3), 1, 100, db.SettlementPhysical, db.DefaultCurrency, dec("2"), dec("1"), "")
+	store.AssignOption(ctx, optionID("PUT", day(-3)), decimal.Zero)
+	store.AddOption(ctx, "AMD", "CALL", "SELL", dec("110"), day(11), 1, 100, db.SettlementPhysical, db.DefaultCurrency, dec("1.50"), dec("1"), "")
+	store.CloseOption(ctx, optionID("CALL", day(11)), dec("0.50"), dec("1"))
+	a.refreshData()
+
+	adjCost := func(ticker string) string {
+		t.Helper()
+		for i, h := range a.book.holdings {
+			if h.Ticker == ticker {
+				return strings.TrimSpace(a.holdingsView.table.GetCell(i+1, 3).Text)
+			}
+		}
+		t.Fatalf("no %s holding", ticker)
+		return ""
+	}
+	// 100 less the put's 199 and the call's 98 net over 100 shares
+	if got := adjCost("AMD"); got != "$97.03" {
+		t.Errorf("AMD adjusted cost = %q, want $97.03", got)
+	}
+	// MSFT's only option is a put still open
+	if got := adjCost("MSFT"); got != "-" {
+		t.Errorf("MSFT adjusted cost = %q, want -", got)
+	}
+}
+
+func TestHoldingDetail(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	dec := decimal.RequireFromString
+	a.db.AddDividend(ctx, "AAPL", dec("48"), time.Date(2025, 11, 13, 0, 0, 0, 0, time.UTC), "")
+	a.db.AddDividend(ctx, "MSFT", dec("41.50"), time.Date(2025, 12, 11, 0, 0, 0, 0, time.UTC), "")
+
+	closes := make([]float64, 250)
+	for i := range closes {
+		closes[i] = 100 + float64(i%50)
+	}
+	dividends, _ := a.db.GetDividendsSince(ctx, time.Time{})
+	report := a.holdingDetailReport(a.book.holdings[0], closes, dividends)
+	for _, want := range []string{
+		"[teal]AAPL:[white] 200.00 shares @ $150.25",
+		"[teal]Value:[white] $48,360.00",
+		"SELL CALL $230.00",
+		"[teal]Premiums:[white] [lime]$370.00[white] collected",
+		"$48.00",
+		"last 63 closes",
+		"Enter: actions",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "$41.50") || strings.Contains(report, "MSFT") {
+		t.Errorf("report lists another holding's rows:\n%s", report)
+	}
+
+	// A rising line starts at the bottom left and ends at the top right
+	lines := brailleChart([]float64{1, 2, 3, 4}, 4, 2)
+	if len(lines) != 2 || []rune(lines[1])[0]&brailleDots[0][3] == 0 || []rune(lines[0])[3]&brailleDots[1][0] == 0 {
+		t.Errorf("chart = %q", lines)
+	}
+
+	// Enter on the holdings table opens the detail page
+	a.holdingsView.table.Select(1, 0)
+	a.holdingsView.table.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+	if name, _ := a.pages.GetFrontPage(); name != "holding" {
+		t.Errorf("front page = %q, want holding", name)
+	}
+}
+
+func TestWatchlist(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	dec := decimal.RequireFromString
+	a.db.SetWatchTicker(ctx, "AMD", decimal.NullDecimal{Decimal: dec("150"), Valid: true}, "on a dip")
+	a.db.SetWatchTicker(ctx, "TSLA", decimal.NullDecimal{Decimal: dec("200"), Valid: true}, "")
+	a.db.SetWatchTicker(ctx, "TSLA", decimal.NullDecimal{Decimal: dec("190"), Valid: true}, "") // Replaces
+
+	a.showWatchlistView()
+	table := a.pages.GetPage("watchlist").(*tview.Flex).GetItem(0).(*tview.Table)
+	a.setWatchQuotes(map[string]yahoo.Quote{"AMD": {Symbol: "AMD", Price: 165, FiftyTwoWeekHigh: 220, PctFromHigh: -25, FiftyTwoWeekLow: 160, PctFromLow: 3.1}})
+	a.fillWatchlistTable(table)
+
+	cell := func(row, col int) string { return strings.TrimSpace(table.GetCell(row, col).Text) }
+	if cell(1, 0) != "AMD" || cell(1, 1) != "$165.00" || cell(1, 2) != "$150.00" || cell(1, 3) != "+10.0%" || cell(1, 4) != "-25.0% ($220.00)" {
+		t.Errorf("AMD row = %s | %s | %s | %s | %s", cell(1, 0), cell(1, 1), cell(1, 2), cell(1, 3), cell(1, 4))
+	}
+	if fg, _, _ := table.GetCell(1, 5).Style.Decompose(); cell(1, 5) != "+3.1% ($160.00)" || fg != tcell.ColorLime {
+		t.Errorf("AMD vs low = %q in %v, want near the low in lime", cell(1, 5), fg)
+	}
+	// TSLA is quoted with the portfolio, below its target
+	if cell(2, 0) != "TSLA" || cell(2, 2) != "$190.00" || cell(2, 3) != "BUY" {
+		t.Errorf("TSLA row = %s | %s | %s", cell(2, 0), cell(2, 2), cell(2, 3))
+	}
+
+	// Converting prefills the holding form at the price and takes the ticker
+	// off the watchlist once saved
+	a.convertWatchToHolding(a.watchlist[0])
+	form := modalForm(t, a, "add")
+	if got := form.GetFormItem(2).(*tview.InputField).GetText(); got != "165" {
+		t.Errorf("avg cost = %q, want the price", got)
+	}
+	form.GetFormItem(1).(*tview.InputField).SetText("10")
+	form.GetFormItem(5).(*tview.InputField).SetText(a.book.locale.FormatDate(renderFixture))
+	pressButton(form, "Save")
+	if h, _ := a.db.GetHoldingByTicker(ctx, "AMD"); h == nil || !h.Quantity.Equal(dec("10")) || h.Notes != "on a dip" {
+		t.Errorf("AMD holding = %+v", h)
+	}
+	if watchlist, _ := a.db.GetWatchlist(ctx); len(watchlist) != 1 || watchlist[0].Ticker != "TSLA" {
+		t.Errorf("watchlist = %+v, want AMD taken off", watchlist)
+	}
+	if a.pages.HasPage("watchlist") {
+		t.Error("watchlist still open after converting")
+	}
+}
+
+func TestWeeklyReview(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	store := a.db.(*fake.Store)
+	dec := decimal.RequireFromString
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+
+	// During the week of the fixture's Monday: the MSFT put is bought back,
+	// the NVDA put expires, an alert fires, and the watchlist is rescored
+	for _, o := range a.book.options {
+		switch {
+		case o.Ticker == "MSFT":
+			store.Now = func() time.Time { return day(3) }
+			store.CloseOption(ctx, o.ID, dec("2.00"), dec("0.65"))
+		case o.Ticker == "NVDA" && o.OptionType == "PUT":
+			store.Now = func() time.Time { return day(4) }
+			store.ExpireOption(ctx, o.ID)
+		}
+	}
+	a.query.LogAlerts(ctx, []query.Alert{{Kind: "itm", Key: "old", Message: "Last week's alert"}}, time.Date(2026, 2, 25, 0, 0, 0, 0, time.UTC))
+	a.query.LogAlerts(ctx, []query.Alert{{Kind: "itm", Key: "new", Message: "TSLA put in the money"}}, day(3))
+	store.SetScoreHistory(ctx, []db.ScoreSnapshot{
+		{Day: time.Date(2026, 2, 27, 0, 0, 0, 0, time.UTC), Scores: map[string]float64{"AMD": 50, "SPY": 40}},
+		{Day: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), Scores: map[string]float64{"AMD": 65, "SPY": 38, "PLTR": 70}},
+	})
+	a.refreshData()
+
+	from := reviewWeekStart(a.today())
+	if want := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC); !from.Equal(want) {
+		t.Fatalf("week starts %v, want %v", from, want)
+	}
+	sections := make(map[string]reviewSection)
+	for _, s := range a.buildWeeklyReview(ctx, from) {
+		sections[s.title] = s
+	}
+	has := func(title, text string) bool {
+		return slices.ContainsFunc(sections[title].lines, func(line string) bool { return strings.Contains(line, text) })
+	}
+
+	if !has("Summary", "5 opened, 1 closed, 1 expired, 0 assigned") {
+		t.Errorf("summary = %v", sections["Summary"].lines)
+	}
+	if len(sections["Options opened"].lines) != 5 {
+		t.Errorf("opened = %v", sections["Options opened"].lines)
+	}
+	// Sold at 6.40, bought back at 2.00
+	if !has("Options closed", "MSFT $380.00 PUT") || !has("Options closed", "closed at $2.00 (P/L $440.00)") ||
+		!has("Options closed", "NVDA $110.00 PUT "+a.book.locale.FormatDate(time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC))+" expired") {
+		t.Errorf("closed = %v", sections["Options closed"].lines)
+	}
+	if !has("Alerts", "TSLA put in the money") || has("Alerts", "Last week's alert") {
+		t.Errorf("alerts = %v", sections["Alerts"].lines)
+	}
+	if got, want := sections["Watchlist scores"].lines, []string{"AMD 50 -> 65 (+15)", "SPY 40 -> 38 (-2)", "PLTR 70 (new)"}; !slices.Equal(got, want) {
+		t.Errorf("scores = %v, want %v", got, want)
+	}
+	// The AAPL call expires Friday; the rest are further out
+	if expiring := sections["Expiring in the next 7 days"].lines; len(expiring) != 1 || !strings.Contains(expiring[0], "AAPL $230.00 CALL") {
+		t.Errorf("expiring = %v", expiring)
+	}
+
+	// The week before opened nothing, and its scores were the first recorded
+	for _, s := range a.buildWeeklyReview(ctx, from.AddDate(0, 0, -7)) {
+		switch s.title {
+		case "Options opened":
+			if len(s.lines) > 0 {
+				t.Errorf("previous week opened = %v", s.lines)
+			}
+		case "Watchlist scores":
+			if want := []string{"AMD 50 (new)", "SPY 40 (new)"}; !slices.Equal(s.lines, want) {
+				t.Errorf("previous week scores = %v, want %v", s.lines, want)
+			}
+		}
+	}
+
+	md := reviewMarkdown(a.reviewTitle(from), a.buildWeeklyReview(ctx, from))
+	for _, want := range []string{"# Weekly Review: ", "\n## Watchlist scores\n\n- AMD 50 -> 65 (+15)\n", "## Assignments\n\n_None_\n"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestTradeIdeas(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	a.refreshData()
+	dec := decimal.RequireFromString
+	expiry := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+
+	// Accepting a CSP advisor row queues its target put at the mid
+	a.cspView.watchlist = []db.CSPWatchItem{{Ticker: "AMD"}}
+	a.cspView.contracts = map[string]ContractInfo{"AMD": {Strike: 150, Expiry: expiry, DTE: 18, Premium: 2.345}}
+	a.cspView.table.SetCell(1, 0, tview.NewTableCell("AMD"))
+	a.cspView.table.Select(1, 0)
+	a.acceptCSPIdea()
+	ideas, _ := a.db.GetTradeIdeas(ctx)
+	if len(ideas) != 1 || ideas[0].Ticker != "AMD" || ideas[0].OptionType != "PUT" || !ideas[0].Strike.Equal(dec("150")) ||
+		!ideas[0].Premium.Equal(dec("2.35")) || ideas[0].Quantity != 1 {
+		t.Fatalf("ideas = %+v", ideas)
+	}
+
+	a.showIdeasView()
+	table := a.pages.GetPage("ideas").(*tview.Flex).GetItem(0).(*tview.Table)
+	cell := func(row, col int) string { return strings.TrimSpace(table.GetCell(row, col).Text) }
+	if cell(1, 0) != "AMD" || cell(1, 4) != "18" || cell(1, 7) != "+$235" {
+		t.Errorf("AMD row = %s | %s | %s", cell(1, 0), cell(1, 4), cell(1, 7))
+	}
+
+	// Booking opens the option form at the intended premium; saving records
+	// the fill and takes the idea off the queue
+	table.InputHandler()(tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone), func(tview.Primitive) {})
+	form := modalForm(t, a, "addoption")
+	if got := form.GetFormItem(6).(*tview.InputField).GetText(); got != "2.35" {
+		t.Errorf("premium = %q, want the intended 2.35", got)
+	}
+	form.GetFormItem(6).(*tview.InputField).SetText("2.41")
+	pressButton(form, "Save")
+	var booked *db.Option
+	for i, o := range a.book.options {
+		if o.Ticker == "AMD" {
+			booked = &a.book.options[i]
+		}
+	}
+	if booked == nil || booked.Action != "SELL" || booked.OptionType != "PUT" || !booked.Premium.Equal(dec("2.41")) {
+		t.Errorf("booked option = %+v", booked)
+	}
+	if ideas, _ := a.db.GetTradeIdeas(ctx); len(ideas) != 0 {
+		t.Errorf("ideas = %+v, want the booked one removed", ideas)
+	}
+	if a.pages.HasPage("ideas") {
+		t.Error("trade ideas still open after booking")
+	}
+}
+
+func TestNearYearLowSignal(t *testing.T) {
+	a := newRenderApp(t)
+	market := a.yahoo.(*fake.Market)
+	market.Quotes["NVDA"] = yahoo.Quote{Symbol: "NVDA", Price: 90, FiftyTwoWeekHigh: 153.13, PctFromHigh: -41.2, FiftyTwoWeekLow: 88, PctFromLow: 2.3}
+	a.refreshData()
+
+	row := 0
+	for r, i := range a.holdingsView.rows {
+		if a.book.holdings[i].Ticker == "NVDA" {
+			row = r + 1
+		}
+	}
+	cell := func(col int) string { return strings.TrimSpace(a.holdingsView.table.GetCell(row, col).Text) }
+	if cell(12) != "+2.3% ($88.00)" {
+		t.Errorf("NVDA vs low = %q", cell(12))
+	}
+	// No target price, but within 5% of the 52-week low
+	if cell(13) != "BUY" {
+		t.Errorf("NVDA signal = %q, want BUY near the low", cell(13))
+	}
+	// AAPL is far above its low
+	if got := strings.TrimSpace(a.holdingsView.table.GetCell(1, 13).Text); got == "BUY" {
+		t.Errorf("AAPL signal = %q", got)
+	}
+}
+
+func TestDemoStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := demoStore(renderFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := decimal.RequireFromString
+
+	holdings, _ := store.GetHoldings(ctx)
+	shares := make(map[string]decimal.Decimal)
+	for _, h := range holdings {
+		shares[h.Ticker] = h.Quantity
+	}
+	// NVDA came from the assigned put; MSFT was trimmed
+	for ticker, want := range map[string]string{"AAPL": "100", "MSFT": "60", "KO": "200", "NVDA": "100"} {
+		if !shares[ticker].Equal(dec(want)) {
+			t.Errorf("%s shares = %s, want %s", ticker, shares[ticker], want)
+		}
+	}
+
+	options, _ := store.GetActiveOptions(ctx)
+	statuses := make(map[string]int)
+	for _, o := range options {
+		statuses[o.Status]++
+		if o.Status == "ACTIVE" && !o.ExpiryDate.After(renderFixture) {
+			t.Errorf("%s %s expires %s, before the demo's today", o.Ticker, o.OptionType, o.ExpiryDate)
+		}
+	}
+	if statuses["ACTIVE"] != 5 || statuses["EXPIRED"] != 2 || statuses["ASSIGNED"] != 1 || statuses["CLOSED"] != 1 {
+		t.Errorf("option statuses = %v", statuses)
+	}
+
+	if cash, _ := store.GetAvailableCash(ctx); !cash.IsPositive() {
+		t.Errorf("cash = %s", cash)
+	}
+	snapshots, _ := store.GetPortfolioSnapshots(ctx, renderFixture.AddDate(-1, 0, 0))
+	watchlist, _ := store.GetWatchlist(ctx)
+	cspWatchlist, _ := store.GetCSPWatchlist(ctx)
+	ideas, _ := store.GetTradeIdeas(ctx)
+	if len(snapshots) != 52 || len(watchlist) != 3 || len(cspWatchlist) != 4 || len(ideas) != 1 {
+		t.Errorf("%d snapshots, %d watched, %d CSP watched, %d ideas", len(snapshots), len(watchlist), len(cspWatchlist), len(ideas))
+	}
+	if transactions, _ := store.GetTransactions(ctx); len(transactions) == 0 || !transactions[0].CreatedAt.Before(renderFixture.AddDate(0, -11, 0)) {
+		t.Error("transactions are not dated over the past year")
+	}
+}
+
+func TestQuickFilter(t *testing.T) {
+	a := newRenderApp(t)
+	a.refreshData()
+
+	// Every word must match: "put" is a type, "ms" a ticker substring
+	a.optionsView.filter = "put ms"
+	a.optionsView.update()
+	if len(a.optionsView.rows) != 1 || a.book.options[a.optionsView.rows[0]].Ticker != "MSFT" {
+		t.Fatalf("option rows = %v", a.optionsView.rows)
+	}
+	if got := a.optionsView.title.GetText(true); !strings.Contains(got, "put ms (1 of 5)") {
+		t.Errorf("options title = %q", got)
+	}
+	a.optionsView.table.Select(1, 0)
+	if i, ok := a.optionsView.selected(); !ok || a.book.options[i].Ticker != "MSFT" {
+		t.Errorf("selected option = %d, %v", i, ok)
+	}
+
+	a.optionsView.filter = "expired"
+	a.optionsView.update()
+	if len(a.optionsView.rows) != 1 || a.book.options[a.optionsView.rows[0]].Status != "EXPIRED" {
+		t.Errorf("expired rows = %v", a.optionsView.rows)
+	}
+
+	a.holdingsView.filter = "VD"
+	a.holdingsView.update()
+	if len(a.holdingsView.rows) != 1 || a.book.holdings[a.holdingsView.rows[0]].Ticker != "NVDA" {
+		t.Fatalf("holding rows = %v", a.holdingsView.rows)
+	}
+	if got := strings.TrimSpace(a.holdingsView.table.GetCell(1, 0).Text); got != "NVDA" {
+		t.Errorf("first holdings row = %q", got)
+	}
+	if got := a.summaryView.text.GetTitle(); !strings.Contains(got, "VD (1 of 3)") {
+		t.Errorf("portfolio title = %q", got)
+	}
+
+	if !a.clearFilters() {
+		t.Fatal("clearFilters reported no filter")
+	}
+	if len(a.holdingsView.rows) != len(a.book.holdings) || len(a.optionsView.rows) != len(a.book.options) {
+		t.Errorf("rows after clearing = %d holdings, %d options", len(a.holdingsView.rows), len(a.optionsView.rows))
+	}
+	if a.clearFilters() {
+		t.Error("clearFilters reported a filter after clearing")
+	}
+}
+
+func TestTags(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	if got := parseTags(" Wheel, long term,#wheel,, "); !slices.Equal(got, []string{"long-term", "wheel"}) {
+		t.Errorf("parseTags = %v", got)
+	}
+
+	// Tag the AAPL holding and the MSFT put
+	a.setFocusIndex(0)
+	a.holdingsView.table.Select(slices.IndexFunc(a.book.holdings, func(h db.Holding) bool { return h.Ticker == "AAPL" })+1, 0)
+	a.showTagsForm()
+	form := modalForm(t, a, "tags")
+	form.GetFormItem(0).(*tview.InputField).SetText("wheel, Long Term")
+	pressButton(form, "Save")
+
+	a.setFocusIndex(1)
+	a.optionsView.table.Select(activeOptionIndex(t, a, "MSFT")+1, 0)
+	a.showTagsForm()
+	form = modalForm(t, a, "tags")
+	form.GetFormItem(0).(*tview.InputField).SetText("wheel")
+	pressButton(form, "Save")
+
+	a.refreshData()
+	aapl := a.book.holdings[slices.IndexFunc(a.book.holdings, func(h db.Holding) bool { return h.Ticker == "AAPL" })]
+	if got := a.book.tags[aapl.ID]; !slices.Equal(got, []string{"long-term", "wheel"}) {
+		t.Errorf("AAPL tags = %v", got)
+	}
+
+	// #tag matches the tag exactly; a plain word matches part of one
+	a.holdingsView.filter, a.optionsView.filter = "#wheel", "#wheel"
+	a.holdingsView.update()
+	a.optionsView.update()
+	if len(a.holdingsView.rows) != 1 || len(a.optionsView.rows) != 1 || a.book.options[a.optionsView.rows[0]].Ticker != "MSFT" {
+		t.Errorf("#wheel rows: %v holdings, %v options", a.holdingsView.rows, a.optionsView.rows)
+	}
+	a.holdingsView.filter = "#long"
+	a.holdingsView.update()
+	if len(a.holdingsView.rows) != 0 {
+		t.Errorf("#long matched holdings %v", a.holdingsView.rows)
+	}
+	a.holdingsView.filter = "long"
+	a.holdingsView.update()
+	if len(a.holdingsView.rows) != 1 {
+		t.Errorf("long matched holdings %v", a.holdingsView.rows)
+	}
+	a.clearFilters()
+
+	// The column goes after the built-in ones, before custom columns
+	a.db.SetCustomColumns(ctx, []db.CustomColumn{{Name: "Upside", Table: db.ColumnTableHoldings, Expr: "(target - price) * qty"}})
+	a.refreshData()
+	a.toggleTagsColumn()
+	if got := a.holdingsView.table.GetCell(0, 15).Text; got != " TAGS " {
+		t.Errorf("holdings header 15 = %q", got)
+	}
+	if got := a.holdingsView.table.GetCell(0, 16).Text; got != " UPSIDE " {
+		t.Errorf("holdings header 16 = %q", got)
+	}
+	for i, h := range a.book.holdings {
+		want := "-"
+		if h.Ticker == "AAPL" {
+			want = "long-term, wheel"
+		}
+		if got := strings.TrimSpace(a.holdingsView.table.GetCell(i+1, 15).Text); got != want {
+			t.Errorf("%s tags cell = %q, want %q", h.Ticker, got, want)
+		}
+	}
+	if got := a.optionsView.table.GetCell(0, 12).Text; got != " TAGS " {
+		t.Errorf("options header 12 = %q", got)
+	}
+	if !a.captureSession().ShowTags {
+		t.Error("tags column not saved with the session")
+	}
+}
+
+func TestArchiveHoldings(t *testing.T) {
+	ctx := context.Background()
+	dec := decimal.RequireFromString
+	a := newRenderApp(t)
+	a.refreshData()
+	total, value := len(a.book.holdings), a.holdingsView.value
+
+	// Archiving hides the row but keeps the holding and its value
+	a.setFocusIndex(0)
+	a.holdingsView.table.Select(slices.IndexFunc(a.book.holdings, func(h db.Holding) bool { return h.Ticker == "AAPL" })+1, 0)
+	a.toggleArchived()
+	a.refreshData()
+	if len(a.book.holdings) != total || len(a.holdingsView.rows) != total-1 {
+		t.Fatalf("%d holdings, %d rows after archiving", len(a.book.holdings), len(a.holdingsView.rows))
+	}
+	for _, i := range a.holdingsView.rows {
+		if a.book.holdings[i].Ticker == "AAPL" {
+			t.Error("archived AAPL still shown")
+		}
+	}
+	if !a.holdingsView.value.Equal(value) {
+		t.Errorf("holdings value = %s, want %s", a.holdingsView.value, value)
+	}
+	if title := a.summaryView.text.GetTitle(); !strings.Contains(title, "1 archived") {
+		t.Errorf("title %q does not count the archived holding", title)
+	}
+
+	// Z shows it, grayed
+	a.toggleShowArchived()
+	row := slices.IndexFunc(a.holdingsView.rows, func(i int) bool { return a.book.holdings[i].Ticker == "AAPL" }) + 1
+	if fg, _, _ := a.holdingsView.table.GetCell(row, 0).Style.Decompose(); row == 0 || fg != tcell.ColorGray {
+		t.Errorf("archived AAPL at row %d not shown in gray", row)
+	}
+	if !a.captureSession().ShowArchived {
+		t.Error("show archived not saved with the session")
+	}
+	a.toggleShowArchived()
+
+	// Buying more restores it
+	if err := a.db.AddHolding(ctx, "AAPL", dec("10"), dec("200"), db.DefaultCurrency, renderFixture, decimal.NullDecimal{}, ""); err != nil {
+		t.Fatal(err)
+	}
+	a.refreshData()
+	if len(a.holdingsView.rows) != total {
+		t.Errorf("%d rows after buying into the archived holding, want %d", len(a.holdingsView.rows), total)
+	}
+
+	// So do shares put to us
+	a.holdingsView.table.Select(slices.IndexFunc(a.book.holdings, func(h db.Holding) bool { return h.Ticker == "MSFT" })+1, 0)
+	a.toggleArchived()
+	a.refreshData()
+	put := a.book.options[activeOptionIndex(t, a, "MSFT")]
+	if err := a.db.AssignOption(ctx, put.ID, decimal.Zero); err != nil {
+		t.Fatal(err)
+	}
+	a.refreshData()
+	if len(a.holdingsView.rows) != total {
+		t.Errorf("%d rows after assignment into the archived holding, want %d", len(a.holdingsView.rows), total)
+	}
+}
+
+func TestFilterBarBothTables(t *testing.T) {
+	a := newRenderApp(t)
+	a.refreshData()
+	a.setFocusIndex(0)
+	a.optionsView.filter = "call"
+	a.optionsView.update()
+
+	a.showFilterBar()
+	input := a.filterBar
+	key := func(k tcell.Key) {
+		input.InputHandler()(tcell.NewEventKey(k, 0, tcell.ModNone), func(tview.Primitive) {})
+	}
+
+	// Typing narrows the focused table only
+	input.SetText("nvda")
+	if len(a.holdingsView.rows) != 1 || a.optionsView.filter != "call" {
+		t.Fatalf("holdings rows %v, options filter %q", a.holdingsView.rows, a.optionsView.filter)
+	}
+
+	// Tab widens it to the options too, and back restores their own filter
+	input.GetInputCapture()(tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone))
+	if !strings.Contains(input.GetLabel(), "holdings and options") || a.optionsView.filter != "nvda" || len(a.optionsView.rows) != 2 {
+		t.Errorf("both tables: label %q, options filter %q, rows %v", input.GetLabel(), a.optionsView.filter, a.optionsView.rows)
+	}
+	input.GetInputCapture()(tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone))
+	if a.optionsView.filter != "call" {
+		t.Errorf("options filter back on the focused table = %q, want call", a.optionsView.filter)
+	}
+
+	// Escape clears the typed filter and puts back the other table's own
+	input.GetInputCapture()(tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone))
+	key(tcell.KeyEscape)
+	if a.filterBar != nil || a.holdingsView.filter != "" || a.optionsView.filter != "call" {
+		t.Errorf("after escape: bar %v, filters %q and %q", a.filterBar != nil, a.holdingsView.filter, a.optionsView.filter)
+	}
+}
+
+func TestPinnedRows(t *testing.T) {
+	a := newRenderApp(t)
+	a.refreshData()
+
+	// Pin NVDA: it moves above AAPL and MSFT and stays selected
+	a.setFocusIndex(0)
+	a.holdingsView.table.Select(3, 0)
+	a.togglePin()
+	if a.book.holdings[0].Ticker != "NVDA" || a.book.holdings[1].Ticker != "AAPL" {
+		t.Fatalf("holdings order = %s, %s", a.book.holdings[0].Ticker, a.book.holdings[1].Ticker)
+	}
+	if got := a.holdingsView.table.GetCell(1, 0).Text; got != "*NVDA " {
+		t.Errorf("pinned ticker cell = %q", got)
+	}
+	if i, ok := a.holdingsView.selected(); !ok || a.book.holdings[i].Ticker != "NVDA" {
+		t.Errorf("selection did not follow the pinned row")
+	}
+
+	// Pin the TSLA put: it leads the options table
+	a.setFocusIndex(1)
+	a.optionsView.table.Select(activeOptionIndex(t, a, "TSLA")+1, 0)
+	a.togglePin()
+	if a.book.options[0].Ticker != "TSLA" {
+		t.Errorf("first option = %s", a.book.options[0].Ticker)
+	}
+
+	// Pins survive a refresh, which reloads rows in database order
+	a.refreshData()
+	if a.book.holdings[0].Ticker != "NVDA" || a.book.options[0].Ticker != "TSLA" {
+		t.Errorf("after refresh: %s, %s", a.book.holdings[0].Ticker, a.book.options[0].Ticker)
+	}
+
+	// Unpinning restores the order
+	a.setFocusIndex(0)
+	a.holdingsView.table.Select(1, 0)
+	a.togglePin()
+	if a.book.holdings[0].Ticker != "AAPL" || a.book.holdings[2].Ticker != "NVDA" {
+		t.Errorf("after unpin: %s ... %s", a.book.holdings[0].Ticker, a.book.holdings[2].Ticker)
+	}
+	ids, _ := a.db.GetPinned(context.Background())
+	if len(ids) != 1 {
+		t.Errorf("stored pins = %v", ids)
+	}
+}
+
+func TestNoTasksAfterShutdown(t *testing.T) {
+	a := newRenderApp(t)
+	a.stopAutoRefresh = make(chan bool)
+	a.shutdown()
+
+	ran := false
+	a.goSafe("late", func() { ran = true })
+	a.tasks.Wait()
+	if ran {
+		t.Error("task started after shutdown")
+	}
+}
+
+func TestStatusToggles(t *testing.T) {
+	a := newRenderApp(t)
+	a.refreshData()
+
+	title := a.optionsView.title.GetText(true)
+	for _, want := range []string{"1:ACTIVE 4", "2:CLOSED 0", "3:EXPIRED 1", "4:ASSIGNED 0"} {
+		if !strings.Contains(title, want) {
+			t.Errorf("options title %q lacks %q", title, want)
+		}
+	}
+
+	// Hiding ACTIVE leaves the expired put; 3 is the same toggle as e
+	a.toggleStatus("ACTIVE")
+	if len(a.optionsView.rows) != 1 || a.book.options[a.optionsView.rows[0]].Status != "EXPIRED" {
+		t.Errorf("rows with ACTIVE hidden = %v", a.optionsView.rows)
+	}
+	a.toggleStatus("EXPIRED")
+	if len(a.optionsView.rows) != 0 || a.optionsView.showExpired {
+		t.Errorf("rows with ACTIVE and EXPIRED hidden = %v", a.optionsView.rows)
+	}
+
+	// Hidden statuses are resumed with the session
+	s := a.captureSession()
+	if len(s.HiddenStatuses) != 1 || s.HiddenStatuses[0] != "ACTIVE" {
+		t.Errorf("saved hidden statuses = %v", s.HiddenStatuses)
+	}
+
+	a.toggleStatus("ACTIVE")
+	if len(a.optionsView.rows) != 4 {
+		t.Errorf("rows with only EXPIRED hidden = %d", len(a.optionsView.rows))
+	}
+}
+
+func TestFormValidation(t *testing.T) {
+	a := newRenderApp(t)
+	a.showAddOptionForm(nil, nil)
+	form := modalForm(t, a, "addoption")
+	input := func(i int) *tview.InputField { return form.GetFormItem(i).(*tview.InputField) }
+	hint := form.GetFormItem(form.GetFormItemCount() - 1).(*tview.TextView)
+
+	// Only edited fields are checked while typing
+	input(3).SetText("-5")
+	if got := hint.GetText(true); got != "Strike: must be greater than 0" {
+		t.Errorf("hint = %q", got)
+	}
+	if !strings.HasPrefix(input(3).GetLabel(), "[red]") || strings.HasPrefix(input(0).GetLabel(), "[red]") {
+		t.Errorf("labels = %q, %q", input(3).GetLabel(), input(0).GetLabel())
+	}
+
+	// An expiry already past is rejected; the clock is 2026-03-02
+	input(3).SetText("200")
+	input(4).SetText("2026-02-27")
+	if got := hint.GetText(true); got != "Expiry: must not be in the past" {
+		t.Errorf("hint = %q", got)
+	}
+	input(4).SetText("2026-03-20")
+	if got := hint.GetText(true); got != "" {
+		t.Errorf("hint with valid fields = %q", got)
+	}
+
+	// Save checks the untouched fields too and keeps the form open
+	before := len(a.book.options)
+	pressButton(form, "Save")
+	if got := hint.GetText(true); got != "Ticker: required" {
+		t.Errorf("hint after Save = %q", got)
+	}
+	if !a.pages.HasPage("addoption") {
+		t.Error("form closed with invalid fields")
+	}
+
+	input(0).SetText("amd")
+	input(6).SetText("1.5")
+	pressButton(form, "Save")
+	if a.pages.HasPage("addoption") || len(a.book.options) != before+1 {
+		t.Errorf("valid option not saved: %d options", len(a.book.options))
+	}
+}
+
+func TestDatePicker(t *testing.T) {
+	a := newRenderApp(t)
+	a.showAddOptionForm(nil, nil)
+	expiry := modalForm(t, a, "addoption").GetFormItem(4).(*tview.InputField)
+	press := func(key tcell.Key) { expiry.GetInputCapture()(tcell.NewEventKey(key, 0, tcell.ModNone)) }
+
+	// The clock is Monday 2026-03-02: expiry defaults to that week's Friday
+	if got := expiry.GetText(); got != "2026-03-06" {
+		t.Errorf("default expiry = %q", got)
+	}
+	press(tcell.KeyUp)
+	press(tcell.KeyPgUp)
+	if got := expiry.GetText(); got != "2026-03-14" {
+		t.Errorf("after Up, PgUp = %q", got)
+	}
+
+	expiry.SetText("3rd fri")
+	press(tcell.KeyTab)
+	if got := expiry.GetText(); got != "2026-03-20" {
+		t.Errorf("3rd fri = %q", got)
+	}
+
+	// Shortcuts are accepted as typed too
+	if d, err := a.parseDate("+30d"); err != nil || d.Format(locale.ISODate) != "2026-04-01" {
+		t.Errorf("+30d = %v, %v", d, err)
+	}
+}
+
+func TestTickerSuggestions(t *testing.T) {
+	a := newRenderApp(t)
+	a.db.AddCSPWatchTicker(context.Background(), "AMD", "")
+
+	known := a.recentTickers()
+	if got := strings.Join(suggestTickers(known, "a"), ","); got != "AAPL,AMD" {
+		t.Errorf("suggestions for a = %s (known %v)", got, known)
+	}
+	// Options-only underlyings are known too
+	if got := suggestTickers(known, "ts"); len(got) != 1 || got[0] != "TSLA" {
+		t.Errorf("suggestions for ts = %v", got)
+	}
+	if got := suggestTickers(known, "TSLA"); got != nil {
+		t.Errorf("suggestions for a complete ticker = %v", got)
+	}
+	if got := suggestTickers(known, ""); got != nil {
+		t.Errorf("suggestions for nothing = %v", got)
+	}
+}
+
+func TestFirstLoadFromCache(t *testing.T) {
+	a := newRenderApp(t)
+
+	// A new session paints with the quotes the last refresh cached, before
+	// (and without) any network round trip
+	b := newTestApp(a.db.(*fake.Store), fake.NewMarket())
+	b.clock = a.clock
+	b.app = tview.NewApplication()
+	b.initWidgets()
+	live, ok := b.loadCached(context.Background())
+	if !ok {
+		t.Fatal("cached load failed")
+	}
+	if len(live) != 4 {
+		t.Errorf("tickers to fetch = %v", live)
+	}
+	if got := b.book.quotes["MSFT"].Price; got != 452.35 {
+		t.Errorf("cached MSFT price = %v", got)
+	}
+	if !b.holdingsView.value.Equal(a.holdingsView.value) {
+		t.Errorf("holdings value from cache = %s, want %s", b.holdingsView.value, a.holdingsView.value)
+	}
+	if got := b.statusBar.GetText(true); !strings.Contains(got, "Prices as of ") {
+		t.Errorf("status = %q", got)
+	}
+}
+
+func TestAlertFlash(t *testing.T) {
+	a := newRenderApp(t)
+
+	// Alerts firing at launch blink their rows but never ring the bell
+	a.alertBell = true
+	var msft, nvdaCall string
+	for _, h := range a.book.holdings {
+		if h.Ticker == "MSFT" {
+			msft = h.ID
+		}
+	}
+	for _, o := range a.book.options {
+		if o.Ticker == "NVDA" && o.OptionType == "CALL" {
+			nvdaCall = o.ID
+		}
+	}
+	if len(a.alerts) == 0 || !a.flashing[msft] {
+		t.Fatalf("alerts = %v, flashing = %v", a.alerts, a.flashing)
+	}
+	if a.rowBackground(msft) != tcell.ColorMaroon || a.rowBackground(nvdaCall) != tcell.ColorBlack {
+		t.Error("only alerted rows should be lit")
+	}
+	if a.bellPending {
+		t.Error("bell rang for alerts firing at launch")
+	}
+	if got := a.statusBar.GetText(true); !strings.Contains(got, "alert(s): ") {
+		t.Errorf("status = %q", got)
+	}
+
+	// A new alert rings once, on the next draw
+	a.yahoo.(*fake.Market).Quotes["NVDA"] = yahoo.Quote{Symbol: "NVDA", Price: 150}
+	a.refreshData()
+	if !a.flashing[nvdaCall] || !a.bellPending {
+		t.Fatalf("new ITM alert: flashing = %v, bell = %v", a.flashing[nvdaCall], a.bellPending)
+	}
+	screen := tcell.NewSimulationScreen("")
+	screen.Init()
+	a.ringBell(screen)
+	if a.bellPending {
+		t.Error("bell still pending after a draw")
+	}
+
+	// Alerts already seen do not ring again
+	a.refreshData()
+	if a.bellPending {
+		t.Error("bell rang for an alert already seen")
+	}
+}
+
+func TestAlertRoutes(t *testing.T) {
+	a := newRenderApp(t)
+	a.alertBell = true
+
+	// ITM alerts go only to the webhook; an unconfigured email is skipped
+	if err := a.db.SetNotifyRoutes(context.Background(), "itm=webhook,email; *=terminal"); err != nil {
+		t.Fatal(err)
+	}
+	sent := make(chan string, 10)
+	webhook := notify.NewFunc(notify.Webhook, func(ctx context.Context, e notify.Event) error {
+		sent <- e.Kind + ": " + e.Text
+		return nil
+	})
+	a.notifier = loadNotifier(context.Background(), a.db, webhook)
+
+	a.yahoo.(*fake.Market).Quotes["NVDA"] = yahoo.Quote{Symbol: "NVDA", Price: 150}
+	a.refreshData()
+	if a.bellPending {
+		t.Error("bell rang for an alert not routed to the terminal")
+	}
+	select {
+	case got := <-sent:
+		if want := "itm: Short NVDA CALL $140.00 is ITM (now $150.00)"; got != want {
+			t.Errorf("webhook got %q, want %q", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not sent the ITM alert")
+	}
+
+	// Other kinds fall back to the terminal
+	a.notifyAlert(query.Alert{Kind: query.AlertExpiry, Key: "expiry:x", Message: "expires"})
+	if !a.bellPending || len(sent) != 0 {
+		t.Errorf("expiry alert: bell = %v, %d sent to the webhook", a.bellPending, len(sent))
+	}
+}
+
+func TestPalette(t *testing.T) {
+	a := newRenderApp(t)
+
+	if _, ok := fuzzyScore("ivs", "IV surface"); !ok {
+		t.Error("ivs should match IV surface")
+	}
+	if _, ok := fuzzyScore("sv", "Settings"); ok {
+		t.Error("sv should not match Settings")
+	}
+	if got := a.paletteMatches("sett")[0].name; got != "Settings" {
+		t.Errorf("best match for sett = %q", got)
+	}
+	for _, action := range a.paletteMatches("") {
+		if action.view == paletteCSPView {
+			t.Errorf("CSP action %q offered on the portfolio", action.name)
+		}
+	}
+	a.role = db.RoleViewer
+	for _, action := range a.paletteMatches("add") {
+		if action.write {
+			t.Errorf("write action %q offered to a viewer", action.name)
+		}
+	}
+	a.role = ""
+
+	// Enter runs the chosen action as its key would
+	a.handleKey(tcell.NewEventKey(tcell.KeyCtrlP, 0, tcell.ModNone))
+	if name, _ := a.pages.GetFrontPage(); name != "palette" {
+		t.Fatalf("front page = %q", name)
+	}
+	flex := a.pages.GetPage("palette").(*tview.Flex).GetItem(1).(*tview.Flex).GetItem(1).(*tview.Flex)
+	input := flex.GetItem(0).(*tview.InputField)
+	input.SetText("margin")
+	input.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+	if name, _ := a.pages.GetFrontPage(); name != "margin" {
+		t.Errorf("front page after running = %q", name)
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	a := newRenderApp(t)
+	if got := strings.TrimSpace(a.holdingsView.table.GetCell(1, 1).Text); got != "200.00" {
+		t.Errorf("default shares = %q", got)
+	}
+
+	a.book.precision = db.Precision{Shares: 0, Prices: 4}
+	a.holdingsView.update()
+	a.optionsView.update()
+	if got := strings.TrimSpace(a.holdingsView.table.GetCell(1, 1).Text); got != "200" {
+		t.Errorf("whole shares = %q", got)
+	}
+	if got := strings.TrimSpace(a.holdingsView.table.GetCell(1, 2).Text); got != "$150.2500" {
+		t.Errorf("avg cost at 4 places = %q", got)
+	}
+	if got := strings.TrimSpace(a.optionsView.table.GetCell(1, 6).Text); !strings.HasSuffix(got, "1.8500") {
+		t.Errorf("premium at 4 places = %q", got)
+	}
+
+	check := requiredCheck(a.sharesCheck())
+	if got := check("1.5"); got != "must be whole shares" {
+		t.Errorf("fractional with whole shares: %q", got)
+	}
+	a.book.precision.Shares = 4
+	if got := check("1.5"); got != "" {
+		t.Errorf("fractional with 4 places: %q", got)
+	}
+	if got := check("1.23456"); got != "at most 4 decimal places" {
+		t.Errorf("5 places: %q", got)
+	}
+}
+
+func TestWeightDrift(t *testing.T) {
+	a := newRenderApp(t)
+	ctx := context.Background()
+	weightOf := func(row int) *tview.TableCell { return a.holdingsView.table.GetCell(row, 10) }
+	colorOf := func(row int) tcell.Color { fg, _, _ := weightOf(row).Style.Decompose(); return fg }
+	if got := weightOf(1).Text; strings.ContainsAny(got, "▲▼") {
+		t.Errorf("drift shown without a target: %q", got)
+	}
+
+	// AAPL is about 55% of the holdings: far over a 30% target, and within
+	// the tolerance of a target 1 point under that
+	weight, _ := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(weightOf(1).Text), "%"), 64)
+	if err := a.saveTargetWeight(ctx, "AAPL", "30"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.saveTargetWeight(ctx, "NVDA", "40"); err != nil {
+		t.Fatal(err)
+	}
+	a.refreshData()
+	if got := weightOf(1); !strings.Contains(got.Text, "▲") || colorOf(1) != tcell.ColorOrange {
+		t.Errorf("AAPL over target: %q", got.Text)
+	}
+	if got := weightOf(3); !strings.Contains(got.Text, "▼") || colorOf(3) != tcell.ColorAqua {
+		t.Errorf("NVDA under target: %q", got.Text)
+	}
+
+	a.saveTargetWeight(ctx, "AAPL", strconv.FormatFloat(weight-1, 'f', 1, 64))
+	a.refreshData()
+	if got := weightOf(1); !strings.Contains(got.Text, "▲1.0") || colorOf(1) != tcell.ColorWhite {
+		t.Errorf("AAPL within tolerance: %q", got.Text)
+	}
+
+	// Clearing the field clears the target
+	a.saveTargetWeight(ctx, "AAPL", "")
+	a.refreshData()
+	if got := weightOf(1).Text; strings.ContainsAny(got, "▲▼") {
+		t.Errorf("drift shown after clearing the target: %q", got)
+	}
+}
+
+func TestToggleBanner(t *testing.T) {
+	a := newRenderApp(t)
+	a.updateLayout()
+	firstLine := func() string { return strings.SplitN(renderText(t, a.mainFlex, 150, 60), "\n", 2)[0] }
+	banner := firstLine()
+
+	a.toggleBanner()
+	if got := firstLine(); got == banner {
+		t.Errorf("banner still shown: %q", got)
+	}
+	// Refreshes keep it collapsed
+	a.refreshData()
+	if got := firstLine(); got == banner {
+		t.Errorf("banner back after refresh: %q", got)
+	}
+	a.toggleBanner()
+	if got := firstLine(); got != banner {
+		t.Errorf("banner not restored: %q", got)
+	}
+}
+
+func TestIncomeLine(t *testing.T) {
+	a := newRenderApp(t)
+	market := a.yahoo.(*fake.Market)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	market.Dividends["AAPL"] = []analytics.Dividend{
+		{ExDate: day(2025, 11, 10), Amount: 0.26}, // Last year
+		{ExDate: day(2026, 2, 9), Amount: 0.26},
+		{ExDate: day(2026, 5, 11), Amount: 0.27}, // Not yet
+	}
+	summaryLines := func() []string { return strings.Split(a.summaryView.text.GetText(true), "\n") }
+	if got := len(summaryLines()); got != 2 {
+		t.Fatalf("summary has %d lines before toggling", got)
+	}
+
+	a.summaryView.showIncome = true
+	a.holdingsView.update()
+	lines := summaryLines()
+	if len(lines) != 3 || !strings.Contains(lines[2], "Dividends: loading") {
+		t.Fatalf("income line before dividends load = %q", lines)
+	}
+
+	a.setDividendHistories(a.fetchDividendHistories([]string{"AAPL", "MSFT", "NVDA"}))
+	a.holdingsView.update()
+	line := summaryLines()[2]
+	for _, want := range []string{"2026:", "Dividends: $52.00", "Realized: $", "Income yield:"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("income line %q missing %q", line, want)
+		}
+	}
+	if got := a.summaryHeight(); got != 5 {
+		t.Errorf("summary height = %d, want 5", got)
+	}
+}
+
+func TestDividends(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	market := a.yahoo.(*fake.Market)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	market.Dividends["AAPL"] = []analytics.Dividend{
+		{ExDate: day(2025, 2, 10), Amount: 0.25}, // Over a year ago
+		{ExDate: day(2025, 11, 10), Amount: 0.26},
+		{ExDate: day(2026, 2, 9), Amount: 0.26},
+	}
+	dividendsLine := func() string { return strings.Split(a.summaryView.text.GetText(true), "\n")[1] }
+	if got := dividendsLine(); !strings.Contains(got, "Projected annual: loading") {
+		t.Errorf("dividends line before histories load = %q", got)
+	}
+
+	a.setDividendHistories(a.fetchDividendHistories([]string{"AAPL", "MSFT", "NVDA"}))
+	a.holdingsView.update()
+	if got := strings.TrimSpace(a.holdingsView.table.GetCell(1, 9).Text); got != "$104.00" {
+		t.Errorf("AAPL projected dividends = %q, want $104.00", got)
+	}
+	if got := strings.TrimSpace(a.holdingsView.table.GetCell(2, 9).Text); got != "-" {
+		t.Errorf("MSFT projected dividends = %q, want -", got)
+	}
+	if got := dividendsLine(); !strings.Contains(got, "Projected annual: $104.00") || !strings.Contains(got, "Received 2026: $0.00") {
+		t.Errorf("dividends line = %q", got)
+	}
+
+	// Recording a payment credits cash and enters it in the ledger
+	cash := a.book.cash
+	if err := a.db.AddDividend(ctx, "AAPL", decimal.RequireFromString("52"), day(2026, 2, 27), ""); err != nil {
+		t.Fatal(err)
+	}
+	a.refreshData()
+	if want := cash.Add(decimal.RequireFromString("52")); !a.book.cash.Equal(want) {
+		t.Errorf("cash = %s, want %s", a.book.cash, want)
+	}
+	if got := dividendsLine(); !strings.Contains(got, "Received 2026: $52.00") {
+		t.Errorf("dividends line after payment = %q", got)
+	}
+	txs, _ := a.db.GetTransactions(ctx)
+	if last := txs[len(txs)-1]; last.Kind != db.TxDividend || last.Ticker != "AAPL" {
+		t.Errorf("last ledger entry = %+v, want an AAPL dividend", last)
+	}
+}
+
+func TestEarningsCalendar(t *testing.T) {
+	a := newRenderApp(t)
+	market := a.yahoo.(*fake.Market)
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, time.UTC) }
+	market.Earnings["AAPL"] = &yahoo.EarningsDate{Date: day(3, 5)}
+	market.Earnings["MSFT"] = &yahoo.EarningsDate{Date: day(3, 25)}
+	market.Earnings["NVDA"] = &yahoo.EarningsDate{Date: day(4, 15)} // Past the 4 weeks
+	market.Earnings["TSLA"] = &yahoo.EarningsDate{Date: day(3, 2), Estimate: true}
+	market.Earnings["AMD"] = &yahoo.EarningsDate{Date: day(3, 10)}
+	a.db.AddCSPWatchTicker(context.Background(), "AMD", "")
+
+	now := a.now()
+	upcoming := a.fetchUpcomingEarnings(a.earningsTickers(context.Background()), now)
+	var got []string
+	for _, e := range upcoming {
+		got = append(got, e.Ticker)
+	}
+	if want := []string{"TSLA", "AAPL", "AMD", "MSFT"}; !slices.Equal(got, want) {
+		t.Fatalf("upcoming earnings = %v, want %v", got, want)
+	}
+
+	lines := strings.Split(a.earningsReport(upcoming, a.openShorts(), now), "\n")
+	for i, want := range []string{"today", "in 3d", "in 8d", "in 23d"} {
+		if !strings.Contains(lines[i+1], want) {
+			t.Errorf("line %d = %q, want %q", i+1, lines[i+1], want)
+		}
+	}
+	if !strings.Contains(lines[1], "Mon Mar 02*") || !strings.Contains(lines[1], "held") {
+		t.Errorf("TSLA line = %q", lines[1])
+	}
+	// The AAPL call expires the day after the report; the MSFT put before it
+	if !strings.Contains(lines[2], "[red]CALL $230.00 03/06 !") {
+		t.Errorf("AAPL line = %q", lines[2])
+	}
+	if !strings.Contains(lines[3], "watched") || !strings.Contains(lines[3], "[gray]-") {
+		t.Errorf("AMD line = %q", lines[3])
+	}
+	if !strings.Contains(lines[4], "PUT $380.00 03/20") || strings.Contains(lines[4], "!") {
+		t.Errorf("MSFT line = %q", lines[4])
+	}
+}
+
+func TestReconcileBrokerPositions(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	var msftQty string
+	for _, h := range a.book.holdings {
+		if h.Ticker == "MSFT" {
+			msftQty = h.Quantity.String()
+		}
+	}
+	positions, _ := importer.ParsePositions("AAPL 100 $1.00\nMSFT " + msftQty + "\nAMD 10 $150.00\n")
+
+	discrepancies := a.reconcilePositions(positions)
+	var tickers []string
+	for _, disc := range discrepancies {
+		tickers = append(tickers, disc.Ticker)
+	}
+	// AAPL's cost differs, MSFT matches, NVDA is missing at the broker, AMD is not recorded
+	if want := []string{"AAPL", "AMD", "NVDA"}; !slices.Equal(tickers, want) {
+		t.Fatalf("discrepancies = %v, want %v", tickers, want)
+	}
+
+	cash := a.book.cash
+	press := func(label string) {
+		t.Helper()
+		page, ok := a.pages.GetPage("reconcile").(*tview.Flex)
+		if !ok {
+			t.Fatal("reconciliation not shown")
+		}
+		buttons := page.GetItem(1).(*tview.Flex).GetItem(1).(*tview.Flex).GetItem(1).(*tview.Form)
+		if buttons.GetButtonIndex(label) < 0 {
+			t.Fatalf("no %q button", label)
+		}
+		pressButton(buttons, label)
+	}
+
+	// A paste may be part of the statement: NVDA is only removed on request
+	a.showReconcileReport(positions, nil)
+	press("Apply corrections")
+	if got := a.reconcilePositions(positions); len(got) != 1 || got[0].Ticker != "NVDA" {
+		t.Fatalf("discrepancies after corrections = %+v, want NVDA left", got)
+	}
+	a.showReconcileReport(positions, nil)
+	press("Apply full statement")
+	if got := a.reconcilePositions(positions); len(got) != 0 {
+		t.Errorf("discrepancies after corrections = %+v", got)
+	}
+	if !a.book.cash.Equal(cash) {
+		t.Errorf("cash = %s, want %s unchanged", a.book.cash, cash)
+	}
+	if h, _ := a.db.GetHoldingByTicker(ctx, "AMD"); h == nil || !h.AvgCost.Equal(decimal.NewFromInt(150)) {
+		t.Errorf("AMD holding = %+v", h)
+	}
+}
+
+func TestBuyMore(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	dec := decimal.RequireFromString
+	cash := a.book.cash
+
+	index := -1
+	for i, h := range a.book.holdings {
+		if h.Ticker == "MSFT" {
+			index = i
+		}
+	}
+	a.showBuyForm(index)
+	form := modalForm(t, a, "buy")
+	form.GetFormItem(0).(*tview.InputField).SetText("50")
+	form.GetFormItem(1).(*tview.InputField).SetText("430")
+	pressButton(form, "Buy")
+	if a.pages.HasPage("buy") {
+		t.Fatal("buy form still open")
+	}
+
+	// 50 @ 410 and 50 @ 430 average to 420
+	h, _ := a.db.GetHoldingByTicker(ctx, "MSFT")
+	if h == nil || !h.Quantity.Equal(dec("100")) || !h.AvgCost.Equal(dec("420")) {
+		t.Errorf("MSFT after buying more = %+v, want 100 @ 420", h)
+	}
+	if want := cash.Sub(dec("21500")); !a.book.cash.Equal(want) {
+		t.Errorf("cash = %s, want %s", a.book.cash, want)
+	}
+	txs, _ := a.db.GetTransactions(ctx)
+	if last := txs[len(txs)-1]; last.Kind != db.TxBuy || last.Ticker != "MSFT" || !last.Quantity.Equal(dec("50")) {
+		t.Errorf("last transaction = %+v, want a 50 share MSFT BUY", last)
+	}
+}
+
+func TestFormCurrencyLabels(t *testing.T) {
+	a := newRenderApp(t)
+	a.refreshData()
+	a.book.holdings[0].Currency = "CAD"
+	a.book.baseCurrency = "EUR"
+
+	// Costs are in the holding's currency, dividends in the base cash is kept in
+	a.showEditForm(0)
+	edit := modalForm(t, a, "edit")
+	if label := edit.GetFormItem(1).GetLabel(); label != "Avg Cost (C$)" {
+		t.Errorf("edit cost label = %q", label)
+	}
+	a.showDividendForm(0)
+	dividend := modalForm(t, a, "adddividend")
+	if label := dividend.GetFormItem(0).GetLabel(); label != "Amount (€)" {
+		t.Errorf("dividend amount label = %q", label)
+	}
+}
+
+func TestSellHolding(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	dec := decimal.RequireFromString
+	cash := a.book.cash
+	realized := a.realizedOptionPL()
+
+	if err := a.db.SellHolding(ctx, "MSFT", dec("60"), dec("420"), a.now(), ""); !errors.Is(err, db.ErrOversold) {
+		t.Errorf("selling more than held: err = %v, want ErrOversold", err)
+	}
+	if err := a.db.SellHolding(ctx, "MSFT", dec("20"), dec("420"), a.now(), ""); err != nil {
+		t.Fatal(err)
+	}
+	a.refreshData()
+	h, _ := a.db.GetHoldingByTicker(ctx, "MSFT")
+	if h == nil || !h.Quantity.Equal(dec("30")) || !h.AvgCost.Equal(dec("410")) {
+		t.Errorf("MSFT after partial sale = %+v, want 30 @ 410", h)
+	}
+	if want := cash.Add(dec("8400")); !a.book.cash.Equal(want) {
+		t.Errorf("cash = %s, want %s", a.book.cash, want)
+	}
+	if got := a.realizedStockPL(); !got.Equal(dec("200")) {
+		t.Errorf("realized stock P/L = %s, want 200", got)
+	}
+	if want := "Realized: [lime]$" + a.book.locale.FormatFixed(realized.Add(dec("200")), 2); !strings.Contains(a.incomeLine(decimal.Zero), want) {
+		t.Errorf("income line does not show %q", want)
+	}
+
+	// Selling the rest removes the holding
+	if err := a.db.SellHolding(ctx, "MSFT", dec("30"), dec("400"), a.now(), ""); err != nil {
+		t.Fatal(err)
+	}
+	a.refreshData()
+	if h, _ := a.db.GetHoldingByTicker(ctx, "MSFT"); h != nil {
+		t.Errorf("MSFT still held after selling all shares: %+v", h)
+	}
+	if got := a.realizedStockPL(); !got.Equal(dec("-100")) {
+		t.Errorf("realized stock P/L = %s, want -100", got)
+	}
+	txs, _ := a.db.GetTransactions(ctx)
+	if last := txs[len(txs)-1]; last.Kind != db.TxSell || !last.Amount.Equal(dec("12000")) {
+		t.Errorf("last transaction = %+v, want a $12,000 SELL", last)
+	}
+}
+
+func TestRealizedPL(t *testing.T) {
+	dec := decimal.RequireFromString
+	tests := []struct {
+		name string
+		o    db.Option
+		want string
+	}{
+		{"expired sold put", db.Option{Action: "SELL", Status: "EXPIRED", Quantity: 1, Multiplier: db.DefaultMultiplier, Premium: dec("2.05"), OpenFee: dec("0.65")}, "204.35"},
+		{"sold call bought back", db.Option{Action: "SELL", Status: "CLOSED", Quantity: 2, Multiplier: db.DefaultMultiplier, Premium: dec("1.50"), OpenFee: dec("1.30"),
+			ClosePremium: decimal.NullDecimal{Decimal: dec("0.40"), Valid: true}, CloseFee: decimal.NullDecimal{Decimal: dec("1.30"), Valid: true}}, "217.4"},
+		{"bought put sold", db.Option{Action: "BUY", Status: "CLOSED", Quantity: 1, Multiplier: db.DefaultMultiplier, Premium: dec("3.00"), OpenFee: dec("0.65"),
+			ClosePremium: decimal.NullDecimal{Decimal: dec("1.00"), Valid: true}, CloseFee: decimal.NullDecimal{Decimal: dec("0.65"), Valid: true}}, "-201.3"},
+	}
+	for _, tt := range tests {
+		if got := realizedPL(tt.o).String(); got != tt.want {
+			t.Errorf("%s: realizedPL = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHoldingsScroll(t *testing.T) {
+	a := newRenderApp(t)
+	ctx := context.Background()
+	for i := range 25 {
+		ticker := "T" + strconv.Itoa(i+10)
+		if err := a.db.AddHolding(ctx, ticker, decimal.NewFromInt(10), decimal.NewFromInt(20), db.DefaultCurrency, renderFixture, decimal.NullDecimal{}, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a.refreshData()
+	a.updateLayout()
+
+	screen := renderText(t, a.mainFlex, 150, 60)
+	if !strings.Contains(screen, "Expiry Timeline") {
+		t.Errorf("options section squeezed off screen:\n%s", screen)
+	}
+	if !strings.Contains(a.summaryView.text.GetTitle(), "Row 1 of 28, 10 shown") {
+		t.Errorf("title = %q, want row count", a.summaryView.text.GetTitle())
+	}
+
+	a.holdingsView.table.Select(28, 0)
+	if !strings.Contains(a.summaryView.text.GetTitle(), "Row 28 of 28") {
+		t.Errorf("title after scrolling = %q", a.summaryView.text.GetTitle())
+	}
+	last := a.book.holdings[a.holdingsView.rows[27]].Ticker
+	if screen := renderText(t, a.mainFlex, 150, 60); !strings.Contains(screen, last) {
+		t.Errorf("last holding %s not scrolled into view:\n%s", last, screen)
+	}
+}
+
+func TestRetryFailedQuotes(t *testing.T) {
+	a := newRenderApp(t)
+	market := a.yahoo.(*fake.Market)
+	msft := market.Quotes["MSFT"]
+	delete(market.Quotes, "MSFT")
+	a.refreshData()
+	if want := "Failed: MSFT (F to retry)"; !strings.Contains(a.summaryView.text.GetText(true), want) {
+		t.Fatalf("summary %q missing %q", a.summaryView.text.GetText(true), want)
+	}
+
+	// Still failing: the chip stays
+	a.retryFailedQuotes()
+	if !slices.Equal(a.book.failedQuotes, []string{"MSFT"}) {
+		t.Errorf("failedQuotes = %v, want [MSFT]", a.book.failedQuotes)
+	}
+
+	market.Quotes["MSFT"] = msft
+	a.retryFailedQuotes()
+	if len(a.book.failedQuotes) != 0 || strings.Contains(a.summaryView.text.GetText(true), "Failed:") {
+		t.Errorf("chip still shown after successful retry: %q", a.summaryView.text.GetText(true))
+	}
+	if got := a.book.quotes["MSFT"].Price; got != 452.35 {
+		t.Errorf("MSFT price = %v, want 452.35", got)
+	}
+}
+
+func TestFailedChip(t *testing.T) {
+	if got := failedChip([]string{"A", "B"}); got != "Failed: A, B" {
+		t.Errorf("failedChip = %q", got)
+	}
+	if got := failedChip([]string{"A", "B", "C", "D", "E"}); got != "Failed: A, B, C +2 more" {
+		t.Errorf("failedChip = %q", got)
+	}
+}
+
+func TestReminders(t *testing.T) {
+	a := newRenderApp(t)
+	ctx := context.Background()
+	aapl := a.book.options[slices.IndexFunc(a.book.options, func(o db.Option) bool { return o.Ticker == "AAPL" })]
+	a.db.AddReminder(ctx, aapl.ID, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), "evaluate roll")
+	a.db.AddReminder(ctx, aapl.ID, time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), "check earnings")
+	a.refreshData()
+
+	if got := a.dueReminders(); got != 1 {
+		t.Errorf("dueReminders = %d, want 1", got)
+	}
+	if !slices.ContainsFunc(a.alerts, func(al query.Alert) bool { return strings.HasSuffix(al.Message, ": evaluate roll") }) {
+		t.Errorf("no alert for the due reminder: %v", a.alerts)
+	}
+	if status := a.statusBar.GetText(true); !strings.Contains(status, "1 reminder(s) due") {
+		t.Errorf("status bar %q missing due reminder", status)
+	}
+
+	a.showRemindersView()
+	_, page := a.pages.GetFrontPage()
+	table := page.(*tview.Flex).GetItem(0).(*tview.Table)
+	if got := table.GetCell(1, 2).Text; got != " evaluate roll " {
+		t.Errorf("first inbox row = %q, want the due reminder", got)
+	}
+	table.GetInputCapture()(tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone))
+
+	reminders, _ := a.db.GetReminders(ctx)
+	if len(reminders) != 1 || reminders[0].Note != "check earnings" {
+		t.Errorf("reminders after dismissing = %v", reminders)
+	}
+	if got := a.dueReminders(); got != 0 {
+		t.Errorf("dueReminders after dismissing = %d, want 0", got)
+	}
+}
+
+func TestExternalOptionLeavesCashAndHoldings(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+	a := newTestApp(store, market)
+
+	lastWeek := time.Now().AddDate(0, 0, -7)
+	store.SetAvailableCash(ctx, decimal.NewFromInt(50000))
+	store.AddHolding(ctx, "AAPL", decimal.NewFromInt(100), decimal.NewFromInt(150), db.DefaultCurrency, lastWeek, decimal.NullDecimal{}, "") // Pays 15000
+	store.AddExternalOption(ctx, "AAPL", "PUT", "SELL", decimal.NewFromInt(200), lastWeek, 2, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, decimal.NewFromInt(2), decimal.NewFromInt(1), "")
+	store.AddExternalOption(ctx, "AAPL", "CALL", "SELL", decimal.NewFromInt(250), time.Now().AddDate(0, 1, 0), 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, decimal.NewFromInt(3), decimal.NewFromInt(1), "")
+	market.SetPrice("AAPL", 190)
+
+	// The put expired ITM and is assigned; the call is bought back
+	a.processExpiredOptions(ctx)
+	options, _ := store.GetActiveOptions(ctx)
+	call := options[slices.IndexFunc(options, func(o db.Option) bool { return o.OptionType == "CALL" })]
+	if err := store.CloseOption(ctx, call.ID, decimal.NewFromInt(1), decimal.NewFromInt(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	options, _ = store.GetActiveOptions(ctx)
+	statuses := map[string]string{}
+	for _, o := range options {
+		statuses[o.OptionType] = o.Status
+		if !o.External {
+			t.Errorf("%s lost its external flag", o.OptionType)
+		}
+	}
+	if statuses["PUT"] != "ASSIGNED" || statuses["CALL"] != "CLOSED" {
+		t.Errorf("statuses = %v, want PUT assigned and CALL closed", statuses)
+	}
+	if cash, _ := store.GetAvailableCash(ctx); !cash.Equal(decimal.NewFromInt(35000)) {
+		t.Errorf("cash = %s, want 35000 untouched", cash)
+	}
+	holdings, _ := store.GetHoldings(ctx)
+	if len(holdings) != 1 || !holdings[0].Quantity.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("holdings = %+v, want 100 AAPL untouched", holdings)
+	}
+}
+
+func TestContractMultiplier(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+
+	store.SetAvailableCash(ctx, decimal.NewFromInt(50000))
+	store.AddOption(ctx, "XYZ", "PUT", "SELL", decimal.NewFromInt(20), time.Now().AddDate(0, 1, 0), 3, 10, db.SettlementPhysical, db.DefaultCurrency, decimal.NewFromInt(2), decimal.NewFromInt(1), "") // Mini contracts
+	if cash, _ := store.GetAvailableCash(ctx); !cash.Equal(decimal.NewFromInt(50059)) {
+		t.Errorf("cash after opening = %s, want 50059 (2 x 3 x 10 less fee)", cash)
+	}
+
+	options, _ := store.GetActiveOptions(ctx)
+	if options[0].Multiplier != 10 || !options[0].Shares().Equal(decimal.NewFromInt(30)) {
+		t.Fatalf("option = %+v, want 30 shares at a multiplier of 10", options[0])
+	}
+	if err := store.AssignOption(ctx, options[0].ID, decimal.Zero); err != nil {
+		t.Fatal(err)
+	}
+	if cash, _ := store.GetAvailableCash(ctx); !cash.Equal(decimal.NewFromInt(49459)) {
+		t.Errorf("cash after assignment = %s, want 49459", cash)
+	}
+	holdings, _ := store.GetHoldings(ctx)
+	if len(holdings) != 1 || !holdings[0].Quantity.Equal(decimal.NewFromInt(30)) {
+		t.Errorf("holdings = %+v, want 30 XYZ", holdings)
+	}
+}
+
+func TestCashSettledAssignment(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+	a := newTestApp(store, market)
+
+	lastWeek := time.Now().AddDate(0, 0, -7)
+	store.SetAvailableCash(ctx, decimal.NewFromInt(50000))
+	store.AddOption(ctx, "SPX", "PUT", "SELL", decimal.NewFromInt(200), lastWeek, 1, db.DefaultMultiplier, db.SettlementCash, db.DefaultCurrency, decimal.NewFromInt(2), decimal.Zero, "")
+	market.SetPrice("SPX", 190) // ITM by $10
+
+	a.processExpiredOptions(ctx)
+
+	options, _ := store.GetActiveOptions(ctx)
+	if len(options) != 1 || options[0].Status != "ASSIGNED" {
+		t.Fatalf("options = %+v", options)
+	}
+	if cp := options[0].ClosePremium; !cp.Valid || !cp.Decimal.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("close premium = %v, want the $10 intrinsic value", cp)
+	}
+	if holdings, _ := store.GetHoldings(ctx); len(holdings) != 0 {
+		t.Errorf("holdings = %+v, want none delivered", holdings)
+	}
+
+	// 50000 + 200 premium - 1000 settlement
+	if cash, _ := store.GetAvailableCash(ctx); !cash.Equal(decimal.NewFromInt(49200)) {
+		t.Errorf("cash = %s, want 49200", cash)
+	}
+	premiums, _ := store.GetPremiumsBetween(ctx, lastWeek.AddDate(0, 0, -30), time.Now().AddDate(0, 0, 1))
+	if !premiums.NetPL.Equal(decimal.NewFromInt(-800)) {
+		t.Errorf("net premium = %s, want -800 after settlement", premiums.NetPL)
+	}
+	if pl := realizedPL(options[0]); !pl.Equal(decimal.NewFromInt(-800)) {
+		t.Errorf("realized P/L = %s, want -800", pl)
+	}
+}
+
+func TestPriceHistorySync(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+
+	now := time.Now()
+	daysAgo := func(n int) int64 { return now.AddDate(0, 0, -n).Unix() }
+	store.AddHolding(ctx, "AAPL", decimal.NewFromInt(10), decimal.NewFromInt(150), db.DefaultCurrency, now, decimal.NullDecimal{}, "")
+	store.AddCSPWatchTicker(ctx, "TSLA", "")
+	for _, ticker := range []string{"AAPL", "TSLA", "SPY", "QQQ"} {
+		market.Series[ticker] = []analytics.PricePoint{{Time: daysAgo(3), Close: 100}, {Time: daysAgo(2), Close: 101}}
+	}
+
+	result, err := syncPriceHistory(ctx, store, market, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Tickers != 4 || result.Bars != 8 {
+		t.Errorf("first sync = %+v, want 8 bars for 4 tickers", result)
+	}
+
+	// Only the day since is downloaded next time
+	market.Series["AAPL"] = append(market.Series["AAPL"], analytics.PricePoint{Time: daysAgo(1), Close: 102})
+	if result, _ = syncPriceHistory(ctx, store, market, now); result.Tickers != 1 || result.Bars != 1 {
+		t.Errorf("second sync = %+v, want 1 bar for AAPL", result)
+	}
+
+	// History is now read from the store, not the market
+	market.Series["AAPL"] = nil
+	closes, err := withStoredHistory(store, market).FetchPriceHistory("AAPL")
+	if err != nil || !slices.Equal(closes, []float64{100, 101, 102}) {
+		t.Errorf("stored closes = %v, %v, want [100 101 102]", closes, err)
+	}
+	if _, err := withStoredHistory(store, market).FetchPriceHistory("NVDA"); err == nil {
+		t.Error("an unsynced ticker did not fall back to the market")
+	}
+}
+
+func TestAccounts(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+	a := newTestApp(store, market)
+
+	store.AddHolding(ctx, "AAPL", decimal.NewFromInt(10), decimal.NewFromInt(150), db.DefaultCurrency, time.Now(), decimal.NullDecimal{}, "")
+	store.SetAvailableCash(ctx, decimal.NewFromInt(1000))
+	if err := store.AddAccount(ctx, "IRA"); err != nil {
+		t.Fatal(err)
+	}
+	accounts, _ := store.GetAccounts(ctx)
+	if len(accounts) != 1 || accounts[0].Name != "IRA" {
+		t.Fatalf("accounts = %+v, want IRA", accounts)
+	}
+	ira := store.ForAccount(accounts[0].ID)
+	ira.AddHolding(ctx, "MSFT", decimal.NewFromInt(2), decimal.NewFromInt(300), db.DefaultCurrency, time.Now(), decimal.NullDecimal{}, "")
+	ira.SetAvailableCash(ctx, decimal.NewFromInt(500))
+
+	tickers, ok := a.loadData(ctx, false)
+	if !ok {
+		t.Fatal("loadData failed")
+	}
+	if len(a.book.holdings) != 1 || a.book.holdings[0].Ticker != "AAPL" || !a.book.cash.Equal(decimal.NewFromInt(1000)) {
+		t.Errorf("main account = %v, cash %s, want AAPL and 1000", a.book.holdings, a.book.cash)
+	}
+	if !slices.Contains(tickers, "MSFT") {
+		t.Errorf("quote tickers = %v, want the IRA's MSFT too", tickers)
+	}
+	if !a.totalCash().Equal(decimal.NewFromInt(1500)) {
+		t.Errorf("total cash = %s, want 1500", a.totalCash())
+	}
+	// Without a quote the IRA's holdings count at cost
+	if got := a.book.bookValue(a.book.accountBooks[0]); !got.Equal(decimal.NewFromInt(1100)) {
+		t.Errorf("IRA value = %s, want 1100", got)
+	}
+
+	a.setAccount(accounts[0].ID)
+	a.loadData(ctx, false)
+	if len(a.book.holdings) != 1 || a.book.holdings[0].Ticker != "MSFT" || !a.book.cash.Equal(decimal.NewFromInt(500)) {
+		t.Errorf("IRA = %v, cash %s, want MSFT and 500", a.book.holdings, a.book.cash)
+	}
+	if len(a.book.accountBooks) != 1 || a.book.accountBooks[0].Name != db.MainAccountName {
+		t.Errorf("other accounts = %+v, want Main", a.book.accountBooks)
+	}
+}
+
+func TestTransactionLedger(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	expiry := time.Now().AddDate(0, 0, 30)
+
+	store.SetAvailableCash(ctx, decimal.NewFromInt(10000))
+	store.AddHolding(ctx, "AAPL", decimal.NewFromInt(10), decimal.NewFromInt(150), db.DefaultCurrency, time.Now(), decimal.NullDecimal{}, "")
+	store.AddOption(ctx, "AAPL", "PUT", "SELL", decimal.NewFromInt(140), expiry, 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, decimal.NewFromInt(2), decimal.RequireFromString("0.65"), "")
+	options, _ := store.GetActiveOptions(ctx)
+	store.AssignOption(ctx, options[0].ID, decimal.NewFromInt(5))
+	store.AddInterestPayment(ctx, decimal.NewFromInt(12), time.Now(), "")
+
+	txs, _ := store.GetTransactions(ctx)
+	var kinds []string
+	for _, tx := range txs {
+		kinds = append(kinds, tx.Kind)
+	}
+	want := []string{db.TxAdjustment, db.TxBuy, db.TxPremium, db.TxFee, db.TxAssignment, db.TxFee, db.TxInterest}
+	if !slices.Equal(kinds, want) {
+		t.Fatalf("ledger kinds = %v, want %v", kinds, want)
+	}
+
+	// Each balance follows from the last, ending at the cash figure
+	balance := decimal.Zero
+	for _, tx := range txs {
+		balance = balance.Add(tx.Amount)
+		if !tx.Balance.Equal(balance) {
+			t.Errorf("%s balance = %s, want %s", tx.Kind, tx.Balance, balance)
+		}
+	}
+	cash, _ := store.GetAvailableCash(ctx)
+	if !cash.Equal(balance) || !cash.Equal(decimal.RequireFromString("-5293.65")) {
+		t.Errorf("cash = %s, ledger balance %s, want -5293.65", cash, balance)
+	}
+}
+
+func TestEventBus(t *testing.T) {
+	var bus eventBus
+	var got []string
+	bus.subscribe(eventDataLoaded, func() { got = append(got, "first") })
+	bus.subscribe(eventDataLoaded, func() { got = append(got, "second") })
+	bus.subscribe(eventHoldingsChanged, func() { got = append(got, "holdings") })
+
+	bus.publish(eventDataLoaded)
+	if !slices.Equal(got, []string{"first", "second"}) {
+		t.Errorf("handlers ran %v, want first then second", got)
+	}
+}
+
+func TestSummaryFollowsHoldings(t *testing.T) {
+	a := newRenderApp(t)
+
+	// Redrawing the holdings table is enough for the summary to follow
+	a.book.cash = decimal.NewFromInt(1234)
+	a.holdingsView.update()
+	if got := a.summaryView.text.GetText(true); !strings.Contains(got, "Cash: $1,234.00") {
+		t.Errorf("summary = %q, want the new cash", got)
+	}
+}
+
+func TestMultiCurrency(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	store := a.db.(*fake.Store)
+	market := a.yahoo.(*fake.Market)
+	dec := decimal.RequireFromString
+
+	store.AddHolding(ctx, "SAP.DE", dec("10"), dec("180"), db.DefaultCurrency, renderFixture, decimal.NullDecimal{}, "")
+	store.AddOption(ctx, "SAP.DE", "CALL", "SELL", dec("220"), renderFixture.AddDate(0, 1, 0), 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, dec("5"), decimal.Zero, "")
+	store.SetTickerCurrency(ctx, "SAP.DE", "EUR")
+	market.Quotes["SAP.DE"] = yahoo.Quote{Symbol: "SAP.DE", Price: 200, Currency: "EUR"}
+	market.SetPrice("EURUSD=X", 1.10)
+	net := a.book.premiums.NetPL
+	value := a.holdingsView.value
+
+	a.refreshData()
+	cell := func(col int) string { return strings.TrimSpace(a.holdingsView.table.GetCell(4, col).Text) }
+	if cell(0) != "SAP.DE" || cell(2) != "€180.00" || cell(4) != "€200.00" {
+		t.Errorf("SAP.DE row = %s %s %s, want prices in euros", cell(0), cell(2), cell(4))
+	}
+	if cell(5) != "$2,200.00" || cell(6) != "+$220.00" {
+		t.Errorf("SAP.DE value, P/L = %s, %s; want $2,200.00, +$220.00", cell(5), cell(6))
+	}
+	if want := value.Add(dec("2200")); !a.holdingsView.value.Equal(want) {
+		t.Errorf("holdings value = %s, want %s", a.holdingsView.value, want)
+	}
+	if want := net.Add(dec("550")); !a.book.premiums.NetPL.Equal(want) {
+		t.Errorf("net premium = %s, want %s with the euro call", a.book.premiums.NetPL, want)
+	}
+
+	// Without a rate, amounts count 1:1 and the summary says so
+	store.AddHolding(ctx, "NESN.SW", dec("10"), dec("90"), db.DefaultCurrency, renderFixture, decimal.NullDecimal{}, "")
+	store.SetTickerCurrency(ctx, "NESN.SW", "CHF")
+	a.refreshData()
+	if got := a.summaryView.text.GetText(true); !strings.Contains(got, "no USD rate for CHF") {
+		t.Errorf("summary = %q, want the missing rate flagged", got)
+	}
+
+	// In a euro base, dollar holdings are converted instead; AAPL is valued at
+	// its call's strike
+	a.book.baseCurrency = "EUR"
+	market.SetPrice("USDEUR=X", 0.5)
+	a.refreshData()
+	if got := strings.TrimSpace(a.holdingsView.table.GetCell(5, 5).Text); got != "€2,000.00" {
+		t.Errorf("SAP.DE value in EUR = %s, want €2,000.00", got)
+	}
+	if got := strings.TrimSpace(a.holdingsView.table.GetCell(1, 5).Text); got != "€23,000.00" {
+		t.Errorf("AAPL value in EUR = %s, want €23,000.00", got)
+	}
+	if got := a.summaryView.text.GetText(true); !strings.Contains(got, "Total: €") {
+		t.Errorf("summary = %q, want totals in euros", got)
+	}
+}
+
+func TestForeignTradeCash(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+	dec := decimal.RequireFromString
+	store.SetAvailableCash(ctx, dec("10000"))
+
+	// Without a saved rate a euro trade is refused rather than booked 1:1
+	err := store.AddHolding(ctx, "SAP.DE", dec("10"), dec("180"), "EUR", renderFixture, decimal.NullDecimal{}, "")
+	if !errors.Is(err, db.ErrNoFXRate) {
+		t.Fatalf("AddHolding without a rate = %v, want ErrNoFXRate", err)
+	}
+
+	market.SetPrice("EURUSD=X", 1.10)
+	rate, err := ensureFXRate(ctx, store, market, "EUR")
+	if err != nil || !rate.Equal(dec("1.1")) {
+		t.Fatalf("ensureFXRate = %s, %v; want 1.1", rate, err)
+	}
+	if err := store.AddHolding(ctx, "SAP.DE", dec("10"), dec("180"), "EUR", renderFixture, decimal.NullDecimal{}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddOption(ctx, "SAP.DE", "CALL", "SELL", dec("220"), renderFixture.AddDate(0, 1, 0), 1, db.DefaultMultiplier, db.SettlementPhysical, "EUR", dec("5"), decimal.Zero, ""); err != nil {
+		t.Fatal(err)
+	}
+	// 10000 - 10 x 180 x 1.10 + 100 x 5 x 1.10
+	if cash, _ := store.GetAvailableCash(ctx); !cash.Equal(dec("8570")) {
+		t.Errorf("cash = %s, want 8570 with the euro amounts converted", cash)
+	}
+
+	options, _ := store.GetActiveOptions(ctx)
+	if err := store.CloseOption(ctx, options[0].ID, dec("2"), decimal.Zero); err != nil {
+		t.Fatal(err)
+	}
+	if cash, _ := store.GetAvailableCash(ctx); !cash.Equal(dec("8350")) {
+		t.Errorf("cash after buying back = %s, want 8350", cash)
+	}
+}
+
+func TestStatusLine(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+	store.AddHolding(ctx, "AAPL", decimal.NewFromInt(10), decimal.NewFromInt(100), db.DefaultCurrency, time.Now(), decimal.NullDecimal{}, "")
+	store.SetAvailableCash(ctx, decimal.NewFromInt(1000))
+	market.Quotes["AAPL"] = yahoo.Quote{Symbol: "AAPL", Price: 110, Change: 2}
+	now := time.Now()
+
+	line, err := statusText(ctx, store, market, "plain", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 10 x 110 + 1000 cash, up 10 x 2 on 1080
+	if !strings.HasPrefix(line, "$2,100.00 ▲ +$20.00 (+1.85%) · ") || !strings.HasSuffix(line, " prem $0.00") {
+		t.Errorf("line = %q", line)
+	}
+
+	// Served from the quote cache saved by the first run
+	market.Quotes["AAPL"] = yahoo.Quote{Symbol: "AAPL", Price: 90, Change: -5}
+	calls := market.Calls
+	line, err = statusText(ctx, store, market, "tmux", now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if market.Calls != calls || !strings.Contains(line, "#[fg=green]▲ +$20.00 (+1.85%)#[default]") {
+		t.Errorf("cached line = %q, calls %d -> %d", line, calls, market.Calls)
+	}
+
+	// Refreshed once the cache is stale
+	line, _ = statusText(ctx, store, market, "tmux", now.Add(statusMaxAge+time.Minute))
+	if !strings.Contains(line, "#[fg=red]▼ -$50.00") {
+		t.Errorf("refreshed line = %q", line)
+	}
+
+	if _, err := statusText(ctx, store, market, "xml", now); err == nil {
+		t.Error("unknown style accepted")
+	}
+}
+
+func TestStatusLineConvertsCurrencies(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+	store.SetFXRates(ctx, db.FXRates{Base: db.DefaultCurrency, Rates: map[string]decimal.Decimal{"CAD": decimal.RequireFromString("0.75")}})
+	store.AddHolding(ctx, "AAPL", decimal.NewFromInt(10), decimal.NewFromInt(100), db.DefaultCurrency, time.Now(), decimal.NullDecimal{}, "")
+	store.AddHolding(ctx, "SHOP", decimal.NewFromInt(10), decimal.NewFromInt(100), "CAD", time.Now(), decimal.NullDecimal{}, "")
+	store.SetAvailableCash(ctx, decimal.Zero)
+	market.Quotes["AAPL"] = yahoo.Quote{Symbol: "AAPL", Price: 110, Change: 2}
+	market.Quotes["SHOP"] = yahoo.Quote{Symbol: "SHOP", Price: 120, Change: 4}
+
+	line, err := statusText(ctx, store, market, "plain", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1,100 + 1,200 CAD at 0.75, up 20 + 40 CAD at 0.75 on 1,950
+	if !strings.HasPrefix(line, "$2,000.00 ▲ +$50.00 (+2.56%) · ") {
+		t.Errorf("line = %q", line)
+	}
+}
+
+func TestImportOptionHistory(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	a := newTestApp(store, fake.NewMarket())
+	store.SetAvailableCash(ctx, decimal.NewFromInt(1000))
+
+	text := "ticker,type,action,strike,expiry,quantity,premium,open_fee,opened,outcome,close_premium\n" +
+		"AAPL,PUT,SELL,150,2024-03-15,2,1.25,1.30,2024-02-01,EXPIRED,\n" +
+		"MSFT,CALL,SELL,400,2024-03-15,1,3.10,0.65,2024-02-05,CLOSED,3.50\n"
+	trades, _, err := importer.ParseOptionTrades(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	added, duplicates, err := a.importOptionTrades(ctx, trades)
+	if err != nil || added != 2 || duplicates != 0 {
+		t.Fatalf("import = %d, %d, %v", added, duplicates, err)
+	}
+
+	// History counts in premium stats by open date, without moving cash
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(1, 0, 0)
+	premiums, _ := store.GetPremiumsBetween(ctx, from, to)
+	// 250 + 310 premium - 1.95 fees - 350 buyback
+	if !premiums.NetPL.Equal(decimal.RequireFromString("208.05")) {
+		t.Errorf("net premium = %s, want 208.05", premiums.NetPL)
+	}
+	if cash, _ := store.GetAvailableCash(ctx); !cash.Equal(decimal.NewFromInt(1000)) {
+		t.Errorf("cash = %s, want 1000", cash)
+	}
+
+	a.book.options, _ = store.GetActiveOptions(ctx)
+	if wins, settled := a.optionsView.premiumWinRate(from, to); wins != 1 || settled != 2 {
+		t.Errorf("win rate = %d/%d, want 1/2", wins, settled)
+	}
+
+	// Importing the same file again adds nothing
+	added, duplicates, _ = a.importOptionTrades(ctx, trades)
+	if added != 0 || duplicates != 2 {
+		t.Errorf("reimport = %d added, %d duplicates", added, duplicates)
+	}
+}
+
+func TestImportOptionHistoryCurrency(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	a := newTestApp(store, fake.NewMarket())
+	a.book.holdings = []db.Holding{{Ticker: "TD", Currency: "CAD"}}
+
+	// The currency column wins; without it the ticker's currency is used
+	text := "ticker,type,action,strike,expiry,quantity,premium,opened,outcome,currency\n" +
+		"SHOP,PUT,SELL,90,2024-03-15,1,2.40,2024-02-01,EXPIRED,CAD\n" +
+		"TD,PUT,SELL,75,2024-03-15,1,0.90,2024-02-01,EXPIRED,\n" +
+		"AAPL,PUT,SELL,150,2024-03-15,1,1.25,2024-02-01,EXPIRED,\n"
+	trades, _, err := importer.ParseOptionTrades(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := a.importOptionTrades(ctx, trades); err != nil {
+		t.Fatal(err)
+	}
+
+	options, _ := store.GetActiveOptions(ctx)
+	got := make(map[string]string)
+	for _, o := range options {
+		got[o.Ticker] = o.Currency
+	}
+	want := map[string]string{"SHOP": "CAD", "TD": "CAD", "AAPL": db.DefaultCurrency}
+	if !maps.Equal(got, want) {
+		t.Errorf("currencies = %v, want %v", got, want)
+	}
+}
+
+func TestImportHoldings(t *testing.T) {
+	ctx := context.Background()
+	dec := decimal.RequireFromString
+	a := newRenderApp(t)
+	store := a.db.(*fake.Store)
+	a.yahoo.(*fake.Market).SetPrice("KO", 60)
+	cash, _ := store.GetAvailableCash(ctx)
+
+	text := "symbol,shares,avg cost,date\n" +
+		"AAPL,200,200,2024-01-02\n" +
+		"KO,50,58.20,\n" +
+		"ZZZQ,5,10,2024-01-02\n" +
+		"MSFT,ten,400,\n"
+	rows, warnings, err := importer.ParseHoldings(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// With Yahoo unreachable nothing is taken as unknown
+	market := a.yahoo.(*fake.Market)
+	market.Err = errors.New("offline")
+	if unknown, err := a.unknownTickers(rows); err == nil {
+		t.Errorf("tickers checked offline: unknown = %v", unknown)
+	}
+	market.Err = nil
+
+	unknown, err := a.unknownTickers(rows)
+	if err != nil || len(unknown) != 1 || !unknown["ZZZQ"] {
+		t.Errorf("unknown tickers = %v, %v", unknown, err)
+	}
+
+	a.showHoldingsImportPreview(rows, warnings, unknown)
+	layout := a.pages.GetPage("holdings-preview").(*tview.Flex).GetItem(1).(*tview.Flex).GetItem(1).(*tview.Flex)
+	table := layout.GetItem(0).(*tview.Table)
+	for row, want := range []string{"add to held", "new", "skip: unknown ticker"} {
+		if got := strings.TrimSpace(table.GetCell(row+1, 5).Text); got != want {
+			t.Errorf("row %d action = %q, want %q", row+1, got, want)
+		}
+	}
+	pressButton(layout.GetItem(2).(*tview.Form), "Import 2")
+
+	// Merged at the average cost and added, without moving cash
+	holdings, _ := store.GetHoldings(ctx)
+	if len(holdings) != 4 || a.pages.HasPage("holdings-preview") {
+		t.Fatalf("holdings = %+v", holdings)
+	}
+	for _, h := range holdings {
+		if h.Ticker == "AAPL" && (!h.Quantity.Equal(dec("400")) || !h.AvgCost.Equal(dec("175.125"))) {
+			t.Errorf("AAPL = %s @ %s, want 400 @ 175.125", h.Quantity, h.AvgCost)
+		}
+		if h.Ticker == "KO" && !h.Quantity.Equal(dec("50")) {
+			t.Errorf("KO quantity = %s, want 50", h.Quantity)
+		}
+	}
+	if after, _ := store.GetAvailableCash(ctx); !after.Equal(cash) {
+		t.Errorf("cash = %s, want %s as before the import", after, cash)
+	}
+	if text := a.statusBar.GetText(true); !strings.Contains(text, "Imported 2 holding(s)") || !strings.Contains(text, "2 row(s) skipped") {
+		t.Errorf("status bar %q", text)
+	}
+}
+
+func TestSectorAllocation(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+	a := newTestApp(store, market)
+	now := time.Now()
+	for _, h := range []struct {
+		ticker string
+		qty    int64
+	}{{"AAPL", 50}, {"MSFT", 20}, {"XOM", 10}, {"SPY", 10}} {
+		store.AddHolding(ctx, h.ticker, decimal.NewFromInt(h.qty), decimal.NewFromInt(100), db.DefaultCurrency, now, decimal.NullDecimal{}, "")
+	}
+	a.book.holdings, _ = store.GetHoldings(ctx)
+	market.Profiles["AAPL"] = &yahoo.Profile{Sector: "Technology", Industry: "Consumer Electronics"}
+	market.Profiles["MSFT"] = &yahoo.Profile{Sector: "Technology", Industry: "Software - Infrastructure"}
+	market.Profiles["SPY"] = &yahoo.Profile{Sector: yahoo.ETFSector}
+	// XOM has no data
+
+	profiles := a.tickerProfiles(ctx, a.book.holdings)
+	if profiles["AAPL"].Industry != "Consumer Electronics" || len(profiles) != 3 {
+		t.Fatalf("profiles = %+v", profiles)
+	}
+	// Cached, so only the ticker Yahoo could not classify is fetched again
+	calls := market.Calls
+	a.tickerProfiles(ctx, a.book.holdings)
+	if market.Calls != calls+1 {
+		t.Errorf("profile fetches = %d, want 1", market.Calls-calls)
+	}
+
+	// A sector assigned by hand wins
+	values, total, _ := a.book.positionValues()
+	sectors := sectorAllocations(a.book.holdings, values, total, profiles, map[string]string{"XOM": "Energy"})
+	var names []string
+	for _, s := range sectors {
+		names = append(names, s.Name+" "+s.Weight.StringFixed(0))
+	}
+	if want := []string{"Technology 78", "ETF 11", "Energy 11"}; !slices.Equal(names, want) {
+		t.Errorf("sectors = %v, want %v", names, want)
+	}
+	if tech := sectors[0]; len(tech.Industries) != 2 || tech.Industries[0].Name != "Consumer Electronics" {
+		t.Errorf("technology industries = %+v", tech.Industries)
+	}
+
+	report := a.sectorReport(sectors)
+	if !strings.Contains(report, "[red]! Technology is 77.8% of holdings, over 40%") {
+		t.Errorf("report missing concentration warning:\n%s", report)
+	}
+}
+
+func TestDuplicateOption(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	store := a.db.(*fake.Store)
+
+	a.showAddOptionForm(nil, nil)
+	form := modalForm(t, a, "addoption")
+	input := func(i int) *tview.InputField { return form.GetFormItem(i).(*tview.InputField) }
+	input(0).SetText("AAPL")
+	input(3).SetText("230")
+	input(4).SetText("2026-03-06")
+	input(6).SetText("2.75")
+
+	// The same short call is already open, so Save asks first
+	before := len(a.book.options)
+	pressButton(form, "Save")
+	if !a.pages.HasPage("duplicate") || len(a.book.options) != before {
+		t.Fatalf("duplicate not caught: page shown %v, %d options", a.pages.HasPage("duplicate"), len(a.book.options))
+	}
+	a.pages.RemovePage("duplicate")
+
+	// Bought, or at another strike, is not a duplicate
+	dup := a.duplicateOption("AAPL", "CALL", "SELL", decimal.NewFromInt(230), time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC), false)
+	if dup == nil || dup.Quantity != 2 {
+		t.Fatalf("duplicate = %+v", dup)
+	}
+	if a.duplicateOption("AAPL", "CALL", "BUY", dup.Strike, dup.ExpiryDate, false) != nil ||
+		a.duplicateOption("AAPL", "CALL", "SELL", decimal.NewFromInt(235), dup.ExpiryDate, false) != nil {
+		t.Error("different contract reported as duplicate")
+	}
+
+	// Merging averages the premium and credits the added contract
+	cash, _ := store.GetAvailableCash(ctx)
+	if err := store.AddToOption(ctx, dup.ID, 1, decimal.RequireFromString("2.75"), decimal.RequireFromString("0.65"), ""); err != nil {
+		t.Fatal(err)
+	}
+	options, _ := store.GetActiveOptions(ctx)
+	for _, o := range options {
+		if o.ID != dup.ID {
+			continue
+		}
+		// (2 x 1.85 + 2.75) / 3
+		if o.Quantity != 3 || !o.Premium.Equal(decimal.RequireFromString("2.15")) || !o.OpenFee.Equal(decimal.RequireFromString("1.95")) {
+			t.Errorf("merged option = %d @ %s, fee %s", o.Quantity, o.Premium, o.OpenFee)
+		}
+	}
+	if after, _ := store.GetAvailableCash(ctx); !after.Sub(cash).Equal(decimal.RequireFromString("274.35")) {
+		t.Errorf("cash change = %s, want 274.35", after.Sub(cash))
+	}
+}
+
+func TestEquityCurve(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	store := a.db.(*fake.Store)
+
+	// Refreshing recorded today's snapshot with the premium of the open short options
+	snapshots, _ := store.GetPortfolioSnapshots(ctx, time.Time{})
+	if len(snapshots) != 1 || !snapshots[0].OptionsCredit.Equal(a.totalOptionsCredit()) || !a.totalOptionsCredit().Equal(decimal.NewFromInt(2295)) {
+		t.Fatalf("snapshots = %+v, options credit %s", snapshots, a.totalOptionsCredit())
+	}
+
+	for _, s := range []struct {
+		day   int
+		value int64
+	}{{10, 100000}, {20, 90000}} {
+		store.Now = func() time.Time { return time.Date(2026, 2, s.day, 15, 0, 0, 0, time.UTC) }
+		store.RecordPortfolioSnapshot(ctx, decimal.NewFromInt(s.value), decimal.NewFromInt(20000), decimal.Zero)
+	}
+	start := renderFixture.AddDate(0, -1, 0)
+	snapshots, _ = store.GetPortfolioSnapshots(ctx, start)
+	report := a.equityReport(equityRanges[0], snapshots, start, renderFixture)
+	for _, want := range []string{"[black:teal] 1M [-:-]", "[teal]Feb 10:[white] $120,000.00", "[teal]Options credit:[white] $2,295.00", "3 snapshot(s)"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+	// Nothing is plotted before the first snapshot, and the 90,000 dip is below the start
+	chart := a.renderEquityChart(snapshots, start, renderFixture)
+	lines := strings.Split(chart, "\n")
+	if len(lines) != equityChartHeight+2 || !strings.Contains(chart, "[red]*") {
+		t.Fatalf("chart:\n%s", chart)
+	}
+	if plot := lines[equityChartHeight-1]; !strings.Contains(plot, "|     ") {
+		t.Errorf("bottom row plotted before the first snapshot: %q", plot)
+	}
+
+	if report := a.equityReport(equityRanges[0], nil, start, renderFixture); !strings.Contains(report, "No snapshots") {
+		t.Errorf("empty report:\n%s", report)
+	}
+}
+
+func TestReturns(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+	a := newTestApp(store, market)
+	at := func(y int, m time.Month, d int) {
+		store.Now = func() time.Time { return time.Date(y, m, d, 15, 0, 0, 0, time.UTC) }
+	}
+	dec := decimal.NewFromInt
+
+	at(2026, 1, 1)
+	store.AddContribution(ctx, dec(10000), store.Now(), "")
+	store.AddHolding(ctx, "AAPL", dec(50), dec(100), db.DefaultCurrency, store.Now(), decimal.NullDecimal{}, "")
+	store.SavePriceBars(ctx, "AAPL", []analytics.DailyBar{{Time: store.Now().Unix(), Close: 100}})
+	store.RecordPortfolioSnapshot(ctx, dec(5000), dec(5000), decimal.Zero)
+	at(2026, 7, 2)
+	store.AddContribution(ctx, dec(10000), store.Now(), "") // Not a gain
+	store.RecordPortfolioSnapshot(ctx, dec(5500), dec(15000), decimal.Zero)
+	at(2027, 1, 1)
+	store.RecordPortfolioSnapshot(ctx, dec(6000), dec(15000), decimal.Zero)
+
+	snapshots, _ := store.GetPortfolioSnapshots(ctx, time.Time{})
+	txs, _ := store.GetTransactions(ctx)
+	_, twr, _, hasTWR := portfolioReturns(snapshots, txs)
+	if want := 1.05*21000/20500 - 1; !hasTWR || math.Abs(twr-want) > 1e-9 {
+		t.Errorf("portfolio TWR = %v, want %v", twr, want)
+	}
+
+	// Bought at 100, worth 120 a year later
+	a.book.holdings, _ = store.GetHoldings(ctx)
+	a.book.quotes = map[string]yahoo.Quote{"AAPL": {Symbol: "AAPL", Price: 120}}
+	positions := a.positionReturns(ctx, txs, store.Now())
+	if len(positions) != 1 {
+		t.Fatalf("positions = %+v", positions)
+	}
+	r := positions[0]
+	if !r.HasXIRR || math.Abs(r.XIRR-0.2) > 1e-6 || !r.HasTWR || math.Abs(r.TWR-0.2) > 1e-9 {
+		t.Errorf("AAPL returns = %+v, want 20%% each", r)
+	}
+
+	report := a.returnsReport(ctx, store.Now())
+	for _, want := range []string{"Returns since Jan 1, 2026", "AAPL", "+20.00%"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestReturnsAcrossAccounts(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+	a := newTestApp(store, market)
+	var now time.Time
+	store.Now = func() time.Time { return now } // Shared with the IRA's store
+	at := func(y int, m time.Month, d int) { now = time.Date(y, m, d, 15, 0, 0, 0, time.UTC) }
+	store.AddAccount(ctx, "IRA")
+	accounts, _ := store.GetAccounts(ctx)
+	ira := store.ForAccount(accounts[0].ID)
+	a.book.accounts = accounts
+	dec := decimal.NewFromInt
+
+	// The snapshots total both accounts; the second deposit went to the IRA
+	at(2026, 1, 1)
+	store.AddContribution(ctx, dec(10000), store.Now(), "")
+	store.RecordPortfolioSnapshot(ctx, dec(5000), dec(5000), decimal.Zero)
+	at(2026, 7, 2)
+	ira.AddContribution(ctx, dec(10000), store.Now(), "")
+	store.RecordPortfolioSnapshot(ctx, dec(5500), dec(15000), decimal.Zero)
+	at(2027, 1, 1)
+	store.RecordPortfolioSnapshot(ctx, dec(6000), dec(15000), decimal.Zero)
+
+	snapshots, _ := store.GetPortfolioSnapshots(ctx, time.Time{})
+	txs, err := accountsLedger(ctx, a.db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, twr, _, hasTWR := portfolioReturns(snapshots, txs)
+	if want := 1.05*21000/20500 - 1; !hasTWR || math.Abs(twr-want) > 1e-9 {
+		t.Errorf("portfolio TWR = %v, want %v with the IRA deposit as a flow", twr, want)
+	}
+
+	if report := a.returnsReport(ctx, store.Now()); !strings.Contains(report, "Returns since Jan 1, 2026, all accounts") {
+		t.Errorf("report not labelled as across accounts:\n%s", report)
+	}
+}
+
+func TestOptionMaxLoss(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	day := time.Date(2026, 4, 17, 0, 0, 0, 0, time.UTC)
+	dec := decimal.RequireFromString
+	// A 240/230 put credit spread for 3.00, and a call on 50 MSFT shares that cannot cover it
+	a.db.AddOption(ctx, "AAPL", "PUT", "SELL", dec("240"), day, 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, dec("5"), decimal.Zero, "")
+	a.db.AddOption(ctx, "AAPL", "PUT", "BUY", dec("230"), day, 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, dec("2"), decimal.Zero, "")
+	a.db.AddOption(ctx, "MSFT", "CALL", "SELL", dec("500"), day, 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, dec("4"), decimal.Zero, "")
+	a.refreshData()
+
+	risks := a.book.optionRisks()
+	byLeg := make(map[string]optionRisk)
+	for _, o := range a.book.options {
+		if r, ok := risks[o.ID]; ok {
+			byLeg[o.Ticker+" "+o.OptionType+" "+o.Action+" "+o.Strike.String()] = r
+		}
+	}
+	if r := byLeg["AAPL PUT SELL 240"]; !r.Spread || !r.Counted || !r.Loss.Equal(dec("700")) {
+		t.Errorf("spread short leg = %+v, want a 700 loss", r)
+	}
+	if r := byLeg["AAPL PUT BUY 230"]; !r.Spread || r.Counted {
+		t.Errorf("spread long leg = %+v", r)
+	}
+	if r := byLeg["MSFT PUT SELL 380"]; !r.Loss.Equal(dec("37360")) {
+		t.Errorf("short put = %+v, want 37,360 collateral less premium", r)
+	}
+	if !byLeg["AAPL CALL SELL 230"].Covered || !byLeg["MSFT CALL SELL 500"].Unbounded {
+		t.Errorf("calls = %+v / %+v", byLeg["AAPL CALL SELL 230"], byLeg["MSFT CALL SELL 500"])
+	}
+
+	// 37,360 + 19,025 + 700, with the naked call apart
+	total, unbounded := totalMaxLoss(risks)
+	if !total.Equal(dec("57085")) || unbounded != 1 {
+		t.Errorf("total = %s with %d unlimited", total, unbounded)
+	}
+	if text := a.optionsView.timeline.GetText(true); !strings.Contains(text, "Max loss: $57,085.00 +1 unlimited") {
+		t.Errorf("premium line = %q", text)
+	}
+}
+
+func TestAssignmentTraceability(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	store := a.db.(*fake.Store)
+	dec := decimal.RequireFromString
+	store.AddOption(ctx, "AAPL", "PUT", "SELL", dec("220"), time.Date(2026, 2, 27, 0, 0, 0, 0, time.UTC), 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, dec("3"), decimal.Zero, "")
+	options, _ := store.GetActiveOptions(ctx)
+	put := options[slices.IndexFunc(options, func(o db.Option) bool { return o.Strike.Equal(dec("220")) })]
+	if err := store.AssignOption(ctx, put.ID, dec("5")); err != nil {
+		t.Fatal(err)
+	}
+	a.refreshData()
+
+	// The assignment and its fee point back at the put
+	txs, _ := store.GetTransactions(ctx)
+	linked := 0
+	for _, tx := range txs {
+		if tx.OptionID == put.ID {
+			linked++
+		}
+	}
+	if linked != 2 {
+		t.Errorf("%d ledger entries linked to the put, want 2", linked)
+	}
+	sources := a.assignedFrom("AAPL")
+	if len(sources) != 1 || sources[0].ID != put.ID {
+		t.Fatalf("AAPL acquired via %+v", sources)
+	}
+	if len(a.assignedFrom("MSFT")) != 0 {
+		t.Error("MSFT was never assigned")
+	}
+
+	// Going to it shows assigned options and drops a filter hiding it
+	a.toggleStatus("ASSIGNED")
+	a.optionsView.filter = "msft"
+	a.optionsView.update()
+	if !a.showOption(put.ID) {
+		t.Fatal("option not found")
+	}
+	if i, ok := a.optionsView.selected(); !ok || a.book.options[i].ID != put.ID || a.focusIndex != 1 {
+		t.Errorf("selected option %d (ok %v), focus %d", i, ok, a.focusIndex)
+	}
+	if a.showOption("missing") {
+		t.Error("showOption found a missing option")
+	}
+}
+
+func TestCashDepositsAndWithdrawals(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	store := a.db.(*fake.Store)
+
+	record := func(kind int, amount string) {
+		t.Helper()
+		a.showCashForm()
+		form := modalForm(t, a, "cash")
+		form.GetFormItem(0).(*tview.DropDown).SetCurrentOption(kind)
+		form.GetFormItem(1).(*tview.InputField).SetText(amount)
+		form.GetFormItem(2).(*tview.InputField).SetText("note")
+		pressButton(form, "Save")
+	}
+	start := a.book.cash
+	want := start.Add(decimal.NewFromInt(700))
+	record(0, "1000")
+	record(1, "300")
+	if !a.book.cash.Equal(want) {
+		t.Errorf("cash %s after deposit and withdrawal, want %s", a.book.cash, want)
+	}
+
+	// A withdrawal is a negative amount, not a negative withdrawal
+	record(1, "-50")
+	if !a.pages.HasPage("cash") || !a.book.cash.Equal(want) {
+		t.Error("negative withdrawal accepted")
+	}
+	a.pages.RemovePage("cash")
+
+	record(2, "20000")
+	if !a.book.cash.Equal(decimal.NewFromInt(20000)) {
+		t.Errorf("cash %s after setting the balance, want 20000", a.book.cash)
+	}
+
+	txs, _ := store.GetTransactions(ctx)
+	var contributions []decimal.Decimal
+	for _, tx := range txs {
+		if tx.Kind == db.TxContribution {
+			contributions = append(contributions, tx.Amount)
+		}
+	}
+	if len(contributions) != 2 || !contributions[0].Equal(decimal.NewFromInt(1000)) || !contributions[1].Equal(decimal.NewFromInt(-300)) {
+		t.Errorf("contributions %v, want [1000 -300]", contributions)
+	}
+	if cash, _ := store.GetAvailableCash(ctx); !cash.Equal(decimal.NewFromInt(20000)) {
+		t.Errorf("store cash %s, want 20000", cash)
+	}
+	// Deposits and withdrawals count in the growth breakdown, not as market gains
+	quarters, _ := store.GetContributionsByQuarter(ctx, renderFixture.AddDate(0, -3, 0))
+	if len(quarters) != 1 || !quarters[0].Amount.Equal(decimal.NewFromInt(700)) {
+		t.Errorf("contributions by quarter %+v, want 700 this quarter", quarters)
+	}
+
+	// History opens the ledger
+	a.showCashForm()
+	pressButton(modalForm(t, a, "cash"), "History")
+	if !a.pages.HasPage("ledger") || a.pages.HasPage("cash") {
+		t.Error("History did not open the ledger")
+	}
+}
+
+func TestPutCollateral(t *testing.T) {
+	a := newRenderApp(t)
+
+	// MSFT 380 and TSLA 200 short puts, one contract each; the expired NVDA
+	// put secures nothing
+	want := decimal.NewFromInt(58000)
+	if got := a.book.putCollateral(); !got.Equal(want) {
+		t.Fatalf("collateral %s, want %s", got, want)
+	}
+	a.summaryView.update()
+	text := a.summaryView.text.GetText(true)
+	free := a.book.cash.Sub(want)
+	for _, s := range []string{"Collateral: $58,000.00", "Free Cash: -$" + a.book.locale.FormatFixed(free.Abs(), 2)} {
+		if !strings.Contains(text, s) {
+			t.Errorf("summary missing %q:\n%s", s, text)
+		}
+	}
+}
+
+func TestTakeProfit(t *testing.T) {
+	a := newRenderApp(t)
+
+	for _, c := range []struct {
+		premium string
+		pct     int
+		want    string
+	}{
+		{"1.85", 50, "0.93"},
+		{"1.85", 75, "0.46"},
+		{"6.40", 50, "3.2"},
+		{"9.75", 75, "2.44"},
+	} {
+		if got := profitClosePremium(decimal.RequireFromString(c.premium), c.pct); got.String() != c.want {
+			t.Errorf("%d%% of %s: buy back at %s, want %s", c.pct, c.premium, got, c.want)
+		}
+	}
+
+	// Choosing a target opens the close form at its price
+	i := activeOptionIndex(t, a, "AAPL")
+	a.showTakeProfit(i)
+	if !a.pages.HasPage("takeprofit") {
+		t.Fatal("take profit not offered for a short call")
+	}
+	modal := a.pages.GetPage("takeprofit").(*tview.Modal)
+	var focus func(p tview.Primitive)
+	focus = func(p tview.Primitive) { p.Focus(focus) }
+	modal.Focus(focus)
+	modal.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(p tview.Primitive) {})
+	if a.pages.HasPage("takeprofit") {
+		t.Error("take profit prompt left open")
+	}
+	premium := modalForm(t, a, "closeoption").GetFormItem(0).(*tview.InputField).GetText()
+	if premium != "0.93" {
+		t.Errorf("close form prefilled with %q, want 0.93 for 50%%", premium)
+	}
+	a.pages.RemovePage("closeoption")
+
+	// Settled options have no premium left to keep
+	j := slices.IndexFunc(a.book.options, func(o db.Option) bool { return o.Status != "ACTIVE" })
+	if j < 0 {
+		t.Fatal("fixture has no settled option")
+	}
+	a.showTakeProfit(j)
+	if a.pages.HasPage("takeprofit") {
+		t.Error("take profit offered for a settled option")
+	}
+}
+
+func TestStockSplits(t *testing.T) {
+	ctx := context.Background()
+	a := newRenderApp(t)
+	store := a.db.(*fake.Store)
+	market := a.yahoo.(*fake.Market)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	market.Splits["NVDA"] = []yahoo.Split{
+		{Date: day(2024, 6, 10), Numerator: 10, Denominator: 1}, // Before the holding was entered
+		{Date: day(2026, 1, 15), Numerator: 2, Denominator: 1},
+	}
+	market.Splits["MSFT"] = []yahoo.Split{{Date: day(2025, 12, 1), Numerator: 3, Denominator: 2}}
+	market.Splits["XYZ"] = []yahoo.Split{{Date: day(2026, 1, 5), Numerator: 2, Denominator: 1}} // Not held
+
+	// A put sold before the MSFT split; the fixture's was sold after it
+	store.Now = func() time.Time { return day(2025, 11, 3) }
+	store.AddOption(ctx, "MSFT", "PUT", "SELL", decimal.NewFromInt(330), day(2026, 3, 20), 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, decimal.NewFromInt(9), decimal.Zero, "")
+	store.Now = func() time.Time { return renderFixture }
+	a.refreshData()
+	putAt := func(strike string) db.Option {
+		t.Helper()
+		i := slices.IndexFunc(a.book.options, func(o db.Option) bool {
+			return o.Ticker == "MSFT" && o.Status == "ACTIVE" && o.Strike.Equal(decimal.RequireFromString(strike))
+		})
+		if i < 0 {
+			t.Fatalf("no active MSFT $%s put", strike)
+		}
+		return a.book.options[i]
+	}
+
+	txs, _ := store.GetTransactions(ctx)
+	a.pendingSplits = a.splitsToApply(a.fetchSplits(a.splitTickers()), txs)
+	if len(a.pendingSplits) != 2 || a.pendingSplits[0].Ticker != "MSFT" || a.pendingSplits[1].Ticker != "NVDA" {
+		t.Fatalf("pending splits %+v, want MSFT then NVDA", a.pendingSplits)
+	}
+	cash := a.book.cash
+
+	press := func(label string) {
+		t.Helper()
+		modal, ok := a.pages.GetPage("split").(*tview.Modal)
+		if !ok {
+			t.Fatal("split not offered")
+		}
+		// Focus moves as the application would, leaving the last button
+		var focused tview.Primitive
+		var focus func(p tview.Primitive)
+		focus = func(p tview.Primitive) {
+			if focused != nil {
+				focused.Blur()
+			}
+			focused = p
+			p.Focus(focus)
+		}
+		modal.Focus(focus)
+		for range slices.Index([]string{"Apply", "Dismiss", "Cancel"}, label) {
+			modal.InputHandler()(tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone), focus)
+		}
+		modal.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), focus)
+	}
+
+	// 3-for-2: more shares at a lower cost; the put sold before it delivers
+	// 150 shares, the one sold since is on the new terms already
+	a.reviewSplits()
+	press("Apply")
+	msft, _ := store.GetHoldingByTicker(ctx, "MSFT")
+	if !msft.Quantity.Equal(decimal.NewFromInt(75)) || !msft.AvgCost.Equal(decimal.RequireFromString("273.3333")) ||
+		!msft.TargetPrice.Decimal.Equal(decimal.NewFromInt(300)) {
+		t.Errorf("MSFT after split: %s @ %s target %s, want 75 @ 273.3333 target 300", msft.Quantity, msft.AvgCost, msft.TargetPrice.Decimal)
+	}
+	put := putAt("220")
+	if put.Quantity != 1 || put.Multiplier != 150 {
+		t.Errorf("MSFT put after split: %d ×%d $%s, want 1 ×150 $220", put.Quantity, put.Multiplier, put.Strike)
+	}
+	if later := putAt("380"); later.Quantity != 1 || later.Multiplier != db.DefaultMultiplier {
+		t.Errorf("MSFT put sold after the split adjusted to %d ×%d", later.Quantity, later.Multiplier)
+	}
+
+	// The NVDA split is offered next; dismissing it leaves NVDA alone
+	press("Dismiss")
+	nvda, _ := store.GetHoldingByTicker(ctx, "NVDA")
+	if !nvda.Quantity.Equal(decimal.NewFromInt(120)) || a.pages.HasPage("split") || len(a.pendingSplits) != 0 {
+		t.Errorf("dismissed split changed NVDA to %s shares or is still pending", nvda.Quantity)
+	}
+
+	// The split is in the ledger without moving cash, and not offered again
+	txs, _ = store.GetTransactions(ctx)
+	last := txs[len(txs)-1]
+	if last.Kind != db.TxSplit || last.Ticker != "MSFT" || !last.Quantity.Equal(decimal.NewFromInt(25)) || !last.Amount.IsZero() {
+		t.Errorf("ledger entry %+v, want a MSFT split adding 25 shares", last)
+	}
+	if !a.book.cash.Equal(cash) {
+		t.Errorf("cash moved from %s to %s", cash, a.book.cash)
+	}
+	if pending := a.splitsToApply(a.fetchSplits(a.splitTickers()), txs); len(pending) != 1 || pending[0].Ticker != "NVDA" {
+		t.Errorf("pending after applying MSFT: %+v, want only NVDA", pending)
+	}
+
+	// A split the contracts cannot follow changes nothing
+	if err := store.ApplySplit(ctx, "MSFT", decimal.NewFromInt(1).Div(decimal.NewFromInt(7)), renderFixture.AddDate(0, 0, 1), ""); err == nil {
+		t.Error("1-for-7 split of 150-share contracts accepted")
+	}
+	if msft, _ := store.GetHoldingByTicker(ctx, "MSFT"); !msft.Quantity.Equal(decimal.NewFromInt(75)) {
+		t.Errorf("failed split changed MSFT to %s shares", msft.Quantity)
+	}
+}
+
+func TestSplitApplied(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	june := yahoo.Split{Date: day(2024, 6, 10), Numerator: 10, Denominator: 1}
+	july := yahoo.Split{Date: day(2024, 7, 1), Numerator: 3, Denominator: 2}
+	// The June split applied in August, after the July split
+	txs := []db.Transaction{{Kind: db.TxSplit, Ticker: "NVDA", SplitDate: june.Date, SplitRatio: decimal.NewFromInt(10), CreatedAt: day(2024, 8, 1)}}
+
+	if !splitApplied("NVDA", june, txs) {
+		t.Error("June split not matched by its entry")
+	}
+	if splitApplied("NVDA", july, txs) {
+		t.Error("July split taken as applied by the later entry for June's")
+	}
+	if splitApplied("NVDA", yahoo.Split{Date: june.Date, Numerator: 2, Denominator: 1}, txs) {
+		t.Error("2-for-1 split on the same day taken as applied")
+	}
+	if splitApplied("AMD", june, txs) {
+		t.Error("split matched another ticker's entry")
+	}
+
+	// Entries from before splits were recorded with their date count for any
+	// split up to when they were made
+	legacy := []db.Transaction{{Kind: db.TxSplit, Ticker: "NVDA", CreatedAt: day(2024, 8, 1)}}
+	if !splitApplied("NVDA", july, legacy) || splitApplied("NVDA", yahoo.Split{Date: day(2024, 9, 1), Numerator: 2, Denominator: 1}, legacy) {
+		t.Error("legacy split entry not matched by date")
+	}
+}
+
+// failingSnapshots is a store whose history snapshots fail
+type failingSnapshots struct {
+	db.Store
+}
+
+func (failingSnapshots) RecordCashSnapshot(ctx context.Context, amount decimal.Decimal) error {
+	return errors.New("cash_history missing")
+}
+
+func (failingSnapshots) RecordPortfolioSnapshot(ctx context.Context, holdingsValue, cash, optionsCredit decimal.Decimal) error {
+	return errors.New("portfolio_snapshots missing")
+}
+
+func TestSnapshotErrorsShown(t *testing.T) {
+	a := newRenderApp(t)
+	a.db = failingSnapshots{a.db}
+	a.refreshData()
+	if text := a.statusBar.GetText(true); !strings.Contains(text, "Cash history not recorded: cash_history missing") {
+		t.Errorf("status bar %q does not report the cash snapshot", text)
+	}
+	if text := a.statusBar.GetText(true); !strings.Contains(text, "Portfolio history not recorded: portfolio_snapshots missing") {
+		t.Errorf("status bar %q does not report the portfolio snapshot", text)
+	}
+}
+
+func TestPruneSentAlerts(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	d := &Daemon{sentAlerts: map[string]time.Time{
+		"target:h1:2026-03-01": now.Add(-3 * 24 * time.Hour),
+		"target:h1:2026-03-03": now.Add(-24 * time.Hour),
+		"expiry:o1:2026-03-04": now.Add(-time.Hour),
+	}}
+	d.pruneSentAlerts(now)
+	if len(d.sentAlerts) != 2 {
+		t.Errorf("sent alerts after pruning = %v", d.sentAlerts)
+	}
+	if _, ok := d.sentAlerts["target:h1:2026-03-01"]; ok {
+		t.Error("three-day-old alert key kept")
+	}
+}
+
+func TestWebhookFillRecordedOnce(t *testing.T) {
+	ctx := context.Background()
+	dec := decimal.RequireFromString
+	store := fake.NewStore()
+	store.SetAvailableCash(ctx, dec("10000"))
+	d := &Daemon{db: store}
+	buy := webhook.Trade{Kind: "STOCK", Ticker: "AAPL", Action: "BUY", Quantity: dec("10"), Price: dec("200"), FillID: "E1"}
+
+	if err := d.recordWebhookTrade(ctx, buy); err != nil {
+		t.Fatal(err)
+	}
+	// The broker retries the same fill
+	if err := d.recordWebhookTrade(ctx, buy); !errors.Is(err, webhook.ErrDuplicateFill) {
+		t.Errorf("retried fill: err = %v, want ErrDuplicateFill", err)
+	}
+	if h, _ := store.GetHoldingByTicker(ctx, "AAPL"); h == nil || !h.Quantity.Equal(dec("10")) {
+		t.Errorf("AAPL after a retried fill = %+v, want 10 shares", h)
+	}
+	if cash, _ := store.GetAvailableCash(ctx); !cash.Equal(dec("8000")) {
+		t.Errorf("cash = %s, want 8000", cash)
+	}
+
+	// A fill that fails is not claimed, so its retry can be recorded
+	sell := webhook.Trade{Kind: "STOCK", Ticker: "AAPL", Action: "SELL", Quantity: dec("10"), Price: dec("210"), FillID: "E2"}
+	if err := d.recordWebhookTrade(ctx, sell); err == nil {
+		t.Fatal("stock sell recorded")
+	}
+	if claimed, _ := store.ClaimFill(ctx, "E2"); !claimed {
+		t.Error("failed fill left claimed")
+	}
+}
+
+func TestWebhookClosingFill(t *testing.T) {
+	ctx := context.Background()
+	dec := decimal.RequireFromString
+	store := fake.NewStore()
+	d := &Daemon{db: store}
+	fill := func(action, qty, price string) error {
+		tr := webhook.Trade{Kind: "OPTION", Ticker: "AAPL", Action: action, Quantity: dec(qty), Price: dec(price),
+			Fee: dec("0.65"), OptionType: "PUT", Strike: dec("200"), Expiry: "2026-11-20"}
+		return d.recordWebhookTrade(ctx, tr)
+	}
+
+	if err := fill("SELL", "2", "2.15"); err != nil {
+		t.Fatal(err)
+	}
+	cash, _ := store.GetAvailableCash(ctx)
+
+	// Buying back fewer contracts than are open is rejected
+	if err := fill("BUY", "1", "0.50"); err == nil {
+		t.Error("partial buy-to-close accepted")
+	}
+
+	// Buying back all of them closes the short instead of opening a long
+	if err := fill("BUY", "2", "0.50"); err != nil {
+		t.Fatal(err)
+	}
+	options, _ := store.GetActiveOptions(ctx)
+	if len(options) != 1 || options[0].Status != "CLOSED" || options[0].Action != "SELL" {
+		t.Fatalf("options = %+v", options)
+	}
+	// 2 contracts bought back at 0.50, plus the fee
+	if after, _ := store.GetAvailableCash(ctx); !after.Equal(cash.Sub(dec("100.65"))) {
+		t.Errorf("cash = %s, want %s", after, cash.Sub(dec("100.65")))
+	}
+}
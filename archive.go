@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// archivedHidden counts the archived holdings the holdings table leaves out
+func (v *HoldingsView) archivedHidden() int {
+	if v.showArchived {
+		return 0
+	}
+	n := 0
+	for _, h := range v.book.holdings {
+		if h.Archived {
+			n++
+		}
+	}
+	return n
+}
+
+// toggleArchived archives the selected holding, hiding it from the holdings
+// table while keeping it in the totals and reports, or restores it
+func (a *App) toggleArchived() {
+	i, ok := a.holdingsView.selected()
+	if !ok {
+		return
+	}
+	h := &a.book.holdings[i]
+	archived := !h.Archived
+	if err := a.db.SetHoldingArchived(context.Background(), h.ID, archived); err != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error (run the archived migration in schema.sql): %v", err))
+		return
+	}
+	h.Archived = archived
+	a.holdingsView.update()
+	a.selectRowOf(h.ID)
+
+	if !archived {
+		a.statusBar.SetText(fmt.Sprintf(" [green]Restored %s", h.Ticker))
+	} else if a.holdingsView.showArchived {
+		a.statusBar.SetText(fmt.Sprintf(" [green]Archived %s", h.Ticker))
+	} else {
+		a.statusBar.SetText(fmt.Sprintf(" [green]Archived %s; Z shows archived holdings", h.Ticker))
+	}
+}
+
+// toggleShowArchived shows or hides the archived holdings
+func (a *App) toggleShowArchived() {
+	a.holdingsView.showArchived = !a.holdingsView.showArchived
+	a.holdingsView.update()
+}
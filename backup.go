@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/export"
+
+	"github.com/rivo/tview"
+)
+
+// exportEncryption reads EXPORT_ENCRYPTION and EXPORT_RECIPIENT. A nil result
+// with a nil error means encryption is not configured.
+func exportEncryption() (*export.Encryption, error) {
+	return export.ParseEncryption(os.Getenv("EXPORT_ENCRYPTION"), os.Getenv("EXPORT_RECIPIENT"))
+}
+
+// defaultBackupPath names a backup after the time it was taken
+func defaultBackupPath(now time.Time) string {
+	return fmt.Sprintf("anyhowhodl-backup-%s.json", now.Format("20060102-1504"))
+}
+
+// writeBackup dumps the book to path, encrypted when enc is set, and returns
+// the path written
+func writeBackup(ctx context.Context, store db.Store, path string, enc *export.Encryption) (string, error) {
+	b, err := export.NewBackup(ctx, store, time.Now())
+	if err != nil {
+		return path, err
+	}
+	w, path, err := export.Create(path, enc)
+	if err != nil {
+		return path, err
+	}
+	if err := export.WriteBackupJSON(w, b); err != nil {
+		w.Close()
+		return path, err
+	}
+	return path, w.Close()
+}
+
+// runBackup implements the backup command
+func runBackup(store db.Store, args []string) error {
+	enc, err := exportEncryption()
+	if err != nil {
+		return err
+	}
+	path := defaultBackupPath(time.Now())
+	if len(args) > 0 {
+		path = args[0]
+	}
+	path, err = writeBackup(context.Background(), store, path, enc)
+	if err != nil {
+		return err
+	}
+	fmt.Println(path)
+	return nil
+}
+
+// showBackupForm writes a full JSON backup, encrypted if configured
+func (a *App) showBackupForm() {
+	enc, encErr := exportEncryption()
+
+	form := tview.NewForm().
+		AddInputField("File", defaultBackupPath(time.Now()), 40, nil, nil)
+	if enc != nil {
+		form.AddCheckbox(fmt.Sprintf("Encrypt (%s: %s)", enc.Tool, enc.Recipient), true, nil)
+	}
+	styleForm(form)
+
+	form.AddButton("Export", func() {
+		path := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		if path == "" {
+			return
+		}
+		a.pages.RemovePage("backup")
+		if encErr != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Backup failed: %v", encErr))
+			return
+		}
+		useEnc := enc
+		if enc != nil && !form.GetFormItem(1).(*tview.Checkbox).IsChecked() {
+			useEnc = nil
+		}
+		path, err := writeBackup(context.Background(), a.db, path, useEnc)
+		if err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Backup failed: %v", err))
+			return
+		}
+		a.statusBar.SetText(fmt.Sprintf(" [lime]Backup written to %s", path))
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("backup")
+	})
+
+	form.SetBorder(true).SetTitle(" Export Backup (JSON) ").SetTitleAlign(tview.AlignLeft)
+
+	height := 7
+	if enc != nil {
+		height = 9
+	}
+	a.createModalPage("backup", form, 70, height)
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/locale"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// betaBenchmarks are the indices the portfolio is regressed against
+var betaBenchmarks = []string{"SPY", "QQQ"}
+
+// showBetaView opens the beta exposure page and loads data in the background
+func (a *App) showBetaView() {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	view.SetBorder(true).
+		SetTitle(" Beta Exposure ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+	view.SetText(" [yellow]Loading price history...")
+
+	a.pages.AddPage("beta", view, true, true)
+
+	a.goSafe("beta report", func() {
+		text := a.buildBetaReport()
+		a.queueUpdateDraw(func() {
+			view.SetText(text)
+		})
+	})
+}
+
+// buildBetaReport fetches 1y history for holdings and benchmarks and renders the report
+func (a *App) buildBetaReport() string {
+	if len(a.book.holdings) == 0 {
+		return " [gray]No holdings"
+	}
+
+	benchSeries := make([][]analytics.PricePoint, len(betaBenchmarks))
+	for i, sym := range betaBenchmarks {
+		series, err := a.yahoo.FetchPriceSeries(sym)
+		if err != nil {
+			return fmt.Sprintf(" [red]Error loading %s history: %v", sym, err)
+		}
+		benchSeries[i] = series
+	}
+
+	// Fetch each holding's history; holdings without data are reported but skipped
+	var tickers []string
+	var values []float64
+	var holdingSeries [][]analytics.PricePoint
+	var skipped []string
+	for _, h := range a.book.holdings {
+		series, err := a.yahoo.FetchPriceSeries(h.Ticker)
+		if err != nil || len(series) == 0 {
+			skipped = append(skipped, h.Ticker)
+			continue
+		}
+		value := a.book.toBase(h.Quantity.Mul(h.AvgCost), h.Currency).InexactFloat64()
+		if quote, ok := a.book.quotes[h.Ticker]; ok {
+			value = a.book.toBase(h.Quantity.Mul(decimal.NewFromFloat(quote.Price)), h.Currency).InexactFloat64()
+		}
+		tickers = append(tickers, h.Ticker)
+		values = append(values, value)
+		holdingSeries = append(holdingSeries, series)
+	}
+
+	if len(tickers) == 0 {
+		return " [red]No price history available for holdings"
+	}
+
+	var sb strings.Builder
+
+	// Per-holding betas, each aligned pairwise with the benchmark
+	betas := make([][]float64, len(betaBenchmarks))
+	for b := range betaBenchmarks {
+		betas[b] = make([]float64, len(tickers))
+		for i := range tickers {
+			aligned := analytics.AlignSeries(holdingSeries[i], benchSeries[b])
+			betas[b][i] = analytics.Beta(analytics.Returns(aligned[0]), analytics.Returns(aligned[1]))
+		}
+	}
+
+	sb.WriteString(" [teal]Portfolio beta (1y daily, value-weighted)[white]\n")
+	for b, sym := range betaBenchmarks {
+		fmt.Fprintf(&sb, "   vs %-4s %s\n", sym, formatBeta(a.book.locale, analytics.PortfolioBeta(betas[b], values)))
+	}
+	sb.WriteString("\n")
+
+	// Per-holding table
+	fmt.Fprintf(&sb, " [teal]%-8s %10s", "TICKER", "WEIGHT")
+	for _, sym := range betaBenchmarks {
+		fmt.Fprintf(&sb, " %10s", "BETA "+sym)
+	}
+	sb.WriteString("[white]\n")
+
+	totalValue := 0.0
+	for _, v := range values {
+		totalValue += v
+	}
+	for i, ticker := range tickers {
+		weight := 0.0
+		if totalValue > 0 {
+			weight = values[i] / totalValue * 100
+		}
+		fmt.Fprintf(&sb, " [fuchsia]%-8s[white] %9.1f%%", ticker, weight)
+		for b := range betaBenchmarks {
+			fmt.Fprintf(&sb, " %s", padBeta(a.book.locale, betas[b][i], 10))
+		}
+		sb.WriteString("\n")
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(&sb, " [gray]No history: %s[white]\n", strings.Join(skipped, ", "))
+	}
+	sb.WriteString("\n")
+
+	// Rolling beta: current weights applied to the common history of every position
+	fmt.Fprintf(&sb, " [teal]Rolling %d-day portfolio beta (sampled monthly)[white]\n", analytics.RollingBetaWindow)
+	for b, sym := range betaBenchmarks {
+		all := append(append([][]analytics.PricePoint{}, holdingSeries...), benchSeries[b])
+		aligned := analytics.AlignSeries(all...)
+
+		returns := make([][]float64, len(tickers))
+		for i := range tickers {
+			returns[i] = analytics.Returns(aligned[i])
+		}
+		portfolio := analytics.WeightedReturns(returns, values)
+		market := analytics.Returns(aligned[len(aligned)-1])
+
+		rolling := analytics.RollingBeta(portfolio, market, analytics.RollingBetaWindow)
+		if len(rolling) == 0 {
+			fmt.Fprintf(&sb, "   vs %-4s [gray]insufficient common history[white]\n", sym)
+			continue
+		}
+
+		// Sample roughly every 21 trading days, always ending on the latest value
+		var samples []string
+		for i := len(rolling) - 1; i >= 0; i -= 21 {
+			samples = append([]string{formatBeta(a.book.locale, rolling[i])}, samples...)
+		}
+		fmt.Fprintf(&sb, "   vs %-4s %s\n", sym, strings.Join(samples, " "))
+	}
+
+	sb.WriteString("\n [gray]β > 1 amplifies the benchmark, β < 1 dampens it. ESC to close.")
+	return sb.String()
+}
+
+// formatBeta colors a beta value: red above 1.2, yellow above 1.0, lime otherwise
+func formatBeta(loc locale.Locale, beta float64) string {
+	if math.IsNaN(beta) {
+		return "[gray]N/A[white]"
+	}
+	color := "lime"
+	if beta > 1.2 {
+		color = "red"
+	} else if beta > 1.0 {
+		color = "yellow"
+	}
+	return fmt.Sprintf("[%s]%s[white]", color, loc.FormatFloat(beta, 2))
+}
+
+// padBeta right-aligns a colored beta value to width visible characters
+func padBeta(loc locale.Locale, beta float64, width int) string {
+	plain := "N/A"
+	if !math.IsNaN(beta) {
+		plain = loc.FormatFloat(beta, 2)
+	}
+	pad := width - len(plain)
+	if pad < 0 {
+		pad = 0
+	}
+	return strings.Repeat(" ", pad) + formatBeta(loc, beta)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/locale"
+	"anyhowhodl/internal/query"
+	"anyhowhodl/internal/yahoo"
+
+	"github.com/shopspring/decimal"
+)
+
+// book is the selected account's portfolio as of the last refresh, and the
+// settings its numbers are shown with. The App loads it; the views read it.
+type book struct {
+	holdings           []db.Holding
+	options            []db.Option
+	quotes             map[string]yahoo.Quote
+	cash               decimal.Decimal
+	baseCurrency       string             // Currency totals are converted into
+	fxRates            map[string]float64 // Rate into baseCurrency by currency, from the currency pair quotes
+	account            string             // Selected account ID, "" for the main account
+	accounts           []db.Account       // Accounts besides the main one
+	accountBooks       []accountBook      // Holdings and cash of the accounts not selected
+	premiums           *db.PremiumSummary // This tax year's, in the base currency
+	premiumsByCurrency map[string]db.PremiumSummary
+	tickerPremiums     map[string]decimal.Decimal // Net premium this tax year by underlying
+	manualPrices       map[string]db.ManualPrice  // Manual prices standing in for missing quotes
+	staleSymbols       map[string]db.StaleSymbol  // Symbols quotes keep failing for
+	failedQuotes       []string                   // Symbols the last quote fetch returned nothing for
+	optionIVs          map[string]optionIV        // Implied volatility of short options, by contractKey
+	optionOI           map[string]query.OIChange  // Open interest of short options, by contractKey
+	customColumns      []customColumn             // User-defined table columns, loaded on refresh
+	pinned             map[string]bool            // IDs of holdings and options pinned to the top
+	tags               map[string][]string        // Tags of holdings and options by ID
+
+	locale    locale.Locale     // Number and date display convention
+	precision db.Precision      // Decimal places for shares and prices
+	taxYear   analytics.TaxYear // Period for the yearly premium stats
+	showTags  bool              // Show the TAGS column in both tables
+}
+
+// newBook is an empty book shown with the default settings
+func newBook() *book {
+	return &book{
+		quotes:    make(map[string]yahoo.Quote),
+		locale:    locale.Default,
+		precision: db.DefaultPrecision,
+	}
+}
+
+// positionValues values each holding in the base currency as the holdings
+// table does: at the quote, capped at the lowest short call strike, or at cost
+// without a quote. It also returns the totals of value and cost.
+func (b *book) positionValues() ([]decimal.Decimal, decimal.Decimal, decimal.Decimal) {
+	// Build map of lowest active SELL CALL strike per ticker (for capping value), watch-only
+	// and cash-settled calls aside as neither can call the shares away
+	callCaps := make(map[string]decimal.Decimal)
+	for _, o := range b.options {
+		if o.Status == "ACTIVE" && o.OptionType == "CALL" && o.Action == "SELL" && !o.External && o.Settlement != db.SettlementCash {
+			if existing, ok := callCaps[o.Ticker]; ok {
+				if o.Strike.LessThan(existing) {
+					callCaps[o.Ticker] = o.Strike
+				}
+			} else {
+				callCaps[o.Ticker] = o.Strike
+			}
+		}
+	}
+
+	var totalCost, totalValue decimal.Decimal
+	positionValues := make([]decimal.Decimal, len(b.holdings))
+
+	// Values and costs are in the base currency
+	for i, h := range b.holdings {
+		quote, hasQuote := b.quotes[h.Ticker]
+		costBasis := b.toBase(h.Quantity.Mul(h.AvgCost), h.Currency)
+		totalCost = totalCost.Add(costBasis)
+
+		if hasQuote {
+			price := decimal.NewFromFloat(quote.Price)
+
+			// Cap price at call strike if there's an active covered call
+			if cap, hasCap := callCaps[h.Ticker]; hasCap && price.GreaterThan(cap) {
+				price = cap
+			}
+
+			value := b.toBase(h.Quantity.Mul(price), h.Currency)
+			positionValues[i] = value
+			totalValue = totalValue.Add(value)
+		} else {
+			positionValues[i] = costBasis
+			totalValue = totalValue.Add(costBasis)
+		}
+	}
+	return positionValues, totalValue, totalCost
+}
+
+// quotePrices is the last quoted price of each ticker
+func (b *book) quotePrices() map[string]float64 {
+	prices := make(map[string]float64, len(b.quotes))
+	for ticker, q := range b.quotes {
+		prices[ticker] = q.Price
+	}
+	return prices
+}
+
+// premiumText formats a premium to the dollar, "+$370" or "-$85"
+func (b *book) premiumText(premium decimal.Decimal) string {
+	if premium.IsNegative() {
+		return "-$" + b.locale.FormatFixed(premium.Abs(), 0)
+	}
+	return "+$" + b.locale.FormatFixed(premium, 0)
+}
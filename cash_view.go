@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/csp"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// cashDragNudgeThreshold is the net forgone income (YTD) above which the view nudges
+const cashDragNudgeThreshold = 50.0
+
+// showCashDragView opens the idle-cash report page
+func (a *App) showCashDragView() {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	view.SetBorder(true).
+		SetTitle(" Cash Drag ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'i':
+			a.showInterestForm(view)
+			return nil
+		case 't':
+			a.showRiskFreeRateForm(view)
+			return nil
+		}
+		return event
+	})
+
+	view.SetText(a.buildCashDragReport())
+	a.pages.AddPage("cashdrag", view, true, true)
+}
+
+// riskFreeRate returns the configured rate, falling back to the CSP engine default
+func (a *App) riskFreeRate(ctx context.Context) float64 {
+	rate, err := a.db.GetRiskFreeRate(ctx)
+	if err != nil || !rate.Valid {
+		return csp.RiskFreeRate
+	}
+	return rate.Decimal.InexactFloat64()
+}
+
+// buildCashDragReport renders idle-cash stats for several lookback windows
+func (a *App) buildCashDragReport() string {
+	ctx := context.Background()
+	now := time.Now()
+	rate := a.riskFreeRate(ctx)
+
+	yearStart, _ := a.book.taxYear.Bounds(now)
+	yearLabel := "Year to date"
+	if !a.book.taxYear.IsCalendar() {
+		yearLabel = "Tax year to date"
+	}
+
+	periods := []struct {
+		label string
+		from  time.Time
+	}{
+		{"30 days", now.AddDate(0, 0, -30)},
+		{"90 days", now.AddDate(0, 0, -90)},
+		{yearLabel, yearStart},
+	}
+
+	earliest := periods[0].from
+	for _, p := range periods {
+		if p.from.Before(earliest) {
+			earliest = p.from
+		}
+	}
+
+	rows, err := a.db.GetCashSnapshots(ctx, earliest)
+	if err != nil {
+		return fmt.Sprintf(" [red]Error loading cash history: %v", err)
+	}
+	snapshots := make([]analytics.CashSnapshot, len(rows))
+	for i, r := range rows {
+		snapshots[i] = analytics.CashSnapshot{Date: r.Date, Amount: r.Amount.InexactFloat64()}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, " [teal]Current cash:[white] [aqua]$%s[white]   [teal]Risk-free rate:[white] %s%%\n\n",
+		a.book.locale.FormatFixed(a.book.cash, 2), a.book.locale.FormatFloat(rate*100, 2))
+
+	if len(snapshots) == 0 {
+		sb.WriteString(" [gray]No cash history yet. A snapshot is recorded on every refresh.[white]\n")
+	} else {
+		fmt.Fprintf(&sb, " [teal]%-14s %6s %14s %12s %12s %12s[white]\n", "PERIOD", "DAYS", "AVG IDLE", "FORGONE", "INTEREST", "NET DRAG")
+		var ytd analytics.CashDragResult
+		for _, p := range periods {
+			interest, err := a.db.GetInterestSince(ctx, p.from)
+			if err != nil {
+				interest = decimal.Zero
+			}
+			r := analytics.ComputeCashDrag(snapshots, interest.InexactFloat64(), rate, p.from, now)
+			ytd = r // periods end with YTD, which drives the nudge
+
+			dragColor := "lime"
+			if r.NetDrag >= cashDragNudgeThreshold {
+				dragColor = "red"
+			} else if r.NetDrag > 0 {
+				dragColor = "yellow"
+			}
+			fmt.Fprintf(&sb, " %-14s %6d %14s %12s %12s [%s]%12s[white]\n",
+				p.label, r.Days,
+				"$"+a.book.locale.FormatFloat(r.AverageIdle, 2),
+				"$"+a.book.locale.FormatFloat(r.ForgoneIncome, 2),
+				"$"+a.book.locale.FormatFloat(r.InterestEarned, 2),
+				dragColor, "$"+a.book.locale.FormatFloat(r.NetDrag, 2))
+		}
+
+		sb.WriteString("\n")
+		if ytd.NetDrag >= cashDragNudgeThreshold {
+			fmt.Fprintf(&sb, " [red]Idle cash has cost ~$%s this year.[white] Deploy it (e.g. a CSP) or record the interest it earned.\n",
+				a.book.locale.FormatFloat(ytd.NetDrag, 2))
+		} else {
+			sb.WriteString(" [lime]Cash drag is under control.[white]\n")
+		}
+	}
+
+	sb.WriteString("\n [yellow]i[white]:Record Interest  [yellow]t[white]:Set Rate  [gray]ESC to close")
+	return sb.String()
+}
+
+// showInterestForm records interest received and credits it to cash
+func (a *App) showInterestForm(report *tview.TextView) {
+	form := tview.NewForm().
+		AddInputField("Amount ($)", "", 15, nil, nil).
+		AddInputField("Received ("+a.book.locale.DateHint+")", a.book.locale.FormatDate(time.Now()), 15, nil, nil).
+		AddInputField("Notes", "", 30, nil, nil)
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		amountStr := form.GetFormItem(0).(*tview.InputField).GetText()
+		dateStr := form.GetFormItem(1).(*tview.InputField).GetText()
+		notes := form.GetFormItem(2).(*tview.InputField).GetText()
+
+		amount, err := a.book.locale.ParseNumber(amountStr)
+		if err != nil || !amount.IsPositive() {
+			a.statusBar.SetText(" [red]Invalid interest amount")
+			return
+		}
+
+		receivedOn, err := a.book.locale.ParseDate(dateStr)
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid date format")
+			return
+		}
+
+		ctx := context.Background()
+		if err := a.db.AddInterestPayment(ctx, amount, receivedOn, notes); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+
+		a.pages.RemovePage("interest")
+		a.refreshData()
+		report.SetText(a.buildCashDragReport())
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("interest")
+	})
+
+	form.SetBorder(true).SetTitle(" Record Interest ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("interest", form, 50, 11)
+}
+
+// showRiskFreeRateForm edits the annual rate used for forgone-income estimates
+func (a *App) showRiskFreeRateForm(report *tview.TextView) {
+	current := a.book.locale.EditNumber(fmt.Sprintf("%.2f", a.riskFreeRate(context.Background())*100))
+
+	form := tview.NewForm().
+		AddInputField("Annual Rate (%)", current, 10, nil, nil)
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		rateStr := form.GetFormItem(0).(*tview.InputField).GetText()
+
+		pct, err := a.book.locale.ParseNumber(rateStr)
+		if err != nil || pct.IsNegative() {
+			a.statusBar.SetText(" [red]Invalid rate")
+			return
+		}
+
+		ctx := context.Background()
+		if err := a.db.SetRiskFreeRate(ctx, pct.Div(decimal.NewFromInt(100))); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+
+		a.pages.RemovePage("riskfreerate")
+		report.SetText(a.buildCashDragReport())
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("riskfreerate")
+	})
+
+	form.SetBorder(true).SetTitle(" Risk-Free Rate ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("riskfreerate", form, 40, 7)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// changeCheckInterval is how often the TUI polls for edits made by another
+// instance sharing the database. Polling backs up live sync, which needs
+// schema_sync.sql and a connection that supports LISTEN.
+const changeCheckInterval = 15 * time.Second
+
+// liveSyncDebounce groups the notifications raised by one multi-statement
+// write (e.g. an assignment touching options, holdings, and cash)
+const liveSyncDebounce = 300 * time.Millisecond
+
+// liveSyncRetry is how long to wait before listening again after the
+// notification connection fails
+const liveSyncRetry = time.Minute
+
+// changeWatchLoop polls for changes regardless of auto-refresh
+func (a *App) changeWatchLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.checkForChanges()
+		case <-a.stopAutoRefresh:
+			return
+		}
+	}
+}
+
+// liveSyncLoop listens for change notifications from other sessions and the
+// daemon, checking for changes shortly after each burst
+func (a *App) liveSyncLoop() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-a.stopAutoRefresh
+		cancel()
+	}()
+
+	var pending *time.Timer
+	onChange := func(table string) {
+		slog.Debug("change notification", "table", table)
+		if pending != nil {
+			pending.Stop()
+		}
+		pending = time.AfterFunc(liveSyncDebounce, func() {
+			// The timer's goroutine is not started by goSafe, so recover here
+			defer a.recoverPanic("live sync check")
+			a.checkForChanges()
+		})
+	}
+
+	for {
+		err := a.db.Listen(ctx, onChange)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("live sync unavailable, polling only", "err", err, "retry", liveSyncRetry)
+		select {
+		case <-time.After(liveSyncRetry):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkForChanges reloads the data when the change stamp moved without this
+// instance having refreshed, i.e. another session edited holdings, options,
+// or cash. Called off the event loop.
+func (a *App) checkForChanges() {
+	if a.stopping.Load() {
+		return
+	}
+	stamp, err := a.db.GetChangeStamp(context.Background())
+	if err != nil {
+		slog.Warn("checking for changes", "err", err)
+		return
+	}
+	a.queueUpdateDraw(func() {
+		a.reloadIfChanged(stamp)
+	})
+}
+
+// reloadIfChanged refreshes if stamp differs from the one seen by the last
+// refresh. Must run on the event loop.
+func (a *App) reloadIfChanged(stamp string) bool {
+	if stamp == a.changeStamp {
+		return false
+	}
+	slog.Info("reloading changes from another session")
+	a.refreshData()
+	a.statusBar.SetText(fmt.Sprintf(" [aqua]Reloaded changes from another session at %s", a.lastRefresh.Format("15:04:05")))
+	return true
+}
+
+// showConflictPrompt is shown when a save lost an optimistic-locking check:
+// what was changed or deleted elsewhere after its form was opened
+func (a *App) showConflictPrompt(what string, overwrite, reload func()) {
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("%s was changed or deleted in another session since you opened it.\n\nOverwrite it with your edit, or reload the latest values?", what)).
+		AddButtons([]string{"Reload", "Overwrite", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("conflict")
+			switch buttonLabel {
+			case "Reload":
+				reload()
+			case "Overwrite":
+				overwrite()
+			}
+		})
+
+	a.pages.AddPage("conflict", modal, true, true)
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/expr"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// Variables custom column expressions can use in each table. A variable with
+// no value for a row, such as price without a quote, shows the cell as "-".
+var (
+	holdingColumnVars = []string{"qty", "cost", "basis", "price", "value", "pl", "weight", "target"}
+	optionColumnVars  = []string{"strike", "premium", "qty", "dte", "fee", "close", "price", "iv"}
+)
+
+// customColumn is a stored column with its parsed expression
+type customColumn struct {
+	db.CustomColumn
+	expr *expr.Expr
+}
+
+// columnVars returns the variables available to columns of table
+func columnVars(table string) []string {
+	if table == db.ColumnTableOptions {
+		return optionColumnVars
+	}
+	return holdingColumnVars
+}
+
+// parseCustomColumn validates a column's expression against its table
+func parseCustomColumn(c db.CustomColumn) (customColumn, error) {
+	e, err := expr.Parse(c.Expr)
+	if err != nil {
+		return customColumn{}, err
+	}
+	if err := e.Check(columnVars(c.Table)); err != nil {
+		return customColumn{}, err
+	}
+	return customColumn{CustomColumn: c, expr: e}, nil
+}
+
+// loadCustomColumns reads the user-defined columns, skipping any whose
+// expression no longer parses
+func (a *App) loadCustomColumns(ctx context.Context) {
+	stored, err := a.db.GetCustomColumns(ctx)
+	if err != nil {
+		slog.Warn("loading custom columns", "err", err)
+	}
+	a.book.customColumns = a.book.customColumns[:0]
+	for _, c := range stored {
+		parsed, err := parseCustomColumn(c)
+		if err != nil {
+			slog.Warn("skipping custom column", "name", c.Name, "err", err)
+			continue
+		}
+		a.book.customColumns = append(a.book.customColumns, parsed)
+	}
+}
+
+// columnsFor returns the custom columns of table in display order
+func (b *book) columnsFor(table string) []customColumn {
+	var columns []customColumn
+	for _, c := range b.customColumns {
+		if c.Table == table {
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// setCustomHeaders adds the custom columns' headers to t after the first
+// offset columns
+func setCustomHeaders(t *tview.Table, columns []customColumn, offset int) {
+	for i, c := range columns {
+		t.SetCell(0, offset+i, tview.NewTableCell(" "+strings.ToUpper(c.Name)+" ").
+			SetTextColor(tcell.ColorBlack).
+			SetBackgroundColor(tcell.ColorTeal).
+			SetAlign(tview.AlignLeft).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+}
+
+// setCustomCells evaluates the custom columns for one row of t
+func (b *book) setCustomCells(t *tview.Table, row int, columns []customColumn, offset int, vars map[string]float64, bg tcell.Color) {
+	for i, c := range columns {
+		text, color := " - ", tcell.ColorDimGray
+		if v, err := c.expr.Eval(vars); err == nil {
+			text, color = " "+b.locale.FormatFloat(v, 2)+" ", tcell.ColorWhite
+		}
+		t.SetCell(row, offset+i, tview.NewTableCell(text).
+			SetTextColor(color).
+			SetBackgroundColor(bg).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+	}
+}
+
+// holdingVars are a holdings row's values for custom columns. value is
+// already capped at any short call strike, as in the VALUE column.
+func (b *book) holdingVars(h db.Holding, value, weight decimal.Decimal) map[string]float64 {
+	basis := h.Quantity.Mul(h.AvgCost)
+	vars := map[string]float64{
+		"qty":    h.Quantity.InexactFloat64(),
+		"cost":   h.AvgCost.InexactFloat64(),
+		"basis":  basis.InexactFloat64(),
+		"weight": weight.InexactFloat64(),
+	}
+	if q, ok := b.quotes[h.Ticker]; ok {
+		vars["price"] = q.Price
+		vars["value"] = value.InexactFloat64()
+		vars["pl"] = value.Sub(basis).InexactFloat64()
+	}
+	if h.TargetPrice.Valid {
+		vars["target"] = h.TargetPrice.Decimal.InexactFloat64()
+	}
+	return vars
+}
+
+// optionVars are an options row's values for custom columns
+func (b *book) optionVars(o db.Option, today time.Time) map[string]float64 {
+	strike := o.Strike.InexactFloat64()
+	vars := map[string]float64{
+		"strike":  strike,
+		"premium": o.Premium.InexactFloat64(),
+		"qty":     float64(o.Quantity),
+		"dte":     float64(int(o.ExpiryDate.Sub(today).Hours() / 24)),
+		"fee":     o.OpenFee.InexactFloat64(),
+	}
+	if o.ClosePremium.Valid {
+		vars["close"] = o.ClosePremium.Decimal.InexactFloat64()
+	}
+	if q, ok := b.quotes[o.Ticker]; ok && q.Price > 0 {
+		vars["price"] = q.Price
+	}
+	if cached, ok := b.optionIVs[contractKey(o.Ticker, o.OptionType, o.ExpiryDate, strike)]; ok && cached.iv > 0 {
+		vars["iv"] = cached.iv
+	}
+	return vars
+}
+
+// showColumnsView lists the custom columns and the variables each table
+// offers; h opens the layout of the built-in holdings columns
+func (a *App) showColumnsView() {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	view.SetBorder(true).
+		SetTitle(" Custom Columns ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'a':
+			if !a.readOnly() {
+				a.showColumnForm(view)
+			}
+			return nil
+		case 'd':
+			if !a.readOnly() && len(a.book.customColumns) > 0 {
+				a.showDeleteColumnForm(view)
+			}
+			return nil
+		case 'h':
+			a.showHoldingsLayoutView()
+			return nil
+		}
+		return event
+	})
+
+	view.SetText(a.buildColumnsReport())
+	a.pages.AddPage("columns", view, true, true)
+}
+
+// buildColumnsReport renders the columns and the expression syntax
+func (a *App) buildColumnsReport() string {
+	var sb strings.Builder
+	if len(a.book.customColumns) == 0 {
+		sb.WriteString(" No custom columns. Press [yellow]a[white] to add one.\n")
+	} else {
+		fmt.Fprintf(&sb, " [yellow]%-10s %-16s %s[white]\n", "TABLE", "NAME", "EXPRESSION")
+		for _, c := range a.book.customColumns {
+			fmt.Fprintf(&sb, " %-10s %-16s %s\n", strings.ToLower(c.Table), c.Name, tview.Escape(c.Expr))
+		}
+	}
+
+	sb.WriteString("\n [teal]Holdings variables:[white] " + strings.Join(holdingColumnVars, ", "))
+	sb.WriteString("\n [teal]Options variables:[white]  " + strings.Join(optionColumnVars, ", "))
+	sb.WriteString("\n [teal]Syntax:[white] numbers, + - * / ^, parentheses, abs(x), min(x, y, ...), max(x, y, ...)")
+	sb.WriteString("\n [gray]e.g. annualized % return on collateral: premium/strike/dte*365*100")
+	sb.WriteString("\n [gray]A cell shows - when a variable has no value for the row or it divides by zero")
+	sb.WriteString("\n\n [yellow]a[white]:Add/Replace  [yellow]d[white]:Delete  [yellow]h[white]:Holdings columns  [gray]ESC to close")
+	return sb.String()
+}
+
+// saveCustomColumns stores columns and redraws the tables with them
+func (a *App) saveCustomColumns(columns []db.CustomColumn) error {
+	ctx := context.Background()
+	if err := a.db.SetCustomColumns(ctx, columns); err != nil {
+		return err
+	}
+	a.loadCustomColumns(ctx)
+	a.holdingsView.update()
+	a.optionsView.update()
+	return nil
+}
+
+// storedColumns returns the custom columns as stored
+func (a *App) storedColumns() []db.CustomColumn {
+	columns := make([]db.CustomColumn, len(a.book.customColumns))
+	for i, c := range a.book.customColumns {
+		columns[i] = c.CustomColumn
+	}
+	return columns
+}
+
+// showColumnForm adds a column, or replaces the expression of the column of
+// the same name in the same table
+func (a *App) showColumnForm(report *tview.TextView) {
+	tables := []string{db.ColumnTableHoldings, db.ColumnTableOptions}
+	form := tview.NewForm().
+		AddDropDown("Table", tables, 0, nil).
+		AddInputField("Name", "", 16, nil, nil).
+		AddInputField("Expression", "", 50, nil, nil)
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		_, table := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+		column := db.CustomColumn{
+			Table: table,
+			Name:  strings.TrimSpace(form.GetFormItem(1).(*tview.InputField).GetText()),
+			Expr:  strings.TrimSpace(form.GetFormItem(2).(*tview.InputField).GetText()),
+		}
+		if column.Name == "" {
+			a.statusBar.SetText(" [red]Name is required")
+			return
+		}
+		if _, err := parseCustomColumn(column); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Invalid expression: %s", tview.Escape(err.Error())))
+			return
+		}
+
+		columns := a.storedColumns()
+		replaced := false
+		for i, c := range columns {
+			if c.Table == column.Table && strings.EqualFold(c.Name, column.Name) {
+				columns[i] = column
+				replaced = true
+			}
+		}
+		if !replaced {
+			columns = append(columns, column)
+		}
+		if err := a.saveCustomColumns(columns); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+
+		a.pages.RemovePage("columnform")
+		report.SetText(a.buildColumnsReport())
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("columnform")
+	})
+
+	form.SetBorder(true).SetTitle(" Custom Column ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("columnform", form, 70, 11)
+}
+
+// showDeleteColumnForm removes one custom column
+func (a *App) showDeleteColumnForm(report *tview.TextView) {
+	labels := make([]string, len(a.book.customColumns))
+	for i, c := range a.book.customColumns {
+		labels[i] = strings.ToLower(c.Table) + ": " + c.Name
+	}
+
+	form := tview.NewForm().
+		AddDropDown("Column", labels, 0, nil)
+
+	styleForm(form)
+
+	form.AddButton("Delete", func() {
+		i, _ := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+		columns := a.storedColumns()
+		columns = append(columns[:i], columns[i+1:]...)
+		if err := a.saveCustomColumns(columns); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+
+		a.pages.RemovePage("columndelete")
+		report.SetText(a.buildColumnsReport())
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("columndelete")
+	})
+
+	form.SetBorder(true).SetTitle(" Delete Custom Column ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("columndelete", form, 50, 7)
+}
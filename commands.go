@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"anyhowhodl/internal/cli"
+)
+
+// cliSpec describes the command line; completions and the man page are generated from it
+var cliSpec = cli.Spec{
+	Name:    "anyhowhodl",
+	Summary: "terminal portfolio and options tracker",
+	Description: "anyhowhodl tracks holdings, options, and cash in a Postgres database " +
+		"and shows them in a terminal UI with live Yahoo Finance quotes. " +
+		"Run without arguments to start the TUI. Configuration is read from the " +
+		"environment and from a .env file in the working directory.",
+	Commands: []cli.Command{
+		{
+			Name:    "daemon",
+			Summary: "run background jobs without the TUI",
+			Description: "Runs the configured background jobs every DAEMON_INTERVAL until interrupted: " +
+				"the ICS feed, daily CSP history, daily price history, Telegram bot, alert notifications, and broker sync.",
+		},
+		{
+			Name:        "serve",
+			Summary:     "run the daemon plus the webhook endpoint",
+			Description: "Like daemon, and also accepts trade and alert webhooks on WEBHOOK_ADDR, authenticated with WEBHOOK_SECRET.",
+		},
+		{
+			Name:    "backup",
+			Summary: "write a JSON backup of the book and exit",
+			Description: "Writes cash, holdings, all options, and the CSP watchlist as JSON to the given file " +
+				"(default anyhowhodl-backup-<time>.json) and prints the path. The file is encrypted " +
+				"when EXPORT_ENCRYPTION and EXPORT_RECIPIENT are set.",
+		},
+		{
+			Name:    "prices",
+			Summary: "download daily price history into the database and exit",
+			Args:    []string{"sync"},
+			Description: "prices sync stores daily open, high, low, and close for every held, optioned, and CSP watchlist " +
+				"ticker, plus the beta benchmarks, in the price_history table (schema_history.sql). The first sync downloads " +
+				"two years; later ones only the days since. Charts, RSI, and beta then read the stored history instead of " +
+				"fetching it from Yahoo. The daemon syncs once a day.",
+		},
+		{
+			Name:    "status",
+			Summary: "print a one-line portfolio summary for tmux, i3, or waybar status bars and exit",
+			Args:    statusStyles,
+			Description: "Prints total value, the holdings' change today, and this tax year's net premium on one line, " +
+				"colored for the given status bar (plain by default). Quotes come from the cache the TUI saves and are " +
+				"refreshed at most every 15 minutes, so the command can be polled often without hitting Yahoo.",
+		},
+		{
+			Name:    "completion",
+			Summary: "print a shell completion script",
+			Args:    []string{"bash", "zsh", "fish"},
+		},
+		{
+			Name:    "man",
+			Summary: "print the man page (roff)",
+		},
+		{
+			Name:    "help",
+			Summary: "show usage",
+		},
+	},
+	Flags: []cli.Flag{
+		{Name: demoFlag, Summary: "start the TUI on a sample portfolio kept in memory, without a database"},
+	},
+	Env: []cli.EnvVar{
+		{Name: "DATABASE_URL", Description: "Postgres connection string (required except for --demo, completion, man, and help)."},
+		{Name: "LOG_FILE", Description: "Log file path (default: anyhowhodl/anyhowhodl.log in the user cache directory)."},
+		{Name: "LOG_LEVEL", Description: "debug, info (default), warn, or error."},
+		{Name: "ACCESS_ROLE", Description: "Set to viewer to open the database read-only even with owner credentials."},
+		{Name: "CPU_PROFILE", Description: "Write a CPU profile for the session to this path (inspect with go tool pprof)."},
+		{Name: "DAEMON_INTERVAL", Description: "How often daemon jobs run, as a Go duration (default 15m)."},
+		{Name: "ICS_FEED_PATH", Description: "Write an ICS calendar of option expiries and earnings to this path."},
+		{Name: "CSP_HISTORY_PATH", Description: "Append a daily CSP watchlist scan to this CSV file."},
+		{Name: "TELEGRAM_BOT_TOKEN", Description: "Enable the Telegram bot with this token."},
+		{Name: "TELEGRAM_CHAT_ID", Description: "The only chat the Telegram bot answers and alerts."},
+		{Name: "NOTIFY_WEBHOOK_URL", Description: "Enable the webhook notification channel, posting JSON events to this URL."},
+		{Name: "SMTP_ADDR", Description: "SMTP server host:port for the email notification channel (with NOTIFY_EMAIL_TO)."},
+		{Name: "SMTP_USERNAME", Description: "SMTP login for email notifications, and the default sender."},
+		{Name: "SMTP_PASSWORD", Description: "SMTP password for email notifications."},
+		{Name: "NOTIFY_EMAIL_FROM", Description: "Sender address of email notifications."},
+		{Name: "NOTIFY_EMAIL_TO", Description: "Comma-separated recipients of email notifications."},
+		{Name: "PLAID_CLIENT_ID", Description: "Enable read-only broker sync via Plaid (with PLAID_SECRET and PLAID_ACCESS_TOKEN)."},
+		{Name: "PLAID_ENV", Description: "Plaid environment: sandbox, development, or production (default)."},
+		{Name: "EXPORT_ENCRYPTION", Description: "Encrypt backups and CSP exports with age or gpg (the tool must be installed)."},
+		{Name: "EXPORT_RECIPIENT", Description: "age public key or gpg key ID that exports are encrypted to."},
+		{Name: "WEBHOOK_SECRET", Description: "Shared secret required by serve mode webhooks."},
+		{Name: "WEBHOOK_ADDR", Description: "Listen address for serve mode (default :8080)."},
+	},
+}
+
+// runOfflineCommand handles subcommands that need no database. Returns false
+// if args name a command that should continue to normal startup.
+func runOfflineCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	switch args[0] {
+	case "completion":
+		shell := ""
+		if len(args) > 1 {
+			shell = args[1]
+		}
+		switch shell {
+		case "bash":
+			fmt.Print(cliSpec.Bash())
+		case "zsh":
+			fmt.Print(cliSpec.Zsh())
+		case "fish":
+			fmt.Print(cliSpec.Fish())
+		default:
+			fmt.Fprintln(os.Stderr, "usage: anyhowhodl completion bash|zsh|fish")
+			os.Exit(2)
+		}
+		return true
+	case "man":
+		fmt.Print(cliSpec.Man(time.Now()))
+		return true
+	case "help", "-h", "--help":
+		fmt.Print(cliSpec.Usage())
+		return true
+	}
+
+	if _, ok := cliSpec.LookupFlag(args[0]); ok {
+		return false
+	}
+	if _, ok := cliSpec.Lookup(args[0]); !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", args[0], cliSpec.Usage())
+		os.Exit(2)
+	}
+	return false
+}
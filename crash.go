@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"anyhowhodl/internal/crash"
+)
+
+// goSafe runs fn in a background goroutine whose panics are reported instead
+// of killing the process with the terminal left in raw mode. Once shutdown
+// has begun fn is dropped, since the wait group may already be waited on.
+func (a *App) goSafe(name string, fn func()) {
+	a.tasksMu.Lock()
+	defer a.tasksMu.Unlock()
+	if a.stopping.Load() {
+		slog.Debug("dropping background task during shutdown", "task", name)
+		return
+	}
+	a.tasks.Add(1)
+	go func() {
+		defer a.tasks.Done()
+		defer a.recoverPanic(name)
+		fn()
+	}()
+}
+
+// recoverPanic must be deferred directly. It writes a crash report next to the
+// log file and stops the TUI, which restores the terminal.
+func (a *App) recoverPanic(where string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	a.crashOnce.Do(func() {
+		stack := debug.Stack()
+		path, err := crash.WriteReport(filepath.Dir(a.logPath), where, r, stack, time.Now())
+		if err != nil {
+			slog.Error("panic", "where", where, "panic", r, "report_err", err)
+			a.crashMessage = fmt.Sprintf("anyhowhodl crashed in %s: %v\n\n%s", where, r, stack)
+			return
+		}
+		slog.Error("panic", "where", where, "panic", r, "report", path)
+		a.crashMessage = fmt.Sprintf("anyhowhodl crashed in %s: %v\nCrash report written to %s\n", where, r, path)
+	})
+
+	a.app.Stop()
+}
+
+// exitIfCrashed prints the crash summary after the TUI has stopped
+func (a *App) exitIfCrashed() {
+	if a.crashMessage == "" {
+		return
+	}
+	fmt.Fprint(os.Stderr, a.crashMessage)
+	os.Exit(2)
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/csp"
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/export"
+	"anyhowhodl/internal/importer"
+	"anyhowhodl/internal/query"
+
+	"github.com/rivo/tview"
+)
+
+// refreshCSPData rescans the CSP watchlist with the selected account's store
+func (a *App) refreshCSPData() {
+	a.cspView.refresh(a.db, a.query, a.role != db.RoleViewer)
+}
+
+// showAddCSPWatchForm shows the form to add a ticker to CSP watchlist
+func (a *App) showAddCSPWatchForm() {
+	form := tview.NewForm()
+	form.SetBorder(true).
+		SetTitle(" Add to CSP Watchlist ").
+		SetTitleAlign(tview.AlignCenter)
+
+	ticker := ""
+	notes := ""
+
+	form.AddInputField("Ticker", "", 10, func(text string, lastChar rune) bool {
+		// Auto-uppercase and limit to letters
+		if lastChar >= 'a' && lastChar <= 'z' {
+			lastChar = lastChar - 'a' + 'A'
+		}
+		return (lastChar >= 'A' && lastChar <= 'Z') || lastChar == 0
+	}, func(text string) {
+		ticker = text
+	})
+
+	form.AddInputField("Notes (optional)", "", 50, nil, func(text string) {
+		notes = text
+	})
+
+	cycle := csp.CycleAll
+	labels := make([]string, len(csp.ExpiryCycles))
+	for i, c := range csp.ExpiryCycles {
+		labels[i] = cycleLabel(c)
+	}
+	form.AddDropDown("Expiries", labels, 0, func(option string, index int) {
+		cycle = csp.ExpiryCycles[index]
+	})
+
+	form.AddButton("Add", func() {
+		if ticker == "" {
+			return
+		}
+
+		ctx := context.Background()
+		err := a.db.AddCSPWatchTicker(ctx, ticker, notes)
+		if err == nil && cycle != csp.CycleAll {
+			err = a.db.SetCSPWatchExpiries(ctx, ticker, string(cycle))
+		}
+		if err != nil {
+			a.pages.RemovePage("add_csp_watch")
+			errorModal := tview.NewModal().
+				SetText(fmt.Sprintf("Failed to add ticker: %v", err)).
+				AddButtons([]string{"OK"}).
+				SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+					a.pages.RemovePage("error")
+				})
+			a.pages.AddPage("error", errorModal, true, true)
+			return
+		}
+
+		a.pages.RemovePage("add_csp_watch")
+		a.refreshCSPData()
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("add_csp_watch")
+	})
+
+	styleForm(form)
+
+	a.pages.AddPage("add_csp_watch", form, true, true)
+}
+
+// cycleLabel describes an expiry cycle for the add form and status bar
+func cycleLabel(c csp.ExpiryCycle) string {
+	switch c {
+	case csp.CycleWeekly:
+		return "Weeklies only"
+	case csp.CycleMonthly:
+		return "Monthlies only"
+	}
+	return "All expiries"
+}
+
+// cycleCSPExpiries moves the watchlist ticker at index on to the next
+// expiry cycle (all, weeklies only, monthlies only) and rescans
+func (a *App) cycleCSPExpiries(index int) {
+	if index < 0 || index >= len(a.cspView.watchlist) {
+		return
+	}
+	item := a.cspView.watchlist[index]
+	cycle, _ := csp.ParseExpiryCycle(item.Expiries)
+	next := cycle.Next()
+	if err := a.db.SetCSPWatchExpiries(context.Background(), item.Ticker, string(next)); err != nil {
+		a.cspView.statusBar.Clear()
+		fmt.Fprintf(a.cspView.statusBar, "[red]Failed to set expiries (run the expiries migration in schema_csp.sql): %v", err)
+		return
+	}
+	a.refreshCSPData()
+	a.cspView.statusBar.Clear()
+	fmt.Fprintf(a.cspView.statusBar, "[green]%s: %s", item.Ticker, strings.ToLower(cycleLabel(next)))
+}
+
+// showRemoveCSPWatchConfirm confirms removal of a ticker from watchlist
+func (a *App) showRemoveCSPWatchConfirm(index int) {
+	if index < 0 || index >= len(a.cspView.watchlist) {
+		return
+	}
+
+	ticker := a.cspView.watchlist[index].Ticker
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Remove %s from CSP watchlist?", ticker)).
+		AddButtons([]string{"Remove", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("confirm_remove_csp")
+			if buttonLabel == "Remove" {
+				ctx := context.Background()
+				err := a.db.RemoveCSPWatchTicker(ctx, ticker)
+				if err != nil {
+					errorModal := tview.NewModal().
+						SetText(fmt.Sprintf("Failed to remove ticker: %v", err)).
+						AddButtons([]string{"OK"}).
+						SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+							a.pages.RemovePage("error")
+						})
+					a.pages.AddPage("error", errorModal, true, true)
+					return
+				}
+				a.refreshCSPData()
+			}
+		})
+
+	a.pages.AddPage("confirm_remove_csp", modal, true, true)
+}
+
+// showCSPExportForm writes the current CSP table to CSV or JSON (by file extension)
+func (a *App) showCSPExportForm() {
+	results := a.cspView.results()
+	if len(results) == 0 {
+		a.cspView.statusBar.Clear()
+		fmt.Fprintf(a.cspView.statusBar, "[yellow]Nothing to export. Press [white]r[yellow] to scan first.")
+		return
+	}
+
+	enc, encErr := exportEncryption()
+
+	defaultPath := fmt.Sprintf("csp-scan-%s.csv", a.cspView.scannedAt.Format("20060102-1504"))
+	form := tview.NewForm().
+		AddInputField("File (.csv or .json)", defaultPath, 40, nil, nil)
+	if enc != nil {
+		form.AddCheckbox(fmt.Sprintf("Encrypt (%s: %s)", enc.Tool, enc.Recipient), true, nil)
+	}
+	styleForm(form)
+
+	form.AddButton("Export", func() {
+		path := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		if path == "" {
+			return
+		}
+		useEnc := enc
+		if enc != nil && !form.GetFormItem(1).(*tview.Checkbox).IsChecked() {
+			useEnc = nil
+		}
+
+		// The format follows the name as typed, before any encryption extension
+		asJSON := strings.EqualFold(filepath.Ext(path), ".json")
+		err := encErr
+		if err == nil {
+			var w io.WriteCloser
+			w, path, err = export.Create(path, useEnc)
+			if err == nil {
+				if asJSON {
+					err = export.WriteCSPJSON(w, results)
+				} else {
+					err = export.WriteCSPCSVLocale(w, results, true, a.book.locale)
+				}
+				if closeErr := w.Close(); err == nil {
+					err = closeErr
+				}
+			}
+		}
+
+		a.pages.RemovePage("csp_export")
+		a.cspView.statusBar.Clear()
+		if err != nil {
+			fmt.Fprintf(a.cspView.statusBar, "[red]Export failed: %v", err)
+			return
+		}
+		fmt.Fprintf(a.cspView.statusBar, "[lime]Exported %d row(s) to %s", len(results), path)
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("csp_export")
+	})
+
+	form.SetBorder(true).SetTitle(" Export CSP Scan ").SetTitleAlign(tview.AlignLeft)
+
+	height := 7
+	if enc != nil {
+		height = 9
+	}
+	a.createModalPage("csp_export", form, 60, height)
+}
+
+// tradingViewHoldingsSection names the watchlist section holding tickers are written to
+const tradingViewHoldingsSection = "Holdings"
+
+// showTradingViewForm imports or exports a TradingView watchlist file
+func (a *App) showTradingViewForm() {
+	form := tview.NewForm().
+		AddInputField("File", "anyhowhodl-watchlist.txt", 40, nil, nil)
+	styleForm(form)
+
+	path := func() string {
+		return strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+	}
+	done := func(format string, args ...any) {
+		a.pages.RemovePage("tradingview")
+		a.cspView.statusBar.Clear()
+		fmt.Fprintf(a.cspView.statusBar, format, args...)
+	}
+
+	form.AddButton("Import", func() {
+		data, err := os.ReadFile(path())
+		if err != nil {
+			done("[red]Import failed: %v", err)
+			return
+		}
+
+		existing := make(map[string]bool)
+		for _, item := range a.cspView.watchlist {
+			existing[item.Ticker] = true
+		}
+
+		// Holdings need a quantity and cost, so only watchlist tickers are imported
+		ctx := context.Background()
+		added := 0
+		for _, section := range importer.ParseTradingView(string(data)) {
+			if strings.EqualFold(section.Name, tradingViewHoldingsSection) {
+				continue
+			}
+			for _, ticker := range section.Tickers {
+				if existing[ticker] {
+					continue
+				}
+				if err := a.db.AddCSPWatchTicker(ctx, ticker, "Imported from TradingView"); err != nil {
+					done("[red]Import failed at %s: %v", ticker, err)
+					return
+				}
+				existing[ticker] = true
+				added++
+			}
+		}
+
+		done("[lime]Added %d ticker(s) to the CSP watchlist", added)
+		if added > 0 {
+			a.goSafe("csp refresh", a.refreshCSPData)
+		}
+	})
+
+	form.AddButton("Export", func() {
+		var held []string
+		seen := make(map[string]bool)
+		for _, h := range a.book.holdings {
+			if !seen[h.Ticker] {
+				held = append(held, h.Ticker)
+				seen[h.Ticker] = true
+			}
+		}
+		watch := make([]string, len(a.cspView.watchlist))
+		for i, item := range a.cspView.watchlist {
+			watch[i] = item.Ticker
+		}
+		sections := []importer.WatchlistSection{
+			{Name: tradingViewHoldingsSection, Tickers: held},
+			{Name: "CSP Watchlist", Tickers: watch},
+		}
+
+		f, err := os.Create(path())
+		if err == nil {
+			err = export.WriteTradingView(f, sections)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			done("[red]Export failed: %v", err)
+			return
+		}
+		done("[lime]Exported %d holding and %d watchlist ticker(s) to %s", len(held), len(watch), path())
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("tradingview")
+	})
+
+	form.SetBorder(true).SetTitle(" TradingView Watchlist ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("tradingview", form, 60, 7)
+}
+
+// ContractInfo stores selected contract details for display
+type ContractInfo struct {
+	Strike  float64
+	Expiry  time.Time
+	DTE     int
+	Delta   float64
+	Premium float64 // Put mid, per share
+	OI      query.OIChange
+}
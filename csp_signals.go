@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"anyhowhodl/internal/csp"
+	"anyhowhodl/internal/db"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showSignalsView lists the signals the CSP composite score is built from:
+// the built-in ones and the user-declared expression signals
+func (a *App) showSignalsView() {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	view.SetBorder(true).
+		SetTitle(" CSP Signals ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'a':
+			if !a.readOnly() {
+				a.showSignalForm(view)
+			}
+			return nil
+		case 'd':
+			if !a.readOnly() {
+				if signals, err := a.db.GetCSPSignals(context.Background()); err == nil && len(signals) > 0 {
+					a.showDeleteSignalForm(view, signals)
+				}
+			}
+			return nil
+		}
+		return event
+	})
+
+	view.SetText(a.buildSignalsReport())
+	a.pages.AddPage("signals", view, true, true)
+}
+
+// buildSignalsReport renders the signals, their weights and the expression
+// syntax
+func (a *App) buildSignalsReport() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, " [yellow]%-16s %-8s %s[white]\n", "SIGNAL", "WEIGHT", "SCORE")
+	for _, s := range csp.BuiltinSignals() {
+		fmt.Fprintf(&sb, " %-16s %-8s [gray]built in[white]\n", s.Name(), a.book.locale.FormatFloat(s.Weight(), 2))
+	}
+	custom, err := a.db.GetCSPSignals(context.Background())
+	if err != nil {
+		fmt.Fprintf(&sb, " [red]Error loading signals: %v[white]\n", err)
+	}
+	for _, s := range custom {
+		line := fmt.Sprintf(" %-16s %-8s %s", s.Name, a.book.locale.FormatFloat(s.Weight, 2), tview.Escape(s.Expr))
+		if _, err := csp.NewExprSignal(s.Name, s.Weight, s.Expr); err != nil {
+			line += " [red](skipped: " + tview.Escape(err.Error()) + ")[white]"
+		}
+		sb.WriteString(line + "\n")
+	}
+	if len(custom) == 0 {
+		sb.WriteString("\n No signals of your own. Press [yellow]a[white] to add one.\n")
+	}
+
+	sb.WriteString("\n [teal]Variables:[white] " + strings.Join(csp.SignalVars, ", "))
+	sb.WriteString("\n [teal]Syntax:[white] numbers, + - * / ^, parentheses, abs(x), min(x, y, ...), max(x, y, ...)")
+	sb.WriteString("\n [gray]A signal scores 0 (poor time to sell puts) to 100 (good); results outside are clamped")
+	sb.WriteString("\n [gray]e.g. favour oversold tickers: 100 - rsi")
+	sb.WriteString("\n [gray]Weights are relative; a signal without a value for a ticker is left out of its score")
+	sb.WriteString("\n [gray]Changes apply from the next refresh")
+	sb.WriteString("\n\n [yellow]a[white]:Add/Replace  [yellow]d[white]:Delete  [gray]ESC to close")
+	return sb.String()
+}
+
+// showSignalForm adds a signal, or replaces the one of the same name
+func (a *App) showSignalForm(report *tview.TextView) {
+	form := tview.NewForm().
+		AddInputField("Name", "", 16, nil, nil).
+		AddInputField("Weight", "", 8, nil, nil).
+		AddInputField("Expression", "", 50, nil, nil)
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		signal := db.CSPSignal{
+			Name: strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText()),
+			Expr: strings.TrimSpace(form.GetFormItem(2).(*tview.InputField).GetText()),
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(form.GetFormItem(1).(*tview.InputField).GetText()), 64)
+		if err != nil {
+			a.cspView.statusBar.SetText("[red]Invalid weight")
+			return
+		}
+		signal.Weight = weight
+		for _, s := range csp.BuiltinSignals() {
+			if strings.EqualFold(s.Name(), signal.Name) {
+				a.cspView.statusBar.SetText("[red]" + tview.Escape(s.Name()) + " is a built-in signal")
+				return
+			}
+		}
+		if _, err := csp.NewExprSignal(signal.Name, signal.Weight, signal.Expr); err != nil {
+			a.cspView.statusBar.SetText(fmt.Sprintf("[red]Invalid signal: %s", tview.Escape(err.Error())))
+			return
+		}
+
+		ctx := context.Background()
+		signals, err := a.db.GetCSPSignals(ctx)
+		if err != nil {
+			a.cspView.statusBar.SetText(fmt.Sprintf("[red]Error: %v", err))
+			return
+		}
+		replaced := false
+		for i, s := range signals {
+			if strings.EqualFold(s.Name, signal.Name) {
+				signals[i] = signal
+				replaced = true
+			}
+		}
+		if !replaced {
+			signals = append(signals, signal)
+		}
+		if err := a.db.SetCSPSignals(ctx, signals); err != nil {
+			a.cspView.statusBar.SetText(fmt.Sprintf("[red]Error: %v", err))
+			return
+		}
+
+		a.pages.RemovePage("signalform")
+		report.SetText(a.buildSignalsReport())
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("signalform")
+	})
+
+	form.SetBorder(true).SetTitle(" CSP Signal ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("signalform", form, 70, 11)
+}
+
+// showDeleteSignalForm removes one user-declared signal
+func (a *App) showDeleteSignalForm(report *tview.TextView, signals []db.CSPSignal) {
+	labels := make([]string, len(signals))
+	for i, s := range signals {
+		labels[i] = s.Name
+	}
+
+	form := tview.NewForm().
+		AddDropDown("Signal", labels, 0, nil)
+
+	styleForm(form)
+
+	form.AddButton("Delete", func() {
+		i, _ := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+		signals = append(signals[:i], signals[i+1:]...)
+		if err := a.db.SetCSPSignals(context.Background(), signals); err != nil {
+			a.cspView.statusBar.SetText(fmt.Sprintf("[red]Error: %v", err))
+			return
+		}
+
+		a.pages.RemovePage("signaldelete")
+		report.SetText(a.buildSignalsReport())
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("signaldelete")
+	})
+
+	form.SetBorder(true).SetTitle(" Delete CSP Signal ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("signaldelete", form, 50, 7)
+}
@@ -3,86 +3,125 @@ package main
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"math"
 	"time"
 
+	"anyhowhodl/internal/analytics"
 	"anyhowhodl/internal/csp"
 	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/query"
+	"anyhowhodl/internal/yahoo"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
-// initCSPView sets up the CSP table and related UI components
-func (a *App) initCSPView() {
-	// Create CSP table
-	a.cspTable = tview.NewTable().
-		SetBorders(true).
-		SetSelectable(true, false).
-		SetFixed(1, 0).
-		SetSeparator(' ').
-		SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorDarkSlateGray))
-
-	// Create status bar
-	a.cspStatusBar = tview.NewTextView().
-		SetDynamicColors(true).
-		SetTextAlign(tview.AlignLeft)
-
-	// Create layout
-	a.cspSection = tview.NewFlex().
-		SetDirection(tview.FlexRow).
-		AddItem(a.cspTable, 0, 1, true).
-		AddItem(a.cspStatusBar, 1, 0, false)
+// CSPView is the CSP advisor page: the scored watchlist and its status bar
+type CSPView struct {
+	table     *tview.Table
+	statusBar *tview.TextView
+	section   *tview.Flex
+	watchlist []db.CSPWatchItem
+	scores    map[string]csp.SignalOutput
+	contracts map[string]ContractInfo
+	scannedAt time.Time // When the table was last scanned
+
+	book   *book
+	market yahoo.Provider
+	host   cspHost
+}
 
-	// Initialize data structures
-	a.cspScores = make(map[string]csp.SignalOutput)
-	a.cspWatchlist = []db.CSPWatchItem{}
+// cspHost is what the CSP view needs from the app: the exposure its headroom
+// column measures, the date, and the event loop it draws on while scanning
+type cspHost interface {
+	riskBook() analytics.RiskBook
+	capsForAnalytics() []analytics.RiskCap
+	today() time.Time
+	draw()
+	stopped() bool
 }
 
-// updateCSPTable refreshes the CSP advisor table with latest data
-func (a *App) updateCSPTable() {
-	a.cspTable.Clear()
+// newCSPView builds the CSP table and its status bar, scored with quotes
+// from market
+func newCSPView(b *book, market yahoo.Provider, host cspHost) *CSPView {
+	v := &CSPView{
+		table: tview.NewTable().
+			SetBorders(true).
+			SetSelectable(true, false).
+			SetFixed(1, 0).
+			SetSeparator(' ').
+			SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorDarkSlateGray)),
+		statusBar: tview.NewTextView().
+			SetDynamicColors(true).
+			SetTextAlign(tview.AlignLeft),
+		scores:    make(map[string]csp.SignalOutput),
+		watchlist: []db.CSPWatchItem{},
+		book:      b,
+		market:    market,
+		host:      host,
+	}
+	v.section = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(v.table, 0, 1, true).
+		AddItem(v.statusBar, 1, 0, false)
+	return v
+}
+
+// update refreshes the CSP advisor table with latest data
+func (v *CSPView) update() {
+	v.table.Clear()
 
 	// Header row
-	headers := []string{"TICKER", "PRICE", "STRIKE", "DTE", "DELTA", "CSP SCORE", "VIX", "IV RANK", "RSI", "P/C", "YIELD", "SIGNAL"}
+	headers := []string{"TICKER", "PRICE", "STRIKE", "DTE", "DELTA", "OI (CHG)", "CSP SCORE", "VIX", "IV RANK", "RSI", "P/C", "YIELD", "SIGNAL", "HEADROOM"}
 	for col, header := range headers {
 		cell := tview.NewTableCell(header).
 			SetTextColor(tcell.ColorYellow).
 			SetAlign(tview.AlignCenter).
 			SetSelectable(false).
 			SetExpansion(1)
-		a.cspTable.SetCell(0, col, cell)
+		v.table.SetCell(0, col, cell)
 	}
 
+	// Headroom under the risk caps for selling one more put
+	book := v.host.riskBook()
+	caps := v.host.capsForAnalytics()
+
 	// Data rows
 	row := 1
-	for _, item := range a.cspWatchlist {
+	for _, item := range v.watchlist {
 		ticker := item.Ticker
-		score, hasScore := a.cspScores[ticker]
+		score, hasScore := v.scores[ticker]
 
 		// Get quote for current price
-		quote, hasQuote := a.quotes[ticker]
+		quote, hasQuote := v.book.quotes[ticker]
 		priceStr := "N/A"
 		if hasQuote {
-			priceStr = fmt.Sprintf("$%.2f", quote.Price)
+			priceStr = "$" + v.book.locale.FormatFloat(quote.Price, 2)
 		}
 
-		// Ticker column
-		a.cspTable.SetCell(row, 0, tview.NewTableCell(ticker).
+		// Ticker column, marked wk or mo when held to weeklies or monthlies
+		v.table.SetCell(row, 0, tview.NewTableCell(ticker+cycleTag(item.Expiries)).
 			SetTextColor(tcell.ColorFuchsia).
 			SetAlign(tview.AlignCenter).
 			SetExpansion(1))
 
 		// Price column
-		a.cspTable.SetCell(row, 1, tview.NewTableCell(priceStr).
+		v.table.SetCell(row, 1, tview.NewTableCell(priceStr).
 			SetTextColor(tcell.ColorAqua).
 			SetAlign(tview.AlignRight).
 			SetExpansion(1))
 
+		// Extract contract info from the score metadata (stored during refresh)
+		contractInfo, hasContract := v.contracts[ticker]
+
+		// Headroom column
+		v.table.SetCell(row, len(headers)-1, v.headroomCell(book, caps, ticker, contractInfo.Strike*100))
+
 		if !hasScore {
 			// No score data available
-			for col := 2; col < len(headers); col++ {
-				a.cspTable.SetCell(row, col, tview.NewTableCell("N/A").
+			for col := 2; col < len(headers)-1; col++ {
+				v.table.SetCell(row, col, tview.NewTableCell("N/A").
 					SetTextColor(tcell.ColorDimGray).
 					SetAlign(tview.AlignCenter).
 					SetExpansion(1))
@@ -91,15 +130,12 @@ func (a *App) updateCSPTable() {
 			continue
 		}
 
-		// Extract contract info from the score metadata (stored during refresh)
-		contractInfo, hasContract := a.cspContractInfo[ticker]
-
 		// Strike column
 		strikeStr := "N/A"
 		if hasContract && contractInfo.Strike > 0 {
-			strikeStr = fmt.Sprintf("$%.2f", contractInfo.Strike)
+			strikeStr = "$" + v.book.locale.FormatFloat(contractInfo.Strike, 2)
 		}
-		a.cspTable.SetCell(row, 2, tview.NewTableCell(strikeStr).
+		v.table.SetCell(row, 2, tview.NewTableCell(strikeStr).
 			SetTextColor(tcell.ColorAqua).
 			SetAlign(tview.AlignRight).
 			SetExpansion(1))
@@ -109,7 +145,7 @@ func (a *App) updateCSPTable() {
 		if hasContract && contractInfo.DTE > 0 {
 			dteStr = fmt.Sprintf("%d", contractInfo.DTE)
 		}
-		a.cspTable.SetCell(row, 3, tview.NewTableCell(dteStr).
+		v.table.SetCell(row, 3, tview.NewTableCell(dteStr).
 			SetTextColor(tcell.ColorWhite).
 			SetAlign(tview.AlignCenter).
 			SetExpansion(1))
@@ -117,13 +153,23 @@ func (a *App) updateCSPTable() {
 		// Delta column
 		deltaStr := "N/A"
 		if hasContract && contractInfo.Delta != 0 {
-			deltaStr = fmt.Sprintf("%.2f", contractInfo.Delta)
+			deltaStr = v.book.locale.FormatFloat(contractInfo.Delta, 2)
 		}
-		a.cspTable.SetCell(row, 4, tview.NewTableCell(deltaStr).
+		v.table.SetCell(row, 4, tview.NewTableCell(deltaStr).
 			SetTextColor(tcell.ColorWhite).
 			SetAlign(tview.AlignCenter).
 			SetExpansion(1))
 
+		// Open interest column, with its change since the last day recorded
+		oiStr, oiColor := "N/A", tcell.ColorWhite
+		if hasContract {
+			oiStr, oiColor = v.book.oiText(contractInfo.OI)
+		}
+		v.table.SetCell(row, 5, tview.NewTableCell(oiStr).
+			SetTextColor(oiColor).
+			SetAlign(tview.AlignCenter).
+			SetExpansion(1))
+
 		// CSP Score column
 		scoreColor := tcell.ColorRed
 		if score.CompositeScore >= 70 {
@@ -131,13 +177,17 @@ func (a *App) updateCSPTable() {
 		} else if score.CompositeScore >= 50 {
 			scoreColor = tcell.ColorYellow
 		}
-		a.cspTable.SetCell(row, 5, tview.NewTableCell(fmt.Sprintf("%.1f", score.CompositeScore)).
+		v.table.SetCell(row, 6, tview.NewTableCell(v.book.locale.FormatFloat(score.CompositeScore, 1)).
 			SetTextColor(scoreColor).
 			SetAlign(tview.AlignCenter).
 			SetExpansion(1))
 
-		// VIX column
-		a.cspTable.SetCell(row, 6, tview.NewTableCell(fmt.Sprintf("%.1f", score.RawVIX)).
+		// VIX column, with its percentile over the VIX history when known
+		vixStr := v.book.locale.FormatFloat(score.RawVIX, 1)
+		if score.Signal != "" && !math.IsNaN(score.RawVIXPercentile) {
+			vixStr += fmt.Sprintf(" (%.0f%%)", score.RawVIXPercentile)
+		}
+		v.table.SetCell(row, 7, tview.NewTableCell(vixStr).
 			SetTextColor(tcell.ColorWhite).
 			SetAlign(tview.AlignCenter).
 			SetExpansion(1))
@@ -145,9 +195,9 @@ func (a *App) updateCSPTable() {
 		// IV Rank column
 		ivRankStr := "N/A"
 		if !math.IsNaN(score.RawIVRank) {
-			ivRankStr = fmt.Sprintf("%.1f", score.RawIVRank)
+			ivRankStr = v.book.locale.FormatFloat(score.RawIVRank, 1)
 		}
-		a.cspTable.SetCell(row, 7, tview.NewTableCell(ivRankStr).
+		v.table.SetCell(row, 8, tview.NewTableCell(ivRankStr).
 			SetTextColor(tcell.ColorWhite).
 			SetAlign(tview.AlignCenter).
 			SetExpansion(1))
@@ -155,21 +205,21 @@ func (a *App) updateCSPTable() {
 		// RSI column
 		rsiStr := "N/A"
 		if !math.IsNaN(score.RawRSI) {
-			rsiStr = fmt.Sprintf("%.1f", score.RawRSI)
+			rsiStr = v.book.locale.FormatFloat(score.RawRSI, 1)
 		}
-		a.cspTable.SetCell(row, 8, tview.NewTableCell(rsiStr).
+		v.table.SetCell(row, 9, tview.NewTableCell(rsiStr).
 			SetTextColor(tcell.ColorWhite).
 			SetAlign(tview.AlignCenter).
 			SetExpansion(1))
 
 		// P/C Ratio column
-		a.cspTable.SetCell(row, 9, tview.NewTableCell(fmt.Sprintf("%.2f", score.RawPutCallRatio)).
+		v.table.SetCell(row, 10, tview.NewTableCell(v.book.locale.FormatFloat(score.RawPutCallRatio, 2)).
 			SetTextColor(tcell.ColorWhite).
 			SetAlign(tview.AlignCenter).
 			SetExpansion(1))
 
 		// Yield column
-		a.cspTable.SetCell(row, 10, tview.NewTableCell(fmt.Sprintf("%.1f%%", score.RawPremiumYield)).
+		v.table.SetCell(row, 11, tview.NewTableCell(v.book.locale.FormatFloat(score.RawPremiumYield, 1)+"%").
 			SetTextColor(tcell.ColorWhite).
 			SetAlign(tview.AlignCenter).
 			SetExpansion(1))
@@ -181,7 +231,7 @@ func (a *App) updateCSPTable() {
 		} else if score.Signal == "MODERATE" {
 			signalColor = tcell.ColorYellow
 		}
-		a.cspTable.SetCell(row, 11, tview.NewTableCell(score.Signal).
+		v.table.SetCell(row, 12, tview.NewTableCell(score.Signal).
 			SetTextColor(signalColor).
 			SetAlign(tview.AlignCenter).
 			SetExpansion(1))
@@ -190,254 +240,161 @@ func (a *App) updateCSPTable() {
 	}
 
 	// Update status bar
-	a.updateCSPStatusBar()
+	v.showKeys()
 }
 
-// refreshCSPData fetches options data and computes scores for all watchlist tickers
-func (a *App) refreshCSPData() {
+// refresh fetches options data and computes scores for all watchlist
+// tickers, from store and queries. With record the scores are saved.
+func (v *CSPView) refresh(store db.Store, queries *query.Service, record bool) {
 	ctx := context.Background()
 
 	// Update status
-	a.cspStatusBar.Clear()
-	fmt.Fprintf(a.cspStatusBar, "[yellow]Loading CSP data...")
-	a.app.Draw()
+	v.statusBar.Clear()
+	fmt.Fprintf(v.statusBar, "[yellow]Loading CSP data...")
+	v.host.draw()
 
 	// Get watchlist from DB
-	watchlist, err := a.db.GetCSPWatchlist(ctx)
+	watchlist, err := store.GetCSPWatchlist(ctx)
 	if err != nil {
-		a.cspStatusBar.Clear()
-		fmt.Fprintf(a.cspStatusBar, "[red]Error loading watchlist: %v", err)
+		v.statusBar.Clear()
+		fmt.Fprintf(v.statusBar, "[red]Error loading watchlist: %v", err)
 		return
 	}
-	a.cspWatchlist = watchlist
+	v.watchlist = watchlist
 
-	if len(a.cspWatchlist) == 0 {
-		a.cspStatusBar.Clear()
-		fmt.Fprintf(a.cspStatusBar, "[yellow]No tickers in watchlist. Press [white]a[yellow] to add.")
-		a.updateCSPTable()
+	if len(v.watchlist) == 0 {
+		v.statusBar.Clear()
+		fmt.Fprintf(v.statusBar, "[yellow]No tickers in watchlist. Press [white]a[yellow] to add.")
+		v.update()
 		return
 	}
 
 	// Fetch VIX once (shared across all tickers)
-	vixQuote, err := a.yahoo.GetQuote("^VIX")
-	vix := 20.0 // Default VIX if fetch fails
-	if err == nil && vixQuote != nil {
-		vix = vixQuote.Price
-	}
+	vix := queries.VIX()
+	vixCloses := queries.VIXCloses()
+	history := queries.IndicatorHistory(context.Background())
+	signals := queries.Signals(context.Background())
 
 	// Fetch quotes for all tickers (for current prices)
-	tickers := make([]string, len(a.cspWatchlist))
-	for i, item := range a.cspWatchlist {
+	tickers := make([]string, len(v.watchlist))
+	for i, item := range v.watchlist {
 		tickers[i] = item.Ticker
 	}
-	quotes, _ := a.yahoo.GetQuotes(tickers)
-	a.quotes = quotes
+	quotes, _ := v.market.GetQuotes(tickers)
+	v.book.quotes = quotes
 
 	// Initialize contract info map
-	a.cspContractInfo = make(map[string]ContractInfo)
+	v.contracts = make(map[string]ContractInfo)
+	var results []query.CSPResult
 
 	// Process each ticker sequentially (with delay to avoid rate limiting)
-	for i, item := range a.cspWatchlist {
+	for i, item := range v.watchlist {
+		if v.host.stopped() {
+			return
+		}
 		ticker := item.Ticker
 
 		// Update status
-		a.cspStatusBar.Clear()
-		fmt.Fprintf(a.cspStatusBar, "[yellow]Loading %s (%d/%d)...", ticker, i+1, len(a.cspWatchlist))
-		a.app.Draw()
+		v.statusBar.Clear()
+		fmt.Fprintf(v.statusBar, "[yellow]Loading %s (%d/%d)...", ticker, i+1, len(v.watchlist))
+		v.host.draw()
 
-		// Fetch options chain
-		optionsData, err := a.yahoo.FetchOptionsChain(ticker)
+		cycle, _ := csp.ParseExpiryCycle(item.Expiries)
+		result, err := queries.ScoreCSPTicker(ticker, cycle, vix, vixCloses, history, signals)
 		if err != nil {
-			a.cspScores[ticker] = csp.SignalOutput{}
-			time.Sleep(200 * time.Millisecond)
-			continue
-		}
-
-		// Fetch price history for RSI
-		priceHistory, err := a.yahoo.FetchPriceHistory(ticker)
-		if err != nil || len(priceHistory) < 15 {
-			a.cspScores[ticker] = csp.SignalOutput{}
-			time.Sleep(200 * time.Millisecond)
+			v.scores[ticker] = csp.SignalOutput{}
+			time.Sleep(query.ScanDelay)
 			continue
 		}
+		v.scores[ticker] = result.Score
+		results = append(results, result)
 
-		// Select target contract
-		targetContract := csp.SelectTargetContract(*optionsData)
-		if targetContract == nil {
-			a.cspScores[ticker] = csp.SignalOutput{}
-			time.Sleep(200 * time.Millisecond)
-			continue
-		}
-
-		// Calculate IV Rank (collect all IVs from puts)
-		var allIVs []float64
-		for _, put := range optionsData.Puts {
-			if put.ImpliedVolatility > 0 {
-				allIVs = append(allIVs, put.ImpliedVolatility)
-			}
-		}
-
-		currentIV := targetContract.ImpliedVolatility
-		ivLow52w := currentIV
-		ivHigh52w := currentIV
-		if len(allIVs) > 0 {
-			for _, iv := range allIVs {
-				if iv < ivLow52w {
-					ivLow52w = iv
-				}
-				if iv > ivHigh52w {
-					ivHigh52w = iv
-				}
-			}
-		}
-
-		// Calculate total put/call volume for P/C ratio
-		var totalPutVolume, totalCallVolume float64
-		for _, put := range optionsData.Puts {
-			totalPutVolume += float64(put.Volume)
-		}
-		for _, call := range optionsData.Calls {
-			totalCallVolume += float64(call.Volume)
-		}
-
-		// Compute DTE
-		expTime := time.Unix(targetContract.Expiration, 0)
-		dte := int(time.Until(expTime).Hours() / 24)
-		if dte < 0 {
-			dte = 0
-		}
+		// Rate limiting
+		time.Sleep(query.ScanDelay)
+	}
+	v.scannedAt = time.Now()
 
-		// Build signal input
-		input := csp.SignalInput{
-			VIX:             vix,
-			CurrentIV:       currentIV,
-			IVHigh52w:       ivHigh52w,
-			IVLow52w:        ivLow52w,
-			ClosingPrices:   priceHistory,
-			TotalPutVolume:  totalPutVolume,
-			TotalCallVolume: totalCallVolume,
-			PutPremium:      (targetContract.Bid + targetContract.Ask) / 2,
-			StrikePrice:     targetContract.Strike,
-			DTE:             dte,
+	// Store contract info for display, with the target puts' open interest
+	// change since it was last recorded
+	if err := queries.TrackCSPOpenInterest(ctx, results, v.host.today()); err != nil {
+		slog.Debug("tracking CSP open interest", "err", err)
+	}
+	if record {
+		if err := queries.RecordScores(ctx, results, v.host.today()); err != nil {
+			slog.Debug("recording watchlist scores", "err", err)
 		}
-
-		// Compute signals
-		output := csp.ComputeSignals(input)
-		a.cspScores[ticker] = output
-
-		// Store contract info for display
-		a.cspContractInfo[ticker] = ContractInfo{
-			Strike: targetContract.Strike,
-			DTE:    dte,
-			Delta:  targetContract.Delta,
+	}
+	for _, r := range results {
+		v.contracts[r.Ticker] = ContractInfo{
+			Strike:  r.Strike,
+			Expiry:  r.Expiry,
+			DTE:     r.DTE,
+			Delta:   r.Delta,
+			Premium: r.Premium,
+			OI:      r.OI,
 		}
-
-		// Rate limiting
-		time.Sleep(200 * time.Millisecond)
 	}
 
 	// Update table and status
-	a.updateCSPTable()
-}
-
-// updateCSPStatusBar updates the CSP status bar
-func (a *App) updateCSPStatusBar() {
-	a.cspStatusBar.Clear()
-	fmt.Fprintf(a.cspStatusBar, "[lime]CSP Advisor[white] | [yellow]p[white]:Portfolio  [yellow]a[white]:Add  [yellow]d[white]:Remove  [yellow]r[white]:Refresh  [yellow]q[white]:Quit")
+	v.update()
 }
 
-// showAddCSPWatchForm shows the form to add a ticker to CSP watchlist
-func (a *App) showAddCSPWatchForm() {
-	form := tview.NewForm()
-	form.SetBorder(true).
-		SetTitle(" Add to CSP Watchlist ").
-		SetTitleAlign(tview.AlignCenter)
-
-	ticker := ""
-	notes := ""
-
-	form.AddInputField("Ticker", "", 10, func(text string, lastChar rune) bool {
-		// Auto-uppercase and limit to letters
-		if lastChar >= 'a' && lastChar <= 'z' {
-			lastChar = lastChar - 'a' + 'A'
-		}
-		return (lastChar >= 'A' && lastChar <= 'Z') || lastChar == 0
-	}, func(text string) {
-		ticker = text
-	})
-
-	form.AddInputField("Notes (optional)", "", 50, nil, func(text string) {
-		notes = text
-	})
-
-	form.AddButton("Add", func() {
-		if ticker == "" {
-			return
-		}
-
-		ctx := context.Background()
-		err := a.db.AddCSPWatchTicker(ctx, ticker, notes)
-		if err != nil {
-			a.pages.RemovePage("add_csp_watch")
-			errorModal := tview.NewModal().
-				SetText(fmt.Sprintf("Failed to add ticker: %v", err)).
-				AddButtons([]string{"OK"}).
-				SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-					a.pages.RemovePage("error")
-				})
-			a.pages.AddPage("error", errorModal, true, true)
-			return
-		}
-
-		a.pages.RemovePage("add_csp_watch")
-		a.refreshCSPData()
-	})
-
-	form.AddButton("Cancel", func() {
-		a.pages.RemovePage("add_csp_watch")
-	})
-
-	styleForm(form)
-
-	a.pages.AddPage("add_csp_watch", form, true, true)
+// showKeys puts the CSP key bindings in the status bar
+func (v *CSPView) showKeys() {
+	v.statusBar.Clear()
+	fmt.Fprintf(v.statusBar, "[lime]CSP Advisor[white] | [yellow]p[white]:Portfolio  [yellow]a[white]:Add  [yellow]d[white]:Remove  [yellow]e[white]:Expiries  [yellow]r[white]:Refresh  [yellow]x[white]:Export  [yellow]t[white]:TradingView  [yellow]s[white]:Signals  [yellow]i[white]:Queue idea  [yellow]u[white]:Ideas  [yellow]I[white]:IV Surface  [yellow]E[white]:Earnings  [yellow]H[white]:Banner  [yellow]^P[white]:Actions  [yellow]q[white]:Quit")
 }
 
-// showRemoveCSPWatchConfirm confirms removal of a ticker from watchlist
-func (a *App) showRemoveCSPWatchConfirm(index int) {
-	if index < 0 || index >= len(a.cspWatchlist) {
-		return
+// cycleTag marks a watchlist ticker held to weeklies or monthlies
+func cycleTag(expiries string) string {
+	switch csp.ExpiryCycle(expiries) {
+	case csp.CycleWeekly:
+		return " wk"
+	case csp.CycleMonthly:
+		return " mo"
 	}
+	return ""
+}
 
-	ticker := a.cspWatchlist[index].Ticker
-
-	modal := tview.NewModal().
-		SetText(fmt.Sprintf("Remove %s from CSP watchlist?", ticker)).
-		AddButtons([]string{"Remove", "Cancel"}).
-		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			a.pages.RemovePage("confirm_remove_csp")
-			if buttonLabel == "Remove" {
-				ctx := context.Background()
-				err := a.db.RemoveCSPWatchTicker(ctx, ticker)
-				if err != nil {
-					errorModal := tview.NewModal().
-						SetText(fmt.Sprintf("Failed to remove ticker: %v", err)).
-						AddButtons([]string{"OK"}).
-						SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-							a.pages.RemovePage("error")
-						})
-					a.pages.AddPage("error", errorModal, true, true)
-					return
-				}
-				a.refreshCSPData()
-			}
+// results collects the scored watchlist tickers currently shown in the table
+func (v *CSPView) results() []query.CSPResult {
+	var results []query.CSPResult
+	for _, item := range v.watchlist {
+		info, ok := v.contracts[item.Ticker]
+		if !ok {
+			continue
+		}
+		results = append(results, query.CSPResult{
+			Ticker:  item.Ticker,
+			Score:   v.scores[item.Ticker],
+			Strike:  info.Strike,
+			Expiry:  info.Expiry,
+			DTE:     info.DTE,
+			Delta:   info.Delta,
+			Premium: info.Premium,
+			OI:      info.OI,
+			Scanned: v.scannedAt,
 		})
-
-	a.pages.AddPage("confirm_remove_csp", modal, true, true)
+	}
+	return results
 }
 
-// ContractInfo stores selected contract details for display
-type ContractInfo struct {
-	Strike float64
-	DTE    int
-	Delta  float64
+// headroomCell shows how much more exposure ticker's caps allow, red when
+// one contract's collateral would not fit
+func (v *CSPView) headroomCell(book analytics.RiskBook, caps []analytics.RiskCap, ticker string, collateral float64) *tview.TableCell {
+	headroom, ok := book.Headroom(caps, ticker)
+	if !ok {
+		return tview.NewTableCell("-").
+			SetTextColor(tcell.ColorDimGray).
+			SetAlign(tview.AlignCenter).
+			SetExpansion(1)
+	}
+	color := tcell.ColorLime
+	if headroom < collateral || headroom <= 0 {
+		color = tcell.ColorRed
+	}
+	return tview.NewTableCell("$" + v.book.locale.FormatFloat(headroom, 0)).
+		SetTextColor(color).
+		SetAlign(tview.AlignRight).
+		SetExpansion(1)
 }
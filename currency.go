@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/yahoo"
+
+	"github.com/shopspring/decimal"
+)
+
+// currencySymbols are the symbols of common currencies; others are shown by
+// their code
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"CAD": "C$",
+	"AUD": "A$",
+	"HKD": "HK$",
+	"SGD": "S$",
+}
+
+// currencySymbol is what amounts in currency are prefixed with: "$", "€",
+// or the code and a space, as in "CHF 12.50"
+func currencySymbol(currency string) string {
+	if symbol, ok := currencySymbols[currency]; ok {
+		return symbol
+	}
+	return currency + " "
+}
+
+// rowCurrency is the currency of a holding or option, DefaultCurrency for
+// rows saved before currencies were stored
+func rowCurrency(currency string) string {
+	if currency == "" {
+		return db.DefaultCurrency
+	}
+	return currency
+}
+
+// parseCurrency reads a three-letter currency code, upper-casing it
+func parseCurrency(text string) (string, bool) {
+	code := strings.ToUpper(strings.TrimSpace(text))
+	if len(code) != 3 {
+		return "", false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", false
+		}
+	}
+	return code, true
+}
+
+// currencyCheck accepts a three-letter currency code
+func currencyCheck(text string) string {
+	if _, ok := parseCurrency(text); !ok {
+		return "use a 3-letter code like USD, EUR, GBP"
+	}
+	return ""
+}
+
+// loadBaseCurrency returns the currency values are converted into, the
+// default if it cannot be read
+func loadBaseCurrency(ctx context.Context, store db.Store) string {
+	currency, err := store.GetBaseCurrency(ctx)
+	if err != nil {
+		slog.Warn("loading base currency", "err", err)
+		return db.DefaultCurrency
+	}
+	return currency
+}
+
+// base is the currency table totals and the summary are shown in
+func (b *book) base() string {
+	if b.baseCurrency == "" {
+		return db.DefaultCurrency
+	}
+	return b.baseCurrency
+}
+
+// baseSymbol prefixes amounts in the base currency
+func (b *book) baseSymbol() string {
+	return currencySymbol(b.base())
+}
+
+// currencies lists the currencies of the loaded holdings and options, and of
+// the other accounts' holdings, besides the base currency
+func (b *book) currencies() []string {
+	var out []string
+	add := func(currency string) {
+		if c := rowCurrency(currency); c != b.base() && !slices.Contains(out, c) {
+			out = append(out, c)
+		}
+	}
+	for _, h := range b.holdings {
+		add(h.Currency)
+	}
+	for _, o := range b.options {
+		add(o.Currency)
+	}
+	for _, book := range b.accountBooks {
+		for _, h := range book.Holdings {
+			add(h.Currency)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// fxSymbols are the currency pairs quoted alongside the tickers, to convert
+// the loaded currencies into the base currency
+func (a *App) fxSymbols() []string {
+	var symbols []string
+	for _, c := range a.book.currencies() {
+		symbols = append(symbols, yahoo.FXSymbol(c, a.book.base()))
+	}
+	return symbols
+}
+
+// loadFXRates takes the conversion rates from the currency pair quotes and,
+// when they change, saves them for trades to book their cash at
+func (a *App) loadFXRates() {
+	previous := a.book.fxRates
+	a.book.fxRates = yahoo.FXRates(a.book.quotes, a.book.currencies(), a.book.base())
+	if a.role == db.RoleViewer || maps.Equal(previous, a.book.fxRates) {
+		return
+	}
+	rates := db.FXRates{Base: a.book.base(), Rates: make(map[string]decimal.Decimal)}
+	for c, rate := range a.book.fxRates {
+		rates.Rates[c] = decimal.NewFromFloat(rate)
+	}
+	if err := a.db.SetFXRates(context.Background(), rates); err != nil {
+		slog.Warn("saving exchange rates", "err", err)
+	}
+}
+
+// ensureFXRate returns the rate from currency into the base currency that
+// store books a trade's cash at, fetching the currency pair's quote and
+// saving it when none is saved, e.g. for the first trade in a currency
+func ensureFXRate(ctx context.Context, store db.Store, market yahoo.Provider, currency string) (decimal.Decimal, error) {
+	base, err := store.GetBaseCurrency(ctx)
+	if err != nil || currency == base {
+		return decimal.NewFromInt(1), err
+	}
+	fx, err := store.GetFXRates(ctx)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if rate, ok := fx.Rates[currency]; ok && fx.Base == base {
+		return rate, nil
+	}
+	symbol := yahoo.FXSymbol(currency, base)
+	q, err := market.GetQuote(symbol)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("fetching %s: %w", symbol, err)
+	}
+	if q.Price <= 0 {
+		return decimal.Zero, fmt.Errorf("no quote for %s", symbol)
+	}
+	if fx.Base != base || fx.Rates == nil {
+		fx = db.FXRates{Base: base, Rates: make(map[string]decimal.Decimal)}
+	}
+	rate := decimal.NewFromFloat(q.Price)
+	fx.Rates[currency] = rate
+	return rate, store.SetFXRates(ctx, fx)
+}
+
+// toBase converts amount in currency into the base currency. Without a rate
+// the amount is left as it is; missingFX lists those currencies.
+func (b *book) toBase(amount decimal.Decimal, currency string) decimal.Decimal {
+	currency = rowCurrency(currency)
+	if currency == b.base() {
+		return amount
+	}
+	rate, ok := b.fxRates[currency]
+	if !ok {
+		return amount
+	}
+	return amount.Mul(decimal.NewFromFloat(rate))
+}
+
+// missingFX lists the loaded currencies there is no rate into the base
+// currency for
+func (b *book) missingFX() []string {
+	var missing []string
+	for _, c := range b.currencies() {
+		if _, ok := b.fxRates[c]; !ok {
+			missing = append(missing, c)
+		}
+	}
+	return missing
+}
+
+// premiumsInBase adds up premium summaries by currency in the base currency
+func (b *book) premiumsInBase(byCurrency map[string]db.PremiumSummary) *db.PremiumSummary {
+	var sum db.PremiumSummary
+	for currency, p := range byCurrency {
+		sum.CallPremiums = sum.CallPremiums.Add(b.toBase(p.CallPremiums, currency))
+		sum.PutPremiums = sum.PutPremiums.Add(b.toBase(p.PutPremiums, currency))
+		sum.TotalPremiums = sum.TotalPremiums.Add(b.toBase(p.TotalPremiums, currency))
+		sum.TotalFees = sum.TotalFees.Add(b.toBase(p.TotalFees, currency))
+		sum.CloseCosts = sum.CloseCosts.Add(b.toBase(p.CloseCosts, currency))
+		sum.NetPL = sum.NetPL.Add(b.toBase(p.NetPL, currency))
+		sum.CapitalAtRisk = sum.CapitalAtRisk.Add(b.toBase(p.CapitalAtRisk, currency))
+	}
+	return &sum
+}
+
+// tickerCurrency is the currency ticker's holding or options are in, or its
+// quote's, DefaultCurrency if neither is known
+func (b *book) tickerCurrency(ticker string) string {
+	for _, h := range b.holdings {
+		if h.Ticker == ticker {
+			return rowCurrency(h.Currency)
+		}
+	}
+	for _, o := range b.options {
+		if o.Ticker == ticker {
+			return rowCurrency(o.Currency)
+		}
+	}
+	if q, ok := b.quotes[ticker]; ok && q.Currency != "" {
+		// Not for subunits like GBp, London prices in pence
+		if c, ok := parseCurrency(q.Currency); ok && c == q.Currency {
+			return c
+		}
+	}
+	return db.DefaultCurrency
+}
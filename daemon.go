@@ -0,0 +1,559 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"anyhowhodl/internal/broker"
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/export"
+	"anyhowhodl/internal/ical"
+	"anyhowhodl/internal/notify"
+	"anyhowhodl/internal/query"
+	"anyhowhodl/internal/yahoo"
+)
+
+// defaultDaemonInterval is used when DAEMON_INTERVAL is unset or invalid
+const defaultDaemonInterval = 15 * time.Minute
+
+// earningsLookahead limits calendar entries to earnings in the near future
+const earningsLookahead = 90 * 24 * time.Hour
+
+// sentAlertsKept is how long an alert key is remembered as notified. Keys
+// carry the day they fire for, so an older key cannot come back; two days
+// covers the day boundary in any time zone.
+const sentAlertsKept = 48 * time.Hour
+
+// eodSummaryAt is when, in exchange time, the end-of-day summary is sent:
+// half an hour after the close, so the day's last quotes have settled
+const eodSummaryAt = 16*time.Hour + 30*time.Minute
+
+// marketZone is the exchange's time zone, or Eastern Standard Time without
+// the zone database
+var marketZone = func() *time.Location {
+	if loc, err := time.LoadLocation("America/New_York"); err == nil {
+		return loc
+	}
+	return time.FixedZone("EST", -5*60*60)
+}()
+
+// daemonTask is a unit of work run on every daemon tick
+type daemonTask struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// Daemon runs background jobs without the TUI
+type Daemon struct {
+	db       db.Store
+	yahoo    yahoo.Provider
+	interval time.Duration
+	tasks    []daemonTask
+	bot      *telegramBot       // Optional, answers commands between ticks
+	webhook  *http.Server       // Serve mode only
+	notifier *notify.Dispatcher // Routes alerts and reports to notification channels
+
+	sentAlerts map[string]time.Time // When each alert key was notified
+
+	lastCSPScan time.Time // Day of the last scan appended to the CSP history
+	lastPolicy  time.Time // Day policies were last evaluated
+	lastPrices  time.Time // Day price history was last synced
+	lastBroker  string    // Discrepancy report from the previous broker sync
+	lastSummary time.Time // Exchange day the end-of-day summary was last sent
+}
+
+// newDaemon builds a daemon from environment configuration
+func newDaemon(database db.Store, client yahoo.Provider) *Daemon {
+	d := &Daemon{
+		db:         database,
+		yahoo:      client,
+		interval:   defaultDaemonInterval,
+		sentAlerts: make(map[string]time.Time),
+	}
+
+	if v := os.Getenv("DAEMON_INTERVAL"); v != "" {
+		if interval, err := time.ParseDuration(v); err == nil && interval > 0 {
+			d.interval = interval
+		} else {
+			log.Printf("invalid DAEMON_INTERVAL %q, using %s", v, defaultDaemonInterval)
+		}
+	}
+
+	// Every account's expired options, as the TUI does on refresh, so
+	// assignments move shares and cash even while no TUI is open
+	d.tasks = append(d.tasks, daemonTask{
+		name: "expired options",
+		run: func(ctx context.Context) error {
+			return forEachAccount(ctx, d.db, func(_ string, store db.Store) error {
+				return processExpiredOptions(ctx, store, d.yahoo)
+			})
+		},
+	})
+
+	if path := os.Getenv("ICS_FEED_PATH"); path != "" {
+		d.tasks = append(d.tasks, daemonTask{
+			name: "ics feed",
+			run: func(ctx context.Context) error {
+				return d.writeICSFeed(ctx, path)
+			},
+		})
+	}
+
+	if path := os.Getenv("CSP_HISTORY_PATH"); path != "" {
+		q := query.New(database, client)
+		d.tasks = append(d.tasks, daemonTask{
+			name: "csp history",
+			run: func(ctx context.Context) error {
+				return d.appendCSPHistory(ctx, q, path)
+			},
+		})
+	}
+
+	if token := os.Getenv("TELEGRAM_BOT_TOKEN"); token != "" {
+		chatID, err := strconv.ParseInt(os.Getenv("TELEGRAM_CHAT_ID"), 10, 64)
+		if err != nil {
+			log.Printf("TELEGRAM_BOT_TOKEN set but TELEGRAM_CHAT_ID is missing or invalid, bot disabled")
+		} else {
+			d.bot = newTelegramBot(token, chatID, query.New(database, client), loadLocale(context.Background(), database))
+		}
+	}
+
+	// The daemon's terminal is its log
+	channels := notifyChannels()
+	d.notifier = loadNotifier(context.Background(), database, append(channels, notify.NewFunc(notify.Terminal, func(ctx context.Context, e notify.Event) error {
+		log.Printf("daemon: %s: %s", e.Kind, e.Text)
+		return nil
+	}))...)
+	if len(channels) > 0 {
+		d.tasks = append(d.tasks, daemonTask{
+			name: "alerts",
+			run:  d.pushAlerts,
+		})
+		d.tasks = append(d.tasks, daemonTask{
+			name: "end-of-day summary",
+			run: func(ctx context.Context) error {
+				return d.sendEndOfDay(ctx, time.Now())
+			},
+		})
+	}
+
+	// Option policies are set in the TUI; without any the daily check is a no-op
+	d.tasks = append(d.tasks, daemonTask{
+		name: "option policies",
+		run:  d.queuePolicyActions,
+	})
+
+	// Without schema_history.sql the daily sync fails and history is fetched live
+	d.tasks = append(d.tasks, daemonTask{
+		name: "price history",
+		run:  d.syncPriceHistory,
+	})
+
+	if clientID := os.Getenv("PLAID_CLIENT_ID"); clientID != "" {
+		provider, err := broker.NewPlaid(os.Getenv("PLAID_ENV"), clientID, os.Getenv("PLAID_SECRET"), os.Getenv("PLAID_ACCESS_TOKEN"))
+		if err != nil {
+			log.Printf("broker sync disabled: %v", err)
+		} else {
+			d.tasks = append(d.tasks, daemonTask{
+				name: "broker sync",
+				run: func(ctx context.Context) error {
+					return d.syncBroker(ctx, provider)
+				},
+			})
+		}
+	}
+
+	return d
+}
+
+// run executes every task immediately and then on each tick until interrupted
+func (d *Daemon) run() {
+	if len(d.tasks) == 0 && d.webhook == nil {
+		log.Println("daemon: no tasks configured (see Daemon mode in README), exiting")
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if d.bot != nil {
+		go d.bot.poll(ctx)
+		log.Println("daemon: telegram bot listening")
+	}
+
+	if d.webhook != nil {
+		go func() {
+			log.Printf("daemon: accepting webhooks on %s", d.webhook.Addr)
+			if err := d.webhook.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("daemon: webhook server: %v", err)
+				stop()
+			}
+		}()
+		defer d.webhook.Shutdown(context.Background())
+	}
+
+	log.Printf("daemon: running %d task(s) every %s", len(d.tasks), d.interval)
+	d.tick(ctx)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.tick(ctx)
+		case <-ctx.Done():
+			log.Println("daemon: shutting down")
+			return
+		}
+	}
+}
+
+// tick runs each task in turn. A shutdown signal stops the tick between tasks
+// but does not cancel the running one, so its database writes complete.
+func (d *Daemon) tick(ctx context.Context) {
+	for _, task := range d.tasks {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := task.run(context.WithoutCancel(ctx)); err != nil {
+			log.Printf("daemon: %s: %v", task.name, err)
+		}
+	}
+}
+
+// writeICSFeed writes every account's option expiries and upcoming earnings
+// to an ICS file
+func (d *Daemon) writeICSFeed(ctx context.Context, path string) error {
+	var options []db.Option
+	var holdings []db.Holding
+	err := forEachAccount(ctx, d.db, func(_ string, store db.Store) error {
+		o, err := store.GetActiveOptions(ctx)
+		if err != nil {
+			return fmt.Errorf("loading options: %w", err)
+		}
+		h, err := store.GetHoldings(ctx)
+		if err != nil {
+			return fmt.Errorf("loading holdings: %w", err)
+		}
+		options, holdings = append(options, o...), append(holdings, h...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var events []ical.Event
+
+	tickers := make([]string, 0)
+	tickerMap := make(map[string]bool)
+	for _, h := range holdings {
+		if !tickerMap[h.Ticker] {
+			tickers = append(tickers, h.Ticker)
+			tickerMap[h.Ticker] = true
+		}
+	}
+
+	for _, o := range options {
+		if o.Status != "ACTIVE" {
+			continue
+		}
+		if !tickerMap[o.Ticker] {
+			tickers = append(tickers, o.Ticker)
+			tickerMap[o.Ticker] = true
+		}
+		events = append(events, ical.Event{
+			UID:     fmt.Sprintf("option-%s@anyhowhodl", o.ID),
+			Date:    o.ExpiryDate,
+			Summary: fmt.Sprintf("%s %s %s $%s expires", o.Ticker, o.Action, o.OptionType, o.Strike.StringFixed(2)),
+			Description: fmt.Sprintf("%d contract(s), premium $%s",
+				o.Quantity, o.Premium.StringFixed(2)),
+		})
+	}
+
+	for _, ticker := range tickers {
+		ed, err := d.yahoo.FetchEarningsDate(ticker)
+		if err != nil || ed.Date.IsZero() {
+			continue
+		}
+		if ed.Date.Before(now) || ed.Date.Sub(now) > earningsLookahead {
+			continue
+		}
+		summary := fmt.Sprintf("%s earnings", ticker)
+		if ed.Estimate {
+			summary += " (estimated)"
+		}
+		events = append(events, ical.Event{
+			UID:     fmt.Sprintf("earnings-%s@anyhowhodl", ticker),
+			Date:    ed.Date,
+			Summary: summary,
+		})
+	}
+
+	// Write to a temp file and rename so calendar clients never read a partial feed
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".anyhowhodl-*.ics")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := ical.Write(tmp, "anyhowhodl", events, now); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	log.Printf("daemon: wrote %d event(s) to %s", len(events), path)
+	return nil
+}
+
+// appendCSPHistory scans the CSP watchlist once per day and appends the results
+// to a CSV file, writing the header when the file is new
+func (d *Daemon) appendCSPHistory(ctx context.Context, q *query.Service, path string) error {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if !d.lastCSPScan.Before(today) {
+		return nil
+	}
+
+	results, err := q.ScanCSP(ctx)
+	if err != nil {
+		return fmt.Errorf("scanning: %w", err)
+	}
+	if err := q.RecordScores(ctx, results, today); err != nil {
+		log.Printf("daemon: recording watchlist scores: %v", err)
+	}
+
+	info, statErr := os.Stat(path)
+	header := statErr != nil || info.Size() == 0
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if err := export.WriteCSPCSV(f, results, header); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	d.lastCSPScan = today
+	log.Printf("daemon: appended %d CSP result(s) to %s", len(results), path)
+	return nil
+}
+
+// queuePolicyActions evaluates option policies once per day and queues the
+// closes and rolls they call for, announcing new ones on the notification
+// channels
+// syncPriceHistory stores the day's new bars once a day
+func (d *Daemon) syncPriceHistory(ctx context.Context) error {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if !d.lastPrices.Before(today) {
+		return nil
+	}
+	result, err := syncPriceHistory(ctx, d.db, d.yahoo, now)
+	d.lastPrices = today
+	if result.Bars > 0 {
+		log.Printf("daemon: stored %d price bar(s) for %d ticker(s)", result.Bars, result.Tickers)
+	}
+	return err
+}
+
+func (d *Daemon) queuePolicyActions(ctx context.Context) error {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if !d.lastPolicy.Before(today) {
+		return nil
+	}
+
+	var lines, names []string
+	accounts := 0
+	err := forEachAccount(ctx, d.db, func(name string, store db.Store) error {
+		accounts++
+		q := query.New(store, d.yahoo)
+		queued, err := q.QueuePolicyActions(ctx, now)
+		if err != nil {
+			return fmt.Errorf("evaluating policies: %w", err)
+		}
+		if len(queued) == 0 {
+			return nil
+		}
+		log.Printf("daemon: queued %d policy action(s) in %s", len(queued), name)
+
+		options, err := q.ActiveOptions(ctx)
+		if err != nil {
+			return fmt.Errorf("loading options: %w", err)
+		}
+		byID := make(map[string]db.Option, len(options))
+		for _, o := range options {
+			byID[o.ID] = o
+		}
+		for _, a := range queued {
+			lines = append(lines, query.PolicyActionText(a, byID[a.OptionID]))
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	d.lastPolicy = today
+	if len(lines) == 0 {
+		return nil
+	}
+	for i := range lines {
+		lines[i] = accountLabel(names[i], accounts) + lines[i]
+	}
+	text := "Queued for confirmation in the TUI (A):\n" + strings.Join(lines, "\n")
+	return d.notifier.Notify(ctx, notify.Event{Kind: notify.KindPolicy, Key: "policy:" + today.Format(time.DateOnly), Text: text})
+}
+
+// accountLabel prefixes a notification line with its account's name when
+// there are several accounts
+func accountLabel(name string, accounts int) string {
+	if accounts > 1 {
+		return name + ": "
+	}
+	return ""
+}
+
+// pushAlerts notifies every account's alerts not already notified
+func (d *Daemon) pushAlerts(ctx context.Context) error {
+	var alerts []query.Alert
+	var names []string
+	accounts := 0
+	err := forEachAccount(ctx, d.db, func(name string, store db.Store) error {
+		accounts++
+		found, err := query.New(store, d.yahoo).Alerts(ctx)
+		for _, al := range found {
+			alerts = append(alerts, al)
+			names = append(names, name)
+		}
+		return err
+	})
+	for i := range alerts {
+		alerts[i].Message = accountLabel(names[i], accounts) + alerts[i].Message
+	}
+	now := time.Now()
+	if err := query.New(d.db, d.yahoo).LogAlerts(ctx, alerts, now); err != nil {
+		log.Printf("daemon: logging alerts: %v", err)
+	}
+	d.pruneSentAlerts(now)
+	errs := []error{err}
+	for _, al := range alerts {
+		if _, sent := d.sentAlerts[al.Key]; sent {
+			continue
+		}
+		// Marked even if a channel fails, so the others are not sent it again
+		d.sentAlerts[al.Key] = now
+		if err := d.notifier.Notify(ctx, notify.Event{Kind: al.Kind, Key: al.Key, Text: al.Message}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pruneSentAlerts forgets the alert keys notified more than sentAlertsKept
+// before now, so a long-running daemon does not keep every key
+func (d *Daemon) pruneSentAlerts(now time.Time) {
+	for key, sent := range d.sentAlerts {
+		if now.Sub(sent) > sentAlertsKept {
+			delete(d.sentAlerts, key)
+		}
+	}
+}
+
+// sendEndOfDay sends the end-of-day summary once each weekday, on the first
+// tick after eodSummaryAt in exchange time, with a section per account
+func (d *Daemon) sendEndOfDay(ctx context.Context, now time.Time) error {
+	local := now.In(marketZone)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, marketZone)
+	if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday ||
+		local.Sub(day) < eodSummaryAt || !d.lastSummary.Before(day) {
+		return nil
+	}
+
+	var sections, names []string
+	err := forEachAccount(ctx, d.db, func(name string, store db.Store) error {
+		e, err := query.New(store, d.yahoo).EndOfDay(ctx, local)
+		if err != nil {
+			return fmt.Errorf("composing: %w", err)
+		}
+		sections, names = append(sections, e.Text()), append(names, name)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(sections) > 1 {
+		for i := range sections {
+			sections[i] = names[i] + "\n" + sections[i]
+		}
+	}
+	d.lastSummary = day
+	return d.notifier.Notify(ctx, notify.Event{Kind: notify.KindSummary, Key: "summary:" + day.Format(time.DateOnly), Text: strings.Join(sections, "\n\n")})
+}
+
+// syncBroker reconciles recorded holdings and cash against the broker. Holdings
+// are never modified; discrepancies are logged and, when the report changes,
+// sent to the notification channels.
+func (d *Daemon) syncBroker(ctx context.Context, provider broker.Provider) error {
+	snap, err := provider.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching from %s: %w", provider.Name(), err)
+	}
+	holdings, err := d.db.GetHoldings(ctx)
+	if err != nil {
+		return fmt.Errorf("loading holdings: %w", err)
+	}
+	cash, err := d.db.GetAvailableCash(ctx)
+	if err != nil {
+		return fmt.Errorf("loading cash: %w", err)
+	}
+
+	recorded := make([]broker.Recorded, len(holdings))
+	for i, h := range holdings {
+		recorded[i] = broker.Recorded{Ticker: h.Ticker, Quantity: h.Quantity, AvgCost: h.AvgCost}
+	}
+
+	discrepancies := broker.Reconcile(recorded, cash, snap)
+	lines := make([]string, len(discrepancies))
+	for i, disc := range discrepancies {
+		lines[i] = disc.Message
+	}
+	report := strings.Join(lines, "\n")
+
+	if len(discrepancies) == 0 {
+		log.Printf("daemon: %s sync: %d position(s) match", provider.Name(), len(snap.Positions))
+	} else {
+		log.Printf("daemon: %s sync: %d discrepancy(ies)\n%s", provider.Name(), len(discrepancies), report)
+	}
+
+	if report != d.lastBroker && report != "" {
+		e := notify.Event{Kind: notify.KindBroker, Key: "broker:" + report, Text: "Broker sync discrepancies:\n" + report}
+		if err := d.notifier.Notify(ctx, e); err != nil {
+			return err
+		}
+	}
+	d.lastBroker = report
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"time"
+
+	"anyhowhodl/internal/datespec"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// today is the current date as midnight UTC, the way dates are entered
+func (a *App) today() time.Time {
+	now := a.now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// parseDate reads a date field: a date in the locale's format or ISO, or a
+// shortcut such as "+30d", "fri" or "3rd friday"
+func (a *App) parseDate(text string) (time.Time, error) {
+	d, err := a.book.locale.ParseDate(text)
+	if err == nil {
+		return d, nil
+	}
+	if d, ok := datespec.Parse(text, a.today()); ok {
+		return d, nil
+	}
+	return time.Time{}, err
+}
+
+// dateField makes the input at index of form a date picker. Up and Down move
+// the date a day, PgUp and PgDn a week, starting from today if the field is
+// empty; a shortcut becomes the date it stands for on Enter or Tab.
+func (a *App) dateField(form *tview.Form, index int) {
+	input := form.GetFormItem(index).(*tview.InputField)
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		step := 0
+		switch event.Key() {
+		case tcell.KeyUp:
+			step = 1
+		case tcell.KeyDown:
+			step = -1
+		case tcell.KeyPgUp:
+			step = 7
+		case tcell.KeyPgDn:
+			step = -7
+		case tcell.KeyEnter, tcell.KeyTab, tcell.KeyBacktab:
+			if d, err := a.parseDate(input.GetText()); err == nil {
+				input.SetText(a.book.locale.FormatDate(d))
+			}
+			return event
+		default:
+			return event
+		}
+
+		d, err := a.parseDate(input.GetText())
+		if err != nil {
+			d = a.today()
+		}
+		input.SetText(a.book.locale.FormatDate(d.AddDate(0, 0, step)))
+		return nil
+	})
+}
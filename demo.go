@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"anyhowhodl/internal/datespec"
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/fake"
+
+	"github.com/shopspring/decimal"
+)
+
+// demoFlag starts the TUI on demoStore instead of the database
+const demoFlag = "--demo"
+
+// demoStore is an in-memory portfolio with a year of history behind it: a
+// wheel on a few large caps with assigned, expired, and bought-back options,
+// dividends, interest, a sale, weekly snapshots for the equity curve, both
+// watchlists, and tags. Dates are relative to now so expiries stay upcoming.
+// Quotes and chains still come from Yahoo.
+func demoStore(now time.Time) (*fake.Store, error) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day := func(offset int) time.Time { return today.AddDate(0, 0, offset) }
+
+	// Each record is created on the day it happened
+	var at time.Time
+	store.Now = func() time.Time { return at }
+	defer func() { store.Now = time.Now }()
+
+	var errs []error
+	try := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	dec := decimal.RequireFromString
+	none := decimal.NullDecimal{}
+	target := func(price string) decimal.NullDecimal { return decimal.NullDecimal{Decimal: dec(price), Valid: true} }
+
+	// sell opens a short option on the day it is created and returns its ID
+	sell := func(ticker, optionType, strike string, expiry time.Time, qty int, premium string) string {
+		try(store.AddOption(ctx, ticker, optionType, "SELL", dec(strike), expiry, qty, db.DefaultMultiplier,
+			db.SettlementPhysical, db.DefaultCurrency, dec(premium), dec("0.65").Mul(decimal.NewFromInt(int64(qty))), ""))
+		options, err := store.GetActiveOptions(ctx)
+		try(err)
+		for _, o := range options {
+			if o.Ticker == ticker && o.OptionType == optionType && o.Strike.Equal(dec(strike)) && o.ExpiryDate.Equal(expiry) {
+				return o.ID
+			}
+		}
+		return ""
+	}
+
+	at = day(-365)
+	try(store.AddContribution(ctx, dec("100000"), at, "Opening deposit"))
+	try(store.AddHolding(ctx, "AAPL", dec("100"), dec("172.50"), db.DefaultCurrency, at, target("260"), "Core position"))
+	try(store.AddHolding(ctx, "MSFT", dec("100"), dec("381.20"), db.DefaultCurrency, at, none, ""))
+
+	at = day(-330)
+	try(store.AddHolding(ctx, "KO", dec("200"), dec("60.10"), db.DefaultCurrency, at, none, "Dividend core"))
+
+	// A put that expired worthless, then one assigned into shares
+	at = day(-300)
+	id := sell("AMD", "PUT", "140", day(-272), 1, "3.10")
+	at = day(-272)
+	try(store.ExpireOption(ctx, id))
+	at = day(-240)
+	id = sell("NVDA", "PUT", "110", day(-212), 1, "2.80")
+	at = day(-212)
+	try(store.AssignOption(ctx, id, decimal.Zero))
+
+	// A call bought back early, and a pair that expired
+	at = day(-150)
+	id = sell("MSFT", "CALL", "420", day(-115), 1, "4.50")
+	at = day(-130)
+	try(store.CloseOption(ctx, id, dec("1.20"), dec("0.65")))
+	at = day(-60)
+	id = sell("KO", "CALL", "65", day(-32), 2, "0.55")
+	at = day(-32)
+	try(store.ExpireOption(ctx, id))
+
+	at = day(-90)
+	try(store.SellHolding(ctx, "MSFT", dec("40"), dec("430.00"), at, "Trimmed"))
+
+	// Quarterly dividends and monthly interest
+	for q := 3; q >= 1; q-- {
+		at = day(-q*91 + 10)
+		try(store.AddDividend(ctx, "KO", dec("97.00"), at, ""))
+		try(store.AddDividend(ctx, "AAPL", dec("25.00"), at.AddDate(0, 0, 3), ""))
+	}
+	for m := 11; m >= 1; m-- {
+		at = today.AddDate(0, -m, 0)
+		try(store.AddInterestPayment(ctx, dec("62.50"), at, "Sweep interest"))
+	}
+
+	// Open positions: covered calls on the shares and a cash-secured put
+	at = day(-10)
+	friday := datespec.NextFriday(today)
+	sell("AAPL", "CALL", "260", friday.AddDate(0, 0, 14), 1, "3.40")
+	sell("KO", "CALL", "75", friday.AddDate(0, 0, 28), 2, "0.62")
+	sell("NVDA", "CALL", "200", friday.AddDate(0, 0, 7), 1, "2.15")
+	id = sell("AMD", "PUT", "130", friday.AddDate(0, 0, 21), 1, "2.95")
+	try(store.AddReminder(ctx, id, friday.AddDate(0, 0, 14), "Roll or let it run?"))
+	try(store.AddExternalOption(ctx, "SPY", "PUT", "SELL", dec("520"), friday.AddDate(0, 0, 35), 1, db.DefaultMultiplier,
+		db.SettlementPhysical, db.DefaultCurrency, dec("4.80"), decimal.Zero, "IRA at the other broker"))
+
+	// Weekly snapshots for the performance and equity views
+	cash, err := store.GetAvailableCash(ctx)
+	try(err)
+	for w := 52; w >= 1; w-- {
+		at = day(-w * 7)
+		t := float64(52-w) / 52
+		value := 78000 * (1 + 0.14*t + 0.03*math.Sin(t*11))
+		try(store.RecordPortfolioSnapshot(ctx, decimal.NewFromFloat(value).Round(2), cash, dec("450")))
+		try(store.RecordCashSnapshot(ctx, cash))
+	}
+
+	// Sectors, a cap, and the watchlists
+	at = today
+	for ticker, sector := range map[string]string{"AAPL": "Technology", "MSFT": "Technology", "NVDA": "Technology",
+		"AMD": "Technology", "KO": "Consumer Defensive", "SPY": "ETF"} {
+		try(store.SetTickerSector(ctx, ticker, sector))
+	}
+	try(store.SetRiskCap(ctx, db.CapScopeSector, "Technology", dec("70")))
+	try(store.SetWatchTicker(ctx, "GOOGL", target("150"), "Buy on a pullback"))
+	try(store.SetWatchTicker(ctx, "COST", target("850"), ""))
+	try(store.SetWatchTicker(ctx, "V", none, "Payments"))
+	for _, ticker := range []string{"AMD", "SPY", "PLTR", "INTC"} {
+		try(store.AddCSPWatchTicker(ctx, ticker, ""))
+	}
+	try(store.AddTradeIdea(ctx, "PLTR", "PUT", dec("25"), friday.AddDate(0, 0, 14), 1, dec("0.85"), "Waiting on a fill"))
+
+	// Tags: the wheel's positions, and the long-term core
+	holdings, err := store.GetHoldings(ctx)
+	try(err)
+	for _, h := range holdings {
+		tags := []string{"wheel"}
+		if h.Ticker == "KO" || h.Ticker == "MSFT" {
+			tags = []string{"long-term"}
+		}
+		try(store.SetTags(ctx, h.ID, tags))
+	}
+	options, err := store.GetActiveOptions(ctx)
+	try(err)
+	for _, o := range options {
+		if o.Status == "ACTIVE" && !o.External {
+			try(store.SetTags(ctx, o.ID, []string{"wheel"}))
+		}
+	}
+
+	return store, errors.Join(errs...)
+}
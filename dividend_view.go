@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/db"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// dividendForecastMonths is how far ahead the dividend calendar projects
+const dividendForecastMonths = 12
+
+// showDividendView opens the dividend forecast page and loads data in the background
+func (a *App) showDividendView() {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	view.SetBorder(true).
+		SetTitle(" Dividend Forecast (next 12 months) ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+	view.SetText(" [yellow]Loading dividend schedules...")
+
+	a.pages.AddPage("dividends", view, true, true)
+
+	a.goSafe("dividend report", func() {
+		text := a.buildDividendReport()
+		a.queueUpdateDraw(func() {
+			view.SetText(text)
+		})
+	})
+}
+
+// buildDividendReport projects each holding's trailing dividend schedule forward
+func (a *App) buildDividendReport() string {
+	if len(a.book.holdings) == 0 {
+		return " [gray]No holdings"
+	}
+
+	now := time.Now()
+	var projected []analytics.ProjectedDividend
+	var failed []string
+	for _, h := range a.book.holdings {
+		history, err := a.yahoo.FetchDividends(h.Ticker)
+		if err != nil {
+			failed = append(failed, h.Ticker)
+			continue
+		}
+		projected = append(projected, analytics.ProjectDividends(h.Ticker, history, h.Quantity.InexactFloat64(), now, dividendForecastMonths)...)
+	}
+	sort.Slice(projected, func(i, j int) bool { return projected[i].ExDate.Before(projected[j].ExDate) })
+
+	totals := analytics.MonthlyDividendTotals(projected, now, dividendForecastMonths)
+
+	// Per-month ticker breakdown
+	byMonth := make(map[string][]string)
+	for _, p := range projected {
+		key := p.ExDate.Format("2006-01")
+		byMonth[key] = append(byMonth[key], fmt.Sprintf("%s $%s", p.Ticker, a.book.locale.FormatFloat(p.CashTotal, 2)))
+	}
+
+	var sb strings.Builder
+	annual := 0.0
+	for _, m := range totals {
+		annual += m.Total
+	}
+
+	fmt.Fprintf(&sb, " [teal]Projected 12-month income:[white] [lime]$%s[white]  ([gray]avg $%s/month[white])\n\n",
+		a.book.locale.FormatFloat(annual, 2),
+		a.book.locale.FormatFloat(annual/float64(dividendForecastMonths), 2))
+
+	fmt.Fprintf(&sb, " [teal]%-10s %12s  %s[white]\n", "MONTH", "TOTAL", "PAYMENTS")
+	for _, m := range totals {
+		color := "white"
+		if m.Total == 0 {
+			color = "gray"
+		}
+		fmt.Fprintf(&sb, " [aqua]%-10s[white] [%s]%12s[white]  %s\n",
+			m.Month.Format("Jan 2006"),
+			color,
+			"$"+a.book.locale.FormatFloat(m.Total, 2),
+			strings.Join(byMonth[m.Month.Format("2006-01")], ", "))
+	}
+
+	if len(failed) > 0 {
+		fmt.Fprintf(&sb, "\n [gray]No dividend data: %s[white]\n", strings.Join(failed, ", "))
+	}
+
+	sb.WriteString("\n [gray]Projected from trailing 12-month ex-dates at current share counts. ESC to close.")
+	return sb.String()
+}
+
+// loadDividends reads the dividends received this tax year. Without the
+// dividends table there are none.
+func (a *App) loadDividends(ctx context.Context) {
+	yearStart, _ := a.book.taxYear.Bounds(a.now())
+	payments, err := a.db.GetDividendsSince(ctx, yearStart)
+	if err != nil {
+		slog.Debug("loading dividends", "err", err)
+	}
+	a.dividends = payments
+}
+
+// projectedDividends is a holding's dividend income over the next year: its
+// trailing 12 months of distributions repeated on the shares held now, in the
+// base currency. ok is false until the ticker's dividend history has been fetched.
+func (a *App) projectedDividends(h db.Holding) (decimal.Decimal, bool) {
+	history, ok := a.dividendHistory[h.Ticker]
+	if !ok {
+		return decimal.Zero, false
+	}
+	total := 0.0
+	for _, p := range analytics.ProjectDividends(h.Ticker, history, h.Quantity.InexactFloat64(), a.now(), dividendForecastMonths) {
+		total += p.CashTotal
+	}
+	return a.book.toBase(decimal.NewFromFloat(total), h.Currency), true
+}
+
+// dividendCell shows a holding's projected annual dividends, "..." while its
+// history loads
+func (a *App) dividendCell(h db.Holding, bg tcell.Color) *tview.TableCell {
+	annual, ok := a.projectedDividends(h)
+	text, color := " "+a.book.baseSymbol()+a.book.locale.FormatFixed(annual, 2)+" ", tcell.ColorLime
+	if !ok {
+		text, color = " ... ", tcell.ColorGray
+	} else if annual.IsZero() {
+		text, color = " - ", tcell.ColorWhite
+	}
+	return tview.NewTableCell(text).
+		SetTextColor(color).
+		SetBackgroundColor(bg).
+		SetAlign(tview.AlignLeft).
+		SetExpansion(1)
+}
+
+// dividendsLine is the summary line with the dividend income projected over
+// the next year, its yield on holdingsValue, and the dividends received this
+// tax year
+func (a *App) dividendsLine(holdingsValue decimal.Decimal) string {
+	projected := decimal.Zero
+	complete := true
+	for _, h := range a.book.holdings {
+		annual, ok := a.projectedDividends(h)
+		if !ok {
+			complete = false
+		}
+		projected = projected.Add(annual)
+	}
+
+	projectedText := "[gray]loading[white]"
+	if complete {
+		projectedText = "[lime]" + a.book.baseSymbol() + a.book.locale.FormatFixed(projected, 2) + "[white]"
+		if holdingsValue.IsPositive() {
+			yield := projected.Div(holdingsValue).Mul(decimal.NewFromInt(100))
+			projectedText += fmt.Sprintf(" (%s%% yield)", a.book.locale.FormatFixed(yield, 2))
+		}
+	}
+
+	received := decimal.Zero
+	for _, p := range a.dividends {
+		received = received.Add(p.Amount)
+	}
+	yearStart, _ := a.book.taxYear.Bounds(a.now())
+	return fmt.Sprintf(" [teal]Dividends:[white] Projected annual: %s  |  Received %s: [lime]%s%s[white]  [gray](D to record)[white]",
+		projectedText, a.book.taxYear.Label(yearStart), a.book.baseSymbol(), a.book.locale.FormatFixed(received, 2))
+}
+
+// showDividendForm records a dividend paid on the holding at index and
+// credits it to cash. The amount starts at the last distribution on the
+// shares held now, converted into the base currency cash is kept in.
+func (a *App) showDividendForm(index int) {
+	h := a.book.holdings[index]
+	amount := ""
+	if history := a.dividendHistory[h.Ticker]; len(history) > 0 {
+		last := history[0]
+		for _, d := range history {
+			if d.ExDate.After(last.ExDate) {
+				last = d
+			}
+		}
+		amount = a.book.locale.EditNumber(a.book.toBase(decimal.NewFromFloat(last.Amount).Mul(h.Quantity), h.Currency).StringFixed(2))
+	}
+
+	form := tview.NewForm().
+		AddInputField("Amount ("+strings.TrimSpace(a.book.baseSymbol())+")", amount, 15, nil, nil).
+		AddInputField("Paid ("+a.book.locale.DateHint+")", a.book.locale.FormatDate(a.now()), 15, nil, nil).
+		AddInputField("Notes", "", 30, nil, nil)
+	checks := newFormChecks(form)
+	checks.add(form, 0, requiredCheck(a.numberCheck(false)), nil)
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		if !checks.valid() {
+			a.statusBar.SetText(" [red]Fix the fields marked in red")
+			return
+		}
+		amount, err := a.book.locale.ParseNumber(form.GetFormItem(0).(*tview.InputField).GetText())
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid dividend amount")
+			return
+		}
+		paidOn, err := a.book.locale.ParseDate(form.GetFormItem(1).(*tview.InputField).GetText())
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid date format")
+			return
+		}
+		notes := form.GetFormItem(2).(*tview.InputField).GetText()
+
+		if err := a.db.AddDividend(context.Background(), h.Ticker, amount, paidOn, notes); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error (run schema_dividends.sql): %v", err))
+			return
+		}
+		a.pages.RemovePage("adddividend")
+		a.refreshData()
+		a.statusBar.SetText(fmt.Sprintf(" [green]Recorded $%s dividend on %s", a.book.locale.FormatFixed(amount, 2), h.Ticker))
+	})
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("adddividend")
+	})
+	form.SetBorder(true).SetTitle(" Record Dividend: " + h.Ticker + " ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("adddividend", form, 50, 14)
+}
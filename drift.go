@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"anyhowhodl/internal/db"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// loadTargetWeights reads the holdings' target weights and drift tolerance
+func (a *App) loadTargetWeights(ctx context.Context) {
+	targets, err := a.db.GetTargetWeights(ctx)
+	if err != nil {
+		slog.Warn("loading target weights", "err", err)
+		targets = db.TargetWeights{Weights: map[string]decimal.Decimal{}, Tolerance: db.DefaultDriftTolerance}
+	}
+	a.targetWeights = targets
+}
+
+// Weights of the holdings above which a position or sector is colored as
+// concentrated (orange) or over-concentrated (red)
+var (
+	concentratedPct     = decimal.NewFromInt(25)
+	overConcentratedPct = decimal.NewFromInt(40)
+)
+
+// weightCell shows a holding's weight. With a target weight it also shows
+// the drift from it, ▲ over or ▼ under, colored once beyond the tolerance;
+// without one, large weights are colored as concentrated.
+func (a *App) weightCell(ticker string, weight decimal.Decimal, bg tcell.Color) *tview.TableCell {
+	text := a.book.locale.FormatFixed(weight, 1) + "%"
+	color := tcell.ColorWhite
+	if target, ok := a.targetWeights.Weights[ticker]; ok {
+		drift := weight.Sub(target)
+		arrow := "▲"
+		if drift.IsNegative() {
+			arrow = "▼"
+		}
+		text += " " + arrow + a.book.locale.FormatFixed(drift.Abs(), 1)
+		if drift.Abs().GreaterThan(a.targetWeights.Tolerance) {
+			color = tcell.ColorOrange
+			if drift.IsNegative() {
+				color = tcell.ColorAqua
+			}
+		}
+	} else if weight.GreaterThan(overConcentratedPct) {
+		color = tcell.ColorRed
+	} else if weight.GreaterThan(concentratedPct) {
+		color = tcell.ColorOrange
+	}
+	return tview.NewTableCell(" " + text + " ").
+		SetTextColor(color).
+		SetBackgroundColor(bg).
+		SetAlign(tview.AlignLeft).
+		SetExpansion(1)
+}
+
+// weightCheck accepts a target weight from 0 to 100 percent
+func (a *App) weightCheck(text string) string {
+	w, err := a.book.locale.ParseNumber(text)
+	if err != nil {
+		return "not a number"
+	}
+	if w.IsNegative() || w.GreaterThan(decimal.NewFromInt(100)) {
+		return "must be between 0 and 100"
+	}
+	return ""
+}
+
+// editTargetWeight is the target weight field's text for ticker
+func (a *App) editTargetWeight(ticker string) string {
+	if w, ok := a.targetWeights.Weights[ticker]; ok {
+		return a.book.locale.EditNumber(w.String())
+	}
+	return ""
+}
+
+// saveTargetWeight sets ticker's target weight from a form field, clearing
+// it if the field is empty
+func (a *App) saveTargetWeight(ctx context.Context, ticker, text string) error {
+	targets, err := a.db.GetTargetWeights(ctx)
+	if err != nil {
+		return err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		if _, ok := targets.Weights[ticker]; !ok {
+			return nil
+		}
+		delete(targets.Weights, ticker)
+		return a.db.SetTargetWeights(ctx, targets)
+	}
+	w, err := a.book.locale.ParseNumber(text)
+	if err != nil {
+		return err
+	}
+	targets.Weights[ticker] = w
+	return a.db.SetTargetWeights(ctx, targets)
+}
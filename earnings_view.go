@@ -0,0 +1,317 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sort"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/db"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// earningsCalendarDays is how far ahead the earnings calendar looks
+const earningsCalendarDays = 28
+
+// earningsMaxAge is how long a fetched earnings date is shown in the tables
+// before it is fetched again
+const earningsMaxAge = 12 * time.Hour
+
+// earningsSoonDays is how near an earnings date shows yellow in the holdings
+// table
+const earningsSoonDays = 7
+
+// earningsDate is a ticker's next earnings date as fetched for the tables.
+// date is zero when none is announced or estimated.
+type earningsDate struct {
+	date     time.Time
+	estimate bool
+	fetched  time.Time
+}
+
+// loadEarningsDates fetches, in the background, the next earnings dates of
+// held tickers and active options' underlyings not fetched in the last
+// earningsMaxAge, and redraws the tables once they arrive
+func (a *App) loadEarningsDates() {
+	now := a.now()
+	var missing []string
+	seen := make(map[string]bool)
+	add := func(ticker string) {
+		if seen[ticker] {
+			return
+		}
+		seen[ticker] = true
+		if e, ok := a.earnings[ticker]; !ok || now.Sub(e.fetched) > earningsMaxAge {
+			missing = append(missing, ticker)
+		}
+	}
+	for _, h := range a.book.holdings {
+		add(h.Ticker)
+	}
+	for _, o := range a.book.options {
+		if o.Status == "ACTIVE" {
+			add(o.Ticker)
+		}
+	}
+	if len(missing) == 0 || a.earningsBusy {
+		return
+	}
+	a.earningsBusy = true
+	a.goSafe("earnings dates", func() {
+		dates := a.fetchEarningsDates(missing, now)
+		a.queueUpdateDraw(func() {
+			a.earningsBusy = false
+			if a.earnings == nil {
+				a.earnings = make(map[string]earningsDate)
+			}
+			maps.Copy(a.earnings, dates)
+			a.holdingsView.update()
+			a.optionsView.update()
+		})
+	})
+}
+
+// fetchEarningsDates fetches the next earnings date of each ticker. Tickers
+// that fail are left out, to be tried again on the next refresh.
+func (a *App) fetchEarningsDates(tickers []string, now time.Time) map[string]earningsDate {
+	dates := make(map[string]earningsDate, len(tickers))
+	for _, ticker := range tickers {
+		ed, err := a.yahoo.FetchEarningsDate(ticker)
+		if err != nil {
+			slog.Debug("fetching earnings date for tables", "ticker", ticker, "err", err)
+			continue
+		}
+		dates[ticker] = earningsDate{date: ed.Date, estimate: ed.Estimate, fetched: now}
+	}
+	return dates
+}
+
+// nextEarnings is ticker's next earnings date from today on, as a UTC day,
+// and whether it is an estimate. ok is false if none is known.
+func (a *App) nextEarnings(ticker string) (day time.Time, estimate, ok bool) {
+	e, found := a.earnings[ticker]
+	if !found || e.date.IsZero() {
+		return time.Time{}, false, false
+	}
+	now := a.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	day = time.Date(e.date.Year(), e.date.Month(), e.date.Day(), 0, 0, 0, 0, time.UTC)
+	if day.Before(today) {
+		return time.Time{}, false, false
+	}
+	return day, e.estimate, true
+}
+
+// earningsText labels an earnings day with the days until it, "Mar 12 (9d)",
+// starred when estimated
+func (a *App) earningsText(day time.Time, estimate bool) string {
+	now := a.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	text := fmt.Sprintf(" %s (%dd)", a.book.locale.FormatMonthDay(day), int(day.Sub(today).Hours()/24))
+	if estimate {
+		text += "*"
+	}
+	return text + " "
+}
+
+// holdingEarningsCell shows a holding's next earnings date, yellow within
+// earningsSoonDays
+func (a *App) holdingEarningsCell(ticker string, bg tcell.Color) *tview.TableCell {
+	text, color := " - ", tcell.ColorWhite
+	if day, estimate, ok := a.nextEarnings(ticker); ok {
+		text = a.earningsText(day, estimate)
+		if day.Sub(a.now()) < earningsSoonDays*24*time.Hour {
+			color = tcell.ColorYellow
+		}
+	}
+	return tview.NewTableCell(text).
+		SetTextColor(color).
+		SetBackgroundColor(bg).
+		SetAlign(tview.AlignLeft).
+		SetExpansion(1)
+}
+
+// optionEarningsCell shows the next earnings date of an option's underlying,
+// red with a ! when the option is an open short that expires on or after it
+func (a *App) optionEarningsCell(o db.Option, bg tcell.Color) *tview.TableCell {
+	text, color := " - ", tcell.ColorWhite
+	if day, estimate, ok := a.nextEarnings(o.Ticker); ok && o.Status == "ACTIVE" {
+		text = a.earningsText(day, estimate)
+		if spansEarnings(o, day) {
+			text, color = strings.TrimSuffix(text, " ")+" ! ", tcell.ColorRed
+		}
+	}
+	if o.Status != "ACTIVE" {
+		color = tcell.ColorDimGray
+	}
+	return tview.NewTableCell(text).
+		SetTextColor(color).
+		SetBackgroundColor(bg).
+		SetAlign(tview.AlignLeft).
+		SetExpansion(1)
+}
+
+// spansEarnings reports whether o is an open short that expires on or after
+// the earnings day, so it is exposed to the earnings move
+func spansEarnings(o db.Option, day time.Time) bool {
+	return o.Status == "ACTIVE" && o.Action == "SELL" && !o.External && !o.ExpiryDate.Before(day)
+}
+
+// earningsTicker is a ticker on the earnings calendar and why it is there
+type earningsTicker struct {
+	Ticker  string
+	Held    bool
+	Watched bool
+}
+
+// upcomingEarnings is a ticker's next earnings date within the calendar window
+type upcomingEarnings struct {
+	earningsTicker
+	Date     time.Time
+	Estimate bool
+}
+
+// showEarningsView opens the earnings calendar and fetches dates in the background
+func (a *App) showEarningsView() {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	view.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Earnings Calendar (next %d weeks) ", earningsCalendarDays/7)).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+	view.SetText(" [yellow]Loading earnings dates...")
+
+	a.pages.AddPage("earnings", view, true, true)
+
+	tickers := a.earningsTickers(context.Background())
+	shorts := a.openShorts()
+	now := a.now()
+	a.goSafe("earnings calendar", func() {
+		text := a.earningsReport(a.fetchUpcomingEarnings(tickers, now), shorts, now)
+		a.queueUpdateDraw(func() {
+			view.SetText(text)
+		})
+	})
+}
+
+// earningsTickers lists the held tickers, the underlyings of active options,
+// and the CSP watchlist, each once
+func (a *App) earningsTickers(ctx context.Context) []earningsTicker {
+	var tickers []earningsTicker
+	index := make(map[string]int)
+	add := func(ticker string, held bool) {
+		i, ok := index[ticker]
+		if !ok {
+			i = len(tickers)
+			index[ticker] = i
+			tickers = append(tickers, earningsTicker{Ticker: ticker})
+		}
+		if held {
+			tickers[i].Held = true
+		} else {
+			tickers[i].Watched = true
+		}
+	}
+	for _, h := range a.book.holdings {
+		add(h.Ticker, true)
+	}
+	for _, o := range a.book.options {
+		if o.Status == "ACTIVE" {
+			add(o.Ticker, true)
+		}
+	}
+	watchlist, err := a.db.GetCSPWatchlist(ctx)
+	if err != nil {
+		slog.Warn("loading CSP watchlist for earnings", "err", err)
+	}
+	for _, w := range watchlist {
+		add(w.Ticker, false)
+	}
+	return tickers
+}
+
+// openShorts groups the active short options of this account by ticker
+func (a *App) openShorts() map[string][]db.Option {
+	shorts := make(map[string][]db.Option)
+	for _, o := range a.book.options {
+		if o.Status == "ACTIVE" && o.Action == "SELL" && !o.External {
+			shorts[o.Ticker] = append(shorts[o.Ticker], o)
+		}
+	}
+	return shorts
+}
+
+// fetchUpcomingEarnings looks up each ticker's next earnings date, keeping the
+// ones from today to the end of the calendar window, soonest first
+func (a *App) fetchUpcomingEarnings(tickers []earningsTicker, now time.Time) []upcomingEarnings {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	end := today.AddDate(0, 0, earningsCalendarDays)
+	var upcoming []upcomingEarnings
+	for _, t := range tickers {
+		ed, err := a.yahoo.FetchEarningsDate(t.Ticker)
+		if err != nil {
+			slog.Warn("fetching earnings date", "ticker", t.Ticker, "err", err)
+			continue
+		}
+		if ed.Date.IsZero() || ed.Date.Before(today) || ed.Date.After(end) {
+			continue
+		}
+		upcoming = append(upcoming, upcomingEarnings{earningsTicker: t, Date: ed.Date, Estimate: ed.Estimate})
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool { return upcoming[i].Date.Before(upcoming[j].Date) })
+	return upcoming
+}
+
+// earningsReport lists the upcoming earnings with the days until each, and
+// marks the names with open short options. A short that expires on or after
+// the report is flagged, as it is exposed to the earnings move.
+func (a *App) earningsReport(upcoming []upcomingEarnings, shorts map[string][]db.Option, now time.Time) string {
+	if len(upcoming) == 0 {
+		return fmt.Sprintf(" [gray]No earnings in the next %d days for held or watched tickers", earningsCalendarDays)
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, " [teal]%-12s %6s  %-8s %-8s  %s[white]\n", "DATE", "DAYS", "TICKER", "SOURCE", "SHORT OPTIONS")
+	for _, e := range upcoming {
+		date := time.Date(e.Date.Year(), e.Date.Month(), e.Date.Day(), 0, 0, 0, 0, time.UTC)
+		days := int(date.Sub(today).Hours() / 24)
+		when := fmt.Sprintf("in %dd", days)
+		if days == 0 {
+			when = "today"
+		}
+		var source []string
+		if e.Held {
+			source = append(source, "held")
+		}
+		if e.Watched {
+			source = append(source, "watched")
+		}
+		label := date.Format("Mon Jan 02")
+		if e.Estimate {
+			label += "*"
+		}
+
+		var marks []string
+		for _, o := range shorts[e.Ticker] {
+			mark := fmt.Sprintf("%s $%s %s", o.OptionType, o.Strike.StringFixed(2), o.ExpiryDate.Format("01/02"))
+			if spansEarnings(o, date) {
+				mark = "[red]" + mark + " ![white]"
+			}
+			marks = append(marks, mark)
+		}
+		shortText := "[gray]-[white]"
+		if len(marks) > 0 {
+			shortText = strings.Join(marks, ", ")
+		}
+		fmt.Fprintf(&sb, " %-12s %6s  %-8s %-8s  %s\n", label, when, e.Ticker, strings.Join(source, "+"), shortText)
+	}
+	sb.WriteString("\n [gray]* estimated date   [red]![gray] short option open through earnings")
+	return sb.String()
+}
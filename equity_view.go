@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/db"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// Equity curve size in characters, excluding the axis labels
+const (
+	equityChartWidth  = 60
+	equityChartHeight = 15
+)
+
+// equityRange is a span of the equity curve, picked with its key
+type equityRange struct {
+	Label  string
+	Key    rune
+	Months int
+}
+
+var equityRanges = []equityRange{
+	{Label: "1M", Key: '1', Months: 1},
+	{Label: "3M", Key: '3', Months: 3},
+	{Label: "1Y", Key: 'y', Months: 12},
+}
+
+// showEquityCurve opens the equity curve over 3 months; 1, 3, and y switch
+// between the ranges
+func (a *App) showEquityCurve() {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	view.SetBorder(true).
+		SetTitle(" Equity Curve ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	load := func(r equityRange) {
+		view.SetText(" [yellow]Loading snapshots...")
+		a.goSafe("equity curve", func() {
+			end := a.now()
+			start := end.AddDate(0, -r.Months, 0)
+			var text string
+			snapshots, err := a.db.GetPortfolioSnapshots(context.Background(), start)
+			if err != nil {
+				text = fmt.Sprintf(" [red]Error loading snapshots: %v", err)
+			} else {
+				text = a.equityReport(r, snapshots, start, end)
+			}
+			a.queueUpdateDraw(func() {
+				view.SetText(text)
+			})
+		})
+	}
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		for _, r := range equityRanges {
+			if event.Rune() == r.Key {
+				load(r)
+				return nil
+			}
+		}
+		return event
+	})
+
+	a.pages.AddPage("equity", view, true, true)
+	load(equityRanges[1])
+}
+
+// totalOptionsCredit is the net premium taken in on the selected account's
+// active options, sold less bought, as recorded in snapshots. Watch-only
+// options never moved cash here, so they are left out.
+func (a *App) totalOptionsCredit() decimal.Decimal {
+	credit := decimal.Zero
+	for _, o := range a.book.options {
+		if o.Status != "ACTIVE" || o.External {
+			continue
+		}
+		premium := a.book.toBase(o.Premium.Mul(o.Shares()), o.Currency)
+		if o.Action == "BUY" {
+			premium = premium.Neg()
+		}
+		credit = credit.Add(premium)
+	}
+	return credit
+}
+
+// equityReport renders the range picker, the curve, and the change over the
+// range from the first snapshot in it to the last
+func (a *App) equityReport(r equityRange, snapshots []db.PortfolioSnapshot, start, end time.Time) string {
+	var sb strings.Builder
+	sb.WriteString(" ")
+	for _, er := range equityRanges {
+		if er == r {
+			fmt.Fprintf(&sb, "[black:teal] %s [-:-] ", er.Label)
+		} else {
+			fmt.Fprintf(&sb, "[gray]%c[white]:%s ", er.Key, er.Label)
+		}
+	}
+	sb.WriteString("\n\n")
+
+	if len(snapshots) == 0 {
+		sb.WriteString(" [gray]No snapshots in this range yet; one is recorded on each refresh")
+		return sb.String()
+	}
+	sb.WriteString(a.renderEquityChart(snapshots, start, end))
+
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+	change := last.Total().Sub(first.Total())
+	color, sign := "lime", "+"
+	if change.IsNegative() {
+		color, sign = "red", "-"
+	}
+	pct := ""
+	if first.Total().IsPositive() {
+		pct = fmt.Sprintf(" (%s%s%%)", sign, a.book.locale.FormatFixed(change.Abs().Div(first.Total()).Mul(decimal.NewFromInt(100)), 2))
+	}
+	money := func(d decimal.Decimal) string {
+		return a.book.baseSymbol() + a.book.locale.FormatFixed(d, 2)
+	}
+	fmt.Fprintf(&sb, "\n [teal]%s:[white] %s  [teal]%s:[white] %s  [teal]Change:[%s] %s%s%s[white]\n",
+		first.Date.Format("Jan 2"), money(first.Total()),
+		last.Date.Format("Jan 2"), money(last.Total()),
+		color, sign, money(change.Abs()), pct)
+	fmt.Fprintf(&sb, " [teal]Holdings:[white] %s  [teal]Cash:[white] %s  [teal]Options credit:[white] %s\n",
+		money(last.HoldingsValue), money(last.Cash), money(last.OptionsCredit))
+	fmt.Fprintf(&sb, "\n [gray]%d snapshot(s); days without a refresh carry the last value forward", len(snapshots))
+	return sb.String()
+}
+
+// renderEquityChart plots the portfolio total across [start, end], one
+// column per step of time. Each column shows the latest snapshot on or
+// before it; columns before the first snapshot are left blank.
+func (a *App) renderEquityChart(snapshots []db.PortfolioSnapshot, start, end time.Time) string {
+	values := make([]float64, equityChartWidth)
+	present := make([]bool, equityChartWidth)
+	top, bottom := math.Inf(-1), math.Inf(1)
+	span := end.Sub(start)
+	next := 0
+	for c := range values {
+		at := start.Add(time.Duration(float64(span) * float64(c) / float64(equityChartWidth-1)))
+		for next < len(snapshots) && !snapshots[next].Date.After(at) {
+			next++
+		}
+		if next == 0 {
+			continue
+		}
+		values[c] = snapshots[next-1].Total().InexactFloat64()
+		present[c] = true
+		top = math.Max(top, values[c])
+		bottom = math.Min(bottom, values[c])
+	}
+	if math.IsInf(top, 0) {
+		// Every snapshot is after the last column; plot the latest at the end
+		values[equityChartWidth-1] = snapshots[len(snapshots)-1].Total().InexactFloat64()
+		present[equityChartWidth-1] = true
+		top, bottom = values[equityChartWidth-1], values[equityChartWidth-1]
+	}
+	if top == bottom {
+		top, bottom = top+1, bottom-1
+	}
+	row := func(v float64) int {
+		return int(math.Round((top - v) / (top - bottom) * float64(equityChartHeight-1)))
+	}
+	label := func(v float64) string {
+		return a.book.baseSymbol() + a.book.locale.FormatFloat(v, 0)
+	}
+	labels := map[int]string{
+		0:                     label(top),
+		equityChartHeight - 1: label(bottom),
+	}
+
+	// Green where the curve is above where the range started, red below
+	base := 0.0
+	for c := range values {
+		if present[c] {
+			base = values[c]
+			break
+		}
+	}
+
+	var sb strings.Builder
+	for r := 0; r < equityChartHeight; r++ {
+		fmt.Fprintf(&sb, " %12s [gray]|", labels[r])
+		for c, v := range values {
+			switch {
+			case !present[c] || row(v) != r:
+				sb.WriteString(" ")
+			case v > base:
+				sb.WriteString("[lime]*")
+			case v < base:
+				sb.WriteString("[red]*")
+			default:
+				sb.WriteString("[white]*")
+			}
+		}
+		sb.WriteString("[white]\n")
+	}
+
+	left := start.Format("Jan 2")
+	right := end.Format("Jan 2")
+	gap := max(equityChartWidth-len(left)-len(right), 1)
+	fmt.Fprintf(&sb, " %12s  [gray]%s%s%s[white]\n", "", left, strings.Repeat(" ", gap), right)
+	return sb.String()
+}
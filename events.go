@@ -0,0 +1,29 @@
+package main
+
+// event is something views may need to re-render for
+type event int
+
+const (
+	eventDataLoaded      event = iota // Holdings, options, cash, and quotes reloaded
+	eventHoldingsChanged              // The holdings table was redrawn, with new totals
+)
+
+// eventBus runs the handlers subscribed to an event, in the order they
+// subscribed. Handlers run on the caller's goroutine, the event loop in the
+// TUI; background tasks publish from queueUpdateDraw.
+type eventBus struct {
+	handlers map[event][]func()
+}
+
+func (b *eventBus) subscribe(e event, handler func()) {
+	if b.handlers == nil {
+		b.handlers = make(map[event][]func())
+	}
+	b.handlers[e] = append(b.handlers[e], handler)
+}
+
+func (b *eventBus) publish(e event) {
+	for _, handler := range b.handlers[e] {
+		handler()
+	}
+}
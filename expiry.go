@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/yahoo"
+
+	"github.com/shopspring/decimal"
+)
+
+// processExpiredOptions settles store's options that are past expiry but
+// still ACTIVE at the underlying's current price: in-the-money ones are
+// assigned, or settled in cash, and the rest expire. Options without a quote
+// wait for the next pass.
+func processExpiredOptions(ctx context.Context, store db.Store, market yahoo.Provider) error {
+	expiredOptions, err := store.GetExpiredActiveOptions(ctx)
+	if err != nil || len(expiredOptions) == 0 {
+		return err
+	}
+
+	// Get unique tickers
+	tickers := make([]string, 0)
+	tickerMap := make(map[string]bool)
+	for _, o := range expiredOptions {
+		if !tickerMap[o.Ticker] {
+			tickers = append(tickers, o.Ticker)
+			tickerMap[o.Ticker] = true
+		}
+	}
+
+	// Fetch current prices
+	quotes, err := market.GetQuotes(tickers)
+	if err != nil {
+		return err
+	}
+	fee, err := store.GetAssignmentFee(ctx)
+	if err != nil {
+		slog.Warn("loading assignment fee", "err", err)
+	}
+
+	// Process each expired option
+	var errs []error
+	for _, o := range expiredOptions {
+		quote, hasQuote := quotes[o.Ticker]
+		if !hasQuote {
+			continue
+		}
+
+		currentPrice := decimal.NewFromFloat(quote.Price)
+		isITM := false
+
+		// CALL is ITM if current price > strike (shares get called away)
+		// PUT is ITM if current price < strike (you get assigned shares)
+		if o.OptionType == "CALL" {
+			isITM = currentPrice.GreaterThan(o.Strike)
+		} else {
+			isITM = currentPrice.LessThan(o.Strike)
+		}
+
+		if isITM && o.Settlement == db.SettlementCash {
+			// Auto-settle at the closing price
+			err = store.SettleOption(ctx, o.ID, currentPrice, fee.For(o.Quantity))
+		} else if isITM {
+			// Auto-assign
+			err = store.AssignOption(ctx, o.ID, fee.For(o.Quantity))
+		} else {
+			// Auto-expire (OTM)
+			err = store.ExpireOption(ctx, o.ID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s $%s: %w", o.Ticker, o.OptionType, o.Strike, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// processExpiredOptions settles the expired options of every account, not
+// only the selected one, so an account's assignments move its shares and
+// cash without waiting for it to be opened
+func (a *App) processExpiredOptions(ctx context.Context) {
+	err := forEachAccount(ctx, a.db, func(_ string, store db.Store) error {
+		return processExpiredOptions(ctx, store, a.yahoo)
+	})
+	if err != nil {
+		slog.Warn("processing expired options", "err", err)
+	}
+}
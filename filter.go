@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"anyhowhodl/internal/db"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// filterMatches reports whether every word of filter matches the row: as a
+// substring of the ticker or of any text (notes, tags), or as one of the
+// exact values (status, type, action, #tag), ignoring case. An empty filter matches everything.
+func filterMatches(filter, ticker string, exact, text []string) bool {
+	for _, word := range strings.Fields(strings.ToLower(filter)) {
+		if !filterWordMatches(word, ticker, exact, text) {
+			return false
+		}
+	}
+	return true
+}
+
+func filterWordMatches(word, ticker string, exact, text []string) bool {
+	if strings.Contains(strings.ToLower(ticker), word) {
+		return true
+	}
+	for _, v := range exact {
+		if strings.EqualFold(v, word) {
+			return true
+		}
+	}
+	for _, v := range text {
+		if strings.Contains(strings.ToLower(v), word) {
+			return true
+		}
+	}
+	return false
+}
+
+// visible reports whether h passes the holdings filter. Archived
+// holdings only show when toggled on.
+func (v *HoldingsView) visible(h db.Holding) bool {
+	if h.Archived && !v.showArchived {
+		return false
+	}
+	exact, tags := v.book.tagFilterValues(h.ID)
+	return filterMatches(v.filter, h.Ticker, exact, append(tags, h.Notes))
+}
+
+// optionStatuses are the option statuses in the order of their toggle keys,
+// 1 to 4
+var optionStatuses = []string{"ACTIVE", "CLOSED", "EXPIRED", "ASSIGNED"}
+
+// statusShown reports whether options with status are shown. EXPIRED follows
+// the show-expired toggle.
+func (v *OptionsView) statusShown(status string) bool {
+	if status == "EXPIRED" {
+		return v.showExpired
+	}
+	return !v.hiddenStatuses[status]
+}
+
+// toggleStatus shows or hides the options with status
+func (a *App) toggleStatus(status string) {
+	if status == "EXPIRED" {
+		a.optionsView.showExpired = !a.optionsView.showExpired
+	} else if a.optionsView.hiddenStatuses[status] {
+		delete(a.optionsView.hiddenStatuses, status)
+	} else {
+		a.optionsView.hiddenStatuses[status] = true
+	}
+	a.optionsView.update()
+	a.updateStatusBar()
+}
+
+// visible reports whether o passes the status toggles and the options
+// filter
+func (v *OptionsView) visible(o db.Option) bool {
+	if !v.statusShown(o.Status) {
+		return false
+	}
+	exact, tags := v.book.tagFilterValues(o.ID)
+	return filterMatches(v.filter, o.Ticker, append(exact, o.Status, o.OptionType, o.Action), append(tags, o.Notes))
+}
+
+// selected returns the index in the book's holdings of the selected row
+func (v *HoldingsView) selected() (int, bool) {
+	row, _ := v.table.GetSelection()
+	return rowIndex(v.rows, row)
+}
+
+// selected returns the index in the book's options of the selected row
+func (v *OptionsView) selected() (int, bool) {
+	row, _ := v.table.GetSelection()
+	return rowIndex(v.rows, row)
+}
+
+// rowIndex maps a table row, below the header, to the index of the item it
+// shows
+func rowIndex(rows []int, row int) (int, bool) {
+	if row < 1 || row > len(rows) {
+		return 0, false
+	}
+	return rows[row-1], true
+}
+
+func filterTitle(filter string, shown, total int) string {
+	return fmt.Sprintf("Filter: %s (%d of %d)", tview.Escape(filter), shown, total)
+}
+
+// setTitle fills the line above the options table with the count of
+// each status, hidden ones in gray, followed by any active filter
+func (v *OptionsView) setTitle(shown int) {
+	counts := make(map[string]int, len(optionStatuses))
+	for _, o := range v.book.options {
+		counts[o.Status]++
+	}
+	var sb strings.Builder
+	sb.WriteString(" [teal]Options[white] ")
+	for i, status := range optionStatuses {
+		color := "white"
+		if !v.statusShown(status) {
+			color = "gray"
+		}
+		fmt.Fprintf(&sb, " [yellow]%d[%s]:%s %d", i+1, color, status, counts[status])
+	}
+	if v.filter != "" {
+		sb.WriteString("  [yellow]" + filterTitle(v.filter, shown, len(v.book.options)))
+	}
+	v.title.SetText(sb.String())
+}
+
+// bottomBar is the status bar, or the filter input while one is being typed
+func (a *App) bottomBar() tview.Primitive {
+	if a.filterBar != nil {
+		return a.filterBar
+	}
+	return a.statusBar
+}
+
+// showFilterBar replaces the status bar with a filter input for the focused
+// table. Rows narrow as you type; Tab switches between filtering the focused
+// table and both tables. Enter keeps the filter; Escape clears it, putting
+// back the other table's own.
+func (a *App) showFilterBar() {
+	holdings := a.focusIndex == 0
+	current := a.optionsView.filter
+	if holdings {
+		current = a.holdingsView.filter
+	}
+	// The other table's filter, put back when it leaves the filter's scope
+	otherHoldings, otherOptions := a.holdingsView.filter, a.optionsView.filter
+	both := false
+
+	input := tview.NewInputField().
+		SetText(current).
+		SetLabelColor(tcell.ColorYellow).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+	setLabel := func() {
+		switch {
+		case both:
+			input.SetLabel(" Filter holdings and options: ")
+		case holdings:
+			input.SetLabel(" Filter holdings: ")
+		default:
+			input.SetLabel(" Filter options: ")
+		}
+	}
+
+	apply := func(filter string) {
+		holdingsFilter, optionsFilter := otherHoldings, otherOptions
+		if holdings || both {
+			holdingsFilter = filter
+		}
+		if !holdings || both {
+			optionsFilter = filter
+		}
+		if holdingsFilter != a.holdingsView.filter {
+			a.holdingsView.filter = holdingsFilter
+			a.holdingsView.update()
+			a.holdingsView.table.Select(1, 0).ScrollToBeginning()
+		}
+		if optionsFilter != a.optionsView.filter {
+			a.optionsView.filter = optionsFilter
+			a.optionsView.update()
+			a.optionsView.table.Select(1, 0).ScrollToBeginning()
+		}
+	}
+
+	setLabel()
+	input.SetChangedFunc(func(text string) {
+		apply(strings.TrimSpace(text))
+	})
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			both = !both
+			setLabel()
+			apply(strings.TrimSpace(input.GetText()))
+			return nil
+		}
+		return event
+	})
+	input.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			both = false
+			apply("")
+		}
+		a.filterBar = nil
+		a.mainFlex.RemoveItem(input)
+		a.mainFlex.AddItem(a.statusBar, 1, 0, false)
+		a.setFocusIndex(a.focusIndex)
+	})
+
+	a.filterBar = input
+	a.mainFlex.RemoveItem(a.statusBar)
+	a.mainFlex.AddItem(input, 1, 0, true)
+	a.app.SetFocus(input)
+}
+
+// clearFilters drops both table filters, reporting whether any was set
+func (a *App) clearFilters() bool {
+	if a.holdingsView.filter == "" && a.optionsView.filter == "" {
+		return false
+	}
+	a.holdingsView.filter, a.optionsView.filter = "", ""
+	a.holdingsView.update()
+	a.optionsView.update()
+	return true
+}
+
+// showOption selects the option with id in the options table and focuses it,
+// first showing its status and clearing the options filter if either hides
+// it. It reports false if no such option is loaded.
+func (a *App) showOption(id string) bool {
+	index := slices.IndexFunc(a.book.options, func(o db.Option) bool { return o.ID == id })
+	if index < 0 {
+		return false
+	}
+	o := a.book.options[index]
+	if !a.optionsView.statusShown(o.Status) {
+		a.toggleStatus(o.Status)
+	}
+	if !a.optionsView.visible(o) {
+		a.optionsView.filter = ""
+		a.optionsView.update()
+	}
+	a.optionsView.table.Select(slices.Index(a.optionsView.rows, index)+1, 0)
+	a.setFocusIndex(1)
+	return true
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"anyhowhodl/internal/db"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// Holding detail chart size in characters, each a 2×4 braille dot cell
+const (
+	detailChartWidth  = 60
+	detailChartHeight = 8
+)
+
+// detailChartCloses is about three months of daily closes
+const detailChartCloses = 63
+
+// brailleDots are the bits of a braille cell's dots by column and row
+var brailleDots = [2][4]rune{{0x01, 0x02, 0x04, 0x40}, {0x08, 0x10, 0x20, 0x80}}
+
+// showHoldingDetail opens a holding's detail page: its position, a
+// three-month price chart, its options, premiums, dividends, and notes.
+// Enter opens the holding's actions.
+func (a *App) showHoldingDetail(index int) {
+	h := a.book.holdings[index]
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	view.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s ", h.Ticker)).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+	view.SetText(a.holdingDetailReport(h, nil, nil) + "\n [yellow]Loading price history...")
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEnter && !a.readOnly() {
+			a.pages.RemovePage("holding")
+			a.showHoldingActions(index)
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("holding", view, true, true)
+
+	a.goSafe("holding detail", func() {
+		closes, err := a.yahoo.FetchPriceHistory(h.Ticker)
+		dividends, _ := a.db.GetDividendsSince(context.Background(), h.EntryDate) // None without schema_dividends.sql
+		text := a.holdingDetailReport(h, closes, dividends)
+		if err != nil {
+			text += fmt.Sprintf("\n [red]Price history: %v", err)
+		}
+		a.queueUpdateDraw(func() {
+			view.SetText(text)
+		})
+	})
+}
+
+// holdingDetailReport renders the detail page for h from the last year of
+// closes, of which the chart shows the last three months, and the dividends
+// paid since it was entered
+func (a *App) holdingDetailReport(h db.Holding, closes []float64, dividends []db.DividendPayment) string {
+	symbol := currencySymbol(rowCurrency(h.Currency))
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, " [teal]%s:[white] %s shares @ %s%s since %s", h.Ticker, a.book.formatShares(h.Quantity),
+		symbol, a.book.formatPrice(h.AvgCost), a.book.locale.FormatDate(h.EntryDate))
+	if h.TargetPrice.Valid {
+		fmt.Fprintf(&sb, "  [teal]Target:[white] %s%s", symbol, a.book.formatPrice(h.TargetPrice.Decimal))
+	}
+	sb.WriteString("\n")
+	cost := h.Quantity.Mul(h.AvgCost)
+	if q, ok := a.book.quotes[h.Ticker]; ok && q.Price > 0 {
+		price := decimal.NewFromFloat(q.Price)
+		value := h.Quantity.Mul(price)
+		fmt.Fprintf(&sb, " [teal]Price:[white] %s%s  [teal]Value:[white] %s%s  [teal]Cost:[white] %s%s  [teal]P/L:[white] %s\n",
+			symbol, a.book.formatPrice(price), symbol, a.book.locale.FormatFixed(value, 2),
+			symbol, a.book.locale.FormatFixed(cost, 2), a.book.moneyText(value.Sub(cost)))
+	} else {
+		fmt.Fprintf(&sb, " [teal]Price:[white] [gray]none[white]  [teal]Cost:[white] %s%s\n", symbol, a.book.locale.FormatFixed(cost, 2))
+	}
+
+	if len(closes) > detailChartCloses {
+		closes = closes[len(closes)-detailChartCloses:]
+	}
+	if len(closes) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(a.renderPriceChart(closes, symbol))
+	}
+
+	sb.WriteString("\n [teal]Options[white]\n")
+	var collected, realized decimal.Decimal
+	count := 0
+	for _, o := range a.book.options {
+		if o.Ticker != h.Ticker {
+			continue
+		}
+		count++
+		fmt.Fprintf(&sb, "  %-4s %-4s %s%s exp %s ×%d  %s  [gray]%s[white]\n", o.Action, o.OptionType,
+			symbol, a.book.formatPrice(o.Strike), a.book.locale.FormatDate(o.ExpiryDate), o.Quantity,
+			a.book.premiumText(optionPremium(o)), o.Status)
+		if o.External {
+			continue
+		}
+		collected = collected.Add(optionPremium(o))
+		if o.Status != "ACTIVE" {
+			realized = realized.Add(realizedPL(o))
+		}
+	}
+	if count == 0 {
+		sb.WriteString("  [gray]None[white]\n")
+	} else {
+		fmt.Fprintf(&sb, " [teal]Premiums:[white] %s collected, %s realized on closed contracts\n",
+			a.book.moneyText(collected), a.book.moneyText(realized))
+	}
+
+	sb.WriteString("\n [teal]Dividends[white]\n")
+	var total decimal.Decimal
+	for _, d := range dividends {
+		if d.Ticker != h.Ticker {
+			continue
+		}
+		total = total.Add(d.Amount)
+		fmt.Fprintf(&sb, "  %s  %s%s\n", a.book.locale.FormatDate(d.PaidOn), symbol, a.book.locale.FormatFixed(d.Amount, 2))
+	}
+	if total.IsZero() {
+		sb.WriteString("  [gray]None recorded[white]\n")
+	} else {
+		fmt.Fprintf(&sb, " [teal]Total:[white] %s\n", a.book.moneyText(total))
+	}
+
+	if h.Notes != "" {
+		fmt.Fprintf(&sb, "\n [teal]Notes[white]\n  %s\n", tview.Escape(h.Notes))
+	}
+	if !a.readOnly() {
+		sb.WriteString("\n [gray]Enter: actions  Esc: back[white]")
+	}
+	return sb.String()
+}
+
+// renderPriceChart plots closes as a braille line, green if the last is at
+// or above the first and red otherwise, with the high and low on the axis
+func (a *App) renderPriceChart(closes []float64, symbol string) string {
+	color := "lime"
+	if closes[len(closes)-1] < closes[0] {
+		color = "red"
+	}
+	top, bottom := math.Inf(-1), math.Inf(1)
+	for _, c := range closes {
+		top, bottom = math.Max(top, c), math.Min(bottom, c)
+	}
+	labels := map[int]string{
+		0:                     symbol + a.book.locale.FormatFloat(top, 2),
+		detailChartHeight - 1: symbol + a.book.locale.FormatFloat(bottom, 2),
+	}
+
+	var sb strings.Builder
+	for r, line := range brailleChart(closes, detailChartWidth, detailChartHeight) {
+		fmt.Fprintf(&sb, " %12s [gray]|[%s]%s[white]\n", labels[r], color, line)
+	}
+	fmt.Fprintf(&sb, " %12s  [gray]last %d closes[white]\n", "", len(closes))
+	return sb.String()
+}
+
+// brailleChart draws values as a line across width by height braille cells,
+// the highest value on the top dot row and the lowest on the bottom one.
+// Steps between neighbouring points are filled so the line is unbroken.
+func brailleChart(values []float64, width, height int) []string {
+	cells := make([][]rune, height)
+	for r := range cells {
+		cells[r] = make([]rune, width)
+	}
+	if len(values) == 0 {
+		return brailleLines(cells)
+	}
+	top, bottom := math.Inf(-1), math.Inf(1)
+	for _, v := range values {
+		top, bottom = math.Max(top, v), math.Min(bottom, v)
+	}
+	if top == bottom {
+		top, bottom = top+1, bottom-1
+	}
+
+	dotsWide, dotsHigh := width*2, height*4
+	dotRow := func(v float64) int {
+		return int(math.Round((top - v) / (top - bottom) * float64(dotsHigh-1)))
+	}
+	prev := -1
+	for x := 0; x < dotsWide; x++ {
+		i := 0
+		if dotsWide > 1 {
+			i = int(math.Round(float64(x) * float64(len(values)-1) / float64(dotsWide-1)))
+		}
+		y := dotRow(values[i])
+		from, to := y, y
+		if prev >= 0 {
+			from, to = min(prev, y), max(prev, y)
+		}
+		for dy := from; dy <= to; dy++ {
+			cells[dy/4][x/2] |= brailleDots[x%2][dy%4]
+		}
+		prev = y
+	}
+	return brailleLines(cells)
+}
+
+// brailleLines turns dot bits into braille characters, the blank pattern
+// where a cell has none
+func brailleLines(cells [][]rune) []string {
+	lines := make([]string, len(cells))
+	for r, row := range cells {
+		var sb strings.Builder
+		for _, bits := range row {
+			sb.WriteRune(0x2800 + bits)
+		}
+		lines[r] = sb.String()
+	}
+	return lines
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// holdingHeaders are the holdings table's built-in columns in their default
+// order. The ticker always comes first; the others can be hidden or moved.
+var holdingHeaders = []string{"TICKER", "QTY", "AVG COST", "ADJ COST", "PRICE", "VALUE", "P/L", "P/L %", "PREM YTD", "DIV/YR", "WEIGHT", "vs HIGH", "vs LOW", "SIGNAL", "EARNINGS"}
+
+// holdingColumnPositions maps each built-in column to where it is shown for
+// the stored layout, -1 if hidden. Unknown names are skipped, and an empty
+// layout shows every column in the default order.
+func holdingColumnPositions(layout []string) []int {
+	pos := make([]int, len(holdingHeaders))
+	if len(layout) == 0 {
+		for i := range pos {
+			pos[i] = i
+		}
+		return pos
+	}
+	for i := range pos {
+		pos[i] = -1
+	}
+	pos[0] = 0
+	next := 1
+	for _, name := range layout {
+		i := slices.Index(holdingHeaders, name)
+		if i <= 0 || pos[i] >= 0 {
+			continue
+		}
+		pos[i] = next
+		next++
+	}
+	return pos
+}
+
+// holdingsLayout is the stored layout with every built-in column listed,
+// hidden ones after the shown ones, for the layout view
+func holdingsLayout(layout []string) (names []string, shown int) {
+	pos := holdingColumnPositions(layout)
+	names = make([]string, len(holdingHeaders))
+	for i, p := range pos {
+		if p >= 0 {
+			names[p] = holdingHeaders[i]
+			shown++
+		}
+	}
+	hidden := shown
+	for i, p := range pos {
+		if p < 0 {
+			names[hidden] = holdingHeaders[i]
+			hidden++
+		}
+	}
+	return names, shown
+}
+
+// loadHoldingsColumns reads which built-in holdings columns to show
+func (a *App) loadHoldingsColumns(ctx context.Context) {
+	layout, err := a.db.GetHoldingsColumns(ctx)
+	if err != nil {
+		slog.Warn("loading holdings columns", "err", err)
+		return
+	}
+	a.holdingsView.columns = layout
+}
+
+// columnCount is the number of built-in holdings columns shown, where
+// the custom columns start
+func (v *HoldingsView) columnCount() int {
+	count := 0
+	for _, p := range v.columnPos {
+		if p >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// setCell sets the cell of built-in column col in row, where the
+// layout shows it; hidden columns are skipped
+func (v *HoldingsView) setCell(row, col int, cell *tview.TableCell) {
+	if p := v.columnPos[col]; p >= 0 {
+		v.table.SetCell(row, p, cell)
+	}
+}
+
+// showHoldingsLayoutView lists the built-in holdings columns to show, hide,
+// and reorder; each change is saved and shown at once
+func (a *App) showHoldingsLayoutView() {
+	table := tview.NewTable().
+		SetSelectable(true, false)
+	table.SetBorder(true).
+		SetTitle(" Holdings Columns ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(" [yellow]Space[white]:Show/Hide  [yellow]K/J[white]:Move up/down  [yellow]R[white]:Reset  [gray]ESC to close")
+
+	names, shown := holdingsLayout(a.holdingsView.columns)
+	fill := func() {
+		row, _ := table.GetSelection()
+		table.Clear()
+		for i, name := range names {
+			mark, color := "[x]", tcell.ColorWhite
+			if i >= shown {
+				mark, color = "[ ]", tcell.ColorGray
+			}
+			table.SetCell(i, 0, tview.NewTableCell(" "+tview.Escape(mark)+" "+name+" ").SetTextColor(color))
+		}
+		table.Select(min(row, len(names)-1), 0)
+	}
+	save := func(selected int) {
+		layout := slices.Clone(names[1:shown])
+		if shown == len(names) && slices.Equal(names, holdingHeaders) {
+			layout = nil // The default, so new columns show up
+		}
+		if err := a.db.SetHoldingsColumns(context.Background(), layout); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error saving columns: %v", err))
+			return
+		}
+		a.holdingsView.columns = layout
+		a.holdingsView.update()
+		fill()
+		table.Select(selected, 0)
+	}
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		i, _ := table.GetSelection()
+		if a.readOnly() || i <= 0 && event.Rune() != 'R' {
+			return event // The ticker stays first and shown
+		}
+		switch event.Rune() {
+		case ' ':
+			// Moving across the boundary shows or hides it, at the end of
+			// the shown columns
+			name := names[i]
+			names = slices.Delete(names, i, i+1)
+			if i < shown {
+				shown--
+				names = slices.Insert(names, shown, name)
+				save(shown)
+			} else {
+				names = slices.Insert(names, shown, name)
+				shown++
+				save(shown - 1)
+			}
+			return nil
+		case 'K':
+			if i > 1 && i < shown {
+				names[i-1], names[i] = names[i], names[i-1]
+				save(i - 1)
+			}
+			return nil
+		case 'J':
+			if i+1 < shown {
+				names[i+1], names[i] = names[i], names[i+1]
+				save(i + 1)
+			}
+			return nil
+		case 'R':
+			names, shown = slices.Clone(holdingHeaders), len(holdingHeaders)
+			save(i)
+			return nil
+		}
+		return event
+	})
+
+	fill()
+	table.Select(1, 0)
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(help, 1, 0, false)
+	a.pages.AddPage("holdingscolumns", flex, true, true)
+}
@@ -0,0 +1,451 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/db"
+
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// showAddForm opens the new holding form, prefilled with the ticker, avg
+// cost, and notes of prefill if set. onAdded, if set, runs once the holding
+// is saved.
+func (a *App) showAddForm(prefill *db.Holding, onAdded func()) {
+	ticker, cost, notes, currency := "", "", "", db.DefaultCurrency
+	if prefill != nil {
+		ticker, notes, currency = prefill.Ticker, prefill.Notes, a.book.tickerCurrency(prefill.Ticker)
+		if !prefill.AvgCost.IsZero() {
+			cost = a.book.locale.EditNumber(prefill.AvgCost.String())
+		}
+	}
+	form := tview.NewForm().
+		AddInputField("Ticker", ticker, 10, nil, nil).
+		AddInputField("Quantity", "", 15, nil, nil).
+		AddInputField("Avg Cost ($)", cost, 15, nil, nil).
+		AddInputField("Target Price ($)", "", 15, nil, nil).
+		AddInputField("Target Weight (%)", "", 15, nil, nil).
+		AddInputField("Entry Date ("+a.book.locale.DateHint+")", a.book.locale.FormatDate(time.Now()), 15, nil, nil).
+		AddInputField("Notes", notes, 30, nil, nil).
+		AddInputField("Currency", currency, 5, nil, nil)
+
+	// Validate as typed; the ticker is upper-cased as typed, and the currency
+	// follows the ticker's when it is known
+	checks := newFormChecks(form)
+	tickerField := form.GetFormItem(0).(*tview.InputField)
+	currencyField := form.GetFormItem(7).(*tview.InputField)
+	checks.add(form, 0, requiredCheck(nil), func(text string) {
+		if upper := strings.ToUpper(text); text != upper {
+			tickerField.SetText(upper)
+			return
+		}
+		currencyField.SetText(a.book.tickerCurrency(text))
+	})
+	a.tickerAutocomplete(tickerField)
+	checks.add(form, 1, requiredCheck(a.sharesCheck()), nil)
+	checks.add(form, 2, requiredCheck(a.numberCheck(true)), nil)
+	checks.add(form, 3, optionalCheck(a.numberCheck(false)), nil)
+	checks.add(form, 4, optionalCheck(a.weightCheck), nil)
+	checks.add(form, 5, requiredCheck(a.dateCheck(notFuture)), nil)
+	a.dateField(form, 5)
+	checks.add(form, 7, requiredCheck(currencyCheck), nil)
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		if !checks.valid() {
+			a.statusBar.SetText(" [red]Fix the fields marked in red")
+			return
+		}
+		ticker := strings.ToUpper(form.GetFormItem(0).(*tview.InputField).GetText())
+		qtyStr := form.GetFormItem(1).(*tview.InputField).GetText()
+		costStr := form.GetFormItem(2).(*tview.InputField).GetText()
+		targetStr := form.GetFormItem(3).(*tview.InputField).GetText()
+		weightStr := form.GetFormItem(4).(*tview.InputField).GetText()
+		dateStr := form.GetFormItem(5).(*tview.InputField).GetText()
+		notes := form.GetFormItem(6).(*tview.InputField).GetText()
+		currency, _ := parseCurrency(currencyField.GetText())
+
+		if ticker == "" || qtyStr == "" || costStr == "" {
+			a.statusBar.SetText(" [red]Ticker, Quantity, and Avg Cost are required")
+			return
+		}
+
+		qty, err := a.book.locale.ParseNumber(qtyStr)
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid quantity")
+			return
+		}
+
+		cost, err := a.book.locale.ParseNumber(costStr)
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid cost")
+			return
+		}
+
+		var targetPrice decimal.NullDecimal
+		if targetStr != "" {
+			tp, err := a.book.locale.ParseNumber(targetStr)
+			if err != nil {
+				a.statusBar.SetText(" [red]Invalid target price")
+				return
+			}
+			targetPrice = decimal.NullDecimal{Decimal: tp, Valid: true}
+		}
+
+		entryDate, err := a.parseDate(dateStr)
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid date format")
+			return
+		}
+
+		save := func() {
+			ctx := context.Background()
+			if _, err := ensureFXRate(ctx, a.db, a.yahoo, currency); err != nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Error getting the %s exchange rate: %v", currency, err))
+				return
+			}
+			if err := a.db.AddHolding(ctx, ticker, qty, cost, currency, entryDate, targetPrice, notes); err != nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+				return
+			}
+			if err := a.db.SetTickerCurrency(ctx, ticker, currency); err != nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Error saving currency: %v", err))
+				return
+			}
+			if err := a.saveTargetWeight(ctx, ticker, weightStr); err != nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Error saving target weight: %v", err))
+				return
+			}
+
+			if onAdded != nil {
+				onAdded()
+			}
+
+			a.pages.SwitchToPage("main")
+			a.pages.RemovePage("add")
+			a.refreshData()
+		}
+
+		// New shares count at the current price when there is one
+		price := cost.InexactFloat64()
+		if q, ok := a.book.quotes[ticker]; ok && q.Price > 0 {
+			price = q.Price
+		}
+		a.confirmRiskCaps(ticker, qty.InexactFloat64()*price, save)
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.SwitchToPage("main")
+		a.pages.RemovePage("add")
+	})
+
+	form.SetBorder(true).SetTitle(" Add Holding ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("add", form, 60, 22)
+}
+
+func (a *App) showHoldingActions(index int) {
+	if a.readOnly() {
+		return
+	}
+	h := a.book.holdings[index]
+
+	text := fmt.Sprintf("Actions for %s\n%s shares @ $%s", h.Ticker, a.book.formatShares(h.Quantity), a.book.formatPrice(h.AvgCost))
+	buttons := []string{"Edit", "Buy", "Sell", "Dividend", "Delete", "Cancel"}
+	sources := a.assignedFrom(h.Ticker)
+	for _, o := range sources {
+		text += fmt.Sprintf("\nAcquired via PUT assignment: %d × $%s exp %s", o.Quantity, a.book.formatPrice(o.Strike), a.book.locale.FormatDate(o.ExpiryDate))
+	}
+	if len(sources) > 0 {
+		buttons = slices.Insert(buttons, len(buttons)-1, "Go to option")
+	}
+
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons(buttons).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			switch buttonLabel {
+			case "Go to option":
+				a.pages.RemovePage("actions")
+				a.showOption(sources[0].ID)
+			case "Edit":
+				a.pages.RemovePage("actions")
+				a.showEditForm(index)
+			case "Buy":
+				a.pages.RemovePage("actions")
+				a.showBuyForm(index)
+			case "Sell":
+				a.pages.RemovePage("actions")
+				a.showSellForm(index)
+			case "Dividend":
+				a.pages.RemovePage("actions")
+				a.showDividendForm(index)
+			case "Delete":
+				a.pages.RemovePage("actions")
+				a.confirmDelete(index)
+			default:
+				a.pages.RemovePage("actions")
+			}
+		})
+
+	a.pages.AddPage("actions", modal, true, true)
+}
+
+// assignedFrom returns the assigned puts that delivered shares of ticker,
+// latest first, from the option each assignment in the ledger records
+func (a *App) assignedFrom(ticker string) []db.Option {
+	txs, err := a.db.GetTransactions(context.Background())
+	if err != nil {
+		return nil
+	}
+	var sources []db.Option
+	for i := len(txs) - 1; i >= 0; i-- {
+		t := txs[i]
+		if t.Kind != db.TxAssignment || t.Ticker != ticker || t.OptionID == "" || !t.Quantity.IsPositive() {
+			continue
+		}
+		if j := slices.IndexFunc(a.book.options, func(o db.Option) bool { return o.ID == t.OptionID }); j >= 0 {
+			sources = append(sources, a.book.options[j])
+		}
+	}
+	return sources
+}
+
+// showBuyForm adds shares to a holding at a price, averaging them into its
+// cost and debiting cash
+func (a *App) showBuyForm(index int) {
+	h := a.book.holdings[index]
+	price := ""
+	if q, ok := a.book.quotes[h.Ticker]; ok && q.Price > 0 {
+		price = a.book.locale.EditNumber(decimal.NewFromFloat(q.Price).StringFixed(2))
+	}
+	symbol := currencySymbol(rowCurrency(h.Currency))
+
+	form := tview.NewForm().
+		AddInputField("Quantity", "", 15, nil, nil).
+		AddInputField("Price ("+strings.TrimSpace(symbol)+")", price, 15, nil, nil).
+		AddInputField("Notes", "", 30, nil, nil)
+	checks := newFormChecks(form)
+	checks.add(form, 0, requiredCheck(a.sharesCheck()), nil)
+	checks.add(form, 1, requiredCheck(a.numberCheck(false)), nil)
+	styleForm(form)
+
+	form.AddButton("Buy", func() {
+		if !checks.valid() {
+			a.statusBar.SetText(" [red]Fix the fields marked in red")
+			return
+		}
+		qty, _ := a.book.locale.ParseNumber(form.GetFormItem(0).(*tview.InputField).GetText())
+		price, _ := a.book.locale.ParseNumber(form.GetFormItem(1).(*tview.InputField).GetText())
+		notes := form.GetFormItem(2).(*tview.InputField).GetText()
+
+		// AddHolding merges into the existing position at the weighted
+		// average cost and records the purchase in the ledger
+		if err := a.db.AddHolding(context.Background(), h.Ticker, qty, price, rowCurrency(h.Currency), a.now(), decimal.NullDecimal{}, notes); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+		a.pages.RemovePage("buy")
+		a.refreshData()
+		total := h.Quantity.Add(qty)
+		avgCost := h.Quantity.Mul(h.AvgCost).Add(qty.Mul(price)).Div(total)
+		a.statusBar.SetText(fmt.Sprintf(" [green]Bought %s %s @ %s%s, now %s @ %s%s", a.book.formatShares(qty), h.Ticker,
+			symbol, a.book.formatPrice(price), a.book.formatShares(total), symbol, a.book.formatPrice(avgCost)))
+	})
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("buy")
+	})
+	form.SetBorder(true).SetTitle(fmt.Sprintf(" Buy %s (%s held @ %s%s) ", h.Ticker, a.book.formatShares(h.Quantity), symbol, a.book.formatPrice(h.AvgCost))).SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("buy", form, 60, 14)
+}
+
+// showSellForm sells some or all of a holding's shares, crediting the
+// proceeds to cash and recording the realized P/L
+func (a *App) showSellForm(index int) {
+	h := a.book.holdings[index]
+	price := ""
+	if q, ok := a.book.quotes[h.Ticker]; ok && q.Price > 0 {
+		price = a.book.locale.EditNumber(decimal.NewFromFloat(q.Price).StringFixed(2))
+	}
+	symbol := currencySymbol(rowCurrency(h.Currency))
+
+	form := tview.NewForm().
+		AddInputField("Quantity", a.book.locale.EditNumber(h.Quantity.String()), 15, nil, nil).
+		AddInputField("Sale Price ("+strings.TrimSpace(symbol)+")", price, 15, nil, nil).
+		AddInputField("Notes", "", 30, nil, nil)
+	shares := a.sharesCheck()
+	checks := newFormChecks(form)
+	checks.add(form, 0, requiredCheck(func(text string) string {
+		if problem := shares(text); problem != "" {
+			return problem
+		}
+		if n, _ := a.book.locale.ParseNumber(text); n.GreaterThan(h.Quantity) {
+			return "only " + a.book.formatShares(h.Quantity) + " held"
+		}
+		return ""
+	}), nil)
+	checks.add(form, 1, requiredCheck(a.numberCheck(false)), nil)
+	styleForm(form)
+
+	form.AddButton("Sell", func() {
+		if !checks.valid() {
+			a.statusBar.SetText(" [red]Fix the fields marked in red")
+			return
+		}
+		qty, _ := a.book.locale.ParseNumber(form.GetFormItem(0).(*tview.InputField).GetText())
+		price, _ := a.book.locale.ParseNumber(form.GetFormItem(1).(*tview.InputField).GetText())
+		notes := form.GetFormItem(2).(*tview.InputField).GetText()
+
+		if err := a.db.SellHolding(context.Background(), h.Ticker, qty, price, a.now(), notes); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error (run schema_sales.sql): %v", err))
+			return
+		}
+		a.pages.RemovePage("sell")
+		a.refreshData()
+		realized := db.StockSale{Quantity: qty, Price: price, AvgCost: h.AvgCost}.RealizedPL()
+		a.statusBar.SetText(fmt.Sprintf(" [green]Sold %s %s @ %s%s, realized %s", a.book.formatShares(qty), h.Ticker,
+			symbol, a.book.formatPrice(price), a.book.moneyText(a.book.toBase(realized, h.Currency))))
+	})
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("sell")
+	})
+	form.SetBorder(true).SetTitle(fmt.Sprintf(" Sell %s (%s held @ %s%s) ", h.Ticker, a.book.formatShares(h.Quantity), symbol, a.book.formatPrice(h.AvgCost))).SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("sell", form, 60, 14)
+}
+
+func (a *App) showEditForm(index int) {
+	h := a.book.holdings[index]
+
+	targetStr := ""
+	if h.TargetPrice.Valid {
+		targetStr = h.TargetPrice.Decimal.String()
+	}
+
+	symbol := strings.TrimSpace(currencySymbol(rowCurrency(h.Currency)))
+
+	form := tview.NewForm().
+		AddInputField("Quantity", a.book.locale.EditNumber(h.Quantity.String()), 15, nil, nil).
+		AddInputField("Avg Cost ("+symbol+")", a.book.locale.EditNumber(h.AvgCost.String()), 15, nil, nil).
+		AddInputField("Target Price ("+symbol+")", targetStr, 15, nil, nil).
+		AddInputField("Target Weight (%)", a.editTargetWeight(h.Ticker), 15, nil, nil).
+		AddInputField("Notes", h.Notes, 30, nil, nil).
+		AddInputField("Currency", rowCurrency(h.Currency), 5, nil, nil)
+
+	checks := newFormChecks(form)
+	checks.add(form, 0, requiredCheck(a.sharesCheck()), nil)
+	checks.add(form, 1, requiredCheck(a.numberCheck(true)), nil)
+	checks.add(form, 2, optionalCheck(a.numberCheck(false)), nil)
+	checks.add(form, 3, optionalCheck(a.weightCheck), nil)
+	checks.add(form, 5, requiredCheck(currencyCheck), nil)
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		if !checks.valid() {
+			a.statusBar.SetText(" [red]Fix the fields marked in red")
+			return
+		}
+		qtyStr := form.GetFormItem(0).(*tview.InputField).GetText()
+		costStr := form.GetFormItem(1).(*tview.InputField).GetText()
+		targetStr := form.GetFormItem(2).(*tview.InputField).GetText()
+		weightStr := form.GetFormItem(3).(*tview.InputField).GetText()
+		notes := form.GetFormItem(4).(*tview.InputField).GetText()
+		currency, _ := parseCurrency(form.GetFormItem(5).(*tview.InputField).GetText())
+
+		qty, err := a.book.locale.ParseNumber(qtyStr)
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid quantity")
+			return
+		}
+
+		cost, err := a.book.locale.ParseNumber(costStr)
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid cost")
+			return
+		}
+
+		var targetPrice decimal.NullDecimal
+		if targetStr != "" {
+			tp, err := a.book.locale.ParseNumber(targetStr)
+			if err != nil {
+				a.statusBar.SetText(" [red]Invalid target price")
+				return
+			}
+			targetPrice = decimal.NullDecimal{Decimal: tp, Valid: true}
+		}
+
+		saved := func(err error) {
+			if err != nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+				return
+			}
+			if err := a.saveTargetWeight(context.Background(), h.Ticker, weightStr); err != nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Error saving target weight: %v", err))
+				return
+			}
+			if err := a.db.SetTickerCurrency(context.Background(), h.Ticker, currency); err != nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Error saving currency: %v", err))
+				return
+			}
+			a.pages.SwitchToPage("main")
+			a.pages.RemovePage("edit")
+			a.refreshData()
+		}
+
+		ctx := context.Background()
+		err = a.db.UpdateHoldingIfUnchanged(ctx, h.ID, h.UpdatedAt, qty, cost, targetPrice, notes)
+		if errors.Is(err, db.ErrConflict) {
+			a.showConflictPrompt(h.Ticker, func() {
+				saved(a.db.UpdateHolding(ctx, h.ID, qty, cost, targetPrice, notes))
+			}, func() {
+				a.pages.RemovePage("edit")
+				a.refreshData()
+				for i := range a.book.holdings {
+					if a.book.holdings[i].ID == h.ID {
+						a.showEditForm(i)
+					}
+				}
+			})
+			return
+		}
+		saved(err)
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.SwitchToPage("main")
+		a.pages.RemovePage("edit")
+	})
+
+	form.SetBorder(true).SetTitle(fmt.Sprintf(" Edit %s ", h.Ticker)).SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("edit", form, 60, 19)
+}
+
+func (a *App) confirmDelete(index int) {
+	h := a.book.holdings[index]
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Delete %s?\n%s shares @ $%s", h.Ticker, a.book.formatShares(h.Quantity), a.book.formatPrice(h.AvgCost))).
+		AddButtons([]string{"Delete", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel == "Delete" {
+				ctx := context.Background()
+				if err := a.db.DeleteHolding(ctx, h.ID); err != nil {
+					a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+				}
+				a.refreshData()
+			}
+			a.pages.RemovePage("confirm")
+		})
+
+	a.pages.AddPage("confirm", modal, true, true)
+}
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/yahoo"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// HoldingsView is the holdings table: each holding in the book with its
+// value, P/L, and signals, narrowed by the quick filter
+type HoldingsView struct {
+	table        *tview.Table
+	filter       string          // Quick filter on the holdings table
+	rows         []int           // Index in holdings of each table row
+	columns      []string        // Built-in columns shown, in order; nil for all
+	columnPos    []int           // Display column of each built-in column, -1 if hidden
+	showArchived bool            // Show archived holdings
+	value        decimal.Decimal // Total holdings value from the last update
+	cost         decimal.Decimal // Total cost basis from the last update
+
+	book  *book
+	cells holdingsCells
+	bus   *eventBus
+}
+
+// holdingsCells are the holdings table's cells other features fill in, and
+// the clock manual prices age by
+type holdingsCells interface {
+	rowBackground(id string) tcell.Color
+	weightCell(ticker string, weight decimal.Decimal, bg tcell.Color) *tview.TableCell
+	dividendCell(h db.Holding, bg tcell.Color) *tview.TableCell
+	holdingEarningsCell(ticker string, bg tcell.Color) *tview.TableCell
+	now() time.Time
+}
+
+// newHoldingsView builds the holdings table of b, redrawn whenever the data
+// loads; the App wires its selection
+func newHoldingsView(b *book, cells holdingsCells, bus *eventBus) *HoldingsView {
+	v := &HoldingsView{
+		table: tview.NewTable().
+			SetBorders(true).
+			SetSelectable(true, false).
+			SetFixed(1, 0).
+			SetSeparator(' ').
+			SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorDarkSlateGray)),
+		book:  b,
+		cells: cells,
+		bus:   bus,
+	}
+	bus.subscribe(eventDataLoaded, v.update)
+	return v
+}
+
+// update redraws the table and its totals, then tells the views showing them
+func (v *HoldingsView) update() {
+	v.table.Clear()
+
+	// Header row - cyan color scheme
+	v.columnPos = holdingColumnPositions(v.columns)
+	for i, h := range holdingHeaders {
+		cell := tview.NewTableCell(" " + h + " ").
+			SetTextColor(tcell.ColorBlack).
+			SetBackgroundColor(tcell.ColorTeal).
+			SetAlign(tview.AlignLeft).
+			SetSelectable(false).
+			SetExpansion(1)
+		v.setCell(0, i, cell)
+	}
+	columns := v.book.columnsFor(db.ColumnTableHoldings)
+	custom := v.columnCount()
+	if v.book.showTags {
+		setTagsHeader(v.table, custom)
+		custom++
+	}
+	setCustomHeaders(v.table, columns, custom)
+
+	// First pass: calculate total portfolio value
+	positionValues, totalValue, totalCost := v.book.positionValues()
+
+	// Second pass: populate table with weight %, for the rows passing the filter
+	v.rows = v.rows[:0]
+	for i, h := range v.book.holdings {
+		if !v.visible(h) {
+			continue
+		}
+		v.rows = append(v.rows, i)
+		row := len(v.rows)
+		rowBg := v.cells.rowBackground(h.ID)
+
+		// Ticker - magenta/purple for visibility, gray when archived
+		tickerColor := tcell.ColorFuchsia
+		if h.Archived {
+			tickerColor = tcell.ColorGray
+		}
+		v.setCell(row, 0, tview.NewTableCell(v.book.tickerLabel(h.ID, h.Ticker)).
+			SetTextColor(tickerColor).
+			SetBackgroundColor(rowBg).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		// Quantity
+		v.setCell(row, 1, tview.NewTableCell(" "+v.book.formatShares(h.Quantity)+" ").
+			SetTextColor(tcell.ColorWhite).
+			SetBackgroundColor(rowBg).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		// Avg Cost, in the holding's own currency like the price
+		symbol := currencySymbol(rowCurrency(h.Currency))
+		v.setCell(row, 2, tview.NewTableCell(" "+symbol+v.book.formatPrice(h.AvgCost)+" ").
+			SetTextColor(tcell.ColorWhite).
+			SetBackgroundColor(rowBg).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		// Avg cost less the wheel's premiums on the ticker: the break-even
+		v.setCell(row, 3, v.adjustedCostCell(h, symbol, rowBg))
+
+		quote, hasQuote := v.book.quotes[h.Ticker]
+		costBasis := v.book.toBase(h.Quantity.Mul(h.AvgCost), h.Currency)
+		value := positionValues[i]
+
+		// Calculate weight
+		weight := decimal.Zero
+		if !totalValue.IsZero() {
+			weight = value.Div(totalValue).Mul(decimal.NewFromInt(100))
+		}
+
+		if hasQuote {
+			price := decimal.NewFromFloat(quote.Price)
+			pl := value.Sub(costBasis)
+			plPct := decimal.Zero
+			if !costBasis.IsZero() {
+				plPct = pl.Div(costBasis).Mul(decimal.NewFromInt(100))
+			}
+
+			// Price - cyan, or a manual or last-known price with its age
+			manual, isManual := v.book.manualPrices[h.Ticker]
+			stale, isStale := v.book.staleSymbols[h.Ticker]
+			isStale = isStale && !isManual && v.book.isFilledQuote(h.Ticker)
+			if isManual {
+				v.setCell(row, 4, v.manualPriceCell(manual, rowBg))
+			} else if isStale {
+				v.setCell(row, 4, v.stalePriceCell(stale, rowBg))
+			} else {
+				v.setCell(row, 4, tview.NewTableCell(" "+symbol+v.book.formatPrice(price)+" ").
+					SetTextColor(tcell.ColorAqua).
+					SetBackgroundColor(rowBg).
+					SetAlign(tview.AlignLeft).
+					SetExpansion(1))
+			}
+
+			// Value - yellow
+			v.setCell(row, 5, tview.NewTableCell(" "+v.book.baseSymbol()+v.book.locale.FormatFixed(value, 2)+" ").
+				SetTextColor(tcell.ColorYellow).
+				SetBackgroundColor(rowBg).
+				SetAlign(tview.AlignLeft).
+				SetExpansion(1))
+
+			// P/L
+			plColor := tcell.ColorWhite
+			if pl.IsPositive() {
+				plColor = tcell.ColorLime
+			} else if pl.IsNegative() {
+				plColor = tcell.ColorRed
+			}
+			plSign := ""
+			if pl.IsPositive() {
+				plSign = "+"
+			}
+			v.setCell(row, 6, tview.NewTableCell(" "+plSign+v.book.baseSymbol()+v.book.locale.FormatFixed(pl, 2)+" ").
+				SetTextColor(plColor).
+				SetBackgroundColor(rowBg).
+				SetAlign(tview.AlignLeft).
+				SetExpansion(1))
+
+			// P/L %
+			pctSign := ""
+			if plPct.IsPositive() {
+				pctSign = "+"
+			}
+			v.setCell(row, 7, tview.NewTableCell(" "+pctSign+v.book.locale.FormatFixed(plPct, 2)+"% ").
+				SetTextColor(plColor).
+				SetBackgroundColor(rowBg).
+				SetAlign(tview.AlignLeft).
+				SetExpansion(1))
+
+			// Weight %, with the drift from its target weight
+			v.setCell(row, 10, v.cells.weightCell(h.Ticker, weight, rowBg))
+
+			// % from 52-week high - green if big dip (buying opportunity)
+			pctFromHigh := quote.PctFromHigh
+			highPrice := decimal.NewFromFloat(quote.FiftyTwoWeekHigh)
+			highColor := tcell.ColorWhite
+			highText := fmt.Sprintf(" %s%% (%s%s) ", v.book.locale.FormatFloat(pctFromHigh, 1), symbol, v.book.formatPrice(highPrice))
+			if isManual || isStale {
+				highText = " - " // No market data behind the price
+			} else if pctFromHigh <= -20 {
+				highColor = tcell.ColorLime // Big dip - potential buy
+			} else if pctFromHigh <= -10 {
+				highColor = tcell.ColorYellow // Moderate dip
+			}
+			v.setCell(row, 11, tview.NewTableCell(highText).
+				SetTextColor(highColor).
+				SetBackgroundColor(rowBg).
+				SetAlign(tview.AlignLeft).
+				SetExpansion(1))
+
+			// % above 52-week low - green near it (buying opportunity)
+			nearLow := !isManual && !isStale && nearYearLow(quote)
+			v.setCell(row, 12, v.lowCell(quote, symbol, isManual || isStale, rowBg))
+
+			// SIGNAL - take-profit signals (priority order)
+			signalText := " - "
+			signalColor := tcell.ColorWhite
+
+			// Check signals in priority order (most urgent first)
+			if h.TargetPrice.Valid && price.GreaterThanOrEqual(h.TargetPrice.Decimal) {
+				// Target price hit - highest priority sell signal
+				signalText = " TARGET "
+				signalColor = tcell.ColorRed
+			} else if plPct.GreaterThanOrEqual(decimal.NewFromInt(200)) {
+				signalText = " +200% "
+				signalColor = tcell.ColorRed
+			} else if plPct.GreaterThanOrEqual(decimal.NewFromInt(100)) {
+				signalText = " +100% "
+				signalColor = tcell.ColorRed
+			} else if plPct.GreaterThanOrEqual(decimal.NewFromInt(50)) {
+				signalText = " +50% "
+				signalColor = tcell.ColorOrange
+			} else if plPct.GreaterThanOrEqual(decimal.NewFromInt(25)) {
+				signalText = " +25% "
+				signalColor = tcell.ColorYellow
+			} else if weight.GreaterThan(decimal.NewFromInt(25)) {
+				// Position too large - rebalance signal
+				signalText = " REBAL "
+				signalColor = tcell.ColorOrange
+			} else if pctFromHigh >= -5 && pctFromHigh < 0 {
+				// Within 5% of 52-week high
+				signalText = " PEAK "
+				signalColor = tcell.ColorTeal
+			} else if (h.TargetPrice.Valid && price.LessThan(h.TargetPrice.Decimal)) || nearLow {
+				// Below target or near the 52-week low - buy signal
+				signalText = " BUY "
+				signalColor = tcell.ColorLime
+			}
+
+			v.setCell(row, 13, tview.NewTableCell(signalText).
+				SetTextColor(signalColor).
+				SetBackgroundColor(rowBg).
+				SetAlign(tview.AlignLeft).
+				SetExpansion(1))
+		} else {
+			v.setCell(row, 4, tview.NewTableCell(" - ").SetBackgroundColor(rowBg).SetAlign(tview.AlignLeft).SetExpansion(1))
+			v.setCell(row, 5, tview.NewTableCell(" - ").SetBackgroundColor(rowBg).SetAlign(tview.AlignLeft).SetExpansion(1))
+			v.setCell(row, 6, tview.NewTableCell(" - ").SetBackgroundColor(rowBg).SetAlign(tview.AlignLeft).SetExpansion(1))
+			v.setCell(row, 7, tview.NewTableCell(" - ").SetBackgroundColor(rowBg).SetAlign(tview.AlignLeft).SetExpansion(1))
+			v.setCell(row, 10, v.cells.weightCell(h.Ticker, weight, rowBg))
+			v.setCell(row, 11, tview.NewTableCell(" - ").SetBackgroundColor(rowBg).SetAlign(tview.AlignLeft).SetExpansion(1))
+			v.setCell(row, 12, tview.NewTableCell(" - ").SetBackgroundColor(rowBg).SetAlign(tview.AlignLeft).SetExpansion(1))
+			v.setCell(row, 13, tview.NewTableCell(" - ").SetBackgroundColor(rowBg).SetAlign(tview.AlignLeft).SetExpansion(1))
+		}
+
+		// Net option premium on the ticker this (tax) year
+		v.setCell(row, 8, v.premiumCell(h.Ticker, h.Currency, rowBg))
+
+		// Dividends projected over the next year
+		v.setCell(row, 9, v.cells.dividendCell(h, rowBg))
+
+		// Next earnings date
+		v.setCell(row, 14, v.cells.holdingEarningsCell(h.Ticker, rowBg))
+
+		if v.book.showTags {
+			v.table.SetCell(row, custom-1, v.book.tagsCell(h.ID, rowBg))
+		}
+
+		// User-defined columns
+		v.book.setCustomCells(v.table, row, columns, custom, v.book.holdingVars(h, value, weight), rowBg)
+	}
+	v.value = totalValue
+	v.cost = totalCost
+	v.bus.publish(eventHoldingsChanged)
+}
+
+// premiumCell shows the net premium collected on ticker this tax year, in
+// the base currency from the ticker's currency, green, or red if closing
+// trades cost more than was collected
+func (v *HoldingsView) premiumCell(ticker, currency string, bg tcell.Color) *tview.TableCell {
+	net, ok := v.book.tickerPremiums[ticker]
+	if !ok {
+		return tview.NewTableCell(" - ").SetBackgroundColor(bg).SetAlign(tview.AlignLeft).SetExpansion(1)
+	}
+	net = v.book.toBase(net, currency)
+	text, color := " "+v.book.baseSymbol()+v.book.locale.FormatFixed(net, 2)+" ", tcell.ColorLime
+	if net.IsNegative() {
+		text, color = " -"+v.book.baseSymbol()+v.book.locale.FormatFixed(net.Abs(), 2)+" ", tcell.ColorRed
+	}
+	return tview.NewTableCell(text).
+		SetTextColor(color).
+		SetBackgroundColor(bg).
+		SetAlign(tview.AlignLeft).
+		SetExpansion(1)
+}
+
+// wheelGrace is how long before a holding's entry date an assigned put may
+// have expired and still count toward it, as assignments are often recorded
+// a few days late and the holding dated then
+const wheelGrace = 7 * 24 * time.Hour
+
+// wheelPremium is the net premium of the covered calls and assigned puts on
+// h's ticker from the wheel cycle the shares are part of: those expiring on
+// or after its entry date, less wheelGrace. ok is false when there are none.
+func (v *HoldingsView) wheelPremium(h db.Holding) (net decimal.Decimal, ok bool) {
+	since := h.EntryDate.Add(-wheelGrace)
+	for _, o := range v.book.options {
+		if o.Ticker != h.Ticker || o.Action != "SELL" || o.External || o.ExpiryDate.Before(since) {
+			continue
+		}
+		if o.OptionType == "CALL" || o.OptionType == "PUT" && o.Status == "ASSIGNED" {
+			net = net.Add(realizedPL(o))
+			ok = true
+		}
+	}
+	return net, ok
+}
+
+// adjustedCostCell shows h's average cost less its wheel premium per share,
+// in the holding's own currency like the avg cost
+func (v *HoldingsView) adjustedCostCell(h db.Holding, symbol string, bg tcell.Color) *tview.TableCell {
+	net, ok := v.wheelPremium(h)
+	if !ok || !h.Quantity.IsPositive() {
+		return tview.NewTableCell(" - ").SetBackgroundColor(bg).SetAlign(tview.AlignLeft).SetExpansion(1)
+	}
+	adjusted := h.AvgCost.Sub(net.Div(h.Quantity))
+	color := tcell.ColorLime
+	if adjusted.GreaterThan(h.AvgCost) {
+		color = tcell.ColorRed // Closing calls cost more than the premiums took in
+	}
+	text := " " + symbol + v.book.formatPrice(adjusted) + " "
+	if adjusted.IsNegative() {
+		text = " -" + symbol + v.book.formatPrice(adjusted.Abs()) + " "
+	}
+	return tview.NewTableCell(text).
+		SetTextColor(color).
+		SetBackgroundColor(bg).
+		SetAlign(tview.AlignLeft).
+		SetExpansion(1)
+}
+
+// nearLowPct is how far above its 52-week low a price counts as near it
+const nearLowPct = 5
+
+// nearYearLow reports whether q is within nearLowPct of its 52-week low
+func nearYearLow(q yahoo.Quote) bool {
+	return q.FiftyTwoWeekLow > 0 && q.PctFromLow <= nearLowPct
+}
+
+// lowCell shows how far q is above its 52-week low, green near it and yellow
+// within twice that; noData blanks it when no market data is behind the price
+func (v *HoldingsView) lowCell(q yahoo.Quote, symbol string, noData bool, bg tcell.Color) *tview.TableCell {
+	text, color := " - ", tcell.ColorWhite
+	if !noData && q.FiftyTwoWeekLow > 0 {
+		text = fmt.Sprintf(" +%s%% (%s%s) ", v.book.locale.FormatFloat(q.PctFromLow, 1), symbol, v.book.formatPrice(decimal.NewFromFloat(q.FiftyTwoWeekLow)))
+		if nearYearLow(q) {
+			color = tcell.ColorLime // Near the low - potential buy
+		} else if q.PctFromLow <= 2*nearLowPct {
+			color = tcell.ColorYellow
+		}
+	}
+	return tview.NewTableCell(text).
+		SetTextColor(color).
+		SetBackgroundColor(bg).
+		SetAlign(tview.AlignLeft).
+		SetExpansion(1)
+}
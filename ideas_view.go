@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"anyhowhodl/internal/datespec"
+	"anyhowhodl/internal/db"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// showIdeasView lists the trade ideas waiting to fill at the broker
+func (a *App) showIdeasView() {
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	table.SetBorder(true).
+		SetTitle(" Trade Ideas ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(" [yellow]b/Enter[white]:Book fill  [yellow]a[white]:Add  [yellow]d[white]:Delete  [gray]ESC to close")
+
+	a.loadIdeas(context.Background())
+	a.fillIdeasTable(table)
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		row, _ := table.GetSelection()
+		var selected *db.TradeIdea
+		if row >= 1 && row <= len(a.ideas) {
+			selected = &a.ideas[row-1]
+		}
+		switch {
+		case event.Rune() == 'b' || event.Key() == tcell.KeyEnter:
+			if !a.readOnly() && selected != nil {
+				a.bookIdea(*selected)
+			}
+			return nil
+		case event.Rune() == 'a':
+			if !a.readOnly() {
+				a.showIdeaForm(table)
+			}
+			return nil
+		case event.Rune() == 'd':
+			if !a.readOnly() && selected != nil {
+				a.confirmDeleteIdea(table, *selected)
+			}
+			return nil
+		}
+		return event
+	})
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(help, 1, 0, false)
+	a.pages.AddPage("ideas", flex, true, true)
+}
+
+// loadIdeas reads the trade idea queue
+func (a *App) loadIdeas(ctx context.Context) {
+	ideas, err := a.db.GetTradeIdeas(ctx)
+	if err != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error loading trade ideas (run schema_ideas.sql): %v", err))
+		return
+	}
+	a.ideas = ideas
+}
+
+// fillIdeasTable lists the queued ideas, oldest first, with the credit each
+// would bring in at its intended premium
+func (a *App) fillIdeasTable(table *tview.Table) {
+	row, _ := table.GetSelection()
+	table.Clear()
+	for i, h := range []string{"TICKER", "TYPE", "STRIKE", "EXPIRY", "DTE", "QTY", "PREMIUM", "CREDIT", "ADDED", "NOTES"} {
+		table.SetCell(0, i, tview.NewTableCell(" "+h+" ").
+			SetTextColor(tcell.ColorBlack).
+			SetBackgroundColor(tcell.ColorTeal).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+	if len(a.ideas) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell(" No trade ideas. Press i on a CSP advisor row, or a to add one.").
+			SetTextColor(tcell.ColorGray).
+			SetSelectable(false))
+		return
+	}
+
+	today := a.today()
+	for i, idea := range a.ideas {
+		dte := int(idea.ExpiryDate.Sub(today).Hours() / 24)
+		credit := idea.Premium.Mul(decimal.NewFromInt(int64(idea.Quantity * db.DefaultMultiplier)))
+		cells := []string{
+			idea.Ticker,
+			idea.OptionType,
+			"$" + a.book.formatPrice(idea.Strike),
+			a.book.locale.FormatDate(idea.ExpiryDate),
+			strconv.Itoa(dte),
+			strconv.Itoa(idea.Quantity),
+			"$" + a.book.formatPrice(idea.Premium),
+			a.book.premiumText(credit),
+			a.book.locale.FormatDate(idea.CreatedAt),
+			idea.Notes,
+		}
+		colors := []tcell.Color{tcell.ColorFuchsia, tcell.ColorWhite, tcell.ColorWhite, tcell.ColorWhite, tcell.ColorWhite,
+			tcell.ColorWhite, tcell.ColorWhite, tcell.ColorLime, tcell.ColorGray, tcell.ColorGray}
+		if dte < 0 {
+			colors[3], colors[4] = tcell.ColorRed, tcell.ColorRed // Expired before it filled
+		}
+		for c, text := range cells {
+			table.SetCell(i+1, c, tview.NewTableCell(" "+tview.Escape(text)+" ").
+				SetTextColor(colors[c]).
+				SetExpansion(1))
+		}
+	}
+	table.Select(max(1, min(row, len(a.ideas))), 0)
+}
+
+// showIdeaForm queues a short option entered by hand, such as a covered call
+func (a *App) showIdeaForm(table *tview.Table) {
+	expiry := a.book.locale.FormatDate(datespec.NextFriday(a.today()))
+	form := tview.NewForm().
+		AddInputField("Ticker", "", 10, nil, nil).
+		AddDropDown("Type", []string{"CALL", "PUT"}, 0, nil).
+		AddInputField("Strike ($)", "", 15, nil, nil).
+		AddInputField("Expiry ("+a.book.locale.DateHint+")", expiry, 15, nil, nil).
+		AddInputField("Quantity", "1", 10, nil, nil).
+		AddInputField("Premium ($)", "", 15, nil, nil).
+		AddInputField("Notes", "", 40, nil, nil)
+
+	checks := newFormChecks(form)
+	tickerField := form.GetFormItem(0).(*tview.InputField)
+	checks.add(form, 0, requiredCheck(nil), func(text string) {
+		if upper := strings.ToUpper(text); text != upper {
+			tickerField.SetText(upper)
+		}
+	})
+	a.tickerAutocomplete(tickerField)
+	checks.add(form, 2, requiredCheck(a.numberCheck(false)), nil)
+	checks.add(form, 3, requiredCheck(a.dateCheck(notPast)), nil)
+	a.dateField(form, 3)
+	checks.add(form, 4, requiredCheck(contractsCheck), nil)
+	checks.add(form, 5, requiredCheck(a.numberCheck(true)), nil)
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		if !checks.valid() {
+			a.statusBar.SetText(" [red]Fix the fields marked in red")
+			return
+		}
+		ticker := strings.ToUpper(strings.TrimSpace(tickerField.GetText()))
+		_, optionType := form.GetFormItem(1).(*tview.DropDown).GetCurrentOption()
+		strike, err := a.book.locale.ParseNumber(form.GetFormItem(2).(*tview.InputField).GetText())
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid strike price")
+			return
+		}
+		expiry, err := a.parseDate(form.GetFormItem(3).(*tview.InputField).GetText())
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid expiry date format")
+			return
+		}
+		qty, err := strconv.Atoi(form.GetFormItem(4).(*tview.InputField).GetText())
+		if err != nil || qty < 1 {
+			a.statusBar.SetText(" [red]Invalid quantity")
+			return
+		}
+		premium, err := a.book.locale.ParseNumber(form.GetFormItem(5).(*tview.InputField).GetText())
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid premium")
+			return
+		}
+		notes := strings.TrimSpace(form.GetFormItem(6).(*tview.InputField).GetText())
+
+		ctx := context.Background()
+		if err := a.db.AddTradeIdea(ctx, ticker, optionType, strike, expiry, qty, premium, notes); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+		a.pages.RemovePage("ideaform")
+		a.loadIdeas(ctx)
+		a.fillIdeasTable(table)
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("ideaform")
+	})
+
+	form.SetBorder(true).SetTitle(" Add Trade Idea ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("ideaform", form, 60, 19)
+}
+
+// confirmDeleteIdea drops idea from the queue once confirmed
+func (a *App) confirmDeleteIdea(table *tview.Table, idea db.TradeIdea) {
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Delete the idea to sell %s %s $%s %s?", idea.Ticker, idea.OptionType,
+			a.book.formatPrice(idea.Strike), a.book.locale.FormatDate(idea.ExpiryDate))).
+		AddButtons([]string{"Delete", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("confirm_delete_idea")
+			if buttonLabel != "Delete" {
+				return
+			}
+			ctx := context.Background()
+			if err := a.db.DeleteTradeIdea(ctx, idea.ID); err != nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+				return
+			}
+			a.loadIdeas(ctx)
+			a.fillIdeasTable(table)
+		})
+	a.pages.AddPage("confirm_delete_idea", modal, true, true)
+}
+
+// bookIdea opens the new option form filled from idea, its premium ready to
+// be replaced by the actual fill price, and takes the idea off the queue once
+// the option is saved
+func (a *App) bookIdea(idea db.TradeIdea) {
+	prefill := db.Option{
+		Ticker:     idea.Ticker,
+		OptionType: idea.OptionType,
+		Action:     "SELL",
+		Strike:     idea.Strike,
+		ExpiryDate: idea.ExpiryDate,
+		Quantity:   idea.Quantity,
+		Premium:    idea.Premium,
+		Notes:      idea.Notes,
+	}
+	a.showAddOptionForm(&prefill, func() {
+		if err := a.db.DeleteTradeIdea(context.Background(), idea.ID); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error removing the booked trade idea: %v", err))
+		}
+		a.pages.RemovePage("ideas")
+	})
+}
+
+// acceptCSPIdea queues the CSP advisor's target put for the selected ticker
+// at its current mid
+func (a *App) acceptCSPIdea() {
+	row, _ := a.cspView.table.GetSelection()
+	if row < 1 || row > len(a.cspView.watchlist) {
+		return
+	}
+	ticker := a.cspView.watchlist[row-1].Ticker
+	info, ok := a.cspView.contracts[ticker]
+	a.cspView.statusBar.Clear()
+	if !ok || info.Strike <= 0 {
+		fmt.Fprintf(a.cspView.statusBar, "[yellow]%s has no target put to queue; refresh with r", ticker)
+		return
+	}
+	strike := decimal.NewFromFloat(info.Strike)
+	premium := decimal.NewFromFloat(info.Premium).Round(2)
+	expiry := a.book.locale.FormatDate(info.Expiry)
+	notes := fmt.Sprintf("CSP advisor, score %.0f", a.cspView.scores[ticker].CompositeScore)
+	if err := a.db.AddTradeIdea(context.Background(), ticker, "PUT", strike, info.Expiry, 1, premium, notes); err != nil {
+		fmt.Fprintf(a.cspView.statusBar, "[red]Error queuing trade idea (run schema_ideas.sql): %v", err)
+		return
+	}
+	fmt.Fprintf(a.cspView.statusBar, "[green]Queued: sell %s $%s put %s at $%s. Book it from the trade ideas (u) once filled",
+		ticker, a.book.formatPrice(strike), expiry, a.book.formatPrice(premium))
+}
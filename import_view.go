@@ -0,0 +1,524 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/broker"
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/importer"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// showPasteImportForm accepts text copied from a broker's positions page, to
+// import it or reconcile the holdings against it
+func (a *App) showPasteImportForm() {
+	textArea := tview.NewTextArea().
+		SetPlaceholder("Paste your broker's positions table here (ticker, quantity, cost)...")
+	textArea.SetTextStyle(tcell.StyleDefault.Background(tcell.ColorDarkSlateGray).Foreground(tcell.ColorWhite))
+	textArea.SetPlaceholderStyle(tcell.StyleDefault.Background(tcell.ColorDarkSlateGray).Foreground(tcell.ColorGray))
+
+	buttons := tview.NewForm()
+	styleForm(buttons)
+	buttons.AddButton("Preview", func() {
+		positions, warnings := importer.ParsePositions(textArea.GetText())
+		if len(positions) == 0 {
+			a.statusBar.SetText(" [red]No positions found in pasted text")
+			return
+		}
+		a.showPasteImportPreview(positions, warnings)
+	})
+	buttons.AddButton("Reconcile", func() {
+		positions, warnings := importer.ParsePositions(textArea.GetText())
+		if len(positions) == 0 {
+			a.statusBar.SetText(" [red]No positions found in pasted text")
+			return
+		}
+		a.showReconcileReport(positions, warnings)
+	})
+	buttons.AddButton("Cancel", func() {
+		a.pages.RemovePage("paste")
+	})
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(textArea, 0, 1, true).
+		AddItem(buttons, 3, 0, false)
+	layout.SetBorder(true).
+		SetTitle(" Import Positions (Tab: buttons) ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal).
+		SetTitleColor(tcell.ColorTeal)
+
+	textArea.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			a.app.SetFocus(buttons)
+			return nil
+		}
+		return event
+	})
+
+	a.createModalPage("paste", layout, 90, 24)
+}
+
+// showPasteImportPreview lists parsed positions and imports them on confirmation
+func (a *App) showPasteImportPreview(positions []importer.Position, warnings []string) {
+	existing := make(map[string]db.Holding)
+	for _, h := range a.book.holdings {
+		existing[h.Ticker] = h
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, " [teal]%-8s %12s %12s  %s[white]\n", "TICKER", "QTY", "AVG COST", "ACTION")
+	for _, p := range positions {
+		_, held := existing[p.Ticker]
+		action := "[lime]new"
+		if held {
+			action = "[yellow]replace"
+		}
+		cost := "$" + a.book.formatPrice(p.AvgCost)
+		if p.AvgCost.IsZero() {
+			cost = "[gray]-[white]"
+			if held {
+				action += " qty only"
+			}
+		}
+		fmt.Fprintf(&sb, " %-8s %12s %12s  %s[white]\n", p.Ticker, p.Quantity.String(), cost, action)
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(&sb, " [gray]skipped %s[white]\n", w)
+	}
+	sb.WriteString("\n [gray]Existing holdings are replaced with the pasted quantity and cost. Cash is not adjusted.[white]")
+
+	preview := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(sb.String())
+	preview.SetBackgroundColor(tcell.ColorBlack)
+
+	buttons := tview.NewForm()
+	styleForm(buttons)
+	buttons.AddButton("Import", func() {
+		ctx := context.Background()
+		imported := 0
+		for _, p := range positions {
+			cost := p.AvgCost
+			if cost.IsZero() {
+				cost = existing[p.Ticker].AvgCost
+			}
+			if err := a.db.SetHoldingPosition(ctx, p.Ticker, p.Quantity, cost, time.Now()); err != nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Error importing %s: %v", p.Ticker, err))
+				break
+			}
+			imported++
+		}
+		a.pages.RemovePage("paste-preview")
+		a.pages.RemovePage("paste")
+		a.refreshData()
+		if imported == len(positions) {
+			a.statusBar.SetText(fmt.Sprintf(" [lime]Imported %d position(s)", imported))
+		}
+	})
+	buttons.AddButton("Back", func() {
+		a.pages.RemovePage("paste-preview")
+	})
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(preview, 0, 1, false).
+		AddItem(buttons, 3, 0, true)
+	layout.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Preview: %d position(s) ", len(positions))).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal).
+		SetTitleColor(tcell.ColorTeal)
+
+	height := min(len(positions)+len(warnings)+9, 30)
+	a.createModalPage("paste-preview", layout, 70, height)
+}
+
+// reconcilePositions diffs the holdings against pasted broker positions
+func (a *App) reconcilePositions(positions []importer.Position) []broker.Discrepancy {
+	recorded := make([]broker.Recorded, len(a.book.holdings))
+	for i, h := range a.book.holdings {
+		recorded[i] = broker.Recorded{Ticker: h.Ticker, Quantity: h.Quantity, AvgCost: h.AvgCost}
+	}
+	reported := make([]broker.Position, len(positions))
+	for i, p := range positions {
+		reported[i] = broker.Position{Ticker: p.Ticker, Quantity: p.Quantity}
+		if !p.AvgCost.IsZero() {
+			reported[i].CostBasis = decimal.NewNullDecimal(p.AvgCost.Mul(p.Quantity))
+		}
+	}
+	return broker.ReconcilePositions(recorded, reported)
+}
+
+// showReconcileReport lists where the holdings differ from the pasted broker
+// positions, with the suggested corrections, and applies them on confirmation.
+// Holdings missing from the paste are only removed when the paste is
+// confirmed as the full statement, so a partial one (a single account page,
+// say) leaves the rest alone.
+func (a *App) showReconcileReport(positions []importer.Position, warnings []string) {
+	discrepancies := a.reconcilePositions(positions)
+	var fixes, removals []broker.Correction
+
+	var sb strings.Builder
+	if len(discrepancies) == 0 {
+		fmt.Fprintf(&sb, " [lime]All %d position(s) match the records[white]\n", len(positions))
+	} else {
+		fmt.Fprintf(&sb, " [teal]%-40s  %s[white]\n", "DISCREPANCY", "SUGGESTED CORRECTION")
+	}
+	for _, disc := range discrepancies {
+		fix := "[gray]add manually, no cost reported[white]"
+		if disc.Fix != nil {
+			fix = "[yellow]" + disc.Fix.String() + "[white]"
+			if disc.Fix.Remove {
+				fix = "[red]" + disc.Fix.String() + " (full statement only)[white]"
+				removals = append(removals, *disc.Fix)
+			} else {
+				fixes = append(fixes, *disc.Fix)
+			}
+		}
+		fmt.Fprintf(&sb, " %-40s  %s\n", disc.Message, fix)
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(&sb, " [gray]skipped %s[white]\n", w)
+	}
+	if len(fixes) > 0 {
+		sb.WriteString("\n [gray]Applying sets the holdings to match the broker. Cash is not adjusted.[white]")
+	}
+	if len(removals) > 0 {
+		sb.WriteString("\n [gray]Only if the paste is the full statement, Apply full statement also removes the holdings missing from it.[white]")
+	}
+
+	report := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(sb.String())
+	report.SetBackgroundColor(tcell.ColorBlack)
+
+	buttons := tview.NewForm()
+	styleForm(buttons)
+	apply := func(fixes []broker.Correction) {
+		applied, err := a.applyCorrections(fixes)
+		a.pages.RemovePage("reconcile")
+		a.pages.RemovePage("paste")
+		a.refreshData()
+		if err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error correcting holdings: %v", err))
+			return
+		}
+		a.statusBar.SetText(fmt.Sprintf(" [lime]Applied %d correction(s)", applied))
+	}
+	if len(fixes) > 0 {
+		buttons.AddButton("Apply corrections", func() {
+			apply(fixes)
+		})
+	}
+	if len(removals) > 0 {
+		buttons.AddButton("Apply full statement", func() {
+			apply(append(slices.Clip(fixes), removals...))
+		})
+	}
+	buttons.AddButton("Back", func() {
+		a.pages.RemovePage("reconcile")
+	})
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(report, 0, 1, false).
+		AddItem(buttons, 3, 0, true)
+	layout.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Reconciliation: %d discrepancy(ies) ", len(discrepancies))).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal).
+		SetTitleColor(tcell.ColorTeal)
+
+	height := min(len(discrepancies)+len(warnings)+10, 30)
+	a.createModalPage("reconcile", layout, 100, height)
+}
+
+// applyCorrections brings the holdings in line with the broker, stopping at
+// the first error. It returns how many corrections were applied.
+func (a *App) applyCorrections(fixes []broker.Correction) (int, error) {
+	ctx := context.Background()
+	ids := make(map[string]string)
+	for _, h := range a.book.holdings {
+		ids[h.Ticker] = h.ID
+	}
+	for i, fix := range fixes {
+		var err error
+		if fix.Remove {
+			err = a.db.DeleteHolding(ctx, ids[fix.Ticker])
+		} else {
+			err = a.db.SetHoldingPosition(ctx, fix.Ticker, fix.Quantity, fix.AvgCost, a.now())
+		}
+		if err != nil {
+			return i, fmt.Errorf("%s: %w", fix.Ticker, err)
+		}
+	}
+	return len(fixes), nil
+}
+
+// showOptionHistoryImport imports past option trades from a CSV file
+func (a *App) showOptionHistoryImport() {
+	form := tview.NewForm().
+		AddInputField("File", "option-history.csv", 40, nil, nil)
+	styleForm(form)
+
+	form.AddButton("Import", func() {
+		path := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		f, err := os.Open(path)
+		if err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Import failed: %v", err))
+			return
+		}
+		trades, warnings, err := importer.ParseOptionTrades(f)
+		f.Close()
+		if err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Import failed: %v", err))
+			return
+		}
+		for _, w := range warnings {
+			slog.Warn("skipped option trade", "file", path, "reason", w)
+		}
+
+		added, duplicates, err := a.importOptionTrades(context.Background(), trades)
+		a.pages.RemovePage("option-history")
+		a.refreshData()
+		if err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Imported %d trade(s), then failed: %v", added, err))
+			return
+		}
+		msg := fmt.Sprintf(" [lime]Imported %d past option trade(s)", added)
+		if duplicates > 0 {
+			msg += fmt.Sprintf(", %d already recorded", duplicates)
+		}
+		if len(warnings) > 0 {
+			msg += fmt.Sprintf(" [yellow]%d line(s) skipped: %s (see logs)", len(warnings), warnings[0])
+		}
+		a.statusBar.SetText(msg)
+	})
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("option-history")
+	})
+
+	form.SetBorder(true).SetTitle(" Import Option History (CSV) ").SetTitleAlign(tview.AlignLeft)
+	a.createModalPage("option-history", form, 60, 7)
+}
+
+// importOptionTrades records past trades as settled options, in the currency
+// given or else the ticker's, skipping any already recorded with the same
+// contract, size, and open date, so a file can be imported again after
+// adding to it.
+func (a *App) importOptionTrades(ctx context.Context, trades []importer.OptionTrade) (added, duplicates int, err error) {
+	key := func(ticker, optionType, action string, strike decimal.Decimal, expiry time.Time, quantity int, opened time.Time) string {
+		return fmt.Sprintf("%s|%s|%s|%s|%s|%d|%s", ticker, optionType, action, strike.String(),
+			expiry.Format("2006-01-02"), quantity, opened.UTC().Format("2006-01-02"))
+	}
+	existing := make(map[string]bool)
+	for _, o := range a.book.options {
+		existing[key(o.Ticker, o.OptionType, o.Action, o.Strike, o.ExpiryDate, o.Quantity, o.CreatedAt)] = true
+	}
+
+	for _, t := range trades {
+		k := key(t.Ticker, t.OptionType, t.Action, t.Strike, t.Expiry, t.Quantity, t.Opened)
+		if existing[k] {
+			duplicates++
+			continue
+		}
+		notes := t.Notes
+		if notes == "" {
+			notes = "Imported history"
+		}
+		currency := t.Currency
+		if currency == "" {
+			currency = a.book.tickerCurrency(t.Ticker)
+		}
+		err := a.db.AddHistoricalOption(ctx, db.Option{
+			Ticker: t.Ticker, OptionType: t.OptionType, Action: t.Action, Strike: t.Strike,
+			ExpiryDate: t.Expiry, Quantity: t.Quantity, Multiplier: t.Multiplier, Settlement: db.SettlementPhysical,
+			Currency: currency, Premium: t.Premium, OpenFee: t.OpenFee, ClosePremium: t.ClosePremium,
+			CloseFee: decimal.NewNullDecimal(t.CloseFee), Status: t.Outcome, Notes: notes,
+			CreatedAt: t.Opened, UpdatedAt: t.Closed,
+		})
+		if err != nil {
+			return added, duplicates, fmt.Errorf("line %d: %w", t.Line, err)
+		}
+		existing[k] = true
+		added++
+	}
+	return added, duplicates, nil
+}
+
+// showHoldingsImport reads holdings from a CSV file and checks their tickers
+// on Yahoo before previewing them
+func (a *App) showHoldingsImport() {
+	form := tview.NewForm().
+		AddInputField("File", "holdings.csv", 40, nil, nil)
+	styleForm(form)
+
+	form.AddButton("Preview", func() {
+		path := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		f, err := os.Open(path)
+		if err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Import failed: %v", err))
+			return
+		}
+		rows, warnings, err := importer.ParseHoldings(f)
+		f.Close()
+		if err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Import failed: %v", err))
+			return
+		}
+		if len(rows) == 0 {
+			a.statusBar.SetText(" [red]No holdings found in " + tview.Escape(path))
+			return
+		}
+
+		a.statusBar.SetText(fmt.Sprintf(" [yellow]Checking %d row(s) against Yahoo...", len(rows)))
+		a.goSafe("holdings import", func() {
+			unknown, err := a.unknownTickers(rows)
+			a.queueUpdateDraw(func() {
+				if err != nil {
+					a.statusBar.SetText(fmt.Sprintf(" [red]Import stopped, tickers not checked: %v", err))
+					return
+				}
+				a.showHoldingsImportPreview(rows, warnings, unknown)
+			})
+		})
+	})
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("holdings-import")
+	})
+
+	form.SetBorder(true).SetTitle(" Import Holdings (CSV) ").SetTitleAlign(tview.AlignLeft)
+	a.createModalPage("holdings-import", form, 60, 7)
+}
+
+// unknownTickers returns the tickers of rows Yahoo has no quote for. When
+// none can be quoted Yahoo is taken to be unreachable, as in a refresh, and
+// an error is returned rather than every ticker marked unknown. It only
+// touches the network, so it may run off the event loop.
+func (a *App) unknownTickers(rows []importer.HoldingRow) (map[string]bool, error) {
+	var tickers []string
+	for _, r := range rows {
+		if !slices.Contains(tickers, r.Ticker) {
+			tickers = append(tickers, r.Ticker)
+		}
+	}
+	quotes, err := a.yahoo.GetQuotes(tickers)
+	if err != nil {
+		return nil, err
+	}
+	if len(quotes) == 0 {
+		return nil, errors.New("no ticker could be quoted; Yahoo may be unreachable")
+	}
+	unknown := make(map[string]bool)
+	for _, t := range tickers {
+		if _, ok := quotes[t]; !ok {
+			unknown[t] = true
+		}
+	}
+	return unknown, nil
+}
+
+// showHoldingsImportPreview lists the rows read from a CSV file and adds the
+// ones with known tickers on confirmation, all at once or not at all
+func (a *App) showHoldingsImportPreview(rows []importer.HoldingRow, warnings []string, unknown map[string]bool) {
+	held := make(map[string]bool)
+	for _, h := range a.book.holdings {
+		held[h.Ticker] = true
+	}
+
+	table := tview.NewTable().
+		SetBorders(false).
+		SetFixed(1, 0)
+	for i, h := range []string{"LINE", "TICKER", "QTY", "AVG COST", "DATE", "ACTION"} {
+		table.SetCell(0, i, tview.NewTableCell(" "+h+" ").
+			SetTextColor(tcell.ColorBlack).
+			SetBackgroundColor(tcell.ColorTeal).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+	var holdings []db.Holding
+	for i, r := range rows {
+		action, color := "new", tcell.ColorLime
+		if unknown[r.Ticker] {
+			action, color = "skip: unknown ticker", tcell.ColorRed
+		} else if held[r.Ticker] {
+			action, color = "add to held", tcell.ColorYellow
+		}
+		date := "today"
+		entry := r.EntryDate
+		if entry.IsZero() {
+			entry = a.now()
+		} else {
+			date = a.book.locale.FormatDate(r.EntryDate)
+		}
+		for col, text := range []string{fmt.Sprint(r.Line), r.Ticker, a.book.formatShares(r.Quantity), "$" + a.book.formatPrice(r.AvgCost), date, action} {
+			cell := tview.NewTableCell(" " + tview.Escape(text) + " ").SetExpansion(1)
+			if col == 5 {
+				cell.SetTextColor(color)
+			}
+			table.SetCell(i+1, col, cell)
+		}
+		if !unknown[r.Ticker] {
+			holdings = append(holdings, db.Holding{Ticker: r.Ticker, Quantity: r.Quantity, AvgCost: r.AvgCost, EntryDate: entry, Notes: r.Notes})
+		}
+	}
+
+	var sb strings.Builder
+	for _, w := range warnings {
+		fmt.Fprintf(&sb, " [gray]skipped %s[white]\n", tview.Escape(w))
+	}
+	sb.WriteString(" [gray]Held tickers are added to at the average cost. Cash is not adjusted.[white]")
+	notes := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(sb.String())
+	notes.SetBackgroundColor(tcell.ColorBlack)
+
+	buttons := tview.NewForm()
+	styleForm(buttons)
+	if len(holdings) > 0 {
+		buttons.AddButton(fmt.Sprintf("Import %d", len(holdings)), func() {
+			err := a.db.BulkAddHoldings(context.Background(), holdings)
+			if err != nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Import failed, nothing was added: %v", err))
+				return
+			}
+			a.pages.RemovePage("holdings-preview")
+			a.pages.RemovePage("holdings-import")
+			a.refreshData()
+			msg := fmt.Sprintf(" [lime]Imported %d holding(s)", len(holdings))
+			if skipped := len(rows) - len(holdings) + len(warnings); skipped > 0 {
+				msg += fmt.Sprintf(" [yellow]%d row(s) skipped", skipped)
+			}
+			a.statusBar.SetText(msg)
+		})
+	}
+	buttons.AddButton("Back", func() {
+		a.pages.RemovePage("holdings-preview")
+	})
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, false).
+		AddItem(notes, len(warnings)+1, 0, false).
+		AddItem(buttons, 3, 0, true)
+	layout.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Preview: %d of %d row(s) to import ", len(holdings), len(rows))).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal).
+		SetTitleColor(tcell.ColorTeal)
+
+	height := min(len(rows)+len(warnings)+8, 30)
+	a.createModalPage("holdings-preview", layout, 90, height)
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/db"
+
+	"github.com/shopspring/decimal"
+)
+
+// toggleIncomeLine shows or hides the income line under the portfolio
+// summary, fetching the dividend histories it needs the first time
+func (a *App) toggleIncomeLine() {
+	a.summaryView.showIncome = !a.summaryView.showIncome
+	if a.summaryView.showIncome {
+		a.loadDividendHistories()
+	}
+	a.holdingsView.update()
+	a.updateLayout()
+}
+
+// summaryHeight is the height of the portfolio summary, one line more each
+// with holdings for the dividends line, with the income line, and with
+// accounts
+func (a *App) summaryHeight() int {
+	height := 3
+	if len(a.book.holdings) > 0 {
+		height++
+	}
+	if a.summaryView.showIncome {
+		height++
+	}
+	if len(a.book.accounts) > 0 {
+		height++
+	}
+	return height
+}
+
+// loadDividendHistories fetches, in the background, the dividend histories
+// of held tickers not fetched yet this session
+func (a *App) loadDividendHistories() {
+	var missing []string
+	for _, h := range a.book.holdings {
+		if _, ok := a.dividendHistory[h.Ticker]; !ok {
+			missing = append(missing, h.Ticker)
+		}
+	}
+	if len(missing) == 0 || a.dividendsBusy {
+		return
+	}
+	a.dividendsBusy = true
+	a.goSafe("dividend histories", func() {
+		histories := a.fetchDividendHistories(missing)
+		a.queueUpdateDraw(func() {
+			a.dividendsBusy = false
+			a.setDividendHistories(histories)
+			a.holdingsView.update()
+		})
+	})
+}
+
+// fetchDividendHistories fetches the dividend histories of tickers. Tickers
+// that fail are left out, to be tried again next time.
+func (a *App) fetchDividendHistories(tickers []string) map[string][]analytics.Dividend {
+	histories := make(map[string][]analytics.Dividend, len(tickers))
+	for _, ticker := range tickers {
+		history, err := a.yahoo.FetchDividends(ticker)
+		if err != nil {
+			slog.Debug("fetching dividends for income line", "ticker", ticker, "err", err)
+			continue
+		}
+		histories[ticker] = history
+	}
+	return histories
+}
+
+// setDividendHistories adds fetched dividend histories to the session cache
+func (a *App) setDividendHistories(histories map[string][]analytics.Dividend) {
+	if a.dividendHistory == nil {
+		a.dividendHistory = make(map[string][]analytics.Dividend)
+	}
+	for ticker, history := range histories {
+		a.dividendHistory[ticker] = history
+	}
+}
+
+// ytdDividends estimates the dividends earned this tax year: each holding's
+// distributions with an ex-date so far this year, on the shares held now.
+// ok is false until every holding's history has been fetched.
+func (a *App) ytdDividends() (decimal.Decimal, bool) {
+	now := a.now()
+	yearStart, _ := a.book.taxYear.Bounds(now)
+	total := 0.0
+	ok := true
+	for _, h := range a.book.holdings {
+		history, fetched := a.dividendHistory[h.Ticker]
+		if !fetched {
+			ok = false
+			continue
+		}
+		for _, d := range history {
+			if !d.ExDate.Before(yearStart) && !d.ExDate.After(now) {
+				total += d.Amount * h.Quantity.InexactFloat64()
+			}
+		}
+	}
+	return decimal.NewFromFloat(total), ok
+}
+
+// realizedOptionPL is the profit locked in this tax year by options that
+// are no longer open: premium less fees and the cost of closing, counted when
+// the option was closed, or at expiry if it expired or was assigned
+func (a *App) realizedOptionPL() decimal.Decimal {
+	now := a.now()
+	yearStart, yearEnd := a.book.taxYear.Bounds(now)
+	var total decimal.Decimal
+	for _, o := range a.book.options {
+		if o.Status == "ACTIVE" {
+			continue
+		}
+		realizedAt := o.ExpiryDate
+		if o.Status == "CLOSED" {
+			realizedAt = o.UpdatedAt
+		}
+		if realizedAt.Before(yearStart) || !realizedAt.Before(yearEnd) {
+			continue
+		}
+		total = total.Add(realizedPL(o))
+	}
+	return total
+}
+
+// loadSales reads the shares sold this tax year
+func (a *App) loadSales(ctx context.Context) {
+	yearStart, _ := a.book.taxYear.Bounds(a.now())
+	sales, err := a.db.GetSalesSince(ctx, yearStart)
+	if err != nil {
+		slog.Debug("loading stock sales", "err", err)
+	}
+	a.sales = sales
+}
+
+// realizedStockPL is the profit over average cost of the shares sold this
+// tax year, in the base currency
+func (a *App) realizedStockPL() decimal.Decimal {
+	var total decimal.Decimal
+	for _, sale := range a.sales {
+		total = total.Add(a.book.toBase(sale.RealizedPL(), sale.Currency))
+	}
+	return total
+}
+
+// realizedPL is a closed-out option's profit: the premium taken in (or paid)
+// less what closing or cash-settling it cost (or brought in) and fees
+func realizedPL(o db.Option) decimal.Decimal {
+	pl := optionPremium(o).Sub(o.OpenFee)
+	if o.CloseFee.Valid {
+		pl = pl.Sub(o.CloseFee.Decimal)
+	}
+	if (o.Status == "CLOSED" || o.Status == "ASSIGNED") && o.ClosePremium.Valid {
+		closing := o.ClosePremium.Decimal.Mul(o.Shares())
+		if o.Action == "BUY" {
+			closing = closing.Neg()
+		}
+		pl = pl.Sub(closing)
+	}
+	return pl
+}
+
+// incomeLine is the summary's second line: this tax year's net option
+// premium, dividends, realized option and stock P/L, and the income yield on the
+// portfolio's value at that pace over a year
+func (a *App) incomeLine(portfolioValue decimal.Decimal) string {
+	now := a.now()
+	yearStart, _ := a.book.taxYear.Bounds(now)
+	premium := a.book.premiums.NetPL
+	dividends, complete := a.ytdDividends()
+
+	dividendText := "[gray]loading[white]"
+	if complete {
+		dividendText = "[lime]$" + a.book.locale.FormatFixed(dividends, 2) + "[white]"
+	}
+	text := fmt.Sprintf(" [teal]%s:[white] Premium: %s  |  Dividends: %s  |  Realized: %s",
+		a.book.taxYear.Label(yearStart),
+		a.book.moneyText(premium), dividendText, a.book.moneyText(a.realizedOptionPL().Add(a.realizedStockPL())))
+
+	days := now.Sub(yearStart).Hours() / 24
+	if days < 1 {
+		days = 1
+	}
+	if complete && portfolioValue.IsPositive() {
+		yield := premium.Add(dividends).Div(portfolioValue).Mul(decimal.NewFromFloat(365 / days * 100))
+		text += fmt.Sprintf("  |  Income yield: [yellow]%s%%[white] ann.", a.book.locale.FormatFixed(yield, 2))
+	}
+	return text
+}
+
+// moneyText formats an amount in green, or red if negative
+func (b *book) moneyText(d decimal.Decimal) string {
+	if d.IsNegative() {
+		return "[red]-$" + b.locale.FormatFixed(d.Abs(), 2) + "[white]"
+	}
+	return "[lime]$" + b.locale.FormatFixed(d, 2) + "[white]"
+}
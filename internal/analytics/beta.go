@@ -0,0 +1,197 @@
+package analytics
+
+import (
+	"math"
+	"time"
+)
+
+// MinBetaObservations is the minimum number of paired returns needed for a beta estimate.
+const MinBetaObservations = 20
+
+// RollingBetaWindow is the default lookback (trading days) for rolling beta.
+const RollingBetaWindow = 60
+
+// PricePoint is a single daily close with its Unix timestamp.
+type PricePoint struct {
+	Time  int64
+	Close float64
+}
+
+// DailyBar is one day's open, high, low, and close, with the Unix timestamp
+// of the session.
+type DailyBar struct {
+	Time                   int64
+	Open, High, Low, Close float64
+}
+
+// Point is the bar's close as a PricePoint
+func (b DailyBar) Point() PricePoint {
+	return PricePoint{Time: b.Time, Close: b.Close}
+}
+
+// WeeklyCloses keeps the last close of each week (ISO weeks, UTC) of a
+// daily series, oldest first.
+func WeeklyCloses(series []PricePoint) []PricePoint {
+	var weekly []PricePoint
+	lastYear, lastWeek := 0, 0
+	for _, p := range series {
+		year, week := time.Unix(p.Time, 0).UTC().ISOWeek()
+		if len(weekly) > 0 && year == lastYear && week == lastWeek {
+			weekly[len(weekly)-1] = p
+			continue
+		}
+		weekly = append(weekly, p)
+		lastYear, lastWeek = year, week
+	}
+	return weekly
+}
+
+// AlignSeries matches price series by calendar day (UTC) and returns, for
+// each input series, the closes on days present in every series, oldest first.
+func AlignSeries(series ...[]PricePoint) [][]float64 {
+	if len(series) == 0 {
+		return nil
+	}
+
+	byDay := make([]map[string]float64, len(series))
+	for i, s := range series {
+		byDay[i] = make(map[string]float64, len(s))
+		for _, p := range s {
+			byDay[i][dayKey(p.Time)] = p.Close
+		}
+	}
+
+	out := make([][]float64, len(series))
+	for _, p := range series[0] {
+		key := dayKey(p.Time)
+		closes := make([]float64, len(series))
+		present := true
+		for i := range series {
+			close, ok := byDay[i][key]
+			if !ok {
+				present = false
+				break
+			}
+			closes[i] = close
+		}
+		if !present {
+			continue
+		}
+		for i, c := range closes {
+			out[i] = append(out[i], c)
+		}
+	}
+	return out
+}
+
+// Returns computes simple daily returns from closing prices (oldest first).
+// Pairs with a non-positive previous close are returned as 0.
+func Returns(closes []float64) []float64 {
+	if len(closes) < 2 {
+		return nil
+	}
+	out := make([]float64, len(closes)-1)
+	for i := 1; i < len(closes); i++ {
+		if closes[i-1] > 0 {
+			out[i-1] = closes[i]/closes[i-1] - 1
+		}
+	}
+	return out
+}
+
+// Beta computes the OLS slope of asset returns regressed on market returns.
+// Returns NaN if the series differ in length, are shorter than
+// MinBetaObservations, or the market has zero variance.
+func Beta(asset, market []float64) float64 {
+	n := len(asset)
+	if n != len(market) || n < MinBetaObservations {
+		return math.NaN()
+	}
+
+	var meanA, meanM float64
+	for i := 0; i < n; i++ {
+		meanA += asset[i]
+		meanM += market[i]
+	}
+	meanA /= float64(n)
+	meanM /= float64(n)
+
+	var cov, varM float64
+	for i := 0; i < n; i++ {
+		dm := market[i] - meanM
+		cov += (asset[i] - meanA) * dm
+		varM += dm * dm
+	}
+	if varM == 0 {
+		return math.NaN()
+	}
+	return cov / varM
+}
+
+// RollingBeta computes beta over a sliding window of returns. Element i is the
+// beta of the window ending at return index i+window-1.
+func RollingBeta(asset, market []float64, window int) []float64 {
+	if len(asset) != len(market) || window < MinBetaObservations || len(asset) < window {
+		return nil
+	}
+	out := make([]float64, 0, len(asset)-window+1)
+	for end := window; end <= len(asset); end++ {
+		out = append(out, Beta(asset[end-window:end], market[end-window:end]))
+	}
+	return out
+}
+
+// WeightedReturns combines per-asset return series into one portfolio series
+// using fixed weights. Series must be the same length; weights need not sum to 1.
+func WeightedReturns(series [][]float64, weights []float64) []float64 {
+	if len(series) == 0 || len(series) != len(weights) {
+		return nil
+	}
+	n := len(series[0])
+	for _, s := range series {
+		if len(s) != n {
+			return nil
+		}
+	}
+
+	totalWeight := 0.0
+	for _, w := range weights {
+		totalWeight += w
+	}
+	if totalWeight == 0 {
+		return nil
+	}
+
+	out := make([]float64, n)
+	for i, s := range series {
+		w := weights[i] / totalWeight
+		for j, r := range s {
+			out[j] += w * r
+		}
+	}
+	return out
+}
+
+// PortfolioBeta returns the value-weighted average of per-position betas.
+// Positions with a NaN beta are excluded and the remainder re-weighted.
+func PortfolioBeta(betas, values []float64) float64 {
+	if len(betas) != len(values) {
+		return math.NaN()
+	}
+	var weighted, total float64
+	for i, b := range betas {
+		if math.IsNaN(b) || values[i] <= 0 {
+			continue
+		}
+		weighted += b * values[i]
+		total += values[i]
+	}
+	if total == 0 {
+		return math.NaN()
+	}
+	return weighted / total
+}
+
+func dayKey(unix int64) string {
+	return time.Unix(unix, 0).UTC().Format("2006-01-02")
+}
@@ -0,0 +1,151 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+)
+
+const epsilon = 0.0001
+
+func approxEqual(a, b float64) bool {
+	if math.IsNaN(a) && math.IsNaN(b) {
+		return true
+	}
+	return math.Abs(a-b) < epsilon
+}
+
+func TestAlignSeries(t *testing.T) {
+	day := int64(86400)
+	a := []PricePoint{{0, 10}, {day, 11}, {2 * day, 12}, {3 * day, 13}}
+	b := []PricePoint{{day, 21}, {3 * day, 23}, {4 * day, 24}}
+
+	aligned := AlignSeries(a, b)
+	if len(aligned) != 2 {
+		t.Fatalf("got %d series, want 2", len(aligned))
+	}
+	if len(aligned[0]) != 2 || aligned[0][0] != 11 || aligned[0][1] != 13 {
+		t.Errorf("aligned a = %v, want [11 13]", aligned[0])
+	}
+	if len(aligned[1]) != 2 || aligned[1][0] != 21 || aligned[1][1] != 23 {
+		t.Errorf("aligned b = %v, want [21 23]", aligned[1])
+	}
+}
+
+func TestWeeklyCloses(t *testing.T) {
+	// Mon 2026-03-02 to Tue 2026-03-10: the Friday closes one week, the
+	// Tuesday the week under way
+	day := func(d int) int64 { return int64(1772409600 + (d-2)*86400) }
+	series := []PricePoint{
+		{Time: day(2), Close: 10}, {Time: day(3), Close: 11}, {Time: day(6), Close: 12},
+		{Time: day(9), Close: 13}, {Time: day(10), Close: 14},
+	}
+	got := WeeklyCloses(series)
+	if len(got) != 2 || got[0].Close != 12 || got[1].Close != 14 {
+		t.Errorf("WeeklyCloses = %v, want closes 12 and 14", got)
+	}
+}
+
+func TestReturns(t *testing.T) {
+	got := Returns([]float64{100, 110, 99})
+	if len(got) != 2 {
+		t.Fatalf("got %d returns, want 2", len(got))
+	}
+	if !approxEqual(got[0], 0.10) || !approxEqual(got[1], -0.10) {
+		t.Errorf("Returns = %v, want [0.10 -0.10]", got)
+	}
+	if Returns([]float64{100}) != nil {
+		t.Error("expected nil for single close")
+	}
+}
+
+func TestBeta(t *testing.T) {
+	market := makeMarketReturns(40)
+
+	tests := []struct {
+		name  string
+		scale float64
+		want  float64
+	}{
+		{"same as market", 1, 1},
+		{"leveraged", 2, 2},
+		{"inverse", -0.5, -0.5},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			asset := make([]float64, len(market))
+			for i, r := range market {
+				asset[i] = tc.scale*r + 0.001
+			}
+			got := Beta(asset, market)
+			if !approxEqual(got, tc.want) {
+				t.Errorf("Beta = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBetaInsufficientData(t *testing.T) {
+	market := makeMarketReturns(MinBetaObservations - 1)
+	if got := Beta(market, market); !math.IsNaN(got) {
+		t.Errorf("Beta with too few observations = %v, want NaN", got)
+	}
+}
+
+func TestBetaZeroVariance(t *testing.T) {
+	flat := make([]float64, 30)
+	asset := makeMarketReturns(30)
+	if got := Beta(asset, flat); !math.IsNaN(got) {
+		t.Errorf("Beta against flat market = %v, want NaN", got)
+	}
+}
+
+func TestRollingBeta(t *testing.T) {
+	market := makeMarketReturns(50)
+	asset := make([]float64, len(market))
+	for i, r := range market {
+		asset[i] = 1.5 * r
+	}
+
+	rolling := RollingBeta(asset, market, 30)
+	if len(rolling) != 21 {
+		t.Fatalf("got %d rolling values, want 21", len(rolling))
+	}
+	for i, b := range rolling {
+		if !approxEqual(b, 1.5) {
+			t.Errorf("rolling[%d] = %v, want 1.5", i, b)
+		}
+	}
+
+	if RollingBeta(asset, market, 60) != nil {
+		t.Error("expected nil when window exceeds data")
+	}
+}
+
+func TestWeightedReturns(t *testing.T) {
+	got := WeightedReturns([][]float64{{0.10, 0.20}, {0.00, -0.20}}, []float64{3, 1})
+	if len(got) != 2 {
+		t.Fatalf("got %d returns, want 2", len(got))
+	}
+	if !approxEqual(got[0], 0.075) || !approxEqual(got[1], 0.10) {
+		t.Errorf("WeightedReturns = %v, want [0.075 0.10]", got)
+	}
+}
+
+func TestPortfolioBeta(t *testing.T) {
+	got := PortfolioBeta([]float64{1.0, 2.0, math.NaN()}, []float64{100, 300, 500})
+	if !approxEqual(got, 1.75) {
+		t.Errorf("PortfolioBeta = %v, want 1.75", got)
+	}
+	if got := PortfolioBeta([]float64{math.NaN()}, []float64{100}); !math.IsNaN(got) {
+		t.Errorf("PortfolioBeta all NaN = %v, want NaN", got)
+	}
+}
+
+// makeMarketReturns builds a deterministic, non-constant return series.
+func makeMarketReturns(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = 0.01 * math.Sin(float64(i))
+	}
+	return out
+}
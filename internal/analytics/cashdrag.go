@@ -0,0 +1,73 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// CashSnapshot is the recorded cash balance on a given day.
+type CashSnapshot struct {
+	Date   time.Time
+	Amount float64
+}
+
+// CashDragResult summarizes idle cash over a period.
+type CashDragResult struct {
+	Days           int     // Days covered by snapshots
+	AverageIdle    float64 // Time-weighted average non-negative cash balance
+	ForgoneIncome  float64 // Income at the risk-free rate on the average balance
+	InterestEarned float64 // Interest actually recorded over the period
+	NetDrag        float64 // ForgoneIncome - InterestEarned (floored at 0)
+}
+
+// AverageIdleCash computes the time-weighted average cash balance over
+// [from, to), carrying each snapshot forward until the next. Days before the
+// first snapshot are not counted. Negative balances count as zero idle cash.
+func AverageIdleCash(snapshots []CashSnapshot, from, to time.Time) (float64, int) {
+	if len(snapshots) == 0 {
+		return 0, 0
+	}
+
+	sorted := append([]CashSnapshot(nil), snapshots...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	start := truncateDay(from)
+	end := truncateDay(to)
+
+	idx := -1
+	days := 0
+	sum := 0.0
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		for idx+1 < len(sorted) && !truncateDay(sorted[idx+1].Date).After(day) {
+			idx++
+		}
+		if idx < 0 {
+			continue
+		}
+		sum += math.Max(0, sorted[idx].Amount)
+		days++
+	}
+	if days == 0 {
+		return 0, 0
+	}
+	return sum / float64(days), days
+}
+
+// ComputeCashDrag estimates income lost by holding idle cash instead of
+// parking it at rate (annual, e.g. 0.05), net of interest already recorded.
+func ComputeCashDrag(snapshots []CashSnapshot, interestEarned, rate float64, from, to time.Time) CashDragResult {
+	avg, days := AverageIdleCash(snapshots, from, to)
+	forgone := avg * rate * float64(days) / 365.0
+	return CashDragResult{
+		Days:           days,
+		AverageIdle:    avg,
+		ForgoneIncome:  forgone,
+		InterestEarned: interestEarned,
+		NetDrag:        math.Max(0, forgone-interestEarned),
+	}
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
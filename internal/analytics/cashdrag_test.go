@@ -0,0 +1,60 @@
+package analytics
+
+import "testing"
+
+func TestAverageIdleCash(t *testing.T) {
+	snapshots := []CashSnapshot{
+		{date(2026, 1, 6), 2000},
+		{date(2026, 1, 1), 1000},
+	}
+	// Jan 1-5 at 1000, Jan 6-10 at 2000
+	avg, days := AverageIdleCash(snapshots, date(2026, 1, 1), date(2026, 1, 11))
+	if days != 10 {
+		t.Errorf("days = %d, want 10", days)
+	}
+	if !approxEqual(avg, 1500) {
+		t.Errorf("avg = %v, want 1500", avg)
+	}
+}
+
+func TestAverageIdleCashSkipsDaysBeforeFirstSnapshot(t *testing.T) {
+	snapshots := []CashSnapshot{{date(2026, 1, 5), 1000}}
+	avg, days := AverageIdleCash(snapshots, date(2026, 1, 1), date(2026, 1, 10))
+	if days != 5 {
+		t.Errorf("days = %d, want 5", days)
+	}
+	if !approxEqual(avg, 1000) {
+		t.Errorf("avg = %v, want 1000", avg)
+	}
+}
+
+func TestAverageIdleCashNegativeBalance(t *testing.T) {
+	snapshots := []CashSnapshot{
+		{date(2026, 1, 1), -500},
+		{date(2026, 1, 3), 1000},
+	}
+	avg, _ := AverageIdleCash(snapshots, date(2026, 1, 1), date(2026, 1, 5))
+	if !approxEqual(avg, 500) {
+		t.Errorf("avg = %v, want 500", avg)
+	}
+}
+
+func TestComputeCashDrag(t *testing.T) {
+	snapshots := []CashSnapshot{{date(2025, 1, 1), 10000}}
+	got := ComputeCashDrag(snapshots, 100, 0.05, date(2025, 1, 1), date(2026, 1, 1))
+	if got.Days != 365 {
+		t.Errorf("Days = %d, want 365", got.Days)
+	}
+	if !approxEqual(got.ForgoneIncome, 500) {
+		t.Errorf("ForgoneIncome = %v, want 500", got.ForgoneIncome)
+	}
+	if !approxEqual(got.NetDrag, 400) {
+		t.Errorf("NetDrag = %v, want 400", got.NetDrag)
+	}
+
+	// Interest beyond the forgone amount floors drag at zero
+	got = ComputeCashDrag(snapshots, 900, 0.05, date(2025, 1, 1), date(2026, 1, 1))
+	if got.NetDrag != 0 {
+		t.Errorf("NetDrag = %v, want 0", got.NetDrag)
+	}
+}
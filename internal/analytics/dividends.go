@@ -0,0 +1,73 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+)
+
+// Dividend is a single historical per-share distribution keyed by ex-date.
+type Dividend struct {
+	ExDate time.Time
+	Amount float64
+}
+
+// ProjectedDividend is an expected future payment for a position.
+type ProjectedDividend struct {
+	Ticker    string
+	ExDate    time.Time
+	PerShare  float64
+	Shares    float64
+	CashTotal float64
+}
+
+// MonthTotal is the projected dividend cash for one calendar month.
+type MonthTotal struct {
+	Month time.Time // First day of the month
+	Total float64
+}
+
+// ProjectDividends repeats the trailing 12 months of distributions one year
+// forward and returns those falling in [from, from+months), sorted by ex-date.
+func ProjectDividends(ticker string, history []Dividend, shares float64, from time.Time, months int) []ProjectedDividend {
+	end := from.AddDate(0, months, 0)
+	trailingStart := from.AddDate(-1, 0, 0)
+
+	var out []ProjectedDividend
+	for _, d := range history {
+		if d.ExDate.Before(trailingStart) || !d.ExDate.Before(from) {
+			continue
+		}
+		next := d.ExDate.AddDate(1, 0, 0)
+		if next.Before(from) || !next.Before(end) {
+			continue
+		}
+		out = append(out, ProjectedDividend{
+			Ticker:    ticker,
+			ExDate:    next,
+			PerShare:  d.Amount,
+			Shares:    shares,
+			CashTotal: d.Amount * shares,
+		})
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].ExDate.Before(out[j].ExDate) })
+	return out
+}
+
+// MonthlyDividendTotals buckets projected payments into consecutive calendar
+// months starting at from's month. Months with no payments are included as zero.
+func MonthlyDividendTotals(projected []ProjectedDividend, from time.Time, months int) []MonthTotal {
+	start := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location())
+	out := make([]MonthTotal, months)
+	for i := range out {
+		out[i].Month = start.AddDate(0, i, 0)
+	}
+
+	for _, p := range projected {
+		idx := (p.ExDate.Year()-start.Year())*12 + int(p.ExDate.Month()-start.Month())
+		if idx >= 0 && idx < months {
+			out[idx].Total += p.CashTotal
+		}
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestProjectDividends(t *testing.T) {
+	history := []Dividend{
+		{date(2024, 3, 14), 0.40}, // older than trailing year, ignored
+		{date(2025, 3, 14), 0.45},
+		{date(2025, 6, 13), 0.45},
+		{date(2025, 9, 12), 0.50},
+		{date(2025, 12, 12), 0.50},
+	}
+	from := date(2026, 1, 1)
+
+	got := ProjectDividends("KO", history, 100, from, 12)
+	if len(got) != 4 {
+		t.Fatalf("got %d projected payments, want 4", len(got))
+	}
+	if !got[0].ExDate.Equal(date(2026, 3, 14)) {
+		t.Errorf("first ex-date = %v, want 2026-03-14", got[0].ExDate)
+	}
+	if !approxEqual(got[0].CashTotal, 45) {
+		t.Errorf("first cash total = %v, want 45", got[0].CashTotal)
+	}
+	if !got[3].ExDate.Equal(date(2026, 12, 12)) {
+		t.Errorf("last ex-date = %v, want 2026-12-12", got[3].ExDate)
+	}
+}
+
+func TestProjectDividendsShortHorizon(t *testing.T) {
+	history := []Dividend{
+		{date(2025, 3, 14), 0.45},
+		{date(2025, 6, 13), 0.45},
+	}
+	got := ProjectDividends("KO", history, 10, date(2026, 1, 1), 3)
+	if len(got) != 1 {
+		t.Fatalf("got %d projected payments, want 1", len(got))
+	}
+}
+
+func TestMonthlyDividendTotals(t *testing.T) {
+	projected := []ProjectedDividend{
+		{ExDate: date(2026, 1, 10), CashTotal: 10},
+		{ExDate: date(2026, 1, 20), CashTotal: 5},
+		{ExDate: date(2026, 3, 1), CashTotal: 7},
+		{ExDate: date(2027, 2, 1), CashTotal: 99}, // outside window
+	}
+	totals := MonthlyDividendTotals(projected, date(2026, 1, 15), 12)
+	if len(totals) != 12 {
+		t.Fatalf("got %d months, want 12", len(totals))
+	}
+	if !totals[0].Month.Equal(date(2026, 1, 1)) {
+		t.Errorf("first month = %v, want 2026-01-01", totals[0].Month)
+	}
+	if !approxEqual(totals[0].Total, 15) || !approxEqual(totals[1].Total, 0) || !approxEqual(totals[2].Total, 7) {
+		t.Errorf("totals = %v, want [15 0 7 ...]", totals[:3])
+	}
+}
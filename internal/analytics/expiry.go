@@ -0,0 +1,155 @@
+package analytics
+
+import "time"
+
+// OptionPosition is the subset of an option trade needed for risk summaries.
+type OptionPosition struct {
+	Ticker     string
+	OptionType string // CALL or PUT
+	Action     string // BUY or SELL
+	Strike     float64
+	Expiry     time.Time
+	Contracts  int
+	Multiplier int // Shares per contract, 0 for the standard 100
+}
+
+// sharesPerContract is a contract's multiplier, the standard 100 when unset
+func sharesPerContract(multiplier int) int {
+	if multiplier <= 0 {
+		return 100
+	}
+	return multiplier
+}
+
+// ExpiryWeekRisk summarizes the option positions expiring in one calendar week.
+type ExpiryWeekRisk struct {
+	WeekStart         time.Time // Monday
+	WeekEnd           time.Time // Following Monday (exclusive)
+	Contracts         int       // Total contracts expiring
+	CollateralAtRisk  float64   // Strike × 100 × contracts for short puts
+	SharesCallable    int       // Shares that could be called away by short calls
+	ITMContracts      int       // Contracts in the money at current prices
+	NetAssignmentCash float64   // Cash impact if every ITM contract is assigned/exercised
+}
+
+// NearestExpiryWeek finds the earliest Monday-Sunday week on or after now that
+// has an expiring position and summarizes it. Positions without a price are
+// treated as out of the money. Returns false if nothing expires on or after now.
+func NearestExpiryWeek(positions []OptionPosition, prices map[string]float64, now time.Time) (ExpiryWeekRisk, bool) {
+	today := truncateDay(now)
+
+	var nearest time.Time
+	for _, p := range positions {
+		exp := truncateDay(p.Expiry)
+		if exp.Before(today) {
+			continue
+		}
+		if nearest.IsZero() || exp.Before(nearest) {
+			nearest = exp
+		}
+	}
+	if nearest.IsZero() {
+		return ExpiryWeekRisk{}, false
+	}
+
+	// Monday of the nearest expiry's week
+	offset := (int(nearest.Weekday()) + 6) % 7
+	weekStart := nearest.AddDate(0, 0, -offset)
+	risk := ExpiryWeekRisk{
+		WeekStart: weekStart,
+		WeekEnd:   weekStart.AddDate(0, 0, 7),
+	}
+
+	for _, p := range positions {
+		exp := truncateDay(p.Expiry)
+		if exp.Before(today) || exp.Before(risk.WeekStart) || !exp.Before(risk.WeekEnd) {
+			continue
+		}
+
+		shares := p.Contracts * sharesPerContract(p.Multiplier)
+		risk.Contracts += p.Contracts
+
+		if p.Action == "SELL" {
+			if p.OptionType == "PUT" {
+				risk.CollateralAtRisk += p.Strike * float64(shares)
+			} else {
+				risk.SharesCallable += shares
+			}
+		}
+
+		price, ok := prices[p.Ticker]
+		if !ok || !isITM(p.OptionType, p.Strike, price) {
+			continue
+		}
+		risk.ITMContracts += p.Contracts
+		risk.NetAssignmentCash += assignmentCash(p)
+	}
+
+	return risk, true
+}
+
+// assignmentCash is the cash p moves if assigned or exercised at its strike
+func assignmentCash(p OptionPosition) float64 {
+	notional := p.Strike * float64(p.Contracts*sharesPerContract(p.Multiplier))
+	// Puts deliver cash to the put holder; calls deliver cash to the call writer
+	cashToWriter := -notional
+	if p.OptionType == "CALL" {
+		cashToWriter = notional
+	}
+	if p.Action == "SELL" {
+		return cashToWriter
+	}
+	return -cashToWriter
+}
+
+// CashWeek is one Monday-Sunday week of a cash forecast.
+type CashWeek struct {
+	WeekStart   time.Time // Monday
+	Assignments int       // ITM contracts expiring in the week
+	Flow        float64   // Cash moved by their assignment or exercise
+	Balance     float64   // Cash at the end of the week
+}
+
+// AssignmentCashForecast projects cash over the given number of weeks,
+// starting with now's, assuming every position in the money at current
+// prices is assigned or exercised at expiry and the rest expire worthless.
+// Positions without a price are treated as out of the money, and those
+// already expired or beyond the last week are left out.
+func AssignmentCashForecast(positions []OptionPosition, prices map[string]float64, cash float64, now time.Time, weeks int) []CashWeek {
+	today := truncateDay(now)
+	start := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	forecast := make([]CashWeek, weeks)
+	for i := range forecast {
+		forecast[i].WeekStart = start.AddDate(0, 0, 7*i)
+	}
+
+	for _, p := range positions {
+		exp := truncateDay(p.Expiry)
+		if exp.Before(today) {
+			continue
+		}
+		week := int(exp.Sub(start).Hours()/24) / 7
+		if week >= weeks {
+			continue
+		}
+		price, ok := prices[p.Ticker]
+		if !ok || !isITM(p.OptionType, p.Strike, price) {
+			continue
+		}
+		forecast[week].Assignments += p.Contracts
+		forecast[week].Flow += assignmentCash(p)
+	}
+
+	for i := range forecast {
+		cash += forecast[i].Flow
+		forecast[i].Balance = cash
+	}
+	return forecast
+}
+
+func isITM(optionType string, strike, price float64) bool {
+	if optionType == "CALL" {
+		return price > strike
+	}
+	return price < strike
+}
@@ -0,0 +1,85 @@
+package analytics
+
+import "testing"
+
+func TestNearestExpiryWeek(t *testing.T) {
+	now := date(2026, 10, 14) // Wednesday
+	positions := []OptionPosition{
+		{"AAPL", "PUT", "SELL", 200, date(2026, 10, 16), 2, 100},  // ITM short put
+		{"MSFT", "CALL", "SELL", 400, date(2026, 10, 16), 1, 100}, // ITM short call
+		{"TSLA", "PUT", "SELL", 150, date(2026, 10, 17), 1, 100},  // OTM short put
+		{"NVDA", "CALL", "BUY", 100, date(2026, 10, 16), 1, 100},  // ITM long call
+		{"AMD", "PUT", "SELL", 90, date(2026, 10, 23), 5, 100},    // next week, excluded
+		{"OLD", "PUT", "SELL", 50, date(2026, 10, 9), 1, 100},     // already expired
+	}
+	prices := map[string]float64{"AAPL": 190, "MSFT": 420, "TSLA": 160, "NVDA": 120, "AMD": 80}
+
+	risk, ok := NearestExpiryWeek(positions, prices, now)
+	if !ok {
+		t.Fatal("expected an expiry week")
+	}
+	if !risk.WeekStart.Equal(date(2026, 10, 12)) {
+		t.Errorf("WeekStart = %v, want 2026-10-12", risk.WeekStart)
+	}
+	if risk.Contracts != 5 {
+		t.Errorf("Contracts = %d, want 5", risk.Contracts)
+	}
+	if !approxEqual(risk.CollateralAtRisk, 200*200+150*100) {
+		t.Errorf("CollateralAtRisk = %v, want 55000", risk.CollateralAtRisk)
+	}
+	if risk.SharesCallable != 100 {
+		t.Errorf("SharesCallable = %d, want 100", risk.SharesCallable)
+	}
+	if risk.ITMContracts != 4 {
+		t.Errorf("ITMContracts = %d, want 4", risk.ITMContracts)
+	}
+	// -40000 (put assigned) + 40000 (called away) - 10000 (exercise long call)
+	if !approxEqual(risk.NetAssignmentCash, -10000) {
+		t.Errorf("NetAssignmentCash = %v, want -10000", risk.NetAssignmentCash)
+	}
+}
+
+func TestNearestExpiryWeekNone(t *testing.T) {
+	positions := []OptionPosition{{"OLD", "PUT", "SELL", 50, date(2026, 1, 2), 1, 100}}
+	if _, ok := NearestExpiryWeek(positions, nil, date(2026, 10, 14)); ok {
+		t.Error("expected no expiry week")
+	}
+}
+
+func TestAssignmentCashForecast(t *testing.T) {
+	now := date(2026, 10, 14) // Wednesday
+	positions := []OptionPosition{
+		{"AAPL", "PUT", "SELL", 200, date(2026, 10, 16), 2, 100},  // ITM this week
+		{"TSLA", "PUT", "SELL", 150, date(2026, 10, 23), 1, 100},  // OTM next week
+		{"MSFT", "CALL", "SELL", 400, date(2026, 10, 30), 1, 100}, // ITM in two weeks
+		{"AMD", "PUT", "SELL", 90, date(2026, 11, 6), 1, 10},      // ITM mini in three weeks
+		{"NVDA", "PUT", "SELL", 100, date(2026, 10, 30), 1, 100},  // unpriced
+		{"OLD", "PUT", "SELL", 50, date(2026, 10, 9), 1, 100},     // already expired
+		{"FAR", "PUT", "SELL", 500, date(2026, 12, 18), 1, 100},   // beyond the forecast
+	}
+	prices := map[string]float64{"AAPL": 190, "TSLA": 160, "MSFT": 420, "AMD": 80, "OLD": 10, "FAR": 100}
+
+	weeks := AssignmentCashForecast(positions, prices, 30000, now, 4)
+	if len(weeks) != 4 {
+		t.Fatalf("%d weeks, want 4", len(weeks))
+	}
+	if !weeks[0].WeekStart.Equal(date(2026, 10, 12)) || !weeks[3].WeekStart.Equal(date(2026, 11, 2)) {
+		t.Errorf("weeks start %v to %v, want Oct 12 to Nov 2", weeks[0].WeekStart, weeks[3].WeekStart)
+	}
+	want := []struct {
+		assignments int
+		flow        float64
+		balance     float64
+	}{
+		{2, -40000, -10000}, // Overdrawn by the AAPL put
+		{0, 0, -10000},
+		{1, 40000, 30000},
+		{1, -900, 29100},
+	}
+	for i, w := range want {
+		got := weeks[i]
+		if got.Assignments != w.assignments || !approxEqual(got.Flow, w.flow) || !approxEqual(got.Balance, w.balance) {
+			t.Errorf("week %d = %+v, want %+v", i, got, w)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Quarter identifies a calendar quarter.
+type Quarter struct {
+	Year int
+	Q    int // 1-4
+}
+
+// QuarterOf returns the calendar quarter containing t.
+func QuarterOf(t time.Time) Quarter {
+	return Quarter{Year: t.Year(), Q: (int(t.Month())-1)/3 + 1}
+}
+
+// Start returns midnight on the first day of the quarter in loc.
+func (q Quarter) Start(loc *time.Location) time.Time {
+	return time.Date(q.Year, time.Month((q.Q-1)*3+1), 1, 0, 0, 0, 0, loc)
+}
+
+// Next returns the following quarter.
+func (q Quarter) Next() Quarter {
+	if q.Q == 4 {
+		return Quarter{Year: q.Year + 1, Q: 1}
+	}
+	return Quarter{Year: q.Year, Q: q.Q + 1}
+}
+
+// Prev returns the preceding quarter.
+func (q Quarter) Prev() Quarter {
+	if q.Q == 1 {
+		return Quarter{Year: q.Year - 1, Q: 4}
+	}
+	return Quarter{Year: q.Year, Q: q.Q - 1}
+}
+
+func (q Quarter) String() string {
+	return fmt.Sprintf("%d Q%d", q.Year, q.Q)
+}
+
+// GrowthDecomposition splits a quarter's change in portfolio value into its sources.
+type GrowthDecomposition struct {
+	Quarter            Quarter
+	StartValue         float64
+	EndValue           float64
+	Contributions      float64
+	Dividends          float64
+	OptionIncome       float64
+	MarketAppreciation float64 // Residual: change not explained by the other components
+}
+
+// DecomposeGrowth attributes endValue-startValue to contributions, dividends,
+// option income, and (as the residual) market appreciation.
+func DecomposeGrowth(q Quarter, startValue, endValue, contributions, dividends, optionIncome float64) GrowthDecomposition {
+	return GrowthDecomposition{
+		Quarter:            q,
+		StartValue:         startValue,
+		EndValue:           endValue,
+		Contributions:      contributions,
+		Dividends:          dividends,
+		OptionIncome:       optionIncome,
+		MarketAppreciation: endValue - startValue - contributions - dividends - optionIncome,
+	}
+}
+
+// Components returns the decomposition in stacking order.
+func (g GrowthDecomposition) Components() []float64 {
+	return []float64{g.Contributions, g.Dividends, g.OptionIncome, g.MarketAppreciation}
+}
+
+// StackedBarWidths converts segment values into character widths for a bar
+// where scale units map to width characters. Widths use absolute values and
+// round to the nearest character, with any non-zero segment at least 1 wide.
+func StackedBarWidths(segments []float64, scale float64, width int) []int {
+	out := make([]int, len(segments))
+	if scale <= 0 {
+		return out
+	}
+	for i, v := range segments {
+		if v == 0 {
+			continue
+		}
+		w := int(math.Round(math.Abs(v) / scale * float64(width)))
+		if w < 1 {
+			w = 1
+		}
+		out[i] = w
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuarterOf(t *testing.T) {
+	tests := []struct {
+		in   time.Time
+		want Quarter
+	}{
+		{date(2026, 1, 1), Quarter{2026, 1}},
+		{date(2026, 3, 31), Quarter{2026, 1}},
+		{date(2026, 4, 1), Quarter{2026, 2}},
+		{date(2026, 12, 31), Quarter{2026, 4}},
+	}
+	for _, tc := range tests {
+		if got := QuarterOf(tc.in); got != tc.want {
+			t.Errorf("QuarterOf(%v) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestQuarterNavigation(t *testing.T) {
+	q := Quarter{2025, 4}
+	if got := q.Start(time.UTC); !got.Equal(date(2025, 10, 1)) {
+		t.Errorf("Start = %v, want 2025-10-01", got)
+	}
+	if got := q.Next(); got != (Quarter{2026, 1}) {
+		t.Errorf("Next = %v, want 2026 Q1", got)
+	}
+	if got := (Quarter{2026, 1}).Prev(); got != q {
+		t.Errorf("Prev = %v, want 2025 Q4", got)
+	}
+	if q.String() != "2025 Q4" {
+		t.Errorf("String = %q, want \"2025 Q4\"", q.String())
+	}
+}
+
+func TestDecomposeGrowth(t *testing.T) {
+	g := DecomposeGrowth(Quarter{2026, 1}, 10000, 12000, 1000, 50, 300)
+	if !approxEqual(g.MarketAppreciation, 650) {
+		t.Errorf("MarketAppreciation = %v, want 650", g.MarketAppreciation)
+	}
+
+	sum := 0.0
+	for _, c := range g.Components() {
+		sum += c
+	}
+	if !approxEqual(sum, g.EndValue-g.StartValue) {
+		t.Errorf("components sum = %v, want %v", sum, g.EndValue-g.StartValue)
+	}
+}
+
+func TestStackedBarWidths(t *testing.T) {
+	got := StackedBarWidths([]float64{500, -250, 0, 1}, 1000, 40)
+	want := []int{20, 10, 0, 1}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("StackedBarWidths = %v, want %v", got, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+
+	"anyhowhodl/internal/csp"
+)
+
+// Margin model parameters. Reg-T follows the standard strategy-based rules;
+// portfolio margin approximates the OCC TIMS method for equities.
+const (
+	RegTStockPct    = 0.50  // Initial requirement on long stock
+	RegTNakedPct    = 0.20  // Of the underlying for a naked short option
+	RegTMinimumPct  = 0.10  // Floor: of the strike for puts, the underlying for calls
+	PMMove          = 0.15  // Largest underlying move stressed, either way
+	PMSteps         = 10    // Equidistant scenarios across the move range
+	PMContractFloor = 37.50 // Minimum requirement per option contract
+)
+
+// MarginOption is an open option position valued for margin.
+type MarginOption struct {
+	Ticker     string
+	OptionType string // CALL or PUT
+	Action     string // BUY or SELL
+	Strike     float64
+	Contracts  int
+	Multiplier int // Shares per contract, 0 for the standard 100
+	DTE        int
+	IV         float64
+}
+
+// MarginBook is the book a margin requirement is computed for. Every ticker
+// with shares or options must have a price.
+type MarginBook struct {
+	Cash    float64
+	Prices  map[string]float64
+	Shares  map[string]float64 // Long shares by ticker
+	Options []MarginOption
+}
+
+// MarginRequirement is what one underlying's positions require under each
+// regime.
+type MarginRequirement struct {
+	Ticker    string
+	RegT      float64
+	Portfolio float64
+}
+
+// mark values o per share at underlying price S
+func (o MarginOption) mark(S float64) float64 {
+	return csp.CalculatePrice(o.OptionType, S, o.Strike, o.IV, o.DTE)
+}
+
+// shares is the number of shares o's contracts control
+func (o MarginOption) shares() float64 {
+	return float64(sharesPerContract(o.Multiplier) * o.Contracts)
+}
+
+// sign is +1 for a long position and -1 for a short one
+func (o MarginOption) sign() float64 {
+	if o.Action == "SELL" {
+		return -1
+	}
+	return 1
+}
+
+// NetLiquidation values the book: cash, shares at their price, and options
+// at their model value, short options as liabilities.
+func (b MarginBook) NetLiquidation() float64 {
+	total := b.Cash
+	for ticker, shares := range b.Shares {
+		total += shares * b.Prices[ticker]
+	}
+	for _, o := range b.Options {
+		total += o.sign() * o.mark(b.Prices[o.Ticker]) * o.shares()
+	}
+	return total
+}
+
+// Requirements computes each underlying's requirement under both regimes,
+// sorted by ticker.
+func (b MarginBook) Requirements() []MarginRequirement {
+	byTicker := make(map[string][]MarginOption)
+	tickers := make(map[string]bool)
+	for ticker, shares := range b.Shares {
+		if shares > 0 {
+			tickers[ticker] = true
+		}
+	}
+	for _, o := range b.Options {
+		byTicker[o.Ticker] = append(byTicker[o.Ticker], o)
+		tickers[o.Ticker] = true
+	}
+
+	reqs := make([]MarginRequirement, 0, len(tickers))
+	for ticker := range tickers {
+		price := b.Prices[ticker]
+		shares := b.Shares[ticker]
+		options := byTicker[ticker]
+		reqs = append(reqs, MarginRequirement{
+			Ticker:    ticker,
+			RegT:      regTRequirement(price, shares, options),
+			Portfolio: portfolioRequirement(price, shares, options),
+		})
+	}
+	sort.Slice(reqs, func(i, j int) bool { return reqs[i].Ticker < reqs[j].Ticker })
+	return reqs
+}
+
+// regTRequirement applies the strategy-based rules to one underlying: half
+// the stock's value, long options paid in full, short calls covered by shares
+// held free, and naked short options at 20% of the underlying less the
+// out-of-the-money amount plus the option's value, with a 10% floor.
+func regTRequirement(S, shares float64, options []MarginOption) float64 {
+	req := shares * S * RegTStockPct
+	free := shares
+	for _, o := range options {
+		mark := o.mark(S)
+		contracts := float64(o.Contracts)
+		multiplier := float64(sharesPerContract(o.Multiplier))
+		if o.Action == "BUY" {
+			req += mark * multiplier * contracts
+			continue
+		}
+
+		if o.OptionType == "CALL" {
+			covered := math.Min(contracts, math.Floor(free/multiplier))
+			free -= covered * multiplier
+			contracts -= covered
+			if contracts == 0 {
+				continue
+			}
+		}
+
+		otm := math.Max(S-o.Strike, 0)
+		floor := RegTMinimumPct * o.Strike
+		if o.OptionType == "CALL" {
+			otm = math.Max(o.Strike-S, 0)
+			floor = RegTMinimumPct * S
+		}
+		perShare := math.Max(RegTNakedPct*S-otm, floor) + mark
+		req += perShare * multiplier * contracts
+	}
+	return req
+}
+
+// portfolioRequirement is the largest loss of one underlying's positions
+// across equidistant price moves up to PMMove either way, each option
+// revalued at its own volatility, but at least PMContractFloor per contract.
+func portfolioRequirement(S, shares float64, options []MarginOption) float64 {
+	value := func(price float64) float64 {
+		v := shares * price
+		for _, o := range options {
+			v += o.sign() * o.mark(price) * o.shares()
+		}
+		return v
+	}
+
+	current := value(S)
+	worst := 0.0
+	for i := 0; i <= PMSteps; i++ {
+		move := -PMMove + 2*PMMove*float64(i)/PMSteps
+		worst = math.Max(worst, current-value(S*(1+move)))
+	}
+
+	contracts := 0
+	for _, o := range options {
+		contracts += o.Contracts
+	}
+	return math.Max(worst, PMContractFloor*float64(contracts))
+}
@@ -0,0 +1,46 @@
+package analytics
+
+import "testing"
+
+func TestMarginRequirements(t *testing.T) {
+	// Zero IV values options at intrinsic, keeping the expected numbers exact
+	book := MarginBook{
+		Cash:   1000,
+		Prices: map[string]float64{"AAPL": 100, "MSFT": 100, "NVDA": 100},
+		Shares: map[string]float64{"AAPL": 100},
+		Options: []MarginOption{
+			{Ticker: "AAPL", OptionType: "CALL", Action: "SELL", Strike: 110, Contracts: 1, DTE: 30},
+			{Ticker: "MSFT", OptionType: "PUT", Action: "SELL", Strike: 90, Contracts: 1, DTE: 30},
+			{Ticker: "NVDA", OptionType: "CALL", Action: "BUY", Strike: 100, Contracts: 2, DTE: 30},
+		},
+	}
+
+	reqs := book.Requirements()
+	if len(reqs) != 3 {
+		t.Fatalf("requirements = %+v", reqs)
+	}
+	want := []MarginRequirement{
+		// Covered call: only the stock, half its value; a 15% drop loses 1500
+		{Ticker: "AAPL", RegT: 5000, Portfolio: 1500},
+		// Naked put 10 OTM: max(20 - 10, 9) per share; a 15% drop puts it 5 ITM
+		{Ticker: "MSFT", RegT: 1000, Portfolio: 500},
+		// Worthless long calls cost nothing to hold but carry the contract floor
+		{Ticker: "NVDA", RegT: 0, Portfolio: 75},
+	}
+	for i, w := range want {
+		got := reqs[i]
+		if got.Ticker != w.Ticker || !approxEqual(got.RegT, w.RegT) || !approxEqual(got.Portfolio, w.Portfolio) {
+			t.Errorf("requirement %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	// An uncovered call falls back to the naked rule: max(20 - 10, 10) per share
+	book.Shares["AAPL"] = 50
+	if got := book.Requirements()[0].RegT; !approxEqual(got, 2500+1000) {
+		t.Errorf("partly covered AAPL Reg-T = %v, want 3500", got)
+	}
+
+	if got := book.NetLiquidation(); !approxEqual(got, 1000+5000) {
+		t.Errorf("net liquidation = %v, want 6000", got)
+	}
+}
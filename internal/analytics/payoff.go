@@ -0,0 +1,126 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+)
+
+// PayoffLeg is one option position in a strategy. Prices are per share.
+type PayoffLeg struct {
+	OptionType string // CALL or PUT
+	Action     string // BUY or SELL
+	Strike     float64
+	Premium    float64 // Price the position was opened at
+	Contracts  int
+	Multiplier int // Shares per contract, 0 for the standard 100
+}
+
+// Payoff is a strategy's profit and loss at expiry: option legs, optionally
+// combined with shares of the underlying bought at CostBasis.
+type Payoff struct {
+	Legs      []PayoffLeg
+	Shares    float64
+	CostBasis float64 // Per share
+}
+
+// At returns the strategy's profit or loss in dollars if the underlying
+// closes at price on expiry.
+func (p Payoff) At(price float64) float64 {
+	pl := p.Shares * (price - p.CostBasis)
+	for _, l := range p.Legs {
+		intrinsic := math.Max(price-l.Strike, 0)
+		if l.OptionType == "PUT" {
+			intrinsic = math.Max(l.Strike-price, 0)
+		}
+		perShare := intrinsic - l.Premium
+		if l.Action == "SELL" {
+			perShare = -perShare
+		}
+		pl += perShare * float64(sharesPerContract(l.Multiplier)*l.Contracts)
+	}
+	return pl
+}
+
+// kinks returns zero and the strikes, in order: the prices where the payoff
+// can change slope
+func (p Payoff) kinks() []float64 {
+	points := []float64{0}
+	for _, l := range p.Legs {
+		points = append(points, l.Strike)
+	}
+	sort.Float64s(points)
+	return points
+}
+
+// Breakevens returns the prices at which the strategy neither makes nor loses
+// money at expiry, in ascending order. The payoff is linear between strikes,
+// so each is found exactly.
+func (p Payoff) Breakevens() []float64 {
+	points := p.kinks()
+	// Past the last strike the slope is constant; one more point far enough
+	// out catches a final crossing
+	last := points[len(points)-1]
+	slope := p.At(last+1) - p.At(last)
+	if slope != 0 {
+		if x := last - p.At(last)/slope; x > last {
+			points = append(points, x+1)
+		}
+	}
+
+	var evens []float64
+	add := func(x float64) {
+		if len(evens) == 0 || math.Abs(evens[len(evens)-1]-x) > 1e-9 {
+			evens = append(evens, x)
+		}
+	}
+	for i := 0; i+1 < len(points); i++ {
+		x0, x1 := points[i], points[i+1]
+		y0, y1 := p.At(x0), p.At(x1)
+		switch {
+		case y0 == 0 && y1 == 0:
+			// A flat stretch at zero has no single breakeven
+		case y0 == 0:
+			if i > 0 {
+				add(x0)
+			}
+		case y0*y1 < 0:
+			add(x0 + (x1-x0)*y0/(y0-y1))
+		}
+	}
+	if n := len(points); n > 1 && p.At(points[n-1]) == 0 && p.At(points[n-2]) != 0 {
+		add(points[n-1])
+	}
+	return evens
+}
+
+// MaxLoss returns the most the strategy can lose at expiry, as a positive
+// amount, or zero if it cannot lose. bounded is false when the loss grows
+// without limit as the underlying rises, as for a short call not covered by
+// shares.
+func (p Payoff) MaxLoss() (loss float64, bounded bool) {
+	points := p.kinks()
+	for _, x := range points {
+		loss = math.Max(loss, -p.At(x))
+	}
+	last := points[len(points)-1]
+	if p.At(last+1) < p.At(last) {
+		return 0, false
+	}
+	return loss, true
+}
+
+// PriceRange returns a span of underlying prices wide enough to show every
+// strike, breakeven and the current price with some margin either side.
+func (p Payoff) PriceRange(current float64) (lo, hi float64) {
+	lo, hi = current, current
+	points := append(p.kinks()[1:], p.Breakevens()...)
+	if p.Shares != 0 {
+		points = append(points, p.CostBasis)
+	}
+	for _, x := range points {
+		lo = math.Min(lo, x)
+		hi = math.Max(hi, x)
+	}
+	pad := math.Max((hi-lo)*0.25, hi*0.1)
+	return math.Max(lo-pad, 0), hi + pad
+}
@@ -0,0 +1,78 @@
+package analytics
+
+import "testing"
+
+func TestPayoff(t *testing.T) {
+	// Short 95 put for 2.00: keeps 200 above 95, breaks even at 93
+	put := Payoff{Legs: []PayoffLeg{{OptionType: "PUT", Action: "SELL", Strike: 95, Premium: 2, Contracts: 1}}}
+	if got := put.At(100); !approxEqual(got, 200) {
+		t.Errorf("short put at 100 = %v, want 200", got)
+	}
+	if got := put.At(90); !approxEqual(got, -300) {
+		t.Errorf("short put at 90 = %v, want -300", got)
+	}
+	if got := put.Breakevens(); len(got) != 1 || !approxEqual(got[0], 93) {
+		t.Errorf("short put breakevens = %v, want [93]", got)
+	}
+
+	// Covered call: 100 shares at 100, short 110 call for 3.00
+	covered := Payoff{
+		Legs:      []PayoffLeg{{OptionType: "CALL", Action: "SELL", Strike: 110, Premium: 3, Contracts: 1}},
+		Shares:    100,
+		CostBasis: 100,
+	}
+	if got := covered.At(130); !approxEqual(got, 1300) {
+		t.Errorf("covered call at 130 = %v, want capped 1300", got)
+	}
+	if got := covered.Breakevens(); len(got) != 1 || !approxEqual(got[0], 97) {
+		t.Errorf("covered call breakevens = %v, want [97]", got)
+	}
+
+	// Short strangle 90/110 for 1.50 each: breakevens 87 and 113
+	strangle := Payoff{Legs: []PayoffLeg{
+		{OptionType: "PUT", Action: "SELL", Strike: 90, Premium: 1.5, Contracts: 1},
+		{OptionType: "CALL", Action: "SELL", Strike: 110, Premium: 1.5, Contracts: 1},
+	}}
+	got := strangle.Breakevens()
+	if len(got) != 2 || !approxEqual(got[0], 87) || !approxEqual(got[1], 113) {
+		t.Errorf("strangle breakevens = %v, want [87 113]", got)
+	}
+
+	lo, hi := strangle.PriceRange(100)
+	if lo >= 87 || hi <= 113 {
+		t.Errorf("price range [%v, %v] does not cover the breakevens", lo, hi)
+	}
+}
+
+func TestPayoffMaxLoss(t *testing.T) {
+	// Short 95 put for 2.00: the stock going to zero loses 9,300
+	put := Payoff{Legs: []PayoffLeg{{OptionType: "PUT", Action: "SELL", Strike: 95, Premium: 2, Contracts: 1}}}
+	if loss, bounded := put.MaxLoss(); !bounded || !approxEqual(loss, 9300) {
+		t.Errorf("short put max loss = %v (bounded %v), want 9300", loss, bounded)
+	}
+
+	// 100/95 put credit spread for 1.50: width less the credit
+	spread := Payoff{Legs: []PayoffLeg{
+		{OptionType: "PUT", Action: "SELL", Strike: 100, Premium: 3, Contracts: 2},
+		{OptionType: "PUT", Action: "BUY", Strike: 95, Premium: 1.5, Contracts: 2},
+	}}
+	if loss, bounded := spread.MaxLoss(); !bounded || !approxEqual(loss, 700) {
+		t.Errorf("put spread max loss = %v (bounded %v), want 700", loss, bounded)
+	}
+
+	// A naked short call is unbounded; covered, the shares bound it
+	call := Payoff{Legs: []PayoffLeg{{OptionType: "CALL", Action: "SELL", Strike: 110, Premium: 3, Contracts: 1}}}
+	if _, bounded := call.MaxLoss(); bounded {
+		t.Error("naked short call should be unbounded")
+	}
+	call.Shares, call.CostBasis = 100, 100
+	if loss, bounded := call.MaxLoss(); !bounded || !approxEqual(loss, 9700) {
+		t.Errorf("covered call max loss = %v (bounded %v), want 9700", loss, bounded)
+	}
+
+	// A long call can lose only its premium, though it gains without limit
+	long := Payoff{Legs: []PayoffLeg{{OptionType: "CALL", Action: "BUY", Strike: 110, Premium: 3, Contracts: 1}}}
+	if loss, bounded := long.MaxLoss(); !bounded || !approxEqual(loss, 300) {
+		t.Errorf("long call max loss = %v (bounded %v), want 300", loss, bounded)
+	}
+}
@@ -0,0 +1,79 @@
+package analytics
+
+import "fmt"
+
+// Option policy kinds
+const (
+	PolicyRollAtDTE     = "ROLL_AT_DTE"     // Roll once DTE falls to the threshold
+	PolicyCloseAtProfit = "CLOSE_AT_PROFIT" // Close once this percent of the premium is captured
+)
+
+// Policy is a rule attached to one option position.
+type Policy struct {
+	Kind      string
+	Threshold float64 // Days for PolicyRollAtDTE, percent for PolicyCloseAtProfit
+	ITMOnly   bool    // Only fire while the option is in the money
+}
+
+// PolicyInput is the state of a position as policies see it. Prices are per
+// share.
+type PolicyInput struct {
+	OptionType string // CALL or PUT
+	Action     string // BUY or SELL
+	Strike     float64
+	Premium    float64 // Price the position was opened at
+	DTE        int
+	Underlying float64 // Zero if unquoted
+	Mark       float64 // Current option price, zero if unknown
+}
+
+// ITM reports whether the option is in the money. An unquoted underlying is
+// never in the money.
+func (in PolicyInput) ITM() bool {
+	if in.Underlying <= 0 {
+		return false
+	}
+	if in.OptionType == "PUT" {
+		return in.Underlying < in.Strike
+	}
+	return in.Underlying > in.Strike
+}
+
+// ProfitPct is the percent of the opening premium captured so far: for a short
+// option the decay from premium to mark, for a long one the gain. ok is false
+// without a mark or premium.
+func (in PolicyInput) ProfitPct() (pct float64, ok bool) {
+	if in.Mark <= 0 || in.Premium <= 0 {
+		return 0, false
+	}
+	if in.Action == "SELL" {
+		return (in.Premium - in.Mark) / in.Premium * 100, true
+	}
+	return (in.Mark - in.Premium) / in.Premium * 100, true
+}
+
+// Evaluate reports whether p fires for in, with a reason fit to show the user.
+func (p Policy) Evaluate(in PolicyInput) (reason string, fire bool) {
+	if p.ITMOnly && !in.ITM() {
+		return "", false
+	}
+	switch p.Kind {
+	case PolicyRollAtDTE:
+		if in.DTE < 0 || in.DTE > int(p.Threshold) {
+			return "", false
+		}
+		reason = fmt.Sprintf("%d DTE (roll at %d)", in.DTE, int(p.Threshold))
+	case PolicyCloseAtProfit:
+		pct, ok := in.ProfitPct()
+		if !ok || pct < p.Threshold {
+			return "", false
+		}
+		reason = fmt.Sprintf("%.0f%% of premium captured (close at %.0f%%)", pct, p.Threshold)
+	default:
+		return "", false
+	}
+	if p.ITMOnly {
+		reason += ", in the money"
+	}
+	return reason, true
+}
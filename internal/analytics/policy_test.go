@@ -0,0 +1,40 @@
+package analytics
+
+import "testing"
+
+func TestPolicyEvaluate(t *testing.T) {
+	put := PolicyInput{OptionType: "PUT", Action: "SELL", Strike: 100, Premium: 2, DTE: 20, Underlying: 98, Mark: 0.9}
+
+	roll := Policy{Kind: PolicyRollAtDTE, Threshold: 21, ITMOnly: true}
+	if reason, fire := roll.Evaluate(put); !fire || reason != "20 DTE (roll at 21), in the money" {
+		t.Errorf("ITM put at 20 DTE: fire=%v reason=%q", fire, reason)
+	}
+	otm := put
+	otm.Underlying = 105
+	if _, fire := roll.Evaluate(otm); fire {
+		t.Error("ITM-only roll fired for an OTM put")
+	}
+	early := put
+	early.DTE = 30
+	if _, fire := roll.Evaluate(early); fire {
+		t.Error("roll at 21 DTE fired at 30 DTE")
+	}
+
+	closeAt := Policy{Kind: PolicyCloseAtProfit, Threshold: 50}
+	if reason, fire := closeAt.Evaluate(put); !fire || reason != "55% of premium captured (close at 50%)" {
+		t.Errorf("short put at 55%% profit: fire=%v reason=%q", fire, reason)
+	}
+	noMark := put
+	noMark.Mark = 0
+	if _, fire := closeAt.Evaluate(noMark); fire {
+		t.Error("close at profit fired without a mark")
+	}
+
+	long := PolicyInput{OptionType: "CALL", Action: "BUY", Strike: 100, Premium: 2, Mark: 3.2, Underlying: 104}
+	if pct, ok := long.ProfitPct(); !ok || !approxEqual(pct, 60) {
+		t.Errorf("long call profit = %v, %v; want 60", pct, ok)
+	}
+	if !long.ITM() || otm.ITM() {
+		t.Error("ITM misclassified")
+	}
+}
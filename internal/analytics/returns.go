@@ -0,0 +1,114 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// CashFlow is money moving between the investor and a portfolio or position:
+// negative when paid in, positive when taken out. The value still held at
+// the end is a final positive flow.
+type CashFlow struct {
+	Date   time.Time
+	Amount float64
+}
+
+// ValuePoint is what a portfolio or position was worth at the end of a day,
+// after Flow, the net cash put into it that day (negative when taken out).
+type ValuePoint struct {
+	Date  time.Time
+	Value float64
+	Flow  float64
+}
+
+const daysPerYear = 365.0
+
+// XIRR returns the annualized money-weighted return of flows: the rate at
+// which their net present value is zero. ok is false without both a payment
+// in and a payment out, or if no rate between -100% and 1,000,000% solves it.
+func XIRR(flows []CashFlow) (float64, bool) {
+	if len(flows) < 2 {
+		return 0, false
+	}
+	sorted := append([]CashFlow(nil), flows...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	var in, out bool
+	years := make([]float64, len(sorted))
+	for i, f := range sorted {
+		in = in || f.Amount < 0
+		out = out || f.Amount > 0
+		years[i] = sorted[i].Date.Sub(sorted[0].Date).Hours() / 24 / daysPerYear
+	}
+	if !in || !out {
+		return 0, false
+	}
+	npv := func(rate float64) (value, slope float64) {
+		for i, f := range sorted {
+			discount := math.Pow(1+rate, years[i])
+			value += f.Amount / discount
+			slope -= years[i] * f.Amount / (discount * (1 + rate))
+		}
+		return value, slope
+	}
+
+	// Newton's method from 10% converges in a few steps for ordinary flows
+	rate := 0.1
+	for range 50 {
+		value, slope := npv(rate)
+		if math.Abs(value) < 1e-7 {
+			return rate, true
+		}
+		if slope == 0 || math.IsNaN(slope) {
+			break
+		}
+		next := rate - value/slope
+		if next <= -1 || math.IsNaN(next) || math.IsInf(next, 0) {
+			break
+		}
+		rate = next
+	}
+
+	// Otherwise bisect between the bounds, which must bracket a root
+	lo, hi := -0.999999, 10000.0
+	vlo, _ := npv(lo)
+	vhi, _ := npv(hi)
+	if math.IsNaN(vlo) || math.IsNaN(vhi) || (vlo > 0) == (vhi > 0) {
+		return 0, false
+	}
+	for range 200 {
+		mid := (lo + hi) / 2
+		vmid, _ := npv(mid)
+		if math.Abs(vmid) < 1e-7 || hi-lo < 1e-10 {
+			return mid, true
+		}
+		if (vmid > 0) == (vlo > 0) {
+			lo, vlo = mid, vmid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2, true
+}
+
+// TWR returns the time-weighted return across points, oldest first: each
+// period's growth net of the day's flows, chained, so money moved in or out
+// does not count as gain. Periods starting from nothing are skipped; ok is
+// false if none remain.
+func TWR(points []ValuePoint) (float64, bool) {
+	growth := 1.0
+	periods := 0
+	for i := 1; i < len(points); i++ {
+		start := points[i-1].Value
+		if start <= 0 {
+			continue
+		}
+		growth *= (points[i].Value - points[i].Flow) / start
+		periods++
+	}
+	if periods == 0 {
+		return 0, false
+	}
+	return growth - 1, true
+}
@@ -0,0 +1,65 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestXIRR(t *testing.T) {
+	// 10,000 in, 11,000 out a year later
+	rate, ok := XIRR([]CashFlow{
+		{date(2026, 1, 1), 11000},
+		{date(2025, 1, 1), -10000},
+	})
+	if !ok || math.Abs(rate-0.10) > 1e-6 {
+		t.Errorf("rate = %v (ok %v), want 0.10", rate, ok)
+	}
+
+	// A deposit halfway weighs the second half more: 10,000 then 10,000, ending at 21,000
+	rate, ok = XIRR([]CashFlow{
+		{date(2025, 1, 1), -10000},
+		{date(2025, 7, 2), -10000},
+		{date(2026, 1, 1), 21000},
+	})
+	if !ok || rate < 0.06 || rate > 0.07 {
+		t.Errorf("rate = %v (ok %v), want about 6.6%%", rate, ok)
+	}
+
+	// A loss
+	rate, ok = XIRR([]CashFlow{{date(2025, 1, 1), -10000}, {date(2026, 1, 1), 8000}})
+	if !ok || math.Abs(rate+0.20) > 1e-6 {
+		t.Errorf("rate = %v (ok %v), want -0.20", rate, ok)
+	}
+}
+
+func TestXIRRNeedsBothDirections(t *testing.T) {
+	if _, ok := XIRR([]CashFlow{{date(2025, 1, 1), 500}, {date(2025, 6, 1), 300}}); ok {
+		t.Error("flows out only should have no rate")
+	}
+	if _, ok := XIRR([]CashFlow{{date(2025, 1, 1), -500}}); ok {
+		t.Error("a single flow should have no rate")
+	}
+}
+
+func TestTWR(t *testing.T) {
+	points := []ValuePoint{
+		{Date: date(2026, 1, 1), Value: 10000},
+		{Date: date(2026, 2, 1), Value: 11000},              // +10%
+		{Date: date(2026, 3, 1), Value: 21000, Flow: 10000}, // Deposit, no gain
+		{Date: date(2026, 4, 1), Value: 18900},              // -10%
+	}
+	r, ok := TWR(points)
+	if !ok || !approxEqual(r, 1.1*0.9-1) {
+		t.Errorf("TWR = %v (ok %v), want %v", r, ok, 1.1*0.9-1)
+	}
+
+	// A withdrawal is not a loss
+	r, _ = TWR([]ValuePoint{{Value: 1000}, {Value: 600, Flow: -500}})
+	if !approxEqual(r, 0.1) {
+		t.Errorf("TWR after withdrawal = %v, want 0.1", r)
+	}
+
+	if _, ok := TWR([]ValuePoint{{Value: 0}, {Value: 1000, Flow: 1000}}); ok {
+		t.Error("TWR from nothing should be unavailable")
+	}
+}
@@ -0,0 +1,89 @@
+package analytics
+
+import (
+	"math"
+	"strings"
+)
+
+// RiskCap is a maximum exposure, as a percent of the portfolio, for one ticker
+// or for all tickers in a sector.
+type RiskCap struct {
+	Sector bool
+	Name   string // Ticker symbol or sector name
+	MaxPct float64
+}
+
+// RiskBook is the current dollar exposure per ticker, measured against the
+// total portfolio value. A ticker's exposure is the market value of its shares
+// plus the collateral held for its short puts.
+type RiskBook struct {
+	Total    float64
+	ByTicker map[string]float64
+	Sectors  map[string]string // Ticker to sector, for sector caps
+}
+
+// CapCheck is one cap that applies to a ticker, with the exposure it governs.
+type CapCheck struct {
+	Cap      RiskCap
+	Exposure float64 // Current dollars under the cap
+	Limit    float64 // Dollars the cap allows
+}
+
+// Headroom is the exposure that can still be added before the cap is reached.
+// It is negative when the cap is already breached.
+func (c CapCheck) Headroom() float64 {
+	return c.Limit - c.Exposure
+}
+
+// Checks returns the caps that apply to ticker: its own cap and the cap on
+// its sector, if any.
+func (b RiskBook) Checks(caps []RiskCap, ticker string) []CapCheck {
+	sector := b.Sectors[ticker]
+	var checks []CapCheck
+	for _, c := range caps {
+		var exposure float64
+		switch {
+		case !c.Sector && strings.EqualFold(c.Name, ticker):
+			exposure = b.ByTicker[ticker]
+		case c.Sector && sector != "" && strings.EqualFold(c.Name, sector):
+			exposure = b.SectorExposure(sector)
+		default:
+			continue
+		}
+		checks = append(checks, CapCheck{Cap: c, Exposure: exposure, Limit: b.Total * c.MaxPct / 100})
+	}
+	return checks
+}
+
+// SectorExposure sums the exposure of every ticker assigned to sector.
+func (b RiskBook) SectorExposure(sector string) float64 {
+	var total float64
+	for ticker, exposure := range b.ByTicker {
+		if strings.EqualFold(b.Sectors[ticker], sector) {
+			total += exposure
+		}
+	}
+	return total
+}
+
+// Breaches returns the caps on ticker that adding exposure of add would exceed.
+func (b RiskBook) Breaches(caps []RiskCap, ticker string, add float64) []CapCheck {
+	var breached []CapCheck
+	for _, c := range b.Checks(caps, ticker) {
+		if add > c.Headroom() {
+			breached = append(breached, c)
+		}
+	}
+	return breached
+}
+
+// Headroom returns the smallest headroom among the caps on ticker, or ok=false
+// if no cap applies.
+func (b RiskBook) Headroom(caps []RiskCap, ticker string) (headroom float64, ok bool) {
+	headroom = math.Inf(1)
+	for _, c := range b.Checks(caps, ticker) {
+		headroom = math.Min(headroom, c.Headroom())
+		ok = true
+	}
+	return headroom, ok
+}
@@ -0,0 +1,47 @@
+package analytics
+
+import "testing"
+
+func TestRiskBookChecks(t *testing.T) {
+	book := RiskBook{
+		Total:    100000,
+		ByTicker: map[string]float64{"AAPL": 15000, "MSFT": 10000, "XOM": 5000},
+		Sectors:  map[string]string{"AAPL": "Tech", "MSFT": "tech", "XOM": "Energy"},
+	}
+	caps := []RiskCap{
+		{Name: "AAPL", MaxPct: 20},
+		{Sector: true, Name: "Tech", MaxPct: 30},
+	}
+
+	checks := book.Checks(caps, "AAPL")
+	if len(checks) != 2 {
+		t.Fatalf("AAPL checks = %d, want 2", len(checks))
+	}
+	if !approxEqual(checks[0].Headroom(), 5000) {
+		t.Errorf("AAPL ticker headroom = %v, want 5000", checks[0].Headroom())
+	}
+	// Sector names match case-insensitively: AAPL + MSFT
+	if !approxEqual(checks[1].Exposure, 25000) || !approxEqual(checks[1].Headroom(), 5000) {
+		t.Errorf("Tech sector = %v exposure, %v headroom", checks[1].Exposure, checks[1].Headroom())
+	}
+
+	if got := book.Breaches(caps, "AAPL", 4000); len(got) != 0 {
+		t.Errorf("4000 more AAPL breaches %d caps, want 0", len(got))
+	}
+	if got := book.Breaches(caps, "AAPL", 6000); len(got) != 2 {
+		t.Errorf("6000 more AAPL breaches %d caps, want 2", len(got))
+	}
+	if got := book.Breaches(caps, "MSFT", 6000); len(got) != 1 || !got[0].Cap.Sector {
+		t.Errorf("6000 more MSFT breaches %v, want the sector cap", got)
+	}
+
+	if h, ok := book.Headroom(caps, "MSFT"); !ok || !approxEqual(h, 5000) {
+		t.Errorf("MSFT headroom = %v, %v; want 5000", h, ok)
+	}
+	if _, ok := book.Headroom(caps, "XOM"); ok {
+		t.Error("XOM has headroom but no cap applies")
+	}
+	if got := book.Breaches(caps, "NEW", 1e9); len(got) != 0 {
+		t.Errorf("uncapped ticker breaches %d caps", len(got))
+	}
+}
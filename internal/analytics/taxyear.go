@@ -0,0 +1,65 @@
+package analytics
+
+import (
+	"fmt"
+	"time"
+)
+
+// TaxYear is a yearly reporting period that starts on a fixed month and day,
+// e.g. April 6 for the UK. The zero value is the calendar year.
+type TaxYear struct {
+	Month time.Month
+	Day   int
+}
+
+// ParseTaxYear reads a start date written as MM-DD. An empty string is the
+// calendar year. February 29 is rejected since it does not start every year.
+func ParseTaxYear(s string) (TaxYear, error) {
+	if s == "" {
+		return TaxYear{}, nil
+	}
+	t, err := time.Parse("01-02", s)
+	if err != nil || (t.Month() == time.February && t.Day() == 29) {
+		return TaxYear{}, fmt.Errorf("invalid tax year start %q (want MM-DD, e.g. 04-06)", s)
+	}
+	return TaxYear{Month: t.Month(), Day: t.Day()}, nil
+}
+
+// IsCalendar reports whether the year starts on January 1.
+func (y TaxYear) IsCalendar() bool {
+	month, day := y.start()
+	return month == time.January && day == 1
+}
+
+// String returns the start date as MM-DD.
+func (y TaxYear) String() string {
+	month, day := y.start()
+	return fmt.Sprintf("%02d-%02d", int(month), day)
+}
+
+// Bounds returns midnight on the first day of the tax year containing t and
+// on the first day of the next one, in t's location.
+func (y TaxYear) Bounds(t time.Time) (start, end time.Time) {
+	month, day := y.start()
+	start = time.Date(t.Year(), month, day, 0, 0, 0, 0, t.Location())
+	if t.Before(start) {
+		start = start.AddDate(-1, 0, 0)
+	}
+	return start, start.AddDate(1, 0, 0)
+}
+
+// Label names the tax year starting at start: "2026" for a calendar year,
+// otherwise the two years it spans, e.g. "2025/26".
+func (y TaxYear) Label(start time.Time) string {
+	if y.IsCalendar() {
+		return fmt.Sprintf("%d", start.Year())
+	}
+	return fmt.Sprintf("%d/%02d", start.Year(), (start.Year()+1)%100)
+}
+
+func (y TaxYear) start() (time.Month, int) {
+	if y.Month == 0 {
+		return time.January, 1
+	}
+	return y.Month, y.Day
+}
@@ -0,0 +1,52 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaxYearBounds(t *testing.T) {
+	uk, err := ParseTaxYear("04-06")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		year       TaxYear
+		in         time.Time
+		start, end time.Time
+		label      string
+	}{
+		{"calendar", TaxYear{}, date(2026, 3, 2), date(2026, 1, 1), date(2027, 1, 1), "2026"},
+		{"uk before start", uk, date(2026, 4, 5), date(2025, 4, 6), date(2026, 4, 6), "2025/26"},
+		{"uk on start", uk, date(2026, 4, 6), date(2026, 4, 6), date(2027, 4, 6), "2026/27"},
+		{"uk december", uk, date(2026, 12, 31), date(2026, 4, 6), date(2027, 4, 6), "2026/27"},
+		{"century", uk, date(2099, 5, 1), date(2099, 4, 6), date(2100, 4, 6), "2099/00"},
+	}
+	for _, tc := range tests {
+		start, end := tc.year.Bounds(tc.in)
+		if !start.Equal(tc.start) || !end.Equal(tc.end) {
+			t.Errorf("%s: Bounds(%v) = %v..%v, want %v..%v", tc.name, tc.in, start, end, tc.start, tc.end)
+		}
+		if got := tc.year.Label(start); got != tc.label {
+			t.Errorf("%s: Label = %q, want %q", tc.name, got, tc.label)
+		}
+	}
+}
+
+func TestParseTaxYear(t *testing.T) {
+	for _, s := range []string{"", "01-01"} {
+		y, err := ParseTaxYear(s)
+		if err != nil || !y.IsCalendar() {
+			t.Errorf("ParseTaxYear(%q) = %v, %v; want calendar year", s, y, err)
+		}
+	}
+	if y, err := ParseTaxYear("10-01"); err != nil || y.String() != "10-01" {
+		t.Errorf("ParseTaxYear(10-01) = %v, %v", y, err)
+	}
+	for _, s := range []string{"13-01", "04-31", "02-29", "April 6", "4/6"} {
+		if _, err := ParseTaxYear(s); err == nil {
+			t.Errorf("ParseTaxYear(%q) succeeded", s)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+// Package broker pulls positions and balances from read-only brokerage
+// aggregators and reconciles them against recorded holdings.
+package broker
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+)
+
+// Position is a brokerage-reported holding.
+type Position struct {
+	Ticker    string
+	Quantity  decimal.Decimal
+	CostBasis decimal.NullDecimal // Total cost, if the broker reports it
+}
+
+// Snapshot is everything a provider reports in one sync.
+type Snapshot struct {
+	Positions []Position
+	Cash      decimal.Decimal // Sum of cash balances across investment accounts
+}
+
+// Provider is a read-only brokerage data source.
+type Provider interface {
+	Name() string
+	Fetch(ctx context.Context) (*Snapshot, error)
+}
@@ -0,0 +1,144 @@
+package broker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var plaidHosts = map[string]string{
+	"sandbox":     "https://sandbox.plaid.com",
+	"development": "https://development.plaid.com",
+	"production":  "https://production.plaid.com",
+}
+
+// Plaid reads holdings via the Plaid Investments API. The access token must
+// be obtained separately through Plaid Link.
+type Plaid struct {
+	clientID    string
+	secret      string
+	accessToken string
+	baseURL     string
+	httpClient  *http.Client
+}
+
+func NewPlaid(env, clientID, secret, accessToken string) (*Plaid, error) {
+	if env == "" {
+		env = "production"
+	}
+	host, ok := plaidHosts[env]
+	if !ok {
+		return nil, fmt.Errorf("unknown Plaid environment %q", env)
+	}
+	return &Plaid{
+		clientID:    clientID,
+		secret:      secret,
+		accessToken: accessToken,
+		baseURL:     host,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (p *Plaid) Name() string { return "plaid" }
+
+type plaidHoldingsResponse struct {
+	Holdings []struct {
+		AccountID  string   `json:"account_id"`
+		SecurityID string   `json:"security_id"`
+		Quantity   float64  `json:"quantity"`
+		CostBasis  *float64 `json:"cost_basis"`
+	} `json:"holdings"`
+	Securities []struct {
+		SecurityID   string  `json:"security_id"`
+		TickerSymbol *string `json:"ticker_symbol"`
+		Type         string  `json:"type"`
+		IsCashEquiv  *bool   `json:"is_cash_equivalent"`
+	} `json:"securities"`
+	ErrorCode    string `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+}
+
+func (p *Plaid) Fetch(ctx context.Context) (*Snapshot, error) {
+	body, err := json.Marshal(map[string]string{
+		"client_id":    p.clientID,
+		"secret":       p.secret,
+		"access_token": p.accessToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/investments/holdings/get", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var data plaidHoldingsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("decoding Plaid response (status %d): %w", resp.StatusCode, err)
+	}
+	if data.ErrorCode != "" {
+		return nil, fmt.Errorf("plaid: %s: %s", data.ErrorCode, data.ErrorMessage)
+	}
+	return parsePlaidHoldings(&data), nil
+}
+
+// parsePlaidHoldings merges holdings across accounts by ticker. Cash-equivalent
+// securities (money market sweeps) count toward cash, not positions.
+func parsePlaidHoldings(data *plaidHoldingsResponse) *Snapshot {
+	type security struct {
+		ticker string
+		cash   bool
+	}
+	securities := make(map[string]security)
+	for _, s := range data.Securities {
+		sec := security{cash: s.Type == "cash" || (s.IsCashEquiv != nil && *s.IsCashEquiv)}
+		if s.TickerSymbol != nil {
+			sec.ticker = strings.ToUpper(strings.ReplaceAll(*s.TickerSymbol, ".", "-"))
+		}
+		securities[s.SecurityID] = sec
+	}
+
+	snap := &Snapshot{}
+	byTicker := make(map[string]int)
+	for _, h := range data.Holdings {
+		sec := securities[h.SecurityID]
+		qty := decimal.NewFromFloat(h.Quantity)
+		if sec.cash {
+			snap.Cash = snap.Cash.Add(qty)
+			continue
+		}
+		if sec.ticker == "" {
+			continue
+		}
+
+		i, ok := byTicker[sec.ticker]
+		if !ok {
+			i = len(snap.Positions)
+			byTicker[sec.ticker] = i
+			snap.Positions = append(snap.Positions, Position{Ticker: sec.ticker, CostBasis: decimal.NullDecimal{Valid: true}})
+		}
+		pos := &snap.Positions[i]
+		pos.Quantity = pos.Quantity.Add(qty)
+		if h.CostBasis == nil {
+			pos.CostBasis.Valid = false
+		} else if pos.CostBasis.Valid {
+			pos.CostBasis.Decimal = pos.CostBasis.Decimal.Add(decimal.NewFromFloat(*h.CostBasis))
+		}
+	}
+
+	return snap
+}
@@ -0,0 +1,79 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestPlaidFetch(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/plaid-holdings-response.json")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/investments/holdings/get" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["access_token"] != "access-token" || body["client_id"] != "client" {
+			t.Errorf("body = %v", body)
+		}
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	p, err := NewPlaid("sandbox", "client", "secret", "access-token")
+	if err != nil {
+		t.Fatalf("NewPlaid: %v", err)
+	}
+	p.baseURL = server.URL
+
+	snap, err := p.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	if len(snap.Positions) != 2 {
+		t.Fatalf("got %d positions, want 2: %+v", len(snap.Positions), snap.Positions)
+	}
+	aapl := snap.Positions[0]
+	if aapl.Ticker != "AAPL" || !aapl.Quantity.Equal(decimal.NewFromInt(100)) || !aapl.CostBasis.Valid || !aapl.CostBasis.Decimal.Equal(decimal.NewFromInt(15025)) {
+		t.Errorf("AAPL = %+v", aapl)
+	}
+	brk := snap.Positions[1]
+	if brk.Ticker != "BRK-B" || brk.CostBasis.Valid {
+		t.Errorf("BRK-B = %+v", brk)
+	}
+	if !snap.Cash.Equal(decimal.RequireFromString("2023.45")) {
+		t.Errorf("Cash = %s, want 2023.45", snap.Cash)
+	}
+}
+
+func TestPlaidFetchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"error_code":"ITEM_LOGIN_REQUIRED","error_message":"login required"}`)
+	}))
+	defer server.Close()
+
+	p, _ := NewPlaid("sandbox", "c", "s", "t")
+	p.baseURL = server.URL
+	if _, err := p.Fetch(context.Background()); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestNewPlaidUnknownEnv(t *testing.T) {
+	if _, err := NewPlaid("staging", "c", "s", "t"); err == nil {
+		t.Error("expected error for unknown environment")
+	}
+}
@@ -0,0 +1,120 @@
+package broker
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// Recorded is a holding as recorded in the app.
+type Recorded struct {
+	Ticker   string
+	Quantity decimal.Decimal
+	AvgCost  decimal.Decimal
+}
+
+// Discrepancy describes one difference between recorded and broker data.
+type Discrepancy struct {
+	Ticker  string // Empty for cash
+	Message string
+	Fix     *Correction // Suggested change to the records, nil if none
+}
+
+// Correction is a change that brings a recorded holding in line with the
+// broker: set it to Quantity shares at AvgCost, or remove it.
+type Correction struct {
+	Ticker   string
+	Quantity decimal.Decimal
+	AvgCost  decimal.Decimal
+	Remove   bool
+}
+
+// String describes the correction, as in "set to 60 shares @ $300.00".
+func (c Correction) String() string {
+	if c.Remove {
+		return "remove holding"
+	}
+	return fmt.Sprintf("set to %s shares @ $%s", c.Quantity, c.AvgCost.StringFixed(2))
+}
+
+// CostTolerance is the relative difference in average cost that is ignored,
+// since brokers often round cost basis or adjust it for wash sales.
+var CostTolerance = decimal.NewFromFloat(0.01)
+
+// CashTolerance is the absolute cash difference that is ignored.
+var CashTolerance = decimal.NewFromInt(1)
+
+// Reconcile compares recorded holdings and cash with a broker snapshot and
+// returns the differences, sorted by ticker with cash last. Nothing is modified.
+func Reconcile(recorded []Recorded, recordedCash decimal.Decimal, snap *Snapshot) []Discrepancy {
+	out := ReconcilePositions(recorded, snap.Positions)
+	if snap.Cash.Sub(recordedCash).Abs().GreaterThan(CashTolerance) {
+		out = append(out, Discrepancy{Message: fmt.Sprintf("Cash: recorded $%s, broker reports $%s",
+			recordedCash.StringFixed(2), snap.Cash.StringFixed(2))})
+	}
+	return out
+}
+
+// ReconcilePositions compares recorded holdings with broker positions and
+// returns the differences sorted by ticker, each with the correction that
+// would make the records match the broker. A position the broker reports
+// without a cost basis keeps the recorded average cost; one that is not
+// recorded gets no correction, as its cost is unknown.
+func ReconcilePositions(recorded []Recorded, positions []Position) []Discrepancy {
+	var out []Discrepancy
+
+	brokerPositions := make(map[string]Position)
+	for _, p := range positions {
+		brokerPositions[p.Ticker] = p
+	}
+	recordedTickers := make(map[string]bool)
+
+	for _, r := range recorded {
+		recordedTickers[r.Ticker] = true
+		p, ok := brokerPositions[r.Ticker]
+		if !ok {
+			out = append(out, Discrepancy{r.Ticker, fmt.Sprintf("%s: recorded %s shares, not held at broker", r.Ticker, r.Quantity),
+				&Correction{Ticker: r.Ticker, Remove: true}})
+			continue
+		}
+		brokerAvg, hasCost := avgCost(p)
+		fix := &Correction{Ticker: r.Ticker, Quantity: p.Quantity, AvgCost: r.AvgCost}
+		if hasCost {
+			fix.AvgCost = brokerAvg
+		}
+		if !p.Quantity.Equal(r.Quantity) {
+			out = append(out, Discrepancy{r.Ticker, fmt.Sprintf("%s: recorded %s shares, broker reports %s", r.Ticker, r.Quantity, p.Quantity), fix})
+			continue
+		}
+		if hasCost && r.AvgCost.IsPositive() {
+			diff := brokerAvg.Sub(r.AvgCost).Abs().Div(r.AvgCost)
+			if diff.GreaterThan(CostTolerance) {
+				out = append(out, Discrepancy{r.Ticker, fmt.Sprintf("%s: recorded avg cost $%s, broker reports $%s",
+					r.Ticker, r.AvgCost.StringFixed(2), brokerAvg.StringFixed(2)), fix})
+			}
+		}
+	}
+
+	for _, p := range positions {
+		if recordedTickers[p.Ticker] {
+			continue
+		}
+		disc := Discrepancy{Ticker: p.Ticker, Message: fmt.Sprintf("%s: broker reports %s shares, not recorded", p.Ticker, p.Quantity)}
+		if brokerAvg, ok := avgCost(p); ok {
+			disc.Fix = &Correction{Ticker: p.Ticker, Quantity: p.Quantity, AvgCost: brokerAvg}
+		}
+		out = append(out, disc)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Ticker < out[j].Ticker })
+	return out
+}
+
+// avgCost is the broker's average cost per share, if it reports a cost basis
+func avgCost(p Position) (decimal.Decimal, bool) {
+	if !p.CostBasis.Valid || !p.Quantity.IsPositive() {
+		return decimal.Zero, false
+	}
+	return p.CostBasis.Decimal.Div(p.Quantity), true
+}
@@ -0,0 +1,78 @@
+package broker
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func d(s string) decimal.Decimal { return decimal.RequireFromString(s) }
+
+func TestReconcile(t *testing.T) {
+	recorded := []Recorded{
+		{Ticker: "AAPL", Quantity: d("100"), AvgCost: d("150")}, // matches within tolerance
+		{Ticker: "MSFT", Quantity: d("50"), AvgCost: d("300")},  // quantity differs
+		{Ticker: "TSLA", Quantity: d("10"), AvgCost: d("200")},  // cost differs
+		{Ticker: "AMD", Quantity: d("5"), AvgCost: d("100")},    // not at broker
+	}
+	snap := &Snapshot{
+		Positions: []Position{
+			{Ticker: "AAPL", Quantity: d("100"), CostBasis: decimal.NullDecimal{Decimal: d("15025"), Valid: true}},
+			{Ticker: "MSFT", Quantity: d("60"), CostBasis: decimal.NullDecimal{Decimal: d("18000"), Valid: true}},
+			{Ticker: "TSLA", Quantity: d("10"), CostBasis: decimal.NullDecimal{Decimal: d("2500"), Valid: true}},
+			{Ticker: "NVDA", Quantity: d("3")}, // not recorded
+		},
+		Cash: d("1000.50"),
+	}
+
+	got := Reconcile(recorded, d("1000"), snap)
+	want := []string{"AMD", "MSFT", "NVDA", "TSLA"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d discrepancies, want %d: %+v", len(got), len(want), got)
+	}
+	for i, ticker := range want {
+		if got[i].Ticker != ticker {
+			t.Errorf("discrepancy %d ticker = %q, want %q", i, got[i].Ticker, ticker)
+		}
+	}
+
+	got = Reconcile(recorded[:1], d("900"), &Snapshot{Positions: snap.Positions[:1], Cash: d("1000")})
+	if len(got) != 1 || got[0].Ticker != "" {
+		t.Errorf("expected a single cash discrepancy, got %+v", got)
+	}
+}
+
+func TestReconcilePositionsCorrections(t *testing.T) {
+	recorded := []Recorded{
+		{Ticker: "MSFT", Quantity: d("50"), AvgCost: d("300")},
+		{Ticker: "TSLA", Quantity: d("10"), AvgCost: d("200")},
+		{Ticker: "AMD", Quantity: d("5"), AvgCost: d("100")},
+	}
+	positions := []Position{
+		{Ticker: "MSFT", Quantity: d("60")}, // No cost basis: keep the recorded cost
+		{Ticker: "TSLA", Quantity: d("10"), CostBasis: decimal.NullDecimal{Decimal: d("2500"), Valid: true}},
+		{Ticker: "NVDA", Quantity: d("4"), CostBasis: decimal.NullDecimal{Decimal: d("480"), Valid: true}},
+		{Ticker: "SPY", Quantity: d("1")},
+	}
+
+	got := ReconcilePositions(recorded, positions)
+	want := map[string]string{
+		"AMD":  "remove holding",
+		"MSFT": "set to 60 shares @ $300.00",
+		"NVDA": "set to 4 shares @ $120.00",
+		"TSLA": "set to 10 shares @ $250.00",
+		"SPY":  "",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d discrepancies, want %d: %+v", len(got), len(want), got)
+	}
+	for _, disc := range got {
+		fix := ""
+		if disc.Fix != nil {
+			fix = disc.Fix.String()
+		}
+		if fix != want[disc.Ticker] {
+			t.Errorf("%s correction = %q, want %q", disc.Ticker, fix, want[disc.Ticker])
+		}
+	}
+}
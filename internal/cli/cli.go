@@ -0,0 +1,256 @@
+// Package cli describes the command-line interface and generates shell
+// completions and a man page from that description.
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Command is a subcommand.
+type Command struct {
+	Name        string
+	Summary     string   // One line, used in completions and usage
+	Description string   // Paragraph for the man page
+	Args        []string // Fixed argument choices offered by completion
+}
+
+// Flag is an option of the TUI itself, such as --demo.
+type Flag struct {
+	Name    string // With its dashes
+	Summary string // One line, used in completions, usage, and the man page
+}
+
+// EnvVar documents an environment variable read by the program.
+type EnvVar struct {
+	Name        string
+	Description string
+}
+
+// Spec is the full CLI description.
+type Spec struct {
+	Name        string
+	Summary     string
+	Description string
+	Commands    []Command
+	Flags       []Flag
+	Env         []EnvVar
+}
+
+// Lookup returns the command with the given name.
+func (s Spec) Lookup(name string) (Command, bool) {
+	for _, c := range s.Commands {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Command{}, false
+}
+
+// LookupFlag returns the flag with the given name.
+func (s Spec) LookupFlag(name string) (Flag, bool) {
+	for _, f := range s.Flags {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Flag{}, false
+}
+
+// Usage returns short help text.
+func (s Spec) Usage() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s - %s\n\nUsage:\n", s.Name, s.Summary)
+
+	width := 0
+	for _, c := range s.Commands {
+		width = max(width, len(commandSynopsis(c)))
+	}
+	for _, f := range s.Flags {
+		width = max(width, len(f.Name))
+	}
+	fmt.Fprintf(&sb, "  %s %-*s  %s\n", s.Name, width, "", "start the TUI")
+	for _, f := range s.Flags {
+		fmt.Fprintf(&sb, "  %s %-*s  %s\n", s.Name, width, f.Name, f.Summary)
+	}
+	for _, c := range s.Commands {
+		fmt.Fprintf(&sb, "  %s %-*s  %s\n", s.Name, width, commandSynopsis(c), c.Summary)
+	}
+	return sb.String()
+}
+
+func commandSynopsis(c Command) string {
+	if len(c.Args) == 0 {
+		return c.Name
+	}
+	return c.Name + " " + strings.Join(c.Args, "|")
+}
+
+// Bash returns a bash completion script.
+func (s Spec) Bash() string {
+	fn := "_" + identifier(s.Name)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# bash completion for %s\n", s.Name)
+	fmt.Fprintf(&sb, "%s() {\n", fn)
+	sb.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	sb.WriteString("    if [[ $COMP_CWORD -eq 1 ]]; then\n")
+	fmt.Fprintf(&sb, "        COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(append(s.commandNames(), s.flagNames()...), " "))
+	sb.WriteString("        return\n")
+	sb.WriteString("    fi\n")
+	sb.WriteString("    case \"${COMP_WORDS[1]}\" in\n")
+	for _, c := range s.Commands {
+		if len(c.Args) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "        %s) [[ $COMP_CWORD -eq 2 ]] && COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n", c.Name, strings.Join(c.Args, " "))
+	}
+	sb.WriteString("    esac\n")
+	sb.WriteString("}\n")
+	fmt.Fprintf(&sb, "complete -F %s %s\n", fn, s.Name)
+	return sb.String()
+}
+
+// Zsh returns a zsh completion script.
+func (s Spec) Zsh() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "#compdef %s\n\n", s.Name)
+	fmt.Fprintf(&sb, "_%s() {\n", identifier(s.Name))
+	sb.WriteString("    local -a commands\n")
+	sb.WriteString("    commands=(\n")
+	for _, c := range s.Commands {
+		fmt.Fprintf(&sb, "        '%s:%s'\n", c.Name, zshEscape(c.Summary))
+	}
+	for _, f := range s.Flags {
+		fmt.Fprintf(&sb, "        '%s:%s'\n", f.Name, zshEscape(f.Summary))
+	}
+	sb.WriteString("    )\n")
+	sb.WriteString("    if (( CURRENT == 2 )); then\n")
+	sb.WriteString("        _describe 'command' commands\n")
+	sb.WriteString("        return\n")
+	sb.WriteString("    fi\n")
+	sb.WriteString("    case $words[2] in\n")
+	for _, c := range s.Commands {
+		if len(c.Args) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "        %s) (( CURRENT == 3 )) && compadd %s ;;\n", c.Name, strings.Join(c.Args, " "))
+	}
+	sb.WriteString("    esac\n")
+	sb.WriteString("}\n\n")
+	fmt.Fprintf(&sb, "_%s \"$@\"\n", identifier(s.Name))
+	return sb.String()
+}
+
+// Fish returns a fish completion script.
+func (s Spec) Fish() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# fish completion for %s\n", s.Name)
+	fmt.Fprintf(&sb, "complete -c %s -f\n", s.Name)
+	noCommand := "not __fish_seen_subcommand_from " + strings.Join(s.commandNames(), " ")
+	for _, c := range s.Commands {
+		fmt.Fprintf(&sb, "complete -c %s -n '%s' -a %s -d '%s'\n", s.Name, noCommand, c.Name, fishEscape(c.Summary))
+	}
+	for _, f := range s.Flags {
+		fmt.Fprintf(&sb, "complete -c %s -n '%s' -l %s -d '%s'\n", s.Name, noCommand, strings.TrimLeft(f.Name, "-"), fishEscape(f.Summary))
+	}
+	for _, c := range s.Commands {
+		if len(c.Args) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "complete -c %s -n '__fish_seen_subcommand_from %s' -a '%s'\n", s.Name, c.Name, strings.Join(c.Args, " "))
+	}
+	return sb.String()
+}
+
+// Man returns a man page in roff format, dated with date.
+func (s Spec) Man(date time.Time) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, ".TH %s 1 %q %q %q\n", strings.ToUpper(s.Name), date.Format("2006-01-02"), s.Name, "User Commands")
+	sb.WriteString(".SH NAME\n")
+	fmt.Fprintf(&sb, "%s \\- %s\n", s.Name, roffEscape(s.Summary))
+	sb.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(&sb, ".B %s\n", s.Name)
+	for _, f := range s.Flags {
+		fmt.Fprintf(&sb, ".RB [ %s ]\n", roffEscape(f.Name))
+	}
+	for _, c := range s.Commands {
+		sb.WriteString(".br\n")
+		fmt.Fprintf(&sb, ".B %s %s\n", s.Name, c.Name)
+		if len(c.Args) > 0 {
+			fmt.Fprintf(&sb, ".RI { %s }\n", strings.Join(c.Args, " | "))
+		}
+	}
+	sb.WriteString(".SH DESCRIPTION\n")
+	sb.WriteString(roffEscape(s.Description) + "\n")
+	sb.WriteString(".SH COMMANDS\n")
+	for _, c := range s.Commands {
+		sb.WriteString(".TP\n")
+		fmt.Fprintf(&sb, ".B %s\n", c.Name)
+		desc := c.Description
+		if desc == "" {
+			desc = c.Summary
+		}
+		sb.WriteString(roffEscape(desc) + "\n")
+	}
+	if len(s.Flags) > 0 {
+		sb.WriteString(".SH OPTIONS\n")
+		for _, f := range s.Flags {
+			sb.WriteString(".TP\n")
+			fmt.Fprintf(&sb, ".B %s\n", roffEscape(f.Name))
+			sb.WriteString(roffEscape(f.Summary) + "\n")
+		}
+	}
+	if len(s.Env) > 0 {
+		sb.WriteString(".SH ENVIRONMENT\n")
+		for _, e := range s.Env {
+			sb.WriteString(".TP\n")
+			fmt.Fprintf(&sb, ".B %s\n", e.Name)
+			sb.WriteString(roffEscape(e.Description) + "\n")
+		}
+	}
+	return sb.String()
+}
+
+func (s Spec) commandNames() []string {
+	names := make([]string, len(s.Commands))
+	for i, c := range s.Commands {
+		names[i] = c.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s Spec) flagNames() []string {
+	names := make([]string, len(s.Flags))
+	for i, f := range s.Flags {
+		names[i] = f.Name
+	}
+	return names
+}
+
+func identifier(name string) string {
+	return strings.NewReplacer("-", "_", ".", "_").Replace(name)
+}
+
+func zshEscape(s string) string {
+	return strings.NewReplacer("'", "'\\''", ":", "\\:").Replace(s)
+}
+
+func fishEscape(s string) string {
+	return strings.ReplaceAll(s, "'", "\\'")
+}
+
+// roffEscape escapes backslashes and leading dots/quotes that roff treats as requests
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\e")
+	s = strings.ReplaceAll(s, "-", "\\-")
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, ".") || strings.HasPrefix(l, "'") {
+			lines[i] = "\\&" + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSpec = Spec{
+	Name:        "tool",
+	Summary:     "a test tool",
+	Description: "Does things.\n.dot line",
+	Commands: []Command{
+		{Name: "serve", Summary: "run the server", Description: "Runs the HTTP server."},
+		{Name: "completion", Summary: "print a completion script", Args: []string{"bash", "zsh", "fish"}},
+	},
+	Flags: []Flag{{Name: "--demo", Summary: "start with sample data"}},
+	Env:   []EnvVar{{Name: "TOOL_ADDR", Description: "listen address"}},
+}
+
+func TestBash(t *testing.T) {
+	out := testSpec.Bash()
+	for _, want := range []string{
+		`compgen -W "completion serve --demo"`,
+		`completion) [[ $COMP_CWORD -eq 2 ]] && COMPREPLY=($(compgen -W "bash zsh fish"`,
+		"complete -F _tool tool",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("bash completion missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestZsh(t *testing.T) {
+	out := testSpec.Zsh()
+	if !strings.HasPrefix(out, "#compdef tool\n") {
+		t.Errorf("missing #compdef header:\n%s", out)
+	}
+	if !strings.Contains(out, "'serve:run the server'") || !strings.Contains(out, "'--demo:start with sample data'") ||
+		!strings.Contains(out, "compadd bash zsh fish") {
+		t.Errorf("zsh completion incomplete:\n%s", out)
+	}
+}
+
+func TestFish(t *testing.T) {
+	out := testSpec.Fish()
+	if !strings.Contains(out, "-a serve -d 'run the server'") {
+		t.Errorf("fish completion missing serve:\n%s", out)
+	}
+	if !strings.Contains(out, "-l demo -d 'start with sample data'") {
+		t.Errorf("fish completion missing --demo:\n%s", out)
+	}
+	if !strings.Contains(out, "__fish_seen_subcommand_from completion' -a 'bash zsh fish'") {
+		t.Errorf("fish completion missing shells:\n%s", out)
+	}
+}
+
+func TestMan(t *testing.T) {
+	out := testSpec.Man(time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC))
+	for _, want := range []string{
+		`.TH TOOL 1 "2026-10-15" "tool" "User Commands"`,
+		"tool \\- a test tool",
+		".B tool completion\n.RI { bash | zsh | fish }",
+		"\\&.dot line",
+		".RB [ \\-\\-demo ]",
+		".SH OPTIONS\n.TP\n.B \\-\\-demo\nstart with sample data",
+		".B TOOL_ADDR\nlisten address",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("man page missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestUsage(t *testing.T) {
+	out := testSpec.Usage()
+	if !strings.Contains(out, "tool completion bash|zsh|fish  print a completion script") {
+		t.Errorf("usage:\n%s", out)
+	}
+	if !strings.Contains(out, "tool --demo                    start with sample data") {
+		t.Errorf("usage missing --demo:\n%s", out)
+	}
+	if _, ok := testSpec.Lookup("serve"); !ok {
+		t.Error("Lookup(serve) failed")
+	}
+	if _, ok := testSpec.LookupFlag("--demo"); !ok {
+		t.Error("LookupFlag(--demo) failed")
+	}
+}
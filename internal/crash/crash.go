@@ -0,0 +1,27 @@
+// Package crash writes panic reports to disk.
+package crash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// WriteReport writes a report for a recovered panic to dir and returns its
+// path. where names the handler or worker that panicked.
+func WriteReport(dir, where string, value any, stack []byte, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("crash-%s.txt", now.Format("20060102-150405")))
+
+	report := fmt.Sprintf("anyhowhodl crash report\n\nTime:    %s\nWhere:   %s\nPanic:   %v\nGo:      %s %s/%s\nArgs:    %v\n\n%s",
+		now.Format(time.RFC3339), where, value, runtime.Version(), runtime.GOOS, runtime.GOARCH, os.Args, stack)
+
+	if err := os.WriteFile(path, []byte(report), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,32 @@
+package crash
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteReport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	now := time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)
+
+	path, err := WriteReport(dir, "csp refresh", "index out of range", []byte("goroutine 1 [running]:\nmain.main()"), now)
+	if err != nil {
+		t.Fatalf("WriteReport: %v", err)
+	}
+	if filepath.Base(path) != "crash-20261015-093000.txt" {
+		t.Errorf("path = %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading report: %v", err)
+	}
+	for _, want := range []string{"Where:   csp refresh", "Panic:   index out of range", "goroutine 1 [running]"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("report missing %q:\n%s", want, data)
+		}
+	}
+}
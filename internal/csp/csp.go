@@ -28,6 +28,7 @@ const (
 // SignalInput holds raw data for CSP score computation.
 type SignalInput struct {
 	VIX             float64
+	VIXHistory      []float64 // Daily VIX closes the percentile is taken over
 	CurrentIV       float64
 	IVHigh52w       float64
 	IVLow52w        float64
@@ -48,10 +49,12 @@ type SignalOutput struct {
 	PremiumYieldScore float64
 	CompositeScore    float64
 	RawVIX            float64
+	RawVIXPercentile  float64 // NaN without VIX history
 	RawIVRank         float64
 	RawRSI            float64
 	RawPutCallRatio   float64
 	RawPremiumYield   float64
+	Scores            []SignalScore // Every signal computed, in order
 	Signal            string
 }
 
@@ -97,6 +100,30 @@ func ScoreVIX(vix float64) float64 {
 	return linearInterp(vix, 15, 20, 30, 0, 50, 100)
 }
 
+// ScoreVIXPercentile scores VIX by its percentile over its history:
+// 25→0, 50→50, 85→100 (capped), which puts the long-run levels of 15, 20,
+// and 30 about where ScoreVIX does.
+func ScoreVIXPercentile(percentile float64) float64 {
+	return linearInterp(percentile, 25, 50, 85, 0, 50, 100)
+}
+
+// VIXPercentile is the percentile of vix among the history closes: the share
+// below it, with ties counting half. Returns NaN without history.
+func VIXPercentile(vix float64, history []float64) float64 {
+	if len(history) == 0 {
+		return math.NaN()
+	}
+	var below float64
+	for _, c := range history {
+		if c < vix {
+			below++
+		} else if c == vix {
+			below += 0.5
+		}
+	}
+	return below / float64(len(history)) * 100
+}
+
 // ScoreIVRank scores IV Rank (0-100): 0→0, 50→50, 100→100 (linear).
 func ScoreIVRank(ivRank float64) float64 {
 	return linearInterp(ivRank, 0, 50, 100, 0, 50, 100)
@@ -194,12 +221,65 @@ func CalculateDelta(S, K, iv float64, dte int) float64 {
 		return 0
 	}
 	t := float64(dte) / 365.0
-	sqrtT := math.Sqrt(t)
+	return putDelta(S, K, iv, t, math.Sqrt(t))
+}
+
+// putDelta is CalculateDelta with time to expiry (in years) and its square
+// root precomputed, so callers scoring many strikes of one expiry pay for them
+// once. Inputs must already be positive.
+func putDelta(S, K, iv, t, sqrtT float64) float64 {
 	d1 := (math.Log(S/K) + (RiskFreeRate+iv*iv/2)*t) / (iv * sqrtT)
 	// Put delta = -N(-d1) = -(1 - N(d1)) = N(d1) - 1
 	return normCDF(d1) - 1
 }
 
+// CalculateTheta computes Black-Scholes theta per share per calendar day for
+// a "CALL" or "PUT". It is negative: the value a holder loses, and a writer
+// gains, each day with the price and volatility unchanged.
+func CalculateTheta(optionType string, S, K, iv float64, dte int) float64 {
+	if iv <= 0 || dte <= 0 || S <= 0 || K <= 0 {
+		return 0
+	}
+	t := float64(dte) / 365.0
+	sqrtT := math.Sqrt(t)
+	d1 := (math.Log(S/K) + (RiskFreeRate+iv*iv/2)*t) / (iv * sqrtT)
+	d2 := d1 - iv*sqrtT
+	decay := -S * normPDF(d1) * iv / (2 * sqrtT)
+	discounted := RiskFreeRate * K * math.Exp(-RiskFreeRate*t)
+	if optionType == "CALL" {
+		return (decay - discounted*normCDF(d2)) / 365
+	}
+	return (decay + discounted*normCDF(-d2)) / 365
+}
+
+// CalculatePrice computes the Black-Scholes value per share of a "CALL" or
+// "PUT". Without time or volatility left it is the intrinsic value.
+func CalculatePrice(optionType string, S, K, iv float64, dte int) float64 {
+	if S <= 0 || K <= 0 {
+		return 0
+	}
+	if iv <= 0 || dte <= 0 {
+		if optionType == "CALL" {
+			return math.Max(S-K, 0)
+		}
+		return math.Max(K-S, 0)
+	}
+	t := float64(dte) / 365.0
+	sqrtT := math.Sqrt(t)
+	d1 := (math.Log(S/K) + (RiskFreeRate+iv*iv/2)*t) / (iv * sqrtT)
+	d2 := d1 - iv*sqrtT
+	discounted := K * math.Exp(-RiskFreeRate*t)
+	if optionType == "CALL" {
+		return S*normCDF(d1) - discounted*normCDF(d2)
+	}
+	return discounted*normCDF(-d2) - S*normCDF(-d1)
+}
+
+// normPDF computes the standard normal probability density function.
+func normPDF(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
+
 // normCDF computes the standard normal cumulative distribution function.
 func normCDF(x float64) float64 {
 	return 0.5 * math.Erfc(-x/math.Sqrt2)
@@ -209,6 +289,14 @@ func normCDF(x float64) float64 {
 // Delta is computed for each contract using the underlying price.
 func FilterContracts(contracts []OptionContract, underlyingPrice float64) []OptionContract {
 	var result []OptionContract
+	now := time.Now()
+
+	// Chains list contracts grouped by expiry, so time to expiry is only
+	// recomputed when the expiration changes
+	lastExpiration := int64(math.MinInt64)
+	var dte int
+	var t, sqrtT float64
+
 	for _, c := range contracts {
 		if c.Volume < MinVolume {
 			continue
@@ -227,8 +315,17 @@ func FilterContracts(contracts []OptionContract, underlyingPrice float64) []Opti
 		if spread > MaxBidAskSpread {
 			continue
 		}
-		dte := daysUntil(c.Expiration)
-		delta := CalculateDelta(underlyingPrice, c.Strike, c.ImpliedVolatility, dte)
+		if c.Expiration != lastExpiration {
+			lastExpiration = c.Expiration
+			dte = daysBetween(now, c.Expiration)
+			t = float64(dte) / 365.0
+			sqrtT = math.Sqrt(t)
+		}
+		// Same guard as CalculateDelta: a zero delta never passes the band
+		if c.ImpliedVolatility <= 0 || dte <= 0 || underlyingPrice <= 0 || c.Strike <= 0 {
+			continue
+		}
+		delta := putDelta(underlyingPrice, c.Strike, c.ImpliedVolatility, t, sqrtT)
 		if delta < MinDelta || delta > MaxDelta {
 			continue
 		}
@@ -243,29 +340,26 @@ func FilterContracts(contracts []OptionContract, underlyingPrice float64) []Opti
 // 2. Filter contracts for that expiry
 // 3. Pick the one closest to ATM (nearest strike to underlying)
 func SelectTargetContract(chain OptionsData) *OptionContract {
-	now := time.Now()
+	return SelectTargetPut(chain, SelectTargetExpiry(chain.ExpirationDates, CycleAll, time.Now()))
+}
 
-	// Find best expiry: closest to 30 DTE within [21, 45]
-	bestExpiry := int64(0)
-	bestDist := math.MaxFloat64
-	for _, exp := range chain.ExpirationDates {
-		expTime := time.Unix(exp, 0)
-		dte := expTime.Sub(now).Hours() / 24
-		if dte < 21 || dte > 45 {
-			continue
-		}
-		dist := math.Abs(dte - 30)
-		if dist < bestDist {
-			bestDist = dist
-			bestExpiry = exp
-		}
-	}
+// SelectTargetPut picks, among the chain's puts expiring at bestExpiry that
+// pass the quality filters, the one closest to ATM. It returns nil when
+// bestExpiry is zero or no put qualifies.
+func SelectTargetPut(chain OptionsData, bestExpiry int64) *OptionContract {
 	if bestExpiry == 0 {
 		return nil
 	}
 
-	// Get puts for this expiry
-	var expiryPuts []OptionContract
+	// Get puts for this expiry, sized up front since chains can hold
+	// thousands of puts across expiries
+	n := 0
+	for _, p := range chain.Puts {
+		if p.Expiration == bestExpiry {
+			n++
+		}
+	}
+	expiryPuts := make([]OptionContract, 0, n)
 	for _, p := range chain.Puts {
 		if p.Expiration == bestExpiry {
 			expiryPuts = append(expiryPuts, p)
@@ -291,74 +385,49 @@ func SelectTargetContract(chain OptionsData) *OptionContract {
 	return best
 }
 
-// ComputeSignals calculates all signal scores and the composite score.
-// If a signal is NaN, remaining signals are re-weighted proportionally.
+// ComputeSignals calculates the built-in signal scores and the composite score.
 func ComputeSignals(input SignalInput) SignalOutput {
-	out := SignalOutput{
-		RawVIX: input.VIX,
-	}
-
-	// Raw calculations
-	ivRank := CalculateIVRank(input.CurrentIV, input.IVLow52w, input.IVHigh52w)
-	rsi := CalculateRSI(input.ClosingPrices)
-	pcr := 0.0
-	if input.TotalCallVolume > 0 {
-		pcr = input.TotalPutVolume / input.TotalCallVolume
-	}
-	premYield := CalculatePremiumYield(input.PutPremium, input.StrikePrice, input.DTE)
-
-	out.RawIVRank = ivRank
-	out.RawRSI = rsi
-	out.RawPutCallRatio = pcr
-	out.RawPremiumYield = premYield
-
-	// Score each signal
-	vixScore := ScoreVIX(input.VIX)
-	out.VIXScore = vixScore
-
-	var ivRankScore float64
-	if math.IsNaN(ivRank) {
-		ivRankScore = math.NaN()
-	} else {
-		ivRankScore = ScoreIVRank(ivRank)
-	}
-	out.IVRankScore = ivRankScore
-
-	var rsiScore float64
-	if math.IsNaN(rsi) {
-		rsiScore = math.NaN()
-	} else {
-		rsiScore = ScoreRSI(rsi)
-	}
-	out.RSIScore = rsiScore
-
-	pcrScore := ScorePutCallRatio(pcr)
-	out.PutCallRatioScore = pcrScore
-
-	premScore := ScorePremiumYield(premYield)
-	out.PremiumYieldScore = premScore
+	return ComputeSignalsWith(input, BuiltinSignals())
+}
 
-	// Composite with NaN re-weighting
-	type weightedSignal struct {
-		weight float64
-		score  float64
-	}
-	signals := []weightedSignal{
-		{WeightVIX, vixScore},
-		{WeightIVRank, ivRankScore},
-		{WeightRSI, rsiScore},
-		{WeightPutCallRatio, pcrScore},
-		{WeightPremiumYield, premScore},
+// ComputeSignalsWith calculates each signal's score and their weighted
+// composite. If a signal is NaN, remaining signals are re-weighted
+// proportionally. The built-in signals also fill their named fields.
+func ComputeSignalsWith(input SignalInput, signals []Signal) SignalOutput {
+	vars := signalVars(input)
+	out := SignalOutput{
+		RawVIX:           vars["vix"],
+		RawVIXPercentile: vars["vix_pct"],
+		RawIVRank:        vars["iv_rank"],
+		RawRSI:           vars["rsi"],
+		RawPutCallRatio:  vars["pcr"],
+		RawPremiumYield:  vars["yield"],
 	}
 
 	totalWeight := 0.0
 	weightedSum := 0.0
 	for _, s := range signals {
-		if math.IsNaN(s.score) {
+		raw, score := s.Compute(vars)
+		out.Scores = append(out.Scores, SignalScore{Name: s.Name(), Weight: s.Weight(), Raw: raw, Score: score})
+		if _, ok := s.(builtinSignal); ok {
+			switch s.Name() {
+			case SignalVIX:
+				out.VIXScore = score
+			case SignalIVRank:
+				out.IVRankScore = score
+			case SignalRSI:
+				out.RSIScore = score
+			case SignalPutCallRatio:
+				out.PutCallRatioScore = score
+			case SignalPremiumYield:
+				out.PremiumYieldScore = score
+			}
+		}
+		if math.IsNaN(score) {
 			continue
 		}
-		totalWeight += s.weight
-		weightedSum += s.weight * s.score
+		totalWeight += s.Weight()
+		weightedSum += s.Weight() * score
 	}
 
 	if totalWeight > 0 {
@@ -378,9 +447,9 @@ func ComputeSignals(input SignalInput) SignalOutput {
 	return out
 }
 
-func daysUntil(unixTimestamp int64) int {
-	expTime := time.Unix(unixTimestamp, 0)
-	d := time.Until(expTime).Hours() / 24
+// daysBetween returns whole days from now until unixTimestamp, floored at zero.
+func daysBetween(now time.Time, unixTimestamp int64) int {
+	d := time.Unix(unixTimestamp, 0).Sub(now).Hours() / 24
 	if d < 0 {
 		return 0
 	}
@@ -0,0 +1,95 @@
+package csp
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// Run with: go test ./internal/csp -bench . -benchmem
+// Profile with: go test ./internal/csp -bench FilterContracts -cpuprofile cpu.out
+
+// benchChain builds a chain shaped like a liquid index underlying: expiries
+// weekly for a year, strikes every dollar from 50% to 150% of spot
+func benchChain(spot float64, expiries int) OptionsData {
+	now := time.Now()
+	chain := OptionsData{UnderlyingPrice: spot}
+	for e := 0; e < expiries; e++ {
+		exp := now.AddDate(0, 0, 7*(e+1)).Unix()
+		chain.ExpirationDates = append(chain.ExpirationDates, exp)
+		for k := spot * 0.5; k <= spot*1.5; k++ {
+			moneyness := math.Abs(k-spot) / spot
+			bid := math.Max(0.05, spot*0.03-moneyness*spot*0.05)
+			chain.Puts = append(chain.Puts, OptionContract{
+				Strike:            k,
+				Bid:               bid,
+				Ask:               bid * 1.08,
+				Volume:            int(500 * (1 - moneyness)),
+				OpenInterest:      int(2000 * (1 - moneyness)),
+				ImpliedVolatility: 0.22 + moneyness*0.3,
+				Expiration:        exp,
+			})
+		}
+	}
+	return chain
+}
+
+func benchCloses(n int) []float64 {
+	closes := make([]float64, n)
+	closes[0] = 100
+	for i := 1; i < n; i++ {
+		closes[i] = closes[i-1] * (1 + 0.01*math.Sin(float64(i)*0.7))
+	}
+	return closes
+}
+
+func BenchmarkFilterContracts(b *testing.B) {
+	for _, expiries := range []int{4, 52} {
+		chain := benchChain(500, expiries)
+		b.Run(fmt.Sprintf("contracts=%d", len(chain.Puts)), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				FilterContracts(chain.Puts, chain.UnderlyingPrice)
+			}
+		})
+	}
+}
+
+func BenchmarkSelectTargetContract(b *testing.B) {
+	chain := benchChain(500, 52)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		SelectTargetContract(chain)
+	}
+}
+
+func BenchmarkCalculateRSI(b *testing.B) {
+	for _, n := range []int{252, 2520} {
+		closes := benchCloses(n)
+		b.Run(fmt.Sprintf("closes=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				CalculateRSI(closes)
+			}
+		})
+	}
+}
+
+func BenchmarkComputeSignals(b *testing.B) {
+	input := SignalInput{
+		VIX:             21.5,
+		CurrentIV:       0.32,
+		IVHigh52w:       0.55,
+		IVLow52w:        0.18,
+		ClosingPrices:   benchCloses(252),
+		TotalPutVolume:  48000,
+		TotalCallVolume: 52000,
+		PutPremium:      4.10,
+		StrikePrice:     480,
+		DTE:             30,
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ComputeSignals(input)
+	}
+}
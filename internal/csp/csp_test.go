@@ -40,6 +40,31 @@ func TestScoreVIX(t *testing.T) {
 	}
 }
 
+func TestVIXPercentile(t *testing.T) {
+	history := []float64{12, 14, 16, 18, 20, 22, 25, 30}
+	tests := []struct {
+		name string
+		vix  float64
+		want float64
+	}{
+		{"below all", 10, 0},
+		{"above all", 40, 100},
+		{"between", 19, 50},
+		{"tie counts half", 20, 56.25},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := VIXPercentile(tc.vix, history)
+			if !approxEqual(got, tc.want) {
+				t.Errorf("VIXPercentile(%v) = %v, want %v", tc.vix, got, tc.want)
+			}
+		})
+	}
+	if got := VIXPercentile(20, nil); !math.IsNaN(got) {
+		t.Errorf("VIXPercentile without history = %v, want NaN", got)
+	}
+}
+
 func TestScoreIVRank(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -278,6 +303,46 @@ func TestCalculateDelta(t *testing.T) {
 	}
 }
 
+func TestCalculateTheta(t *testing.T) {
+	// At the money, 30% IV, 30 days: about -0.06 per share per day
+	put := CalculateTheta("PUT", 100, 100, 0.30, 30)
+	if put > -0.04 || put < -0.08 {
+		t.Errorf("CalculateTheta(PUT,100,100,0.30,30) = %v, expected in [-0.08,-0.04]", put)
+	}
+	// With a positive rate the call decays faster than the put
+	call := CalculateTheta("CALL", 100, 100, 0.30, 30)
+	if call >= put {
+		t.Errorf("call theta %v should be below put theta %v", call, put)
+	}
+	// Decay accelerates toward expiry
+	if near := CalculateTheta("PUT", 100, 100, 0.30, 5); near >= put {
+		t.Errorf("5 DTE theta %v should be below 30 DTE theta %v", near, put)
+	}
+	if got := CalculateTheta("PUT", 100, 100, 0, 30); got != 0 {
+		t.Errorf("theta without IV = %v, want 0", got)
+	}
+}
+
+func TestCalculatePrice(t *testing.T) {
+	// At the money, 30% IV, 30 days: about 3.4 per share
+	put := CalculatePrice("PUT", 100, 100, 0.30, 30)
+	if put < 3 || put > 3.8 {
+		t.Errorf("CalculatePrice(PUT,100,100,0.30,30) = %v, expected in [3,3.8]", put)
+	}
+	// Put-call parity: C - P = S - K·e^(-rT)
+	call := CalculatePrice("CALL", 100, 100, 0.30, 30)
+	parity := 100 - 100*math.Exp(-RiskFreeRate*30/365.0)
+	if math.Abs(call-put-parity) > 1e-9 {
+		t.Errorf("call - put = %v, want %v", call-put, parity)
+	}
+	if got := CalculatePrice("CALL", 110, 100, 0.30, 0); got != 10 {
+		t.Errorf("expired ITM call = %v, want intrinsic 10", got)
+	}
+	if got := CalculatePrice("PUT", 110, 100, 0, 30); got != 0 {
+		t.Errorf("OTM put without IV = %v, want 0", got)
+	}
+}
+
 // --- Filter and Select ---
 
 func TestFilterContracts(t *testing.T) {
@@ -316,6 +381,20 @@ func TestBidAskSpreadFilter(t *testing.T) {
 	}
 }
 
+func TestFilterContractsDeltaMatchesCalculateDelta(t *testing.T) {
+	chain := benchChain(100, 6)
+	filtered := FilterContracts(chain.Puts, chain.UnderlyingPrice)
+	if len(filtered) == 0 {
+		t.Fatal("FilterContracts returned 0 contracts")
+	}
+	for _, c := range filtered {
+		want := CalculateDelta(chain.UnderlyingPrice, c.Strike, c.ImpliedVolatility, daysBetween(time.Now(), c.Expiration))
+		if c.Delta != want {
+			t.Errorf("strike %v expiry %d: delta %v, want %v", c.Strike, c.Expiration, c.Delta, want)
+		}
+	}
+}
+
 func TestSelectTargetContract(t *testing.T) {
 	now := time.Now()
 	exp30 := now.AddDate(0, 0, 30).Unix()
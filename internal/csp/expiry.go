@@ -0,0 +1,140 @@
+package csp
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// ExpiryCycle selects which listed expirations the advisor may target.
+// Index ETFs like SPY, QQQ, and IWM list several expirations a week, so a
+// ticker can be held to its weeklies or its standard monthlies.
+type ExpiryCycle string
+
+const (
+	CycleAll     ExpiryCycle = "ALL"     // Every listed expiration
+	CycleWeekly  ExpiryCycle = "WEEKLY"  // The last expiration of each week without a monthly
+	CycleMonthly ExpiryCycle = "MONTHLY" // The last expiration of the week of the third Friday
+)
+
+// ExpiryCycles lists the cycles in the order they are offered.
+var ExpiryCycles = []ExpiryCycle{CycleAll, CycleWeekly, CycleMonthly}
+
+// Target window for the expiry, in days to expiration. Monthlies are up to
+// five weeks apart, so their window is wide enough to always hold one.
+const (
+	TargetDTE     = 30
+	MinTargetDTE  = 21
+	MaxTargetDTE  = 45
+	MaxMonthlyDTE = MinTargetDTE + 35
+)
+
+// IVRangeBand is how far from spot, as a fraction, the strikes setting a
+// chain's IV range may be.
+const IVRangeBand = 0.20
+
+// ParseExpiryCycle parses a stored cycle. Empty text is CycleAll.
+func ParseExpiryCycle(text string) (ExpiryCycle, error) {
+	if text == "" {
+		return CycleAll, nil
+	}
+	c := ExpiryCycle(text)
+	if !slices.Contains(ExpiryCycles, c) {
+		return "", fmt.Errorf("expiry cycle %q is not ALL, WEEKLY, or MONTHLY", text)
+	}
+	return c, nil
+}
+
+// Next is the cycle after c in ExpiryCycles, wrapping around.
+func (c ExpiryCycle) Next() ExpiryCycle {
+	i := slices.Index(ExpiryCycles, c)
+	return ExpiryCycles[(i+1)%len(ExpiryCycles)]
+}
+
+// CycleExpirations returns the expirations in cycle, in the order given.
+// Weeks are told apart by the last expiration listed in them, so a holiday
+// that moves an expiry to Thursday is still that week's weekly or monthly,
+// and the daily expirations before it belong to neither.
+func CycleExpirations(expirations []int64, cycle ExpiryCycle) []int64 {
+	if cycle == CycleAll || cycle == "" {
+		return expirations
+	}
+
+	type week struct{ year, week int }
+	weekOf := func(exp int64) week {
+		y, w := time.Unix(exp, 0).UTC().ISOWeek()
+		return week{y, w}
+	}
+	weekEnd := make(map[week]int64)
+	for _, exp := range expirations {
+		if k := weekOf(exp); exp > weekEnd[k] {
+			weekEnd[k] = exp
+		}
+	}
+
+	var out []int64
+	for _, exp := range expirations {
+		if weekEnd[weekOf(exp)] != exp {
+			continue
+		}
+		if isMonthlyWeek(time.Unix(exp, 0).UTC()) == (cycle == CycleMonthly) {
+			out = append(out, exp)
+		}
+	}
+	return out
+}
+
+// isMonthlyWeek reports whether t's week holds the third Friday of the
+// month, when standard monthly options expire
+func isMonthlyWeek(t time.Time) bool {
+	friday := t.AddDate(0, 0, int(time.Friday-t.Weekday()))
+	return friday.Day() >= 15 && friday.Day() <= 21
+}
+
+// SelectTargetExpiry picks the expiration in cycle closest to TargetDTE
+// within the target window, or zero if none falls in it.
+func SelectTargetExpiry(expirations []int64, cycle ExpiryCycle, now time.Time) int64 {
+	maxDTE := float64(MaxTargetDTE)
+	if cycle == CycleMonthly {
+		maxDTE = MaxMonthlyDTE
+	}
+
+	bestExpiry := int64(0)
+	bestDist := math.MaxFloat64
+	for _, exp := range CycleExpirations(expirations, cycle) {
+		dte := time.Unix(exp, 0).Sub(now).Hours() / 24
+		if dte < MinTargetDTE || dte > maxDTE {
+			continue
+		}
+		if dist := math.Abs(dte - TargetDTE); dist < bestDist {
+			bestDist = dist
+			bestExpiry = exp
+		}
+	}
+	return bestExpiry
+}
+
+// PutIVRange is the lowest and highest implied volatility among the puts
+// expiring at expiry with strikes within IVRangeBand of spot. ETFs list
+// strikes a dollar apart far into the wings, where quotes are thin and
+// implied volatility runs away, so the wings are left out. ok is false when
+// no put in the band has an implied volatility.
+func PutIVRange(puts []OptionContract, expiry int64, spot float64) (low, high float64, ok bool) {
+	for _, p := range puts {
+		if p.Expiration != expiry || p.ImpliedVolatility <= 0 {
+			continue
+		}
+		if math.Abs(p.Strike-spot) > spot*IVRangeBand {
+			continue
+		}
+		if !ok || p.ImpliedVolatility < low {
+			low = p.ImpliedVolatility
+		}
+		if !ok || p.ImpliedVolatility > high {
+			high = p.ImpliedVolatility
+		}
+		ok = true
+	}
+	return low, high, ok
+}
@@ -0,0 +1,98 @@
+package csp
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// etfExpirations lists SPY-style expirations in March and April 2026:
+// Monday, Wednesday, and Friday dailies, with Good Friday (April 3) moving
+// that week's expiry to Thursday
+func etfExpirations() []int64 {
+	var exps []int64
+	for _, d := range []struct {
+		month time.Month
+		day   int
+	}{{3, 16}, {3, 18}, {3, 20}, {3, 23}, {3, 25}, {3, 27}, {4, 1}, {4, 2}, {4, 10}, {4, 17}} {
+		exps = append(exps, time.Date(2026, d.month, d.day, 0, 0, 0, 0, time.UTC).Unix())
+	}
+	return exps
+}
+
+func dates(exps []int64) []string {
+	var out []string
+	for _, exp := range exps {
+		out = append(out, time.Unix(exp, 0).UTC().Format("01-02"))
+	}
+	return out
+}
+
+func TestCycleExpirations(t *testing.T) {
+	exps := etfExpirations()
+	tests := []struct {
+		cycle ExpiryCycle
+		want  []string
+	}{
+		{CycleAll, dates(exps)},
+		{CycleWeekly, []string{"03-27", "04-02", "04-10"}},
+		{CycleMonthly, []string{"03-20", "04-17"}},
+	}
+	for _, tc := range tests {
+		if got := dates(CycleExpirations(exps, tc.cycle)); !slices.Equal(got, tc.want) {
+			t.Errorf("%s expirations = %v, want %v", tc.cycle, got, tc.want)
+		}
+	}
+}
+
+func TestSelectTargetExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		cycle ExpiryCycle
+		want  string
+	}{
+		{CycleAll, "04-01"},     // A daily exactly 30 days out
+		{CycleWeekly, "04-02"},  // The holiday-week Thursday, 31 days out
+		{CycleMonthly, "04-17"}, // 46 days out: March's is too close
+	}
+	for _, tc := range tests {
+		got := SelectTargetExpiry(etfExpirations(), tc.cycle, now)
+		if got == 0 || dates([]int64{got})[0] != tc.want {
+			t.Errorf("%s target = %v, want %s", tc.cycle, dates([]int64{got}), tc.want)
+		}
+	}
+
+	if got := SelectTargetExpiry(etfExpirations(), CycleAll, now.AddDate(0, 3, 0)); got != 0 {
+		t.Errorf("target with every expiry past = %v, want none", dates([]int64{got}))
+	}
+}
+
+func TestParseExpiryCycle(t *testing.T) {
+	if c, err := ParseExpiryCycle(""); err != nil || c != CycleAll {
+		t.Errorf("empty cycle = %q, %v; want ALL", c, err)
+	}
+	if c, err := ParseExpiryCycle("MONTHLY"); err != nil || c.Next() != CycleAll {
+		t.Errorf("MONTHLY = %q, %v; want it to wrap to ALL", c, err)
+	}
+	if _, err := ParseExpiryCycle("DAILY"); err == nil {
+		t.Error("DAILY parsed")
+	}
+}
+
+func TestPutIVRange(t *testing.T) {
+	exp := time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC).Unix()
+	puts := []OptionContract{
+		{Strike: 300, ImpliedVolatility: 1.40, Expiration: exp}, // Far wing
+		{Strike: 450, ImpliedVolatility: 0.24, Expiration: exp},
+		{Strike: 500, ImpliedVolatility: 0.18, Expiration: exp},
+		{Strike: 520, ImpliedVolatility: 0, Expiration: exp},        // No quote
+		{Strike: 500, ImpliedVolatility: 0.90, Expiration: exp + 1}, // Another expiry
+	}
+	low, high, ok := PutIVRange(puts, exp, 510)
+	if !ok || low != 0.18 || high != 0.24 {
+		t.Errorf("IV range = %v-%v (%v), want 0.18-0.24", low, high, ok)
+	}
+	if _, _, ok := PutIVRange(puts, exp+2, 510); ok {
+		t.Error("IV range found for an expiry without puts")
+	}
+}
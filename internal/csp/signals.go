@@ -0,0 +1,146 @@
+package csp
+
+import (
+	"fmt"
+	"math"
+
+	"anyhowhodl/internal/expr"
+)
+
+// Signal is one input to the composite CSP score. Compute reads the
+// variables named in SignalVars and returns the raw reading the score came
+// from and the score from 0 (poor time to sell puts) to 100 (good), or a NaN
+// score when it cannot be computed, which leaves the signal out and
+// re-weights the others.
+type Signal interface {
+	Name() string
+	Weight() float64
+	Compute(vars map[string]float64) (raw, score float64)
+}
+
+// SignalVars are the variables signals are computed from. A variable that
+// cannot be computed, such as rsi without enough history, is NaN.
+var SignalVars = []string{"vix", "vix_pct", "iv", "iv_rank", "rsi", "pcr", "yield", "premium", "strike", "dte"}
+
+// Names of the built-in signals
+const (
+	SignalVIX          = "VIX"
+	SignalIVRank       = "IV Rank"
+	SignalRSI          = "RSI"
+	SignalPutCallRatio = "P/C Ratio"
+	SignalPremiumYield = "Yield"
+)
+
+// builtinSignal scores one variable with a fixed curve
+type builtinSignal struct {
+	name     string
+	weight   float64
+	vari     string
+	score    func(float64) float64
+	fallback *builtinSignal // Scores instead when vari has no value
+}
+
+func (s builtinSignal) Name() string    { return s.name }
+func (s builtinSignal) Weight() float64 { return s.weight }
+
+func (s builtinSignal) Compute(vars map[string]float64) (raw, score float64) {
+	raw = vars[s.vari]
+	if math.IsNaN(raw) {
+		if s.fallback != nil {
+			return s.fallback.Compute(vars)
+		}
+		return raw, math.NaN()
+	}
+	return raw, s.score(raw)
+}
+
+// BuiltinSignals returns the advisor's own signals with their weights.
+func BuiltinSignals() []Signal {
+	return []Signal{
+		vixLevelSignal,
+		builtinSignal{name: SignalIVRank, weight: WeightIVRank, vari: "iv_rank", score: ScoreIVRank},
+		builtinSignal{name: SignalRSI, weight: WeightRSI, vari: "rsi", score: ScoreRSI},
+		builtinSignal{name: SignalPutCallRatio, weight: WeightPutCallRatio, vari: "pcr", score: ScorePutCallRatio},
+		builtinSignal{name: SignalPremiumYield, weight: WeightPremiumYield, vari: "yield", score: ScorePremiumYield},
+	}
+}
+
+var vixLevelSignal = builtinSignal{name: SignalVIX, weight: WeightVIX, vari: "vix", score: ScoreVIX}
+
+// PercentileVIXSignal scores VIX by its percentile over its history in place
+// of the fixed levels of the built-in VIX signal, falling back to them
+// without history.
+func PercentileVIXSignal() Signal {
+	return builtinSignal{name: SignalVIX, weight: WeightVIX, vari: "vix_pct", score: ScoreVIXPercentile, fallback: &vixLevelSignal}
+}
+
+// exprSignal is a user-declared signal scored by an expression over
+// SignalVars, clamped to [0, 100]
+type exprSignal struct {
+	name   string
+	weight float64
+	expr   *expr.Expr
+}
+
+// NewExprSignal parses a user-declared signal, e.g. "100 - rsi" to favour
+// oversold tickers. The weight counts alongside the built-in ones.
+func NewExprSignal(name string, weight float64, src string) (Signal, error) {
+	if name == "" {
+		return nil, fmt.Errorf("signal name is required")
+	}
+	if !(weight > 0) {
+		return nil, fmt.Errorf("signal weight must be positive")
+	}
+	e, err := expr.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.Check(SignalVars); err != nil {
+		return nil, err
+	}
+	return exprSignal{name: name, weight: weight, expr: e}, nil
+}
+
+func (s exprSignal) Name() string    { return s.name }
+func (s exprSignal) Weight() float64 { return s.weight }
+
+func (s exprSignal) Compute(vars map[string]float64) (raw, score float64) {
+	for _, v := range s.expr.Vars() {
+		if math.IsNaN(vars[v]) {
+			return math.NaN(), math.NaN()
+		}
+	}
+	raw, err := s.expr.Eval(vars)
+	if err != nil || math.IsNaN(raw) || math.IsInf(raw, 0) {
+		return math.NaN(), math.NaN()
+	}
+	return raw, math.Max(0, math.Min(100, raw))
+}
+
+// SignalScore is one signal's part in a composite score.
+type SignalScore struct {
+	Name   string
+	Weight float64
+	Raw    float64
+	Score  float64 // NaN when left out
+}
+
+// signalVars computes the variables signals read from the raw inputs
+func signalVars(input SignalInput) map[string]float64 {
+	pcr := 0.0
+	if input.TotalCallVolume > 0 {
+		pcr = input.TotalPutVolume / input.TotalCallVolume
+	}
+	return map[string]float64{
+		"vix":     input.VIX,
+		"vix_pct": VIXPercentile(input.VIX, input.VIXHistory),
+		"iv":      input.CurrentIV,
+		"iv_rank": CalculateIVRank(input.CurrentIV, input.IVLow52w, input.IVHigh52w),
+		"rsi":     CalculateRSI(input.ClosingPrices),
+		"pcr":     pcr,
+		"yield":   CalculatePremiumYield(input.PutPremium, input.StrikePrice, input.DTE),
+		"premium": input.PutPremium,
+		"strike":  input.StrikePrice,
+		"dte":     float64(input.DTE),
+	}
+}
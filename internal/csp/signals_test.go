@@ -0,0 +1,93 @@
+package csp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeSignalsWithExpression(t *testing.T) {
+	input := SignalInput{
+		VIX:             25,
+		CurrentIV:       0.30,
+		IVHigh52w:       0.40,
+		IVLow52w:        0.20,
+		ClosingPrices:   makeRSIData(40),
+		TotalPutVolume:  1000,
+		TotalCallVolume: 1000,
+		PutPremium:      1.23,
+		StrikePrice:     100,
+		DTE:             30,
+	}
+	builtin := ComputeSignals(input)
+	if len(builtin.Scores) != 5 || builtin.Scores[0].Name != SignalVIX || !approxEqual(builtin.Scores[0].Score, builtin.VIXScore) {
+		t.Fatalf("built-in scores %+v", builtin.Scores)
+	}
+
+	// A user signal of 100 weighted as much as all the built-ins together
+	// pulls the composite halfway to 100
+	full, err := NewExprSignal("Always", 1, "max(vix, 200)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := ComputeSignalsWith(input, append(BuiltinSignals(), full))
+	if want := (builtin.CompositeScore + 100) / 2; !approxEqual(out.CompositeScore, want) {
+		t.Errorf("composite with user signal = %v, want %v", out.CompositeScore, want)
+	}
+	last := out.Scores[len(out.Scores)-1]
+	if last.Name != "Always" || !approxEqual(last.Raw, 200) || !approxEqual(last.Score, 100) {
+		t.Errorf("user signal scored %+v, want raw 200 clamped to 100", last)
+	}
+	if !approxEqual(out.VIXScore, builtin.VIXScore) {
+		t.Errorf("VIXScore = %v, want %v", out.VIXScore, builtin.VIXScore)
+	}
+
+	// A signal over a variable that cannot be computed is left out
+	oversold, _ := NewExprSignal("Oversold", 1, "100 - rsi")
+	short := input
+	short.ClosingPrices = nil
+	out = ComputeSignalsWith(short, append(BuiltinSignals(), oversold))
+	if last := out.Scores[len(out.Scores)-1]; !math.IsNaN(last.Score) {
+		t.Errorf("signal over missing rsi scored %v, want NaN", last.Score)
+	}
+	if want := ComputeSignals(short).CompositeScore; !approxEqual(out.CompositeScore, want) {
+		t.Errorf("composite = %v, want %v with the signal left out", out.CompositeScore, want)
+	}
+}
+
+func TestPercentileVIXSignal(t *testing.T) {
+	input := SignalInput{VIX: 18, VIXHistory: []float64{10, 11, 12, 13, 14, 15, 16, 17, 19, 20}}
+	signals := []Signal{PercentileVIXSignal()}
+
+	// 18 is above 8 of 10 closes: the 80th percentile, though a low level
+	out := ComputeSignalsWith(input, signals)
+	if !approxEqual(out.RawVIXPercentile, 80) {
+		t.Errorf("RawVIXPercentile = %v, want 80", out.RawVIXPercentile)
+	}
+	if want := ScoreVIXPercentile(80); !approxEqual(out.VIXScore, want) || !approxEqual(out.Scores[0].Raw, 80) {
+		t.Errorf("VIX scored %+v, want %v from the percentile", out.Scores[0], want)
+	}
+
+	// Without history it scores the level
+	input.VIXHistory = nil
+	out = ComputeSignalsWith(input, signals)
+	if want := ScoreVIX(18); !approxEqual(out.VIXScore, want) || !math.IsNaN(out.RawVIXPercentile) {
+		t.Errorf("VIXScore without history = %v (percentile %v), want %v", out.VIXScore, out.RawVIXPercentile, want)
+	}
+}
+
+func TestNewExprSignalErrors(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		weight float64
+		src    string
+	}{
+		{"", 1, "vix"},
+		{"Zero", 0, "vix"},
+		{"Syntax", 1, "vix +"},
+		{"Unknown", 1, "price * 2"},
+	} {
+		if _, err := NewExprSignal(c.name, c.weight, c.src); err == nil {
+			t.Errorf("NewExprSignal(%q, %v, %q) accepted", c.name, c.weight, c.src)
+		}
+	}
+}
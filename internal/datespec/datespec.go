@@ -0,0 +1,138 @@
+// Package datespec resolves the shortcuts accepted by date fields, such as
+// "+30d" or "3rd friday", and finds the Fridays options usually expire on.
+package datespec
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+var ordinals = map[string]int{
+	"1st": 1, "first": 1,
+	"2nd": 2, "second": 2,
+	"3rd": 3, "third": 3,
+	"4th": 4, "fourth": 4,
+	"5th": 5, "fifth": 5,
+	"last": -1,
+}
+
+// NextWeekday returns the first day after from that falls on weekday.
+func NextWeekday(from time.Time, weekday time.Weekday) time.Time {
+	days := (int(weekday) - int(from.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return from.AddDate(0, 0, days)
+}
+
+// NextFriday returns the weekly expiry after from.
+func NextFriday(from time.Time) time.Time {
+	return NextWeekday(from, time.Friday)
+}
+
+// NthWeekday returns the nth weekday of month, or with n = -1 the last. ok is
+// false if the month has no such day, e.g. a 5th Friday.
+func NthWeekday(year int, month time.Month, n int, weekday time.Weekday, loc *time.Location) (time.Time, bool) {
+	if n == -1 {
+		last := time.Date(year, month+1, 0, 0, 0, 0, 0, loc)
+		return last.AddDate(0, 0, -((int(last.Weekday()) - int(weekday) + 7) % 7)), true
+	}
+	first := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	d := first.AddDate(0, 0, (int(weekday)-int(first.Weekday())+7)%7+(n-1)*7)
+	return d, d.Month() == month
+}
+
+// NextMonthly returns the standard monthly expiry, the third Friday of a
+// month, after from.
+func NextMonthly(from time.Time) time.Time {
+	d, _ := nextNth(from, 3, time.Friday)
+	return d
+}
+
+// nextNth returns the nth weekday of from's month if it is after from, or
+// else of the first month after that has one
+func nextNth(from time.Time, n int, weekday time.Weekday) (time.Time, bool) {
+	for i := 0; i < 12; i++ {
+		d, ok := NthWeekday(from.Year(), from.Month()+time.Month(i), n, weekday, from.Location())
+		if ok && d.After(from) {
+			return d, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Parse resolves a shortcut relative to today, which should be midnight:
+//
+//	today, t            today
+//	+30d, -2w, +3m, +1y days, weeks, months, or years from today
+//	fri, friday         the next Friday (any weekday works)
+//	weekly              the next Friday
+//	monthly             the next third Friday
+//	3rd fri, last mon   that day of this month, or of the next month that has
+//	                    one if it has passed
+//
+// ok is false if s is not a shortcut.
+func Parse(s string, today time.Time) (time.Time, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "today", "t":
+		return today, true
+	case "weekly":
+		return NextFriday(today), true
+	case "monthly":
+		return NextMonthly(today), true
+	}
+	if weekday, ok := weekdays[s]; ok {
+		return NextWeekday(today, weekday), true
+	}
+	if d, ok := parseOffset(s, today); ok {
+		return d, true
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return time.Time{}, false
+	}
+	n, okN := ordinals[fields[0]]
+	weekday, okW := weekdays[fields[1]]
+	if !okN || !okW {
+		return time.Time{}, false
+	}
+	return nextNth(today.AddDate(0, 0, -1), n, weekday)
+}
+
+// parseOffset resolves "+30d" style offsets
+func parseOffset(s string, today time.Time) (time.Time, bool) {
+	if len(s) < 3 || (s[0] != '+' && s[0] != '-') || s[1] < '0' || s[1] > '9' {
+		return time.Time{}, false
+	}
+	n, err := strconv.Atoi(s[1 : len(s)-1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	if s[0] == '-' {
+		n = -n
+	}
+	switch s[len(s)-1] {
+	case 'd':
+		return today.AddDate(0, 0, n), true
+	case 'w':
+		return today.AddDate(0, 0, 7*n), true
+	case 'm':
+		return today.AddDate(0, n, 0), true
+	case 'y':
+		return today.AddDate(n, 0, 0), true
+	}
+	return time.Time{}, false
+}
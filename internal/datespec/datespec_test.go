@@ -0,0 +1,62 @@
+package datespec
+
+import (
+	"testing"
+	"time"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	monday := day(2026, 3, 2)
+	friday := day(2026, 3, 20) // The March monthly
+
+	tests := []struct {
+		in    string
+		today time.Time
+		want  time.Time
+	}{
+		{"today", monday, monday},
+		{"+30d", monday, day(2026, 4, 1)},
+		{"-1w", monday, day(2026, 2, 23)},
+		{"+2m", monday, day(2026, 5, 2)},
+		{"+1y", monday, day(2027, 3, 2)},
+		{"fri", monday, day(2026, 3, 6)},
+		{"Friday", friday, day(2026, 3, 27)}, // Never today
+		{"weekly", monday, day(2026, 3, 6)},
+		{"monthly", monday, day(2026, 3, 20)},
+		{"monthly", friday, day(2026, 4, 17)},
+		{"3rd friday", monday, day(2026, 3, 20)},
+		{"3rd fri", friday, friday},                     // Today still counts
+		{"3rd fri", day(2026, 3, 21), day(2026, 4, 17)}, // Passed: next month
+		{"1st mon", day(2026, 3, 3), day(2026, 4, 6)},
+		{"last fri", monday, day(2026, 3, 27)},
+		{"5th fri", monday, day(2026, 5, 29)}, // March and April have four
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.in, tt.today)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("Parse(%q, %s) = %s, %v, want %s", tt.in, tt.today.Format("2006-01-02"), got.Format("2006-01-02"), ok, tt.want.Format("2006-01-02"))
+		}
+	}
+
+	for _, in := range []string{"", "2026-03-20", "+d", "+-3d", "+3x", "3rd", "9th fri", "next fri"} {
+		if _, ok := Parse(in, monday); ok {
+			t.Errorf("Parse(%q) accepted", in)
+		}
+	}
+}
+
+func TestNthWeekday(t *testing.T) {
+	if d, ok := NthWeekday(2026, time.June, 3, time.Friday, time.UTC); !ok || !d.Equal(day(2026, 6, 19)) {
+		t.Errorf("3rd Friday of June = %s, %v", d, ok)
+	}
+	if d, ok := NthWeekday(2026, time.February, -1, time.Saturday, time.UTC); !ok || !d.Equal(day(2026, 2, 28)) {
+		t.Errorf("last Saturday of February = %s, %v", d, ok)
+	}
+	if _, ok := NthWeekday(2026, time.February, 5, time.Monday, time.UTC); ok {
+		t.Error("February 2026 has no 5th Monday")
+	}
+}
@@ -0,0 +1,361 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+
+	"github.com/shopspring/decimal"
+)
+
+// Role is the access level of the connected credentials.
+type Role string
+
+const (
+	// RoleOwner can read and modify everything.
+	RoleOwner Role = "owner"
+	// RoleViewer can read everything; every mutating method fails with ErrReadOnly.
+	RoleViewer Role = "viewer"
+)
+
+// ErrReadOnly is returned by every mutating method of a ReadOnly store.
+var ErrReadOnly = errors.New("read-only access: viewer credentials cannot modify data")
+
+// CurrentRole reports RoleViewer when the connected database user may not
+// write holdings, as with the login created by schema_roles.sql.
+func (d *DB) CurrentRole(ctx context.Context) (Role, error) {
+	var canWrite bool
+	if err := d.pool.QueryRow(ctx, `SELECT has_table_privilege('holdings', 'INSERT')`).Scan(&canWrite); err != nil {
+		return RoleOwner, err
+	}
+	if !canWrite {
+		return RoleViewer, nil
+	}
+	return RoleOwner, nil
+}
+
+// ReadOnly wraps s so that reads pass through and every mutating method
+// returns ErrReadOnly without reaching the database. New mutating Store
+// methods must be overridden here; TestReadOnlyRejectsWrites enforces it.
+func ReadOnly(s Store) Store {
+	return readOnlyStore{s}
+}
+
+type readOnlyStore struct {
+	Store
+}
+
+func (readOnlyStore) CurrentRole(ctx context.Context) (Role, error) {
+	return RoleViewer, nil
+}
+
+// ForAccount scopes the wrapped store, keeping it read-only
+func (s readOnlyStore) ForAccount(id string) Store {
+	return readOnlyStore{s.Store.ForAccount(id)}
+}
+
+func (readOnlyStore) AddAccount(ctx context.Context, name string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) AddHolding(ctx context.Context, ticker string, quantity, avgCost decimal.Decimal, currency string, entryDate time.Time, targetPrice decimal.NullDecimal, notes string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) UpdateHolding(ctx context.Context, id string, quantity, avgCost decimal.Decimal, targetPrice decimal.NullDecimal, notes string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) UpdateHoldingIfUnchanged(ctx context.Context, id string, readAt time.Time, quantity, avgCost decimal.Decimal, targetPrice decimal.NullDecimal, notes string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetHoldingPosition(ctx context.Context, ticker string, quantity, avgCost decimal.Decimal, entryDate time.Time) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetTickerCurrency(ctx context.Context, ticker, currency string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) BulkAddHoldings(ctx context.Context, holdings []Holding) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetHoldingArchived(ctx context.Context, id string, archived bool) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) DeleteHolding(ctx context.Context, id string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetAvailableCash(ctx context.Context, amount decimal.Decimal) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) AdjustCash(ctx context.Context, kind, ticker string, amount decimal.Decimal, notes string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) RecordCashSnapshot(ctx context.Context, amount decimal.Decimal) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) AddInterestPayment(ctx context.Context, amount decimal.Decimal, receivedOn time.Time, notes string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) AddDividend(ctx context.Context, ticker string, amount decimal.Decimal, paidOn time.Time, notes string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SellHolding(ctx context.Context, ticker string, quantity, price decimal.Decimal, soldOn time.Time, notes string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) AddOption(ctx context.Context, ticker, optionType, action string, strike decimal.Decimal, expiryDate time.Time, quantity, multiplier int, settlement, currency string, premium, openFee decimal.Decimal, notes string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) AddExternalOption(ctx context.Context, ticker, optionType, action string, strike decimal.Decimal, expiryDate time.Time, quantity, multiplier int, settlement, currency string, premium, openFee decimal.Decimal, notes string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) AddToOption(ctx context.Context, id string, quantity int, premium, openFee decimal.Decimal, notes string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) AddHistoricalOption(ctx context.Context, o Option) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) UpdateOption(ctx context.Context, id string, strike decimal.Decimal, expiryDate time.Time, quantity, multiplier int, settlement string, premium, openFee decimal.Decimal, notes string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) UpdateOptionIfUnchanged(ctx context.Context, id string, readAt time.Time, strike decimal.Decimal, expiryDate time.Time, quantity, multiplier int, settlement string, premium, openFee decimal.Decimal, notes string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) DeleteOption(ctx context.Context, id string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) ExpireOption(ctx context.Context, id string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) CloseOption(ctx context.Context, id string, closePremium, closeFee decimal.Decimal) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) AssignOption(ctx context.Context, id string, fee decimal.Decimal) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SettleOption(ctx context.Context, id string, price, fee decimal.Decimal) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) ApplySplit(ctx context.Context, ticker string, ratio decimal.Decimal, date time.Time, notes string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) AddCSPWatchTicker(ctx context.Context, ticker, notes string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetCSPWatchExpiries(ctx context.Context, ticker, expiries string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) RemoveCSPWatchTicker(ctx context.Context, ticker string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetWatchTicker(ctx context.Context, ticker string, targetPrice decimal.NullDecimal, notes string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) RemoveWatchTicker(ctx context.Context, ticker string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) AddTradeIdea(ctx context.Context, ticker, optionType string, strike decimal.Decimal, expiryDate time.Time, quantity int, premium decimal.Decimal, notes string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) DeleteTradeIdea(ctx context.Context, id string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetTags(ctx context.Context, id string, tags []string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) RecordPortfolioSnapshot(ctx context.Context, holdingsValue, cash, optionsCredit decimal.Decimal) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) AddContribution(ctx context.Context, amount decimal.Decimal, contributedOn time.Time, notes string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetRiskCap(ctx context.Context, scope, name string, maxPct decimal.Decimal) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) DeleteRiskCap(ctx context.Context, id string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetTickerSector(ctx context.Context, ticker, sector string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SaveTickerProfile(ctx context.Context, p TickerProfile) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetManualPrice(ctx context.Context, ticker string, price decimal.Decimal) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) DeleteManualPrice(ctx context.Context, ticker string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) RecordQuoteFailure(ctx context.Context, ticker string, lastPrice decimal.NullDecimal, lastPriceAt time.Time) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) ClearStaleSymbol(ctx context.Context, ticker string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SavePriceBars(ctx context.Context, ticker string, bars []analytics.DailyBar) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SaveOpenInterest(ctx context.Context, snapshots []OpenInterest) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) AddOptionPolicy(ctx context.Context, optionID, kind string, threshold decimal.Decimal, itmOnly bool) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) DeleteOptionPolicy(ctx context.Context, id string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) QueuePolicyAction(ctx context.Context, a PolicyAction) (bool, error) {
+	return false, ErrReadOnly
+}
+
+func (readOnlyStore) ClaimFill(ctx context.Context, fillID string) (bool, error) {
+	return false, ErrReadOnly
+}
+
+func (readOnlyStore) ReleaseFill(ctx context.Context, fillID string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) ResolvePolicyAction(ctx context.Context, id, status string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) AddReminder(ctx context.Context, optionID string, remindOn time.Time, note string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) DismissReminder(ctx context.Context, id string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetRiskFreeRate(ctx context.Context, rate decimal.Decimal) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetUIState(ctx context.Context, state string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetQuoteCache(ctx context.Context, cache string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetLocale(ctx context.Context, name string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetTaxYearStart(ctx context.Context, start string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetIndicatorHistory(ctx context.Context, history string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetChainCacheTTL(ctx context.Context, ttl string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetAssignmentFee(ctx context.Context, fee AssignmentFee) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetPrecision(ctx context.Context, p Precision) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetTargetWeights(ctx context.Context, targets TargetWeights) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetCustomColumns(ctx context.Context, columns []CustomColumn) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetHoldingsColumns(ctx context.Context, columns []string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetCSPSignals(ctx context.Context, signals []CSPSignal) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetPinned(ctx context.Context, ids []string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetAlertLog(ctx context.Context, alerts []LoggedAlert) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetScoreHistory(ctx context.Context, history []ScoreSnapshot) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetBaseCurrency(ctx context.Context, currency string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetFXRates(ctx context.Context, rates FXRates) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetAlertBell(ctx context.Context, on bool) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetVIXPercentileScoring(ctx context.Context, on bool) error {
+	return ErrReadOnly
+}
+
+func (readOnlyStore) SetNotifyRoutes(ctx context.Context, routes string) error {
+	return ErrReadOnly
+}
@@ -0,0 +1,69 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/fake"
+
+	"github.com/shopspring/decimal"
+)
+
+// readMethods are the Store methods a viewer may call; every other method must
+// be rejected by db.ReadOnly
+var readMethods = map[string]bool{
+	"Listen":      true,
+	"CurrentRole": true,
+	"ForAccount":  true,
+}
+
+func TestReadOnlyRejectsWrites(t *testing.T) {
+	store := db.ReadOnly(fake.NewStore())
+	v := reflect.ValueOf(store)
+	storeType := reflect.TypeOf((*db.Store)(nil)).Elem()
+
+	for i := 0; i < storeType.NumMethod(); i++ {
+		name := storeType.Method(i).Name
+		if strings.HasPrefix(name, "Get") || readMethods[name] {
+			continue
+		}
+		m := v.MethodByName(name)
+		args := make([]reflect.Value, m.Type().NumIn())
+		for j := range args {
+			args[j] = reflect.Zero(m.Type().In(j))
+		}
+		args[0] = reflect.ValueOf(context.Background())
+
+		out := m.Call(args)
+		err, _ := out[len(out)-1].Interface().(error)
+		if !errors.Is(err, db.ErrReadOnly) {
+			t.Errorf("%s: err = %v, want ErrReadOnly", name, err)
+		}
+	}
+}
+
+func TestReadOnlyAccountStaysReadOnly(t *testing.T) {
+	store := db.ReadOnly(fake.NewStore()).ForAccount("ira")
+	if err := store.SetAvailableCash(context.Background(), decimal.NewFromInt(1)); !errors.Is(err, db.ErrReadOnly) {
+		t.Errorf("SetAvailableCash on a scoped viewer store: err = %v, want ErrReadOnly", err)
+	}
+}
+
+func TestReadOnlyPassesReads(t *testing.T) {
+	ctx := context.Background()
+	inner := fake.NewStore()
+	inner.AddCSPWatchTicker(ctx, "AAPL", "")
+	store := db.ReadOnly(inner)
+
+	items, err := store.GetCSPWatchlist(ctx)
+	if err != nil || len(items) != 1 {
+		t.Errorf("GetCSPWatchlist = %v, %v; want the inner store's item", items, err)
+	}
+	if role, _ := store.CurrentRole(ctx); role != db.RoleViewer {
+		t.Errorf("CurrentRole = %q, want viewer", role)
+	}
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// MainAccountName names the account holdings, options, and cash belong to
+// unless another is chosen. It has no row in the accounts table: its rows
+// have a NULL account_id, so books from before accounts need no migration.
+const MainAccountName = "Main"
+
+// Account is a brokerage account, e.g. an IRA kept apart from the taxable
+// account. Holdings, options, and cash are scoped per account.
+type Account struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+}
+
+// GetAccounts returns the accounts besides the main one, by name.
+func (d *DB) GetAccounts(ctx context.Context) ([]Account, error) {
+	rows, err := d.pool.Query(ctx, `SELECT id, name, created_at FROM accounts ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []Account
+	for rows.Next() {
+		var a Account
+		if err := rows.Scan(&a.ID, &a.Name, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, rows.Err()
+}
+
+func (d *DB) AddAccount(ctx context.Context, name string) error {
+	_, err := d.pool.Exec(ctx, `INSERT INTO accounts (name) VALUES ($1)`, name)
+	return err
+}
+
+// ForAccount returns a store whose holdings, options, and cash are those of
+// the account with id, or of the main account for "". Everything else is
+// shared by all accounts.
+func (d *DB) ForAccount(id string) Store {
+	return &DB{pool: d.pool, account: id}
+}
+
+// accountID is the scoped account as a query argument: NULL for the main
+// account, compared with IS NOT DISTINCT FROM
+func (d *DB) accountID() any {
+	if d.account == "" {
+		return nil
+	}
+	return d.account
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type CashSnapshot struct {
+	Date   time.Time
+	Amount decimal.Decimal
+}
+
+type InterestPayment struct {
+	ID         string
+	Amount     decimal.Decimal
+	ReceivedOn time.Time
+	Notes      string
+	CreatedAt  time.Time
+}
+
+// RecordCashSnapshot stores today's cash balance, overwriting any earlier snapshot from today.
+func (d *DB) RecordCashSnapshot(ctx context.Context, amount decimal.Decimal) error {
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO cash_snapshots (snapshot_date, amount, updated_at) VALUES (CURRENT_DATE, $1, NOW())
+		 ON CONFLICT (snapshot_date) DO UPDATE SET amount = $1, updated_at = NOW()`,
+		amount)
+	return err
+}
+
+// GetCashSnapshots returns snapshots on or after since, oldest first. The most
+// recent snapshot before since is included so balances can be carried forward.
+func (d *DB) GetCashSnapshots(ctx context.Context, since time.Time) ([]CashSnapshot, error) {
+	rows, err := d.pool.Query(ctx,
+		`SELECT snapshot_date, amount FROM cash_snapshots
+		 WHERE snapshot_date >= COALESCE((SELECT MAX(snapshot_date) FROM cash_snapshots WHERE snapshot_date <= $1), $1)
+		 ORDER BY snapshot_date`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []CashSnapshot
+	for rows.Next() {
+		var s CashSnapshot
+		if err := rows.Scan(&s.Date, &s.Amount); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+// AddInterestPayment records interest received and credits it to available cash.
+func (d *DB) AddInterestPayment(ctx context.Context, amount decimal.Decimal, receivedOn time.Time, notes string) error {
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO interest_payments (amount, received_on, notes) VALUES ($1, $2, $3)`,
+		amount, receivedOn, notes)
+	if err != nil {
+		return err
+	}
+
+	return d.moveCash(ctx, TxInterest, "", decimal.Zero, amount, notes)
+}
+
+// GetInterestSince returns total interest received on or after since.
+func (d *DB) GetInterestSince(ctx context.Context, since time.Time) (decimal.Decimal, error) {
+	var total decimal.Decimal
+	err := d.pool.QueryRow(ctx,
+		`SELECT COALESCE(SUM(amount), 0) FROM interest_payments WHERE received_on >= $1`, since).Scan(&total)
+	return total, err
+}
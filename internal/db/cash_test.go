@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func cleanCashTables(t *testing.T, d *DB) {
+	t.Helper()
+	clean := func() {
+		ctx := context.Background()
+		d.pool.Exec(ctx, `DELETE FROM cash_snapshots`)
+		d.pool.Exec(ctx, `DELETE FROM interest_payments`)
+	}
+	clean()
+	t.Cleanup(clean)
+}
+
+func TestRecordCashSnapshotUpsertsToday(t *testing.T) {
+	d := testDB(t)
+	cleanCashTables(t, d)
+	ctx := context.Background()
+
+	if err := d.RecordCashSnapshot(ctx, decimal.NewFromInt(1000)); err != nil {
+		t.Fatalf("RecordCashSnapshot: %v", err)
+	}
+	if err := d.RecordCashSnapshot(ctx, decimal.NewFromInt(1500)); err != nil {
+		t.Fatalf("RecordCashSnapshot second: %v", err)
+	}
+
+	snapshots, err := d.GetCashSnapshots(ctx, time.Now().AddDate(0, 0, -1))
+	if err != nil {
+		t.Fatalf("GetCashSnapshots: %v", err)
+	}
+	if len(snapshots) != 1 {
+		t.Fatalf("expected 1 snapshot, got %d", len(snapshots))
+	}
+	if !snapshots[0].Amount.Equal(decimal.NewFromInt(1500)) {
+		t.Errorf("expected amount 1500, got %s", snapshots[0].Amount)
+	}
+}
+
+func TestGetInterestSince(t *testing.T) {
+	d := testDB(t)
+	cleanCashTables(t, d)
+	ctx := context.Background()
+
+	original, _ := d.GetAvailableCash(ctx)
+	t.Cleanup(func() { d.SetAvailableCash(context.Background(), original) })
+
+	now := time.Now()
+	_ = d.AddInterestPayment(ctx, decimal.NewFromInt(10), now.AddDate(0, -2, 0), "old")
+	_ = d.AddInterestPayment(ctx, decimal.NewFromInt(5), now, "new")
+
+	total, err := d.GetInterestSince(ctx, now.AddDate(0, -1, 0))
+	if err != nil {
+		t.Fatalf("GetInterestSince: %v", err)
+	}
+	if !total.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("expected 5, got %s", total)
+	}
+
+	cash, _ := d.GetAvailableCash(ctx)
+	if !cash.Equal(original.Add(decimal.NewFromInt(15))) {
+		t.Errorf("expected cash credited by 15, got %s (was %s)", cash, original)
+	}
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrConflict is returned by the IfUnchanged updates when the row was modified
+// or deleted since the caller read it, e.g. by another instance sharing the
+// database.
+var ErrConflict = errors.New("changed by another session")
+
+// UpdateHoldingIfUnchanged is UpdateHolding guarded by the updated_at value the
+// caller read. It returns ErrConflict instead of overwriting a newer edit.
+func (d *DB) UpdateHoldingIfUnchanged(ctx context.Context, id string, readAt time.Time, quantity, avgCost decimal.Decimal, targetPrice decimal.NullDecimal, notes string) error {
+	tag, err := d.pool.Exec(ctx,
+		`UPDATE holdings SET quantity = $3, avg_cost = $4, target_price = $5, notes = $6 WHERE id = $1 AND updated_at = $2`,
+		id, readAt, quantity, avgCost, targetPrice, notes)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// UpdateOptionIfUnchanged is UpdateOption guarded by the updated_at value the
+// caller read. It returns ErrConflict instead of overwriting a newer edit.
+func (d *DB) UpdateOptionIfUnchanged(ctx context.Context, id string, readAt time.Time, strike decimal.Decimal, expiryDate time.Time, quantity, multiplier int, settlement string, premium, openFee decimal.Decimal, notes string) error {
+	tag, err := d.pool.Exec(ctx,
+		`UPDATE options SET strike = $3, expiry_date = $4, quantity = $5, multiplier = $6, settlement = $7, premium = $8, open_fee = $9, notes = $10 WHERE id = $1 AND updated_at = $2`,
+		id, readAt, strike, expiryDate, quantity, multiplier, settlement, premium, openFee, notes)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// GetChangeStamp returns an opaque value that changes whenever holdings,
+// options, or available cash change, so a running instance can cheaply notice
+// edits made elsewhere. Row counts catch deletes; updated_at catches the rest.
+// The cash ledger is only appended to, so its count is enough.
+func (d *DB) GetChangeStamp(ctx context.Context) (string, error) {
+	var stamp string
+	err := d.pool.QueryRow(ctx,
+		`SELECT concat_ws('|',
+			(SELECT count(*) FROM holdings), (SELECT max(updated_at) FROM holdings),
+			(SELECT count(*) FROM options), (SELECT max(updated_at) FROM options),
+			(SELECT count(*) FROM transactions))`).Scan(&stamp)
+	return stamp, err
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestUpdateHoldingIfUnchanged(t *testing.T) {
+	d := testDB(t)
+	ctx := context.Background()
+	clean := func() { d.pool.Exec(context.Background(), `DELETE FROM holdings WHERE ticker = 'ZZLOCK'`) }
+	clean()
+	t.Cleanup(clean)
+
+	original, _ := d.GetAvailableCash(ctx)
+	t.Cleanup(func() { d.SetAvailableCash(context.Background(), original) })
+
+	if err := d.AddHolding(ctx, "ZZLOCK", decimal.NewFromInt(10), decimal.NewFromInt(5), DefaultCurrency, time.Now(), decimal.NullDecimal{}, ""); err != nil {
+		t.Fatalf("AddHolding: %v", err)
+	}
+	read, err := d.GetHoldingByTicker(ctx, "ZZLOCK")
+	if err != nil || read == nil {
+		t.Fatalf("GetHoldingByTicker: %v", err)
+	}
+
+	before, _ := d.GetChangeStamp(ctx)
+
+	// Another session saves first
+	if err := d.UpdateHoldingIfUnchanged(ctx, read.ID, read.UpdatedAt, decimal.NewFromInt(20), read.AvgCost, read.TargetPrice, "other"); err != nil {
+		t.Fatalf("first update: %v", err)
+	}
+	// This session's save is based on the stale read
+	err = d.UpdateHoldingIfUnchanged(ctx, read.ID, read.UpdatedAt, decimal.NewFromInt(30), read.AvgCost, read.TargetPrice, "mine")
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("stale update err = %v, want ErrConflict", err)
+	}
+
+	after, _ := d.GetChangeStamp(ctx)
+	if before == after {
+		t.Error("change stamp did not move after an update")
+	}
+
+	h, _ := d.GetHoldingByTicker(ctx, "ZZLOCK")
+	if !h.Quantity.Equal(decimal.NewFromInt(20)) || h.Notes != "other" {
+		t.Errorf("holding = %s shares, notes %q; want the first update kept", h.Quantity, h.Notes)
+	}
+}
@@ -9,6 +9,7 @@ type CSPWatchItem struct {
 	ID        string
 	Ticker    string
 	Notes     string
+	Expiries  string // Expirations the advisor targets: ALL, WEEKLY, or MONTHLY
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -25,9 +26,15 @@ func (d *DB) RemoveCSPWatchTicker(ctx context.Context, ticker string) error {
 	return err
 }
 
+// SetCSPWatchExpiries sets which expirations the advisor targets for ticker.
+func (d *DB) SetCSPWatchExpiries(ctx context.Context, ticker, expiries string) error {
+	_, err := d.pool.Exec(ctx, `UPDATE csp_watchlist SET expiries = $2 WHERE ticker = $1`, ticker, expiries)
+	return err
+}
+
 func (d *DB) GetCSPWatchlist(ctx context.Context) ([]CSPWatchItem, error) {
 	rows, err := d.pool.Query(ctx,
-		`SELECT id, ticker, notes, created_at, updated_at FROM csp_watchlist ORDER BY ticker`)
+		`SELECT id, ticker, notes, expiries, created_at, updated_at FROM csp_watchlist ORDER BY ticker`)
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +44,7 @@ func (d *DB) GetCSPWatchlist(ctx context.Context) ([]CSPWatchItem, error) {
 	for rows.Next() {
 		var item CSPWatchItem
 		var notes *string
-		err := rows.Scan(&item.ID, &item.Ticker, &notes, &item.CreatedAt, &item.UpdatedAt)
+		err := rows.Scan(&item.ID, &item.Ticker, &notes, &item.Expiries, &item.CreatedAt, &item.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
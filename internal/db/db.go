@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -16,11 +17,28 @@ type Holding struct {
 	AvgCost     decimal.Decimal
 	EntryDate   time.Time
 	TargetPrice decimal.NullDecimal
+	Currency    string // ISO code prices and costs are in, DefaultCurrency unless set
 	Notes       string
+	Archived    bool // Hidden from the holdings table unless archived rows are shown
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
 
+// DefaultCurrency is the currency of holdings and options until another is
+// set for their ticker.
+const DefaultCurrency = "USD"
+
+// DefaultMultiplier is the shares per contract of a standard equity option.
+const DefaultMultiplier = 100
+
+// Settlement types. A physically settled option delivers shares on
+// assignment; a cash-settled one, like most index options, pays its
+// intrinsic value instead.
+const (
+	SettlementPhysical = "PHYSICAL"
+	SettlementCash     = "CASH"
+)
+
 type Option struct {
 	ID           string
 	Ticker       string
@@ -29,18 +47,28 @@ type Option struct {
 	Strike       decimal.Decimal
 	ExpiryDate   time.Time
 	Quantity     int
+	Multiplier   int    // Shares per contract: DefaultMultiplier, or e.g. 10 for minis and adjusted deliverables
+	Settlement   string // SettlementPhysical or SettlementCash
+	Currency     string // ISO code the strike and premiums are in
 	Premium      decimal.Decimal
 	OpenFee      decimal.Decimal
 	ClosePremium decimal.NullDecimal
 	CloseFee     decimal.NullDecimal
 	Status       string // ACTIVE, EXPIRED, ASSIGNED, CLOSED
 	Notes        string
+	External     bool // Watch-only: held at another broker, so it never moves cash or holdings
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
 
+// Shares is the number of shares the option's contracts deliver.
+func (o Option) Shares() decimal.Decimal {
+	return decimal.NewFromInt(int64(o.Quantity) * int64(o.Multiplier))
+}
+
 type DB struct {
-	pool *pgxpool.Pool
+	pool    *pgxpool.Pool
+	account string // Account ID holdings, options, and cash are scoped to, "" for the main account
 }
 
 func New(databaseURL string) (*DB, error) {
@@ -50,6 +78,7 @@ func New(databaseURL string) (*DB, error) {
 	}
 	// Disable prepared statements for Supabase transaction pooler compatibility
 	config.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	config.ConnConfig.Tracer = queryTracer{}
 
 	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
@@ -62,20 +91,21 @@ func (d *DB) Close() {
 	d.pool.Close()
 }
 
-func (d *DB) AddHolding(ctx context.Context, ticker string, quantity, avgCost decimal.Decimal, entryDate time.Time, targetPrice decimal.NullDecimal, notes string) error {
+// AddHolding buys quantity shares of ticker at avgCost, in currency, merging
+// into an existing holding at the weighted average cost. The cost is taken
+// from cash in the base currency.
+func (d *DB) AddHolding(ctx context.Context, ticker string, quantity, avgCost decimal.Decimal, currency string, entryDate time.Time, targetPrice decimal.NullDecimal, notes string) error {
 	existing, err := d.GetHoldingByTicker(ctx, ticker)
 	if err != nil {
 		return err
 	}
-
-	totalCost := quantity.Mul(avgCost)
-
-	currentCash, err := d.GetAvailableCash(ctx)
+	rate, err := d.fxRate(ctx, currency)
 	if err != nil {
-		currentCash = decimal.Zero
+		return err
 	}
-	currentCash = currentCash.Sub(totalCost)
-	if err := d.SetAvailableCash(ctx, currentCash); err != nil {
+
+	totalCost := quantity.Mul(avgCost).Mul(rate)
+	if err := d.moveCash(ctx, TxBuy, ticker, quantity, totalCost.Neg(), notes); err != nil {
 		return err
 	}
 
@@ -98,18 +128,31 @@ func (d *DB) AddHolding(ctx context.Context, ticker string, quantity, avgCost de
 			newTargetPrice = targetPrice
 		}
 
-		return d.UpdateHolding(ctx, existing.ID, totalShares, newAvgCost, newTargetPrice, mergedNotes)
+		if err := d.UpdateHolding(ctx, existing.ID, totalShares, newAvgCost, newTargetPrice, mergedNotes); err != nil {
+			return err
+		}
+		if existing.Archived {
+			// Buying more brings it back
+			return d.SetHoldingArchived(ctx, existing.ID, false)
+		}
+		return nil
 	}
 
-	_, err = d.pool.Exec(ctx,
-		`INSERT INTO holdings (ticker, quantity, avg_cost, entry_date, target_price, notes) VALUES ($1, $2, $3, $4, $5, $6)`,
-		ticker, quantity, avgCost, entryDate, targetPrice, notes)
+	return d.insertHolding(ctx, ticker, quantity, avgCost, currency, entryDate, targetPrice, notes)
+}
+
+// insertHolding adds a new holding without touching cash
+func (d *DB) insertHolding(ctx context.Context, ticker string, quantity, avgCost decimal.Decimal, currency string, entryDate time.Time, targetPrice decimal.NullDecimal, notes string) error {
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO holdings (ticker, quantity, avg_cost, entry_date, target_price, currency, notes, account_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		ticker, quantity, avgCost, entryDate, targetPrice, currency, notes, d.accountID())
 	return err
 }
 
 func (d *DB) GetHoldings(ctx context.Context) ([]Holding, error) {
 	rows, err := d.pool.Query(ctx,
-		`SELECT id, ticker, quantity, avg_cost, entry_date, target_price, notes, created_at, updated_at FROM holdings ORDER BY ticker`)
+		`SELECT id, ticker, quantity, avg_cost, entry_date, target_price, currency, notes, archived, created_at, updated_at FROM holdings
+		 WHERE account_id IS NOT DISTINCT FROM $1 ORDER BY ticker`, d.accountID())
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +163,7 @@ func (d *DB) GetHoldings(ctx context.Context) ([]Holding, error) {
 		var h Holding
 		var targetPrice *decimal.Decimal
 		var notes *string
-		err := rows.Scan(&h.ID, &h.Ticker, &h.Quantity, &h.AvgCost, &h.EntryDate, &targetPrice, &notes, &h.CreatedAt, &h.UpdatedAt)
+		err := rows.Scan(&h.ID, &h.Ticker, &h.Quantity, &h.AvgCost, &h.EntryDate, &targetPrice, &h.Currency, &notes, &h.Archived, &h.CreatedAt, &h.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -142,18 +185,103 @@ func (d *DB) UpdateHolding(ctx context.Context, id string, quantity, avgCost dec
 	return err
 }
 
-func (d *DB) DeleteHolding(ctx context.Context, id string) error {
-	_, err := d.pool.Exec(ctx, `DELETE FROM holdings WHERE id = $1`, id)
+// SetHoldingPosition sets a ticker's quantity and average cost to match an
+// external source, creating the holding if needed. Unlike AddHolding it does
+// not merge with the existing position or adjust cash.
+func (d *DB) SetHoldingPosition(ctx context.Context, ticker string, quantity, avgCost decimal.Decimal, entryDate time.Time) error {
+	existing, err := d.GetHoldingByTicker(ctx, ticker)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return d.UpdateHolding(ctx, existing.ID, quantity, avgCost, existing.TargetPrice, existing.Notes)
+	}
+	_, err = d.pool.Exec(ctx,
+		`INSERT INTO holdings (ticker, quantity, avg_cost, entry_date, account_id) VALUES ($1, $2, $3, $4, $5)`,
+		ticker, quantity, avgCost, entryDate, d.accountID())
 	return err
 }
 
+// BulkAddHoldings adds imported holdings in one transaction, so either all
+// are added or none. A ticker already held is merged into the holding at the
+// weighted average cost, restoring it if archived. Cash is not adjusted.
+func (d *DB) BulkAddHoldings(ctx context.Context, holdings []Holding) error {
+	return pgx.BeginFunc(ctx, d.pool, func(tx pgx.Tx) error {
+		for _, h := range holdings {
+			var id string
+			var quantity, avgCost decimal.Decimal
+			err := tx.QueryRow(ctx,
+				`SELECT id, quantity, avg_cost FROM holdings WHERE ticker = $1 AND account_id IS NOT DISTINCT FROM $2 FOR UPDATE`,
+				h.Ticker, d.accountID()).Scan(&id, &quantity, &avgCost)
+			if err == pgx.ErrNoRows {
+				_, err = tx.Exec(ctx,
+					`INSERT INTO holdings (ticker, quantity, avg_cost, entry_date, notes, account_id) VALUES ($1, $2, $3, $4, $5, $6)`,
+					h.Ticker, h.Quantity, h.AvgCost, h.EntryDate, h.Notes, d.accountID())
+			} else if err == nil {
+				totalShares := quantity.Add(h.Quantity)
+				newAvgCost := quantity.Mul(avgCost).Add(h.Quantity.Mul(h.AvgCost)).Div(totalShares)
+				_, err = tx.Exec(ctx,
+					`UPDATE holdings SET quantity = $2, avg_cost = $3, archived = FALSE,
+					 notes = CASE WHEN $4 = '' THEN notes WHEN COALESCE(notes, '') = '' THEN $4 ELSE notes || '; ' || $4 END
+					 WHERE id = $1`,
+					id, totalShares, newAvgCost, h.Notes)
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", h.Ticker, err)
+			}
+		}
+		return nil
+	})
+}
+
+// SetTickerCurrency sets the currency of ticker's holding and options, which
+// all trade in the ticker's listing currency.
+func (d *DB) SetTickerCurrency(ctx context.Context, ticker, currency string) error {
+	// Only rows that differ, so unchanged rows keep their updated_at for
+	// conflict detection
+	if _, err := d.pool.Exec(ctx,
+		`UPDATE holdings SET currency = $2 WHERE ticker = $1 AND currency <> $2 AND account_id IS NOT DISTINCT FROM $3`,
+		ticker, currency, d.accountID()); err != nil {
+		return err
+	}
+	_, err := d.pool.Exec(ctx,
+		`UPDATE options SET currency = $2 WHERE ticker = $1 AND currency <> $2 AND account_id IS NOT DISTINCT FROM $3`,
+		ticker, currency, d.accountID())
+	return err
+}
+
+// SetHoldingArchived archives or restores a holding. Archived holdings are
+// kept, with their history, but hidden from the holdings table.
+func (d *DB) SetHoldingArchived(ctx context.Context, id string, archived bool) error {
+	_, err := d.pool.Exec(ctx, `UPDATE holdings SET archived = $2 WHERE id = $1`, id, archived)
+	return err
+}
+
+// DeleteHolding removes the holding with id and its tags.
+func (d *DB) DeleteHolding(ctx context.Context, id string) error {
+	return d.deleteTagged(ctx, "holdings", id)
+}
+
+// deleteTagged removes the row with id from table, a holdings or options
+// table, together with its tags, which no foreign key can cascade from as
+// item_tags holds both
+func (d *DB) deleteTagged(ctx context.Context, table, id string) error {
+	return pgx.BeginFunc(ctx, d.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM item_tags WHERE item_id = $1`, id); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE id = $1`, id)
+		return err
+	})
+}
+
 func (d *DB) GetHoldingByTicker(ctx context.Context, ticker string) (*Holding, error) {
 	var h Holding
 	var targetPrice *decimal.Decimal
 	var notes *string
 	err := d.pool.QueryRow(ctx,
-		`SELECT id, ticker, quantity, avg_cost, entry_date, target_price, notes, created_at, updated_at FROM holdings WHERE ticker = $1`,
-		ticker).Scan(&h.ID, &h.Ticker, &h.Quantity, &h.AvgCost, &h.EntryDate, &targetPrice, &notes, &h.CreatedAt, &h.UpdatedAt)
+		`SELECT id, ticker, quantity, avg_cost, entry_date, target_price, currency, notes, archived, created_at, updated_at FROM holdings WHERE ticker = $1 AND account_id IS NOT DISTINCT FROM $2`,
+		ticker, d.accountID()).Scan(&h.ID, &h.Ticker, &h.Quantity, &h.AvgCost, &h.EntryDate, &targetPrice, &h.Currency, &notes, &h.Archived, &h.CreatedAt, &h.UpdatedAt)
 	if err == pgx.ErrNoRows {
 		return nil, nil
 	}
@@ -169,31 +297,35 @@ func (d *DB) GetHoldingByTicker(ctx context.Context, ticker string) (*Holding, e
 	return &h, nil
 }
 
+// GetAvailableCash returns the account's cash: the sum of its ledger.
 func (d *DB) GetAvailableCash(ctx context.Context) (decimal.Decimal, error) {
-	var value string
-	err := d.pool.QueryRow(ctx, `SELECT value FROM settings WHERE key = 'available_cash'`).Scan(&value)
-	if err == pgx.ErrNoRows {
-		return decimal.Zero, nil
-	}
-	if err != nil {
-		return decimal.Zero, err
-	}
-	return decimal.NewFromString(value)
+	var cash decimal.Decimal
+	err := d.pool.QueryRow(ctx,
+		`SELECT COALESCE(SUM(amount), 0) FROM transactions WHERE account_id IS NOT DISTINCT FROM $1`,
+		d.accountID()).Scan(&cash)
+	return cash, err
 }
 
+// SetAvailableCash sets cash to amount, recording the difference in the
+// ledger as an adjustment.
 func (d *DB) SetAvailableCash(ctx context.Context, amount decimal.Decimal) error {
-	_, err := d.pool.Exec(ctx,
-		`INSERT INTO settings (key, value, updated_at) VALUES ('available_cash', $1, NOW())
-		 ON CONFLICT (key) DO UPDATE SET value = $1, updated_at = NOW()`,
-		amount.String())
-	return err
+	cash, err := d.GetAvailableCash(ctx)
+	if err != nil {
+		return err
+	}
+	return d.moveCash(ctx, TxAdjustment, "", decimal.Zero, amount.Sub(cash), "Cash set")
 }
 
-func (d *DB) AddOption(ctx context.Context, ticker, optionType, action string, strike decimal.Decimal, expiryDate time.Time, quantity int, premium, openFee decimal.Decimal, notes string) error {
+func (d *DB) AddOption(ctx context.Context, ticker, optionType, action string, strike decimal.Decimal, expiryDate time.Time, quantity, multiplier int, settlement, currency string, premium, openFee decimal.Decimal, notes string) error {
+	rate, err := d.fxRate(ctx, currency)
+	if err != nil {
+		return err
+	}
+
 	// Insert the option
-	_, err := d.pool.Exec(ctx,
-		`INSERT INTO options (ticker, option_type, action, strike, expiry_date, quantity, premium, open_fee, status, notes) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 'ACTIVE', $9)`,
-		ticker, optionType, action, strike, expiryDate, quantity, premium, openFee, notes)
+	_, err = d.pool.Exec(ctx,
+		`INSERT INTO options (ticker, option_type, action, strike, expiry_date, quantity, multiplier, settlement, currency, premium, open_fee, status, notes, account_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, 'ACTIVE', $12, $13)`,
+		ticker, optionType, action, strike, expiryDate, quantity, multiplier, settlement, currency, premium, openFee, notes, d.accountID())
 	if err != nil {
 		return err
 	}
@@ -201,31 +333,116 @@ func (d *DB) AddOption(ctx context.Context, ticker, optionType, action string, s
 	// Auto-adjust cash based on action
 	// SELL = receive premium, BUY = pay premium
 	// Fees are always deducted
-	premiumTotal := premium.Mul(decimal.NewFromInt(int64(quantity))).Mul(decimal.NewFromInt(100))
+	premiumTotal := premium.Mul(decimal.NewFromInt(int64(quantity) * int64(multiplier)))
+	if action != "SELL" {
+		premiumTotal = premiumTotal.Neg()
+	}
+	entry := contractNotes(action, optionType, strike, expiryDate)
+	contracts := decimal.NewFromInt(int64(quantity))
+	if err := d.moveCash(ctx, TxPremium, ticker, contracts, premiumTotal.Mul(rate), entry); err != nil {
+		return err
+	}
+	return d.moveCash(ctx, TxFee, ticker, decimal.Zero, openFee.Neg().Mul(rate), entry)
+}
 
-	currentCash, err := d.GetAvailableCash(ctx)
+// AddExternalOption records a watch-only option, held at another broker. It
+// shows with the others but leaves cash alone, and closing or assigning it
+// later moves neither cash nor holdings.
+func (d *DB) AddExternalOption(ctx context.Context, ticker, optionType, action string, strike decimal.Decimal, expiryDate time.Time, quantity, multiplier int, settlement, currency string, premium, openFee decimal.Decimal, notes string) error {
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO options (ticker, option_type, action, strike, expiry_date, quantity, multiplier, settlement, currency, premium, open_fee, status, notes, external, account_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, 'ACTIVE', $12, TRUE, $13)`,
+		ticker, optionType, action, strike, expiryDate, quantity, multiplier, settlement, currency, premium, openFee, notes, d.accountID())
+	return err
+}
+
+// AddToOption adds quantity contracts at premium to an existing option, as if
+// they had been opened together: the premium becomes the average over all
+// contracts and the fee is added to the open fee. Cash moves for the added
+// contracts as in AddOption, unless the option is watch-only.
+func (d *DB) AddToOption(ctx context.Context, id string, quantity int, premium, openFee decimal.Decimal, notes string) error {
+	var o Option
+	var existingFee *decimal.Decimal
+	var existingNotes *string
+	err := d.pool.QueryRow(ctx,
+		`SELECT ticker, option_type, action, strike, expiry_date, quantity, multiplier, currency, premium, open_fee, notes, external FROM options WHERE id = $1`, id).
+		Scan(&o.Ticker, &o.OptionType, &o.Action, &o.Strike, &o.ExpiryDate, &o.Quantity, &o.Multiplier, &o.Currency, &o.Premium, &existingFee, &existingNotes, &o.External)
 	if err != nil {
-		currentCash = decimal.Zero
+		return err
+	}
+	if existingFee != nil {
+		o.OpenFee = *existingFee
+	}
+	if existingNotes != nil {
+		o.Notes = *existingNotes
+	}
+	rate := decimal.NewFromInt(1)
+	if !o.External {
+		if rate, err = d.fxRate(ctx, o.Currency); err != nil {
+			return err
+		}
 	}
 
-	if action == "SELL" {
-		currentCash = currentCash.Add(premiumTotal)
-	} else {
-		currentCash = currentCash.Sub(premiumTotal)
+	total := o.Quantity + quantity
+	avgPremium := o.Premium.Mul(decimal.NewFromInt(int64(o.Quantity))).
+		Add(premium.Mul(decimal.NewFromInt(int64(quantity)))).
+		Div(decimal.NewFromInt(int64(total)))
+	mergedNotes := o.Notes
+	if notes != "" {
+		if mergedNotes != "" {
+			mergedNotes = mergedNotes + "; " + notes
+		} else {
+			mergedNotes = notes
+		}
 	}
-	// Deduct opening fee
-	currentCash = currentCash.Sub(openFee)
+	_, err = d.pool.Exec(ctx,
+		`UPDATE options SET quantity = $2, premium = $3, open_fee = $4, notes = $5 WHERE id = $1`,
+		id, total, avgPremium, o.OpenFee.Add(openFee), mergedNotes)
+	if err != nil || o.External {
+		return err
+	}
+
+	premiumTotal := premium.Mul(decimal.NewFromInt(int64(quantity) * int64(o.Multiplier)))
+	if o.Action != "SELL" {
+		premiumTotal = premiumTotal.Neg()
+	}
+	entry := contractNotes(o.Action, o.OptionType, o.Strike, o.ExpiryDate)
+	if err := d.moveCash(ctx, TxPremium, o.Ticker, decimal.NewFromInt(int64(quantity)), premiumTotal.Mul(rate), entry); err != nil {
+		return err
+	}
+	return d.moveCash(ctx, TxFee, o.Ticker, decimal.Zero, openFee.Neg().Mul(rate), entry)
+}
 
-	return d.SetAvailableCash(ctx, currentCash)
+// AddHistoricalOption records an option traded before the app was tracking
+// the account, already settled with o.Status, opened at o.CreatedAt and
+// closed at o.UpdatedAt, in o.Currency (DefaultCurrency if empty). Premium
+// stats go by open date and count it; cash and holdings are left alone, as
+// they already reflect the trade.
+func (d *DB) AddHistoricalOption(ctx context.Context, o Option) error {
+	if o.Currency == "" {
+		o.Currency = DefaultCurrency
+	}
+	var closePremium *decimal.Decimal
+	if o.ClosePremium.Valid {
+		closePremium = &o.ClosePremium.Decimal
+	}
+	var closeFee *decimal.Decimal
+	if o.CloseFee.Valid {
+		closeFee = &o.CloseFee.Decimal
+	}
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO options (ticker, option_type, action, strike, expiry_date, quantity, multiplier, settlement, currency, premium, open_fee, close_premium, close_fee, status, notes, account_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`,
+		o.Ticker, o.OptionType, o.Action, o.Strike, o.ExpiryDate, o.Quantity, o.Multiplier, o.Settlement, o.Currency, o.Premium, o.OpenFee, closePremium, closeFee, o.Status, o.Notes, d.accountID(), o.CreatedAt, o.UpdatedAt)
+	return err
 }
 
 func (d *DB) GetActiveOptions(ctx context.Context) ([]Option, error) {
 	rows, err := d.pool.Query(ctx,
-		`SELECT id, ticker, option_type, action, strike, expiry_date, quantity, premium, open_fee, close_premium, close_fee, status, notes, created_at, updated_at
+		`SELECT id, ticker, option_type, action, strike, expiry_date, quantity, multiplier, settlement, currency, premium, open_fee, close_premium, close_fee, status, notes, external, created_at, updated_at
 		 FROM options
+		 WHERE account_id IS NOT DISTINCT FROM $1
 		 ORDER BY
 		   CASE status WHEN 'ACTIVE' THEN 0 ELSE 1 END,
-		   expiry_date, ticker`)
+		   expiry_date, ticker`, d.accountID())
 	if err != nil {
 		return nil, err
 	}
@@ -236,7 +453,7 @@ func (d *DB) GetActiveOptions(ctx context.Context) ([]Option, error) {
 		var o Option
 		var openFee, closePremium, closeFee *decimal.Decimal
 		var notes *string
-		err := rows.Scan(&o.ID, &o.Ticker, &o.OptionType, &o.Action, &o.Strike, &o.ExpiryDate, &o.Quantity, &o.Premium, &openFee, &closePremium, &closeFee, &o.Status, &notes, &o.CreatedAt, &o.UpdatedAt)
+		err := rows.Scan(&o.ID, &o.Ticker, &o.OptionType, &o.Action, &o.Strike, &o.ExpiryDate, &o.Quantity, &o.Multiplier, &o.Settlement, &o.Currency, &o.Premium, &openFee, &closePremium, &closeFee, &o.Status, &notes, &o.External, &o.CreatedAt, &o.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -259,10 +476,10 @@ func (d *DB) GetActiveOptions(ctx context.Context) ([]Option, error) {
 
 func (d *DB) GetExpiredActiveOptions(ctx context.Context) ([]Option, error) {
 	rows, err := d.pool.Query(ctx,
-		`SELECT id, ticker, option_type, action, strike, expiry_date, quantity, premium, open_fee, close_premium, close_fee, status, notes, created_at, updated_at
+		`SELECT id, ticker, option_type, action, strike, expiry_date, quantity, multiplier, settlement, currency, premium, open_fee, close_premium, close_fee, status, notes, external, created_at, updated_at
 		 FROM options
-		 WHERE status = 'ACTIVE' AND expiry_date < CURRENT_DATE
-		 ORDER BY expiry_date, ticker`)
+		 WHERE status = 'ACTIVE' AND expiry_date < CURRENT_DATE AND account_id IS NOT DISTINCT FROM $1
+		 ORDER BY expiry_date, ticker`, d.accountID())
 	if err != nil {
 		return nil, err
 	}
@@ -273,7 +490,7 @@ func (d *DB) GetExpiredActiveOptions(ctx context.Context) ([]Option, error) {
 		var o Option
 		var openFee, closePremium, closeFee *decimal.Decimal
 		var notes *string
-		err := rows.Scan(&o.ID, &o.Ticker, &o.OptionType, &o.Action, &o.Strike, &o.ExpiryDate, &o.Quantity, &o.Premium, &openFee, &closePremium, &closeFee, &o.Status, &notes, &o.CreatedAt, &o.UpdatedAt)
+		err := rows.Scan(&o.ID, &o.Ticker, &o.OptionType, &o.Action, &o.Strike, &o.ExpiryDate, &o.Quantity, &o.Multiplier, &o.Settlement, &o.Currency, &o.Premium, &openFee, &closePremium, &closeFee, &o.Status, &notes, &o.External, &o.CreatedAt, &o.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -294,16 +511,17 @@ func (d *DB) GetExpiredActiveOptions(ctx context.Context) ([]Option, error) {
 	return options, rows.Err()
 }
 
-func (d *DB) UpdateOption(ctx context.Context, id string, strike decimal.Decimal, expiryDate time.Time, quantity int, premium, openFee decimal.Decimal, notes string) error {
+func (d *DB) UpdateOption(ctx context.Context, id string, strike decimal.Decimal, expiryDate time.Time, quantity, multiplier int, settlement string, premium, openFee decimal.Decimal, notes string) error {
 	_, err := d.pool.Exec(ctx,
-		`UPDATE options SET strike = $2, expiry_date = $3, quantity = $4, premium = $5, open_fee = $6, notes = $7 WHERE id = $1`,
-		id, strike, expiryDate, quantity, premium, openFee, notes)
+		`UPDATE options SET strike = $2, expiry_date = $3, quantity = $4, multiplier = $5, settlement = $6, premium = $7, open_fee = $8, notes = $9 WHERE id = $1`,
+		id, strike, expiryDate, quantity, multiplier, settlement, premium, openFee, notes)
 	return err
 }
 
+// DeleteOption removes the option with id and its tags; its policies and
+// reminders cascade.
 func (d *DB) DeleteOption(ctx context.Context, id string) error {
-	_, err := d.pool.Exec(ctx, `DELETE FROM options WHERE id = $1`, id)
-	return err
+	return d.deleteTagged(ctx, "options", id)
 }
 
 func (d *DB) ExpireOption(ctx context.Context, id string) error {
@@ -316,34 +534,36 @@ func (d *DB) CloseOption(ctx context.Context, id string, closePremium, closeFee
 	var o Option
 	var notes *string
 	err := d.pool.QueryRow(ctx,
-		`SELECT id, ticker, option_type, action, strike, expiry_date, quantity, premium, status, notes FROM options WHERE id = $1`, id).
-		Scan(&o.ID, &o.Ticker, &o.OptionType, &o.Action, &o.Strike, &o.ExpiryDate, &o.Quantity, &o.Premium, &o.Status, &notes)
+		`SELECT id, ticker, option_type, action, strike, expiry_date, quantity, multiplier, currency, premium, status, notes, external FROM options WHERE id = $1`, id).
+		Scan(&o.ID, &o.Ticker, &o.OptionType, &o.Action, &o.Strike, &o.ExpiryDate, &o.Quantity, &o.Multiplier, &o.Currency, &o.Premium, &o.Status, &notes, &o.External)
 	if err != nil {
 		return err
 	}
+	if o.External {
+		_, err = d.pool.Exec(ctx, `UPDATE options SET status = 'CLOSED', close_premium = $2, close_fee = $3 WHERE id = $1`, id, closePremium, closeFee)
+		return err
+	}
 
-	// Calculate cash adjustment
-	// If originally SELL: we received premium, now we pay closePremium to close
-	// If originally BUY: we paid premium, now we receive closePremium to close
-	closeCost := closePremium.Mul(decimal.NewFromInt(int64(o.Quantity))).Mul(decimal.NewFromInt(100))
-
-	currentCash, err := d.GetAvailableCash(ctx)
+	rate, err := d.fxRate(ctx, o.Currency)
 	if err != nil {
-		currentCash = decimal.Zero
+		return err
 	}
 
+	// Calculate cash adjustment
+	// If originally SELL: we received premium, now we pay closePremium to close
+	// If originally BUY: we paid premium, now we receive closePremium to close
+	closeCost := closePremium.Mul(o.Shares()).Mul(rate)
 	if o.Action == "SELL" {
 		// Sold option, buying back to close = pay premium
-		currentCash = currentCash.Sub(closeCost)
-	} else {
-		// Bought option, selling to close = receive premium
-		currentCash = currentCash.Add(closeCost)
+		closeCost = closeCost.Neg()
+	}
+	// Bought option, selling to close = receive premium
+	entry := contractNotes(o.Action, o.OptionType, o.Strike, o.ExpiryDate)
+	if err := d.moveCash(ctx, TxClose, o.Ticker, decimal.NewFromInt(int64(o.Quantity)), closeCost, entry); err != nil {
+		return err
 	}
 	// Deduct closing fee
-	currentCash = currentCash.Sub(closeFee)
-
-	err = d.SetAvailableCash(ctx, currentCash)
-	if err != nil {
+	if err := d.moveCash(ctx, TxFee, o.Ticker, decimal.Zero, closeFee.Neg().Mul(rate), entry); err != nil {
 		return err
 	}
 
@@ -352,31 +572,42 @@ func (d *DB) CloseOption(ctx context.Context, id string, closePremium, closeFee
 	return err
 }
 
-func (d *DB) AssignOption(ctx context.Context, id string) error {
+// AssignOption settles an assigned option: shares change hands at the strike
+// and fee, the broker's assignment/exercise fee, is taken from cash and
+// recorded as the option's close fee. An external option is only marked
+// assigned.
+func (d *DB) AssignOption(ctx context.Context, id string, fee decimal.Decimal) error {
 	// Get the option details first
 	var o Option
 	var notes *string
 	err := d.pool.QueryRow(ctx,
-		`SELECT id, ticker, option_type, action, strike, expiry_date, quantity, premium, status, notes FROM options WHERE id = $1`, id).
-		Scan(&o.ID, &o.Ticker, &o.OptionType, &o.Action, &o.Strike, &o.ExpiryDate, &o.Quantity, &o.Premium, &o.Status, &notes)
+		`SELECT id, ticker, option_type, action, strike, expiry_date, quantity, multiplier, currency, premium, status, notes, external FROM options WHERE id = $1`, id).
+		Scan(&o.ID, &o.Ticker, &o.OptionType, &o.Action, &o.Strike, &o.ExpiryDate, &o.Quantity, &o.Multiplier, &o.Currency, &o.Premium, &o.Status, &notes, &o.External)
 	if err != nil {
 		return err
 	}
+	if o.External {
+		// Shares and cash change hands at the other broker
+		_, err = d.pool.Exec(ctx, `UPDATE options SET status = 'ASSIGNED', close_fee = $2 WHERE id = $1`, id, fee)
+		return err
+	}
 
-	// Calculate total value (strike × quantity × 100)
-	totalValue := o.Strike.Mul(decimal.NewFromInt(int64(o.Quantity))).Mul(decimal.NewFromInt(100))
-	shares := decimal.NewFromInt(int64(o.Quantity * 100))
-
-	// Get current cash
-	currentCash, err := d.GetAvailableCash(ctx)
+	rate, err := d.fxRate(ctx, o.Currency)
 	if err != nil {
-		currentCash = decimal.Zero
+		return err
 	}
 
+	// Calculate total value (strike × shares delivered)
+	shares := o.Shares()
+	totalValue := o.Strike.Mul(shares).Mul(rate)
+	entry := contractNotes(o.Action, o.OptionType, o.Strike, o.ExpiryDate)
+
 	if o.OptionType == "PUT" {
 		// PUT assigned: we buy shares at strike price
 		// Deduct cash, add to holdings
-		currentCash = currentCash.Sub(totalValue)
+		if err := d.moveOptionCash(ctx, TxAssignment, o.Ticker, o.ID, shares, totalValue.Neg(), entry); err != nil {
+			return err
+		}
 
 		// Check if holding exists, update or create
 		existing, err := d.GetHoldingByTicker(ctx, o.Ticker)
@@ -390,9 +621,13 @@ func (d *DB) AssignOption(ctx context.Context, id string) error {
 			totalCost := existing.Quantity.Mul(existing.AvgCost).Add(shares.Mul(o.Strike))
 			newAvgCost := totalCost.Div(totalShares)
 			err = d.UpdateHolding(ctx, existing.ID, totalShares, newAvgCost, existing.TargetPrice, existing.Notes)
+			if err == nil && existing.Archived {
+				// Shares put to us bring it back, as buying more does
+				err = d.SetHoldingArchived(ctx, existing.ID, false)
+			}
 		} else {
-			// Create new holding
-			err = d.AddHolding(ctx, o.Ticker, shares, o.Strike, time.Now(), decimal.NullDecimal{}, "Assigned from PUT option")
+			// Create new holding; the assignment above paid for it
+			err = d.insertHolding(ctx, o.Ticker, shares, o.Strike, o.Currency, time.Now(), decimal.NullDecimal{}, "Assigned from PUT option")
 		}
 		if err != nil {
 			return err
@@ -400,7 +635,9 @@ func (d *DB) AssignOption(ctx context.Context, id string) error {
 	} else {
 		// CALL assigned: we sell shares at strike price
 		// Add cash, remove from holdings
-		currentCash = currentCash.Add(totalValue)
+		if err := d.moveOptionCash(ctx, TxAssignment, o.Ticker, o.ID, shares.Neg(), totalValue, entry); err != nil {
+			return err
+		}
 
 		// Find and reduce/remove holding
 		existing, err := d.GetHoldingByTicker(ctx, o.Ticker)
@@ -423,14 +660,58 @@ func (d *DB) AssignOption(ctx context.Context, id string) error {
 		}
 	}
 
-	// Update cash
-	err = d.SetAvailableCash(ctx, currentCash)
+	// Deduct assignment fee
+	if err := d.moveOptionCash(ctx, TxFee, o.Ticker, o.ID, decimal.Zero, fee.Neg().Mul(rate), entry); err != nil {
+		return err
+	}
+
+	// Mark option as assigned with the fee
+	_, err = d.pool.Exec(ctx, `UPDATE options SET status = 'ASSIGNED', close_fee = $2 WHERE id = $1`, id, fee)
+	return err
+}
+
+// IntrinsicValue is what an option is worth per share at expiry with the
+// underlying at price
+func (o Option) IntrinsicValue(price decimal.Decimal) decimal.Decimal {
+	if o.OptionType == "CALL" {
+		return decimal.Max(price.Sub(o.Strike), decimal.Zero)
+	}
+	return decimal.Max(o.Strike.Sub(price), decimal.Zero)
+}
+
+// SettleOption assigns a cash-settled option at the underlying's settlement
+// price: the intrinsic value changes hands in cash, paid on a short option
+// and received on a long one, and no shares move. The intrinsic value is
+// kept as the close premium so premium stats count it like a buyback.
+func (d *DB) SettleOption(ctx context.Context, id string, price, fee decimal.Decimal) error {
+	var o Option
+	err := d.pool.QueryRow(ctx,
+		`SELECT id, ticker, option_type, action, strike, expiry_date, quantity, multiplier, currency, external FROM options WHERE id = $1`, id).
+		Scan(&o.ID, &o.Ticker, &o.OptionType, &o.Action, &o.Strike, &o.ExpiryDate, &o.Quantity, &o.Multiplier, &o.Currency, &o.External)
 	if err != nil {
 		return err
 	}
+	intrinsic := o.IntrinsicValue(price)
 
-	// Mark option as assigned
-	_, err = d.pool.Exec(ctx, `UPDATE options SET status = 'ASSIGNED' WHERE id = $1`, id)
+	if !o.External {
+		rate, err := d.fxRate(ctx, o.Currency)
+		if err != nil {
+			return err
+		}
+		settlement := intrinsic.Mul(o.Shares()).Mul(rate)
+		if o.Action == "SELL" {
+			settlement = settlement.Neg()
+		}
+		entry := contractNotes(o.Action, o.OptionType, o.Strike, o.ExpiryDate)
+		if err := d.moveCash(ctx, TxSettlement, o.Ticker, decimal.NewFromInt(int64(o.Quantity)), settlement, entry); err != nil {
+			return err
+		}
+		if err := d.moveCash(ctx, TxFee, o.Ticker, decimal.Zero, fee.Neg().Mul(rate), entry); err != nil {
+			return err
+		}
+	}
+
+	_, err = d.pool.Exec(ctx, `UPDATE options SET status = 'ASSIGNED', close_premium = $2, close_fee = $3 WHERE id = $1`, id, intrinsic, fee)
 	return err
 }
 
@@ -441,26 +722,58 @@ type PremiumSummary struct {
 	TotalFees     decimal.Decimal
 	CloseCosts    decimal.Decimal // Premium paid to close positions early
 	NetPL         decimal.Decimal // Premiums - Fees - Close costs
-	CapitalAtRisk decimal.Decimal // Total notional (strike × 100 × qty) for RoR calc
+	CapitalAtRisk decimal.Decimal // Total notional (strike × multiplier × qty) for RoR calc
+}
+
+// GetNetPremiumsByTicker returns the net premium of options sold in
+// [from, to) by underlying: premiums less fees and the cost of closing early,
+// as in PremiumSummary.NetPL.
+func (d *DB) GetNetPremiumsByTicker(ctx context.Context, from, to time.Time) (map[string]decimal.Decimal, error) {
+	rows, err := d.pool.Query(ctx,
+		`SELECT ticker, COALESCE(SUM(premium * quantity * multiplier
+		        - COALESCE(open_fee, 0) - COALESCE(close_fee, 0)
+		        - CASE WHEN status IN ('CLOSED', 'ASSIGNED') THEN COALESCE(close_premium, 0) * quantity * multiplier ELSE 0 END), 0)
+		 FROM options
+		 WHERE action = 'SELL' AND created_at >= $1 AND created_at < $2 AND account_id IS NOT DISTINCT FROM $3
+		 GROUP BY ticker`, from, to, d.accountID())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	net := make(map[string]decimal.Decimal)
+	for rows.Next() {
+		var ticker string
+		var amount decimal.Decimal
+		if err := rows.Scan(&ticker, &amount); err != nil {
+			return nil, err
+		}
+		net[ticker] = amount
+	}
+	return net, rows.Err()
 }
 
-func (d *DB) GetPremiumsByYear(ctx context.Context, year int) (*PremiumSummary, error) {
+// GetPremiumsBetween summarizes options sold in [from, to), e.g. a calendar or
+// tax year.
+func (d *DB) GetPremiumsBetween(ctx context.Context, from, to time.Time) (*PremiumSummary, error) {
 	var callPremiums, putPremiums, totalFees, closeCosts, capitalAtRisk decimal.Decimal
 
 	// Get CALL premiums sold
 	err := d.pool.QueryRow(ctx,
-		`SELECT COALESCE(SUM(premium * quantity * 100), 0) FROM options
+		`SELECT COALESCE(SUM(premium * quantity * multiplier), 0) FROM options
 		 WHERE action = 'SELL' AND option_type = 'CALL'
-		 AND EXTRACT(YEAR FROM created_at) = $1`, year).Scan(&callPremiums)
+		 AND created_at >= $1 AND created_at < $2
+		 AND account_id IS NOT DISTINCT FROM $3`, from, to, d.accountID()).Scan(&callPremiums)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get PUT premiums sold
 	err = d.pool.QueryRow(ctx,
-		`SELECT COALESCE(SUM(premium * quantity * 100), 0) FROM options
+		`SELECT COALESCE(SUM(premium * quantity * multiplier), 0) FROM options
 		 WHERE action = 'SELL' AND option_type = 'PUT'
-		 AND EXTRACT(YEAR FROM created_at) = $1`, year).Scan(&putPremiums)
+		 AND created_at >= $1 AND created_at < $2
+		 AND account_id IS NOT DISTINCT FROM $3`, from, to, d.accountID()).Scan(&putPremiums)
 	if err != nil {
 		return nil, err
 	}
@@ -469,25 +782,28 @@ func (d *DB) GetPremiumsByYear(ctx context.Context, year int) (*PremiumSummary,
 	err = d.pool.QueryRow(ctx,
 		`SELECT COALESCE(SUM(COALESCE(open_fee, 0) + COALESCE(close_fee, 0)), 0) FROM options
 		 WHERE action = 'SELL'
-		 AND EXTRACT(YEAR FROM created_at) = $1`, year).Scan(&totalFees)
+		 AND created_at >= $1 AND created_at < $2
+		 AND account_id IS NOT DISTINCT FROM $3`, from, to, d.accountID()).Scan(&totalFees)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get close costs (premium paid to buy back SELL options)
+	// Get close costs (premium paid to buy back SELL options, or settle them in cash)
 	err = d.pool.QueryRow(ctx,
-		`SELECT COALESCE(SUM(close_premium * quantity * 100), 0) FROM options
-		 WHERE action = 'SELL' AND status = 'CLOSED'
-		 AND EXTRACT(YEAR FROM created_at) = $1`, year).Scan(&closeCosts)
+		`SELECT COALESCE(SUM(close_premium * quantity * multiplier), 0) FROM options
+		 WHERE action = 'SELL' AND status IN ('CLOSED', 'ASSIGNED')
+		 AND created_at >= $1 AND created_at < $2
+		 AND account_id IS NOT DISTINCT FROM $3`, from, to, d.accountID()).Scan(&closeCosts)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get total capital at risk (strike × 100 × quantity) for all SELL options
+	// Get total capital at risk (strike × multiplier × quantity) for all SELL options
 	err = d.pool.QueryRow(ctx,
-		`SELECT COALESCE(SUM(strike * quantity * 100), 0) FROM options
+		`SELECT COALESCE(SUM(strike * quantity * multiplier), 0) FROM options
 		 WHERE action = 'SELL'
-		 AND EXTRACT(YEAR FROM created_at) = $1`, year).Scan(&capitalAtRisk)
+		 AND created_at >= $1 AND created_at < $2
+		 AND account_id IS NOT DISTINCT FROM $3`, from, to, d.accountID()).Scan(&capitalAtRisk)
 	if err != nil {
 		return nil, err
 	}
@@ -505,3 +821,35 @@ func (d *DB) GetPremiumsByYear(ctx context.Context, year int) (*PremiumSummary,
 		CapitalAtRisk: capitalAtRisk,
 	}, nil
 }
+
+// GetPremiumsByCurrency summarizes options sold in [from, to) like
+// GetPremiumsBetween, separately for each currency they were sold in.
+func (d *DB) GetPremiumsByCurrency(ctx context.Context, from, to time.Time) (map[string]PremiumSummary, error) {
+	rows, err := d.pool.Query(ctx,
+		`SELECT currency,
+		        COALESCE(SUM(CASE WHEN option_type = 'CALL' THEN premium * quantity * multiplier END), 0),
+		        COALESCE(SUM(CASE WHEN option_type = 'PUT' THEN premium * quantity * multiplier END), 0),
+		        COALESCE(SUM(COALESCE(open_fee, 0) + COALESCE(close_fee, 0)), 0),
+		        COALESCE(SUM(CASE WHEN status IN ('CLOSED', 'ASSIGNED') THEN close_premium * quantity * multiplier END), 0),
+		        COALESCE(SUM(strike * quantity * multiplier), 0)
+		 FROM options
+		 WHERE action = 'SELL' AND created_at >= $1 AND created_at < $2 AND account_id IS NOT DISTINCT FROM $3
+		 GROUP BY currency`, from, to, d.accountID())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byCurrency := make(map[string]PremiumSummary)
+	for rows.Next() {
+		var currency string
+		var p PremiumSummary
+		if err := rows.Scan(&currency, &p.CallPremiums, &p.PutPremiums, &p.TotalFees, &p.CloseCosts, &p.CapitalAtRisk); err != nil {
+			return nil, err
+		}
+		p.TotalPremiums = p.CallPremiums.Add(p.PutPremiums)
+		p.NetPL = p.TotalPremiums.Sub(p.TotalFees).Sub(p.CloseCosts)
+		byCurrency[currency] = p
+	}
+	return byCurrency, rows.Err()
+}
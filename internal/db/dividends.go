@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DividendPayment is a dividend received on a holding, as cash.
+type DividendPayment struct {
+	ID        string
+	Ticker    string
+	Amount    decimal.Decimal
+	PaidOn    time.Time
+	Notes     string
+	CreatedAt time.Time
+}
+
+// AddDividend records a dividend received on ticker and credits it to
+// available cash.
+func (d *DB) AddDividend(ctx context.Context, ticker string, amount decimal.Decimal, paidOn time.Time, notes string) error {
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO dividends (ticker, amount, paid_on, notes, account_id) VALUES ($1, $2, $3, NULLIF($4, ''), $5)`,
+		ticker, amount, paidOn, notes, d.accountID())
+	if err != nil {
+		return err
+	}
+
+	return d.moveCash(ctx, TxDividend, ticker, decimal.Zero, amount, notes)
+}
+
+// GetDividendsSince returns the account's dividends paid on or after since,
+// oldest first.
+func (d *DB) GetDividendsSince(ctx context.Context, since time.Time) ([]DividendPayment, error) {
+	rows, err := d.pool.Query(ctx,
+		`SELECT id, ticker, amount, paid_on, COALESCE(notes, ''), created_at FROM dividends
+		 WHERE paid_on >= $1 AND account_id IS NOT DISTINCT FROM $2
+		 ORDER BY paid_on, created_at`, since, d.accountID())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []DividendPayment
+	for rows.Next() {
+		var p DividendPayment
+		if err := rows.Scan(&p.ID, &p.Ticker, &p.Amount, &p.PaidOn, &p.Notes, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
@@ -0,0 +1,22 @@
+package db
+
+import "context"
+
+// ClaimFill marks the broker's fill id as recorded before its trade is, so a
+// retried webhook is only booked once. claimed is false if it already was.
+func (d *DB) ClaimFill(ctx context.Context, fillID string) (claimed bool, err error) {
+	tag, err := d.pool.Exec(ctx,
+		`INSERT INTO webhook_fills (fill_id, account_id) VALUES ($1, $2) ON CONFLICT (fill_id) DO NOTHING`,
+		fillID, d.accountID())
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ReleaseFill forgets a claimed fill whose trade could not be recorded, so
+// the broker's retry can record it.
+func (d *DB) ReleaseFill(ctx context.Context, fillID string) error {
+	_, err := d.pool.Exec(ctx, `DELETE FROM webhook_fills WHERE fill_id = $1`, fillID)
+	return err
+}
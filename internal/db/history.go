@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetPriceBars returns ticker's stored daily bars from since on, oldest
+// first. Each bar is timestamped at midnight UTC of its day.
+func (d *DB) GetPriceBars(ctx context.Context, ticker string, since time.Time) ([]analytics.DailyBar, error) {
+	rows, err := d.pool.Query(ctx,
+		`SELECT day, open, high, low, close FROM price_history
+		 WHERE ticker = $1 AND day >= $2
+		 ORDER BY day`, ticker, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bars []analytics.DailyBar
+	for rows.Next() {
+		var day time.Time
+		var b analytics.DailyBar
+		if err := rows.Scan(&day, &b.Open, &b.High, &b.Low, &b.Close); err != nil {
+			return nil, err
+		}
+		b.Time = day.Unix()
+		bars = append(bars, b)
+	}
+	return bars, rows.Err()
+}
+
+// SavePriceBars stores ticker's daily bars, replacing any stored for the
+// same days.
+func (d *DB) SavePriceBars(ctx context.Context, ticker string, bars []analytics.DailyBar) error {
+	batch := &pgx.Batch{}
+	for _, b := range bars {
+		batch.Queue(
+			`INSERT INTO price_history (ticker, day, open, high, low, close) VALUES ($1, $2, $3, $4, $5, $6)
+			 ON CONFLICT (ticker, day) DO UPDATE SET open = $3, high = $4, low = $5, close = $6`,
+			ticker, time.Unix(b.Time, 0).UTC().Format(time.DateOnly), b.Open, b.High, b.Low, b.Close)
+	}
+	return d.pool.SendBatch(ctx, batch).Close()
+}
+
+// GetPriceHistoryLatest returns the day of the latest stored bar by ticker.
+func (d *DB) GetPriceHistoryLatest(ctx context.Context) (map[string]time.Time, error) {
+	rows, err := d.pool.Query(ctx, `SELECT ticker, MAX(day) FROM price_history GROUP BY ticker`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	latest := make(map[string]time.Time)
+	for rows.Next() {
+		var ticker string
+		var day time.Time
+		if err := rows.Scan(&ticker, &day); err != nil {
+			return nil, err
+		}
+		latest[ticker] = day
+	}
+	return latest, rows.Err()
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TradeIdea is a short option accepted from an advisor but not yet filled:
+// the contract and premium intended, booked as an option once the order
+// fills at the broker.
+type TradeIdea struct {
+	ID         string
+	Ticker     string
+	OptionType string // CALL or PUT
+	Strike     decimal.Decimal
+	ExpiryDate time.Time
+	Quantity   int
+	Premium    decimal.Decimal // Intended premium per share
+	Notes      string
+	CreatedAt  time.Time
+}
+
+// AddTradeIdea queues an idea to sell quantity contracts at premium.
+func (d *DB) AddTradeIdea(ctx context.Context, ticker, optionType string, strike decimal.Decimal, expiryDate time.Time, quantity int, premium decimal.Decimal, notes string) error {
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO trade_ideas (ticker, option_type, strike, expiry_date, quantity, premium, notes)
+		 VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))`,
+		ticker, optionType, strike, expiryDate, quantity, premium, notes)
+	return err
+}
+
+func (d *DB) DeleteTradeIdea(ctx context.Context, id string) error {
+	_, err := d.pool.Exec(ctx, `DELETE FROM trade_ideas WHERE id = $1`, id)
+	return err
+}
+
+// GetTradeIdeas returns the queued ideas, oldest first.
+func (d *DB) GetTradeIdeas(ctx context.Context) ([]TradeIdea, error) {
+	rows, err := d.pool.Query(ctx,
+		`SELECT id, ticker, option_type, strike, expiry_date, quantity, premium, notes, created_at
+		 FROM trade_ideas ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ideas []TradeIdea
+	for rows.Next() {
+		var idea TradeIdea
+		var notes *string
+		if err := rows.Scan(&idea.ID, &idea.Ticker, &idea.OptionType, &idea.Strike, &idea.ExpiryDate,
+			&idea.Quantity, &idea.Premium, &notes, &idea.CreatedAt); err != nil {
+			return nil, err
+		}
+		if notes != nil {
+			idea.Notes = *notes
+		}
+		ideas = append(ideas, idea)
+	}
+	return ideas, rows.Err()
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Transaction kinds: what moved cash, or shares for a split
+const (
+	TxBuy          = "BUY"          // Shares bought
+	TxSell         = "SELL"         // Shares sold
+	TxPremium      = "PREMIUM"      // Option opened: premium received or paid
+	TxClose        = "CLOSE"        // Option closed early: bought back or sold
+	TxAssignment   = "ASSIGNMENT"   // Shares delivered at the strike
+	TxSettlement   = "SETTLEMENT"   // Cash-settled option's intrinsic value
+	TxFee          = "FEE"          // Commissions and assignment fees
+	TxInterest     = "INTEREST"     // Interest on cash
+	TxDividend     = "DIVIDEND"     // Dividends received on holdings
+	TxContribution = "CONTRIBUTION" // Deposits, negative for withdrawals
+	TxAdjustment   = "ADJUSTMENT"   // Cash set by hand
+	TxSplit        = "SPLIT"        // Stock split: the shares it added, no cash
+)
+
+// Transaction is one entry in the cash ledger. An account's cash is the sum
+// of its entries; each records the balance it left, so the cash figure can
+// be traced back entry by entry.
+type Transaction struct {
+	ID         string
+	Kind       string
+	Ticker     string
+	Quantity   decimal.Decimal // Shares or contracts, zero for cash-only entries
+	Amount     decimal.Decimal // Cash in, negative for cash out
+	Balance    decimal.Decimal // Cash after the transaction
+	Notes      string
+	OptionID   string          // The option assigned, for assignments and their fees
+	SplitDate  time.Time       // The date of the split applied, for splits
+	SplitRatio decimal.Decimal // Its new shares per old
+	CreatedAt  time.Time
+}
+
+// GetTransactions returns the account's ledger, oldest first.
+func (d *DB) GetTransactions(ctx context.Context) ([]Transaction, error) {
+	rows, err := d.pool.Query(ctx,
+		`SELECT id, kind, COALESCE(ticker, ''), quantity, amount, balance, COALESCE(notes, ''), COALESCE(option_id::text, ''), split_date, COALESCE(split_ratio, 0), created_at
+		 FROM transactions WHERE account_id IS NOT DISTINCT FROM $1 ORDER BY created_at, seq`, d.accountID())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var txs []Transaction
+	for rows.Next() {
+		var t Transaction
+		var splitDate *time.Time
+		if err := rows.Scan(&t.ID, &t.Kind, &t.Ticker, &t.Quantity, &t.Amount, &t.Balance, &t.Notes, &t.OptionID, &splitDate, &t.SplitRatio, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		if splitDate != nil {
+			t.SplitDate = *splitDate
+		}
+		txs = append(txs, t)
+	}
+	return txs, rows.Err()
+}
+
+// AdjustCash moves cash by amount and records it in the ledger as kind, e.g.
+// a stock commission as TxFee.
+func (d *DB) AdjustCash(ctx context.Context, kind, ticker string, amount decimal.Decimal, notes string) error {
+	return d.moveCash(ctx, kind, ticker, decimal.Zero, amount, notes)
+}
+
+// moveCash adds amount to cash and records the transaction with the balance
+// it leaves. Nothing is recorded for a zero amount.
+func (d *DB) moveCash(ctx context.Context, kind, ticker string, quantity, amount decimal.Decimal, notes string) error {
+	return d.moveOptionCash(ctx, kind, ticker, "", quantity, amount, notes)
+}
+
+// moveOptionCash is moveCash for an entry that came from the option with
+// optionID, so the shares it moved can be traced back to it.
+func (d *DB) moveOptionCash(ctx context.Context, kind, ticker, optionID string, quantity, amount decimal.Decimal, notes string) error {
+	if amount.IsZero() {
+		return nil
+	}
+	// Cash is the ledger's sum, so the entry is the change
+	cash, err := d.GetAvailableCash(ctx)
+	if err != nil {
+		return err
+	}
+	balance := cash.Add(amount)
+	_, err = d.pool.Exec(ctx,
+		`INSERT INTO transactions (kind, ticker, quantity, amount, balance, notes, account_id, option_id) VALUES ($1, NULLIF($2, ''), $3, $4, $5, NULLIF($6, ''), $7, NULLIF($8, '')::uuid)`,
+		kind, ticker, quantity, amount, balance, notes, d.accountID(), optionID)
+	return err
+}
+
+// ErrNoFXRate is returned for a trade in a currency there is no saved rate
+// into the base currency for.
+var ErrNoFXRate = errors.New("no exchange rate to book cash at; refresh quotes first")
+
+// fxRate returns the rate cash in currency is booked at in the base
+// currency: 1 for the base currency, otherwise the rate saved last with
+// SetFXRates. Without one the trade is refused rather than booked 1:1.
+func (d *DB) fxRate(ctx context.Context, currency string) (decimal.Decimal, error) {
+	base, err := d.GetBaseCurrency(ctx)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if currency == "" || currency == base {
+		return decimal.NewFromInt(1), nil
+	}
+	fx, err := d.GetFXRates(ctx)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	rate, ok := fx.Rates[currency]
+	if fx.Base != base || !ok {
+		return decimal.Zero, fmt.Errorf("%w: %s to %s", ErrNoFXRate, currency, base)
+	}
+	return rate, nil
+}
+
+// contractNotes describes an option for its ledger entries, e.g.
+// "SELL PUT 200 2026-03-20"
+func contractNotes(action, optionType string, strike decimal.Decimal, expiry time.Time) string {
+	return fmt.Sprintf("%s %s %s %s", action, optionType, strike.String(), expiry.Format(time.DateOnly))
+}
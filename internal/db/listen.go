@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+)
+
+// ChangeChannel is the NOTIFY channel raised by the schema_sync.sql triggers
+const ChangeChannel = "anyhowhodl_changes"
+
+// Listen blocks on a dedicated connection, calling onChange with the changed
+// table's name for each notification, until ctx is done or the connection
+// fails. It needs a direct or session-mode connection; transaction-mode
+// poolers (e.g. Supabase's port 6543) do not deliver notifications.
+func (d *DB) Listen(ctx context.Context, onChange func(table string)) error {
+	pooled, err := d.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// Take the connection out of the pool: it stays subscribed and may be
+	// left mid-read when ctx is cancelled
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+ChangeChannel); err != nil {
+		return err
+	}
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		onChange(n.Payload)
+	}
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// OpenInterest is the open interest of one listed contract as seen on Day.
+// Yahoo reports the previous session's figure, so one snapshot a day is
+// enough to follow its change.
+type OpenInterest struct {
+	Ticker       string
+	OptionType   string // CALL or PUT
+	Expiry       time.Time
+	Strike       float64
+	Day          time.Time // Midnight UTC
+	OpenInterest int
+}
+
+// SameContract reports whether o and other are snapshots of the same contract.
+func (o OpenInterest) SameContract(other OpenInterest) bool {
+	return o.Ticker == other.Ticker && o.OptionType == other.OptionType &&
+		o.Expiry.Equal(other.Expiry) && o.Strike == other.Strike
+}
+
+// GetOpenInterest returns the snapshots taken on or after since, oldest first.
+func (d *DB) GetOpenInterest(ctx context.Context, since time.Time) ([]OpenInterest, error) {
+	rows, err := d.pool.Query(ctx,
+		`SELECT ticker, option_type, expiry, strike, day, open_interest FROM option_open_interest
+		 WHERE day >= $1 ORDER BY day`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []OpenInterest
+	for rows.Next() {
+		var o OpenInterest
+		if err := rows.Scan(&o.Ticker, &o.OptionType, &o.Expiry, &o.Strike, &o.Day, &o.OpenInterest); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, o)
+	}
+	return snapshots, rows.Err()
+}
+
+// SaveOpenInterest stores snapshots, replacing any taken of the same
+// contracts on the same days.
+func (d *DB) SaveOpenInterest(ctx context.Context, snapshots []OpenInterest) error {
+	batch := &pgx.Batch{}
+	for _, o := range snapshots {
+		batch.Queue(
+			`INSERT INTO option_open_interest (ticker, option_type, expiry, strike, day, open_interest) VALUES ($1, $2, $3, $4, $5, $6)
+			 ON CONFLICT (ticker, option_type, expiry, strike, day) DO UPDATE SET open_interest = $6`,
+			o.Ticker, o.OptionType, o.Expiry.Format(time.DateOnly), o.Strike, o.Day.Format(time.DateOnly), o.OpenInterest)
+	}
+	return d.pool.SendBatch(ctx, batch).Close()
+}
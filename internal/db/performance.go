@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type PortfolioSnapshot struct {
+	Date          time.Time
+	HoldingsValue decimal.Decimal
+	Cash          decimal.Decimal
+	OptionsCredit decimal.Decimal // Net premium received on options still open, included in Cash
+}
+
+// Total returns holdings value plus cash.
+func (s PortfolioSnapshot) Total() decimal.Decimal {
+	return s.HoldingsValue.Add(s.Cash)
+}
+
+type Contribution struct {
+	ID            string
+	Amount        decimal.Decimal // Negative for withdrawals
+	ContributedOn time.Time
+	Notes         string
+	CreatedAt     time.Time
+}
+
+// QuarterAmount is a total for the calendar quarter starting at Start.
+type QuarterAmount struct {
+	Start  time.Time
+	Amount decimal.Decimal
+}
+
+// RecordPortfolioSnapshot stores today's portfolio value, overwriting any earlier snapshot from today.
+func (d *DB) RecordPortfolioSnapshot(ctx context.Context, holdingsValue, cash, optionsCredit decimal.Decimal) error {
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO portfolio_snapshots (snapshot_date, holdings_value, cash, options_credit, updated_at) VALUES (CURRENT_DATE, $1, $2, $3, NOW())
+		 ON CONFLICT (snapshot_date) DO UPDATE SET holdings_value = $1, cash = $2, options_credit = $3, updated_at = NOW()`,
+		holdingsValue, cash, optionsCredit)
+	return err
+}
+
+// GetPortfolioSnapshots returns snapshots on or after since, oldest first.
+func (d *DB) GetPortfolioSnapshots(ctx context.Context, since time.Time) ([]PortfolioSnapshot, error) {
+	rows, err := d.pool.Query(ctx,
+		`SELECT snapshot_date, holdings_value, cash, options_credit FROM portfolio_snapshots
+		 WHERE snapshot_date >= $1
+		 ORDER BY snapshot_date`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []PortfolioSnapshot
+	for rows.Next() {
+		var s PortfolioSnapshot
+		if err := rows.Scan(&s.Date, &s.HoldingsValue, &s.Cash, &s.OptionsCredit); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+// AddContribution records a deposit (positive) or withdrawal (negative) and adjusts available cash.
+func (d *DB) AddContribution(ctx context.Context, amount decimal.Decimal, contributedOn time.Time, notes string) error {
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO contributions (amount, contributed_on, notes) VALUES ($1, $2, $3)`,
+		amount, contributedOn, notes)
+	if err != nil {
+		return err
+	}
+
+	return d.moveCash(ctx, TxContribution, "", decimal.Zero, amount, notes)
+}
+
+// GetContributionsByQuarter returns net contributions per quarter on or after since.
+func (d *DB) GetContributionsByQuarter(ctx context.Context, since time.Time) ([]QuarterAmount, error) {
+	return d.queryQuarterAmounts(ctx,
+		`SELECT date_trunc('quarter', contributed_on)::date, SUM(amount) FROM contributions
+		 WHERE contributed_on >= $1
+		 GROUP BY 1 ORDER BY 1`, since)
+}
+
+// GetInterestByQuarter returns interest received per quarter on or after since.
+func (d *DB) GetInterestByQuarter(ctx context.Context, since time.Time) ([]QuarterAmount, error) {
+	return d.queryQuarterAmounts(ctx,
+		`SELECT date_trunc('quarter', received_on)::date, SUM(amount) FROM interest_payments
+		 WHERE received_on >= $1
+		 GROUP BY 1 ORDER BY 1`, since)
+}
+
+// GetOptionIncomeByQuarter returns net option cash flow per quarter (by open date)
+// for options opened on or after since: premiums received minus premiums paid,
+// fees, and buyback costs. External options are left out, as their cash is
+// elsewhere.
+func (d *DB) GetOptionIncomeByQuarter(ctx context.Context, since time.Time) ([]QuarterAmount, error) {
+	return d.queryQuarterAmounts(ctx,
+		`SELECT date_trunc('quarter', created_at)::date,
+		        SUM(
+		          CASE WHEN action = 'SELL' THEN 1 ELSE -1 END * premium * quantity * multiplier
+		          - COALESCE(open_fee, 0)
+		          - CASE WHEN status IN ('CLOSED', 'ASSIGNED')
+		                 THEN CASE WHEN action = 'SELL' THEN 1 ELSE -1 END * COALESCE(close_premium, 0) * quantity * multiplier
+		                 ELSE 0 END
+		          - COALESCE(close_fee, 0)
+		        )
+		 FROM options
+		 WHERE created_at >= $1 AND NOT external AND account_id IS NOT DISTINCT FROM $2
+		 GROUP BY 1 ORDER BY 1`, since, d.accountID())
+}
+
+func (d *DB) queryQuarterAmounts(ctx context.Context, sql string, args ...any) ([]QuarterAmount, error) {
+	rows, err := d.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var amounts []QuarterAmount
+	for rows.Next() {
+		var q QuarterAmount
+		if err := rows.Scan(&q.Start, &q.Amount); err != nil {
+			return nil, err
+		}
+		amounts = append(amounts, q)
+	}
+	return amounts, rows.Err()
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func cleanPerformanceTables(t *testing.T, d *DB) {
+	t.Helper()
+	clean := func() {
+		ctx := context.Background()
+		d.pool.Exec(ctx, `DELETE FROM portfolio_snapshots`)
+		d.pool.Exec(ctx, `DELETE FROM contributions`)
+	}
+	clean()
+	t.Cleanup(clean)
+}
+
+func TestRecordPortfolioSnapshot(t *testing.T) {
+	d := testDB(t)
+	cleanPerformanceTables(t, d)
+	ctx := context.Background()
+
+	if err := d.RecordPortfolioSnapshot(ctx, decimal.NewFromInt(9000), decimal.NewFromInt(1000), decimal.NewFromInt(250)); err != nil {
+		t.Fatalf("RecordPortfolioSnapshot: %v", err)
+	}
+
+	snapshots, err := d.GetPortfolioSnapshots(ctx, time.Now().AddDate(0, 0, -1))
+	if err != nil {
+		t.Fatalf("GetPortfolioSnapshots: %v", err)
+	}
+	if len(snapshots) != 1 {
+		t.Fatalf("expected 1 snapshot, got %d", len(snapshots))
+	}
+	if !snapshots[0].Total().Equal(decimal.NewFromInt(10000)) {
+		t.Errorf("expected total 10000, got %s", snapshots[0].Total())
+	}
+	if !snapshots[0].OptionsCredit.Equal(decimal.NewFromInt(250)) {
+		t.Errorf("expected options credit 250, got %s", snapshots[0].OptionsCredit)
+	}
+}
+
+func TestGetContributionsByQuarter(t *testing.T) {
+	d := testDB(t)
+	cleanPerformanceTables(t, d)
+	ctx := context.Background()
+
+	original, _ := d.GetAvailableCash(ctx)
+	t.Cleanup(func() { d.SetAvailableCash(context.Background(), original) })
+
+	q1 := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	q2 := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	_ = d.AddContribution(ctx, decimal.NewFromInt(1000), q1, "deposit")
+	_ = d.AddContribution(ctx, decimal.NewFromInt(-200), q1, "withdrawal")
+	_ = d.AddContribution(ctx, decimal.NewFromInt(500), q2, "deposit")
+
+	amounts, err := d.GetContributionsByQuarter(ctx, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetContributionsByQuarter: %v", err)
+	}
+	if len(amounts) != 2 {
+		t.Fatalf("expected 2 quarters, got %d", len(amounts))
+	}
+	if !amounts[0].Amount.Equal(decimal.NewFromInt(800)) {
+		t.Errorf("expected Q1 800, got %s", amounts[0].Amount)
+	}
+
+	cash, _ := d.GetAvailableCash(ctx)
+	if !cash.Equal(original.Add(decimal.NewFromInt(1300))) {
+		t.Errorf("expected cash adjusted by 1300, got %s (was %s)", cash, original)
+	}
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Policy action kinds and statuses
+const (
+	PolicyActionClose = "CLOSE"
+	PolicyActionRoll  = "ROLL"
+
+	PolicyActionPending   = "PENDING"
+	PolicyActionDone      = "DONE"
+	PolicyActionDismissed = "DISMISSED"
+)
+
+// OptionPolicy is a rule attached to one option position, such as rolling at
+// 21 DTE or closing at 50% profit. Kind is one of the analytics.Policy kinds.
+type OptionPolicy struct {
+	ID        string
+	OptionID  string
+	Kind      string
+	Threshold decimal.Decimal // Days or percent, depending on Kind
+	ITMOnly   bool
+}
+
+// PolicyAction is a close or roll suggested by a policy, waiting for
+// confirmation. RollExpiry is zero when no expiry was suggested.
+type PolicyAction struct {
+	ID         string
+	PolicyID   string
+	OptionID   string
+	Action     string // PolicyActionClose or PolicyActionRoll
+	Reason     string
+	Price      decimal.NullDecimal // Option mark when evaluated
+	RollExpiry time.Time
+	Status     string
+	CreatedAt  time.Time
+}
+
+// GetOptionPolicies returns every policy, oldest first.
+func (d *DB) GetOptionPolicies(ctx context.Context) ([]OptionPolicy, error) {
+	rows, err := d.pool.Query(ctx,
+		`SELECT id, option_id, kind, threshold, itm_only FROM option_policies ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []OptionPolicy
+	for rows.Next() {
+		var p OptionPolicy
+		if err := rows.Scan(&p.ID, &p.OptionID, &p.Kind, &p.Threshold, &p.ITMOnly); err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+func (d *DB) AddOptionPolicy(ctx context.Context, optionID, kind string, threshold decimal.Decimal, itmOnly bool) error {
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO option_policies (option_id, kind, threshold, itm_only) VALUES ($1, $2, $3, $4)`,
+		optionID, kind, threshold, itmOnly)
+	return err
+}
+
+func (d *DB) DeleteOptionPolicy(ctx context.Context, id string) error {
+	_, err := d.pool.Exec(ctx, `DELETE FROM option_policies WHERE id = $1`, id)
+	return err
+}
+
+// GetPendingPolicyActions returns the actions waiting for confirmation, oldest
+// first.
+func (d *DB) GetPendingPolicyActions(ctx context.Context) ([]PolicyAction, error) {
+	rows, err := d.pool.Query(ctx,
+		`SELECT id, policy_id, option_id, action, reason, price, roll_expiry, status, created_at
+		 FROM policy_actions WHERE status = 'PENDING' ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []PolicyAction
+	for rows.Next() {
+		var a PolicyAction
+		var rollExpiry *time.Time
+		if err := rows.Scan(&a.ID, &a.PolicyID, &a.OptionID, &a.Action, &a.Reason, &a.Price, &rollExpiry, &a.Status, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		if rollExpiry != nil {
+			a.RollExpiry = *rollExpiry
+		}
+		actions = append(actions, a)
+	}
+	return actions, rows.Err()
+}
+
+// QueuePolicyAction adds a pending action unless its policy already has one.
+// queued reports whether it was added.
+func (d *DB) QueuePolicyAction(ctx context.Context, a PolicyAction) (queued bool, err error) {
+	var rollExpiry *time.Time
+	if !a.RollExpiry.IsZero() {
+		rollExpiry = &a.RollExpiry
+	}
+	tag, err := d.pool.Exec(ctx,
+		`INSERT INTO policy_actions (policy_id, option_id, action, reason, price, roll_expiry)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (policy_id) WHERE status = 'PENDING' DO NOTHING`,
+		a.PolicyID, a.OptionID, a.Action, a.Reason, a.Price, rollExpiry)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ResolvePolicyAction marks a pending action done or dismissed.
+func (d *DB) ResolvePolicyAction(ctx context.Context, id, status string) error {
+	_, err := d.pool.Exec(ctx, `UPDATE policy_actions SET status = $2 WHERE id = $1`, id, status)
+	return err
+}
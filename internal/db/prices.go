@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ManualPrice is a price entered by hand for a symbol Yahoo has no data for.
+// UpdatedAt tells how stale it is.
+type ManualPrice struct {
+	Ticker    string
+	Price     decimal.Decimal
+	UpdatedAt time.Time
+}
+
+// GetManualPrices returns the manual prices by ticker.
+func (d *DB) GetManualPrices(ctx context.Context) (map[string]ManualPrice, error) {
+	rows, err := d.pool.Query(ctx, `SELECT ticker, price, updated_at FROM manual_prices`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := make(map[string]ManualPrice)
+	for rows.Next() {
+		var p ManualPrice
+		if err := rows.Scan(&p.Ticker, &p.Price, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		prices[p.Ticker] = p
+	}
+	return prices, rows.Err()
+}
+
+// SetManualPrice records price for ticker as of now, replacing any earlier one.
+func (d *DB) SetManualPrice(ctx context.Context, ticker string, price decimal.Decimal) error {
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO manual_prices (ticker, price, updated_at) VALUES ($1, $2, NOW())
+		 ON CONFLICT (ticker) DO UPDATE SET price = $2, updated_at = NOW()`,
+		ticker, price)
+	return err
+}
+
+func (d *DB) DeleteManualPrice(ctx context.Context, ticker string) error {
+	_, err := d.pool.Exec(ctx, `DELETE FROM manual_prices WHERE ticker = $1`, ticker)
+	return err
+}
+
+// StaleSymbol is a symbol quotes have repeatedly failed for, with the last
+// price seen before they did. LastPrice is invalid, and LastPriceAt zero, if
+// the symbol never had a quote.
+type StaleSymbol struct {
+	Ticker      string
+	Failures    int
+	LastPrice   decimal.NullDecimal
+	LastPriceAt time.Time
+}
+
+// GetStaleSymbols returns the symbols with failed quotes by ticker.
+func (d *DB) GetStaleSymbols(ctx context.Context) (map[string]StaleSymbol, error) {
+	rows, err := d.pool.Query(ctx, `SELECT ticker, failures, last_price, last_price_at FROM stale_symbols`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stale := make(map[string]StaleSymbol)
+	for rows.Next() {
+		var s StaleSymbol
+		var at *time.Time
+		if err := rows.Scan(&s.Ticker, &s.Failures, &s.LastPrice, &at); err != nil {
+			return nil, err
+		}
+		if at != nil {
+			s.LastPriceAt = *at
+		}
+		stale[s.Ticker] = s
+	}
+	return stale, rows.Err()
+}
+
+// RecordQuoteFailure counts a failed quote for ticker. The last price is kept
+// from the first failure on.
+func (d *DB) RecordQuoteFailure(ctx context.Context, ticker string, lastPrice decimal.NullDecimal, lastPriceAt time.Time) error {
+	var at *time.Time
+	if lastPrice.Valid {
+		at = &lastPriceAt
+	}
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO stale_symbols (ticker, last_price, last_price_at) VALUES ($1, $2, $3)
+		 ON CONFLICT (ticker) DO UPDATE SET failures = stale_symbols.failures + 1, updated_at = NOW()`,
+		ticker, lastPrice, at)
+	return err
+}
+
+// ClearStaleSymbol forgets the failures of a symbol that quotes again.
+func (d *DB) ClearStaleSymbol(ctx context.Context, ticker string) error {
+	_, err := d.pool.Exec(ctx, `DELETE FROM stale_symbols WHERE ticker = $1`, ticker)
+	return err
+}
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// TickerProfile is a ticker's sector and industry as classified by Yahoo.
+type TickerProfile struct {
+	Ticker    string
+	Sector    string
+	Industry  string
+	FetchedAt time.Time
+}
+
+// GetTickerProfiles returns the cached profile of each ticker.
+func (d *DB) GetTickerProfiles(ctx context.Context) (map[string]TickerProfile, error) {
+	rows, err := d.pool.Query(ctx, `SELECT ticker, sector, industry, fetched_at FROM ticker_profiles`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := make(map[string]TickerProfile)
+	for rows.Next() {
+		var p TickerProfile
+		if err := rows.Scan(&p.Ticker, &p.Sector, &p.Industry, &p.FetchedAt); err != nil {
+			return nil, err
+		}
+		profiles[p.Ticker] = p
+	}
+	return profiles, rows.Err()
+}
+
+// SaveTickerProfile caches a ticker's profile, replacing any earlier one.
+func (d *DB) SaveTickerProfile(ctx context.Context, p TickerProfile) error {
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO ticker_profiles (ticker, sector, industry, fetched_at) VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (ticker) DO UPDATE SET sector = $2, industry = $3, fetched_at = $4`,
+		p.Ticker, p.Sector, p.Industry, p.FetchedAt)
+	return err
+}
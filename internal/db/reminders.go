@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// Reminder is a dated note attached to an option, e.g. "evaluate roll". It is
+// due from RemindOn until dismissed.
+type Reminder struct {
+	ID       string
+	OptionID string
+	RemindOn time.Time
+	Note     string
+}
+
+// Due reports whether the reminder is due by today, given as midnight UTC
+// like RemindOn.
+func (r Reminder) Due(today time.Time) bool {
+	return !r.RemindOn.After(today)
+}
+
+// GetReminders returns the reminders not yet dismissed, soonest first.
+func (d *DB) GetReminders(ctx context.Context) ([]Reminder, error) {
+	rows, err := d.pool.Query(ctx,
+		`SELECT id, option_id, remind_on, note FROM option_reminders
+		 WHERE NOT dismissed AND option_id IN (SELECT id FROM options WHERE account_id IS NOT DISTINCT FROM $1)
+		 ORDER BY remind_on, created_at`, d.accountID())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reminders []Reminder
+	for rows.Next() {
+		var r Reminder
+		if err := rows.Scan(&r.ID, &r.OptionID, &r.RemindOn, &r.Note); err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, r)
+	}
+	return reminders, rows.Err()
+}
+
+func (d *DB) AddReminder(ctx context.Context, optionID string, remindOn time.Time, note string) error {
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO option_reminders (option_id, remind_on, note) VALUES ($1, $2, $3)`,
+		optionID, remindOn, note)
+	return err
+}
+
+func (d *DB) DismissReminder(ctx context.Context, id string) error {
+	_, err := d.pool.Exec(ctx, `UPDATE option_reminders SET dismissed = TRUE WHERE id = $1`, id)
+	return err
+}
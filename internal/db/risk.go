@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+)
+
+// Risk cap scopes
+const (
+	CapScopeTicker = "TICKER"
+	CapScopeSector = "SECTOR"
+)
+
+// RiskCap limits exposure to one ticker, or to every ticker in a sector, as a
+// percent of the portfolio.
+type RiskCap struct {
+	ID     string
+	Scope  string // CapScopeTicker or CapScopeSector
+	Name   string // Ticker symbol or sector name
+	MaxPct decimal.Decimal
+}
+
+// GetRiskCaps returns all caps, tickers first, then by name.
+func (d *DB) GetRiskCaps(ctx context.Context) ([]RiskCap, error) {
+	rows, err := d.pool.Query(ctx,
+		`SELECT id, scope, name, max_pct FROM risk_caps ORDER BY scope DESC, name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var caps []RiskCap
+	for rows.Next() {
+		var c RiskCap
+		if err := rows.Scan(&c.ID, &c.Scope, &c.Name, &c.MaxPct); err != nil {
+			return nil, err
+		}
+		caps = append(caps, c)
+	}
+	return caps, rows.Err()
+}
+
+// SetRiskCap adds a cap, or replaces the limit of an existing cap with the same
+// scope and name.
+func (d *DB) SetRiskCap(ctx context.Context, scope, name string, maxPct decimal.Decimal) error {
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO risk_caps (scope, name, max_pct) VALUES ($1, $2, $3)
+		 ON CONFLICT (scope, name) DO UPDATE SET max_pct = $3`,
+		scope, name, maxPct)
+	return err
+}
+
+func (d *DB) DeleteRiskCap(ctx context.Context, id string) error {
+	_, err := d.pool.Exec(ctx, `DELETE FROM risk_caps WHERE id = $1`, id)
+	return err
+}
+
+// GetTickerSectors returns the sector assigned to each ticker.
+func (d *DB) GetTickerSectors(ctx context.Context) (map[string]string, error) {
+	rows, err := d.pool.Query(ctx, `SELECT ticker, sector FROM ticker_sectors`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sectors := make(map[string]string)
+	for rows.Next() {
+		var ticker, sector string
+		if err := rows.Scan(&ticker, &sector); err != nil {
+			return nil, err
+		}
+		sectors[ticker] = sector
+	}
+	return sectors, rows.Err()
+}
+
+// SetTickerSector assigns ticker to sector. An empty sector removes the
+// assignment.
+func (d *DB) SetTickerSector(ctx context.Context, ticker, sector string) error {
+	if sector == "" {
+		_, err := d.pool.Exec(ctx, `DELETE FROM ticker_sectors WHERE ticker = $1`, ticker)
+		return err
+	}
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO ticker_sectors (ticker, sector, updated_at) VALUES ($1, $2, NOW())
+		 ON CONFLICT (ticker) DO UPDATE SET sector = $2, updated_at = NOW()`,
+		ticker, sector)
+	return err
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrOversold is returned when selling more shares than are held.
+var ErrOversold = errors.New("more shares than held")
+
+// StockSale is shares sold out of a holding.
+type StockSale struct {
+	ID        string
+	Ticker    string
+	Quantity  decimal.Decimal
+	Price     decimal.Decimal // Per share
+	AvgCost   decimal.Decimal // Holding's average cost at the sale
+	Currency  string
+	SoldOn    time.Time
+	Notes     string
+	CreatedAt time.Time
+}
+
+// Proceeds is the cash the sale brought in.
+func (s StockSale) Proceeds() decimal.Decimal {
+	return s.Quantity.Mul(s.Price)
+}
+
+// RealizedPL is the sale's profit over the shares' average cost.
+func (s StockSale) RealizedPL() decimal.Decimal {
+	return s.Quantity.Mul(s.Price.Sub(s.AvgCost))
+}
+
+// SellHolding sells quantity shares of ticker at price: the sale is recorded
+// with the holding's average cost, the holding is reduced (removed if none
+// are left, its average cost unchanged otherwise), and the proceeds are
+// credited to available cash.
+func (d *DB) SellHolding(ctx context.Context, ticker string, quantity, price decimal.Decimal, soldOn time.Time, notes string) error {
+	h, err := d.GetHoldingByTicker(ctx, ticker)
+	if err != nil {
+		return err
+	}
+	if h == nil || quantity.GreaterThan(h.Quantity) {
+		return ErrOversold
+	}
+	rate, err := d.fxRate(ctx, h.Currency)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.pool.Exec(ctx,
+		`INSERT INTO stock_sales (ticker, quantity, price, avg_cost, currency, sold_on, notes, account_id) VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8)`,
+		ticker, quantity, price, h.AvgCost, h.Currency, soldOn, notes, d.accountID())
+	if err != nil {
+		return err
+	}
+
+	if remaining := h.Quantity.Sub(quantity); remaining.IsZero() {
+		err = d.DeleteHolding(ctx, h.ID)
+	} else {
+		err = d.UpdateHolding(ctx, h.ID, remaining, h.AvgCost, h.TargetPrice, h.Notes)
+	}
+	if err != nil {
+		return err
+	}
+
+	return d.moveCash(ctx, TxSell, ticker, quantity.Neg(), quantity.Mul(price).Mul(rate), notes)
+}
+
+// GetSalesSince returns the account's stock sales on or after since, oldest
+// first.
+func (d *DB) GetSalesSince(ctx context.Context, since time.Time) ([]StockSale, error) {
+	rows, err := d.pool.Query(ctx,
+		`SELECT id, ticker, quantity, price, avg_cost, currency, sold_on, COALESCE(notes, ''), created_at FROM stock_sales
+		 WHERE sold_on >= $1 AND account_id IS NOT DISTINCT FROM $2
+		 ORDER BY sold_on, created_at`, since, d.accountID())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sales []StockSale
+	for rows.Next() {
+		var s StockSale
+		if err := rows.Scan(&s.ID, &s.Ticker, &s.Quantity, &s.Price, &s.AvgCost, &s.Currency, &s.SoldOn, &s.Notes, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		sales = append(sales, s)
+	}
+	return sales, rows.Err()
+}
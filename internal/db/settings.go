@@ -0,0 +1,480 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// getSetting returns the value for key, or ok=false if it is not set.
+func (d *DB) getSetting(ctx context.Context, key string) (string, bool, error) {
+	var value string
+	err := d.pool.QueryRow(ctx, `SELECT value FROM settings WHERE key = $1`, key).Scan(&value)
+	if err == pgx.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+func (d *DB) setSetting(ctx context.Context, key, value string) error {
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO settings (key, value, updated_at) VALUES ($1, $2, NOW())
+		 ON CONFLICT (key) DO UPDATE SET value = $2, updated_at = NOW()`,
+		key, value)
+	return err
+}
+
+// GetRiskFreeRate returns the configured annual risk-free rate (e.g. 0.05).
+// Returns an invalid NullDecimal if no rate has been configured.
+func (d *DB) GetRiskFreeRate(ctx context.Context) (decimal.NullDecimal, error) {
+	value, ok, err := d.getSetting(ctx, "risk_free_rate")
+	if err != nil || !ok {
+		return decimal.NullDecimal{}, err
+	}
+	rate, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.NullDecimal{}, err
+	}
+	return decimal.NullDecimal{Decimal: rate, Valid: true}, nil
+}
+
+func (d *DB) SetRiskFreeRate(ctx context.Context, rate decimal.Decimal) error {
+	return d.setSetting(ctx, "risk_free_rate", rate.String())
+}
+
+// GetUIState returns the TUI session state saved on the last quit, or an empty
+// string if none has been saved. The format is owned by the TUI.
+func (d *DB) GetUIState(ctx context.Context) (string, error) {
+	value, _, err := d.getSetting(ctx, "ui_state")
+	return value, err
+}
+
+func (d *DB) SetUIState(ctx context.Context, state string) error {
+	return d.setSetting(ctx, "ui_state", state)
+}
+
+// GetQuoteCache returns the quotes saved by the last refresh, or an empty
+// string if none have been saved. The format is owned by the TUI.
+func (d *DB) GetQuoteCache(ctx context.Context) (string, error) {
+	value, _, err := d.getSetting(ctx, "quote_cache")
+	return value, err
+}
+
+func (d *DB) SetQuoteCache(ctx context.Context, cache string) error {
+	return d.setSetting(ctx, "quote_cache", cache)
+}
+
+// GetLocale returns the name of the display locale, or an empty string if
+// none has been chosen.
+func (d *DB) GetLocale(ctx context.Context) (string, error) {
+	value, _, err := d.getSetting(ctx, "locale")
+	return value, err
+}
+
+func (d *DB) SetLocale(ctx context.Context, name string) error {
+	return d.setSetting(ctx, "locale", name)
+}
+
+// GetTaxYearStart returns the first day of the tax year as MM-DD, or an empty
+// string for the calendar year.
+func (d *DB) GetTaxYearStart(ctx context.Context) (string, error) {
+	value, _, err := d.getSetting(ctx, "tax_year_start")
+	return value, err
+}
+
+func (d *DB) SetTaxYearStart(ctx context.Context, start string) error {
+	return d.setSetting(ctx, "tax_year_start", start)
+}
+
+// GetIndicatorHistory returns the price history indicators are computed from
+// as range/interval, e.g. "6mo/1wk", or an empty string for the default.
+func (d *DB) GetIndicatorHistory(ctx context.Context) (string, error) {
+	value, _, err := d.getSetting(ctx, "indicator_history")
+	return value, err
+}
+
+func (d *DB) SetIndicatorHistory(ctx context.Context, history string) error {
+	return d.setSetting(ctx, "indicator_history", history)
+}
+
+// GetChainCacheTTL returns how long fetched option chains are reused as a
+// duration, e.g. "5m", or an empty string for the default.
+func (d *DB) GetChainCacheTTL(ctx context.Context) (string, error) {
+	value, _, err := d.getSetting(ctx, "chain_cache_ttl")
+	return value, err
+}
+
+func (d *DB) SetChainCacheTTL(ctx context.Context, ttl string) error {
+	return d.setSetting(ctx, "chain_cache_ttl", ttl)
+}
+
+// AssignmentFee is what the broker charges when an option is assigned or
+// exercised: a flat amount per assignment plus an amount per contract.
+type AssignmentFee struct {
+	PerAssignment decimal.Decimal
+	PerContract   decimal.Decimal
+}
+
+// For returns the fee for assigning contracts contracts at once.
+func (f AssignmentFee) For(contracts int) decimal.Decimal {
+	return f.PerAssignment.Add(f.PerContract.Mul(decimal.NewFromInt(int64(contracts))))
+}
+
+// GetAssignmentFee returns the configured assignment/exercise fee, zero if
+// none has been configured.
+func (d *DB) GetAssignmentFee(ctx context.Context) (AssignmentFee, error) {
+	var fee AssignmentFee
+	for key, dst := range map[string]*decimal.Decimal{
+		"assignment_fee":              &fee.PerAssignment,
+		"assignment_fee_per_contract": &fee.PerContract,
+	} {
+		value, ok, err := d.getSetting(ctx, key)
+		if err != nil {
+			return AssignmentFee{}, err
+		}
+		if !ok {
+			continue
+		}
+		if *dst, err = decimal.NewFromString(value); err != nil {
+			return AssignmentFee{}, err
+		}
+	}
+	return fee, nil
+}
+
+func (d *DB) SetAssignmentFee(ctx context.Context, fee AssignmentFee) error {
+	if err := d.setSetting(ctx, "assignment_fee", fee.PerAssignment.String()); err != nil {
+		return err
+	}
+	return d.setSetting(ctx, "assignment_fee_per_contract", fee.PerContract.String())
+}
+
+// Precision is how many decimal places shares and per-share prices (costs,
+// quotes, strikes, premiums) are shown with. Amounts always have two.
+type Precision struct {
+	Shares int32
+	Prices int32
+}
+
+// DefaultPrecision is the precision until another is configured.
+var DefaultPrecision = Precision{Shares: 2, Prices: 2}
+
+// GetPrecision returns the configured display precision, DefaultPrecision
+// for any part not configured.
+func (d *DB) GetPrecision(ctx context.Context) (Precision, error) {
+	p := DefaultPrecision
+	for key, dst := range map[string]*int32{
+		"share_decimals": &p.Shares,
+		"price_decimals": &p.Prices,
+	} {
+		value, ok, err := d.getSetting(ctx, key)
+		if err != nil {
+			return DefaultPrecision, err
+		}
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return DefaultPrecision, err
+		}
+		*dst = int32(n)
+	}
+	return p, nil
+}
+
+func (d *DB) SetPrecision(ctx context.Context, p Precision) error {
+	if err := d.setSetting(ctx, "share_decimals", strconv.Itoa(int(p.Shares))); err != nil {
+		return err
+	}
+	return d.setSetting(ctx, "price_decimals", strconv.Itoa(int(p.Prices)))
+}
+
+// DefaultDriftTolerance is how far, in percentage points, a holding may
+// drift from its target weight before it is flagged, until configured.
+var DefaultDriftTolerance = decimal.NewFromInt(2)
+
+// TargetWeights are the portfolio weights, in percent, holdings are meant to
+// have, by ticker, and the drift Tolerance in percentage points.
+type TargetWeights struct {
+	Weights   map[string]decimal.Decimal `json:"weights"`
+	Tolerance decimal.Decimal            `json:"tolerance"`
+}
+
+// GetTargetWeights returns the target weights, none with
+// DefaultDriftTolerance if they have not been set.
+func (d *DB) GetTargetWeights(ctx context.Context) (TargetWeights, error) {
+	targets := TargetWeights{Weights: map[string]decimal.Decimal{}, Tolerance: DefaultDriftTolerance}
+	value, ok, err := d.getSetting(ctx, "target_weights")
+	if err != nil || !ok {
+		return targets, err
+	}
+	if err := json.Unmarshal([]byte(value), &targets); err != nil {
+		return TargetWeights{}, err
+	}
+	if targets.Weights == nil {
+		targets.Weights = map[string]decimal.Decimal{}
+	}
+	return targets, nil
+}
+
+func (d *DB) SetTargetWeights(ctx context.Context, targets TargetWeights) error {
+	value, err := json.Marshal(targets)
+	if err != nil {
+		return err
+	}
+	return d.setSetting(ctx, "target_weights", string(value))
+}
+
+// Tables a custom column can be added to
+const (
+	ColumnTableHoldings = "HOLDINGS"
+	ColumnTableOptions  = "OPTIONS"
+)
+
+// CustomColumn is a user-defined table column computed per row from an
+// expression over the row's fields.
+type CustomColumn struct {
+	Name  string `json:"name"`
+	Table string `json:"table"` // ColumnTableHoldings or ColumnTableOptions
+	Expr  string `json:"expr"`
+}
+
+// GetCustomColumns returns the user-defined columns in display order.
+func (d *DB) GetCustomColumns(ctx context.Context) ([]CustomColumn, error) {
+	value, ok, err := d.getSetting(ctx, "custom_columns")
+	if err != nil || !ok {
+		return nil, err
+	}
+	var columns []CustomColumn
+	if err := json.Unmarshal([]byte(value), &columns); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+func (d *DB) SetCustomColumns(ctx context.Context, columns []CustomColumn) error {
+	value, err := json.Marshal(columns)
+	if err != nil {
+		return err
+	}
+	return d.setSetting(ctx, "custom_columns", string(value))
+}
+
+// GetHoldingsColumns returns the built-in holdings table columns to show, by
+// header, in display order; nil until chosen, for all of them.
+func (d *DB) GetHoldingsColumns(ctx context.Context) ([]string, error) {
+	value, ok, err := d.getSetting(ctx, "holdings_columns")
+	if err != nil || !ok {
+		return nil, err
+	}
+	var columns []string
+	if err := json.Unmarshal([]byte(value), &columns); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+func (d *DB) SetHoldingsColumns(ctx context.Context, columns []string) error {
+	value, err := json.Marshal(columns)
+	if err != nil {
+		return err
+	}
+	return d.setSetting(ctx, "holdings_columns", string(value))
+}
+
+// CSPSignal is a user-declared signal in the CSP advisor's composite score,
+// scored 0-100 by an expression over the csp.SignalVars.
+type CSPSignal struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+	Expr   string  `json:"expr"`
+}
+
+// GetCSPSignals returns the user-declared CSP signals.
+func (d *DB) GetCSPSignals(ctx context.Context) ([]CSPSignal, error) {
+	value, ok, err := d.getSetting(ctx, "csp_signals")
+	if err != nil || !ok {
+		return nil, err
+	}
+	var signals []CSPSignal
+	if err := json.Unmarshal([]byte(value), &signals); err != nil {
+		return nil, err
+	}
+	return signals, nil
+}
+
+func (d *DB) SetCSPSignals(ctx context.Context, signals []CSPSignal) error {
+	value, err := json.Marshal(signals)
+	if err != nil {
+		return err
+	}
+	return d.setSetting(ctx, "csp_signals", string(value))
+}
+
+// LoggedAlert is an alert as it first fired, kept for the weekly review.
+type LoggedAlert struct {
+	Key     string    `json:"key"`
+	Kind    string    `json:"kind"`
+	Message string    `json:"message"`
+	FiredAt time.Time `json:"fired_at"`
+}
+
+// GetAlertLog returns the alerts fired recently, oldest first.
+func (d *DB) GetAlertLog(ctx context.Context) ([]LoggedAlert, error) {
+	value, ok, err := d.getSetting(ctx, "alert_log")
+	if err != nil || !ok {
+		return nil, err
+	}
+	var alerts []LoggedAlert
+	if err := json.Unmarshal([]byte(value), &alerts); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+func (d *DB) SetAlertLog(ctx context.Context, alerts []LoggedAlert) error {
+	value, err := json.Marshal(alerts)
+	if err != nil {
+		return err
+	}
+	return d.setSetting(ctx, "alert_log", string(value))
+}
+
+// ScoreSnapshot is the CSP advisor's composite score of each watchlist
+// ticker on one day.
+type ScoreSnapshot struct {
+	Day    time.Time          `json:"day"`
+	Scores map[string]float64 `json:"scores"`
+}
+
+// GetScoreHistory returns the recent daily CSP watchlist scores, oldest first.
+func (d *DB) GetScoreHistory(ctx context.Context) ([]ScoreSnapshot, error) {
+	value, ok, err := d.getSetting(ctx, "score_history")
+	if err != nil || !ok {
+		return nil, err
+	}
+	var history []ScoreSnapshot
+	if err := json.Unmarshal([]byte(value), &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (d *DB) SetScoreHistory(ctx context.Context, history []ScoreSnapshot) error {
+	value, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	return d.setSetting(ctx, "score_history", string(value))
+}
+
+// GetPinned returns the IDs of the holdings and options pinned to the top of
+// their tables.
+func (d *DB) GetPinned(ctx context.Context) ([]string, error) {
+	value, ok, err := d.getSetting(ctx, "pinned")
+	if err != nil || !ok {
+		return nil, err
+	}
+	var ids []string
+	if err := json.Unmarshal([]byte(value), &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (d *DB) SetPinned(ctx context.Context, ids []string) error {
+	value, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+	return d.setSetting(ctx, "pinned", string(value))
+}
+
+// GetBaseCurrency returns the currency values are converted into for
+// display, DefaultCurrency if none has been chosen.
+func (d *DB) GetBaseCurrency(ctx context.Context) (string, error) {
+	value, ok, err := d.getSetting(ctx, "base_currency")
+	if err != nil || !ok {
+		return DefaultCurrency, err
+	}
+	return value, nil
+}
+
+func (d *DB) SetBaseCurrency(ctx context.Context, currency string) error {
+	return d.setSetting(ctx, "base_currency", currency)
+}
+
+// FXRates are the rates into Base, as fetched with the quotes, that cash for
+// trades in other currencies is booked at.
+type FXRates struct {
+	Base  string                     `json:"base"`
+	Rates map[string]decimal.Decimal `json:"rates"`
+}
+
+// GetFXRates returns the rates saved last, none if never saved.
+func (d *DB) GetFXRates(ctx context.Context) (FXRates, error) {
+	var rates FXRates
+	value, ok, err := d.getSetting(ctx, "fx_rates")
+	if err != nil || !ok {
+		return rates, err
+	}
+	if err := json.Unmarshal([]byte(value), &rates); err != nil {
+		return FXRates{}, err
+	}
+	return rates, nil
+}
+
+func (d *DB) SetFXRates(ctx context.Context, rates FXRates) error {
+	value, err := json.Marshal(rates)
+	if err != nil {
+		return err
+	}
+	return d.setSetting(ctx, "fx_rates", string(value))
+}
+
+// GetAlertBell reports whether the terminal bell rings when an alert fires
+// in the TUI. It is off unless turned on.
+func (d *DB) GetAlertBell(ctx context.Context) (bool, error) {
+	value, ok, err := d.getSetting(ctx, "alert_bell")
+	if err != nil || !ok {
+		return false, err
+	}
+	return value == "true", nil
+}
+
+func (d *DB) SetAlertBell(ctx context.Context, on bool) error {
+	return d.setSetting(ctx, "alert_bell", strconv.FormatBool(on))
+}
+
+// GetVIXPercentileScoring reports whether the CSP advisor scores VIX by its
+// percentile over several years instead of at fixed levels
+func (d *DB) GetVIXPercentileScoring(ctx context.Context) (bool, error) {
+	value, ok, err := d.getSetting(ctx, "vix_percentile_scoring")
+	if err != nil || !ok {
+		return false, err
+	}
+	return value == "true", nil
+}
+
+func (d *DB) SetVIXPercentileScoring(ctx context.Context, on bool) error {
+	return d.setSetting(ctx, "vix_percentile_scoring", strconv.FormatBool(on))
+}
+
+// GetNotifyRoutes returns which notification channels each kind of event is
+// sent to, as text like "itm=telegram,desktop; *=terminal", empty if unset
+func (d *DB) GetNotifyRoutes(ctx context.Context) (string, error) {
+	value, _, err := d.getSetting(ctx, "notify_routes")
+	return value, err
+}
+
+func (d *DB) SetNotifyRoutes(ctx context.Context, routes string) error {
+	return d.setSetting(ctx, "notify_routes", routes)
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// Split returns o adjusted for a stock split of ratio new shares per old, as
+// listed contracts are: a whole-number split multiplies the contracts, any
+// other ratio (3-for-2, or a reverse split) the shares each delivers. Strike
+// and premium shrink by the ratio, so what the contracts are worth is
+// unchanged. It fails if the contracts cannot be adjusted to whole numbers.
+func (o Option) Split(ratio decimal.Decimal) (Option, error) {
+	if !ratio.IsPositive() {
+		return o, fmt.Errorf("invalid split ratio %s", ratio)
+	}
+	if ratio.IsInteger() {
+		o.Quantity = int(decimal.NewFromInt(int64(o.Quantity)).Mul(ratio).IntPart())
+	} else {
+		multiplier := decimal.NewFromInt(int64(o.Multiplier)).Mul(ratio)
+		if !multiplier.IsInteger() || multiplier.IsZero() {
+			return o, fmt.Errorf("%s %s %s contracts of %d shares cannot be adjusted for a %s-for-1 split",
+				o.Ticker, o.OptionType, o.Strike, o.Multiplier, ratio)
+		}
+		o.Multiplier = int(multiplier.IntPart())
+	}
+	o.Strike = o.Strike.Div(ratio).Round(2)
+	o.Premium = o.Premium.Div(ratio).Round(4)
+	return o, nil
+}
+
+// Split returns h adjusted for a stock split of ratio new shares per old:
+// more shares at a proportionally lower cost and target.
+func (h Holding) Split(ratio decimal.Decimal) Holding {
+	h.Quantity = h.Quantity.Mul(ratio)
+	h.AvgCost = h.AvgCost.Div(ratio).Round(4)
+	if h.TargetPrice.Valid {
+		h.TargetPrice.Decimal = h.TargetPrice.Decimal.Div(ratio).Round(4)
+	}
+	return h
+}
+
+// ApplySplit adjusts the account's holding and the active options on ticker
+// opened before date for a stock split of ratio new shares per old on date
+// (options opened since are on post-split terms already), and records the split
+// in the ledger with the shares it added, no cash, and its date and ratio.
+// It is all or nothing: a contract that cannot be adjusted, or a failed
+// write, leaves everything as it was.
+func (d *DB) ApplySplit(ctx context.Context, ticker string, ratio decimal.Decimal, date time.Time, notes string) error {
+	all, err := d.GetActiveOptions(ctx)
+	if err != nil {
+		return err
+	}
+	var adjusted []Option
+	for _, o := range all {
+		if o.Ticker != ticker || o.Status != "ACTIVE" || !o.CreatedAt.Before(date) {
+			continue
+		}
+		split, err := o.Split(ratio)
+		if err != nil {
+			return err
+		}
+		adjusted = append(adjusted, split)
+	}
+
+	holding, err := d.GetHoldingByTicker(ctx, ticker)
+	if err != nil {
+		return err
+	}
+	cash, err := d.GetAvailableCash(ctx)
+	if err != nil {
+		return err
+	}
+
+	return pgx.BeginFunc(ctx, d.pool, func(tx pgx.Tx) error {
+		added := decimal.Zero
+		if holding != nil {
+			split := holding.Split(ratio)
+			added = split.Quantity.Sub(holding.Quantity)
+			_, err := tx.Exec(ctx,
+				`UPDATE holdings SET quantity = $2, avg_cost = $3, target_price = $4 WHERE id = $1`,
+				split.ID, split.Quantity, split.AvgCost, split.TargetPrice)
+			if err != nil {
+				return err
+			}
+		}
+		for _, o := range adjusted {
+			_, err := tx.Exec(ctx,
+				`UPDATE options SET quantity = $2, multiplier = $3, strike = $4, premium = $5 WHERE id = $1`,
+				o.ID, o.Quantity, o.Multiplier, o.Strike, o.Premium)
+			if err != nil {
+				return err
+			}
+		}
+		_, err := tx.Exec(ctx,
+			`INSERT INTO transactions (kind, ticker, quantity, amount, balance, notes, account_id, split_date, split_ratio) VALUES ($1, $2, $3, 0, $4, NULLIF($5, ''), $6, $7, $8)`,
+			TxSplit, ticker, added, cash, notes, d.accountID(), date, ratio)
+		return err
+	})
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestApplySplitSkipsLaterOptions(t *testing.T) {
+	d := testDB(t)
+	ctx := context.Background()
+	clean := func() {
+		d.pool.Exec(context.Background(), `DELETE FROM options WHERE ticker = 'ZZSPLIT'`)
+		d.pool.Exec(context.Background(), `DELETE FROM transactions WHERE ticker = 'ZZSPLIT'`)
+	}
+	clean()
+	t.Cleanup(clean)
+
+	split := time.Now().AddDate(0, 0, -10).Truncate(24 * time.Hour)
+	expiry := time.Now().AddDate(0, 2, 0)
+	for _, strike := range []int64{100, 50} {
+		if err := d.AddExternalOption(ctx, "ZZSPLIT", "PUT", "SELL", decimal.NewFromInt(strike), expiry, 1, DefaultMultiplier, SettlementPhysical, DefaultCurrency, decimal.NewFromInt(2), decimal.Zero, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The $100 put was sold before the split, the $50 one on its terms since
+	if _, err := d.pool.Exec(ctx, `UPDATE options SET created_at = $1 WHERE ticker = 'ZZSPLIT' AND strike = 100`, split.AddDate(0, 0, -5)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.ApplySplit(ctx, "ZZSPLIT", decimal.NewFromInt(2), split, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	options, err := d.GetActiveOptions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seen int
+	for _, o := range options {
+		if o.Ticker != "ZZSPLIT" {
+			continue
+		}
+		seen++
+		if o.Strike.Equal(decimal.NewFromInt(50)) && o.Quantity == 2 {
+			continue // The $100 put, split 2-for-1
+		}
+		if o.Strike.Equal(decimal.NewFromInt(50)) && o.Quantity == 1 {
+			continue // The later put, untouched
+		}
+		t.Errorf("option after split: %d × $%s", o.Quantity, o.Strike)
+	}
+	if seen != 2 {
+		t.Errorf("%d ZZSPLIT options, want 2", seen)
+	}
+}
@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+
+	"github.com/shopspring/decimal"
+)
+
+// Store is the persistence API used by the app. *DB implements it against
+// Postgres; internal/fake provides an in-memory implementation for tests.
+type Store interface {
+	// Holdings
+	AddHolding(ctx context.Context, ticker string, quantity, avgCost decimal.Decimal, currency string, entryDate time.Time, targetPrice decimal.NullDecimal, notes string) error
+	GetHoldings(ctx context.Context) ([]Holding, error)
+	GetHoldingByTicker(ctx context.Context, ticker string) (*Holding, error)
+	UpdateHolding(ctx context.Context, id string, quantity, avgCost decimal.Decimal, targetPrice decimal.NullDecimal, notes string) error
+	UpdateHoldingIfUnchanged(ctx context.Context, id string, readAt time.Time, quantity, avgCost decimal.Decimal, targetPrice decimal.NullDecimal, notes string) error
+	SetHoldingPosition(ctx context.Context, ticker string, quantity, avgCost decimal.Decimal, entryDate time.Time) error
+	BulkAddHoldings(ctx context.Context, holdings []Holding) error
+	SetTickerCurrency(ctx context.Context, ticker, currency string) error
+	SetHoldingArchived(ctx context.Context, id string, archived bool) error
+	DeleteHolding(ctx context.Context, id string) error
+
+	// Cash
+	GetAvailableCash(ctx context.Context) (decimal.Decimal, error)
+	SetAvailableCash(ctx context.Context, amount decimal.Decimal) error
+	AdjustCash(ctx context.Context, kind, ticker string, amount decimal.Decimal, notes string) error
+	GetTransactions(ctx context.Context) ([]Transaction, error)
+	RecordCashSnapshot(ctx context.Context, amount decimal.Decimal) error
+	GetCashSnapshots(ctx context.Context, since time.Time) ([]CashSnapshot, error)
+	AddInterestPayment(ctx context.Context, amount decimal.Decimal, receivedOn time.Time, notes string) error
+	GetInterestSince(ctx context.Context, since time.Time) (decimal.Decimal, error)
+	AddDividend(ctx context.Context, ticker string, amount decimal.Decimal, paidOn time.Time, notes string) error
+	GetDividendsSince(ctx context.Context, since time.Time) ([]DividendPayment, error)
+	SellHolding(ctx context.Context, ticker string, quantity, price decimal.Decimal, soldOn time.Time, notes string) error
+	GetSalesSince(ctx context.Context, since time.Time) ([]StockSale, error)
+
+	// Options
+	AddOption(ctx context.Context, ticker, optionType, action string, strike decimal.Decimal, expiryDate time.Time, quantity, multiplier int, settlement, currency string, premium, openFee decimal.Decimal, notes string) error
+	AddExternalOption(ctx context.Context, ticker, optionType, action string, strike decimal.Decimal, expiryDate time.Time, quantity, multiplier int, settlement, currency string, premium, openFee decimal.Decimal, notes string) error
+	AddToOption(ctx context.Context, id string, quantity int, premium, openFee decimal.Decimal, notes string) error
+	AddHistoricalOption(ctx context.Context, o Option) error
+	GetActiveOptions(ctx context.Context) ([]Option, error)
+	GetExpiredActiveOptions(ctx context.Context) ([]Option, error)
+	UpdateOption(ctx context.Context, id string, strike decimal.Decimal, expiryDate time.Time, quantity, multiplier int, settlement string, premium, openFee decimal.Decimal, notes string) error
+	UpdateOptionIfUnchanged(ctx context.Context, id string, readAt time.Time, strike decimal.Decimal, expiryDate time.Time, quantity, multiplier int, settlement string, premium, openFee decimal.Decimal, notes string) error
+	DeleteOption(ctx context.Context, id string) error
+	ExpireOption(ctx context.Context, id string) error
+	CloseOption(ctx context.Context, id string, closePremium, closeFee decimal.Decimal) error
+	AssignOption(ctx context.Context, id string, fee decimal.Decimal) error
+	SettleOption(ctx context.Context, id string, price, fee decimal.Decimal) error
+	ApplySplit(ctx context.Context, ticker string, ratio decimal.Decimal, date time.Time, notes string) error
+	GetPremiumsBetween(ctx context.Context, from, to time.Time) (*PremiumSummary, error)
+	GetPremiumsByCurrency(ctx context.Context, from, to time.Time) (map[string]PremiumSummary, error)
+	GetNetPremiumsByTicker(ctx context.Context, from, to time.Time) (map[string]decimal.Decimal, error)
+
+	// CSP watchlist
+	AddCSPWatchTicker(ctx context.Context, ticker, notes string) error
+	SetCSPWatchExpiries(ctx context.Context, ticker, expiries string) error
+	RemoveCSPWatchTicker(ctx context.Context, ticker string) error
+	GetCSPWatchlist(ctx context.Context) ([]CSPWatchItem, error)
+
+	// Stock watchlist
+	SetWatchTicker(ctx context.Context, ticker string, targetPrice decimal.NullDecimal, notes string) error
+	RemoveWatchTicker(ctx context.Context, ticker string) error
+	GetWatchlist(ctx context.Context) ([]WatchItem, error)
+
+	// Trade ideas
+	AddTradeIdea(ctx context.Context, ticker, optionType string, strike decimal.Decimal, expiryDate time.Time, quantity int, premium decimal.Decimal, notes string) error
+	DeleteTradeIdea(ctx context.Context, id string) error
+	GetTradeIdeas(ctx context.Context) ([]TradeIdea, error)
+
+	// Tags
+	GetTags(ctx context.Context) (map[string][]string, error)
+	SetTags(ctx context.Context, id string, tags []string) error
+
+	// Performance
+	RecordPortfolioSnapshot(ctx context.Context, holdingsValue, cash, optionsCredit decimal.Decimal) error
+	GetPortfolioSnapshots(ctx context.Context, since time.Time) ([]PortfolioSnapshot, error)
+	AddContribution(ctx context.Context, amount decimal.Decimal, contributedOn time.Time, notes string) error
+	GetContributionsByQuarter(ctx context.Context, since time.Time) ([]QuarterAmount, error)
+	GetInterestByQuarter(ctx context.Context, since time.Time) ([]QuarterAmount, error)
+	GetOptionIncomeByQuarter(ctx context.Context, since time.Time) ([]QuarterAmount, error)
+
+	// Risk caps
+	GetRiskCaps(ctx context.Context) ([]RiskCap, error)
+	SetRiskCap(ctx context.Context, scope, name string, maxPct decimal.Decimal) error
+	DeleteRiskCap(ctx context.Context, id string) error
+	GetTickerSectors(ctx context.Context) (map[string]string, error)
+	SetTickerSector(ctx context.Context, ticker, sector string) error
+	GetTickerProfiles(ctx context.Context) (map[string]TickerProfile, error)
+	SaveTickerProfile(ctx context.Context, p TickerProfile) error
+
+	// Manual prices
+	GetManualPrices(ctx context.Context) (map[string]ManualPrice, error)
+	SetManualPrice(ctx context.Context, ticker string, price decimal.Decimal) error
+	DeleteManualPrice(ctx context.Context, ticker string) error
+	GetStaleSymbols(ctx context.Context) (map[string]StaleSymbol, error)
+	RecordQuoteFailure(ctx context.Context, ticker string, lastPrice decimal.NullDecimal, lastPriceAt time.Time) error
+	ClearStaleSymbol(ctx context.Context, ticker string) error
+
+	// Price history
+	GetPriceBars(ctx context.Context, ticker string, since time.Time) ([]analytics.DailyBar, error)
+	SavePriceBars(ctx context.Context, ticker string, bars []analytics.DailyBar) error
+	GetPriceHistoryLatest(ctx context.Context) (map[string]time.Time, error)
+
+	// Option open interest
+	GetOpenInterest(ctx context.Context, since time.Time) ([]OpenInterest, error)
+	SaveOpenInterest(ctx context.Context, snapshots []OpenInterest) error
+
+	// Option policies
+	GetOptionPolicies(ctx context.Context) ([]OptionPolicy, error)
+	AddOptionPolicy(ctx context.Context, optionID, kind string, threshold decimal.Decimal, itmOnly bool) error
+	DeleteOptionPolicy(ctx context.Context, id string) error
+	GetPendingPolicyActions(ctx context.Context) ([]PolicyAction, error)
+	QueuePolicyAction(ctx context.Context, a PolicyAction) (queued bool, err error)
+	ResolvePolicyAction(ctx context.Context, id, status string) error
+
+	// Webhook fills
+	ClaimFill(ctx context.Context, fillID string) (claimed bool, err error)
+	ReleaseFill(ctx context.Context, fillID string) error
+
+	// Option reminders
+	GetReminders(ctx context.Context) ([]Reminder, error)
+	AddReminder(ctx context.Context, optionID string, remindOn time.Time, note string) error
+	DismissReminder(ctx context.Context, id string) error
+
+	// Settings
+	GetRiskFreeRate(ctx context.Context) (decimal.NullDecimal, error)
+	SetRiskFreeRate(ctx context.Context, rate decimal.Decimal) error
+	GetUIState(ctx context.Context) (string, error)
+	SetUIState(ctx context.Context, state string) error
+	GetQuoteCache(ctx context.Context) (string, error)
+	SetQuoteCache(ctx context.Context, cache string) error
+	GetLocale(ctx context.Context) (string, error)
+	SetLocale(ctx context.Context, name string) error
+	GetTaxYearStart(ctx context.Context) (string, error)
+	SetTaxYearStart(ctx context.Context, start string) error
+	GetIndicatorHistory(ctx context.Context) (string, error)
+	SetIndicatorHistory(ctx context.Context, history string) error
+	GetChainCacheTTL(ctx context.Context) (string, error)
+	SetChainCacheTTL(ctx context.Context, ttl string) error
+	GetAssignmentFee(ctx context.Context) (AssignmentFee, error)
+	SetAssignmentFee(ctx context.Context, fee AssignmentFee) error
+	GetPrecision(ctx context.Context) (Precision, error)
+	SetPrecision(ctx context.Context, p Precision) error
+	GetTargetWeights(ctx context.Context) (TargetWeights, error)
+	SetTargetWeights(ctx context.Context, targets TargetWeights) error
+	GetCustomColumns(ctx context.Context) ([]CustomColumn, error)
+	SetCustomColumns(ctx context.Context, columns []CustomColumn) error
+	GetHoldingsColumns(ctx context.Context) ([]string, error)
+	SetHoldingsColumns(ctx context.Context, columns []string) error
+	GetCSPSignals(ctx context.Context) ([]CSPSignal, error)
+	SetCSPSignals(ctx context.Context, signals []CSPSignal) error
+	GetPinned(ctx context.Context) ([]string, error)
+	SetPinned(ctx context.Context, ids []string) error
+	GetAlertLog(ctx context.Context) ([]LoggedAlert, error)
+	SetAlertLog(ctx context.Context, alerts []LoggedAlert) error
+	GetScoreHistory(ctx context.Context) ([]ScoreSnapshot, error)
+	SetScoreHistory(ctx context.Context, history []ScoreSnapshot) error
+	GetBaseCurrency(ctx context.Context) (string, error)
+	SetBaseCurrency(ctx context.Context, currency string) error
+	GetFXRates(ctx context.Context) (FXRates, error)
+	SetFXRates(ctx context.Context, rates FXRates) error
+	GetAlertBell(ctx context.Context) (bool, error)
+	SetAlertBell(ctx context.Context, on bool) error
+	GetVIXPercentileScoring(ctx context.Context) (bool, error)
+	SetVIXPercentileScoring(ctx context.Context, on bool) error
+	GetNotifyRoutes(ctx context.Context) (string, error)
+	SetNotifyRoutes(ctx context.Context, routes string) error
+
+	// Change detection between instances sharing the database
+	GetChangeStamp(ctx context.Context) (string, error)
+	Listen(ctx context.Context, onChange func(table string)) error
+
+	// Accounts: holdings, options, and cash are per account
+	GetAccounts(ctx context.Context) ([]Account, error)
+	AddAccount(ctx context.Context, name string) error
+	ForAccount(id string) Store
+
+	// Access
+	CurrentRole(ctx context.Context) (Role, error)
+}
+
+var _ Store = (*DB)(nil)
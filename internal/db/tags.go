@@ -0,0 +1,42 @@
+package db
+
+import "context"
+
+// GetTags returns the tags of the account's holdings and options by their
+// ID, each item's in alphabetical order.
+func (d *DB) GetTags(ctx context.Context) (map[string][]string, error) {
+	rows, err := d.pool.Query(ctx,
+		`SELECT item_id, tag FROM item_tags
+		 WHERE item_id IN (SELECT id FROM holdings WHERE account_id IS NOT DISTINCT FROM $1
+		                   UNION ALL SELECT id FROM options WHERE account_id IS NOT DISTINCT FROM $1)
+		 ORDER BY tag`, d.accountID())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[string][]string)
+	for rows.Next() {
+		var id, tag string
+		if err := rows.Scan(&id, &tag); err != nil {
+			return nil, err
+		}
+		tags[id] = append(tags[id], tag)
+	}
+	return tags, rows.Err()
+}
+
+// SetTags replaces the tags of the holding or option with id; no tags
+// removes them all.
+func (d *DB) SetTags(ctx context.Context, id string, tags []string) error {
+	// A nil slice goes out as NULL, and tag <> ALL(NULL) matches no row
+	if tags == nil {
+		tags = []string{}
+	}
+	_, err := d.pool.Exec(ctx,
+		`WITH removed AS (DELETE FROM item_tags WHERE item_id = $1 AND tag <> ALL($2::text[]))
+		 INSERT INTO item_tags (item_id, tag) SELECT $1, unnest($2::text[])
+		 ON CONFLICT DO NOTHING`,
+		id, tags)
+	return err
+}
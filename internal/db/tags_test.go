@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDeleteRemovesTags(t *testing.T) {
+	d := testDB(t)
+	ctx := context.Background()
+	clean := func() { d.pool.Exec(context.Background(), `DELETE FROM holdings WHERE ticker = 'ZZTAG'`) }
+	clean()
+	t.Cleanup(clean)
+
+	if err := d.insertHolding(ctx, "ZZTAG", decimal.NewFromInt(1), decimal.NewFromInt(1), DefaultCurrency, time.Now(), decimal.NullDecimal{}, ""); err != nil {
+		t.Fatal(err)
+	}
+	h, err := d.GetHoldingByTicker(ctx, "ZZTAG")
+	if err != nil || h == nil {
+		t.Fatalf("GetHoldingByTicker: %v", err)
+	}
+	if err := d.SetTags(ctx, h.ID, []string{"wheel"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.DeleteHolding(ctx, h.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var left int
+	if err := d.pool.QueryRow(ctx, `SELECT COUNT(*) FROM item_tags WHERE item_id = $1`, h.ID).Scan(&left); err != nil {
+		t.Fatal(err)
+	}
+	if left != 0 {
+		t.Errorf("%d tags left on the deleted holding", left)
+	}
+}
+
+func TestSetTagsClearsAll(t *testing.T) {
+	d := testDB(t)
+	ctx := context.Background()
+	clean := func() { d.pool.Exec(context.Background(), `DELETE FROM holdings WHERE ticker = 'ZZTAG'`) }
+	clean()
+	t.Cleanup(clean)
+
+	if err := d.insertHolding(ctx, "ZZTAG", decimal.NewFromInt(1), decimal.NewFromInt(1), DefaultCurrency, time.Now(), decimal.NullDecimal{}, ""); err != nil {
+		t.Fatal(err)
+	}
+	h, err := d.GetHoldingByTicker(ctx, "ZZTAG")
+	if err != nil || h == nil {
+		t.Fatalf("GetHoldingByTicker: %v", err)
+	}
+	if err := d.SetTags(ctx, h.ID, []string{"wheel", "core"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetTags(ctx, h.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tags, err := d.GetTags(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if left := tags[h.ID]; len(left) != 0 {
+		t.Errorf("tags after clearing = %v, want none", left)
+	}
+}
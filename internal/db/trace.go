@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// slowQuery is the duration above which queries are logged at info level
+const slowQuery = 500 * time.Millisecond
+
+type traceKey struct{}
+
+type traceData struct {
+	sql   string
+	start time.Time
+}
+
+// queryTracer logs query timing at debug level and failures at error level
+type queryTracer struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, traceKey{}, traceData{sql: data.SQL, start: time.Now()})
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	td, ok := ctx.Value(traceKey{}).(traceData)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(td.start)
+	sql := strings.Join(strings.Fields(td.sql), " ")
+
+	switch {
+	case data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows):
+		slog.Error("db query failed", "sql", sql, "duration", elapsed, "err", data.Err)
+	case elapsed > slowQuery:
+		slog.Info("db query slow", "sql", sql, "duration", elapsed)
+	default:
+		slog.Debug("db query", "sql", sql, "duration", elapsed)
+	}
+}
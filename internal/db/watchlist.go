@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// WatchItem is a stock on the watchlist: a ticker not yet held and the
+// price to buy it at. It is separate from the CSP advisor's watchlist.
+type WatchItem struct {
+	ID          string
+	Ticker      string
+	TargetPrice decimal.NullDecimal // Price to buy at
+	Notes       string
+	CreatedAt   time.Time
+}
+
+// SetWatchTicker adds ticker to the watchlist, or replaces its target price
+// and notes if it is on it.
+func (d *DB) SetWatchTicker(ctx context.Context, ticker string, targetPrice decimal.NullDecimal, notes string) error {
+	_, err := d.pool.Exec(ctx,
+		`INSERT INTO watchlist (ticker, target_price, notes) VALUES ($1, $2, NULLIF($3, ''))
+		 ON CONFLICT (ticker) DO UPDATE SET target_price = EXCLUDED.target_price, notes = EXCLUDED.notes`,
+		ticker, targetPrice, notes)
+	return err
+}
+
+func (d *DB) RemoveWatchTicker(ctx context.Context, ticker string) error {
+	_, err := d.pool.Exec(ctx, `DELETE FROM watchlist WHERE ticker = $1`, ticker)
+	return err
+}
+
+// GetWatchlist returns the watchlist by ticker.
+func (d *DB) GetWatchlist(ctx context.Context) ([]WatchItem, error) {
+	rows, err := d.pool.Query(ctx,
+		`SELECT id, ticker, target_price, notes, created_at FROM watchlist ORDER BY ticker`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []WatchItem
+	for rows.Next() {
+		var item WatchItem
+		var notes *string
+		if err := rows.Scan(&item.ID, &item.Ticker, &item.TargetPrice, &notes, &item.CreatedAt); err != nil {
+			return nil, err
+		}
+		if notes != nil {
+			item.Notes = *notes
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
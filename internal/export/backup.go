@@ -0,0 +1,86 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"anyhowhodl/internal/db"
+
+	"github.com/shopspring/decimal"
+)
+
+// Backup is a full JSON dump of the book: the main account's cash, holdings,
+// every option (including closed ones), and cash ledger at the top level,
+// each other account's under accounts, and the CSP watchlist.
+type Backup struct {
+	ExportedAt time.Time `json:"exported_at"`
+	AccountBackup
+	Accounts  []AccountBackup   `json:"accounts,omitempty"`
+	Watchlist []db.CSPWatchItem `json:"csp_watchlist"`
+}
+
+// AccountBackup is one account's part of a Backup.
+type AccountBackup struct {
+	Name         string           `json:"name,omitempty"` // Empty for the main account
+	Cash         decimal.Decimal  `json:"cash"`
+	Holdings     []db.Holding     `json:"holdings"`
+	Options      []db.Option      `json:"options"`
+	Transactions []db.Transaction `json:"transactions"`
+}
+
+// NewBackup reads everything a Backup holds from store, for every account
+// whichever one store is for.
+func NewBackup(ctx context.Context, store db.Store, now time.Time) (*Backup, error) {
+	b := &Backup{ExportedAt: now.UTC()}
+	main, err := newAccountBackup(ctx, store.ForAccount(""), "")
+	if err != nil {
+		return nil, err
+	}
+	b.AccountBackup = *main
+
+	accounts, err := store.GetAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, acct := range accounts {
+		ab, err := newAccountBackup(ctx, store.ForAccount(acct.ID), acct.Name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", acct.Name, err)
+		}
+		b.Accounts = append(b.Accounts, *ab)
+	}
+
+	if b.Watchlist, err = store.GetCSPWatchlist(ctx); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// newAccountBackup reads the account store is for
+func newAccountBackup(ctx context.Context, store db.Store, name string) (*AccountBackup, error) {
+	ab := &AccountBackup{Name: name}
+	var err error
+	if ab.Cash, err = store.GetAvailableCash(ctx); err != nil {
+		return nil, err
+	}
+	if ab.Holdings, err = store.GetHoldings(ctx); err != nil {
+		return nil, err
+	}
+	if ab.Options, err = store.GetActiveOptions(ctx); err != nil {
+		return nil, err
+	}
+	if ab.Transactions, err = store.GetTransactions(ctx); err != nil {
+		return nil, err
+	}
+	return ab, nil
+}
+
+// WriteBackupJSON writes b as indented JSON.
+func WriteBackupJSON(w io.Writer, b *Backup) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
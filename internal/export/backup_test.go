@@ -0,0 +1,50 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/fake"
+
+	"github.com/shopspring/decimal"
+)
+
+// TestNewBackupAllAccounts dumps every account and its ledger, whichever
+// account the store is for
+func TestNewBackupAllAccounts(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	store.SetAvailableCash(ctx, decimal.NewFromInt(1000))
+	store.AddAccount(ctx, "IRA")
+	accounts, _ := store.GetAccounts(ctx)
+	ira := store.ForAccount(accounts[0].ID)
+	ira.SetAvailableCash(ctx, decimal.NewFromInt(5000))
+	ira.AddHolding(ctx, "KO", decimal.NewFromInt(10), decimal.NewFromInt(60), db.DefaultCurrency, time.Now(), decimal.NullDecimal{}, "")
+
+	b, err := NewBackup(ctx, ira, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !b.Cash.Equal(decimal.NewFromInt(1000)) || len(b.Transactions) != 1 || b.Name != "" {
+		t.Errorf("main account = %s cash, %d transactions, name %q; want 1000, 1, none", b.Cash, len(b.Transactions), b.Name)
+	}
+	if len(b.Accounts) != 1 {
+		t.Fatalf("accounts = %d, want 1", len(b.Accounts))
+	}
+	got := b.Accounts[0]
+	if got.Name != "IRA" || !got.Cash.Equal(decimal.NewFromInt(4400)) || len(got.Holdings) != 1 || len(got.Transactions) != 2 {
+		t.Errorf("IRA = %q, %s cash, %d holdings, %d transactions; want IRA, 4400, 1, 2",
+			got.Name, got.Cash, len(got.Holdings), len(got.Transactions))
+	}
+
+	var buf bytes.Buffer
+	if err := WriteBackupJSON(&buf, b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"transactions"`)) || !bytes.Contains(buf.Bytes(), []byte(`"name": "IRA"`)) {
+		t.Errorf("backup JSON missing the ledger or the IRA:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,94 @@
+// Package export writes portfolio data to files for use outside the app.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"anyhowhodl/internal/locale"
+	"anyhowhodl/internal/query"
+)
+
+// CSPHeader is the CSV header row for CSP scan results.
+var CSPHeader = []string{"timestamp", "ticker", "strike", "dte", "delta", "score", "yield", "signal"}
+
+// CSPRecord is the JSON shape of one CSP scan result.
+type CSPRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	Ticker    string    `json:"ticker"`
+	Strike    float64   `json:"strike"`
+	DTE       int       `json:"dte"`
+	Delta     float64   `json:"delta"`
+	Score     float64   `json:"score"`
+	Yield     float64   `json:"yield"` // Annualized premium yield, percent
+	Signal    string    `json:"signal"`
+}
+
+// NewCSPRecord flattens a scan result.
+func NewCSPRecord(r query.CSPResult) CSPRecord {
+	return CSPRecord{
+		Timestamp: r.Scanned.UTC(),
+		Ticker:    r.Ticker,
+		Strike:    r.Strike,
+		DTE:       r.DTE,
+		Delta:     r.Delta,
+		Score:     r.Score.CompositeScore,
+		Yield:     r.Score.RawPremiumYield,
+		Signal:    r.Score.Signal,
+	}
+}
+
+// WriteCSPCSV writes results as CSV rows, preceded by CSPHeader if header is true.
+func WriteCSPCSV(w io.Writer, results []query.CSPResult, header bool) error {
+	return WriteCSPCSVLocale(w, results, header, locale.Default)
+}
+
+// WriteCSPCSVLocale is WriteCSPCSV with numbers written in loc's decimal mark,
+// ungrouped. Locales with a decimal comma get semicolon-separated fields, as
+// spreadsheets there expect. Timestamps stay RFC 3339.
+func WriteCSPCSVLocale(w io.Writer, results []query.CSPResult, header bool, loc locale.Locale) error {
+	cw := csv.NewWriter(w)
+	if loc.Decimal == "," {
+		cw.Comma = ';'
+	}
+	num := func(f float64, places int) string {
+		return loc.EditNumber(strconv.FormatFloat(f, 'f', places, 64))
+	}
+	if header {
+		if err := cw.Write(CSPHeader); err != nil {
+			return err
+		}
+	}
+	for _, r := range results {
+		rec := NewCSPRecord(r)
+		row := []string{
+			rec.Timestamp.Format(time.RFC3339),
+			rec.Ticker,
+			num(rec.Strike, 2),
+			strconv.Itoa(rec.DTE),
+			num(rec.Delta, 3),
+			num(rec.Score, 1),
+			num(rec.Yield, 2),
+			rec.Signal,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteCSPJSON writes results as an indented JSON array.
+func WriteCSPJSON(w io.Writer, results []query.CSPResult) error {
+	records := make([]CSPRecord, len(results))
+	for i, r := range results {
+		records[i] = NewCSPRecord(r)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
@@ -0,0 +1,73 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"anyhowhodl/internal/csp"
+	"anyhowhodl/internal/locale"
+	"anyhowhodl/internal/query"
+)
+
+func sampleResults() []query.CSPResult {
+	return []query.CSPResult{{
+		Ticker:  "AAPL",
+		Strike:  200,
+		DTE:     30,
+		Delta:   -0.2512,
+		Scanned: time.Date(2026, 10, 15, 14, 30, 0, 0, time.UTC),
+		Score: csp.SignalOutput{
+			CompositeScore:  72.34,
+			RawPremiumYield: 18.456,
+			Signal:          "STRONG",
+		},
+	}}
+}
+
+func TestWriteCSPCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSPCSV(&buf, sampleResults(), true); err != nil {
+		t.Fatalf("WriteCSPCSV: %v", err)
+	}
+	want := "timestamp,ticker,strike,dte,delta,score,yield,signal\n" +
+		"2026-10-15T14:30:00Z,AAPL,200.00,30,-0.251,72.3,18.46,STRONG\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := WriteCSPCSV(&buf, sampleResults(), false); err != nil {
+		t.Fatalf("WriteCSPCSV without header: %v", err)
+	}
+	if bytes.HasPrefix(buf.Bytes(), []byte("timestamp")) {
+		t.Error("header written when header=false")
+	}
+}
+
+func TestWriteCSPCSVLocale(t *testing.T) {
+	de, _ := locale.Lookup("de-DE")
+	var buf bytes.Buffer
+	if err := WriteCSPCSVLocale(&buf, sampleResults(), false, de); err != nil {
+		t.Fatalf("WriteCSPCSVLocale: %v", err)
+	}
+	want := "2026-10-15T14:30:00Z;AAPL;200,00;30;-0,251;72,3;18,46;STRONG\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteCSPJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSPJSON(&buf, sampleResults()); err != nil {
+		t.Fatalf("WriteCSPJSON: %v", err)
+	}
+	var records []CSPRecord
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(records) != 1 || records[0].Ticker != "AAPL" || records[0].Signal != "STRONG" || records[0].DTE != 30 {
+		t.Errorf("unexpected records: %+v", records)
+	}
+}
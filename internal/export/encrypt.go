@@ -0,0 +1,123 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Encryption pipes exports through the age or gpg command-line tool, so files
+// are encrypted to Recipient before they reach disk.
+type Encryption struct {
+	Tool      string // "age" or "gpg"
+	Recipient string // age public key (age1...) or gpg key ID / email
+}
+
+// ParseEncryption validates a tool and recipient pair. It returns nil, nil
+// when tool is empty, meaning exports are written in the clear.
+func ParseEncryption(tool, recipient string) (*Encryption, error) {
+	tool = strings.ToLower(strings.TrimSpace(tool))
+	recipient = strings.TrimSpace(recipient)
+	if tool == "" {
+		return nil, nil
+	}
+	if tool != "age" && tool != "gpg" {
+		return nil, fmt.Errorf("unknown encryption tool %q (want age or gpg)", tool)
+	}
+	if recipient == "" {
+		return nil, fmt.Errorf("%s encryption needs a recipient", tool)
+	}
+	return &Encryption{Tool: tool, Recipient: recipient}, nil
+}
+
+// Ext is the extension appended to encrypted files.
+func (e *Encryption) Ext() string {
+	if e.Tool == "gpg" {
+		return ".gpg"
+	}
+	return ".age"
+}
+
+func (e *Encryption) args() []string {
+	if e.Tool == "gpg" {
+		return []string{"--batch", "--yes", "--trust-model", "always", "--encrypt", "--recipient", e.Recipient}
+	}
+	return []string{"--encrypt", "--recipient", e.Recipient}
+}
+
+// Encrypt returns a writer whose plaintext is encrypted into w. Close must be
+// called to flush the ciphertext; it reports the tool's failure, if any.
+func (e *Encryption) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	cmd := exec.Command(e.Tool, e.args()...)
+	cmd.Stdout = w
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %s: %w", e.Tool, err)
+	}
+	return &encryptWriter{WriteCloser: stdin, cmd: cmd, stderr: &stderr}, nil
+}
+
+type encryptWriter struct {
+	io.WriteCloser
+	cmd    *exec.Cmd
+	stderr *strings.Builder
+}
+
+func (w *encryptWriter) Close() error {
+	closeErr := w.WriteCloser.Close()
+	if err := w.cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(w.stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %s", w.cmd.Args[0], msg)
+		}
+		return err
+	}
+	return closeErr
+}
+
+// Create creates path for an export, encrypted when enc is non-nil, in which
+// case enc.Ext() is appended to the name. It returns the path actually
+// written. On a failed Close of an encrypted file, the partial file is removed.
+func Create(path string, enc *Encryption) (io.WriteCloser, string, error) {
+	if enc != nil {
+		path += enc.Ext()
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, path, err
+	}
+	if enc == nil {
+		return f, path, nil
+	}
+	w, err := enc.Encrypt(f)
+	if err != nil {
+		f.Close()
+		os.Remove(path)
+		return nil, path, err
+	}
+	return &encryptedFile{w: w, f: f, path: path}, path, nil
+}
+
+type encryptedFile struct {
+	w    io.WriteCloser
+	f    *os.File
+	path string
+}
+
+func (e *encryptedFile) Write(p []byte) (int, error) { return e.w.Write(p) }
+
+func (e *encryptedFile) Close() error {
+	err := errors.Join(e.w.Close(), e.f.Close())
+	if err != nil {
+		os.Remove(e.path)
+	}
+	return err
+}
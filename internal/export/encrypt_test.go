@@ -0,0 +1,101 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"anyhowhodl/internal/fake"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestParseEncryption(t *testing.T) {
+	if enc, err := ParseEncryption("", ""); enc != nil || err != nil {
+		t.Errorf("empty tool = %v, %v; want nil, nil", enc, err)
+	}
+	if _, err := ParseEncryption("zip", "x"); err == nil {
+		t.Error("unknown tool accepted")
+	}
+	if _, err := ParseEncryption("age", " "); err == nil {
+		t.Error("missing recipient accepted")
+	}
+	enc, err := ParseEncryption(" GPG ", "me@example.com")
+	if err != nil || enc.Tool != "gpg" || enc.Ext() != ".gpg" {
+		t.Errorf("gpg = %+v, %v", enc, err)
+	}
+}
+
+// TestCreateEncryptedGPG round-trips a backup through gpg with a throwaway key
+func TestCreateEncryptedGPG(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not installed")
+	}
+	t.Setenv("GNUPGHOME", t.TempDir())
+	t.Cleanup(func() { exec.Command("gpgconf", "--kill", "gpg-agent").Run() })
+	gen := exec.Command("gpg", "--batch", "--passphrase", "", "--quick-gen-key", "backup-test@example.invalid", "default", "default", "never")
+	if out, err := gen.CombinedOutput(); err != nil {
+		t.Skipf("gpg key generation unavailable: %v\n%s", err, out)
+	}
+
+	ctx := context.Background()
+	store := fake.NewStore()
+	store.SetAvailableCash(ctx, decimal.NewFromInt(1000))
+	store.AddCSPWatchTicker(ctx, "AAPL", "")
+	b, err := NewBackup(ctx, store, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enc := &Encryption{Tool: "gpg", Recipient: "backup-test@example.invalid"}
+	w, path, err := Create(filepath.Join(t.TempDir(), "backup.json"), enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(path, ".json.gpg") {
+		t.Errorf("path = %s, want .json.gpg suffix", path)
+	}
+	if err := WriteBackupJSON(w, b); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext, _ := os.ReadFile(path)
+	if bytes.Contains(ciphertext, []byte("AAPL")) {
+		t.Fatal("backup written in the clear")
+	}
+	plain, err := exec.Command("gpg", "--batch", "--decrypt", path).Output()
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if !bytes.Contains(plain, []byte(`"cash": "1000"`)) || !bytes.Contains(plain, []byte("AAPL")) {
+		t.Errorf("decrypted backup missing data:\n%s", plain)
+	}
+}
+
+func TestCreateEncryptedRemovesFileOnFailure(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not installed")
+	}
+	t.Setenv("GNUPGHOME", t.TempDir())
+
+	enc := &Encryption{Tool: "gpg", Recipient: "nobody@example.invalid"}
+	w, path, err := Create(filepath.Join(t.TempDir(), "backup.json"), enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("{}"))
+	if err := w.Close(); err == nil {
+		t.Fatal("Close succeeded for an unknown recipient")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("partial file left at %s", path)
+	}
+}
@@ -0,0 +1,24 @@
+package export
+
+import (
+	"io"
+	"strings"
+
+	"anyhowhodl/internal/importer"
+)
+
+// WriteTradingView writes sections as a TradingView watchlist import file.
+// Yahoo share-class tickers (BRK-B) are written in TradingView form (BRK.B).
+func WriteTradingView(w io.Writer, sections []importer.WatchlistSection) error {
+	var entries []string
+	for _, s := range sections {
+		if s.Name != "" {
+			entries = append(entries, "###"+s.Name)
+		}
+		for _, t := range s.Tickers {
+			entries = append(entries, strings.ReplaceAll(t, "-", "."))
+		}
+	}
+	_, err := io.WriteString(w, strings.Join(entries, ",")+"\n")
+	return err
+}
@@ -0,0 +1,28 @@
+package export
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"anyhowhodl/internal/importer"
+)
+
+func TestWriteTradingViewRoundTrip(t *testing.T) {
+	sections := []importer.WatchlistSection{
+		{Name: "Holdings", Tickers: []string{"AAPL", "BRK-B"}},
+		{Name: "CSP Watchlist", Tickers: []string{"TSLA"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTradingView(&buf, sections); err != nil {
+		t.Fatalf("WriteTradingView: %v", err)
+	}
+	if want := "###Holdings,AAPL,BRK.B,###CSP Watchlist,TSLA\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	if got := importer.ParseTradingView(buf.String()); !reflect.DeepEqual(got, sections) {
+		t.Errorf("round trip = %+v", got)
+	}
+}
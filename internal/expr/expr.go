@@ -0,0 +1,344 @@
+// Package expr parses and evaluates the small arithmetic language behind
+// user-defined table columns, e.g. `premium*100*qty/(strike*100)/dte*365`.
+//
+// Expressions combine numbers and variables with + - * / ^ and parentheses,
+// and may call abs(x), min(x, y, ...) and max(x, y, ...).
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrDivideByZero is returned by Eval when a divisor is zero.
+var ErrDivideByZero = errors.New("division by zero")
+
+// Expr is a parsed expression.
+type Expr struct {
+	src  string
+	root node
+	vars map[string]bool
+}
+
+type node func(vars map[string]float64) (float64, error)
+
+// Parse compiles src, reporting the position of the first syntax error.
+func Parse(src string) (*Expr, error) {
+	p := &parser{src: src, vars: make(map[string]bool)}
+	p.next()
+	root, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok != tokEOF {
+		return nil, p.unexpected()
+	}
+	return &Expr{src: src, root: root, vars: p.vars}, nil
+}
+
+// String returns the source the expression was parsed from.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Vars returns the variables the expression refers to, sorted.
+func (e *Expr) Vars() []string {
+	names := make([]string, 0, len(e.vars))
+	for name := range e.vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check returns an error naming the first variable not in allowed.
+func (e *Expr) Check(allowed []string) error {
+	known := make(map[string]bool, len(allowed))
+	for _, name := range allowed {
+		known[name] = true
+	}
+	for _, name := range e.Vars() {
+		if !known[name] {
+			return fmt.Errorf("unknown variable %q", name)
+		}
+	}
+	return nil
+}
+
+// Eval computes the expression with the given variable values. A variable
+// without a value is an error.
+func (e *Expr) Eval(vars map[string]float64) (float64, error) {
+	return e.root(vars)
+}
+
+type token int
+
+const (
+	tokEOF token = iota
+	tokNumber
+	tokIdent
+	tokOp // One of + - * / ^ ( ) ,
+	tokInvalid
+)
+
+type parser struct {
+	src  string
+	pos  int // Offset just past the current token
+	at   int // Offset of the current token
+	tok  token
+	text string
+	vars map[string]bool
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("at %d: %s", p.at+1, fmt.Sprintf(format, args...))
+}
+
+// unexpected reports the current token as out of place
+func (p *parser) unexpected() error {
+	if p.tok == tokEOF {
+		return p.errorf("unexpected end of expression")
+	}
+	return p.errorf("unexpected %q", p.text)
+}
+
+// next scans the following token
+func (p *parser) next() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+	p.at = p.pos
+	if p.pos >= len(p.src) {
+		p.tok, p.text = tokEOF, ""
+		return
+	}
+
+	c := rune(p.src[p.pos])
+	switch {
+	case unicode.IsDigit(c) || c == '.':
+		for p.pos < len(p.src) && (unicode.IsDigit(rune(p.src[p.pos])) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		p.tok = tokNumber
+	case unicode.IsLetter(c) || c == '_':
+		for p.pos < len(p.src) && (unicode.IsLetter(rune(p.src[p.pos])) || unicode.IsDigit(rune(p.src[p.pos])) || p.src[p.pos] == '_') {
+			p.pos++
+		}
+		p.tok = tokIdent
+	case strings.ContainsRune("+-*/^(),", c):
+		p.pos++
+		p.tok = tokOp
+	default:
+		p.pos++
+		p.tok = tokInvalid
+	}
+	p.text = p.src[p.at:p.pos]
+}
+
+func (p *parser) isOp(op string) bool {
+	return p.tok == tokOp && p.text == op
+}
+
+// sum := product (('+' | '-') product)*
+func (p *parser) sum() (node, error) {
+	left, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+") || p.isOp("-") {
+		op := p.text
+		p.next()
+		right, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(vars map[string]float64) (float64, error) {
+			a, b, err := both(l, right, vars)
+			if op == "-" {
+				return a - b, err
+			}
+			return a + b, err
+		}
+	}
+	return left, nil
+}
+
+// product := unary (('*' | '/') unary)*
+func (p *parser) product() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*") || p.isOp("/") {
+		op := p.text
+		p.next()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(vars map[string]float64) (float64, error) {
+			a, b, err := both(l, right, vars)
+			if err != nil {
+				return 0, err
+			}
+			if op == "*" {
+				return a * b, nil
+			}
+			if b == 0 {
+				return 0, ErrDivideByZero
+			}
+			return a / b, nil
+		}
+	}
+	return left, nil
+}
+
+// unary := '-' unary | power
+func (p *parser) unary() (node, error) {
+	if p.isOp("-") {
+		p.next()
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(vars map[string]float64) (float64, error) {
+			v, err := operand(vars)
+			return -v, err
+		}, nil
+	}
+	return p.power()
+}
+
+// power := primary ('^' unary)?, so 2^-1 works and -2^2 is -4
+func (p *parser) power() (node, error) {
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOp("^") {
+		return base, nil
+	}
+	p.next()
+	exp, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return func(vars map[string]float64) (float64, error) {
+		a, b, err := both(base, exp, vars)
+		return math.Pow(a, b), err
+	}, nil
+}
+
+// primary := number | ident | ident '(' args ')' | '(' sum ')'
+func (p *parser) primary() (node, error) {
+	switch {
+	case p.tok == tokNumber:
+		v, err := strconv.ParseFloat(p.text, 64)
+		if err != nil {
+			return nil, p.errorf("bad number %q", p.text)
+		}
+		p.next()
+		return func(map[string]float64) (float64, error) { return v, nil }, nil
+
+	case p.tok == tokIdent:
+		name := strings.ToLower(p.text)
+		p.next()
+		if p.isOp("(") {
+			return p.call(name)
+		}
+		p.vars[name] = true
+		return func(vars map[string]float64) (float64, error) {
+			v, ok := vars[name]
+			if !ok {
+				return 0, fmt.Errorf("unknown variable %q", name)
+			}
+			return v, nil
+		}, nil
+
+	case p.isOp("("):
+		p.next()
+		inner, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isOp(")") {
+			return nil, p.errorf("expected )")
+		}
+		p.next()
+		return inner, nil
+	}
+	return nil, p.unexpected()
+}
+
+// call parses a function's arguments; the name has been consumed
+func (p *parser) call(name string) (node, error) {
+	at := p.at
+	p.next() // (
+	var args []node
+	for !p.isOp(")") {
+		if len(args) > 0 {
+			if !p.isOp(",") {
+				return nil, p.errorf("expected , or )")
+			}
+			p.next()
+		}
+		arg, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	p.next() // )
+
+	var fold func(a, b float64) float64
+	switch name {
+	case "abs":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("at %d: abs takes one argument", at+1)
+		}
+		return func(vars map[string]float64) (float64, error) {
+			v, err := args[0](vars)
+			return math.Abs(v), err
+		}, nil
+	case "min":
+		fold = math.Min
+	case "max":
+		fold = math.Max
+	default:
+		return nil, fmt.Errorf("at %d: unknown function %q", at+1, name)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("at %d: %s needs an argument", at+1, name)
+	}
+	return func(vars map[string]float64) (float64, error) {
+		acc, err := args[0](vars)
+		if err != nil {
+			return 0, err
+		}
+		for _, arg := range args[1:] {
+			v, err := arg(vars)
+			if err != nil {
+				return 0, err
+			}
+			acc = fold(acc, v)
+		}
+		return acc, nil
+	}, nil
+}
+
+// both evaluates two operands, left first
+func both(l, r node, vars map[string]float64) (float64, float64, error) {
+	a, err := l(vars)
+	if err != nil {
+		return 0, 0, err
+	}
+	b, err := r(vars)
+	return a, b, err
+}
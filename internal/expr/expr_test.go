@@ -0,0 +1,83 @@
+package expr
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	vars := map[string]float64{"premium": 2, "qty": 3, "strike": 100, "dte": 30, "price": 90}
+	tests := []struct {
+		src  string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"12 / 4 / 3", 1},
+		{"-2^2", -4},
+		{"2^-1", 0.5},
+		{"premium*100*qty", 600},
+		{"premium/strike/dte*365*100", 2.0 / 100 / 30 * 365 * 100},
+		{"abs(price - strike)", 10},
+		{"max(price, strike, 95)", 100},
+		{"min(price, strike)", 90},
+		{"PRICE / Strike", 0.9},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.src)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.src, err)
+			continue
+		}
+		got, err := e.Eval(vars)
+		if err != nil || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%q = %v, %v; want %v", tt.src, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		"":           "at 1",
+		"1 +":        "at 4: unexpected end of expression",
+		"(1 + 2":     "expected )",
+		"2 $ 3":      `unexpected "$"`,
+		"sqrt(4)":    `unknown function "sqrt"`,
+		"abs(1, 2)":  "one argument",
+		"1.2.3":      "bad number",
+		"price 2":    `unexpected "2"`,
+		"min()":      "needs an argument",
+		"max(1 2)":   "expected , or )",
+		"premium**2": `unexpected "*"`,
+	} {
+		if _, err := Parse(src); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want %q", src, err, want)
+		}
+	}
+}
+
+func TestVarsAndCheck(t *testing.T) {
+	e, err := Parse("premium * qty / Strike + min(qty, 1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(e.Vars(), ","); got != "premium,qty,strike" {
+		t.Errorf("Vars = %s", got)
+	}
+	if err := e.Check([]string{"premium", "qty", "strike"}); err != nil {
+		t.Errorf("Check: %v", err)
+	}
+	if err := e.Check([]string{"premium", "qty"}); err == nil || !strings.Contains(err.Error(), "strike") {
+		t.Errorf("Check without strike = %v", err)
+	}
+
+	if _, err := e.Eval(map[string]float64{"premium": 1, "qty": 1, "strike": 0}); !errors.Is(err, ErrDivideByZero) {
+		t.Errorf("Eval by zero strike = %v, want ErrDivideByZero", err)
+	}
+	if _, err := e.Eval(map[string]float64{"premium": 1}); err == nil {
+		t.Error("Eval with a missing variable succeeded")
+	}
+}
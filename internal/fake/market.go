@@ -0,0 +1,209 @@
+package fake
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/csp"
+	"anyhowhodl/internal/yahoo"
+)
+
+// Market is a canned yahoo.Provider. Symbols without data return an error,
+// as the real client does for unknown tickers. Set Err to fail every call.
+type Market struct {
+	mu sync.Mutex
+
+	Quotes    map[string]yahoo.Quote
+	Chains    map[string]*csp.OptionsData
+	Series    map[string][]analytics.PricePoint
+	Dividends map[string][]analytics.Dividend
+	Splits    map[string][]yahoo.Split
+	Earnings  map[string]*yahoo.EarningsDate
+	Profiles  map[string]*yahoo.Profile
+	Err       error
+
+	Calls int // Number of provider calls, for asserting caching
+}
+
+var _ yahoo.Provider = (*Market)(nil)
+
+func NewMarket() *Market {
+	return &Market{
+		Quotes:    make(map[string]yahoo.Quote),
+		Chains:    make(map[string]*csp.OptionsData),
+		Series:    make(map[string][]analytics.PricePoint),
+		Dividends: make(map[string][]analytics.Dividend),
+		Splits:    make(map[string][]yahoo.Split),
+		Earnings:  make(map[string]*yahoo.EarningsDate),
+		Profiles:  make(map[string]*yahoo.Profile),
+	}
+}
+
+// SetPrice sets a quote with only Symbol and Price populated.
+func (m *Market) SetPrice(symbol string, price float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Quotes[symbol] = yahoo.Quote{Symbol: symbol, Price: price}
+}
+
+func (m *Market) call() error {
+	m.Calls++
+	return m.Err
+}
+
+func (m *Market) GetQuote(symbol string) (*yahoo.Quote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.call(); err != nil {
+		return nil, err
+	}
+	q, ok := m.Quotes[symbol]
+	if !ok {
+		return nil, fmt.Errorf("no data for symbol %s", symbol)
+	}
+	return &q, nil
+}
+
+// GetQuotes omits symbols without quotes, like the real client.
+func (m *Market) GetQuotes(symbols []string) (map[string]yahoo.Quote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]yahoo.Quote)
+	if err := m.call(); err != nil {
+		return out, nil
+	}
+	for _, s := range symbols {
+		if q, ok := m.Quotes[s]; ok {
+			out[s] = q
+		}
+	}
+	return out, nil
+}
+
+func (m *Market) FetchOptionsChain(ticker string) (*csp.OptionsData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.call(); err != nil {
+		return nil, err
+	}
+	chain, ok := m.Chains[ticker]
+	if !ok {
+		return nil, fmt.Errorf("no options chain for %s", ticker)
+	}
+	return chain, nil
+}
+
+func (m *Market) FetchOptionsChainForExpiry(ticker string, expiry int64) (*csp.OptionsData, error) {
+	return m.FetchOptionsChain(ticker)
+}
+
+func (m *Market) FetchPriceHistory(ticker string) ([]float64, error) {
+	series, err := m.FetchPriceSeries(ticker)
+	if err != nil {
+		return nil, err
+	}
+	closes := make([]float64, len(series))
+	for i, p := range series {
+		closes[i] = p.Close
+	}
+	return closes, nil
+}
+
+// FetchCloseHistory serves the closes of Series within h's range, taking the
+// last of each week for a weekly interval
+func (m *Market) FetchCloseHistory(ticker string, h yahoo.History) ([]float64, error) {
+	series, err := m.FetchPriceSeries(ticker)
+	if err != nil {
+		return nil, err
+	}
+	since := h.Since(time.Now()).Unix()
+	var points []analytics.PricePoint
+	for _, p := range series {
+		if p.Time >= since {
+			points = append(points, p)
+		}
+	}
+	if h.Weekly() {
+		points = analytics.WeeklyCloses(points)
+	}
+	closes := make([]float64, len(points))
+	for i, p := range points {
+		closes[i] = p.Close
+	}
+	return closes, nil
+}
+
+func (m *Market) FetchPriceSeries(ticker string) ([]analytics.PricePoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.call(); err != nil {
+		return nil, err
+	}
+	series, ok := m.Series[ticker]
+	if !ok {
+		return nil, fmt.Errorf("no price history for %s", ticker)
+	}
+	return series, nil
+}
+
+// FetchDailyBars serves Series from since on, each close standing in for the
+// whole bar
+func (m *Market) FetchDailyBars(ticker string, since time.Time) ([]analytics.DailyBar, error) {
+	series, err := m.FetchPriceSeries(ticker)
+	if err != nil {
+		return nil, err
+	}
+	var bars []analytics.DailyBar
+	for _, p := range series {
+		if p.Time >= since.Unix() {
+			bars = append(bars, analytics.DailyBar{Time: p.Time, Open: p.Close, High: p.Close, Low: p.Close, Close: p.Close})
+		}
+	}
+	return bars, nil
+}
+
+func (m *Market) FetchDividends(ticker string) ([]analytics.Dividend, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.call(); err != nil {
+		return nil, err
+	}
+	return m.Dividends[ticker], nil
+}
+
+func (m *Market) FetchSplits(ticker string) ([]yahoo.Split, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.call(); err != nil {
+		return nil, err
+	}
+	return m.Splits[ticker], nil
+}
+
+func (m *Market) FetchEarningsDate(ticker string) (*yahoo.EarningsDate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.call(); err != nil {
+		return nil, err
+	}
+	ed, ok := m.Earnings[ticker]
+	if !ok {
+		return &yahoo.EarningsDate{}, nil
+	}
+	return ed, nil
+}
+
+func (m *Market) FetchProfile(ticker string) (*yahoo.Profile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.call(); err != nil {
+		return nil, err
+	}
+	p, ok := m.Profiles[ticker]
+	if !ok {
+		return nil, fmt.Errorf("no data for symbol %s", ticker)
+	}
+	return p, nil
+}
@@ -0,0 +1,1803 @@
+// Package fake provides in-memory implementations of db.Store and
+// yahoo.Provider so app logic can be tested without Postgres or the network.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/db"
+
+	"github.com/shopspring/decimal"
+)
+
+// Store is an in-memory db.Store. Cash adjustments mirror *db.DB.
+// The zero value is not usable; call NewStore.
+type Store struct {
+	mu sync.Mutex
+
+	// Now is the clock used for created_at and "today"; defaults to time.Now.
+	Now func() time.Time
+
+	nextID        int
+	holdings      []db.Holding
+	options       []db.Option
+	cash          decimal.Decimal
+	transactions  []db.Transaction
+	watchlist     []db.CSPWatchItem
+	stockWatch    []db.WatchItem
+	ideas         []db.TradeIdea
+	tags          map[string][]string
+	cashSnapshots map[string]db.CashSnapshot
+	interest      []db.InterestPayment
+	dividends     []db.DividendPayment
+	sales         []db.StockSale
+	snapshots     map[string]db.PortfolioSnapshot
+	contributions []db.Contribution
+	riskCaps      []db.RiskCap
+	sectors       map[string]string
+	profiles      map[string]db.TickerProfile
+	manualPrices  map[string]db.ManualPrice
+	staleSymbols  map[string]db.StaleSymbol
+	priceBars     map[string][]analytics.DailyBar
+	openInterest  []db.OpenInterest
+	policies      []db.OptionPolicy
+	policyActions []db.PolicyAction
+	fills         map[string]bool
+	reminders     []db.Reminder
+	riskFreeRate  decimal.NullDecimal
+	uiState       string
+	notifyRoutes  string
+	quoteCache    string
+	locale        string
+	taxYearStart  string
+	history       string
+	chainTTL      string
+	assignmentFee db.AssignmentFee
+	precision     db.Precision
+	targetWeights db.TargetWeights
+	customColumns []db.CustomColumn
+	holdingsCols  []string
+	cspSignals    []db.CSPSignal
+	pinned        []string
+	alertLog      []db.LoggedAlert
+	scoreHistory  []db.ScoreSnapshot
+	alertBell     bool
+	vixPercentile bool
+	baseCurrency  string
+	fxRates       db.FXRates // Shared by the accounts, like other settings
+	role          db.Role
+	listeners     []chan string
+
+	// Accounts live on the main account's store; each other account is a
+	// separate Store pointing back to it
+	root          *Store
+	account       string // ID of the account, "" for the main one
+	accounts      []db.Account
+	accountStores map[string]*Store
+}
+
+var _ db.Store = (*Store)(nil)
+
+func NewStore() *Store {
+	return &Store{
+		Now:           time.Now,
+		cashSnapshots: make(map[string]db.CashSnapshot),
+		snapshots:     make(map[string]db.PortfolioSnapshot),
+		sectors:       make(map[string]string),
+		tags:          make(map[string][]string),
+		profiles:      make(map[string]db.TickerProfile),
+		manualPrices:  make(map[string]db.ManualPrice),
+		staleSymbols:  make(map[string]db.StaleSymbol),
+		priceBars:     make(map[string][]analytics.DailyBar),
+		precision:     db.DefaultPrecision,
+		targetWeights: db.TargetWeights{Tolerance: db.DefaultDriftTolerance},
+	}
+}
+
+func (s *Store) id(prefix string) string {
+	s.nextID++
+	if s.account != "" {
+		// IDs stay unique across accounts, as in the database
+		return fmt.Sprintf("%s-%s%d", s.account, prefix, s.nextID)
+	}
+	return fmt.Sprintf("%s%d", prefix, s.nextID)
+}
+
+func (s *Store) today() time.Time {
+	now := s.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// Holdings
+
+func (s *Store) AddHolding(ctx context.Context, ticker string, quantity, avgCost decimal.Decimal, currency string, entryDate time.Time, targetPrice decimal.NullDecimal, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rate, err := s.fxRate(currency)
+	if err != nil {
+		return err
+	}
+	s.moveCash(db.TxBuy, ticker, quantity, quantity.Mul(avgCost).Mul(rate).Neg(), notes)
+	s.addHolding(ticker, quantity, avgCost, currency, entryDate, targetPrice, notes)
+	return nil
+}
+
+// addHolding merges into or adds a holding without touching cash
+func (s *Store) addHolding(ticker string, quantity, avgCost decimal.Decimal, currency string, entryDate time.Time, targetPrice decimal.NullDecimal, notes string) {
+	if h := s.holding(ticker); h != nil {
+		totalShares := h.Quantity.Add(quantity)
+		h.AvgCost = h.Quantity.Mul(h.AvgCost).Add(quantity.Mul(avgCost)).Div(totalShares)
+		h.Quantity = totalShares
+		if notes != "" {
+			if h.Notes != "" {
+				h.Notes += "; " + notes
+			} else {
+				h.Notes = notes
+			}
+		}
+		if targetPrice.Valid {
+			h.TargetPrice = targetPrice
+		}
+		h.Archived = false
+		h.UpdatedAt = s.Now()
+		return
+	}
+
+	now := s.Now()
+	s.holdings = append(s.holdings, db.Holding{
+		ID:          s.id("h"),
+		Ticker:      ticker,
+		Quantity:    quantity,
+		AvgCost:     avgCost,
+		EntryDate:   entryDate,
+		TargetPrice: targetPrice,
+		Currency:    currency,
+		Notes:       notes,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+}
+
+func (s *Store) holding(ticker string) *db.Holding {
+	for i := range s.holdings {
+		if s.holdings[i].Ticker == ticker {
+			return &s.holdings[i]
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetHoldings(ctx context.Context) ([]db.Holding, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := append([]db.Holding(nil), s.holdings...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Ticker < out[j].Ticker })
+	return out, nil
+}
+
+func (s *Store) GetHoldingByTicker(ctx context.Context, ticker string) (*db.Holding, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if h := s.holding(ticker); h != nil {
+		copy := *h
+		return &copy, nil
+	}
+	return nil, nil
+}
+
+func (s *Store) UpdateHolding(ctx context.Context, id string, quantity, avgCost decimal.Decimal, targetPrice decimal.NullDecimal, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.holdings {
+		if h := &s.holdings[i]; h.ID == id {
+			h.Quantity, h.AvgCost, h.TargetPrice, h.Notes = quantity, avgCost, targetPrice, notes
+			h.UpdatedAt = s.Now()
+		}
+	}
+	return nil
+}
+
+func (s *Store) UpdateHoldingIfUnchanged(ctx context.Context, id string, readAt time.Time, quantity, avgCost decimal.Decimal, targetPrice decimal.NullDecimal, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.holdings {
+		if h := &s.holdings[i]; h.ID == id {
+			if !h.UpdatedAt.Equal(readAt) {
+				return db.ErrConflict
+			}
+			h.Quantity, h.AvgCost, h.TargetPrice, h.Notes = quantity, avgCost, targetPrice, notes
+			h.UpdatedAt = s.Now()
+			return nil
+		}
+	}
+	return db.ErrConflict
+}
+
+func (s *Store) SetHoldingPosition(ctx context.Context, ticker string, quantity, avgCost decimal.Decimal, entryDate time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if h := s.holding(ticker); h != nil {
+		h.Quantity, h.AvgCost = quantity, avgCost
+		h.UpdatedAt = s.Now()
+		return nil
+	}
+	now := s.Now()
+	s.holdings = append(s.holdings, db.Holding{
+		ID: s.id("h"), Ticker: ticker, Quantity: quantity, AvgCost: avgCost,
+		EntryDate: entryDate, Currency: db.DefaultCurrency, CreatedAt: now, UpdatedAt: now,
+	})
+	return nil
+}
+
+func (s *Store) SetTickerCurrency(ctx context.Context, ticker, currency string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.Now()
+	for i := range s.holdings {
+		if h := &s.holdings[i]; h.Ticker == ticker && h.Currency != currency {
+			h.Currency, h.UpdatedAt = currency, now
+		}
+	}
+	for i := range s.options {
+		if o := &s.options[i]; o.Ticker == ticker && o.Currency != currency {
+			o.Currency, o.UpdatedAt = currency, now
+		}
+	}
+	return nil
+}
+
+// BulkAddHoldings merges or adds every holding without touching cash
+func (s *Store) BulkAddHoldings(ctx context.Context, holdings []db.Holding) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, h := range holdings {
+		s.addHolding(h.Ticker, h.Quantity, h.AvgCost, db.DefaultCurrency, h.EntryDate, decimal.NullDecimal{}, h.Notes)
+	}
+	return nil
+}
+
+func (s *Store) SetHoldingArchived(ctx context.Context, id string, archived bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.holdings {
+		if h := &s.holdings[i]; h.ID == id {
+			h.Archived = archived
+			h.UpdatedAt = s.Now()
+		}
+	}
+	return nil
+}
+
+func (s *Store) DeleteHolding(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleteHolding(id)
+	return nil
+}
+
+func (s *Store) deleteHolding(id string) {
+	delete(s.tags, id)
+	for i := range s.holdings {
+		if s.holdings[i].ID == id {
+			s.holdings = append(s.holdings[:i], s.holdings[i+1:]...)
+			return
+		}
+	}
+}
+
+// Cash
+
+func (s *Store) GetAvailableCash(ctx context.Context) (decimal.Decimal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cash, nil
+}
+
+func (s *Store) SetAvailableCash(ctx context.Context, amount decimal.Decimal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.moveCash(db.TxAdjustment, "", decimal.Zero, amount.Sub(s.cash), "Cash set")
+	return nil
+}
+
+func (s *Store) AdjustCash(ctx context.Context, kind, ticker string, amount decimal.Decimal, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.moveCash(kind, ticker, decimal.Zero, amount, notes)
+	return nil
+}
+
+func (s *Store) GetTransactions(ctx context.Context) ([]db.Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.transactions), nil
+}
+
+// moveCash adds amount to cash and records it in the ledger, as *db.DB does
+func (s *Store) moveCash(kind, ticker string, quantity, amount decimal.Decimal, notes string) {
+	s.moveOptionCash(kind, ticker, "", quantity, amount, notes)
+}
+
+func (s *Store) moveOptionCash(kind, ticker, optionID string, quantity, amount decimal.Decimal, notes string) {
+	if amount.IsZero() {
+		return
+	}
+	s.cash = s.cash.Add(amount)
+	s.transactions = append(s.transactions, db.Transaction{
+		ID: s.id("t"), Kind: kind, Ticker: ticker, Quantity: quantity, Amount: amount,
+		Balance: s.cash, Notes: notes, OptionID: optionID, CreatedAt: s.Now(),
+	})
+}
+
+func contractNotes(o *db.Option) string {
+	return fmt.Sprintf("%s %s %s %s", o.Action, o.OptionType, o.Strike.String(), o.ExpiryDate.Format(time.DateOnly))
+}
+
+func (s *Store) RecordCashSnapshot(ctx context.Context, amount decimal.Decimal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	day := s.today()
+	s.cashSnapshots[day.Format(time.DateOnly)] = db.CashSnapshot{Date: day, Amount: amount}
+	return nil
+}
+
+func (s *Store) GetCashSnapshots(ctx context.Context, since time.Time) ([]db.CashSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var all []db.CashSnapshot
+	for _, snap := range s.cashSnapshots {
+		all = append(all, snap)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Date.Before(all[j].Date) })
+
+	// Include the latest snapshot on or before since, as *db.DB does
+	anchor := since
+	for _, snap := range all {
+		if !snap.Date.After(since) {
+			anchor = snap.Date
+		}
+	}
+	var out []db.CashSnapshot
+	for _, snap := range all {
+		if !snap.Date.Before(anchor) {
+			out = append(out, snap)
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) AddInterestPayment(ctx context.Context, amount decimal.Decimal, receivedOn time.Time, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interest = append(s.interest, db.InterestPayment{ID: s.id("i"), Amount: amount, ReceivedOn: receivedOn, Notes: notes, CreatedAt: s.Now()})
+	s.moveCash(db.TxInterest, "", decimal.Zero, amount, notes)
+	return nil
+}
+
+func (s *Store) GetInterestSince(ctx context.Context, since time.Time) (decimal.Decimal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	total := decimal.Zero
+	for _, p := range s.interest {
+		if !p.ReceivedOn.Before(since) {
+			total = total.Add(p.Amount)
+		}
+	}
+	return total, nil
+}
+
+func (s *Store) AddDividend(ctx context.Context, ticker string, amount decimal.Decimal, paidOn time.Time, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dividends = append(s.dividends, db.DividendPayment{ID: s.id("d"), Ticker: ticker, Amount: amount, PaidOn: paidOn, Notes: notes, CreatedAt: s.Now()})
+	s.moveCash(db.TxDividend, ticker, decimal.Zero, amount, notes)
+	return nil
+}
+
+func (s *Store) GetDividendsSince(ctx context.Context, since time.Time) ([]db.DividendPayment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []db.DividendPayment
+	for _, p := range s.dividends {
+		if !p.PaidOn.Before(since) {
+			out = append(out, p)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b db.DividendPayment) int { return a.PaidOn.Compare(b.PaidOn) })
+	return out, nil
+}
+
+func (s *Store) SellHolding(ctx context.Context, ticker string, quantity, price decimal.Decimal, soldOn time.Time, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := s.holding(ticker)
+	if h == nil || quantity.GreaterThan(h.Quantity) {
+		return db.ErrOversold
+	}
+	rate, err := s.fxRate(h.Currency)
+	if err != nil {
+		return err
+	}
+	s.sales = append(s.sales, db.StockSale{
+		ID: s.id("s"), Ticker: ticker, Quantity: quantity, Price: price, AvgCost: h.AvgCost,
+		Currency: h.Currency, SoldOn: soldOn, Notes: notes, CreatedAt: s.Now(),
+	})
+	if remaining := h.Quantity.Sub(quantity); remaining.IsZero() {
+		s.deleteHolding(h.ID)
+	} else {
+		h.Quantity = remaining
+		h.UpdatedAt = s.Now()
+	}
+	s.moveCash(db.TxSell, ticker, quantity.Neg(), quantity.Mul(price).Mul(rate), notes)
+	return nil
+}
+
+func (s *Store) GetSalesSince(ctx context.Context, since time.Time) ([]db.StockSale, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []db.StockSale
+	for _, sale := range s.sales {
+		if !sale.SoldOn.Before(since) {
+			out = append(out, sale)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b db.StockSale) int { return a.SoldOn.Compare(b.SoldOn) })
+	return out, nil
+}
+
+// Options
+
+func (s *Store) AddOption(ctx context.Context, ticker, optionType, action string, strike decimal.Decimal, expiryDate time.Time, quantity, multiplier int, settlement, currency string, premium, openFee decimal.Decimal, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rate, err := s.fxRate(currency)
+	if err != nil {
+		return err
+	}
+	now := s.Now()
+	s.options = append(s.options, db.Option{
+		ID: s.id("o"), Ticker: ticker, OptionType: optionType, Action: action, Strike: strike,
+		ExpiryDate: expiryDate, Quantity: quantity, Multiplier: multiplier, Settlement: settlement, Currency: currency,
+		Premium: premium, OpenFee: openFee, Status: "ACTIVE", Notes: notes, CreatedAt: now, UpdatedAt: now,
+	})
+
+	premiumTotal := premium.Mul(decimal.NewFromInt(int64(quantity) * int64(multiplier)))
+	if action != "SELL" {
+		premiumTotal = premiumTotal.Neg()
+	}
+	entry := contractNotes(&s.options[len(s.options)-1])
+	s.moveCash(db.TxPremium, ticker, decimal.NewFromInt(int64(quantity)), premiumTotal.Mul(rate), entry)
+	s.moveCash(db.TxFee, ticker, decimal.Zero, openFee.Neg().Mul(rate), entry)
+	return nil
+}
+
+func (s *Store) AddExternalOption(ctx context.Context, ticker, optionType, action string, strike decimal.Decimal, expiryDate time.Time, quantity, multiplier int, settlement, currency string, premium, openFee decimal.Decimal, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.Now()
+	s.options = append(s.options, db.Option{
+		ID: s.id("o"), Ticker: ticker, OptionType: optionType, Action: action, Strike: strike,
+		ExpiryDate: expiryDate, Quantity: quantity, Multiplier: multiplier, Settlement: settlement, Currency: currency,
+		Premium: premium, OpenFee: openFee, Status: "ACTIVE", Notes: notes, External: true, CreatedAt: now, UpdatedAt: now,
+	})
+	return nil
+}
+
+func (s *Store) AddToOption(ctx context.Context, id string, quantity int, premium, openFee decimal.Decimal, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.option(id)
+	if err != nil {
+		return err
+	}
+	rate := decimal.NewFromInt(1)
+	if !o.External {
+		if rate, err = s.fxRate(o.Currency); err != nil {
+			return err
+		}
+	}
+	total := o.Quantity + quantity
+	o.Premium = o.Premium.Mul(decimal.NewFromInt(int64(o.Quantity))).
+		Add(premium.Mul(decimal.NewFromInt(int64(quantity)))).
+		Div(decimal.NewFromInt(int64(total)))
+	o.Quantity = total
+	o.OpenFee = o.OpenFee.Add(openFee)
+	if notes != "" {
+		if o.Notes != "" {
+			o.Notes += "; " + notes
+		} else {
+			o.Notes = notes
+		}
+	}
+	o.UpdatedAt = s.Now()
+	if o.External {
+		return nil
+	}
+
+	premiumTotal := premium.Mul(decimal.NewFromInt(int64(quantity) * int64(o.Multiplier)))
+	if o.Action != "SELL" {
+		premiumTotal = premiumTotal.Neg()
+	}
+	entry := contractNotes(o)
+	s.moveCash(db.TxPremium, o.Ticker, decimal.NewFromInt(int64(quantity)), premiumTotal.Mul(rate), entry)
+	s.moveCash(db.TxFee, o.Ticker, decimal.Zero, openFee.Neg().Mul(rate), entry)
+	return nil
+}
+
+func (s *Store) AddHistoricalOption(ctx context.Context, o db.Option) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o.ID = s.id("o")
+	if o.Currency == "" {
+		o.Currency = db.DefaultCurrency
+	}
+	s.options = append(s.options, o)
+	return nil
+}
+
+func (s *Store) option(id string) (*db.Option, error) {
+	for i := range s.options {
+		if s.options[i].ID == id {
+			return &s.options[i], nil
+		}
+	}
+	return nil, fmt.Errorf("option %s not found", id)
+}
+
+// GetActiveOptions returns all options (despite the name, as *db.DB does):
+// ACTIVE first, then by expiry and ticker.
+func (s *Store) GetActiveOptions(ctx context.Context) ([]db.Option, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := append([]db.Option(nil), s.options...)
+	sort.SliceStable(out, func(i, j int) bool {
+		ai, aj := out[i].Status == "ACTIVE", out[j].Status == "ACTIVE"
+		if ai != aj {
+			return ai
+		}
+		if !out[i].ExpiryDate.Equal(out[j].ExpiryDate) {
+			return out[i].ExpiryDate.Before(out[j].ExpiryDate)
+		}
+		return out[i].Ticker < out[j].Ticker
+	})
+	return out, nil
+}
+
+func (s *Store) GetExpiredActiveOptions(ctx context.Context) ([]db.Option, error) {
+	all, _ := s.GetActiveOptions(ctx)
+	today := s.today()
+	var out []db.Option
+	for _, o := range all {
+		if o.Status == "ACTIVE" && o.ExpiryDate.Before(today) {
+			out = append(out, o)
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) UpdateOption(ctx context.Context, id string, strike decimal.Decimal, expiryDate time.Time, quantity, multiplier int, settlement string, premium, openFee decimal.Decimal, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.option(id)
+	if err != nil {
+		return nil // UPDATE of a missing row is not an error
+	}
+	o.Strike, o.ExpiryDate, o.Quantity, o.Multiplier, o.Settlement, o.Premium, o.OpenFee, o.Notes = strike, expiryDate, quantity, multiplier, settlement, premium, openFee, notes
+	o.UpdatedAt = s.Now()
+	return nil
+}
+
+func (s *Store) UpdateOptionIfUnchanged(ctx context.Context, id string, readAt time.Time, strike decimal.Decimal, expiryDate time.Time, quantity, multiplier int, settlement string, premium, openFee decimal.Decimal, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.option(id)
+	if err != nil || !o.UpdatedAt.Equal(readAt) {
+		return db.ErrConflict
+	}
+	o.Strike, o.ExpiryDate, o.Quantity, o.Multiplier, o.Settlement, o.Premium, o.OpenFee, o.Notes = strike, expiryDate, quantity, multiplier, settlement, premium, openFee, notes
+	o.UpdatedAt = s.Now()
+	return nil
+}
+
+func (s *Store) DeleteOption(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.options {
+		if s.options[i].ID == id {
+			s.options = append(s.options[:i], s.options[i+1:]...)
+			break
+		}
+	}
+	// Policies, their actions, and reminders cascade, as in schema_policies.sql
+	// and schema_reminders.sql; tags go with it, as in *db.DB
+	delete(s.tags, id)
+	s.policies = slices.DeleteFunc(s.policies, func(p db.OptionPolicy) bool { return p.OptionID == id })
+	s.policyActions = slices.DeleteFunc(s.policyActions, func(a db.PolicyAction) bool { return a.OptionID == id })
+	s.reminders = slices.DeleteFunc(s.reminders, func(r db.Reminder) bool { return r.OptionID == id })
+	return nil
+}
+
+func (s *Store) ExpireOption(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if o, err := s.option(id); err == nil {
+		o.Status, o.UpdatedAt = "EXPIRED", s.Now()
+	}
+	return nil
+}
+
+func (s *Store) CloseOption(ctx context.Context, id string, closePremium, closeFee decimal.Decimal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.option(id)
+	if err != nil {
+		return err
+	}
+
+	if !o.External {
+		rate, err := s.fxRate(o.Currency)
+		if err != nil {
+			return err
+		}
+		closeCost := closePremium.Mul(o.Shares()).Mul(rate)
+		if o.Action == "SELL" {
+			closeCost = closeCost.Neg()
+		}
+		s.moveCash(db.TxClose, o.Ticker, decimal.NewFromInt(int64(o.Quantity)), closeCost, contractNotes(o))
+		s.moveCash(db.TxFee, o.Ticker, decimal.Zero, closeFee.Neg().Mul(rate), contractNotes(o))
+	}
+
+	o.Status, o.UpdatedAt = "CLOSED", s.Now()
+	o.ClosePremium = decimal.NullDecimal{Decimal: closePremium, Valid: true}
+	o.CloseFee = decimal.NullDecimal{Decimal: closeFee, Valid: true}
+	return nil
+}
+
+func (s *Store) AssignOption(ctx context.Context, id string, fee decimal.Decimal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.option(id)
+	if err != nil {
+		return err
+	}
+	if o.External {
+		o.Status, o.UpdatedAt = "ASSIGNED", s.Now()
+		o.CloseFee = decimal.NullDecimal{Decimal: fee, Valid: true}
+		return nil
+	}
+
+	rate, err := s.fxRate(o.Currency)
+	if err != nil {
+		return err
+	}
+
+	shares := o.Shares()
+	totalValue := o.Strike.Mul(shares).Mul(rate)
+
+	if o.OptionType == "PUT" {
+		s.moveOptionCash(db.TxAssignment, o.Ticker, o.ID, shares, totalValue.Neg(), contractNotes(o))
+		if h := s.holding(o.Ticker); h != nil {
+			totalShares := h.Quantity.Add(shares)
+			h.AvgCost = h.Quantity.Mul(h.AvgCost).Add(shares.Mul(o.Strike)).Div(totalShares)
+			h.Quantity = totalShares
+			h.Archived = false
+		} else {
+			s.addHolding(o.Ticker, shares, o.Strike, o.Currency, s.Now(), decimal.NullDecimal{}, "Assigned from PUT option")
+		}
+	} else {
+		s.moveOptionCash(db.TxAssignment, o.Ticker, o.ID, shares.Neg(), totalValue, contractNotes(o))
+		if h := s.holding(o.Ticker); h != nil {
+			remaining := h.Quantity.Sub(shares)
+			if remaining.LessThanOrEqual(decimal.Zero) {
+				s.deleteHolding(h.ID)
+			} else {
+				h.Quantity = remaining
+			}
+		}
+	}
+
+	s.moveOptionCash(db.TxFee, o.Ticker, o.ID, decimal.Zero, fee.Neg().Mul(rate), contractNotes(o))
+	o.Status, o.UpdatedAt = "ASSIGNED", s.Now()
+	o.CloseFee = decimal.NullDecimal{Decimal: fee, Valid: true}
+	return nil
+}
+
+func (s *Store) ApplySplit(ctx context.Context, ticker string, ratio decimal.Decimal, date time.Time, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var adjusted []db.Option
+	for _, o := range s.options {
+		if o.Ticker != ticker || o.Status != "ACTIVE" || !o.CreatedAt.Before(date) {
+			continue
+		}
+		split, err := o.Split(ratio)
+		if err != nil {
+			return err
+		}
+		adjusted = append(adjusted, split)
+	}
+	added := decimal.Zero
+	if h := s.holding(ticker); h != nil {
+		split := h.Split(ratio)
+		added = split.Quantity.Sub(h.Quantity)
+		*h = split
+	}
+	for _, split := range adjusted {
+		o, _ := s.option(split.ID)
+		*o = split
+	}
+	s.transactions = append(s.transactions, db.Transaction{
+		ID: s.id("t"), Kind: db.TxSplit, Ticker: ticker, Quantity: added,
+		Balance: s.cash, Notes: notes, SplitDate: date, SplitRatio: ratio, CreatedAt: s.Now(),
+	})
+	return nil
+}
+
+func (s *Store) SettleOption(ctx context.Context, id string, price, fee decimal.Decimal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.option(id)
+	if err != nil {
+		return err
+	}
+	intrinsic := o.IntrinsicValue(price)
+	if !o.External {
+		rate, err := s.fxRate(o.Currency)
+		if err != nil {
+			return err
+		}
+		settlement := intrinsic.Mul(o.Shares()).Mul(rate)
+		if o.Action == "SELL" {
+			settlement = settlement.Neg()
+		}
+		s.moveCash(db.TxSettlement, o.Ticker, decimal.NewFromInt(int64(o.Quantity)), settlement, contractNotes(o))
+		s.moveCash(db.TxFee, o.Ticker, decimal.Zero, fee.Neg().Mul(rate), contractNotes(o))
+	}
+	o.Status, o.UpdatedAt = "ASSIGNED", s.Now()
+	o.ClosePremium = decimal.NullDecimal{Decimal: intrinsic, Valid: true}
+	o.CloseFee = decimal.NullDecimal{Decimal: fee, Valid: true}
+	return nil
+}
+
+func (s *Store) GetPremiumsBetween(ctx context.Context, from, to time.Time) (*db.PremiumSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sum := s.premiumsBetween(from, to, "")
+	return &sum, nil
+}
+
+func (s *Store) GetPremiumsByCurrency(ctx context.Context, from, to time.Time) (map[string]db.PremiumSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byCurrency := make(map[string]db.PremiumSummary)
+	for _, o := range s.options {
+		if _, ok := byCurrency[o.Currency]; !ok && o.Action == "SELL" && !o.CreatedAt.Before(from) && o.CreatedAt.Before(to) {
+			byCurrency[o.Currency] = s.premiumsBetween(from, to, o.Currency)
+		}
+	}
+	return byCurrency, nil
+}
+
+// premiumsBetween sums the options sold in [from, to) in currency, or in any
+// currency if it is ""
+func (s *Store) premiumsBetween(from, to time.Time, currency string) db.PremiumSummary {
+	var sum db.PremiumSummary
+	for _, o := range s.options {
+		if o.Action != "SELL" || o.CreatedAt.Before(from) || !o.CreatedAt.Before(to) {
+			continue
+		}
+		if currency != "" && o.Currency != currency {
+			continue
+		}
+		qty := o.Shares()
+		premium := o.Premium.Mul(qty)
+		if o.OptionType == "CALL" {
+			sum.CallPremiums = sum.CallPremiums.Add(premium)
+		} else {
+			sum.PutPremiums = sum.PutPremiums.Add(premium)
+		}
+		sum.TotalFees = sum.TotalFees.Add(o.OpenFee)
+		if o.CloseFee.Valid {
+			sum.TotalFees = sum.TotalFees.Add(o.CloseFee.Decimal)
+		}
+		if (o.Status == "CLOSED" || o.Status == "ASSIGNED") && o.ClosePremium.Valid {
+			sum.CloseCosts = sum.CloseCosts.Add(o.ClosePremium.Decimal.Mul(qty))
+		}
+		sum.CapitalAtRisk = sum.CapitalAtRisk.Add(o.Strike.Mul(qty))
+	}
+	sum.TotalPremiums = sum.CallPremiums.Add(sum.PutPremiums)
+	sum.NetPL = sum.TotalPremiums.Sub(sum.TotalFees).Sub(sum.CloseCosts)
+	return sum
+}
+
+func (s *Store) GetNetPremiumsByTicker(ctx context.Context, from, to time.Time) (map[string]decimal.Decimal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	net := make(map[string]decimal.Decimal)
+	for _, o := range s.options {
+		if o.Action != "SELL" || o.CreatedAt.Before(from) || !o.CreatedAt.Before(to) {
+			continue
+		}
+		qty := o.Shares()
+		amount := o.Premium.Mul(qty).Sub(o.OpenFee)
+		if o.CloseFee.Valid {
+			amount = amount.Sub(o.CloseFee.Decimal)
+		}
+		if (o.Status == "CLOSED" || o.Status == "ASSIGNED") && o.ClosePremium.Valid {
+			amount = amount.Sub(o.ClosePremium.Decimal.Mul(qty))
+		}
+		net[o.Ticker] = net[o.Ticker].Add(amount)
+	}
+	return net, nil
+}
+
+// CSP watchlist
+
+func (s *Store) AddCSPWatchTicker(ctx context.Context, ticker, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range s.watchlist {
+		if w.Ticker == ticker {
+			return fmt.Errorf("duplicate key value violates unique constraint (ticker %s)", ticker)
+		}
+	}
+	now := s.Now()
+	s.watchlist = append(s.watchlist, db.CSPWatchItem{ID: s.id("w"), Ticker: ticker, Notes: notes, Expiries: "ALL", CreatedAt: now, UpdatedAt: now})
+	sort.Slice(s.watchlist, func(i, j int) bool { return s.watchlist[i].Ticker < s.watchlist[j].Ticker })
+	return nil
+}
+
+func (s *Store) SetCSPWatchExpiries(ctx context.Context, ticker, expiries string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, w := range s.watchlist {
+		if w.Ticker == ticker {
+			s.watchlist[i].Expiries = expiries
+			s.watchlist[i].UpdatedAt = s.Now()
+		}
+	}
+	return nil
+}
+
+func (s *Store) RemoveCSPWatchTicker(ctx context.Context, ticker string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, w := range s.watchlist {
+		if w.Ticker == ticker {
+			s.watchlist = append(s.watchlist[:i], s.watchlist[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetCSPWatchlist(ctx context.Context) ([]db.CSPWatchItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]db.CSPWatchItem(nil), s.watchlist...), nil
+}
+
+// Stock watchlist
+
+func (s *Store) SetWatchTicker(ctx context.Context, ticker string, targetPrice decimal.NullDecimal, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, w := range s.stockWatch {
+		if w.Ticker == ticker {
+			s.stockWatch[i].TargetPrice = targetPrice
+			s.stockWatch[i].Notes = notes
+			return nil
+		}
+	}
+	s.stockWatch = append(s.stockWatch, db.WatchItem{ID: s.id("sw"), Ticker: ticker, TargetPrice: targetPrice, Notes: notes, CreatedAt: s.Now()})
+	sort.Slice(s.stockWatch, func(i, j int) bool { return s.stockWatch[i].Ticker < s.stockWatch[j].Ticker })
+	return nil
+}
+
+func (s *Store) RemoveWatchTicker(ctx context.Context, ticker string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stockWatch = slices.DeleteFunc(s.stockWatch, func(w db.WatchItem) bool { return w.Ticker == ticker })
+	return nil
+}
+
+func (s *Store) GetWatchlist(ctx context.Context) ([]db.WatchItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.stockWatch), nil
+}
+
+// Trade ideas
+
+func (s *Store) AddTradeIdea(ctx context.Context, ticker, optionType string, strike decimal.Decimal, expiryDate time.Time, quantity int, premium decimal.Decimal, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ideas = append(s.ideas, db.TradeIdea{ID: s.id("ti"), Ticker: ticker, OptionType: optionType, Strike: strike,
+		ExpiryDate: expiryDate, Quantity: quantity, Premium: premium, Notes: notes, CreatedAt: s.Now()})
+	return nil
+}
+
+func (s *Store) DeleteTradeIdea(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ideas = slices.DeleteFunc(s.ideas, func(idea db.TradeIdea) bool { return idea.ID == id })
+	return nil
+}
+
+func (s *Store) GetTradeIdeas(ctx context.Context) ([]db.TradeIdea, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.ideas), nil
+}
+
+// Tags
+
+func (s *Store) GetTags(ctx context.Context) (map[string][]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string][]string)
+	for id, tags := range s.tags {
+		if slices.ContainsFunc(s.holdings, func(h db.Holding) bool { return h.ID == id }) ||
+			slices.ContainsFunc(s.options, func(o db.Option) bool { return o.ID == id }) {
+			out[id] = slices.Clone(tags)
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) SetTags(ctx context.Context, id string, tags []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(tags) == 0 {
+		delete(s.tags, id)
+		return nil
+	}
+	s.tags[id] = slices.Sorted(slices.Values(tags))
+	return nil
+}
+
+// Performance
+
+func (s *Store) RecordPortfolioSnapshot(ctx context.Context, holdingsValue, cash, optionsCredit decimal.Decimal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	day := s.today()
+	s.snapshots[day.Format(time.DateOnly)] = db.PortfolioSnapshot{Date: day, HoldingsValue: holdingsValue, Cash: cash, OptionsCredit: optionsCredit}
+	return nil
+}
+
+func (s *Store) GetPortfolioSnapshots(ctx context.Context, since time.Time) ([]db.PortfolioSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []db.PortfolioSnapshot
+	for _, snap := range s.snapshots {
+		if !snap.Date.Before(since) {
+			out = append(out, snap)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Date.Before(out[j].Date) })
+	return out, nil
+}
+
+func (s *Store) AddContribution(ctx context.Context, amount decimal.Decimal, contributedOn time.Time, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.contributions = append(s.contributions, db.Contribution{ID: s.id("c"), Amount: amount, ContributedOn: contributedOn, Notes: notes, CreatedAt: s.Now()})
+	s.moveCash(db.TxContribution, "", decimal.Zero, amount, notes)
+	return nil
+}
+
+func (s *Store) GetContributionsByQuarter(ctx context.Context, since time.Time) ([]db.QuarterAmount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	totals := make(map[analytics.Quarter]decimal.Decimal)
+	for _, c := range s.contributions {
+		if !c.ContributedOn.Before(since) {
+			q := analytics.QuarterOf(c.ContributedOn)
+			totals[q] = totals[q].Add(c.Amount)
+		}
+	}
+	return quarterAmounts(totals), nil
+}
+
+func (s *Store) GetInterestByQuarter(ctx context.Context, since time.Time) ([]db.QuarterAmount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	totals := make(map[analytics.Quarter]decimal.Decimal)
+	for _, p := range s.interest {
+		if !p.ReceivedOn.Before(since) {
+			q := analytics.QuarterOf(p.ReceivedOn)
+			totals[q] = totals[q].Add(p.Amount)
+		}
+	}
+	return quarterAmounts(totals), nil
+}
+
+func (s *Store) GetOptionIncomeByQuarter(ctx context.Context, since time.Time) ([]db.QuarterAmount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	totals := make(map[analytics.Quarter]decimal.Decimal)
+	for _, o := range s.options {
+		if o.CreatedAt.Before(since) || o.External {
+			continue
+		}
+		sign := decimal.NewFromInt(1)
+		if o.Action != "SELL" {
+			sign = sign.Neg()
+		}
+		qty := o.Shares()
+		income := sign.Mul(o.Premium).Mul(qty).Sub(o.OpenFee)
+		if (o.Status == "CLOSED" || o.Status == "ASSIGNED") && o.ClosePremium.Valid {
+			income = income.Sub(sign.Mul(o.ClosePremium.Decimal).Mul(qty))
+		}
+		if o.CloseFee.Valid {
+			income = income.Sub(o.CloseFee.Decimal)
+		}
+		q := analytics.QuarterOf(o.CreatedAt)
+		totals[q] = totals[q].Add(income)
+	}
+	return quarterAmounts(totals), nil
+}
+
+func quarterAmounts(totals map[analytics.Quarter]decimal.Decimal) []db.QuarterAmount {
+	var out []db.QuarterAmount
+	for q, amount := range totals {
+		out = append(out, db.QuarterAmount{Start: q.Start(time.Local), Amount: amount})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out
+}
+
+// Risk caps
+
+func (s *Store) GetRiskCaps(ctx context.Context) ([]db.RiskCap, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	caps := append([]db.RiskCap(nil), s.riskCaps...)
+	sort.Slice(caps, func(i, j int) bool {
+		if caps[i].Scope != caps[j].Scope {
+			return caps[i].Scope > caps[j].Scope
+		}
+		return caps[i].Name < caps[j].Name
+	})
+	return caps, nil
+}
+
+func (s *Store) SetRiskCap(ctx context.Context, scope, name string, maxPct decimal.Decimal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.riskCaps {
+		if s.riskCaps[i].Scope == scope && s.riskCaps[i].Name == name {
+			s.riskCaps[i].MaxPct = maxPct
+			return nil
+		}
+	}
+	s.riskCaps = append(s.riskCaps, db.RiskCap{ID: s.id("r"), Scope: scope, Name: name, MaxPct: maxPct})
+	return nil
+}
+
+func (s *Store) DeleteRiskCap(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, c := range s.riskCaps {
+		if c.ID == id {
+			s.riskCaps = append(s.riskCaps[:i], s.riskCaps[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetTickerSectors(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sectors := make(map[string]string, len(s.sectors))
+	for ticker, sector := range s.sectors {
+		sectors[ticker] = sector
+	}
+	return sectors, nil
+}
+
+func (s *Store) SetTickerSector(ctx context.Context, ticker, sector string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sector == "" {
+		delete(s.sectors, ticker)
+	} else {
+		s.sectors[ticker] = sector
+	}
+	return nil
+}
+
+func (s *Store) GetTickerProfiles(ctx context.Context) (map[string]db.TickerProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.profiles), nil
+}
+
+func (s *Store) SaveTickerProfile(ctx context.Context, p db.TickerProfile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profiles[p.Ticker] = p
+	return nil
+}
+
+// Manual prices
+
+func (s *Store) GetManualPrices(ctx context.Context) (map[string]db.ManualPrice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prices := make(map[string]db.ManualPrice, len(s.manualPrices))
+	for ticker, p := range s.manualPrices {
+		prices[ticker] = p
+	}
+	return prices, nil
+}
+
+func (s *Store) SetManualPrice(ctx context.Context, ticker string, price decimal.Decimal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.manualPrices[ticker] = db.ManualPrice{Ticker: ticker, Price: price, UpdatedAt: s.Now()}
+	return nil
+}
+
+func (s *Store) DeleteManualPrice(ctx context.Context, ticker string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.manualPrices, ticker)
+	return nil
+}
+
+func (s *Store) GetStaleSymbols(ctx context.Context) (map[string]db.StaleSymbol, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stale := make(map[string]db.StaleSymbol, len(s.staleSymbols))
+	for ticker, sym := range s.staleSymbols {
+		stale[ticker] = sym
+	}
+	return stale, nil
+}
+
+func (s *Store) RecordQuoteFailure(ctx context.Context, ticker string, lastPrice decimal.NullDecimal, lastPriceAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sym, ok := s.staleSymbols[ticker]
+	if !ok {
+		sym = db.StaleSymbol{Ticker: ticker, LastPrice: lastPrice}
+		if lastPrice.Valid {
+			sym.LastPriceAt = lastPriceAt
+		}
+	}
+	sym.Failures++
+	s.staleSymbols[ticker] = sym
+	return nil
+}
+
+func (s *Store) ClearStaleSymbol(ctx context.Context, ticker string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.staleSymbols, ticker)
+	return nil
+}
+
+// Price history
+
+func (s *Store) GetPriceBars(ctx context.Context, ticker string, since time.Time) ([]analytics.DailyBar, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var bars []analytics.DailyBar
+	for _, b := range s.priceBars[ticker] {
+		if b.Time >= since.Unix() {
+			bars = append(bars, b)
+		}
+	}
+	return bars, nil
+}
+
+// SavePriceBars stores bars at midnight UTC of their day, as *db.DB does
+func (s *Store) SavePriceBars(ctx context.Context, ticker string, bars []analytics.DailyBar) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byDay := make(map[int64]analytics.DailyBar)
+	for _, b := range s.priceBars[ticker] {
+		byDay[b.Time] = b
+	}
+	for _, b := range bars {
+		t := time.Unix(b.Time, 0).UTC()
+		b.Time = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix()
+		byDay[b.Time] = b
+	}
+	stored := slices.Collect(maps.Values(byDay))
+	sort.Slice(stored, func(i, j int) bool { return stored[i].Time < stored[j].Time })
+	s.priceBars[ticker] = stored
+	return nil
+}
+
+func (s *Store) GetPriceHistoryLatest(ctx context.Context) (map[string]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	latest := make(map[string]time.Time, len(s.priceBars))
+	for ticker, bars := range s.priceBars {
+		if len(bars) > 0 {
+			latest[ticker] = time.Unix(bars[len(bars)-1].Time, 0).UTC()
+		}
+	}
+	return latest, nil
+}
+
+// Option open interest
+
+func (s *Store) GetOpenInterest(ctx context.Context, since time.Time) ([]db.OpenInterest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []db.OpenInterest
+	for _, o := range s.openInterest {
+		if !o.Day.Before(since) {
+			out = append(out, o)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b db.OpenInterest) int { return a.Day.Compare(b.Day) })
+	return out, nil
+}
+
+func (s *Store) SaveOpenInterest(ctx context.Context, snapshots []db.OpenInterest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, o := range snapshots {
+		s.openInterest = slices.DeleteFunc(s.openInterest, func(stored db.OpenInterest) bool {
+			return stored.SameContract(o) && stored.Day.Equal(o.Day)
+		})
+		s.openInterest = append(s.openInterest, o)
+	}
+	return nil
+}
+
+// Option policies
+
+func (s *Store) GetOptionPolicies(ctx context.Context) ([]db.OptionPolicy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]db.OptionPolicy(nil), s.policies...), nil
+}
+
+func (s *Store) AddOptionPolicy(ctx context.Context, optionID, kind string, threshold decimal.Decimal, itmOnly bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.option(optionID); err != nil {
+		return err
+	}
+	s.policies = append(s.policies, db.OptionPolicy{ID: s.id("p"), OptionID: optionID, Kind: kind, Threshold: threshold, ITMOnly: itmOnly})
+	return nil
+}
+
+func (s *Store) DeleteOptionPolicy(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policies = slices.DeleteFunc(s.policies, func(p db.OptionPolicy) bool { return p.ID == id })
+	s.policyActions = slices.DeleteFunc(s.policyActions, func(a db.PolicyAction) bool { return a.PolicyID == id })
+	return nil
+}
+
+func (s *Store) GetPendingPolicyActions(ctx context.Context) ([]db.PolicyAction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var pending []db.PolicyAction
+	for _, a := range s.policyActions {
+		if a.Status == db.PolicyActionPending {
+			pending = append(pending, a)
+		}
+	}
+	return pending, nil
+}
+
+func (s *Store) QueuePolicyAction(ctx context.Context, a db.PolicyAction) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.policyActions {
+		if existing.PolicyID == a.PolicyID && existing.Status == db.PolicyActionPending {
+			return false, nil
+		}
+	}
+	a.ID = s.id("q")
+	a.Status = db.PolicyActionPending
+	a.CreatedAt = s.Now()
+	s.policyActions = append(s.policyActions, a)
+	return true, nil
+}
+
+func (s *Store) ClaimFill(ctx context.Context, fillID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fills[fillID] {
+		return false, nil
+	}
+	if s.fills == nil {
+		s.fills = make(map[string]bool)
+	}
+	s.fills[fillID] = true
+	return true, nil
+}
+
+func (s *Store) ReleaseFill(ctx context.Context, fillID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.fills, fillID)
+	return nil
+}
+
+func (s *Store) ResolvePolicyAction(ctx context.Context, id, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.policyActions {
+		if s.policyActions[i].ID == id {
+			s.policyActions[i].Status = status
+			return nil
+		}
+	}
+	return fmt.Errorf("policy action %s not found", id)
+}
+
+// Option reminders
+
+func (s *Store) GetReminders(ctx context.Context) ([]db.Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reminders := slices.Clone(s.reminders)
+	slices.SortStableFunc(reminders, func(a, b db.Reminder) int { return a.RemindOn.Compare(b.RemindOn) })
+	return reminders, nil
+}
+
+func (s *Store) AddReminder(ctx context.Context, optionID string, remindOn time.Time, note string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.option(optionID); err != nil {
+		return err
+	}
+	s.reminders = append(s.reminders, db.Reminder{ID: s.id("m"), OptionID: optionID, RemindOn: remindOn, Note: note})
+	return nil
+}
+
+func (s *Store) DismissReminder(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.reminders)
+	s.reminders = slices.DeleteFunc(s.reminders, func(r db.Reminder) bool { return r.ID == id })
+	if len(s.reminders) == n {
+		return fmt.Errorf("reminder %s not found", id)
+	}
+	return nil
+}
+
+// Settings
+
+func (s *Store) GetRiskFreeRate(ctx context.Context) (decimal.NullDecimal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.riskFreeRate, nil
+}
+
+func (s *Store) SetRiskFreeRate(ctx context.Context, rate decimal.Decimal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.riskFreeRate = decimal.NullDecimal{Decimal: rate, Valid: true}
+	return nil
+}
+
+// GetChangeStamp renders the whole book, which changes whenever any of it does.
+func (s *Store) GetChangeStamp(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fmt.Sprintf("%v|%v|%s", s.holdings, s.options, s.cash), nil
+}
+
+// Listen blocks until ctx is done, delivering tables passed to Notify. Unlike
+// the real database, mutations do not notify on their own.
+func (s *Store) Listen(ctx context.Context, onChange func(table string)) error {
+	ch := make(chan string, 16)
+	s.mu.Lock()
+	s.listeners = append(s.listeners, ch)
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, l := range s.listeners {
+			if l == ch {
+				s.listeners = append(s.listeners[:i], s.listeners[i+1:]...)
+				break
+			}
+		}
+	}()
+
+	for {
+		select {
+		case table := <-ch:
+			onChange(table)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Accounts
+
+func (s *Store) GetAccounts(ctx context.Context) ([]db.Account, error) {
+	if s.root != nil {
+		return s.root.GetAccounts(ctx)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	accounts := slices.Clone(s.accounts)
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].Name < accounts[j].Name })
+	return accounts, nil
+}
+
+func (s *Store) AddAccount(ctx context.Context, name string) error {
+	if s.root != nil {
+		return s.root.AddAccount(ctx, name)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range s.accounts {
+		if a.Name == name {
+			return fmt.Errorf("account %q already exists", name)
+		}
+	}
+	s.accounts = append(s.accounts, db.Account{ID: s.id("acct"), Name: name, CreatedAt: s.Now()})
+	return nil
+}
+
+// ForAccount returns the account's store. Unlike *db.DB, where only
+// holdings, options, and cash are per account, it shares nothing else with
+// the main store; tests set what they need on the store they use.
+func (s *Store) ForAccount(id string) db.Store {
+	if s.root != nil {
+		return s.root.ForAccount(id)
+	}
+	if id == "" {
+		return s
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.accountStores == nil {
+		s.accountStores = make(map[string]*Store)
+	}
+	sub, ok := s.accountStores[id]
+	if !ok {
+		sub = NewStore()
+		sub.Now = s.Now
+		sub.root = s
+		sub.account = id
+		s.accountStores[id] = sub
+	}
+	return sub
+}
+
+// CurrentRole returns the role set with SetRole, RoleOwner by default.
+func (s *Store) CurrentRole(ctx context.Context) (db.Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.role == "" {
+		return db.RoleOwner, nil
+	}
+	return s.role, nil
+}
+
+// SetRole sets the role reported by CurrentRole.
+func (s *Store) SetRole(role db.Role) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.role = role
+}
+
+// Notify simulates a change notification from another session.
+func (s *Store) Notify(table string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, l := range s.listeners {
+		l <- table
+	}
+}
+
+func (s *Store) GetUIState(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.uiState, nil
+}
+
+func (s *Store) SetUIState(ctx context.Context, state string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uiState = state
+	return nil
+}
+
+func (s *Store) GetQuoteCache(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.quoteCache, nil
+}
+
+func (s *Store) SetQuoteCache(ctx context.Context, cache string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quoteCache = cache
+	return nil
+}
+
+func (s *Store) GetLocale(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.locale, nil
+}
+
+func (s *Store) SetLocale(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locale = name
+	return nil
+}
+
+func (s *Store) GetTaxYearStart(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.taxYearStart, nil
+}
+
+func (s *Store) SetTaxYearStart(ctx context.Context, start string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.taxYearStart = start
+	return nil
+}
+
+func (s *Store) GetIndicatorHistory(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.history, nil
+}
+
+func (s *Store) SetIndicatorHistory(ctx context.Context, history string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history = history
+	return nil
+}
+
+func (s *Store) GetChainCacheTTL(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.chainTTL, nil
+}
+
+func (s *Store) SetChainCacheTTL(ctx context.Context, ttl string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chainTTL = ttl
+	return nil
+}
+
+func (s *Store) GetAssignmentFee(ctx context.Context) (db.AssignmentFee, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.assignmentFee, nil
+}
+
+func (s *Store) SetAssignmentFee(ctx context.Context, fee db.AssignmentFee) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.assignmentFee = fee
+	return nil
+}
+
+func (s *Store) GetPrecision(ctx context.Context) (db.Precision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.precision, nil
+}
+
+func (s *Store) SetPrecision(ctx context.Context, p db.Precision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.precision = p
+	return nil
+}
+
+func (s *Store) GetTargetWeights(ctx context.Context) (db.TargetWeights, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	targets := s.targetWeights
+	targets.Weights = maps.Clone(targets.Weights)
+	if targets.Weights == nil {
+		targets.Weights = map[string]decimal.Decimal{}
+	}
+	return targets, nil
+}
+
+func (s *Store) SetTargetWeights(ctx context.Context, targets db.TargetWeights) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	targets.Weights = maps.Clone(targets.Weights)
+	s.targetWeights = targets
+	return nil
+}
+
+func (s *Store) GetCustomColumns(ctx context.Context) ([]db.CustomColumn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.customColumns), nil
+}
+
+func (s *Store) SetCustomColumns(ctx context.Context, columns []db.CustomColumn) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.customColumns = slices.Clone(columns)
+	return nil
+}
+
+func (s *Store) GetHoldingsColumns(ctx context.Context) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.holdingsCols), nil
+}
+
+func (s *Store) SetHoldingsColumns(ctx context.Context, columns []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.holdingsCols = slices.Clone(columns)
+	return nil
+}
+
+func (s *Store) GetCSPSignals(ctx context.Context) ([]db.CSPSignal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.cspSignals), nil
+}
+
+func (s *Store) SetCSPSignals(ctx context.Context, signals []db.CSPSignal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cspSignals = slices.Clone(signals)
+	return nil
+}
+
+func (s *Store) GetPinned(ctx context.Context) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.pinned), nil
+}
+
+func (s *Store) SetPinned(ctx context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pinned = slices.Clone(ids)
+	return nil
+}
+
+func (s *Store) GetAlertLog(ctx context.Context) ([]db.LoggedAlert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.alertLog), nil
+}
+
+func (s *Store) SetAlertLog(ctx context.Context, alerts []db.LoggedAlert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alertLog = slices.Clone(alerts)
+	return nil
+}
+
+func (s *Store) GetScoreHistory(ctx context.Context) ([]db.ScoreSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.scoreHistory), nil
+}
+
+func (s *Store) SetScoreHistory(ctx context.Context, history []db.ScoreSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scoreHistory = slices.Clone(history)
+	return nil
+}
+
+func (s *Store) GetBaseCurrency(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.baseCurrency == "" {
+		return db.DefaultCurrency, nil
+	}
+	return s.baseCurrency, nil
+}
+
+func (s *Store) SetBaseCurrency(ctx context.Context, currency string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.baseCurrency = currency
+	return nil
+}
+
+func (s *Store) GetFXRates(ctx context.Context) (db.FXRates, error) {
+	if s.root != nil {
+		return s.root.GetFXRates(ctx)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return db.FXRates{Base: s.fxRates.Base, Rates: maps.Clone(s.fxRates.Rates)}, nil
+}
+
+func (s *Store) SetFXRates(ctx context.Context, rates db.FXRates) error {
+	if s.root != nil {
+		return s.root.SetFXRates(ctx, rates)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fxRates = db.FXRates{Base: rates.Base, Rates: maps.Clone(rates.Rates)}
+	return nil
+}
+
+// fxRate returns the rate cash in currency is booked at, as *db.DB does. The
+// caller holds s.mu; the rates are read from the main account's store.
+func (s *Store) fxRate(currency string) (decimal.Decimal, error) {
+	base, fx := s.baseCurrency, s.fxRates
+	if s.root != nil {
+		s.root.mu.Lock()
+		base, fx = s.root.baseCurrency, s.root.fxRates
+		s.root.mu.Unlock()
+	}
+	if base == "" {
+		base = db.DefaultCurrency
+	}
+	if currency == "" || currency == base {
+		return decimal.NewFromInt(1), nil
+	}
+	rate, ok := fx.Rates[currency]
+	if fx.Base != base || !ok {
+		return decimal.Zero, fmt.Errorf("%w: %s to %s", db.ErrNoFXRate, currency, base)
+	}
+	return rate, nil
+}
+
+func (s *Store) GetAlertBell(ctx context.Context) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.alertBell, nil
+}
+
+func (s *Store) SetAlertBell(ctx context.Context, on bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alertBell = on
+	return nil
+}
+
+func (s *Store) GetVIXPercentileScoring(ctx context.Context) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.vixPercentile, nil
+}
+
+func (s *Store) SetVIXPercentileScoring(ctx context.Context, on bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vixPercentile = on
+	return nil
+}
+
+func (s *Store) GetNotifyRoutes(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.notifyRoutes, nil
+}
+
+func (s *Store) SetNotifyRoutes(ctx context.Context, routes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifyRoutes = routes
+	return nil
+}
@@ -0,0 +1,67 @@
+package ical
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Event is a single all-day calendar entry.
+type Event struct {
+	UID         string // Stable identifier so calendar clients update rather than duplicate
+	Date        time.Time
+	Summary     string
+	Description string
+}
+
+// Write renders events as an iCalendar (RFC 5545) feed.
+func Write(w io.Writer, calendarName string, events []Event, now time.Time) error {
+	var sb strings.Builder
+	sb.WriteString("BEGIN:VCALENDAR\r\n")
+	sb.WriteString("VERSION:2.0\r\n")
+	sb.WriteString("PRODID:-//anyhowhodl//portfolio//EN\r\n")
+	sb.WriteString("CALSCALE:GREGORIAN\r\n")
+	writeLine(&sb, "X-WR-CALNAME:"+escapeText(calendarName))
+
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, e := range events {
+		sb.WriteString("BEGIN:VEVENT\r\n")
+		writeLine(&sb, "UID:"+escapeText(e.UID))
+		sb.WriteString("DTSTAMP:" + stamp + "\r\n")
+		sb.WriteString("DTSTART;VALUE=DATE:" + e.Date.Format("20060102") + "\r\n")
+		sb.WriteString("DTEND;VALUE=DATE:" + e.Date.AddDate(0, 0, 1).Format("20060102") + "\r\n")
+		writeLine(&sb, "SUMMARY:"+escapeText(e.Summary))
+		if e.Description != "" {
+			writeLine(&sb, "DESCRIPTION:"+escapeText(e.Description))
+		}
+		sb.WriteString("END:VEVENT\r\n")
+	}
+	sb.WriteString("END:VCALENDAR\r\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// escapeText escapes characters with special meaning in iCalendar TEXT values.
+func escapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// writeLine folds content lines longer than 75 octets as required by RFC 5545.
+func writeLine(sb *strings.Builder, line string) {
+	// Continuation lines start with a space, leaving 74 octets of content
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		// Don't split a multi-byte UTF-8 sequence
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		sb.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	fmt.Fprintf(sb, "%s\r\n", line)
+}
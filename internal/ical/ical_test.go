@@ -0,0 +1,58 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	events := []Event{
+		{
+			UID:         "expiry-123@anyhowhodl",
+			Date:        time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+			Summary:     "AAPL PUT $200 expires",
+			Description: "SELL 2 contracts, premium $1.50",
+		},
+	}
+
+	var sb strings.Builder
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	if err := Write(&sb, "Portfolio", events, now); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := sb.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:expiry-123@anyhowhodl\r\n",
+		"DTSTAMP:20261015T120000Z\r\n",
+		"DTSTART;VALUE=DATE:20261016\r\n",
+		"DTEND;VALUE=DATE:20261017\r\n",
+		"SUMMARY:AAPL PUT $200 expires\r\n",
+		"DESCRIPTION:SELL 2 contracts\\, premium $1.50\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+}
+
+func TestWriteFoldsLongLines(t *testing.T) {
+	events := []Event{{
+		UID:     "long@anyhowhodl",
+		Date:    time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Summary: strings.Repeat("x", 200),
+	}}
+
+	var sb strings.Builder
+	if err := Write(&sb, "Portfolio", events, time.Now()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	for _, line := range strings.Split(sb.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line exceeds 75 octets: %d", len(line))
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// HoldingRow is a share position read from a CSV file.
+type HoldingRow struct {
+	Ticker    string
+	Quantity  decimal.Decimal
+	AvgCost   decimal.Decimal // Per share
+	EntryDate time.Time       // Zero if not given
+	Notes     string
+	Line      int // 1-based source line
+}
+
+// holding columns, by the header names accepted for each
+var holdingColumns = map[string][]string{
+	"ticker":   {"ticker", "symbol"},
+	"quantity": {"quantity", "qty", "shares"},
+	"cost":     {"cost", "avg_cost", "average_cost", "cost_per_share", "price"},
+	"date":     {"date", "entry_date", "opened", "purchase_date", "acquired"},
+	"notes":    {"notes", "note"},
+}
+
+var requiredHoldingColumns = []string{"ticker", "quantity", "cost"}
+
+// ParseHoldings reads share positions from CSV with a header row naming the
+// columns, in any order: ticker, quantity, and cost per share are required;
+// date (when bought) and notes are optional. Rows that cannot be parsed are
+// reported as warnings; an error means the file itself is unusable.
+func ParseHoldings(r io.Reader) ([]HoldingRow, []string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	index, err := readHeader(reader, holdingColumns, requiredHoldingColumns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []HoldingRow
+	var warnings []string
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			warnings = append(warnings, err.Error())
+			continue
+		}
+		cell := func(col string) string {
+			if i, ok := index[col]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+		h, err := parseHoldingRow(cell)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		h.Line = line
+		rows = append(rows, h)
+	}
+	return rows, warnings, nil
+}
+
+func parseHoldingRow(cell func(col string) string) (HoldingRow, error) {
+	h := HoldingRow{Notes: cell("notes")}
+	ticker := strings.ToUpper(cell("ticker"))
+	if !tickerPattern.MatchString(ticker) {
+		return h, fmt.Errorf("bad ticker %q", cell("ticker"))
+	}
+	h.Ticker = normalizeTicker(ticker)
+
+	var err error
+	if h.Quantity, err = tradeAmount(cell("quantity")); err != nil || !h.Quantity.IsPositive() {
+		return h, fmt.Errorf("bad quantity %q", cell("quantity"))
+	}
+	if h.AvgCost, err = tradeAmount(cell("cost")); err != nil || h.AvgCost.IsNegative() {
+		return h, fmt.Errorf("bad cost %q", cell("cost"))
+	}
+	if s := cell("date"); s != "" {
+		if h.EntryDate, err = tradeDate(s); err != nil {
+			return h, fmt.Errorf("bad date %q", s)
+		}
+	}
+	return h, nil
+}
@@ -0,0 +1,48 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestParseHoldings(t *testing.T) {
+	text := "Symbol,Shares,Avg Cost,Date,Notes\n" +
+		"aapl,100,$172.50,2024-02-01,Core\n" +
+		"BRK.B,\"1,000\",410,,\n" +
+		"MSFT,-5,400,2024-02-01,\n" +
+		"NVDA,10,120,yesterday,\n" +
+		"\n"
+
+	rows, warnings, err := ParseHoldings(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("rows = %+v", rows)
+	}
+	aapl := rows[0]
+	if aapl.Ticker != "AAPL" || !aapl.Quantity.Equal(decimal.NewFromInt(100)) ||
+		!aapl.AvgCost.Equal(decimal.RequireFromString("172.5")) || aapl.Notes != "Core" ||
+		!aapl.EntryDate.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) || aapl.Line != 2 {
+		t.Errorf("AAPL = %+v", aapl)
+	}
+	brk := rows[1]
+	if brk.Ticker != "BRK-B" || !brk.Quantity.Equal(decimal.NewFromInt(1000)) || !brk.EntryDate.IsZero() {
+		t.Errorf("BRK-B = %+v", brk)
+	}
+
+	// A short quantity, and a date that is not one
+	if len(warnings) != 2 || !strings.HasPrefix(warnings[0], "line 4:") || !strings.HasPrefix(warnings[1], "line 5:") {
+		t.Errorf("warnings = %q", warnings)
+	}
+}
+
+func TestParseHoldingsMissingColumns(t *testing.T) {
+	_, _, err := ParseHoldings(strings.NewReader("ticker,date\nAAPL,2024-03-15\n"))
+	if err == nil || !strings.Contains(err.Error(), "quantity, cost") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,247 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// OptionTrade is a past option trade read from a CSV file, opened and closed
+// before the app was tracking it.
+type OptionTrade struct {
+	Ticker       string
+	OptionType   string // CALL or PUT
+	Action       string // BUY or SELL
+	Strike       decimal.Decimal
+	Expiry       time.Time
+	Quantity     int
+	Multiplier   int
+	Currency     string          // Three-letter code, "" when not given
+	Premium      decimal.Decimal // Per share
+	OpenFee      decimal.Decimal
+	Opened       time.Time
+	Closed       time.Time // The expiry unless given
+	Outcome      string    // EXPIRED, ASSIGNED, or CLOSED
+	ClosePremium decimal.NullDecimal
+	CloseFee     decimal.Decimal
+	Notes        string
+	Line         int // 1-based source line
+}
+
+// option trade columns, by the header names accepted for each
+var optionColumns = map[string][]string{
+	"ticker":        {"ticker", "symbol", "underlying"},
+	"type":          {"type", "option_type", "right", "call_put"},
+	"action":        {"action", "side"},
+	"strike":        {"strike"},
+	"expiry":        {"expiry", "expiration", "expiry_date", "exp"},
+	"quantity":      {"quantity", "qty", "contracts"},
+	"multiplier":    {"multiplier"},
+	"currency":      {"currency", "ccy"},
+	"premium":       {"premium", "open_premium", "open_price"},
+	"open_fee":      {"open_fee", "fee", "fees", "commission"},
+	"opened":        {"opened", "open_date", "opened_on"},
+	"closed":        {"closed", "close_date", "closed_on"},
+	"outcome":       {"outcome", "status", "result"},
+	"close_premium": {"close_premium", "close_price"},
+	"close_fee":     {"close_fee"},
+	"notes":         {"notes", "note"},
+}
+
+var requiredOptionColumns = []string{"ticker", "type", "action", "strike", "expiry", "quantity", "premium", "opened", "outcome"}
+
+var tradeDateLayouts = []string{"2006-01-02", "01/02/2006", "1/2/2006", "2006/01/02"}
+
+// ParseOptionTrades reads past option trades from CSV with a header row
+// naming the columns, in any order: ticker, type (CALL/PUT or C/P), action
+// (SELL/BUY, or STO/BTO), strike, expiry, quantity in contracts, premium per
+// share, opened, and outcome (EXPIRED, ASSIGNED, or CLOSED) are required;
+// multiplier (100), currency, open_fee, closed (the expiry), close_premium
+// (required when CLOSED), close_fee, and notes are optional. Rows that cannot be parsed
+// are reported as warnings; an error means the file itself is unusable.
+func ParseOptionTrades(r io.Reader) ([]OptionTrade, []string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	index, err := readHeader(reader, optionColumns, requiredOptionColumns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var trades []OptionTrade
+	var warnings []string
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			warnings = append(warnings, err.Error())
+			continue
+		}
+		cell := func(col string) string {
+			if i, ok := index[col]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+		t, err := parseOptionTrade(cell)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		t.Line = line
+		trades = append(trades, t)
+	}
+	return trades, warnings, nil
+}
+
+func parseOptionTrade(cell func(col string) string) (OptionTrade, error) {
+	t := OptionTrade{Multiplier: 100, Notes: cell("notes")}
+	ticker := strings.ToUpper(cell("ticker"))
+	if !tickerPattern.MatchString(ticker) {
+		return t, fmt.Errorf("bad ticker %q", cell("ticker"))
+	}
+	t.Ticker = normalizeTicker(ticker)
+
+	switch strings.ToUpper(cell("type")) {
+	case "CALL", "C":
+		t.OptionType = "CALL"
+	case "PUT", "P":
+		t.OptionType = "PUT"
+	default:
+		return t, fmt.Errorf("bad type %q", cell("type"))
+	}
+	switch strings.ToUpper(cell("action")) {
+	case "SELL", "SOLD", "STO", "SHORT":
+		t.Action = "SELL"
+	case "BUY", "BOUGHT", "BTO", "LONG":
+		t.Action = "BUY"
+	default:
+		return t, fmt.Errorf("bad action %q", cell("action"))
+	}
+	switch outcome := strings.ToUpper(cell("outcome")); outcome {
+	case "EXPIRED", "ASSIGNED", "CLOSED":
+		t.Outcome = outcome
+	case "EXERCISED":
+		t.Outcome = "ASSIGNED"
+	default:
+		return t, fmt.Errorf("bad outcome %q", cell("outcome"))
+	}
+
+	var err error
+	if t.Strike, err = tradeAmount(cell("strike")); err != nil || !t.Strike.IsPositive() {
+		return t, fmt.Errorf("bad strike %q", cell("strike"))
+	}
+	if t.Premium, err = tradeAmount(cell("premium")); err != nil || t.Premium.IsNegative() {
+		return t, fmt.Errorf("bad premium %q", cell("premium"))
+	}
+	if t.Quantity, err = strconv.Atoi(cell("quantity")); err != nil || t.Quantity <= 0 {
+		return t, fmt.Errorf("bad quantity %q", cell("quantity"))
+	}
+	if s := cell("multiplier"); s != "" {
+		if t.Multiplier, err = strconv.Atoi(s); err != nil || t.Multiplier <= 0 {
+			return t, fmt.Errorf("bad multiplier %q", s)
+		}
+	}
+	if s := cell("currency"); s != "" {
+		t.Currency = strings.ToUpper(s)
+		if len(t.Currency) != 3 || strings.Trim(t.Currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			return t, fmt.Errorf("bad currency %q", s)
+		}
+	}
+	if t.Expiry, err = tradeDate(cell("expiry")); err != nil {
+		return t, fmt.Errorf("bad expiry %q", cell("expiry"))
+	}
+	if t.Opened, err = tradeDate(cell("opened")); err != nil {
+		return t, fmt.Errorf("bad open date %q", cell("opened"))
+	}
+	t.Closed = t.Expiry
+	if s := cell("closed"); s != "" {
+		if t.Closed, err = tradeDate(s); err != nil {
+			return t, fmt.Errorf("bad close date %q", s)
+		}
+	}
+	if t.Closed.Before(t.Opened) {
+		return t, fmt.Errorf("closed before it was opened")
+	}
+
+	if s := cell("open_fee"); s != "" {
+		if t.OpenFee, err = tradeAmount(s); err != nil {
+			return t, fmt.Errorf("bad fee %q", s)
+		}
+	}
+	if s := cell("close_fee"); s != "" {
+		if t.CloseFee, err = tradeAmount(s); err != nil {
+			return t, fmt.Errorf("bad close fee %q", s)
+		}
+	}
+	if s := cell("close_premium"); s != "" {
+		price, err := tradeAmount(s)
+		if err != nil {
+			return t, fmt.Errorf("bad close premium %q", s)
+		}
+		t.ClosePremium = decimal.NewNullDecimal(price)
+	} else if t.Outcome == "CLOSED" {
+		return t, fmt.Errorf("closed without a close premium")
+	}
+	return t, nil
+}
+
+// readHeader reads the header row of a CSV file and returns the position of
+// each of columns found, by the aliases accepted for it. Names are matched
+// case-insensitively with spaces as underscores.
+func readHeader(reader *csv.Reader, columns map[string][]string, required []string) (map[string]int, error) {
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("empty file")
+	}
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "_"))
+		for col, aliases := range columns {
+			for _, alias := range aliases {
+				if name == alias {
+					index[col] = i
+				}
+			}
+		}
+	}
+	var missing []string
+	for _, col := range required {
+		if _, ok := index[col]; !ok {
+			missing = append(missing, col)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing column(s): %s", strings.Join(missing, ", "))
+	}
+	return index, nil
+}
+
+func tradeAmount(s string) (decimal.Decimal, error) {
+	return decimal.NewFromString(strings.NewReplacer("$", "", ",", "").Replace(s))
+}
+
+func tradeDate(s string) (time.Time, error) {
+	for _, layout := range tradeDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", s)
+}
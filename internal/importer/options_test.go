@@ -0,0 +1,69 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestParseOptionTrades(t *testing.T) {
+	text := "Symbol,Type,Side,Strike,Expiration,Contracts,Premium,Fees,Opened,Closed,Outcome,Close Price\n" +
+		"aapl,P,STO,150,2024-03-15,2,$1.25,1.30,2024-02-01,,EXPIRED,\n" +
+		"MSFT,CALL,SELL,400,03/15/2024,1,3.10,0.65,02/05/2024,2024-03-01,CLOSED,0.80\n" +
+		"TSLA,PUT,SELL,200,2024-03-15,1,4,,2024-02-05,,CLOSED,\n" +
+		"NVDA,PUT,SELL,500,2024-03-15,1,4,,2024-04-01,,EXPIRED,\n" +
+		"\n"
+
+	trades, warnings, err := ParseOptionTrades(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 2 {
+		t.Fatalf("trades = %+v", trades)
+	}
+	aapl := trades[0]
+	if aapl.Ticker != "AAPL" || aapl.OptionType != "PUT" || aapl.Action != "SELL" || aapl.Quantity != 2 ||
+		aapl.Multiplier != 100 || !aapl.Premium.Equal(decimal.RequireFromString("1.25")) ||
+		!aapl.OpenFee.Equal(decimal.RequireFromString("1.3")) || aapl.Outcome != "EXPIRED" ||
+		!aapl.Closed.Equal(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)) || aapl.ClosePremium.Valid || aapl.Line != 2 {
+		t.Errorf("AAPL = %+v", aapl)
+	}
+	msft := trades[1]
+	if msft.Outcome != "CLOSED" || !msft.ClosePremium.Decimal.Equal(decimal.RequireFromString("0.8")) ||
+		!msft.Opened.Equal(time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC)) ||
+		!msft.Closed.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("MSFT = %+v", msft)
+	}
+
+	// A close without its price, and a close before the open
+	if len(warnings) != 2 || !strings.HasPrefix(warnings[0], "line 4:") || !strings.HasPrefix(warnings[1], "line 5:") {
+		t.Errorf("warnings = %q", warnings)
+	}
+}
+
+func TestParseOptionTradesMissingColumns(t *testing.T) {
+	_, _, err := ParseOptionTrades(strings.NewReader("ticker,strike,expiry\nAAPL,150,2024-03-15\n"))
+	if err == nil || !strings.Contains(err.Error(), "type, action") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestParseOptionTradesCurrency(t *testing.T) {
+	text := "ticker,type,action,strike,expiry,quantity,premium,opened,outcome,currency\n" +
+		"D05.SI,PUT,SELL,30,2024-03-15,1,0.40,2024-02-01,EXPIRED,sgd\n" +
+		"AAPL,PUT,SELL,150,2024-03-15,1,1.25,2024-02-01,EXPIRED,\n" +
+		"MSFT,PUT,SELL,300,2024-03-15,1,5,2024-02-01,EXPIRED,US$\n"
+
+	trades, warnings, err := ParseOptionTrades(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 2 || trades[0].Currency != "SGD" || trades[1].Currency != "" {
+		t.Errorf("trades = %+v, want SGD then none given", trades)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "bad currency") {
+		t.Errorf("warnings = %q", warnings)
+	}
+}
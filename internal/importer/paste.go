@@ -0,0 +1,217 @@
+// Package importer parses holdings from external sources.
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Position is a holding extracted from imported text.
+type Position struct {
+	Ticker   string
+	Quantity decimal.Decimal
+	AvgCost  decimal.Decimal // Zero if no cost could be found
+	Line     int             // 1-based source line
+}
+
+// column kinds recognized in a pasted header row
+const (
+	colOther = iota
+	colQuantity
+	colAvgCost
+	colTotalCost
+)
+
+var (
+	tickerPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{0,5}([.\-/][A-Z]{1,2})?$`)
+	cellSplit     = regexp.MustCompile(`\t|\s{2,}|\|`)
+	numberPattern = regexp.MustCompile(`^\(?-?[$€£]?-?[\d,]*\.?\d+\)?%?$`)
+)
+
+// words that match the ticker pattern but are never positions
+var nonTickers = map[string]bool{
+	"TOTAL": true, "TOTALS": true, "CASH": true, "USD": true, "SYMBOL": true,
+	"ACCOUNT": true, "N/A": true, "NA": true, "ETF": true, "EQUITY": true,
+	"STOCK": true, "STOCKS": true, "BUY": true, "SELL": true, "LONG": true, "SHORT": true,
+}
+
+// ParsePositions extracts positions from text copied from a broker's positions
+// page. Cells may be separated by tabs, pipes, or runs of spaces. If a header
+// row names quantity and cost columns, numbers are read from those columns;
+// otherwise the first number after the ticker is the quantity and the second
+// is the average cost. Lines that cannot be parsed are reported as warnings.
+func ParsePositions(text string) ([]Position, []string) {
+	var positions []Position
+	var warnings []string
+	var header []int // column kind per cell index, nil until a header is seen
+
+	for i, raw := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		lineNo := i + 1
+		cells := splitCells(raw)
+		if len(cells) == 0 {
+			continue
+		}
+
+		// A header row has no numbers, and its names (QTY, PRICE) can look
+		// like tickers, so it is recognized before looking for one
+		if !hasNumber(cells) {
+			if kinds, ok := parseHeader(cells); ok {
+				header = kinds
+				continue
+			}
+		}
+		tickerIdx := findTicker(cells)
+		if tickerIdx < 0 {
+			continue
+		}
+		ticker := normalizeTicker(cells[tickerIdx])
+
+		var qty, cost decimal.Decimal
+		var found bool
+		if header != nil && len(cells) == len(header) {
+			qty, cost, found = fromHeader(cells, header)
+		} else {
+			qty, cost, found = fromPosition(cells[tickerIdx+1:])
+		}
+		if !found || !qty.IsPositive() {
+			warnings = append(warnings, fmt.Sprintf("line %d: no quantity for %s", lineNo, ticker))
+			continue
+		}
+
+		positions = append(positions, Position{
+			Ticker:   ticker,
+			Quantity: qty,
+			AvgCost:  cost,
+			Line:     lineNo,
+		})
+	}
+
+	return positions, warnings
+}
+
+func splitCells(line string) []string {
+	parts := cellSplit.Split(strings.TrimSpace(line), -1)
+	if len(parts) == 1 {
+		parts = strings.Fields(parts[0])
+	}
+	cells := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			cells = append(cells, p)
+		}
+	}
+	return cells
+}
+
+func hasNumber(cells []string) bool {
+	for _, c := range cells {
+		if _, ok := parseNumber(c); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// findTicker returns the index of the first cell that looks like a ticker
+func findTicker(cells []string) int {
+	for i, c := range cells {
+		// Brokers often append a description or exchange: "AAPL APPLE INC"
+		first := strings.Fields(c)[0]
+		if tickerPattern.MatchString(first) && !nonTickers[first] {
+			return i
+		}
+	}
+	return -1
+}
+
+func normalizeTicker(cell string) string {
+	t := strings.Fields(cell)[0]
+	// Yahoo uses a dash for share classes (BRK-B)
+	return strings.NewReplacer(".", "-", "/", "-").Replace(t)
+}
+
+func parseHeader(cells []string) ([]int, bool) {
+	kinds := make([]int, len(cells))
+	hasQty := false
+	for i, c := range cells {
+		c = strings.ToLower(c)
+		switch {
+		case strings.Contains(c, "qty") || strings.Contains(c, "quantity") || c == "shares":
+			kinds[i] = colQuantity
+			hasQty = true
+		case strings.Contains(c, "avg") || strings.Contains(c, "average") ||
+			strings.Contains(c, "cost/share") || strings.Contains(c, "unit cost") ||
+			strings.Contains(c, "cost per share"):
+			kinds[i] = colAvgCost
+		case strings.Contains(c, "cost basis") || strings.Contains(c, "total cost"):
+			kinds[i] = colTotalCost
+		}
+	}
+	return kinds, hasQty
+}
+
+func fromHeader(cells []string, header []int) (decimal.Decimal, decimal.Decimal, bool) {
+	var qty, cost, totalCost decimal.Decimal
+	var hasQty bool
+	for i, kind := range header {
+		n, ok := parseNumber(cells[i])
+		if !ok {
+			continue
+		}
+		switch kind {
+		case colQuantity:
+			qty, hasQty = n, true
+		case colAvgCost:
+			cost = n
+		case colTotalCost:
+			totalCost = n
+		}
+	}
+	if cost.IsZero() && !totalCost.IsZero() && qty.IsPositive() {
+		cost = totalCost.Div(qty).Round(4)
+	}
+	return qty, cost, hasQty
+}
+
+func fromPosition(cells []string) (decimal.Decimal, decimal.Decimal, bool) {
+	var nums []decimal.Decimal
+	for _, c := range cells {
+		if n, ok := parseNumber(c); ok {
+			nums = append(nums, n)
+		}
+	}
+	switch len(nums) {
+	case 0:
+		return decimal.Zero, decimal.Zero, false
+	case 1:
+		return nums[0], decimal.Zero, true
+	default:
+		return nums[0], nums[1], true
+	}
+}
+
+// parseNumber accepts $1,234.56, (12.50) for negatives, and trailing %
+func parseNumber(s string) (decimal.Decimal, bool) {
+	s = strings.TrimSpace(s)
+	if !numberPattern.MatchString(s) {
+		return decimal.Zero, false
+	}
+	negative := strings.HasPrefix(s, "(") || strings.Contains(s, "-")
+	clean := strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == '.' {
+			return r
+		}
+		return -1
+	}, s)
+	n, err := decimal.NewFromString(clean)
+	if err != nil {
+		return decimal.Zero, false
+	}
+	if negative {
+		n = n.Neg()
+	}
+	return n, true
+}
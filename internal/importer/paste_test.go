@@ -0,0 +1,99 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestParsePositionsWithHeader(t *testing.T) {
+	text := "Symbol\tDescription\tQuantity\tPrice\tMarket Value\tCost Basis\n" +
+		"AAPL\tAPPLE INC\t100\t$230.10\t$23,010.00\t$15,025.00\n" +
+		"BRK.B\tBERKSHIRE HATHAWAY INC CL B\t10\t$460.00\t$4,600.00\t$3,500.50\n" +
+		"Cash & Cash Investments\t--\t--\t--\t$1,000.00\t--\n" +
+		"Account Total\t\t\t\t$28,610.00\t\n"
+
+	positions, warnings := ParsePositions(text)
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+	if len(positions) != 2 {
+		t.Fatalf("got %d positions, want 2: %+v", len(positions), positions)
+	}
+
+	want := []struct {
+		ticker string
+		qty    string
+		cost   string
+	}{
+		{"AAPL", "100", "150.25"},
+		{"BRK-B", "10", "350.05"},
+	}
+	for i, w := range want {
+		p := positions[i]
+		if p.Ticker != w.ticker || !p.Quantity.Equal(decimal.RequireFromString(w.qty)) || !p.AvgCost.Equal(decimal.RequireFromString(w.cost)) {
+			t.Errorf("position %d = %s %s @ %s, want %s %s @ %s", i, p.Ticker, p.Quantity, p.AvgCost, w.ticker, w.qty, w.cost)
+		}
+	}
+}
+
+func TestParsePositionsTickerLikeHeader(t *testing.T) {
+	text := "SYMBOL  QTY  PRICE  AVG COST\n" +
+		"MSFT  20  $452.35  $410.00\n"
+
+	positions, warnings := ParsePositions(text)
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+	if len(positions) != 1 || positions[0].Ticker != "MSFT" || !positions[0].Quantity.Equal(decimal.NewFromInt(20)) ||
+		!positions[0].AvgCost.Equal(decimal.NewFromInt(410)) {
+		t.Errorf("positions = %+v", positions)
+	}
+}
+
+func TestParsePositionsFreeform(t *testing.T) {
+	text := `
+MSFT   50   $310.20
+NVDA 12.5 98
+  TSLA | 3
+hello world
+SPY
+`
+	positions, warnings := ParsePositions(text)
+	if len(positions) != 3 {
+		t.Fatalf("got %d positions, want 3: %+v", len(positions), positions)
+	}
+	if p := positions[0]; p.Ticker != "MSFT" || !p.Quantity.Equal(decimal.NewFromInt(50)) || !p.AvgCost.Equal(decimal.RequireFromString("310.20")) {
+		t.Errorf("MSFT parsed as %+v", p)
+	}
+	if p := positions[1]; p.Ticker != "NVDA" || !p.Quantity.Equal(decimal.RequireFromString("12.5")) || !p.AvgCost.Equal(decimal.NewFromInt(98)) {
+		t.Errorf("NVDA parsed as %+v", p)
+	}
+	if p := positions[2]; p.Ticker != "TSLA" || !p.AvgCost.IsZero() || p.Line != 4 {
+		t.Errorf("TSLA parsed as %+v", p)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %v, want one for SPY", warnings)
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"$1,234.56", "1234.56", true},
+		{"(12.50)", "-12.5", true},
+		{"-3", "-3", true},
+		{".5", "0.5", true},
+		{"--", "", false},
+		{"abc", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := parseNumber(tt.in)
+		if ok != tt.ok || (ok && !got.Equal(decimal.RequireFromString(tt.want))) {
+			t.Errorf("parseNumber(%q) = %s, %v", tt.in, got, ok)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package importer
+
+import (
+	"strings"
+)
+
+// WatchlistSection is a named group of tickers in a TradingView watchlist.
+// Symbols before the first section marker have an empty Name.
+type WatchlistSection struct {
+	Name    string
+	Tickers []string
+}
+
+// ParseTradingView reads a TradingView watchlist export: comma- or
+// newline-separated symbols with optional EXCHANGE: prefixes, and "###Name"
+// entries starting sections. Symbols are converted to Yahoo format (BRK.B
+// becomes BRK-B); entries that are not plain tickers, such as spreads or
+// continuous futures, are skipped. Duplicates within a section are dropped.
+func ParseTradingView(text string) []WatchlistSection {
+	var sections []WatchlistSection
+	current := WatchlistSection{}
+	seen := make(map[string]bool)
+
+	flush := func() {
+		if current.Name != "" || len(current.Tickers) > 0 {
+			sections = append(sections, current)
+		}
+	}
+
+	entries := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	})
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.HasPrefix(entry, "###") {
+			flush()
+			current = WatchlistSection{Name: strings.TrimSpace(strings.TrimPrefix(entry, "###"))}
+			seen = make(map[string]bool)
+			continue
+		}
+
+		// Spreads like NASDAQ:AAPL/NASDAQ:MSFT reference several symbols
+		if strings.Count(entry, ":") > 1 {
+			continue
+		}
+		symbol := entry
+		if i := strings.LastIndex(symbol, ":"); i >= 0 {
+			symbol = symbol[i+1:]
+		}
+		symbol = strings.ToUpper(symbol)
+		if !tickerPattern.MatchString(symbol) {
+			continue
+		}
+		ticker := normalizeTicker(symbol)
+		if seen[ticker] {
+			continue
+		}
+		seen[ticker] = true
+		current.Tickers = append(current.Tickers, ticker)
+	}
+	flush()
+
+	return sections
+}
@@ -0,0 +1,27 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTradingView(t *testing.T) {
+	text := "NASDAQ:AAPL,NYSE:BRK.B,###CSP Watchlist,NASDAQ:TSLA,AMEX:SPY,NASDAQ:TSLA,\n" +
+		"CME_MINI:ES1!,NASDAQ:AAPL/NASDAQ:MSFT,###Empty"
+
+	got := ParseTradingView(text)
+	want := []WatchlistSection{
+		{Name: "", Tickers: []string{"AAPL", "BRK-B"}},
+		{Name: "CSP Watchlist", Tickers: []string{"TSLA", "SPY"}},
+		{Name: "Empty"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTradingView:\n got  %+v\n want %+v", got, want)
+	}
+}
+
+func TestParseTradingViewEmpty(t *testing.T) {
+	if got := ParseTradingView("  \n"); len(got) != 0 {
+		t.Errorf("expected no sections, got %+v", got)
+	}
+}
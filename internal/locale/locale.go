@@ -0,0 +1,141 @@
+// Package locale formats and parses numbers and dates for display in the
+// user's chosen convention (thousands separator, decimal mark, date order).
+package locale
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ISODate is the date layout always accepted when parsing, whatever the locale.
+const ISODate = "2006-01-02"
+
+// Locale is a display convention for numbers and dates.
+type Locale struct {
+	Name        string
+	Description string
+	Group       string // Thousands separator
+	Decimal     string // Decimal mark
+	DateLayout  string // Full date, as a time.Format layout
+	DateHint    string // DateLayout spelled out for form labels
+	MonthDay    string // Short day-and-month label, e.g. for expiry weeks
+}
+
+// Default matches the app's original formatting: US number separators with
+// ISO dates.
+var Default = Locale{
+	Name:        "en",
+	Description: "1,234.56  2026-03-20 (default)",
+	Group:       ",",
+	Decimal:     ".",
+	DateLayout:  ISODate,
+	DateHint:    "YYYY-MM-DD",
+	MonthDay:    "Jan 02",
+}
+
+var locales = []Locale{
+	Default,
+	{Name: "en-US", Description: "1,234.56  03/20/2026", Group: ",", Decimal: ".", DateLayout: "01/02/2006", DateHint: "MM/DD/YYYY", MonthDay: "Jan 02"},
+	{Name: "en-GB", Description: "1,234.56  20/03/2026", Group: ",", Decimal: ".", DateLayout: "02/01/2006", DateHint: "DD/MM/YYYY", MonthDay: "02 Jan"},
+	{Name: "de-DE", Description: "1.234,56  20.03.2026", Group: ".", Decimal: ",", DateLayout: "02.01.2006", DateHint: "DD.MM.YYYY", MonthDay: "02 Jan"},
+	{Name: "fr-FR", Description: "1 234,56  20/03/2026", Group: " ", Decimal: ",", DateLayout: "02/01/2006", DateHint: "DD/MM/YYYY", MonthDay: "02 Jan"},
+	{Name: "de-CH", Description: "1'234.56  20.03.2026", Group: "'", Decimal: ".", DateLayout: "02.01.2006", DateHint: "DD.MM.YYYY", MonthDay: "02 Jan"},
+}
+
+// All returns the supported locales, Default first.
+func All() []Locale {
+	return append([]Locale(nil), locales...)
+}
+
+// Lookup returns the locale with the given name (case-insensitive).
+func Lookup(name string) (Locale, bool) {
+	for _, l := range locales {
+		if strings.EqualFold(l.Name, name) {
+			return l, true
+		}
+	}
+	return Locale{}, false
+}
+
+// FormatNumber groups the integer part of a plain decimal string such as
+// "-1234.56" (as produced by StringFixed or %f) and swaps in the decimal mark.
+func (l Locale) FormatNumber(s string) string {
+	intPart, fracPart, hasFrac := strings.Cut(s, ".")
+
+	negative := strings.HasPrefix(intPart, "-")
+	intPart = strings.TrimPrefix(intPart, "-")
+
+	var sb strings.Builder
+	if negative {
+		sb.WriteByte('-')
+	}
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			sb.WriteString(l.Group)
+		}
+		sb.WriteRune(c)
+	}
+	if hasFrac {
+		sb.WriteString(l.Decimal)
+		sb.WriteString(fracPart)
+	}
+	return sb.String()
+}
+
+// FormatFixed formats d rounded to places decimal places.
+func (l Locale) FormatFixed(d decimal.Decimal, places int32) string {
+	return l.FormatNumber(d.StringFixed(places))
+}
+
+// FormatFloat formats f with places decimal places.
+func (l Locale) FormatFloat(f float64, places int) string {
+	return l.FormatNumber(fmt.Sprintf("%.*f", places, f))
+}
+
+// EditNumber formats a plain decimal string for a form field: the locale's
+// decimal mark, no grouping, so the value reads back through ParseNumber.
+func (l Locale) EditNumber(s string) string {
+	return strings.Replace(s, ".", l.Decimal, 1)
+}
+
+// ParseNumber reads a number typed in this locale. Thousands separators are
+// ignored. When the locale uses a comma decimal mark, a lone dot followed by
+// other than three digits is also taken as a decimal point, so "12.5" works
+// everywhere.
+func (l Locale) ParseNumber(s string) (decimal.Decimal, error) {
+	s = strings.TrimSpace(s)
+	if l.Decimal != "." && !strings.Contains(s, l.Decimal) && strings.Count(s, ".") == 1 {
+		if _, frac, _ := strings.Cut(s, "."); len(frac) != 3 || l.Group != "." {
+			return decimal.NewFromString(s)
+		}
+	}
+	s = strings.ReplaceAll(s, l.Group, "")
+	s = strings.Replace(s, l.Decimal, ".", 1)
+	return decimal.NewFromString(s)
+}
+
+// FormatDate formats t as a full date.
+func (l Locale) FormatDate(t time.Time) string {
+	return t.Format(l.DateLayout)
+}
+
+// FormatMonthDay formats t as a short day-and-month label.
+func (l Locale) FormatMonthDay(t time.Time) string {
+	return t.Format(l.MonthDay)
+}
+
+// ParseDate reads a date typed in this locale, or in ISO form.
+func (l Locale) ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	t, err := time.Parse(l.DateLayout, s)
+	if err == nil {
+		return t, nil
+	}
+	if t, isoErr := time.Parse(ISODate, s); isoErr == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (want %s)", s, l.DateHint)
+}
@@ -0,0 +1,110 @@
+package locale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func mustLookup(t *testing.T, name string) Locale {
+	t.Helper()
+	l, ok := Lookup(name)
+	if !ok {
+		t.Fatalf("locale %q not found", name)
+	}
+	return l
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		locale, in, want string
+	}{
+		{"en", "1234567.89", "1,234,567.89"},
+		{"en", "-1234.5", "-1,234.5"},
+		{"en", "999", "999"},
+		{"en", "-100.00", "-100.00"},
+		{"de-DE", "1234567.89", "1.234.567,89"},
+		{"fr-FR", "-1234.56", "-1 234,56"},
+		{"de-CH", "1234.56", "1'234.56"},
+	}
+	for _, tt := range tests {
+		if got := mustLookup(t, tt.locale).FormatNumber(tt.in); got != tt.want {
+			t.Errorf("%s FormatNumber(%q) = %q, want %q", tt.locale, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		locale, in, want string
+	}{
+		{"en", "1,234.56", "1234.56"},
+		{"en", "12.5", "12.5"},
+		{"de-DE", "1.234,56", "1234.56"},
+		{"de-DE", "12,5", "12.5"},
+		{"de-DE", "12.5", "12.5"},      // lone dot, not a thousands group
+		{"de-DE", "1.234", "1234"},     // lone dot before three digits is a group
+		{"fr-FR", "1 234,5", "1234.5"}, // space group
+		{"fr-FR", "12.500", "12.5"},    // dot is never a group in fr-FR
+	}
+	for _, tt := range tests {
+		got, err := mustLookup(t, tt.locale).ParseNumber(tt.in)
+		if err != nil {
+			t.Errorf("%s ParseNumber(%q): %v", tt.locale, tt.in, err)
+			continue
+		}
+		if !got.Equal(decimal.RequireFromString(tt.want)) {
+			t.Errorf("%s ParseNumber(%q) = %s, want %s", tt.locale, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEditNumberRoundTrip(t *testing.T) {
+	d := decimal.RequireFromString("1234.5")
+	for _, l := range All() {
+		got, err := l.ParseNumber(l.EditNumber(d.String()))
+		if err != nil || !got.Equal(d) {
+			t.Errorf("%s: EditNumber round trip = %s, %v", l.Name, got, err)
+		}
+	}
+}
+
+func TestDates(t *testing.T) {
+	day := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		locale, date, monthDay string
+	}{
+		{"en", "2026-03-20", "Mar 20"},
+		{"en-US", "03/20/2026", "Mar 20"},
+		{"en-GB", "20/03/2026", "20 Mar"},
+		{"de-DE", "20.03.2026", "20 Mar"},
+	}
+	for _, tt := range tests {
+		l := mustLookup(t, tt.locale)
+		if got := l.FormatDate(day); got != tt.date {
+			t.Errorf("%s FormatDate = %q, want %q", tt.locale, got, tt.date)
+		}
+		if got := l.FormatMonthDay(day); got != tt.monthDay {
+			t.Errorf("%s FormatMonthDay = %q, want %q", tt.locale, got, tt.monthDay)
+		}
+		for _, in := range []string{tt.date, "2026-03-20"} {
+			if got, err := l.ParseDate(in); err != nil || !got.Equal(day) {
+				t.Errorf("%s ParseDate(%q) = %v, %v", tt.locale, in, got, err)
+			}
+		}
+	}
+
+	if _, err := mustLookup(t, "en-GB").ParseDate("03/20/2026"); err == nil {
+		t.Error("en-GB accepted a month-first date")
+	}
+}
+
+func TestLookupIsCaseInsensitive(t *testing.T) {
+	if l := mustLookup(t, "DE-de"); l.Decimal != "," {
+		t.Errorf("DE-de decimal = %q", l.Decimal)
+	}
+	if _, ok := Lookup("xx"); ok {
+		t.Error("Lookup(xx) succeeded")
+	}
+}
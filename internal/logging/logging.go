@@ -0,0 +1,156 @@
+// Package logging configures structured logging to a size-rotated file.
+package logging
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultMaxBytes is the size at which the log file is rotated.
+	DefaultMaxBytes = 5 << 20
+	// DefaultBackups is how many rotated files are kept (file.1 ... file.N).
+	DefaultBackups = 3
+)
+
+// DefaultPath returns the log file location used when LOG_FILE is unset.
+func DefaultPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "anyhowhodl", "anyhowhodl.log")
+}
+
+// ParseLevel maps debug/info/warn/error (case-insensitive) to a level.
+// Empty input is info.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("invalid log level %q", s)
+	}
+	return level, nil
+}
+
+// Setup opens path for rotated logging and installs a text handler at level
+// as the default slog logger, which also captures the standard log package.
+// Extra writers (e.g. stderr for the daemon) receive the same records.
+func Setup(path string, level slog.Level, extra ...io.Writer) (io.Closer, error) {
+	file, err := OpenRotating(path, DefaultMaxBytes, DefaultBackups)
+	if err != nil {
+		return nil, err
+	}
+
+	var w io.Writer = file
+	if len(extra) > 0 {
+		w = io.MultiWriter(append([]io.Writer{file}, extra...)...)
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})))
+	return file, nil
+}
+
+// RotatingFile is an append-only file that rotates when it exceeds maxBytes.
+type RotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	backups  int
+	file     *os.File
+	size     int64
+}
+
+// OpenRotating opens (or creates) path for appending, creating parent directories.
+func OpenRotating(path string, maxBytes int64, backups int) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	r := &RotatingFile{path: path, maxBytes: maxBytes, backups: backups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file = f
+	r.size = info.Size()
+	return nil
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size > 0 && r.size+int64(len(p)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts file.N-1 to file.N ... file to file.1 and reopens file
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	for i := r.backups; i > 0; i-- {
+		src := r.path
+		if i > 1 {
+			src = fmt.Sprintf("%s.%d", r.path, i-1)
+		}
+		if _, err := os.Stat(src); err == nil {
+			os.Rename(src, fmt.Sprintf("%s.%d", r.path, i))
+		}
+	}
+	if r.backups == 0 {
+		os.Remove(r.path)
+	}
+	return r.open()
+}
+
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// Tail returns the last n lines of the file at path.
+func Tail(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ring := make([]string, 0, n)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		if len(ring) == n {
+			ring = ring[1:]
+		}
+		ring = append(ring, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	return ring, scanner.Err()
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	r, err := OpenRotating(path, 20, 2)
+	if err != nil {
+		t.Fatalf("OpenRotating: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		if _, err := fmt.Fprintf(r, "line %d 123456789\n", i); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+	}
+	r.Close()
+
+	read := func(p string) string {
+		b, _ := os.ReadFile(p)
+		return string(b)
+	}
+	if got := read(path); got != "line 3 123456789\n" {
+		t.Errorf("current = %q", got)
+	}
+	if got := read(path + ".1"); got != "line 2 123456789\n" {
+		t.Errorf(".1 = %q", got)
+	}
+	if got := read(path + ".2"); got != "line 1 123456789\n" {
+		t.Errorf(".2 = %q", got)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("expected only 2 backups")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{"": slog.LevelInfo, "debug": slog.LevelDebug, "WARN": slog.LevelWarn, "error": slog.LevelError}
+	for in, want := range tests {
+		if got, err := ParseLevel(in); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v", in, got, err)
+		}
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
+
+func TestTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(path, []byte("a\nb\nc\nd\n"), 0644)
+
+	lines, err := Tail(path, 2)
+	if err != nil {
+		t.Fatalf("Tail: %v", err)
+	}
+	if strings.Join(lines, ",") != "c,d" {
+		t.Errorf("Tail = %v", lines)
+	}
+}
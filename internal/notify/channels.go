@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// sendTimeout bounds a webhook post
+const sendTimeout = 15 * time.Second
+
+// WebhookChannel posts events as JSON, {"kind", "key", "text"}, to a URL.
+type WebhookChannel struct {
+	URL    string
+	Client *http.Client // http.DefaultClient with a timeout if nil
+}
+
+func (w *WebhookChannel) Name() string { return Webhook }
+
+func (w *WebhookChannel) Send(ctx context.Context, e Event) error {
+	body, err := json.Marshal(map[string]string{"kind": e.Kind, "key": e.Key, "text": e.Text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: sendTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// EmailChannel mails events over SMTP, one message per event.
+type EmailChannel struct {
+	Addr     string // SMTP server host:port
+	Username string // Plain auth if set
+	Password string
+	From     string
+	To       []string
+}
+
+func (m *EmailChannel) Name() string { return Email }
+
+func (m *EmailChannel) Send(ctx context.Context, e Event) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		host, _, _ := strings.Cut(m.Addr, ":")
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+	return smtp.SendMail(m.Addr, auth, m.From, m.To, emailMessage(m.From, m.To, e))
+}
+
+// emailMessage is e as a plain-text mail, its first line the subject
+func emailMessage(from string, to []string, e Event) []byte {
+	subject, _, _ := strings.Cut(e.Text, "\n")
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: anyhowhodl: %s\r\n", subject)
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(e.Text, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// TelegramSender is the part of a Telegram client a channel needs.
+type TelegramSender interface {
+	SendMessage(ctx context.Context, chatID int64, text string) error
+}
+
+// TelegramChannel messages events to a chat.
+type TelegramChannel struct {
+	Client TelegramSender
+	ChatID int64
+}
+
+func (t *TelegramChannel) Name() string { return Telegram }
+
+func (t *TelegramChannel) Send(ctx context.Context, e Event) error {
+	return t.Client.SendMessage(ctx, t.ChatID, e.Text)
+}
+
+// DesktopChannel shows events as desktop notifications, with notify-send on
+// Linux and osascript on macOS.
+type DesktopChannel struct{}
+
+func (DesktopChannel) Name() string { return Desktop }
+
+func (DesktopChannel) Send(ctx context.Context, e Event) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", e.Text, "anyhowhodl")
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	case "linux", "freebsd", "openbsd":
+		cmd = exec.CommandContext(ctx, "notify-send", "anyhowhodl", e.Text)
+	default:
+		return fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+// Package notify delivers events such as alerts over pluggable channels,
+// routed by event kind.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Channel names
+const (
+	Terminal = "terminal" // The TUI's log and bell, or the daemon's log
+	Webhook  = "webhook"
+	Email    = "email"
+	Telegram = "telegram"
+	Desktop  = "desktop"
+)
+
+// ChannelNames lists the channels routes may name.
+var ChannelNames = []string{Terminal, Webhook, Email, Telegram, Desktop}
+
+// Event kinds beyond the alert kinds, which the alert engine names
+const (
+	KindPolicy  = "policy"  // Option policy actions queued for confirmation
+	KindBroker  = "broker"  // Broker sync discrepancies
+	KindInbound = "inbound" // Alerts received by the webhook server
+	KindSummary = "summary" // The daemon's end-of-day summary
+)
+
+// AnyKind in a route matches events of every kind without a route of their own.
+const AnyKind = "*"
+
+// DefaultRoutes sends everything to the terminal and Telegram, as before
+// routes could be configured.
+const DefaultRoutes = "*=terminal,telegram"
+
+// Event is something worth telling the user about. Key is stable for the
+// same condition on the same day, so senders can de-duplicate.
+type Event struct {
+	Kind string
+	Key  string
+	Text string
+}
+
+// Channel delivers events. Implementations must be safe for concurrent use.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, e Event) error
+}
+
+// Route sends events of Kind to the named channels.
+type Route struct {
+	Kind     string
+	Channels []string
+}
+
+// ParseRoutes reads routes written as "kind=channel,channel; kind=channel",
+// e.g. "itm=telegram,desktop; *=terminal". An empty text means DefaultRoutes.
+func ParseRoutes(text string) ([]Route, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultRoutes
+	}
+	var routes []Route
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(text, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kind, list, ok := strings.Cut(part, "=")
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		if !ok || kind == "" {
+			return nil, fmt.Errorf("%q: want kind=channel,channel", part)
+		}
+		if seen[kind] {
+			return nil, fmt.Errorf("%s routed twice", kind)
+		}
+		seen[kind] = true
+		route := Route{Kind: kind}
+		for _, name := range strings.Split(list, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if !slices.Contains(ChannelNames, name) {
+				return nil, fmt.Errorf("unknown channel %q (have %s)", name, strings.Join(ChannelNames, ", "))
+			}
+			if !slices.Contains(route.Channels, name) {
+				route.Channels = append(route.Channels, name)
+			}
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// FormatRoutes writes routes as ParseRoutes reads them.
+func FormatRoutes(routes []Route) string {
+	parts := make([]string, len(routes))
+	for i, r := range routes {
+		parts[i] = r.Kind + "=" + strings.Join(r.Channels, ",")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Dispatcher sends each event to the channels its kind is routed to. Routes
+// naming a channel that is not registered, e.g. one left unconfigured, are
+// skipped, so channels can be added without touching what raises events.
+type Dispatcher struct {
+	routes   []Route
+	channels map[string]Channel
+}
+
+// NewDispatcher routes events over channels.
+func NewDispatcher(routes []Route, channels ...Channel) *Dispatcher {
+	d := &Dispatcher{routes: routes, channels: make(map[string]Channel)}
+	for _, c := range channels {
+		d.channels[c.Name()] = c
+	}
+	return d
+}
+
+// Routes lists the channels events of kind go to: its own route's, or the
+// AnyKind route's if it has none.
+func (d *Dispatcher) Routes(kind string) []string {
+	var fallback []string
+	for _, r := range d.routes {
+		switch r.Kind {
+		case kind:
+			return r.Channels
+		case AnyKind:
+			fallback = r.Channels
+		}
+	}
+	return fallback
+}
+
+// Registered lists the names of the channels that can be sent to, sorted.
+func (d *Dispatcher) Registered() []string {
+	names := make([]string, 0, len(d.channels))
+	for name := range d.channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Notify sends e to each registered channel its kind is routed to. A failing
+// channel does not stop the others; their errors are returned together.
+func (d *Dispatcher) Notify(ctx context.Context, e Event) error {
+	var errs []error
+	for _, name := range d.Routes(e.Kind) {
+		c, ok := d.channels[name]
+		if !ok {
+			continue
+		}
+		if err := c.Send(ctx, e); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// funcChannel is a channel backed by a function
+type funcChannel struct {
+	name string
+	send func(ctx context.Context, e Event) error
+}
+
+// NewFunc makes a channel named name that delivers events by calling send.
+func NewFunc(name string, send func(ctx context.Context, e Event) error) Channel {
+	return funcChannel{name: name, send: send}
+}
+
+func (c funcChannel) Name() string { return c.name }
+
+func (c funcChannel) Send(ctx context.Context, e Event) error { return c.send(ctx, e) }
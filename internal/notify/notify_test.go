@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := ParseRoutes(" ITM = telegram, Desktop ,telegram; *=terminal ;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := FormatRoutes(routes); got != "itm=telegram,desktop; *=terminal" {
+		t.Errorf("routes = %q", got)
+	}
+
+	if routes, _ := ParseRoutes(""); FormatRoutes(routes) != DefaultRoutes {
+		t.Errorf("empty routes = %q, want %q", FormatRoutes(routes), DefaultRoutes)
+	}
+	// A kind may be routed nowhere, silencing it
+	if routes, err := ParseRoutes("expiry=; *=terminal"); err != nil || len(routes[0].Channels) != 0 {
+		t.Errorf("silenced kind = %+v, %v", routes, err)
+	}
+
+	for _, text := range []string{"itm", "=terminal", "itm=pager", "itm=terminal; itm=email"} {
+		if _, err := ParseRoutes(text); err == nil {
+			t.Errorf("ParseRoutes(%q) accepted", text)
+		}
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	var got []string
+	record := func(name string) Channel {
+		return NewFunc(name, func(ctx context.Context, e Event) error {
+			got = append(got, name+":"+e.Key)
+			return nil
+		})
+	}
+	failing := NewFunc(Email, func(ctx context.Context, e Event) error { return errors.New("no server") })
+
+	routes, _ := ParseRoutes("itm=telegram,webhook,email; reminder=; *=terminal")
+	d := NewDispatcher(routes, record(Terminal), record(Telegram), failing)
+
+	if err := d.Notify(context.Background(), Event{Kind: "itm", Key: "a"}); err == nil || !strings.Contains(err.Error(), "email: no server") {
+		t.Errorf("failing channel error = %v", err)
+	}
+	d.Notify(context.Background(), Event{Kind: "expiry", Key: "b"})
+	d.Notify(context.Background(), Event{Kind: "reminder", Key: "c"})
+
+	// The unregistered webhook is skipped and the failing email does not stop
+	// the others; expiry falls back to *, reminders go nowhere
+	if want := []string{"telegram:a", "terminal:b"}; !slices.Equal(got, want) {
+		t.Errorf("sent %v, want %v", got, want)
+	}
+	if want := []string{Email, Telegram, Terminal}; !slices.Equal(d.Registered(), want) {
+		t.Errorf("registered = %v, want %v", d.Registered(), want)
+	}
+}
+
+func TestWebhookChannel(t *testing.T) {
+	var body map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["kind"] == "fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	w := &WebhookChannel{URL: server.URL}
+	if err := w.Send(context.Background(), Event{Kind: "itm", Key: "itm:1", Text: "Short TSLA PUT is ITM"}); err != nil {
+		t.Fatal(err)
+	}
+	if body["kind"] != "itm" || body["key"] != "itm:1" || body["text"] != "Short TSLA PUT is ITM" {
+		t.Errorf("posted %v", body)
+	}
+	if err := w.Send(context.Background(), Event{Kind: "fail"}); err == nil {
+		t.Error("error status accepted")
+	}
+}
+
+func TestEmailMessage(t *testing.T) {
+	msg := string(emailMessage("bot@example.com", []string{"me@example.com", "you@example.com"},
+		Event{Text: "Broker sync discrepancies:\nAAPL: recorded 100 shares, broker reports 90"}))
+	for _, want := range []string{
+		"To: me@example.com, you@example.com\r\n",
+		"Subject: anyhowhodl: Broker sync discrepancies:\r\n",
+		"\r\n\r\nBroker sync discrepancies:\r\nAAPL: recorded 100 shares, broker reports 90\r\n",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message missing %q:\n%s", want, msg)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/yahoo"
+
+	"github.com/shopspring/decimal"
+)
+
+// AlertExpiryDays is how close to expiry an active option triggers an alert.
+const AlertExpiryDays = 3
+
+// Alert kinds, which notification routes are set by
+const (
+	AlertTarget   = "target"   // Holding at its target price
+	AlertExpiry   = "expiry"   // Option about to expire
+	AlertITM      = "itm"      // Short option in the money
+	AlertReminder = "reminder" // Option reminder due
+)
+
+// Alert is a notification-worthy condition. Key is stable for the same
+// condition on the same day so senders can de-duplicate; ID is the holding
+// or option the alert is about.
+type Alert struct {
+	Kind    string
+	Key     string
+	ID      string
+	Message string
+}
+
+// Alerts returns conditions that need attention: holdings at their target
+// price, options about to expire, short options in the money, and option
+// reminders that are due.
+func (s *Service) Alerts(ctx context.Context) ([]Alert, error) {
+	holdings, err := s.db.GetHoldings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	options, err := s.ActiveOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tickers := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, h := range holdings {
+		if !seen[h.Ticker] {
+			tickers = append(tickers, h.Ticker)
+			seen[h.Ticker] = true
+		}
+	}
+	for _, o := range options {
+		if !seen[o.Ticker] {
+			tickers = append(tickers, o.Ticker)
+			seen[o.Ticker] = true
+		}
+	}
+	quotes, _ := s.yahoo.GetQuotes(tickers)
+	// Without schema_reminders.sql there are no reminders
+	reminders, _ := s.db.GetReminders(ctx)
+	return EvaluateAlerts(holdings, options, reminders, quotes, time.Now()), nil
+}
+
+// EvaluateAlerts returns the alerts for holdings and options priced at
+// quotes as of now, and for the reminders due by then. Options that are not
+// active are ignored, though their reminders are not.
+func EvaluateAlerts(holdings []db.Holding, options []db.Option, reminders []db.Reminder, quotes map[string]yahoo.Quote, now time.Time) []Alert {
+	day := now.Format("2006-01-02")
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var alerts []Alert
+
+	for _, h := range holdings {
+		quote, ok := quotes[h.Ticker]
+		if !ok || !h.TargetPrice.Valid {
+			continue
+		}
+		price := decimal.NewFromFloat(quote.Price)
+		if price.GreaterThanOrEqual(h.TargetPrice.Decimal) {
+			alerts = append(alerts, Alert{
+				Kind:    AlertTarget,
+				Key:     fmt.Sprintf("target:%s:%s", h.ID, day),
+				ID:      h.ID,
+				Message: fmt.Sprintf("%s hit target $%s (now $%s)", h.Ticker, h.TargetPrice.Decimal.StringFixed(2), price.StringFixed(2)),
+			})
+		}
+	}
+
+	for _, o := range options {
+		if o.Status != "ACTIVE" {
+			continue
+		}
+		daysLeft := int(o.ExpiryDate.Sub(today).Hours() / 24)
+		if daysLeft >= 0 && daysLeft <= AlertExpiryDays {
+			alerts = append(alerts, Alert{
+				Kind:    AlertExpiry,
+				Key:     fmt.Sprintf("expiry:%s:%s", o.ID, day),
+				ID:      o.ID,
+				Message: fmt.Sprintf("%s %s %s $%s expires in %dd", o.Ticker, o.Action, o.OptionType, o.Strike.StringFixed(2), daysLeft),
+			})
+		}
+
+		quote, ok := quotes[o.Ticker]
+		if !ok || o.Action != "SELL" {
+			continue
+		}
+		price := decimal.NewFromFloat(quote.Price)
+		itm := (o.OptionType == "PUT" && price.LessThan(o.Strike)) || (o.OptionType == "CALL" && price.GreaterThan(o.Strike))
+		if itm {
+			alerts = append(alerts, Alert{
+				Kind:    AlertITM,
+				Key:     fmt.Sprintf("itm:%s:%s", o.ID, day),
+				ID:      o.ID,
+				Message: fmt.Sprintf("Short %s %s $%s is ITM (now $%s)", o.Ticker, o.OptionType, o.Strike.StringFixed(2), price.StringFixed(2)),
+			})
+		}
+	}
+
+	// Reminder dates are stored as midnight UTC
+	todayUTC := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for _, r := range reminders {
+		if !r.Due(todayUTC) {
+			continue
+		}
+		subject := "option"
+		for _, o := range options {
+			if o.ID == r.OptionID {
+				subject = fmt.Sprintf("%s %s %s $%s", o.Ticker, o.Action, o.OptionType, o.Strike.StringFixed(2))
+				break
+			}
+		}
+		alerts = append(alerts, Alert{
+			Kind:    AlertReminder,
+			Key:     fmt.Sprintf("reminder:%s:%s", r.ID, day),
+			ID:      r.OptionID,
+			Message: fmt.Sprintf("Reminder for %s: %s", subject, r.Note),
+		})
+	}
+
+	return alerts
+}
@@ -0,0 +1,169 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/db"
+
+	"github.com/shopspring/decimal"
+)
+
+// OptionMark is an active option marked from its chain.
+type OptionMark struct {
+	Option db.Option
+	Mark   float64         // Per share, zero if the contract is not listed
+	PL     decimal.Decimal // Unrealized, against the opening premium
+}
+
+// EndOfDay is the day's wrap-up: what the portfolio did today and what is
+// coming on the next session.
+type EndOfDay struct {
+	Day      time.Time
+	Next     time.Time // The next weekday, when the listed expiries and earnings fall
+	Summary  *PortfolioSummary
+	Change   decimal.Decimal // The holdings' change today
+	Marks    []OptionMark
+	Alerts   []Alert
+	Expiring []db.Option // Active options expiring after today through Next
+	Earnings []string    // Held or optioned tickers reporting on Next
+}
+
+// nextWeekday is the first weekday after day; exchange holidays are not
+// known, so it may be one.
+func nextWeekday(day time.Time) time.Time {
+	next := day.AddDate(0, 0, 1)
+	for next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// EndOfDay gathers the wrap-up for the day of now: the portfolio's totals
+// and change, every active option's mark, the alerts firing, and the
+// expiries and earnings due on the next weekday.
+func (s *Service) EndOfDay(ctx context.Context, now time.Time) (*EndOfDay, error) {
+	sum, err := s.Summary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	holdings, err := s.db.GetHoldings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	options, err := s.ActiveOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	alerts, err := s.Alerts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	e := &EndOfDay{Day: day, Next: nextWeekday(day), Summary: sum, Alerts: alerts}
+
+	var tickers []string
+	seen := make(map[string]bool)
+	for _, h := range holdings {
+		if !seen[h.Ticker] {
+			tickers = append(tickers, h.Ticker)
+			seen[h.Ticker] = true
+		}
+	}
+	for _, o := range options {
+		if !seen[o.Ticker] {
+			tickers = append(tickers, o.Ticker)
+			seen[o.Ticker] = true
+		}
+	}
+	quotes, _ := s.yahoo.GetQuotes(tickers)
+	for _, h := range holdings {
+		if q, ok := quotes[h.Ticker]; ok {
+			e.Change = e.Change.Add(h.Quantity.Mul(decimal.NewFromFloat(q.Change)))
+		}
+	}
+
+	for _, o := range options {
+		expiry := time.Date(o.ExpiryDate.Year(), o.ExpiryDate.Month(), o.ExpiryDate.Day(), 0, 0, 0, 0, time.UTC)
+		chain, err := s.yahoo.FetchOptionsChainForExpiry(o.Ticker, expiry.Unix())
+		if err != nil {
+			chain = nil
+		}
+		m := OptionMark{Option: o, Mark: optionMark(chain, o)}
+		if m.Mark > 0 {
+			m.PL = o.Premium.Sub(decimal.NewFromFloat(m.Mark)).Mul(o.Shares())
+			if o.Action == "BUY" {
+				m.PL = m.PL.Neg()
+			}
+		}
+		e.Marks = append(e.Marks, m)
+
+		expiryDay := time.Date(o.ExpiryDate.Year(), o.ExpiryDate.Month(), o.ExpiryDate.Day(), 0, 0, 0, 0, now.Location())
+		if expiryDay.After(day) && !expiryDay.After(e.Next) {
+			e.Expiring = append(e.Expiring, o)
+		}
+	}
+
+	for _, ticker := range tickers {
+		ed, err := s.yahoo.FetchEarningsDate(ticker)
+		if err != nil || ed.Date.IsZero() {
+			continue
+		}
+		if ed.Date.In(now.Location()).Format(time.DateOnly) == e.Next.Format(time.DateOnly) {
+			e.Earnings = append(e.Earnings, ticker)
+		}
+	}
+	return e, nil
+}
+
+// Text formats the wrap-up for notifications.
+func (e *EndOfDay) Text() string {
+	money := func(d decimal.Decimal) string {
+		if d.IsNegative() {
+			return "-$" + d.Abs().StringFixed(2)
+		}
+		return "+$" + d.StringFixed(2)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "End of day %s\n", e.Day.Format(time.DateOnly))
+	fmt.Fprintf(&sb, "Portfolio: $%s, today %s\n", e.Summary.Total.StringFixed(2), money(e.Change))
+	fmt.Fprintf(&sb, "P/L: %s (%s%%)\n", money(e.Summary.PL), e.Summary.PLPct.StringFixed(1))
+
+	if len(e.Marks) > 0 {
+		sb.WriteString("Options:\n")
+		for _, m := range e.Marks {
+			o := m.Option
+			fmt.Fprintf(&sb, "  %s %s %s $%s exp %s: ", o.Ticker, o.Action, o.OptionType, o.Strike.StringFixed(2), o.ExpiryDate.Format("01-02"))
+			if m.Mark > 0 {
+				fmt.Fprintf(&sb, "mark $%.2f, %s\n", m.Mark, money(m.PL))
+			} else {
+				sb.WriteString("no mark\n")
+			}
+		}
+	}
+
+	if len(e.Alerts) > 0 {
+		sb.WriteString("Alerts:\n")
+		for _, al := range e.Alerts {
+			sb.WriteString("  " + al.Message + "\n")
+		}
+	}
+
+	next := e.Next.Format("Mon 01-02")
+	if len(e.Expiring) == 0 && len(e.Earnings) == 0 {
+		fmt.Fprintf(&sb, "Nothing expiring or reporting by %s", next)
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "By %s:", next)
+	for _, o := range e.Expiring {
+		fmt.Fprintf(&sb, "\n  %s %s %s $%s x%d expires %s", o.Ticker, o.Action, o.OptionType, o.Strike.StringFixed(2), o.Quantity, o.ExpiryDate.Format("01-02"))
+	}
+	for _, ticker := range e.Earnings {
+		fmt.Fprintf(&sb, "\n  %s reports earnings", ticker)
+	}
+	return sb.String()
+}
@@ -0,0 +1,71 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"anyhowhodl/internal/db"
+)
+
+// OILookbackDays is how far back the previous open interest snapshot is
+// looked for, enough to span a long weekend.
+const OILookbackDays = 7
+
+// OIChange is a contract's open interest and its change since the last
+// earlier day it was recorded. HasChange is false for a contract seen for
+// the first time.
+type OIChange struct {
+	OpenInterest int
+	Change       int
+	HasChange    bool
+}
+
+// TrackOpenInterest records the open interest of contracts, snapshots all
+// taken on the same Day, and returns each one's change since its latest
+// earlier snapshot, in the order given. If the snapshots cannot be read or
+// stored the error says so; open interest is still returned, without changes.
+func (s *Service) TrackOpenInterest(ctx context.Context, contracts []db.OpenInterest) ([]OIChange, error) {
+	changes := make([]OIChange, len(contracts))
+	for i, c := range contracts {
+		changes[i].OpenInterest = c.OpenInterest
+	}
+	if len(contracts) == 0 {
+		return changes, nil
+	}
+
+	history, err := s.db.GetOpenInterest(ctx, contracts[0].Day.AddDate(0, 0, -OILookbackDays))
+	if err != nil {
+		return changes, err
+	}
+	for i, c := range contracts {
+		// Oldest first, so the latest earlier day wins
+		for _, prev := range history {
+			if prev.Day.Before(c.Day) && prev.SameContract(c) {
+				changes[i].Change = c.OpenInterest - prev.OpenInterest
+				changes[i].HasChange = true
+			}
+		}
+	}
+	return changes, s.db.SaveOpenInterest(ctx, contracts)
+}
+
+// TrackCSPOpenInterest records the open interest of the results' target
+// puts on day and sets each result's OI.
+func (s *Service) TrackCSPOpenInterest(ctx context.Context, results []CSPResult, day time.Time) error {
+	contracts := make([]db.OpenInterest, len(results))
+	for i, r := range results {
+		contracts[i] = db.OpenInterest{
+			Ticker:       r.Ticker,
+			OptionType:   "PUT",
+			Expiry:       r.Expiry,
+			Strike:       r.Strike,
+			Day:          day,
+			OpenInterest: r.OI.OpenInterest,
+		}
+	}
+	changes, err := s.TrackOpenInterest(ctx, contracts)
+	for i := range results {
+		results[i].OI = changes[i]
+	}
+	return err
+}
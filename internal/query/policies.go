@@ -0,0 +1,174 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/csp"
+	"anyhowhodl/internal/db"
+
+	"github.com/shopspring/decimal"
+)
+
+// RollTargetDays is how far out a roll looks for the expiry to roll to.
+const RollTargetDays = 30
+
+// EvaluatePolicies checks every policy on an active option against current
+// prices and returns the actions they call for. Options are marked from their
+// chain at the bid/ask midpoint, or the last price if there is no market.
+func (s *Service) EvaluatePolicies(ctx context.Context, now time.Time) ([]db.PolicyAction, error) {
+	policies, err := s.db.GetOptionPolicies(ctx)
+	if err != nil || len(policies) == 0 {
+		return nil, err
+	}
+	options, err := s.ActiveOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]db.Option, len(options))
+	var tickers []string
+	seen := make(map[string]bool)
+	for _, o := range options {
+		byID[o.ID] = o
+		if !seen[o.Ticker] {
+			tickers = append(tickers, o.Ticker)
+			seen[o.Ticker] = true
+		}
+	}
+	quotes, _ := s.yahoo.GetQuotes(tickers)
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	chains := make(map[string]*csp.OptionsData)
+	chain := func(o db.Option) *csp.OptionsData {
+		key := o.Ticker + "|" + o.ExpiryDate.Format(time.DateOnly)
+		if c, ok := chains[key]; ok {
+			return c
+		}
+		expiry := time.Date(o.ExpiryDate.Year(), o.ExpiryDate.Month(), o.ExpiryDate.Day(), 0, 0, 0, 0, time.UTC).Unix()
+		c, err := s.yahoo.FetchOptionsChainForExpiry(o.Ticker, expiry)
+		if err != nil {
+			c = nil
+		}
+		chains[key] = c
+		return c
+	}
+
+	var actions []db.PolicyAction
+	for _, p := range policies {
+		o, ok := byID[p.OptionID]
+		if !ok {
+			continue
+		}
+		in := analytics.PolicyInput{
+			OptionType: o.OptionType,
+			Action:     o.Action,
+			Strike:     o.Strike.InexactFloat64(),
+			Premium:    o.Premium.InexactFloat64(),
+			DTE:        int(o.ExpiryDate.Sub(today).Hours() / 24),
+			Underlying: quotes[o.Ticker].Price,
+		}
+		policy := analytics.Policy{Kind: p.Kind, Threshold: p.Threshold.InexactFloat64(), ITMOnly: p.ITMOnly}
+		if p.Kind == analytics.PolicyCloseAtProfit {
+			in.Mark = optionMark(chain(o), o)
+		}
+		reason, fire := policy.Evaluate(in)
+		if !fire {
+			continue
+		}
+
+		action := db.PolicyAction{PolicyID: p.ID, OptionID: o.ID, Action: db.PolicyActionClose, Reason: reason}
+		if p.Kind == analytics.PolicyRollAtDTE {
+			action.Action = db.PolicyActionRoll
+			in.Mark = optionMark(chain(o), o)
+			action.RollExpiry = rollExpiry(chain(o), today)
+		}
+		if in.Mark > 0 {
+			action.Price = decimal.NewNullDecimal(decimal.NewFromFloat(in.Mark).Round(2))
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// QueuePolicyActions evaluates the policies and queues what they call for,
+// returning the actions that were not already pending.
+func (s *Service) QueuePolicyActions(ctx context.Context, now time.Time) ([]db.PolicyAction, error) {
+	actions, err := s.EvaluatePolicies(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	var queued []db.PolicyAction
+	for _, a := range actions {
+		added, err := s.db.QueuePolicyAction(ctx, a)
+		if err != nil {
+			return queued, err
+		}
+		if added {
+			queued = append(queued, a)
+		}
+	}
+	return queued, nil
+}
+
+// PolicyActionText describes a queued action on option o for notifications.
+func PolicyActionText(a db.PolicyAction, o db.Option) string {
+	verb := "Close"
+	if a.Action == db.PolicyActionRoll {
+		verb = "Roll"
+	}
+	text := fmt.Sprintf("%s %s %s %s $%s exp %s: %s", verb, o.Ticker, o.Action, o.OptionType,
+		o.Strike.StringFixed(2), o.ExpiryDate.Format("01-02"), a.Reason)
+	if a.Price.Valid {
+		text += fmt.Sprintf(" (mark $%s)", a.Price.Decimal.StringFixed(2))
+	}
+	if !a.RollExpiry.IsZero() {
+		text += fmt.Sprintf(", roll to %s", a.RollExpiry.Format(time.DateOnly))
+	}
+	return text
+}
+
+// optionMark finds o in its chain and returns the bid/ask midpoint, or the
+// last price without a two-sided market. It is zero if o is not listed.
+func optionMark(chain *csp.OptionsData, o db.Option) float64 {
+	if chain == nil {
+		return 0
+	}
+	contracts := chain.Calls
+	if o.OptionType == "PUT" {
+		contracts = chain.Puts
+	}
+	strike := o.Strike.InexactFloat64()
+	for _, c := range contracts {
+		if math.Abs(c.Strike-strike) > 0.001 {
+			continue
+		}
+		if c.Bid > 0 && c.Ask > 0 {
+			return (c.Bid + c.Ask) / 2
+		}
+		return c.LastPrice
+	}
+	return 0
+}
+
+// rollExpiry picks the first listed expiry at least RollTargetDays out, or
+// zero if the chain lists none.
+func rollExpiry(chain *csp.OptionsData, today time.Time) time.Time {
+	if chain == nil {
+		return time.Time{}
+	}
+	earliest := today.AddDate(0, 0, RollTargetDays)
+	var best time.Time
+	for _, e := range chain.ExpirationDates {
+		t := time.Unix(e, 0).UTC()
+		if t.Before(earliest) {
+			continue
+		}
+		if best.IsZero() || t.Before(best) {
+			best = t
+		}
+	}
+	return best
+}
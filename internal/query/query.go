@@ -0,0 +1,393 @@
+// Package query is the headless read layer shared by the TUI, daemon, and bots.
+package query
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/csp"
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/yahoo"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultVIX is used when the VIX quote cannot be fetched.
+const DefaultVIX = 20.0
+
+// ScanDelay is the pause between tickers in a scan to avoid rate limiting.
+const ScanDelay = 200 * time.Millisecond
+
+type Service struct {
+	db    db.Store
+	yahoo yahoo.Provider
+}
+
+func New(database db.Store, client yahoo.Provider) *Service {
+	return &Service{db: database, yahoo: client}
+}
+
+// PortfolioSummary holds portfolio totals at current prices.
+type PortfolioSummary struct {
+	Positions     int
+	HoldingsValue decimal.Decimal // In the base currency, capped at the lowest short call strike, as in the holdings table
+	CostBasis     decimal.Decimal // In the base currency
+	Cash          decimal.Decimal
+	Total         decimal.Decimal
+	PL            decimal.Decimal
+	PLPct         decimal.Decimal
+	ActiveOptions int
+	Premiums      *db.PremiumSummary // Current tax year
+	PremiumYear   string             // Label of the tax year, e.g. "2026" or "2025/26"
+	ManualPriced  int                // Holdings valued at a manual price
+	Unpriced      int                // Holdings with no price at all, valued at cost
+}
+
+// TaxYear returns the configured tax year, falling back to the calendar year
+// if the setting cannot be read.
+func (s *Service) TaxYear(ctx context.Context) analytics.TaxYear {
+	start, err := s.db.GetTaxYearStart(ctx)
+	if err != nil {
+		return analytics.TaxYear{}
+	}
+	y, err := analytics.ParseTaxYear(start)
+	if err != nil {
+		return analytics.TaxYear{}
+	}
+	return y
+}
+
+// Summary computes portfolio totals using live quotes.
+func (s *Service) Summary(ctx context.Context) (*PortfolioSummary, error) {
+	holdings, err := s.db.GetHoldings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	options, err := s.ActiveOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cash, err := s.db.GetAvailableCash(ctx)
+	if err != nil {
+		cash = decimal.Zero
+	}
+	taxYear := s.TaxYear(ctx)
+	from, to := taxYear.Bounds(time.Now())
+	premiums, err := s.db.GetPremiumsBetween(ctx, from, to)
+	if err != nil {
+		premiums = &db.PremiumSummary{}
+	}
+
+	tickers := make([]string, len(holdings))
+	for i, h := range holdings {
+		tickers[i] = h.Ticker
+	}
+	quotes, _ := s.yahoo.GetQuotes(tickers)
+	manual, _ := s.db.GetManualPrices(ctx) // None without schema_prices.sql
+	quotes, used := WithManualPrices(quotes, manual)
+
+	callCaps := ShortCallCaps(options)
+	toBase := s.ToBase(ctx)
+
+	sum := &PortfolioSummary{
+		Positions:     len(holdings),
+		Cash:          cash,
+		ActiveOptions: len(options),
+		Premiums:      premiums,
+		PremiumYear:   taxYear.Label(from),
+	}
+	for _, h := range holdings {
+		costBasis := toBase(h.Quantity.Mul(h.AvgCost), h.Currency)
+		sum.CostBasis = sum.CostBasis.Add(costBasis)
+
+		quote, ok := quotes[h.Ticker]
+		if !ok {
+			sum.HoldingsValue = sum.HoldingsValue.Add(costBasis)
+			sum.Unpriced++
+			continue
+		}
+		if _, ok := used[h.Ticker]; ok {
+			sum.ManualPriced++
+		}
+		price := decimal.NewFromFloat(quote.Price)
+		if cap, hasCap := callCaps[h.Ticker]; hasCap && price.GreaterThan(cap) {
+			price = cap
+		}
+		sum.HoldingsValue = sum.HoldingsValue.Add(toBase(h.Quantity.Mul(price), h.Currency))
+	}
+
+	sum.Total = sum.HoldingsValue.Add(cash)
+	sum.PL = sum.HoldingsValue.Sub(sum.CostBasis)
+	if !sum.CostBasis.IsZero() {
+		sum.PLPct = sum.PL.Div(sum.CostBasis).Mul(decimal.NewFromInt(100))
+	}
+	return sum, nil
+}
+
+// ToBase returns a conversion of amounts in a currency into the base
+// currency at the rates saved last. Amounts in a currency without a saved
+// rate are left as they are, as the TUI leaves them.
+func (s *Service) ToBase(ctx context.Context) func(amount decimal.Decimal, currency string) decimal.Decimal {
+	base, err := s.db.GetBaseCurrency(ctx)
+	if err != nil {
+		base = db.DefaultCurrency
+	}
+	fx, err := s.db.GetFXRates(ctx)
+	if err != nil || fx.Base != base {
+		fx.Rates = nil
+	}
+	return func(amount decimal.Decimal, currency string) decimal.Decimal {
+		if currency == "" {
+			currency = db.DefaultCurrency
+		}
+		if rate, ok := fx.Rates[currency]; ok && currency != base {
+			return amount.Mul(rate)
+		}
+		return amount
+	}
+}
+
+// WithManualPrices fills in a quote from the manual prices for each ticker
+// Yahoo returned no price for, so delisted or unlisted positions value at
+// that price rather than at cost. Yahoo's price always wins. It returns the
+// completed quotes and the manual prices it used.
+func WithManualPrices(quotes map[string]yahoo.Quote, manual map[string]db.ManualPrice) (map[string]yahoo.Quote, map[string]db.ManualPrice) {
+	used := make(map[string]db.ManualPrice)
+	if len(manual) == 0 {
+		return quotes, used
+	}
+	if quotes == nil {
+		quotes = make(map[string]yahoo.Quote)
+	}
+	for ticker, p := range manual {
+		if q, ok := quotes[ticker]; ok && q.Price > 0 {
+			continue
+		}
+		quotes[ticker] = yahoo.Quote{Symbol: ticker, Price: p.Price.InexactFloat64()}
+		used[ticker] = p
+	}
+	return quotes, used
+}
+
+// ShortCallCaps maps each ticker to its lowest active short call strike.
+func ShortCallCaps(options []db.Option) map[string]decimal.Decimal {
+	caps := make(map[string]decimal.Decimal)
+	for _, o := range options {
+		if o.Status != "ACTIVE" || o.OptionType != "CALL" || o.Action != "SELL" {
+			continue
+		}
+		if existing, ok := caps[o.Ticker]; !ok || o.Strike.LessThan(existing) {
+			caps[o.Ticker] = o.Strike
+		}
+	}
+	return caps
+}
+
+// ActiveOptions returns options with status ACTIVE, ordered by expiry.
+func (s *Service) ActiveOptions(ctx context.Context) ([]db.Option, error) {
+	all, err := s.db.GetActiveOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var active []db.Option
+	for _, o := range all {
+		if o.Status == "ACTIVE" {
+			active = append(active, o)
+		}
+	}
+	return active, nil
+}
+
+// CSPResult is the advisor output for one watchlist ticker.
+type CSPResult struct {
+	Ticker  string
+	Score   csp.SignalOutput
+	Strike  float64
+	Expiry  time.Time // Midnight UTC
+	DTE     int
+	Delta   float64
+	Premium float64  // Target put's mid, per share
+	OI      OIChange // Target put's open interest, with its change once tracked
+	Scanned time.Time
+}
+
+// VIX returns the current VIX level, or DefaultVIX if it cannot be fetched.
+func (s *Service) VIX() float64 {
+	q, err := s.yahoo.GetQuote("^VIX")
+	if err != nil || q == nil {
+		return DefaultVIX
+	}
+	return q.Price
+}
+
+// VIXHistory is the range of daily VIX closes the current level's
+// percentile is taken over.
+var VIXHistory = yahoo.History{Range: "5y", Interval: "1d"}
+
+// VIXCloses returns the daily VIX closes over VIXHistory, or nil if they
+// cannot be fetched.
+func (s *Service) VIXCloses() []float64 {
+	closes, err := s.yahoo.FetchCloseHistory("^VIX", VIXHistory)
+	if err != nil {
+		return nil
+	}
+	return closes
+}
+
+// IndicatorHistory returns the configured price history for indicators,
+// falling back to the default if the setting cannot be read.
+func (s *Service) IndicatorHistory(ctx context.Context) yahoo.History {
+	text, err := s.db.GetIndicatorHistory(ctx)
+	if err != nil {
+		return yahoo.DefaultHistory
+	}
+	h, err := yahoo.ParseHistory(text)
+	if err != nil {
+		return yahoo.DefaultHistory
+	}
+	return h
+}
+
+// Signals returns the CSP signals scans are scored with: the built-in ones,
+// VIX by percentile if so set, and the user-declared ones, skipping any whose
+// expression no longer parses.
+func (s *Service) Signals(ctx context.Context) []csp.Signal {
+	signals := csp.BuiltinSignals()
+	if on, err := s.db.GetVIXPercentileScoring(ctx); err == nil && on {
+		for i, signal := range signals {
+			if signal.Name() == csp.SignalVIX {
+				signals[i] = csp.PercentileVIXSignal()
+			}
+		}
+	}
+	stored, err := s.db.GetCSPSignals(ctx)
+	if err != nil {
+		return signals
+	}
+	for _, d := range stored {
+		signal, err := csp.NewExprSignal(d.Name, d.Weight, d.Expr)
+		if err != nil {
+			continue
+		}
+		signals = append(signals, signal)
+	}
+	return signals
+}
+
+// ScoreCSPTicker fetches the options chain and the price history h for
+// ticker and computes its CSP signals against the target contract, taken
+// from the expirations in cycle. vixCloses, which may be nil, place vix in
+// its history.
+func (s *Service) ScoreCSPTicker(ticker string, cycle csp.ExpiryCycle, vix float64, vixCloses []float64, h yahoo.History, signals []csp.Signal) (CSPResult, error) {
+	result := CSPResult{Ticker: ticker, Scanned: time.Now()}
+
+	optionsData, err := s.yahoo.FetchOptionsChain(ticker)
+	if err != nil {
+		return result, fmt.Errorf("options chain: %w", err)
+	}
+
+	// The first chain is the nearest expiry's, only days out for ETFs listing
+	// several a week, so the target expiry's chain is fetched when it is
+	// another
+	expiry := csp.SelectTargetExpiry(optionsData.ExpirationDates, cycle, time.Now())
+	if expiry == 0 {
+		return result, fmt.Errorf("no %s expiry %d-%d days out", strings.ToLower(string(cycle)), csp.MinTargetDTE, csp.MaxTargetDTE)
+	}
+	if !slices.ContainsFunc(optionsData.Puts, func(p csp.OptionContract) bool { return p.Expiration == expiry }) {
+		if optionsData, err = s.yahoo.FetchOptionsChainForExpiry(ticker, expiry); err != nil {
+			return result, fmt.Errorf("options chain for %s: %w", time.Unix(expiry, 0).UTC().Format(time.DateOnly), err)
+		}
+	}
+
+	priceHistory, err := s.yahoo.FetchCloseHistory(ticker, h)
+	if err != nil {
+		return result, fmt.Errorf("price history: %w", err)
+	}
+	if len(priceHistory) < yahoo.MinHistoryCloses {
+		return result, fmt.Errorf("price history: only %d closes", len(priceHistory))
+	}
+
+	targetContract := csp.SelectTargetPut(*optionsData, expiry)
+	if targetContract == nil {
+		return result, fmt.Errorf("no contract passes filters")
+	}
+
+	// IV range across the target expiry's puts near the money
+	currentIV := targetContract.ImpliedVolatility
+	ivLow52w := currentIV
+	ivHigh52w := currentIV
+	if low, high, ok := csp.PutIVRange(optionsData.Puts, expiry, optionsData.UnderlyingPrice); ok {
+		ivLow52w = min(ivLow52w, low)
+		ivHigh52w = max(ivHigh52w, high)
+	}
+
+	// Total put/call volume for P/C ratio
+	var totalPutVolume, totalCallVolume float64
+	for _, put := range optionsData.Puts {
+		totalPutVolume += float64(put.Volume)
+	}
+	for _, call := range optionsData.Calls {
+		totalCallVolume += float64(call.Volume)
+	}
+
+	expTime := time.Unix(targetContract.Expiration, 0)
+	dte := int(time.Until(expTime).Hours() / 24)
+	if dte < 0 {
+		dte = 0
+	}
+
+	mid := (targetContract.Bid + targetContract.Ask) / 2
+	result.Score = csp.ComputeSignalsWith(csp.SignalInput{
+		VIX:             vix,
+		VIXHistory:      vixCloses,
+		CurrentIV:       currentIV,
+		IVHigh52w:       ivHigh52w,
+		IVLow52w:        ivLow52w,
+		ClosingPrices:   priceHistory,
+		TotalPutVolume:  totalPutVolume,
+		TotalCallVolume: totalCallVolume,
+		PutPremium:      mid,
+		StrikePrice:     targetContract.Strike,
+		DTE:             dte,
+	}, signals)
+	result.Strike = targetContract.Strike
+	result.Expiry = expTime.UTC()
+	result.DTE = dte
+	result.OI = OIChange{OpenInterest: targetContract.OpenInterest}
+	result.Delta = targetContract.Delta
+	result.Premium = mid
+	return result, nil
+}
+
+// ScanCSP scores every watchlist ticker and tracks the open interest of
+// their target puts. Tickers that fail are omitted.
+func (s *Service) ScanCSP(ctx context.Context) ([]CSPResult, error) {
+	watchlist, err := s.db.GetCSPWatchlist(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vix := s.VIX()
+	vixCloses := s.VIXCloses()
+	history := s.IndicatorHistory(ctx)
+	signals := s.Signals(ctx)
+	var results []CSPResult
+	for _, item := range watchlist {
+		cycle, _ := csp.ParseExpiryCycle(item.Expiries)
+		r, err := s.ScoreCSPTicker(item.Ticker, cycle, vix, vixCloses, history, signals)
+		time.Sleep(ScanDelay)
+		if err != nil {
+			continue
+		}
+		results = append(results, r)
+	}
+
+	// Open interest is tracked when it can be; the scan stands without it
+	now := time.Now()
+	s.TrackCSPOpenInterest(ctx, results, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+	return results, nil
+}
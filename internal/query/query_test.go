@@ -0,0 +1,297 @@
+package query
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/csp"
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/fake"
+	"anyhowhodl/internal/yahoo"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSummaryCapsAtShortCallStrike(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+	s := New(store, market)
+
+	store.AddHolding(ctx, "AAPL", decimal.NewFromInt(100), decimal.NewFromInt(150), db.DefaultCurrency, time.Now(), decimal.NullDecimal{}, "")
+	store.AddHolding(ctx, "MSFT", decimal.NewFromInt(10), decimal.NewFromInt(300), db.DefaultCurrency, time.Now(), decimal.NullDecimal{}, "")
+	store.SetAvailableCash(ctx, decimal.NewFromInt(1000))
+	store.AddOption(ctx, "AAPL", "CALL", "SELL", decimal.NewFromInt(180), time.Now().AddDate(0, 1, 0), 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, decimal.Zero, decimal.Zero, "")
+	market.SetPrice("AAPL", 200) // capped at 180
+	// MSFT has no quote: valued at cost
+
+	sum, err := s.Summary(ctx)
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if !sum.HoldingsValue.Equal(decimal.NewFromInt(18000 + 3000)) {
+		t.Errorf("HoldingsValue = %s, want 21000", sum.HoldingsValue)
+	}
+	if !sum.Total.Equal(decimal.NewFromInt(22000)) {
+		t.Errorf("Total = %s, want 22000", sum.Total)
+	}
+	if sum.Positions != 2 || sum.ActiveOptions != 1 {
+		t.Errorf("Positions = %d, ActiveOptions = %d", sum.Positions, sum.ActiveOptions)
+	}
+}
+
+func TestAlerts(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+	s := New(store, market)
+
+	target := decimal.NullDecimal{Decimal: decimal.NewFromInt(110), Valid: true}
+	store.AddHolding(ctx, "KO", decimal.NewFromInt(10), decimal.NewFromInt(60), db.DefaultCurrency, time.Now(), target, "")
+	store.AddOption(ctx, "TSLA", "PUT", "SELL", decimal.NewFromInt(250), time.Now().AddDate(0, 0, 2), 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, decimal.NewFromInt(5), decimal.Zero, "")
+	market.SetPrice("KO", 115)
+	market.SetPrice("TSLA", 240)
+	options, _ := store.GetActiveOptions(ctx)
+	store.AddReminder(ctx, options[0].ID, time.Now().AddDate(0, 0, -1), "evaluate roll")
+	store.AddReminder(ctx, options[0].ID, time.Now().AddDate(0, 0, 1), "not yet")
+
+	alerts, err := s.Alerts(ctx)
+	if err != nil {
+		t.Fatalf("Alerts: %v", err)
+	}
+
+	var kinds []string
+	for _, a := range alerts {
+		kinds = append(kinds, strings.SplitN(a.Key, ":", 2)[0])
+	}
+	if got := strings.Join(kinds, ","); got != "target,expiry,itm,reminder" {
+		t.Errorf("alert kinds = %s, want target,expiry,itm,reminder", got)
+	}
+	if got, want := alerts[3].Message, "Reminder for TSLA SELL PUT $250.00: evaluate roll"; got != want {
+		t.Errorf("reminder message = %q, want %q", got, want)
+	}
+}
+
+func TestSummaryUsesManualPrices(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+	s := New(store, market)
+
+	store.AddHolding(ctx, "AAPL", decimal.NewFromInt(10), decimal.NewFromInt(150), db.DefaultCurrency, time.Now(), decimal.NullDecimal{}, "")
+	store.AddHolding(ctx, "GONE", decimal.NewFromInt(100), decimal.NewFromInt(20), db.DefaultCurrency, time.Now(), decimal.NullDecimal{}, "")
+	store.AddHolding(ctx, "PRIV", decimal.NewFromInt(5), decimal.NewFromInt(10), db.DefaultCurrency, time.Now(), decimal.NullDecimal{}, "")
+	store.SetManualPrice(ctx, "AAPL", decimal.NewFromInt(1)) // Yahoo's price wins
+	store.SetManualPrice(ctx, "GONE", decimal.NewFromInt(2))
+	market.SetPrice("AAPL", 200)
+	// PRIV has neither: valued at cost
+
+	sum, err := s.Summary(ctx)
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if !sum.HoldingsValue.Equal(decimal.NewFromInt(2000 + 200 + 50)) {
+		t.Errorf("HoldingsValue = %s, want 2250", sum.HoldingsValue)
+	}
+	if sum.ManualPriced != 1 || sum.Unpriced != 1 {
+		t.Errorf("ManualPriced = %d, Unpriced = %d; want 1 and 1", sum.ManualPriced, sum.Unpriced)
+	}
+}
+
+func TestQueuePolicyActions(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+	s := New(store, market)
+
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	store.AddOption(ctx, "AAPL", "PUT", "SELL", decimal.NewFromInt(200), time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC), 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, decimal.NewFromInt(4), decimal.Zero, "")
+	store.AddOption(ctx, "MSFT", "CALL", "SELL", decimal.NewFromInt(450), time.Date(2026, 4, 17, 0, 0, 0, 0, time.UTC), 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, decimal.NewFromInt(6), decimal.Zero, "")
+	options, _ := store.GetActiveOptions(ctx)
+	aapl, msft := options[0], options[1]
+
+	store.AddOptionPolicy(ctx, aapl.ID, analytics.PolicyRollAtDTE, decimal.NewFromInt(21), true)
+	store.AddOptionPolicy(ctx, aapl.ID, analytics.PolicyCloseAtProfit, decimal.NewFromInt(50), false)
+	store.AddOptionPolicy(ctx, msft.ID, analytics.PolicyCloseAtProfit, decimal.NewFromInt(50), false)
+
+	market.SetPrice("AAPL", 195) // ITM, 18 DTE: roll
+	market.SetPrice("MSFT", 430)
+	april := time.Date(2026, 4, 17, 0, 0, 0, 0, time.UTC).Unix()
+	market.Chains["AAPL"] = &csp.OptionsData{
+		Puts:            []csp.OptionContract{{Strike: 200, Bid: 6.9, Ask: 7.1}}, // Losing: no close
+		ExpirationDates: []int64{time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC).Unix(), april},
+	}
+	market.Chains["MSFT"] = &csp.OptionsData{Calls: []csp.OptionContract{{Strike: 450, Bid: 1.9, Ask: 2.1}}} // 67% captured
+
+	queued, err := s.QueuePolicyActions(ctx, now)
+	if err != nil {
+		t.Fatalf("QueuePolicyActions: %v", err)
+	}
+	if len(queued) != 2 {
+		t.Fatalf("queued %d actions, want the AAPL roll and MSFT close", len(queued))
+	}
+	roll, closeMSFT := queued[0], queued[1]
+	if roll.Action != db.PolicyActionRoll || roll.OptionID != aapl.ID || !roll.RollExpiry.Equal(time.Unix(april, 0).UTC()) {
+		t.Errorf("roll = %+v, want AAPL rolled to 2026-04-17", roll)
+	}
+	if closeMSFT.Action != db.PolicyActionClose || !closeMSFT.Price.Decimal.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("close = %+v, want MSFT at the $2 mark", closeMSFT)
+	}
+	if got := PolicyActionText(closeMSFT, msft); got != "Close MSFT SELL CALL $450.00 exp 04-17: 67% of premium captured (close at 50%) (mark $2.00)" {
+		t.Errorf("PolicyActionText = %q", got)
+	}
+
+	// Pending actions are not queued twice
+	if again, _ := s.QueuePolicyActions(ctx, now); len(again) != 0 {
+		t.Errorf("requeued %d pending actions", len(again))
+	}
+}
+
+func TestIVSurface(t *testing.T) {
+	market := fake.NewMarket()
+	s := New(fake.NewStore(), market)
+
+	chain := &csp.OptionsData{
+		UnderlyingPrice: 100,
+		ExpirationDates: []int64{1, 2, 3, 4, 5, 6, 7, 8},
+	}
+	for k := 60.0; k <= 140; k += 5 {
+		chain.Puts = append(chain.Puts, csp.OptionContract{Strike: k, ImpliedVolatility: 0.3})
+	}
+	chain.Calls = []csp.OptionContract{{Strike: 110, ImpliedVolatility: 0.25}}
+	market.Chains["AAPL"] = chain
+
+	surface, err := s.IVSurface("AAPL")
+	if err != nil {
+		t.Fatalf("IVSurface: %v", err)
+	}
+	if len(surface.Expiries) != SurfaceExpiries {
+		t.Errorf("expiries = %v, want the nearest %d", surface.Expiries, SurfaceExpiries)
+	}
+	// Within 20% of 100: 80 through 120
+	if n := len(surface.Strikes); n != 9 || surface.Strikes[0] != 80 || surface.Strikes[n-1] != 120 {
+		t.Fatalf("strikes = %v", surface.Strikes)
+	}
+	if c := surface.Puts[2][SurfaceExpiries-1]; c == nil || c.Strike != 90 {
+		t.Errorf("90 put in the last expiry = %+v", c)
+	}
+	if surface.Calls[2][0] != nil || surface.Calls[6][0] == nil || surface.Calls[6][0].ImpliedVolatility != 0.25 {
+		t.Error("calls listed at the wrong strikes")
+	}
+
+	if _, err := s.IVSurface("MSFT"); err == nil {
+		t.Error("no error for a ticker without a chain")
+	}
+}
+
+func TestTrackOpenInterest(t *testing.T) {
+	ctx := context.Background()
+	s := New(fake.NewStore(), fake.NewMarket())
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	put := func(strike float64, on time.Time, oi int) db.OpenInterest {
+		return db.OpenInterest{Ticker: "AAPL", OptionType: "PUT", Expiry: day(20), Strike: strike, Day: on, OpenInterest: oi}
+	}
+
+	// Friday's first snapshot has nothing to compare with
+	changes, err := s.TrackOpenInterest(ctx, []db.OpenInterest{put(200, day(6), 1200)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes[0] != (OIChange{OpenInterest: 1200}) {
+		t.Errorf("first snapshot = %+v, want no change", changes[0])
+	}
+
+	// Monday compares with Friday; a strike first seen Monday has no change
+	changes, _ = s.TrackOpenInterest(ctx, []db.OpenInterest{put(200, day(9), 1450), put(195, day(9), 300)})
+	if want := (OIChange{OpenInterest: 1450, Change: 250, HasChange: true}); changes[0] != want {
+		t.Errorf("200 put = %+v, want %+v", changes[0], want)
+	}
+	if changes[1].HasChange {
+		t.Errorf("195 put = %+v, want no change", changes[1])
+	}
+
+	// A second refresh the same day replaces Monday's snapshot and still
+	// compares with Friday
+	changes, _ = s.TrackOpenInterest(ctx, []db.OpenInterest{put(200, day(9), 1100)})
+	if want := (OIChange{OpenInterest: 1100, Change: -100, HasChange: true}); changes[0] != want {
+		t.Errorf("200 put refreshed = %+v, want %+v", changes[0], want)
+	}
+}
+
+func TestEndOfDay(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	market := fake.NewMarket()
+	s := New(store, market)
+
+	now := time.Now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	next := nextWeekday(day)
+	store.AddHolding(ctx, "KO", decimal.NewFromInt(100), decimal.NewFromInt(60), db.DefaultCurrency, day, decimal.NullDecimal{}, "")
+	store.AddOption(ctx, "KO", "CALL", "SELL", decimal.NewFromInt(70), next, 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, decimal.NewFromInt(2), decimal.Zero, "")
+	store.AddOption(ctx, "PEP", "PUT", "SELL", decimal.NewFromInt(150), day.AddDate(0, 1, 0), 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, decimal.NewFromInt(3), decimal.Zero, "")
+	market.Quotes["KO"] = yahoo.Quote{Symbol: "KO", Price: 65, Change: -0.5}
+	market.SetPrice("PEP", 160)
+	market.Chains["KO"] = &csp.OptionsData{Calls: []csp.OptionContract{{Strike: 70, Bid: 0.4, Ask: 0.6}}}
+	market.Earnings["PEP"] = &yahoo.EarningsDate{Date: next.Add(8 * time.Hour)}
+
+	e, err := s.EndOfDay(ctx, now)
+	if err != nil {
+		t.Fatalf("EndOfDay: %v", err)
+	}
+	if !e.Change.Equal(decimal.NewFromInt(-50)) {
+		t.Errorf("Change = %s, want -50", e.Change)
+	}
+	if len(e.Marks) != 2 || e.Marks[0].Mark != 0.5 || !e.Marks[0].PL.Equal(decimal.NewFromInt(150)) || e.Marks[1].Mark != 0 {
+		t.Errorf("Marks = %+v, want KO at 0.50 up $150 and PEP unmarked", e.Marks)
+	}
+	if len(e.Expiring) != 1 || e.Expiring[0].Ticker != "KO" {
+		t.Errorf("Expiring = %+v, want the KO call", e.Expiring)
+	}
+	if len(e.Earnings) != 1 || e.Earnings[0] != "PEP" {
+		t.Errorf("Earnings = %v, want PEP", e.Earnings)
+	}
+	text := e.Text()
+	for _, want := range []string{"today -$50.00", "KO SELL CALL $70.00", "mark $0.50, +$150.00", "PEP SELL PUT $150.00", "no mark", "PEP reports earnings"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestReviewLogs(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewStore()
+	s := New(store, fake.NewMarket())
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	// An alert is logged once, when it first fires
+	itm := Alert{Kind: "itm", Key: "itm:o1", Message: "AAPL put ITM"}
+	s.LogAlerts(ctx, []Alert{itm}, day(2))
+	s.LogAlerts(ctx, []Alert{itm, {Kind: "expiry", Key: "expiry:o2", Message: "MSFT call expires"}}, day(3))
+	log, _ := store.GetAlertLog(ctx)
+	if len(log) != 2 || !log[0].FiredAt.Equal(day(2)) || log[1].Key != "expiry:o2" {
+		t.Errorf("alert log = %+v", log)
+	}
+	// Old entries are dropped, so the alert can be logged again
+	s.LogAlerts(ctx, []Alert{itm}, day(3).AddDate(0, 0, ReviewLogDays+1))
+	log, _ = store.GetAlertLog(ctx)
+	if len(log) != 1 || log[0].Key != "itm:o1" || log[0].FiredAt.Equal(day(2)) {
+		t.Errorf("alert log after expiry = %+v", log)
+	}
+
+	// A second scan the same day replaces that day's scores
+	scored := func(ticker string, score float64) CSPResult {
+		return CSPResult{Ticker: ticker, Score: csp.SignalOutput{CompositeScore: score}}
+	}
+	s.RecordScores(ctx, []CSPResult{scored("AMD", 55)}, day(6))
+	s.RecordScores(ctx, []CSPResult{scored("AMD", 60)}, day(9))
+	s.RecordScores(ctx, []CSPResult{scored("AMD", 72), scored("SPY", 40)}, day(9))
+	history, _ := store.GetScoreHistory(ctx)
+	if len(history) != 2 || history[0].Scores["AMD"] != 55 || history[1].Scores["AMD"] != 72 || history[1].Scores["SPY"] != 40 {
+		t.Errorf("score history = %+v", history)
+	}
+}
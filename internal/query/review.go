@@ -0,0 +1,61 @@
+package query
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"anyhowhodl/internal/db"
+)
+
+// ReviewLogDays is how long fired alerts and daily watchlist scores are kept
+// for the weekly review: a week under review and the one before it, with room
+// to look back a few weeks.
+const ReviewLogDays = 35
+
+// LogAlerts adds the alerts not already in the alert log as fired at now, and
+// drops entries older than ReviewLogDays.
+func (s *Service) LogAlerts(ctx context.Context, alerts []Alert, now time.Time) error {
+	log, err := s.db.GetAlertLog(ctx)
+	if err != nil {
+		return err
+	}
+	cutoff := now.AddDate(0, 0, -ReviewLogDays)
+	log = slices.DeleteFunc(log, func(l db.LoggedAlert) bool { return l.FiredAt.Before(cutoff) })
+	added := false
+	for _, al := range alerts {
+		if slices.ContainsFunc(log, func(l db.LoggedAlert) bool { return l.Key == al.Key }) {
+			continue
+		}
+		log = append(log, db.LoggedAlert{Key: al.Key, Kind: al.Kind, Message: al.Message, FiredAt: now})
+		added = true
+	}
+	if !added {
+		return nil
+	}
+	return s.db.SetAlertLog(ctx, log)
+}
+
+// RecordScores stores the results' composite scores as day's snapshot,
+// replacing one already taken that day, and drops snapshots older than
+// ReviewLogDays.
+func (s *Service) RecordScores(ctx context.Context, results []CSPResult, day time.Time) error {
+	if len(results) == 0 {
+		return nil
+	}
+	history, err := s.db.GetScoreHistory(ctx)
+	if err != nil {
+		return err
+	}
+	cutoff := day.AddDate(0, 0, -ReviewLogDays)
+	history = slices.DeleteFunc(history, func(h db.ScoreSnapshot) bool {
+		return h.Day.Before(cutoff) || h.Day.Equal(day)
+	})
+	scores := make(map[string]float64, len(results))
+	for _, r := range results {
+		scores[r.Ticker] = r.Score.CompositeScore
+	}
+	history = append(history, db.ScoreSnapshot{Day: day, Scores: scores})
+	slices.SortFunc(history, func(a, b db.ScoreSnapshot) int { return a.Day.Compare(b.Day) })
+	return s.db.SetScoreHistory(ctx, history)
+}
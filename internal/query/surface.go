@@ -0,0 +1,111 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"anyhowhodl/internal/csp"
+)
+
+// Volatility surface bounds: how many expiries are fetched and which strikes
+// are kept around the underlying price.
+const (
+	SurfaceExpiries    = 6
+	SurfaceStrikeRange = 0.20 // Fraction of the underlying either side
+	SurfaceMaxStrikes  = 25
+)
+
+// IVSurface is a snapshot of a ticker's option chains over several expiries,
+// indexed [strike][expiry]. Contracts not listed at a strike are nil.
+type IVSurface struct {
+	Ticker     string
+	Underlying float64
+	Expiries   []int64 // Unix timestamps, nearest first
+	Strikes    []float64
+	Puts       [][]*csp.OptionContract
+	Calls      [][]*csp.OptionContract
+}
+
+// IVSurface fetches the nearest SurfaceExpiries chains for ticker and lays
+// out the strikes listed within SurfaceStrikeRange of the underlying, at most
+// SurfaceMaxStrikes of them closest to it. Expiries whose chain fails to load
+// are left out.
+func (s *Service) IVSurface(ticker string) (*IVSurface, error) {
+	first, err := s.yahoo.FetchOptionsChain(ticker)
+	if err != nil {
+		return nil, err
+	}
+	if first.UnderlyingPrice <= 0 || len(first.ExpirationDates) == 0 {
+		return nil, fmt.Errorf("no options listed for %s", ticker)
+	}
+
+	expiries := first.ExpirationDates
+	if len(expiries) > SurfaceExpiries {
+		expiries = expiries[:SurfaceExpiries]
+	}
+	surface := &IVSurface{Ticker: ticker, Underlying: first.UnderlyingPrice}
+	var chains []*csp.OptionsData
+	for i, expiry := range expiries {
+		chain := first
+		if i > 0 {
+			if chain, err = s.yahoo.FetchOptionsChainForExpiry(ticker, expiry); err != nil {
+				continue
+			}
+		}
+		surface.Expiries = append(surface.Expiries, expiry)
+		chains = append(chains, chain)
+	}
+
+	surface.Strikes = surfaceStrikes(chains, surface.Underlying)
+	row := make(map[float64]int, len(surface.Strikes))
+	for i, k := range surface.Strikes {
+		row[k] = i
+	}
+	surface.Puts = make([][]*csp.OptionContract, len(surface.Strikes))
+	surface.Calls = make([][]*csp.OptionContract, len(surface.Strikes))
+	for i := range surface.Strikes {
+		surface.Puts[i] = make([]*csp.OptionContract, len(chains))
+		surface.Calls[i] = make([]*csp.OptionContract, len(chains))
+	}
+	for j, chain := range chains {
+		for _, c := range chain.Puts {
+			if i, ok := row[c.Strike]; ok {
+				surface.Puts[i][j] = &c
+			}
+		}
+		for _, c := range chain.Calls {
+			if i, ok := row[c.Strike]; ok {
+				surface.Calls[i][j] = &c
+			}
+		}
+	}
+	return surface, nil
+}
+
+// surfaceStrikes collects the strikes listed in any chain within range of
+// the underlying, keeps the closest SurfaceMaxStrikes, and sorts them
+func surfaceStrikes(chains []*csp.OptionsData, underlying float64) []float64 {
+	seen := make(map[float64]bool)
+	var strikes []float64
+	for _, chain := range chains {
+		for _, contracts := range [][]csp.OptionContract{chain.Puts, chain.Calls} {
+			for _, c := range contracts {
+				if seen[c.Strike] || math.Abs(c.Strike-underlying) > underlying*SurfaceStrikeRange {
+					continue
+				}
+				seen[c.Strike] = true
+				strikes = append(strikes, c.Strike)
+			}
+		}
+	}
+
+	if len(strikes) > SurfaceMaxStrikes {
+		sort.Slice(strikes, func(i, j int) bool {
+			return math.Abs(strikes[i]-underlying) < math.Abs(strikes[j]-underlying)
+		})
+		strikes = strikes[:SurfaceMaxStrikes]
+	}
+	sort.Float64s(strikes)
+	return strikes
+}
@@ -0,0 +1,134 @@
+// Package telegram is a minimal Telegram Bot API client (long polling and sendMessage).
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultBaseURL = "https://api.telegram.org"
+
+// PollTimeout is the long-poll duration passed to getUpdates.
+const PollTimeout = 30 * time.Second
+
+type Client struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewClient(token string) *Client {
+	return &Client{
+		token:   token,
+		baseURL: defaultBaseURL,
+		httpClient: &http.Client{
+			// Must exceed the long-poll timeout
+			Timeout: PollTimeout + 10*time.Second,
+		},
+	}
+}
+
+// Update is an incoming bot update. Only text messages are decoded.
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message"`
+}
+
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text"`
+}
+
+type Chat struct {
+	ID int64 `json:"id"`
+}
+
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	Description string          `json:"description"`
+}
+
+// GetUpdates long-polls for updates with IDs >= offset.
+func (c *Client) GetUpdates(ctx context.Context, offset int64) ([]Update, error) {
+	params := url.Values{}
+	params.Set("offset", strconv.FormatInt(offset, 10))
+	params.Set("timeout", strconv.Itoa(int(PollTimeout.Seconds())))
+	params.Set("allowed_updates", `["message"]`)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.methodURL("getUpdates")+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []Update
+	if err := c.do(req, &updates); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// SendMessage sends plain text to a chat.
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string) error {
+	body, err := json.Marshal(map[string]any{
+		"chat_id": chatID,
+		"text":    text,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.methodURL("sendMessage"), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return c.do(req, nil)
+}
+
+func (c *Client) methodURL(method string) string {
+	return fmt.Sprintf("%s/bot%s/%s", c.baseURL, c.token, method)
+}
+
+func (c *Client) do(req *http.Request, result any) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("decoding response (status %d): %w", resp.StatusCode, err)
+	}
+	if !apiResp.OK {
+		return fmt.Errorf("telegram: %s", apiResp.Description)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(apiResp.Result, result)
+}
+
+// ParseCommand splits "/cmd@botname arg1 arg2" into "cmd" and its args.
+// Returns ok=false for text that is not a command.
+func ParseCommand(text string) (cmd string, args []string, ok bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", nil, false
+	}
+	cmd = strings.TrimPrefix(fields[0], "/")
+	if at := strings.Index(cmd, "@"); at >= 0 {
+		cmd = cmd[:at]
+	}
+	return strings.ToLower(cmd), fields[1:], cmd != ""
+}
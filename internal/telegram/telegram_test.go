@@ -0,0 +1,100 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	c := NewClient("TOKEN")
+	c.baseURL = server.URL
+	return c
+}
+
+func TestGetUpdates(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botTOKEN/getUpdates" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("offset"); got != "42" {
+			t.Errorf("offset = %s, want 42", got)
+		}
+		io.WriteString(w, `{"ok":true,"result":[{"update_id":42,"message":{"message_id":1,"chat":{"id":99},"text":"/summary"}}]}`)
+	})
+
+	updates, err := c.GetUpdates(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("GetUpdates: %v", err)
+	}
+	if len(updates) != 1 {
+		t.Fatalf("got %d updates, want 1", len(updates))
+	}
+	u := updates[0]
+	if u.UpdateID != 42 || u.Message == nil || u.Message.Chat.ID != 99 || u.Message.Text != "/summary" {
+		t.Errorf("unexpected update: %+v", u)
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/botTOKEN/sendMessage" {
+			t.Errorf("%s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			ChatID int64  `json:"chat_id"`
+			Text   string `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if body.ChatID != 99 || body.Text != "hello" {
+			t.Errorf("body = %+v", body)
+		}
+		io.WriteString(w, `{"ok":true,"result":{}}`)
+	})
+
+	if err := c.SendMessage(context.Background(), 99, "hello"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		io.WriteString(w, `{"ok":false,"error_code":401,"description":"Unauthorized"}`)
+	})
+
+	err := c.SendMessage(context.Background(), 1, "x")
+	if err == nil || err.Error() != "telegram: Unauthorized" {
+		t.Errorf("err = %v, want telegram: Unauthorized", err)
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		text string
+		cmd  string
+		args int
+		ok   bool
+	}{
+		{"/summary", "summary", 0, true},
+		{"/CSP@anyhowhodl_bot", "csp", 0, true},
+		{"/options AAPL", "options", 1, true},
+		{"hello", "", 0, false},
+		{"", "", 0, false},
+		{"/", "", 0, false},
+	}
+	for _, tt := range tests {
+		cmd, args, ok := ParseCommand(tt.text)
+		if cmd != tt.cmd || len(args) != tt.args || ok != tt.ok {
+			t.Errorf("ParseCommand(%q) = %q, %v, %v", tt.text, cmd, args, ok)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Package webhook receives trade fills and alerts over HTTP.
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxBodyBytes bounds request bodies; fills and alerts are small.
+const maxBodyBytes = 64 << 10
+
+// SecretHeader carries the shared secret. Senders that cannot set headers
+// (TradingView) may pass it as a "secret" query parameter or JSON field instead.
+const SecretHeader = "X-Webhook-Secret"
+
+// Trade is a fill reported by a broker notification relay.
+type Trade struct {
+	Kind       string          `json:"kind"` // STOCK or OPTION
+	Ticker     string          `json:"ticker"`
+	Action     string          `json:"action"` // BUY or SELL
+	Quantity   decimal.Decimal `json:"quantity"`
+	Price      decimal.Decimal `json:"price"` // Per share; premium per share for options
+	Fee        decimal.Decimal `json:"fee"`
+	OptionType string          `json:"option_type"` // CALL or PUT, options only
+	Strike     decimal.Decimal `json:"strike"`
+	Expiry     string          `json:"expiry"`   // YYYY-MM-DD, options only
+	Currency   string          `json:"currency"` // ISO code; optional, the held ticker's currency by default
+	FillID     string          `json:"fill_id"`  // Broker's execution ID; optional, a fill already recorded with it is skipped
+	Notes      string          `json:"notes"`
+	Secret     string          `json:"secret"`
+}
+
+// ExpiryDate parses Expiry.
+func (t Trade) ExpiryDate() (time.Time, error) {
+	return time.Parse("2006-01-02", t.Expiry)
+}
+
+// Validate normalizes case and checks required fields.
+func (t *Trade) Validate() error {
+	t.Kind = strings.ToUpper(strings.TrimSpace(t.Kind))
+	t.Ticker = strings.ToUpper(strings.TrimSpace(t.Ticker))
+	t.Action = strings.ToUpper(strings.TrimSpace(t.Action))
+	t.OptionType = strings.ToUpper(strings.TrimSpace(t.OptionType))
+	t.Currency = strings.ToUpper(strings.TrimSpace(t.Currency))
+	t.FillID = strings.TrimSpace(t.FillID)
+
+	if t.Ticker == "" {
+		return errors.New("ticker is required")
+	}
+	if t.Action != "BUY" && t.Action != "SELL" {
+		return errors.New("action must be BUY or SELL")
+	}
+	if !t.Quantity.IsPositive() {
+		return errors.New("quantity must be positive")
+	}
+	if t.Price.IsNegative() || t.Fee.IsNegative() {
+		return errors.New("price and fee cannot be negative")
+	}
+	if t.Currency != "" && len(t.Currency) != 3 {
+		return errors.New("currency must be a three-letter code")
+	}
+
+	switch t.Kind {
+	case "STOCK":
+		if !t.Price.IsPositive() {
+			return errors.New("price is required for stock trades")
+		}
+	case "OPTION":
+		if t.OptionType != "CALL" && t.OptionType != "PUT" {
+			return errors.New("option_type must be CALL or PUT")
+		}
+		if !t.Strike.IsPositive() {
+			return errors.New("strike is required for option trades")
+		}
+		if !t.Quantity.IsInteger() {
+			return errors.New("option quantity must be whole contracts")
+		}
+		if _, err := t.ExpiryDate(); err != nil {
+			return errors.New("expiry must be YYYY-MM-DD")
+		}
+	default:
+		return errors.New("kind must be STOCK or OPTION")
+	}
+	return nil
+}
+
+// ErrDuplicateFill is returned by OnTrade for a fill already recorded, as
+// when a broker retries the POST. The sender is told it succeeded.
+var ErrDuplicateFill = errors.New("fill already recorded")
+
+// Alert is a free-form notification, e.g. from a TradingView alert.
+type Alert struct {
+	Ticker  string `json:"ticker"`
+	Message string `json:"message"`
+	Secret  string `json:"secret"`
+}
+
+// Handler serves POST /webhook/trade and POST /webhook/alert.
+type Handler struct {
+	Secret  string
+	OnTrade func(ctx context.Context, t Trade) error
+	OnAlert func(ctx context.Context, a Alert) error
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes))
+	if err != nil {
+		http.Error(w, "reading body", http.StatusBadRequest)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/webhook/trade":
+		var t Trade
+		if err := json.Unmarshal(body, &t); err != nil {
+			http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !h.authorized(r, t.Secret) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if err := t.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		err := h.OnTrade(r.Context(), t)
+		if errors.Is(err, ErrDuplicateFill) {
+			fmt.Fprintf(w, "already recorded %s\n", t.FillID)
+			return
+		}
+		if err != nil {
+			log.Printf("webhook: trade %s %s: %v", t.Action, t.Ticker, err)
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		fmt.Fprintf(w, "recorded %s %s %s\n", t.Action, t.Quantity, t.Ticker)
+
+	case "/webhook/alert":
+		a := parseAlert(body)
+		if !h.authorized(r, a.Secret) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if a.Message == "" {
+			http.Error(w, "message is required", http.StatusUnprocessableEntity)
+			return
+		}
+		if err := h.OnAlert(r.Context(), a); err != nil {
+			log.Printf("webhook: alert: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		io.WriteString(w, "ok\n")
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// parseAlert accepts a JSON alert or, as TradingView sends by default, plain text.
+func parseAlert(body []byte) Alert {
+	var a Alert
+	if err := json.Unmarshal(body, &a); err != nil {
+		return Alert{Message: strings.TrimSpace(string(body))}
+	}
+	a.Ticker = strings.ToUpper(strings.TrimSpace(a.Ticker))
+	a.Message = strings.TrimSpace(a.Message)
+	return a
+}
+
+func (h *Handler) authorized(r *http.Request, bodySecret string) bool {
+	got := r.Header.Get(SecretHeader)
+	if got == "" {
+		got = r.URL.Query().Get("secret")
+	}
+	if got == "" {
+		got = bodySecret
+	}
+	return h.Secret != "" && subtle.ConstantTimeCompare([]byte(got), []byte(h.Secret)) == 1
+}
@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestHandler() (*Handler, *[]Trade, *[]Alert) {
+	var trades []Trade
+	var alerts []Alert
+	h := &Handler{
+		Secret: "s3cret",
+		OnTrade: func(ctx context.Context, t Trade) error {
+			trades = append(trades, t)
+			return nil
+		},
+		OnAlert: func(ctx context.Context, a Alert) error {
+			alerts = append(alerts, a)
+			return nil
+		},
+	}
+	return h, &trades, &alerts
+}
+
+func post(h http.Handler, target, body string, header bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", target, strings.NewReader(body))
+	if header {
+		req.Header.Set(SecretHeader, "s3cret")
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestTradeWebhook(t *testing.T) {
+	h, trades, _ := newTestHandler()
+
+	body := `{"kind":"option","ticker":"aapl","action":"sell","quantity":2,"price":"1.25","fee":"1.30","option_type":"put","strike":200,"expiry":"2026-11-20"}`
+	rec := post(h, "/webhook/trade", body, true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	if len(*trades) != 1 {
+		t.Fatalf("got %d trades", len(*trades))
+	}
+	tr := (*trades)[0]
+	if tr.Kind != "OPTION" || tr.Ticker != "AAPL" || tr.Action != "SELL" || tr.OptionType != "PUT" || tr.Price.String() != "1.25" {
+		t.Errorf("trade = %+v", tr)
+	}
+}
+
+func TestTradeWebhookDuplicateFill(t *testing.T) {
+	h, _, _ := newTestHandler()
+	h.OnTrade = func(ctx context.Context, t Trade) error { return ErrDuplicateFill }
+
+	body := `{"kind":"STOCK","ticker":"AAPL","action":"BUY","quantity":1,"price":1,"fill_id":" 0001f4e8.6601 "}`
+	rec := post(h, "/webhook/trade", body, true)
+	// The retry succeeds, so the broker stops sending it
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "already recorded 0001f4e8.6601") {
+		t.Errorf("duplicate fill: status = %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestTradeWebhookRejects(t *testing.T) {
+	h, trades, _ := newTestHandler()
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		header bool
+		status int
+	}{
+		{"no secret", "/webhook/trade", `{"kind":"STOCK","ticker":"AAPL","action":"BUY","quantity":1,"price":1}`, false, http.StatusUnauthorized},
+		{"wrong secret", "/webhook/trade?secret=nope", `{"kind":"STOCK","ticker":"AAPL","action":"BUY","quantity":1,"price":1}`, false, http.StatusUnauthorized},
+		{"bad json", "/webhook/trade", `{`, true, http.StatusBadRequest},
+		{"missing price", "/webhook/trade", `{"kind":"STOCK","ticker":"AAPL","action":"BUY","quantity":1}`, true, http.StatusUnprocessableEntity},
+		{"fractional contracts", "/webhook/trade", `{"kind":"OPTION","ticker":"AAPL","action":"SELL","quantity":1.5,"price":1,"option_type":"PUT","strike":100,"expiry":"2026-11-20"}`, true, http.StatusUnprocessableEntity},
+		{"unknown path", "/webhook/other", `{}`, true, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if rec := post(h, tt.target, tt.body, tt.header); rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.status)
+		}
+	}
+	if len(*trades) != 0 {
+		t.Errorf("rejected requests recorded %d trades", len(*trades))
+	}
+}
+
+func TestAlertWebhook(t *testing.T) {
+	h, _, alerts := newTestHandler()
+
+	// TradingView sends the alert message as plain text; secret via query string
+	if rec := post(h, "/webhook/alert?secret=s3cret", "SPY crossed 500", false); rec.Code != http.StatusOK {
+		t.Fatalf("plain text: status = %d: %s", rec.Code, rec.Body)
+	}
+	if rec := post(h, "/webhook/alert", `{"ticker":"qqq","message":"QQQ RSI < 30","secret":"s3cret"}`, false); rec.Code != http.StatusOK {
+		t.Fatalf("json: status = %d: %s", rec.Code, rec.Body)
+	}
+
+	if len(*alerts) != 2 {
+		t.Fatalf("got %d alerts", len(*alerts))
+	}
+	if (*alerts)[0].Message != "SPY crossed 500" || (*alerts)[1].Ticker != "QQQ" {
+		t.Errorf("alerts = %+v", *alerts)
+	}
+}
+
+func TestGetNotAllowed(t *testing.T) {
+	h, _, _ := newTestHandler()
+	req := httptest.NewRequest("GET", "/webhook/alert", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d", rec.Code)
+	}
+}
@@ -0,0 +1,113 @@
+package yahoo
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"anyhowhodl/internal/csp"
+)
+
+// DefaultChainTTL is how long a fetched options chain is reused unless
+// configured otherwise
+const DefaultChainTTL = 5 * time.Minute
+
+// chainKey identifies a chain by ticker and expiry, 0 for the nearest
+type chainKey struct {
+	ticker string
+	expiry int64
+}
+
+type cachedChain struct {
+	chain   *csp.OptionsData
+	fetched time.Time
+}
+
+// ChainCache is a Provider that reuses the options chains it fetched for a
+// TTL, so the roll suggestions, IV surface, and CSP advisor share one
+// download of each (ticker, expiry) in a refresh. Failed fetches are not
+// cached. Chains are shared and must not be modified.
+type ChainCache struct {
+	Provider
+
+	mu     sync.Mutex
+	ttl    time.Duration
+	chains map[chainKey]cachedChain
+	now    func() time.Time
+}
+
+// NewChainCache wraps p with a chain cache; a ttl of 0 disables it.
+func NewChainCache(p Provider, ttl time.Duration) *ChainCache {
+	return &ChainCache{Provider: p, ttl: ttl, chains: make(map[chainKey]cachedChain), now: time.Now}
+}
+
+// SetTTL changes how long chains are reused, dropping the cached ones when
+// caching is turned off
+func (c *ChainCache) SetTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = ttl
+	if ttl <= 0 {
+		clear(c.chains)
+	}
+}
+
+// FetchOptionsChain returns the nearest expiry's chain, cached under that
+// expiry too so a later fetch of it by date is served from the cache.
+func (c *ChainCache) FetchOptionsChain(ticker string) (*csp.OptionsData, error) {
+	if chain, ok := c.cached(chainKey{ticker: ticker}); ok {
+		return chain, nil
+	}
+	chain, err := c.Provider.FetchOptionsChain(ticker)
+	if err != nil {
+		return nil, err
+	}
+	c.store(chainKey{ticker: ticker}, chain)
+	if len(chain.ExpirationDates) > 0 {
+		c.store(chainKey{ticker: ticker, expiry: chain.ExpirationDates[0]}, chain)
+	}
+	return chain, nil
+}
+
+// FetchOptionsChainForExpiry returns the chain for expiry.
+func (c *ChainCache) FetchOptionsChainForExpiry(ticker string, expiry int64) (*csp.OptionsData, error) {
+	key := chainKey{ticker: ticker, expiry: expiry}
+	if chain, ok := c.cached(key); ok {
+		return chain, nil
+	}
+	chain, err := c.Provider.FetchOptionsChainForExpiry(ticker, expiry)
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, chain)
+	return chain, nil
+}
+
+func (c *ChainCache) cached(key chainKey) (*csp.OptionsData, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.chains[key]
+	if !ok {
+		return nil, false
+	}
+	if c.now().Sub(entry.fetched) >= c.ttl {
+		delete(c.chains, key)
+		return nil, false
+	}
+	return entry.chain, true
+}
+
+// store caches chain under key, first dropping the expired chains so the
+// daemon's cache does not grow with expiries that are never fetched again
+func (c *ChainCache) store(key chainKey, chain *csp.OptionsData) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ttl <= 0 {
+		return
+	}
+	now := c.now()
+	maps.DeleteFunc(c.chains, func(_ chainKey, entry cachedChain) bool {
+		return now.Sub(entry.fetched) >= c.ttl
+	})
+	c.chains[key] = cachedChain{chain: chain, fetched: now}
+}
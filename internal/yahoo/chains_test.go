@@ -0,0 +1,61 @@
+package yahoo
+
+import (
+	"testing"
+	"time"
+
+	"anyhowhodl/internal/csp"
+)
+
+// chainCounter serves one chain per call and counts the calls
+type chainCounter struct {
+	Provider
+	calls int
+}
+
+func (p *chainCounter) FetchOptionsChain(ticker string) (*csp.OptionsData, error) {
+	return p.FetchOptionsChainForExpiry(ticker, 0)
+}
+
+func (p *chainCounter) FetchOptionsChainForExpiry(ticker string, expiry int64) (*csp.OptionsData, error) {
+	p.calls++
+	return &csp.OptionsData{ExpirationDates: []int64{100, 200}}, nil
+}
+
+func TestChainCache(t *testing.T) {
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	p := &chainCounter{}
+	cache := NewChainCache(p, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	// The nearest chain also serves its expiry by date
+	cache.FetchOptionsChain("SPY")
+	cache.FetchOptionsChainForExpiry("SPY", 100)
+	cache.FetchOptionsChain("SPY")
+	if p.calls != 1 {
+		t.Errorf("calls = %d after fetching the nearest chain three ways, want 1", p.calls)
+	}
+	cache.FetchOptionsChainForExpiry("SPY", 200)
+	cache.FetchOptionsChainForExpiry("QQQ", 100)
+	if p.calls != 3 {
+		t.Errorf("calls = %d, want other expiries and tickers fetched", p.calls)
+	}
+
+	now = now.Add(time.Minute)
+	cache.FetchOptionsChainForExpiry("SPY", 200)
+	if p.calls != 4 {
+		t.Errorf("calls = %d, want an expired chain fetched again", p.calls)
+	}
+
+	// Expired chains are dropped rather than kept until fetched again
+	if len(cache.chains) != 1 {
+		t.Errorf("%d chains cached, want only the refetched one", len(cache.chains))
+	}
+
+	cache.SetTTL(0)
+	cache.FetchOptionsChainForExpiry("SPY", 200)
+	cache.FetchOptionsChainForExpiry("SPY", 200)
+	if p.calls != 6 {
+		t.Errorf("calls = %d, want every fetch to go out with caching off", p.calls)
+	}
+}
@@ -0,0 +1,80 @@
+package yahoo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+)
+
+// dividendResponse maps the /v8/finance/chart/ JSON response with events=div.
+type dividendResponse struct {
+	Chart struct {
+		Result []struct {
+			Events struct {
+				Dividends map[string]struct {
+					Amount float64 `json:"amount"`
+					Date   int64   `json:"date"`
+				} `json:"dividends"`
+			} `json:"events"`
+		} `json:"result"`
+		Error *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"chart"`
+}
+
+// FetchDividends fetches the last 2 years of dividend distributions for a ticker.
+func (c *Client) FetchDividends(ticker string) ([]analytics.Dividend, error) {
+	time.Sleep(200 * time.Millisecond)
+
+	url := fmt.Sprintf("https://query2.finance.yahoo.com/v8/finance/chart/%s?range=2y&interval=1mo&events=div", ticker)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("yahoo chart API returned status %d", resp.StatusCode)
+	}
+
+	var dr dividendResponse
+	if err := json.NewDecoder(resp.Body).Decode(&dr); err != nil {
+		return nil, err
+	}
+
+	return parseDividendResponse(&dr)
+}
+
+func parseDividendResponse(dr *dividendResponse) ([]analytics.Dividend, error) {
+	if dr.Chart.Error != nil {
+		return nil, fmt.Errorf("yahoo chart error: %s", dr.Chart.Error.Description)
+	}
+	if len(dr.Chart.Result) == 0 {
+		return nil, fmt.Errorf("no chart data in response")
+	}
+
+	// Non-payers simply have no dividends map
+	var divs []analytics.Dividend
+	for _, d := range dr.Chart.Result[0].Events.Dividends {
+		divs = append(divs, analytics.Dividend{
+			ExDate: time.Unix(d.Date, 0),
+			Amount: d.Amount,
+		})
+	}
+
+	sort.Slice(divs, func(i, j int) bool { return divs[i].ExDate.Before(divs[j].ExDate) })
+	return divs, nil
+}
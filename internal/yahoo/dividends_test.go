@@ -0,0 +1,61 @@
+package yahoo
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestParseDividendResponse(t *testing.T) {
+	data, err := os.ReadFile("testdata/yahoo-dividends-response.json")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	var dr dividendResponse
+	if err := json.Unmarshal(data, &dr); err != nil {
+		t.Fatalf("unmarshaling: %v", err)
+	}
+
+	divs, err := parseDividendResponse(&dr)
+	if err != nil {
+		t.Fatalf("parseDividendResponse: %v", err)
+	}
+
+	if len(divs) != 4 {
+		t.Fatalf("got %d dividends, want 4", len(divs))
+	}
+
+	// Map iteration order is random, so verify sorting
+	for i := 1; i < len(divs); i++ {
+		if divs[i].ExDate.Before(divs[i-1].ExDate) {
+			t.Errorf("dividends not sorted: %v before %v", divs[i-1].ExDate, divs[i].ExDate)
+		}
+	}
+	if divs[0].ExDate.Unix() != 1710163800 {
+		t.Errorf("first ex-date = %d, want 1710163800", divs[0].ExDate.Unix())
+	}
+	if divs[0].Amount != 0.485 {
+		t.Errorf("first amount = %v, want 0.485", divs[0].Amount)
+	}
+}
+
+func TestParseDividendResponseNoEvents(t *testing.T) {
+	data, err := os.ReadFile("testdata/yahoo-chart-1y-response.json")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	var dr dividendResponse
+	if err := json.Unmarshal(data, &dr); err != nil {
+		t.Fatalf("unmarshaling: %v", err)
+	}
+
+	divs, err := parseDividendResponse(&dr)
+	if err != nil {
+		t.Fatalf("parseDividendResponse: %v", err)
+	}
+	if len(divs) != 0 {
+		t.Errorf("got %d dividends, want 0", len(divs))
+	}
+}
@@ -0,0 +1,91 @@
+package yahoo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// EarningsDate is the next scheduled earnings report for a ticker.
+type EarningsDate struct {
+	Date     time.Time // Zero if Yahoo has no upcoming date
+	Estimate bool      // True if Yahoo flags the date as an estimate
+}
+
+// calendarEventsResponse maps the /v10/finance/quoteSummary/ JSON response for modules=calendarEvents.
+type calendarEventsResponse struct {
+	QuoteSummary struct {
+		Result []struct {
+			CalendarEvents struct {
+				Earnings struct {
+					EarningsDate []struct {
+						Raw int64 `json:"raw"`
+					} `json:"earningsDate"`
+					IsEarningsDateEstimate bool `json:"isEarningsDateEstimate"`
+				} `json:"earnings"`
+			} `json:"calendarEvents"`
+		} `json:"result"`
+		Error *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"quoteSummary"`
+}
+
+// FetchEarningsDate fetches the next earnings date for a ticker.
+func (c *Client) FetchEarningsDate(ticker string) (*EarningsDate, error) {
+	if err := c.ensureCrumb(); err != nil {
+		return nil, fmt.Errorf("auth: %w", err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	url := fmt.Sprintf("https://query2.finance.yahoo.com/v10/finance/quoteSummary/%s?modules=calendarEvents&crumb=%s", ticker, c.crumb)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36")
+
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: loggingTransport{},
+		Jar:       c.cookieJar,
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("yahoo quoteSummary API returned status %d", resp.StatusCode)
+	}
+
+	var cr calendarEventsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&cr); err != nil {
+		return nil, err
+	}
+
+	return parseCalendarEventsResponse(&cr)
+}
+
+func parseCalendarEventsResponse(cr *calendarEventsResponse) (*EarningsDate, error) {
+	if cr.QuoteSummary.Error != nil {
+		return nil, fmt.Errorf("yahoo API error: %s", cr.QuoteSummary.Error.Description)
+	}
+	if len(cr.QuoteSummary.Result) == 0 {
+		return nil, fmt.Errorf("no calendar data in response")
+	}
+
+	earnings := cr.QuoteSummary.Result[0].CalendarEvents.Earnings
+	ed := &EarningsDate{Estimate: earnings.IsEarningsDateEstimate}
+	// Yahoo sometimes returns a range; the first entry is the earliest candidate
+	if len(earnings.EarningsDate) > 0 {
+		ed.Date = time.Unix(earnings.EarningsDate[0].Raw, 0)
+	}
+	return ed, nil
+}
@@ -0,0 +1,46 @@
+package yahoo
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestParseCalendarEventsResponse(t *testing.T) {
+	data, err := os.ReadFile("testdata/yahoo-calendar-events-response.json")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	var cr calendarEventsResponse
+	if err := json.Unmarshal(data, &cr); err != nil {
+		t.Fatalf("unmarshaling: %v", err)
+	}
+
+	ed, err := parseCalendarEventsResponse(&cr)
+	if err != nil {
+		t.Fatalf("parseCalendarEventsResponse: %v", err)
+	}
+
+	if ed.Date.Unix() != 1769720400 {
+		t.Errorf("earnings date = %d, want 1769720400", ed.Date.Unix())
+	}
+	if !ed.Estimate {
+		t.Error("expected date to be flagged as estimate")
+	}
+}
+
+func TestParseCalendarEventsResponseNoDate(t *testing.T) {
+	var cr calendarEventsResponse
+	if err := json.Unmarshal([]byte(`{"quoteSummary":{"result":[{"calendarEvents":{"earnings":{}}}],"error":null}}`), &cr); err != nil {
+		t.Fatalf("unmarshaling: %v", err)
+	}
+
+	ed, err := parseCalendarEventsResponse(&cr)
+	if err != nil {
+		t.Fatalf("parseCalendarEventsResponse: %v", err)
+	}
+	if !ed.Date.IsZero() {
+		t.Errorf("expected zero date, got %v", ed.Date)
+	}
+}
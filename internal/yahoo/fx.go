@@ -0,0 +1,20 @@
+package yahoo
+
+// FXSymbol is Yahoo's symbol for the price of one unit of from in to, e.g.
+// EURUSD=X. Currency pairs are fetched like any other quote.
+func FXSymbol(from, to string) string {
+	return from + to + "=X"
+}
+
+// FXRates reads the rate converting each of currencies into base from the
+// currency pair quotes among quotes. Base converts at 1; currencies without
+// a quote are left out, so a missing rate can be told from a real one.
+func FXRates(quotes map[string]Quote, currencies []string, base string) map[string]float64 {
+	rates := map[string]float64{base: 1}
+	for _, c := range currencies {
+		if q, ok := quotes[FXSymbol(c, base)]; ok && q.Price > 0 {
+			rates[c] = q.Price
+		}
+	}
+	return rates
+}
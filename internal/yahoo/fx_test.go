@@ -0,0 +1,23 @@
+package yahoo
+
+import "testing"
+
+func TestFXRates(t *testing.T) {
+	quotes := map[string]Quote{
+		"EURUSD=X": {Symbol: "EURUSD=X", Price: 1.085},
+		"JPYUSD=X": {Symbol: "JPYUSD=X", Price: 0}, // No trade yet
+	}
+	rates := FXRates(quotes, []string{"USD", "EUR", "JPY", "GBP"}, "USD")
+
+	if rates["USD"] != 1 {
+		t.Errorf("USD rate = %v, want 1", rates["USD"])
+	}
+	if rates["EUR"] != 1.085 {
+		t.Errorf("EUR rate = %v, want 1.085", rates["EUR"])
+	}
+	for _, c := range []string{"JPY", "GBP"} {
+		if r, ok := rates[c]; ok {
+			t.Errorf("%s rate = %v, want none", c, r)
+		}
+	}
+}
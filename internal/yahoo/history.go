@@ -0,0 +1,84 @@
+package yahoo
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// History is how much price history indicators like RSI are computed from:
+// a range back from today and the bar interval, in the chart API's terms.
+type History struct {
+	Range    string // 3mo, 6mo, 1y, or 2y; 5y for VIX context
+	Interval string // 1d or 1wk
+}
+
+// HistoryRanges and HistoryIntervals are the ranges and intervals a History
+// may use, shortest first.
+var (
+	HistoryRanges    = []string{"3mo", "6mo", "1y", "2y"}
+	HistoryIntervals = []string{"1d", "1wk"}
+)
+
+// DefaultHistory is a year of daily closes.
+var DefaultHistory = History{Range: "1y", Interval: "1d"}
+
+// MinHistoryCloses is the fewest closes a History must cover: a 14-period
+// RSI needs 15.
+const MinHistoryCloses = 15
+
+// ParseHistory parses a History written as range/interval, e.g. "6mo/1wk".
+// Empty text is DefaultHistory.
+func ParseHistory(text string) (History, error) {
+	if text == "" {
+		return DefaultHistory, nil
+	}
+	rng, interval, ok := strings.Cut(text, "/")
+	h := History{Range: rng, Interval: interval}
+	if !ok || !slices.Contains(HistoryRanges, rng) || !slices.Contains(HistoryIntervals, interval) {
+		return History{}, fmt.Errorf("history %q is not one of %s over %s", text,
+			strings.Join(HistoryRanges, ", "), strings.Join(HistoryIntervals, " or "))
+	}
+	if h.Closes() < MinHistoryCloses {
+		return History{}, fmt.Errorf("history %s has about %d closes, fewer than the %d RSI needs", text, h.Closes(), MinHistoryCloses)
+	}
+	return h, nil
+}
+
+func (h History) String() string {
+	return h.Range + "/" + h.Interval
+}
+
+// Weekly reports whether h has one close a week
+func (h History) Weekly() bool {
+	return h.Interval == "1wk"
+}
+
+// Since is the start of h's range back from now
+func (h History) Since(now time.Time) time.Time {
+	return now.AddDate(0, -h.months(), 0)
+}
+
+// Closes is about how many closes h covers
+func (h History) Closes() int {
+	if h.Weekly() {
+		return h.months() * 52 / 12
+	}
+	return h.months() * 21
+}
+
+func (h History) months() int {
+	switch h.Range {
+	case "3mo":
+		return 3
+	case "6mo":
+		return 6
+	case "2y":
+		return 24
+	case "5y":
+		return 60
+	default:
+		return 12
+	}
+}
@@ -0,0 +1,24 @@
+package yahoo
+
+import "testing"
+
+func TestParseHistory(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    History
+		wantErr bool
+	}{
+		{"", DefaultHistory, false},
+		{"6mo/1wk", History{Range: "6mo", Interval: "1wk"}, false},
+		{"2y/1d", History{Range: "2y", Interval: "1d"}, false},
+		{"3mo/1wk", History{}, true}, // 13 weekly closes, too few for RSI
+		{"5y/1d", History{}, true},
+		{"1y", History{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseHistory(tt.text)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseHistory(%q) = %v, %v; want %v, error %v", tt.text, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
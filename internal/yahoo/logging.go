@@ -0,0 +1,27 @@
+package yahoo
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// loggingTransport records timing and failures for every Yahoo request
+type loggingTransport struct{}
+
+func (loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		slog.Warn("yahoo request failed", "path", req.URL.Path, "duration", elapsed, "err", err)
+		return nil, err
+	}
+	level := slog.LevelDebug
+	if resp.StatusCode != http.StatusOK {
+		level = slog.LevelWarn
+	}
+	slog.Log(req.Context(), level, "yahoo request", "path", req.URL.Path, "status", resp.StatusCode, "duration", elapsed)
+	return resp, nil
+}
@@ -8,6 +8,7 @@ import (
 	"net/http/cookiejar"
 	"time"
 
+	"anyhowhodl/internal/analytics"
 	"anyhowhodl/internal/csp"
 )
 
@@ -51,12 +52,16 @@ type optionRawItem struct {
 	InTheMoney        bool    `json:"inTheMoney"`
 }
 
-// chartHistoryResponse maps the /v8/finance/chart/ JSON response for range=1y.
+// chartHistoryResponse maps the /v8/finance/chart/ JSON response for daily history.
 type chartHistoryResponse struct {
 	Chart struct {
 		Result []struct {
+			Timestamp  []int64 `json:"timestamp"`
 			Indicators struct {
 				Quote []struct {
+					Open  []*float64 `json:"open"`
+					High  []*float64 `json:"high"`
+					Low   []*float64 `json:"low"`
 					Close []*float64 `json:"close"`
 				} `json:"quote"`
 			} `json:"indicators"`
@@ -81,8 +86,9 @@ func (c *Client) ensureCrumb() error {
 
 	// Step 1: GET fc.yahoo.com to get cookies
 	client := &http.Client{
-		Timeout: 10 * time.Second,
-		Jar:     jar,
+		Timeout:   10 * time.Second,
+		Transport: loggingTransport{},
+		Jar:       jar,
 	}
 
 	req, err := http.NewRequest("GET", "https://fc.yahoo.com", nil)
@@ -153,8 +159,9 @@ func (c *Client) fetchOptions(ticker string, expiry int64) (*csp.OptionsData, er
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36")
 
 	client := &http.Client{
-		Timeout: 10 * time.Second,
-		Jar:     c.cookieJar,
+		Timeout:   10 * time.Second,
+		Transport: loggingTransport{},
+		Jar:       c.cookieJar,
 	}
 
 	resp, err := client.Do(req)
@@ -222,9 +229,47 @@ func parseOptionsResponse(or *optionsResponse) (*csp.OptionsData, error) {
 
 // FetchPriceHistory fetches 1 year of daily closing prices for a ticker.
 func (c *Client) FetchPriceHistory(ticker string) ([]float64, error) {
-	time.Sleep(200 * time.Millisecond)
+	cr, err := c.fetchChartHistory(ticker)
+	if err != nil {
+		return nil, err
+	}
+	return parseChartHistoryResponse(cr)
+}
+
+// FetchCloseHistory fetches the closing prices over h, oldest first.
+func (c *Client) FetchCloseHistory(ticker string, h History) ([]float64, error) {
+	cr, err := c.fetchChart(fmt.Sprintf("https://query2.finance.yahoo.com/v8/finance/chart/%s?range=%s&interval=%s", ticker, h.Range, h.Interval))
+	if err != nil {
+		return nil, err
+	}
+	return parseChartHistoryResponse(cr)
+}
+
+// FetchPriceSeries fetches 1 year of timestamped daily closes for a ticker.
+func (c *Client) FetchPriceSeries(ticker string) ([]analytics.PricePoint, error) {
+	cr, err := c.fetchChartHistory(ticker)
+	if err != nil {
+		return nil, err
+	}
+	return parseChartSeriesResponse(cr)
+}
+
+// FetchDailyBars fetches the daily bars for a ticker from since to now.
+func (c *Client) FetchDailyBars(ticker string, since time.Time) ([]analytics.DailyBar, error) {
+	cr, err := c.fetchChart(fmt.Sprintf("https://query2.finance.yahoo.com/v8/finance/chart/%s?period1=%d&period2=%d&interval=1d",
+		ticker, since.Unix(), time.Now().Unix()))
+	if err != nil {
+		return nil, err
+	}
+	return parseChartBarsResponse(cr)
+}
+
+func (c *Client) fetchChartHistory(ticker string) (*chartHistoryResponse, error) {
+	return c.fetchChart(fmt.Sprintf("https://query2.finance.yahoo.com/v8/finance/chart/%s?range=1y&interval=1d", ticker))
+}
 
-	url := fmt.Sprintf("https://query2.finance.yahoo.com/v8/finance/chart/%s?range=1y&interval=1d", ticker)
+func (c *Client) fetchChart(url string) (*chartHistoryResponse, error) {
+	time.Sleep(200 * time.Millisecond)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -247,7 +292,7 @@ func (c *Client) FetchPriceHistory(ticker string) ([]float64, error) {
 		return nil, err
 	}
 
-	return parseChartHistoryResponse(&cr)
+	return &cr, nil
 }
 
 func parseChartHistoryResponse(cr *chartHistoryResponse) ([]float64, error) {
@@ -273,3 +318,63 @@ func parseChartHistoryResponse(cr *chartHistoryResponse) ([]float64, error) {
 
 	return closes, nil
 }
+
+func parseChartSeriesResponse(cr *chartHistoryResponse) ([]analytics.PricePoint, error) {
+	if cr.Chart.Error != nil {
+		return nil, fmt.Errorf("yahoo chart error: %s", cr.Chart.Error.Description)
+	}
+	if len(cr.Chart.Result) == 0 {
+		return nil, fmt.Errorf("no chart data in response")
+	}
+
+	r := cr.Chart.Result[0]
+	if len(r.Indicators.Quote) == 0 {
+		return nil, fmt.Errorf("no quote indicators in chart response")
+	}
+
+	rawCloses := r.Indicators.Quote[0].Close
+	if len(r.Timestamp) != len(rawCloses) {
+		return nil, fmt.Errorf("chart timestamps (%d) and closes (%d) differ in length", len(r.Timestamp), len(rawCloses))
+	}
+
+	var points []analytics.PricePoint
+	for i, v := range rawCloses {
+		if v != nil {
+			points = append(points, analytics.PricePoint{Time: r.Timestamp[i], Close: *v})
+		}
+	}
+
+	return points, nil
+}
+
+// parseChartBarsResponse keeps the days with all four prices; Yahoo leaves
+// them null for sessions without trades
+func parseChartBarsResponse(cr *chartHistoryResponse) ([]analytics.DailyBar, error) {
+	if cr.Chart.Error != nil {
+		return nil, fmt.Errorf("yahoo chart error: %s", cr.Chart.Error.Description)
+	}
+	if len(cr.Chart.Result) == 0 {
+		return nil, fmt.Errorf("no chart data in response")
+	}
+
+	r := cr.Chart.Result[0]
+	if len(r.Indicators.Quote) == 0 {
+		// No sessions in the range, e.g. a weekend
+		return nil, nil
+	}
+
+	q := r.Indicators.Quote[0]
+	n := len(r.Timestamp)
+	if len(q.Open) != n || len(q.High) != n || len(q.Low) != n || len(q.Close) != n {
+		return nil, fmt.Errorf("chart timestamps and prices differ in length")
+	}
+
+	var bars []analytics.DailyBar
+	for i, ts := range r.Timestamp {
+		if q.Open[i] == nil || q.High[i] == nil || q.Low[i] == nil || q.Close[i] == nil {
+			continue
+		}
+		bars = append(bars, analytics.DailyBar{Time: ts, Open: *q.Open[i], High: *q.High[i], Low: *q.Low[i], Close: *q.Close[i]})
+	}
+	return bars, nil
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"os"
 	"testing"
+
+	"anyhowhodl/internal/analytics"
 )
 
 func TestParseOptionsResponse(t *testing.T) {
@@ -107,3 +109,60 @@ func TestParseChartResponse(t *testing.T) {
 
 	t.Logf("Parsed %d closing prices (nils filtered)", len(closes))
 }
+
+func TestParseChartSeriesResponse(t *testing.T) {
+	data, err := os.ReadFile("testdata/yahoo-chart-1y-response.json")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	var cr chartHistoryResponse
+	if err := json.Unmarshal(data, &cr); err != nil {
+		t.Fatalf("unmarshaling: %v", err)
+	}
+
+	points, err := parseChartSeriesResponse(&cr)
+	if err != nil {
+		t.Fatalf("parseChartSeriesResponse: %v", err)
+	}
+
+	if len(points) != 19 {
+		t.Fatalf("got %d points, want 19", len(points))
+	}
+	if points[0].Time != 1738594200 || points[0].Close != 170.12 {
+		t.Errorf("first point = %+v, want {1738594200 170.12}", points[0])
+	}
+	// The null close at index 3 is skipped, so point 3 carries timestamp 4
+	if points[3].Time != 1738939800 || points[3].Close != 173.50 {
+		t.Errorf("point 3 = %+v, want {1738939800 173.5}", points[3])
+	}
+}
+
+func TestParseChartBarsResponse(t *testing.T) {
+	data, err := os.ReadFile("testdata/yahoo-chart-bars-response.json")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	var cr chartHistoryResponse
+	if err := json.Unmarshal(data, &cr); err != nil {
+		t.Fatalf("unmarshaling: %v", err)
+	}
+
+	bars, err := parseChartBarsResponse(&cr)
+	if err != nil {
+		t.Fatalf("parseChartBarsResponse: %v", err)
+	}
+
+	// The day without trades is skipped
+	if len(bars) != 3 {
+		t.Fatalf("got %d bars, want 3", len(bars))
+	}
+	want := analytics.DailyBar{Time: 1738594200, Open: 169.50, High: 171.00, Low: 168.90, Close: 170.12}
+	if bars[0] != want {
+		t.Errorf("first bar = %+v, want %+v", bars[0], want)
+	}
+	if bars[2].Time != 1738853400 || bars[2].Close != 173.50 {
+		t.Errorf("last bar = %+v, want close 173.5 at 1738853400", bars[2])
+	}
+}
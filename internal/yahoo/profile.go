@@ -0,0 +1,93 @@
+package yahoo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ETFSector is the sector given to funds, which Yahoo reports without one
+const ETFSector = "ETF"
+
+// Profile is a ticker's sector and industry classification.
+type Profile struct {
+	Sector   string // Empty if Yahoo has none
+	Industry string
+}
+
+// profileResponse maps the /v10/finance/quoteSummary/ JSON response for modules=assetProfile,quoteType.
+type profileResponse struct {
+	QuoteSummary struct {
+		Result []struct {
+			AssetProfile struct {
+				Sector   string `json:"sector"`
+				Industry string `json:"industry"`
+			} `json:"assetProfile"`
+			QuoteType struct {
+				QuoteType string `json:"quoteType"`
+			} `json:"quoteType"`
+		} `json:"result"`
+		Error *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"quoteSummary"`
+}
+
+// FetchProfile fetches the sector and industry of a ticker.
+func (c *Client) FetchProfile(ticker string) (*Profile, error) {
+	if err := c.ensureCrumb(); err != nil {
+		return nil, fmt.Errorf("auth: %w", err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	url := fmt.Sprintf("https://query2.finance.yahoo.com/v10/finance/quoteSummary/%s?modules=assetProfile,quoteType&crumb=%s", ticker, c.crumb)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36")
+
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: loggingTransport{},
+		Jar:       c.cookieJar,
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("yahoo quoteSummary API returned status %d", resp.StatusCode)
+	}
+
+	var pr profileResponse
+	if err := json.NewDecoder(resp.Body).Decode(&pr); err != nil {
+		return nil, err
+	}
+
+	return parseProfileResponse(&pr)
+}
+
+func parseProfileResponse(pr *profileResponse) (*Profile, error) {
+	if pr.QuoteSummary.Error != nil {
+		return nil, fmt.Errorf("yahoo API error: %s", pr.QuoteSummary.Error.Description)
+	}
+	if len(pr.QuoteSummary.Result) == 0 {
+		return nil, fmt.Errorf("no profile data in response")
+	}
+
+	result := pr.QuoteSummary.Result[0]
+	p := &Profile{Sector: result.AssetProfile.Sector, Industry: result.AssetProfile.Industry}
+	// Funds have a fund profile instead, without a sector
+	if p.Sector == "" && result.QuoteType.QuoteType == "ETF" {
+		p.Sector = ETFSector
+	}
+	return p, nil
+}
@@ -0,0 +1,42 @@
+package yahoo
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestParseProfileResponse(t *testing.T) {
+	data, err := os.ReadFile("testdata/yahoo-profile-response.json")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	var pr profileResponse
+	if err := json.Unmarshal(data, &pr); err != nil {
+		t.Fatalf("unmarshaling: %v", err)
+	}
+
+	p, err := parseProfileResponse(&pr)
+	if err != nil {
+		t.Fatalf("parseProfileResponse: %v", err)
+	}
+	if p.Sector != "Technology" || p.Industry != "Consumer Electronics" {
+		t.Errorf("profile = %+v", p)
+	}
+}
+
+func TestParseProfileResponseETF(t *testing.T) {
+	var pr profileResponse
+	if err := json.Unmarshal([]byte(`{"quoteSummary":{"result":[{"assetProfile":{"maxAge":86400},"quoteType":{"quoteType":"ETF","symbol":"SPY"}}],"error":null}}`), &pr); err != nil {
+		t.Fatalf("unmarshaling: %v", err)
+	}
+
+	p, err := parseProfileResponse(&pr)
+	if err != nil {
+		t.Fatalf("parseProfileResponse: %v", err)
+	}
+	if p.Sector != ETFSector || p.Industry != "" {
+		t.Errorf("profile = %+v", p)
+	}
+}
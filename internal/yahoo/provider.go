@@ -0,0 +1,27 @@
+package yahoo
+
+import (
+	"time"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/csp"
+)
+
+// Provider is the market data API used by the app. *Client implements it
+// against Yahoo Finance; internal/fake provides a canned implementation for tests.
+type Provider interface {
+	GetQuote(symbol string) (*Quote, error)
+	GetQuotes(symbols []string) (map[string]Quote, error)
+	FetchOptionsChain(ticker string) (*csp.OptionsData, error)
+	FetchOptionsChainForExpiry(ticker string, expiry int64) (*csp.OptionsData, error)
+	FetchPriceHistory(ticker string) ([]float64, error)
+	FetchCloseHistory(ticker string, h History) ([]float64, error)
+	FetchPriceSeries(ticker string) ([]analytics.PricePoint, error)
+	FetchDailyBars(ticker string, since time.Time) ([]analytics.DailyBar, error)
+	FetchDividends(ticker string) ([]analytics.Dividend, error)
+	FetchSplits(ticker string) ([]Split, error)
+	FetchEarningsDate(ticker string) (*EarningsDate, error)
+	FetchProfile(ticker string) (*Profile, error)
+}
+
+var _ Provider = (*Client)(nil)
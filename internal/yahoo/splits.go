@@ -0,0 +1,96 @@
+package yahoo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Split is a stock split: Numerator new shares for every Denominator held,
+// e.g. 4 for 1, or 1 for 10 for a reverse split.
+type Split struct {
+	Date        time.Time
+	Numerator   float64
+	Denominator float64
+}
+
+// Ratio is the new shares per old share.
+func (s Split) Ratio() float64 {
+	return s.Numerator / s.Denominator
+}
+
+// splitResponse maps the /v8/finance/chart/ JSON response with events=split.
+type splitResponse struct {
+	Chart struct {
+		Result []struct {
+			Events struct {
+				Splits map[string]struct {
+					Date        int64   `json:"date"`
+					Numerator   float64 `json:"numerator"`
+					Denominator float64 `json:"denominator"`
+				} `json:"splits"`
+			} `json:"events"`
+		} `json:"result"`
+		Error *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"chart"`
+}
+
+// FetchSplits fetches the last 2 years of stock splits for a ticker.
+func (c *Client) FetchSplits(ticker string) ([]Split, error) {
+	time.Sleep(200 * time.Millisecond)
+
+	url := fmt.Sprintf("https://query2.finance.yahoo.com/v8/finance/chart/%s?range=2y&interval=1mo&events=split", ticker)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("yahoo chart API returned status %d", resp.StatusCode)
+	}
+
+	var sr splitResponse
+	if err := json.NewDecoder(resp.Body).Decode(&sr); err != nil {
+		return nil, err
+	}
+
+	return parseSplitResponse(&sr)
+}
+
+func parseSplitResponse(sr *splitResponse) ([]Split, error) {
+	if sr.Chart.Error != nil {
+		return nil, fmt.Errorf("yahoo chart error: %s", sr.Chart.Error.Description)
+	}
+	if len(sr.Chart.Result) == 0 {
+		return nil, fmt.Errorf("no chart data in response")
+	}
+
+	// Most tickers have no splits map
+	var splits []Split
+	for _, s := range sr.Chart.Result[0].Events.Splits {
+		if s.Numerator <= 0 || s.Denominator <= 0 {
+			continue
+		}
+		splits = append(splits, Split{
+			Date:        time.Unix(s.Date, 0),
+			Numerator:   s.Numerator,
+			Denominator: s.Denominator,
+		})
+	}
+
+	sort.Slice(splits, func(i, j int) bool { return splits[i].Date.Before(splits[j].Date) })
+	return splits, nil
+}
@@ -0,0 +1,53 @@
+package yahoo
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestParseSplitResponse(t *testing.T) {
+	data, err := os.ReadFile("testdata/yahoo-splits-response.json")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	var sr splitResponse
+	if err := json.Unmarshal(data, &sr); err != nil {
+		t.Fatalf("unmarshaling: %v", err)
+	}
+
+	splits, err := parseSplitResponse(&sr)
+	if err != nil {
+		t.Fatalf("parseSplitResponse: %v", err)
+	}
+	if len(splits) != 2 {
+		t.Fatalf("got %d splits, want 2", len(splits))
+	}
+	if splits[0].Date.Unix() != 1626701400 || splits[0].Ratio() != 4 {
+		t.Errorf("first split = %+v, want 4-for-1 at 1626701400", splits[0])
+	}
+	if splits[1].Date.Unix() != 1718026200 || splits[1].Ratio() != 10 {
+		t.Errorf("second split = %+v, want 10-for-1 at 1718026200", splits[1])
+	}
+}
+
+func TestParseSplitResponseNoEvents(t *testing.T) {
+	data, err := os.ReadFile("testdata/yahoo-dividends-response.json")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	var sr splitResponse
+	if err := json.Unmarshal(data, &sr); err != nil {
+		t.Fatalf("unmarshaling: %v", err)
+	}
+
+	splits, err := parseSplitResponse(&sr)
+	if err != nil {
+		t.Fatalf("parseSplitResponse: %v", err)
+	}
+	if len(splits) != 0 {
+		t.Errorf("got %d splits, want 0", len(splits))
+	}
+}
@@ -3,6 +3,7 @@ package yahoo
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/cookiejar"
 	"sync"
@@ -17,6 +18,9 @@ type Quote struct {
 	MarketState     string
 	FiftyTwoWeekHigh float64
 	PctFromHigh     float64
+	FiftyTwoWeekLow float64
+	PctFromLow      float64 // How far the price is above the 52-week low
+	Currency        string // ISO code the price is in, as Yahoo reports it
 }
 
 type chartResponse struct {
@@ -27,6 +31,8 @@ type chartResponse struct {
 				RegularMarketPrice float64 `json:"regularMarketPrice"`
 				ChartPreviousClose float64 `json:"chartPreviousClose"`
 				FiftyTwoWeekHigh   float64 `json:"fiftyTwoWeekHigh"`
+				FiftyTwoWeekLow    float64 `json:"fiftyTwoWeekLow"`
+				Currency           string  `json:"currency"`
 			} `json:"meta"`
 		} `json:"result"`
 		Error *struct {
@@ -45,7 +51,8 @@ type Client struct {
 func NewClient() *Client {
 	return &Client{
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: loggingTransport{},
 		},
 	}
 }
@@ -64,6 +71,9 @@ func (c *Client) GetQuotes(symbols []string) (map[string]Quote, error) {
 		go func(sym string) {
 			defer wg.Done()
 			quote, err := c.fetchQuote(sym)
+			if err != nil {
+				slog.Warn("quote fetch failed", "symbol", sym, "err", err)
+			}
 			if err == nil && quote != nil {
 				mu.Lock()
 				quotes[sym] = *quote
@@ -121,6 +131,11 @@ func (c *Client) fetchQuote(symbol string) (*Quote, error) {
 		pctFromHigh = ((meta.RegularMarketPrice - meta.FiftyTwoWeekHigh) / meta.FiftyTwoWeekHigh) * 100
 	}
 
+	pctFromLow := 0.0
+	if meta.FiftyTwoWeekLow > 0 {
+		pctFromLow = ((meta.RegularMarketPrice - meta.FiftyTwoWeekLow) / meta.FiftyTwoWeekLow) * 100
+	}
+
 	return &Quote{
 		Symbol:           meta.Symbol,
 		Price:            meta.RegularMarketPrice,
@@ -128,6 +143,9 @@ func (c *Client) fetchQuote(symbol string) (*Quote, error) {
 		ChangePercent:    changePercent,
 		FiftyTwoWeekHigh: meta.FiftyTwoWeekHigh,
 		PctFromHigh:      pctFromHigh,
+		FiftyTwoWeekLow:  meta.FiftyTwoWeekLow,
+		PctFromLow:       pctFromLow,
+		Currency:         meta.Currency,
 	}, nil
 }
 
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"anyhowhodl/internal/db"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showLedgerView shows the cash ledger of the selected account, oldest first
+// and scrolled to the latest entry, with the balance each entry left
+func (a *App) showLedgerView() {
+	txs, err := a.db.GetTransactions(context.Background())
+	if err != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]History unavailable (run the transactions migration in schema.sql): %v", err))
+		return
+	}
+
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	table.SetBorder(true).
+		SetTitle(fmt.Sprintf(" History: %d transactions ", len(txs))).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+	a.fillLedgerTable(table, txs)
+
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(" [gray]Every change to cash, with the balance it left. ESC to close")
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(help, 1, 0, false)
+	a.pages.AddPage("ledger", flex, true, true)
+}
+
+// fillLedgerTable lists txs with the latest selected
+func (a *App) fillLedgerTable(table *tview.Table, txs []db.Transaction) {
+	table.Clear()
+	for i, h := range []string{"DATE", "KIND", "TICKER", "QTY", "AMOUNT", "BALANCE", "NOTES"} {
+		align := tview.AlignLeft
+		if i >= 3 && i <= 5 {
+			align = tview.AlignRight
+		}
+		table.SetCell(0, i, tview.NewTableCell(" "+h+" ").
+			SetTextColor(tcell.ColorBlack).
+			SetBackgroundColor(tcell.ColorTeal).
+			SetAlign(align).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+	if len(txs) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell(" No transactions yet").
+			SetTextColor(tcell.ColorGray).
+			SetSelectable(false))
+		return
+	}
+	for i, t := range txs {
+		row := i + 1
+		amountColor := tcell.ColorLime
+		if t.Amount.IsNegative() {
+			amountColor = tcell.ColorRed
+		}
+		qty := ""
+		if !t.Quantity.IsZero() {
+			qty = a.book.formatShares(t.Quantity)
+		}
+		table.SetCell(row, 0, tview.NewTableCell(" "+a.book.locale.FormatDate(t.CreatedAt)+" "+t.CreatedAt.Format("15:04")))
+		table.SetCell(row, 1, tview.NewTableCell(t.Kind).SetTextColor(tcell.ColorYellow))
+		table.SetCell(row, 2, tview.NewTableCell(t.Ticker))
+		table.SetCell(row, 3, tview.NewTableCell(qty).SetAlign(tview.AlignRight))
+		table.SetCell(row, 4, tview.NewTableCell(a.book.locale.FormatFixed(t.Amount, 2)).SetTextColor(amountColor).SetAlign(tview.AlignRight))
+		table.SetCell(row, 5, tview.NewTableCell(a.book.locale.FormatFixed(t.Balance, 2)).SetAlign(tview.AlignRight))
+		table.SetCell(row, 6, tview.NewTableCell(tview.Escape(t.Notes)).SetTextColor(tcell.ColorGray))
+	}
+	table.Select(len(txs), 0)
+}
+
+// showCashForm records a deposit or withdrawal as a contribution, which the
+// growth breakdown counts, or sets the balance with an adjustment; cash is
+// the sum of the ledger, which History opens
+func (a *App) showCashForm() {
+	form := tview.NewForm()
+	kinds := []string{"Deposit", "Withdrawal", "Set balance"}
+
+	saveCash := func() {
+		kind, _ := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+		amountStr := form.GetFormItem(1).(*tview.InputField).GetText()
+		notes := form.GetFormItem(2).(*tview.InputField).GetText()
+
+		amount, err := a.book.locale.ParseNumber(amountStr)
+		if err != nil || (kind != 2 && !amount.IsPositive()) {
+			a.statusBar.SetText(" [red]Invalid cash amount")
+			return
+		}
+
+		ctx := context.Background()
+		switch kind {
+		case 0:
+			err = a.db.AddContribution(ctx, amount, a.now(), notes)
+		case 1:
+			err = a.db.AddContribution(ctx, amount.Neg(), a.now(), notes)
+		default:
+			err = a.db.SetAvailableCash(ctx, amount)
+		}
+		if err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+
+		a.pages.SwitchToPage("main")
+		a.pages.RemovePage("cash")
+		a.refreshData()
+	}
+
+	form.AddDropDown("Kind", kinds, 0, nil)
+	form.AddInputField("Amount ($)", "", 15, nil, nil)
+	form.AddInputField("Notes", "", 30, nil, nil)
+	form.GetFormItem(1).(*tview.InputField).SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			saveCash()
+		}
+	})
+
+	styleForm(form)
+
+	form.AddButton("Save", saveCash)
+
+	form.AddButton("History", func() {
+		a.pages.RemovePage("cash")
+		a.showLedgerView()
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.SwitchToPage("main")
+		a.pages.RemovePage("cash")
+	})
+
+	form.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Cash: $%s ", a.book.locale.FormatFloat(a.book.cash.InexactFloat64(), 2))).
+		SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("cash", form, 55, 11)
+}
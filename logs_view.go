@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"anyhowhodl/internal/logging"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// logsViewLines is how many trailing log lines the logs view shows
+const logsViewLines = 500
+
+// setupLogging configures slog from LOG_FILE and LOG_LEVEL. Returns the log
+// path and a closer, or a nil closer if the file could not be opened.
+func setupLogging(stderr bool) (string, io.Closer) {
+	path := os.Getenv("LOG_FILE")
+	if path == "" {
+		path = logging.DefaultPath()
+	}
+
+	level, levelErr := logging.ParseLevel(os.Getenv("LOG_LEVEL"))
+
+	var extra []io.Writer
+	if stderr {
+		extra = append(extra, os.Stderr)
+	}
+	closer, err := logging.Setup(path, level, extra...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logging disabled: %v\n", err)
+		return path, nil
+	}
+	if levelErr != nil {
+		slog.Warn("using info level", "err", levelErr)
+	}
+	slog.Info("starting", "args", os.Args[1:], "level", level.String())
+	return path, closer
+}
+
+// showLogsView shows the tail of the log file, colored by level
+func (a *App) showLogsView() {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	view.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Logs: %s (r:Reload  ESC:Close) ", a.logPath)).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	load := func() {
+		lines, err := logging.Tail(a.logPath, logsViewLines)
+		if err != nil {
+			view.SetText(fmt.Sprintf(" [red]Error reading log: %v", err))
+			return
+		}
+		var sb strings.Builder
+		for _, line := range lines {
+			color := "white"
+			switch {
+			case strings.Contains(line, "level=ERROR"):
+				color = "red"
+			case strings.Contains(line, "level=WARN"):
+				color = "yellow"
+			case strings.Contains(line, "level=DEBUG"):
+				color = "gray"
+			}
+			fmt.Fprintf(&sb, "[%s]%s\n", color, tview.Escape(line))
+		}
+		view.SetText(sb.String())
+		view.ScrollToEnd()
+	}
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'r' {
+			load()
+			return nil
+		}
+		return event
+	})
+
+	load()
+	a.pages.AddPage("logs", view, true, true)
+}
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"os"
 	"strconv"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"anyhowhodl/internal/csp"
+	"anyhowhodl/internal/analytics"
 	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/notify"
+	"anyhowhodl/internal/query"
 	"anyhowhodl/internal/yahoo"
 
 	"github.com/gdamore/tcell/v2"
@@ -19,306 +22,618 @@ import (
 )
 
 type App struct {
-	db              *db.DB
-	yahoo           *yahoo.Client
+	book *book // The selected account's portfolio, loaded on refresh
+
+	// Views render the book and own their widgets and view state; see their files
+	holdingsView *HoldingsView
+	summaryView  *SummaryView
+	optionsView  *OptionsView
+	cspView      *CSPView
+	bus          eventBus // Views subscribe to re-render when what they show changes
+
+	db              db.Store
+	role            db.Role // Viewer sessions are read-only
+	demo            bool    // Running on the in-memory demo portfolio
+	targetWeights   db.TargetWeights  // Target weights and drift tolerance, loaded on refresh
+	yahoo           yahoo.Provider
+	chains          *yahoo.ChainCache // The option chain cache behind yahoo, nil in tests
+	query           *query.Service
 	app             *tview.Application
 	pages           *tview.Pages
-	table           *tview.Table
-	optionsTable    *tview.Table
-	timeline        *tview.TextView // Premium stats
-	expiryTimeline  *tview.TextView // Visual expiry timeline
 	statusBar       *tview.TextView
-	summary         *tview.TextView
 	header          tview.Primitive
 	holdingsSection *tview.Flex
 	optionsSection  *tview.Flex
 	mainFlex        *tview.Flex
-	holdings        []db.Holding
-	options         []db.Option
-	quotes          map[string]yahoo.Quote
-	cash            decimal.Decimal
-	premiums        *db.PremiumSummary
 	focusIndex      int       // 0 = holdings table, 1 = options table
 	lastEscTime     time.Time // For double-ESC to quit
-	weeklyView      bool      // Toggle between weekly and monthly timeline view
 	lastRefresh     time.Time // Timestamp of last data refresh
+	changeStamp     string    // Database change stamp as of the last refresh
+	cashSnapshotErr error     // Why the last refresh's cash snapshot failed, nil if recorded
+	valueSnapshotErr error    // Why the last refresh's portfolio snapshot failed, nil if recorded
 	autoRefresh     bool      // Auto-refresh toggle
 	stopAutoRefresh chan bool // Channel to stop auto-refresh goroutine
-	showExpired     bool      // Show expired options toggle
-	// CSP Advisor fields
-	cspTable        *tview.Table
-	cspStatusBar    *tview.TextView
-	cspSection      *tview.Flex
-	cspWatchlist    []db.CSPWatchItem
-	cspScores       map[string]csp.SignalOutput
-	cspContractInfo map[string]ContractInfo
-	showCSP         bool // Toggle CSP view visibility
+	hideBanner      bool      // ASCII banner header collapsed
+	showCSP         bool      // The CSP advisor shown instead of the portfolio
+	dividendHistory map[string][]analytics.Dividend // Dividend histories for projected income, fetched once per session
+	dividends       []db.DividendPayment            // Dividends received this tax year
+	sales           []db.StockSale                  // Shares sold this tax year
+	dividendsBusy   bool      // Dividend histories being fetched
+	earnings        map[string]earningsDate // Next earnings date by ticker, for the tables
+	earningsBusy    bool                    // Earnings dates being fetched
+	riskCaps        []db.RiskCap      // Exposure caps, loaded on refresh
+	sectors         map[string]string // Ticker to sector, for sector caps
+	policies        []db.OptionPolicy // Roll and close rules on option positions
+	policyActions   []db.PolicyAction // Policy actions waiting for confirmation
+	reminders       []db.Reminder     // Option reminders not yet dismissed
+	watchlist       []db.WatchItem    // Stock watchlist, loaded when shown
+	watchQuotes     map[string]yahoo.Quote // Quotes of the watchlist's tickers
+	ideas           []db.TradeIdea    // Trade ideas waiting to fill, loaded when shown
+	pendingSplits   []tickerSplit     // Stock splits found but not yet applied or dismissed
+	filterBar       *tview.InputField   // Filter input while one is being typed
+	loadOrder       map[string]int      // Position of each holding and option as loaded
+	alerts          []query.Alert       // Alerts firing as of the last refresh
+	seenAlerts      map[string]bool     // Keys of the alerts already flashed
+	flashing        map[string]bool     // IDs of the rows flashing for new alerts
+	flashLeft       int                 // Flash steps left; the rows are lit on even steps
+	alertBell       bool                // Ring the terminal bell for new alerts
+	bellPending     bool                // Ring the bell on the next draw
+	notifier        *notify.Dispatcher  // Routes new alerts to notification channels
+	logPath         string // Log file shown in the logs view
+	crashOnce       sync.Once
+	tasks           sync.WaitGroup // Background tasks started with goSafe
+	tasksMu         sync.Mutex     // Orders adding tasks against stopping, so none is added once waited on
+	stopping        atomic.Bool    // Set once the event loop has exited
+	crashMessage    string // Set when a recovered panic stopped the TUI
+	clock           func() time.Time // Time source for rendering, nil means time.Now
+}
+
+// newApp builds the views over an empty book, read from store with quotes
+// from market; each view subscribes to the data it shows
+func newApp(store db.Store, market yahoo.Provider) *App {
+	a := &App{
+		book:  newBook(),
+		db:    store,
+		yahoo: market,
+		query: query.New(store, market),
+	}
+	a.holdingsView = newHoldingsView(a.book, a, &a.bus)
+	a.summaryView = newSummaryView(a.book, a.holdingsView, a, &a.bus)
+	a.optionsView = newOptionsView(a.book, a, &a.bus)
+	a.cspView = newCSPView(a.book, market, a)
+	return a
 }
 
 func main() {
+	if runOfflineCommand(os.Args[1:]) {
+		return
+	}
+
 	// Load .env file
 	godotenv.Load()
 
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		fmt.Println("DATABASE_URL not set. Please create a .env file with your Supabase connection string.")
-		fmt.Println("See .env.example for the format.")
-		os.Exit(1)
-	}
-
-	// Connect to database
-	database, err := db.New(dbURL)
-	if err != nil {
-		fmt.Printf("Failed to connect to database: %v\n", err)
-		os.Exit(1)
+	// Headless modes also log to stderr; the TUI only logs to the file
+	headless := len(os.Args) > 1 && (os.Args[1] == "daemon" || os.Args[1] == "serve")
+	logPath, logCloser := setupLogging(headless)
+	if logCloser != nil {
+		defer logCloser.Close()
 	}
-	defer database.Close()
-
-	app := &App{
-		db:              database,
-		yahoo:           yahoo.NewClient(),
-		quotes:          make(map[string]yahoo.Quote),
-		weeklyView:      true,  // Default to weekly view
-		autoRefresh:     true,  // Auto-refresh enabled by default
-		stopAutoRefresh: make(chan bool),
-		showExpired:     true,  // Show expired options by default
+	if profile := startCPUProfile(); profile != nil {
+		defer profile.Close()
 	}
 
-	app.run()
-}
-
-func (a *App) run() {
-	a.app = tview.NewApplication()
+	var store db.Store
+	role, demoMode := db.RoleOwner, false
+	if len(os.Args) > 1 && os.Args[1] == demoFlag {
+		// Sample data in memory; nothing is saved
+		demo, err := demoStore(time.Now())
+		if err != nil {
+			fmt.Printf("Failed to build the demo portfolio: %v\n", err)
+			os.Exit(1)
+		}
+		store, demoMode = demo, true
+	} else {
+		dbURL := os.Getenv("DATABASE_URL")
+		if dbURL == "" {
+			fmt.Println("DATABASE_URL not set. Please create a .env file with your Supabase connection string.")
+			fmt.Println("See .env.example for the format, or run with --demo to try the app on sample data.")
+			os.Exit(1)
+		}
 
-	// Set global button styles for better visibility
-	tview.Styles.PrimitiveBackgroundColor = tcell.ColorBlack
-	tview.Styles.ContrastBackgroundColor = tcell.ColorDarkSlateGray
-	tview.Styles.MoreContrastBackgroundColor = tcell.ColorGreen
-	tview.Styles.BorderColor = tcell.ColorWhite
-	tview.Styles.TitleColor = tcell.ColorWhite
-	tview.Styles.PrimaryTextColor = tcell.ColorWhite
-	tview.Styles.SecondaryTextColor = tcell.ColorYellow
+		// Connect to database
+		database, err := db.New(dbURL)
+		if err != nil {
+			fmt.Printf("Failed to connect to database: %v\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
 
-	// Create holdings table
-	a.table = tview.NewTable().
-		SetBorders(true).
-		SetSelectable(true, false).
-		SetFixed(1, 0).
-		SetSeparator(' ').
-		SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorDarkSlateGray))
+		// Viewer credentials get a store that rejects every write
+		store, role = accessStore(database)
+	}
 
-	a.table.SetSelectedFunc(func(row, column int) {
-		if row > 0 && row <= len(a.holdings) {
-			a.showHoldingActions(row - 1)
+	// One-shot backup, e.g. from cron
+	if len(os.Args) > 1 && os.Args[1] == "backup" {
+		if err := runBackup(store, os.Args[2:]); err != nil {
+			fmt.Printf("Backup failed: %v\n", err)
+			os.Exit(1)
 		}
-	})
+		return
+	}
 
-	// Create options table
-	a.optionsTable = tview.NewTable().
-		SetBorders(true).
-		SetSelectable(true, false).
-		SetFixed(1, 0).
-		SetSeparator(' ').
-		SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorDarkSlateGray))
+	// Price history is read from the database once synced; option chains
+	// are shared by the features that fetch them for a while
+	chains := yahoo.NewChainCache(yahoo.NewClient(), loadChainTTL(context.Background(), store))
+	client := withStoredHistory(store, chains)
 
-	a.optionsTable.SetSelectedFunc(func(row, column int) {
-		if row > 0 && row <= len(a.options) {
-			a.showOptionActions(row - 1)
+	// One-shot price history download, e.g. from cron
+	if len(os.Args) > 1 && os.Args[1] == "prices" {
+		if err := runPriceSync(store, client, os.Args[2:]); err != nil {
+			fmt.Printf("Price sync failed: %v\n", err)
+			os.Exit(1)
 		}
-	})
+		return
+	}
 
-	// Premium stats view
-	a.timeline = tview.NewTextView().
-		SetDynamicColors(true).
-		SetTextAlign(tview.AlignLeft)
-	a.timeline.SetBorder(true).SetTitle(" Option Premium Stats ").SetTitleAlign(tview.AlignLeft).SetBorderColor(tcell.ColorTeal)
+	// Headless mode: run background jobs instead of the TUI
+	if len(os.Args) > 1 && os.Args[1] == "status" {
+		if err := runStatus(store, client, os.Args[2:]); err != nil {
+			fmt.Printf("Status failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	// Visual expiry timeline
-	a.expiryTimeline = tview.NewTextView().
-		SetDynamicColors(true).
-		SetTextAlign(tview.AlignLeft)
-	a.expiryTimeline.SetBorder(true).SetTitle(" Expiry Timeline ").SetTitleAlign(tview.AlignLeft).SetBorderColor(tcell.ColorTeal)
+	if len(os.Args) > 1 && os.Args[1] == "daemon" {
+		newDaemon(store, client).run()
+		return
+	}
 
-	// Status bar
-	a.statusBar = tview.NewTextView().
-		SetDynamicColors(true).
-		SetText(" [yellow]a[white]:Add Holding  [yellow]o[white]:Add Option  [yellow]c[white]:Cash  [yellow]p[white]:CSP Advisor  [yellow]Tab[white]:Switch  [yellow]d[white]:Delete  [yellow]r[white]:Refresh  [yellow]w[white]:Week/Month  [yellow]q[white]:Quit")
+	// Serve mode: daemon plus an HTTP endpoint for webhooks
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		if role == db.RoleViewer {
+			fmt.Println("serve mode records trades and needs owner credentials")
+			os.Exit(1)
+		}
+		d := newDaemon(store, client)
+		if err := d.enableWebhooks(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		d.run()
+		return
+	}
 
-	// Summary bar (portfolio totals)
-	a.summary = tview.NewTextView().SetDynamicColors(true)
-	a.summary.SetBorder(true).SetTitle(" Portfolio ").SetTitleAlign(tview.AlignLeft).SetBorderColor(tcell.ColorTeal)
+	app := newApp(store, client)
+	app.role = role
+	app.chains = chains
+	app.autoRefresh = true // Auto-refresh enabled by default
+	app.stopAutoRefresh = make(chan bool)
+	app.logPath = logPath
+	app.demo = demoMode
 
-	// Holdings section (summary on top, then table) - will be auto-sized
-	a.holdingsSection = tview.NewFlex().
-		SetDirection(tview.FlexRow).
-		AddItem(a.summary, 3, 0, false).
-		AddItem(a.table, 0, 1, true)
+	app.run()
+	app.exitIfCrashed()
+}
 
-	// Options section (stats on top, then table, then timeline)
-	a.optionsSection = tview.NewFlex().
-		SetDirection(tview.FlexRow).
-		AddItem(a.timeline, 3, 0, false).
-		AddItem(a.optionsTable, 0, 2, false).
-		AddItem(a.expiryTimeline, 0, 1, false)
+func (a *App) run() {
+	a.app = tview.NewApplication()
+	a.initWidgets()
 
-	// Create header once and store it
-	a.header = a.createHeader()
+	// Key bindings
+	a.app.SetInputCapture(a.handleKey)
+
+	// Initial data load, resuming the last session's view
+	a.book.locale = loadLocale(context.Background(), a.db)
+	a.book.taxYear = a.query.TaxYear(context.Background())
+	a.book.precision = loadPrecision(context.Background(), a.db)
+	a.alertBell = loadAlertBell(context.Background(), a.db)
+	a.notifier = tuiNotifier(context.Background(), a.db)
+	a.book.baseCurrency = loadBaseCurrency(context.Background(), a.db)
+	session := a.loadSession(context.Background())
+	a.firstLoad()
+	a.app.SetRoot(a.pages, true).EnableMouse(true)
+	a.app.SetBeforeDrawFunc(a.ringBell)
+	a.restoreSession(session)
 
-	// Main layout - holdings auto-sized, options takes remaining space
-	a.mainFlex = tview.NewFlex().
-		SetDirection(tview.FlexRow).
-		AddItem(a.header, 8, 0, false).
-		AddItem(a.holdingsSection, 0, 1, true).
-		AddItem(a.optionsSection, 0, 2, false).
-		AddItem(a.statusBar, 1, 0, false)
+	// Start auto-refresh goroutine (30 second interval)
+	a.goSafe("auto-refresh", func() { a.autoRefreshLoop(30 * time.Second) })
+	a.goSafe("change watch", func() { a.changeWatchLoop(changeCheckInterval) })
+	a.goSafe("live sync", a.liveSyncLoop)
 
-	// Initialize CSP view
-	a.initCSPView()
+	// tview restores the terminal before re-panicking; this records the report
+	defer a.recoverPanic("event loop")
 
-	a.pages = tview.NewPages().
-		AddPage("main", a.mainFlex, true, true)
+	if err := a.app.Run(); err != nil {
+		panic(err)
+	}
+	a.shutdown()
+}
 
-	// Key bindings
-	a.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		name, _ := a.pages.GetFrontPage()
-
-		// ESC handling
-		if event.Key() == tcell.KeyEscape {
-			if name != "main" {
-				// Close current dialog
-				a.pages.RemovePage(name)
-				return nil
-			}
-			// Double-ESC to quit (within 500ms)
-			now := time.Now()
-			if now.Sub(a.lastEscTime) < 500*time.Millisecond {
-				a.app.Stop()
-				return nil
-			}
-			a.lastEscTime = now
-			return nil
-		}
+// handleKey runs the main screen's key bindings
+func (a *App) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	name, _ := a.pages.GetFrontPage()
 
-		// Only handle other shortcuts when on main page
+	// ESC handling
+	if event.Key() == tcell.KeyEscape {
 		if name != "main" {
+			// Close current dialog
+			a.pages.RemovePage(name)
+			return nil
+		}
+		// The filter bar handles its own ESC; otherwise ESC clears an active filter
+		if a.filterBar != nil {
 			return event
 		}
-
-		// Ctrl+C to quit
-		if event.Key() == tcell.KeyCtrlC {
-			a.app.Stop()
+		if a.clearFilters() {
 			return nil
 		}
-
-		// Tab to switch focus between tables
-		if event.Key() == tcell.KeyTab {
-			a.focusIndex = (a.focusIndex + 1) % 2
-			if a.focusIndex == 0 {
-				a.app.SetFocus(a.table)
-			} else {
-				a.app.SetFocus(a.optionsTable)
-			}
+		// Double-ESC to quit (within 500ms)
+		now := time.Now()
+		if now.Sub(a.lastEscTime) < 500*time.Millisecond {
+			a.app.Stop()
 			return nil
 		}
+		a.lastEscTime = now
+		return nil
+	}
 
-		switch event.Rune() {
-		case 'q':
-			a.app.Stop()
-			return nil
-		case 'p':
-			// Toggle CSP view
-			a.showCSP = !a.showCSP
-			if a.showCSP {
-				// Switch to CSP view
-				cspLayout := tview.NewFlex().
-					SetDirection(tview.FlexRow).
-					AddItem(a.header, 8, 0, false).
-					AddItem(a.cspSection, 0, 1, true)
-				a.pages.RemovePage("main")
-				a.pages.AddPage("main", cspLayout, true, true)
-				a.app.SetFocus(a.cspTable)
-				// Initialize CSP data
-				go a.refreshCSPData()
-			} else {
-				// Switch back to normal view
-				a.pages.RemovePage("main")
-				a.pages.AddPage("main", a.mainFlex, true, true)
-				a.app.SetFocus(a.table)
-			}
+	// Only handle other shortcuts when on main page, and not while typing a filter
+	if name != "main" || a.filterBar != nil {
+		return event
+	}
+
+	// Ctrl+C to quit
+	if event.Key() == tcell.KeyCtrlC {
+		a.app.Stop()
+		return nil
+	}
+
+	// Ctrl+P for the command palette
+	if event.Key() == tcell.KeyCtrlP {
+		a.showPalette()
+		return nil
+	}
+
+	// Tab to switch focus between tables
+	if event.Key() == tcell.KeyTab {
+		a.setFocusIndex((a.focusIndex + 1) % 2)
+		return nil
+	}
+
+	switch event.Rune() {
+	case 'q':
+		a.app.Stop()
+		return nil
+	case 'p':
+		a.setCSPView(!a.showCSP)
+		return nil
+	case 'a':
+		if a.readOnly() {
 			return nil
-		case 'a':
-			if a.showCSP {
-				a.showAddCSPWatchForm()
-			} else {
-				a.showAddForm()
-			}
+		}
+		if a.showCSP {
+			a.showAddCSPWatchForm()
+		} else {
+			a.showAddForm(nil, nil)
+		}
+		return nil
+	case 'o':
+		if !a.showCSP && !a.readOnly() {
+			a.showAddOptionForm(nil, nil)
+		}
+		return nil
+	case 'c':
+		if !a.showCSP && !a.readOnly() {
+			a.showCashForm()
+		}
+		return nil
+	case 'd':
+		if a.readOnly() {
 			return nil
-		case 'o':
-			if !a.showCSP {
-				a.showAddOptionForm()
+		}
+		if a.showCSP {
+			row, _ := a.cspView.table.GetSelection()
+			if row > 0 && row <= len(a.cspView.watchlist) {
+				a.showRemoveCSPWatchConfirm(row - 1)
 			}
-			return nil
-		case 'c':
-			if !a.showCSP {
-				a.showCashForm()
+		} else if a.focusIndex == 0 {
+			if i, ok := a.holdingsView.selected(); ok {
+				a.confirmDelete(i)
 			}
-			return nil
-		case 'd':
-			if a.showCSP {
-				row, _ := a.cspTable.GetSelection()
-				if row > 0 && row <= len(a.cspWatchlist) {
-					a.showRemoveCSPWatchConfirm(row - 1)
-				}
-			} else if a.focusIndex == 0 {
-				row, _ := a.table.GetSelection()
-				if row > 0 && row <= len(a.holdings) {
-					a.confirmDelete(row - 1)
-				}
-			} else {
-				row, _ := a.optionsTable.GetSelection()
-				if row > 0 && row <= len(a.options) {
-					a.confirmDeleteOption(row - 1)
-				}
+		} else {
+			if i, ok := a.optionsView.selected(); ok {
+				a.confirmDeleteOption(i)
 			}
+		}
+		return nil
+	case 'r':
+		if a.showCSP {
+			a.refreshCSPData()
+		} else {
+			a.refreshData()
+		}
+		return nil
+	case 'R':
+		if !a.showCSP {
+			a.autoRefresh = !a.autoRefresh
+			a.updateStatusBar()
+		}
+		return nil
+	case 'w':
+		if !a.showCSP {
+			a.optionsView.weeklyView = !a.optionsView.weeklyView
+			a.optionsView.updateTimeline()
+		}
+		return nil
+	case 'e':
+		if !a.showCSP {
+			a.toggleStatus("EXPIRED")
+		} else if !a.readOnly() {
+			row, _ := a.cspView.table.GetSelection()
+			a.cycleCSPExpiries(row - 1)
+		}
+		return nil
+	case '1', '2', '3', '4':
+		if !a.showCSP {
+			a.toggleStatus(optionStatuses[event.Rune()-'1'])
+		}
+		return nil
+	case 'b':
+		if !a.showCSP {
+			a.showBetaView()
+		}
+		return nil
+	case 'v':
+		if !a.showCSP {
+			a.showDividendView()
+		}
+		return nil
+	case 'E':
+		a.showEarningsView()
+		return nil
+	case 'C':
+		if !a.showCSP {
+			a.showCashDragView()
+		}
+		return nil
+	case 'g':
+		if !a.showCSP {
+			a.showPerformanceView()
+		}
+		return nil
+	case 'i':
+		if a.readOnly() {
 			return nil
-		case 'r':
-			if a.showCSP {
-				a.refreshCSPData()
-			} else {
-				a.refreshData()
-			}
+		}
+		if a.showCSP {
+			a.acceptCSPIdea()
+		} else {
+			a.showPasteImportForm()
+		}
+		return nil
+	case 'u':
+		a.showIdeasView()
+		return nil
+	case 'j':
+		a.showReviewView()
+		return nil
+	case 'n':
+		if !a.showCSP && !a.readOnly() {
+			a.showTagsForm()
+		}
+		return nil
+	case 'l':
+		if !a.showCSP {
+			a.toggleTagsColumn()
+		}
+		return nil
+	case 'z':
+		if !a.showCSP && a.focusIndex == 0 && !a.readOnly() {
+			a.toggleArchived()
+		}
+		return nil
+	case 'Z':
+		if !a.showCSP {
+			a.toggleShowArchived()
+		}
+		return nil
+	case 'G':
+		if !a.showCSP {
+			a.showSectorView()
+		}
+		return nil
+	case 'V':
+		if !a.showCSP {
+			a.showEquityCurve()
+		}
+		return nil
+	case 'O':
+		if !a.showCSP && !a.readOnly() {
+			a.showOptionHistoryImport()
+		}
+		return nil
+	case 'Q':
+		if !a.showCSP && !a.readOnly() {
+			a.showHoldingsImport()
+		}
+		return nil
+	case 'L':
+		a.showLogsView()
+		return nil
+	case 'N':
+		a.showRemindersView()
+		return nil
+	case 'W':
+		if !a.showCSP {
+			a.showWatchlistView()
+		}
+		return nil
+	case 'H':
+		a.toggleBanner()
+		return nil
+	case 'h':
+		if !a.showCSP {
+			a.showLedgerView()
+		}
+		return nil
+	case 'B':
+		if !a.showCSP {
+			a.showAccountsView()
+		}
+		return nil
+	case 'D':
+		if a.readOnly() || a.showCSP || a.focusIndex != 0 {
 			return nil
-		case 'R':
-			if !a.showCSP {
-				a.autoRefresh = !a.autoRefresh
-				a.updateStatusBar()
-			}
+		}
+		if i, ok := a.holdingsView.selected(); ok {
+			a.showDividendForm(i)
+		}
+		return nil
+	case 'Y':
+		if !a.showCSP {
+			a.toggleIncomeLine()
+		}
+		return nil
+	case 'X':
+		if !a.showCSP {
+			a.showBackupForm()
+		}
+		return nil
+	case 'S':
+		if !a.showCSP && !a.readOnly() {
+			a.showSettingsForm()
+		}
+		return nil
+	case 'K':
+		a.showRiskCapsView()
+		return nil
+	case 'A':
+		a.showPolicyActionsView()
+		return nil
+	case 'M':
+		if !a.showCSP {
+			a.showMarginView()
+		}
+		return nil
+	case '/':
+		if !a.showCSP {
+			a.showFilterBar()
+		}
+		return nil
+	case 'f':
+		if !a.showCSP && !a.readOnly() {
+			a.togglePin()
+		}
+		return nil
+	case 'U':
+		a.showColumnsView()
+		return nil
+	case 'I':
+		a.showSurfaceForm()
+		return nil
+	case 'P':
+		if a.showCSP || a.focusIndex != 1 {
 			return nil
-		case 'w':
-			if !a.showCSP {
-				a.weeklyView = !a.weeklyView
-				a.updateTimeline()
-			}
+		}
+		if i, ok := a.optionsView.selected(); ok {
+			a.showPayoffView(i)
+		}
+		return nil
+	case 'J':
+		if !a.showCSP && !a.readOnly() {
+			a.reviewSplits()
+		}
+		return nil
+	case 'T':
+		if a.showCSP || a.focusIndex != 1 || a.readOnly() {
 			return nil
-		case 'e':
-			if !a.showCSP {
-				a.showExpired = !a.showExpired
-				a.updateOptionsTable()
-				a.updateStatusBar()
-			}
+		}
+		if i, ok := a.optionsView.selected(); ok {
+			a.showTakeProfit(i)
+		}
+		return nil
+	case 'y':
+		if !a.readOnly() && !a.showCSP {
+			a.retryStaleSymbols()
+		}
+		return nil
+	case 'F':
+		if !a.showCSP {
+			a.retryFailedQuotes()
+		}
+		return nil
+	case 'm':
+		if a.readOnly() || a.showCSP {
 			return nil
 		}
-		return event
-	})
+		ticker := ""
+		if i, ok := a.holdingsView.selected(); a.focusIndex == 0 && ok {
+			ticker = a.book.holdings[i].Ticker
+		}
+		a.showManualPriceForm(ticker)
+		return nil
+	case 'x':
+		if a.showCSP {
+			a.showCSPExportForm()
+		}
+		return nil
+	case 't':
+		if a.showCSP {
+			a.showTradingViewForm()
+		}
+		return nil
+	case 's':
+		if a.showCSP {
+			a.showSignalsView()
+		}
+		return nil
+	}
+	return event
+}
 
-	// Initial data load
-	a.refreshData()
+// bannerHeight is the height of the ASCII banner header, 0 while collapsed
+func (a *App) bannerHeight() int {
+	if a.hideBanner {
+		return 0
+	}
+	return 8
+}
 
-	// Start auto-refresh goroutine (30 second interval)
-	go a.autoRefreshLoop(30 * time.Second)
+// toggleBanner collapses or expands the banner header on the current page
+func (a *App) toggleBanner() {
+	a.hideBanner = !a.hideBanner
+	if layout, ok := a.pages.GetPage("main").(*tview.Flex); ok {
+		layout.ResizeItem(a.header, a.bannerHeight(), 0)
+	}
+}
 
-	if err := a.app.SetRoot(a.pages, true).EnableMouse(true).Run(); err != nil {
-		panic(err)
+// setCSPView switches the main page between the portfolio and the CSP advisor
+func (a *App) setCSPView(show bool) {
+	a.showCSP = show
+	a.pages.RemovePage("main")
+	if show {
+		cspLayout := tview.NewFlex().
+			SetDirection(tview.FlexRow).
+			AddItem(a.header, a.bannerHeight(), 0, false).
+			AddItem(a.cspView.section, 0, 1, true)
+		a.pages.AddPage("main", cspLayout, true, true)
+		a.app.SetFocus(a.cspView.table)
+		// Initialize CSP data
+		a.goSafe("csp refresh", a.refreshCSPData)
+	} else {
+		a.pages.AddPage("main", a.mainFlex, true, true)
+		a.app.SetFocus(a.holdingsView.table)
+	}
+}
+
+// setFocusIndex focuses the holdings table (0) or the options table (1)
+func (a *App) setFocusIndex(i int) {
+	a.focusIndex = i
+	if i == 0 {
+		a.app.SetFocus(a.holdingsView.table)
+	} else {
+		a.app.SetFocus(a.optionsView.table)
 	}
 }
 
@@ -330,7 +645,7 @@ func (a *App) autoRefreshLoop(interval time.Duration) {
 		select {
 		case <-ticker.C:
 			if a.autoRefresh {
-				a.app.QueueUpdateDraw(func() {
+				a.queueUpdateDraw(func() {
 					a.refreshData()
 				})
 			}
@@ -340,6 +655,91 @@ func (a *App) autoRefreshLoop(interval time.Duration) {
 	}
 }
 
+// initWidgets lays out the views' widgets with the status bar and header,
+// without touching the terminal, so rendering can be exercised against a
+// simulated screen
+func (a *App) initWidgets() {
+	// Set global button styles for better visibility
+	tview.Styles.PrimitiveBackgroundColor = tcell.ColorBlack
+	tview.Styles.ContrastBackgroundColor = tcell.ColorDarkSlateGray
+	tview.Styles.MoreContrastBackgroundColor = tcell.ColorGreen
+	tview.Styles.BorderColor = tcell.ColorWhite
+	tview.Styles.TitleColor = tcell.ColorWhite
+	tview.Styles.PrimaryTextColor = tcell.ColorWhite
+	tview.Styles.SecondaryTextColor = tcell.ColorYellow
+
+	a.holdingsView.table.SetSelectedFunc(func(row, column int) {
+		if i, ok := rowIndex(a.holdingsView.rows, row); ok {
+			a.showHoldingDetail(i)
+		}
+	})
+	a.holdingsView.table.SetSelectionChangedFunc(func(row, column int) {
+		a.summaryView.setTitle()
+	})
+
+	a.optionsView.table.SetSelectedFunc(func(row, column int) {
+		if i, ok := rowIndex(a.optionsView.rows, row); ok {
+			a.showOptionActions(i)
+		}
+	})
+
+	// Status bar
+	a.statusBar = tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(" [yellow]a[white]:Add Holding  [yellow]o[white]:Add Option  [yellow]c[white]:Cash  [yellow]p[white]:CSP Advisor  [yellow]Tab[white]:Switch  [yellow]d[white]:Delete  [yellow]r[white]:Refresh  [yellow]w[white]:Week/Month  [yellow]q[white]:Quit")
+
+	// Holdings section (summary on top, then table) - will be auto-sized
+	a.holdingsSection = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(a.summaryView.text, 3, 0, false).
+		AddItem(a.holdingsView.table, 0, 1, true)
+
+	// Options section (stats on top, then table, then timeline)
+	a.optionsSection = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(a.optionsView.timeline, 3, 0, false).
+		AddItem(a.optionsView.expiryWeek, 3, 0, false).
+		AddItem(a.optionsView.title, 1, 0, false).
+		AddItem(a.optionsView.table, 0, 2, false).
+		AddItem(a.optionsView.expiryTimeline, 0, 1, false)
+
+	// Create header once and store it
+	a.header = a.createHeader()
+
+	// Main layout - holdings auto-sized, options takes remaining space
+	a.mainFlex = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(a.header, a.bannerHeight(), 0, false).
+		AddItem(a.holdingsSection, 0, 1, true).
+		AddItem(a.optionsSection, 0, 2, false).
+		AddItem(a.statusBar, 1, 0, false)
+
+	// The layout fits the tables once the views have re-rendered
+	a.bus.subscribe(eventDataLoaded, a.updateLayout)
+
+	a.pages = tview.NewPages().
+		AddPage("main", a.mainFlex, true, true)
+}
+
+// now returns the current time, or the injected clock's time in tests
+func (a *App) now() time.Time {
+	if a.clock != nil {
+		return a.clock()
+	}
+	return time.Now()
+}
+
+// draw redraws the screen from a background task
+func (a *App) draw() {
+	a.app.Draw()
+}
+
+// stopped reports whether the event loop has exited, for background tasks
+// to give up
+func (a *App) stopped() bool {
+	return a.stopping.Load()
+}
+
 func (a *App) createHeader() *tview.TextView {
 	ascii := "\n[teal::b]" +
 		" █████╗ ███╗   ██╗██╗   ██╗██╗  ██╗ ██████╗ ██╗    ██╗██╗  ██╗ ██████╗ ██████╗ ██╗     \n" +
@@ -360,41 +760,71 @@ func (a *App) refreshData() {
 	a.app.ForceDraw()
 
 	ctx := context.Background()
+	live, ok := a.loadData(ctx, true)
+	if !ok {
+		return
+	}
+	quotes, err := a.fetchQuotes(live)
+	a.applyQuotes(ctx, live, quotes, err)
+	a.finishRefresh(ctx)
+}
+
+// loadData reads the portfolio from the database and returns the tickers to
+// fetch quotes for. With processExpired, expired options are assigned or
+// expired first. Reports false if the holdings could not be read.
+func (a *App) loadData(ctx context.Context, processExpired bool) ([]string, bool) {
+	// Process expired options first (auto-assign or expire based on ITM/OTM),
+	// in every account, so the holdings and cash read next include them.
+	// Viewers see them as they are until the owner's session processes them
+	if processExpired && a.role != db.RoleViewer {
+		a.processExpiredOptions(ctx)
+	}
 
 	// Get holdings from DB
 	holdings, err := a.db.GetHoldings(ctx)
 	if err != nil {
 		a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
-		return
+		return nil, false
 	}
-	a.holdings = holdings
+	a.book.holdings = holdings
 
 	// Get available cash
 	cash, err := a.db.GetAvailableCash(ctx)
 	if err != nil {
 		cash = decimal.Zero
 	}
-	a.cash = cash
+	a.book.cash = cash
+
+	// Other accounts count towards the totals and need quotes too
+	accountTickers := a.loadAccounts(ctx)
 
-	// Process expired options first (auto-assign or expire based on ITM/OTM)
-	a.processExpiredOptions(ctx)
+	// Record today's cash balance, across accounts, for idle-cash tracking.
+	// Viewers cannot write it; the owner's session does.
+	if a.role != db.RoleViewer {
+		a.cashSnapshotErr = a.db.RecordCashSnapshot(ctx, a.totalCash())
+	}
 
 	// Get active options
 	options, err := a.db.GetActiveOptions(ctx)
 	if err != nil {
 		options = []db.Option{}
 	}
-	a.options = options
+	a.book.options = options
 
-	// Get premium summary for current year
-	currentYear := time.Now().Year()
-	premiums, err := a.db.GetPremiumsByYear(ctx, currentYear)
+	// Get premium summary for the current tax year
+	yearStart, yearEnd := a.book.taxYear.Bounds(a.now())
+	premiums, err := a.db.GetPremiumsByCurrency(ctx, yearStart, yearEnd)
+	if err != nil {
+		premiums = nil
+	}
+	a.book.premiumsByCurrency = premiums
+	tickerPremiums, err := a.db.GetNetPremiumsByTicker(ctx, yearStart, yearEnd)
 	if err != nil {
-		premiums = &db.PremiumSummary{}
+		tickerPremiums = map[string]decimal.Decimal{}
 	}
-	a.premiums = premiums
+	a.book.tickerPremiums = tickerPremiums
 
-	// Get unique tickers
+	// Get unique tickers (holdings plus active option underlyings)
 	tickers := make([]string, 0)
 	tickerMap := make(map[string]bool)
 	for _, h := range holdings {
@@ -403,22 +833,98 @@ func (a *App) refreshData() {
 			tickerMap[h.Ticker] = true
 		}
 	}
+	for _, o := range options {
+		if o.Status == "ACTIVE" && !tickerMap[o.Ticker] {
+			tickers = append(tickers, o.Ticker)
+			tickerMap[o.Ticker] = true
+		}
+	}
 
-	// Fetch quotes
-	if len(tickers) > 0 {
-		quotes, err := a.yahoo.GetQuotes(tickers)
-		if err != nil {
-			a.statusBar.SetText(fmt.Sprintf(" [yellow]Prices unavailable: %v", err))
-		} else {
-			a.quotes = quotes
+	for _, t := range accountTickers {
+		if !tickerMap[t] {
+			tickers = append(tickers, t)
+			tickerMap[t] = true
 		}
 	}
 
-	a.updateTable()
-	a.updateOptionsTable()
-	a.updateTimeline()
-	a.updateLayout()
-	a.lastRefresh = time.Now()
+	// Rates for the holdings and options in other currencies
+	tickers = append(tickers, a.fxSymbols()...)
+
+	// Quotes are fetched for all but symbols that look delisted or halted
+	a.loadStaleSymbols(ctx)
+	return a.liveTickers(tickers), true
+}
+
+// fetchQuotes fetches quotes for tickers. It only touches the network, so it
+// may run off the event loop.
+func (a *App) fetchQuotes(tickers []string) (map[string]yahoo.Quote, error) {
+	if len(tickers) == 0 {
+		return nil, nil
+	}
+	return a.yahoo.GetQuotes(tickers)
+}
+
+// applyQuotes takes the quotes fetched for live, tracking the symbols that
+// failed, and caches them for the next launch. On error the last quotes stay.
+func (a *App) applyQuotes(ctx context.Context, live []string, quotes map[string]yahoo.Quote, err error) {
+	a.book.failedQuotes = nil
+	if len(live) == 0 {
+		return
+	}
+	if err != nil {
+		a.book.failedQuotes = live
+		a.statusBar.SetText(fmt.Sprintf(" [yellow]Prices unavailable: %v", err))
+		return
+	}
+	a.book.failedQuotes = missingQuotes(live, quotes)
+	if a.role != db.RoleViewer {
+		a.trackQuoteFailures(ctx, live, quotes)
+		a.loadStaleSymbols(ctx)
+		a.saveQuoteCache(ctx, quotes)
+	}
+	a.book.quotes = quotes
+}
+
+// renderData fills in missing prices and redraws the tables and panels from
+// the loaded data
+func (a *App) renderData(ctx context.Context) {
+	a.applyManualPrices(ctx)
+	a.applyStalePrices()
+	a.loadFXRates()
+	a.book.premiums = a.book.premiumsInBase(a.book.premiumsByCurrency)
+	a.loadRiskCaps(ctx)
+	a.loadTargetWeights(ctx)
+	a.loadPolicies(ctx)
+	a.loadReminders(ctx)
+	a.loadDividends(ctx)
+	a.loadSales(ctx)
+	a.loadCustomColumns(ctx)
+	a.loadHoldingsColumns(ctx)
+	a.loadPinned(ctx)
+	a.loadTags(ctx)
+
+	a.bus.publish(eventDataLoaded)
+	a.refreshOptionIVs()
+}
+
+// finishRefresh renders the refreshed data and records it
+func (a *App) finishRefresh(ctx context.Context) {
+	a.renderData(ctx)
+
+	// Record today's portfolio value, across accounts, for performance tracking
+	if a.role != db.RoleViewer {
+		a.valueSnapshotErr = a.db.RecordPortfolioSnapshot(ctx, a.totalHoldingsValue(), a.totalCash(), a.totalOptionsCredit())
+	}
+
+	// Remember what this refresh saw, so edits from other sessions stand out
+	if stamp, err := a.db.GetChangeStamp(ctx); err == nil {
+		a.changeStamp = stamp
+	}
+
+	a.lastRefresh = a.now()
+	a.loadDividendHistories()
+	a.loadEarningsDates()
+	a.checkAlerts()
 	a.updateStatusBar()
 }
 
@@ -429,1336 +935,104 @@ func (a *App) updateStatusBar() {
 		autoStatus = "[lime]ON"
 	}
 	expiredStatus := "[red]OFF"
-	if a.showExpired {
+	if a.optionsView.showExpired {
 		expiredStatus = "[lime]ON"
 	}
-	a.statusBar.SetText(fmt.Sprintf(" [gray]Updated %s[white] | [yellow]Auto[white]:%s | [yellow]Expired[white]:%s | [yellow]a[white]:Add  [yellow]o[white]:Option  [yellow]c[white]:Cash  [yellow]p[white]:CSP  [yellow]Tab[white]:Switch  [yellow]d[white]:Del  [yellow]r[white]:Refresh  [yellow]R[white]:Auto  [yellow]e[white]:Expired  [yellow]w[white]:View  [yellow]q[white]:Quit", refreshTime, autoStatus, expiredStatus))
+	notices := ""
+	if a.role == db.RoleViewer {
+		notices = "[yellow]VIEWER (read-only)[white] | "
+	}
+	if a.demo {
+		notices = "[yellow]DEMO (nothing is saved)[white] | "
+	}
+	if a.cashSnapshotErr != nil {
+		notices += fmt.Sprintf("[red]Cash history not recorded: %s[white] | ", tview.Escape(a.cashSnapshotErr.Error()))
+	}
+	if a.valueSnapshotErr != nil {
+		notices += fmt.Sprintf("[red]Portfolio history not recorded: %s[white] | ", tview.Escape(a.valueSnapshotErr.Error()))
+	}
+	if n := len(a.alerts); n > 0 {
+		notices += fmt.Sprintf("[red]%d alert(s): %s[white] | ", n, tview.Escape(a.alerts[0].Message))
+	}
+	if n := len(a.policyActions); n > 0 {
+		notices += fmt.Sprintf("[orange]%d queued action(s), A to review[white] | ", n)
+	}
+	if n := a.dueReminders(); n > 0 {
+		notices += fmt.Sprintf("[aqua]%d reminder(s) due, N to open[white] | ", n)
+	}
+	if n := len(a.pendingSplits); n > 0 {
+		notices += fmt.Sprintf("[aqua]%d stock split(s) to apply, J to review[white] | ", n)
+	}
+	a.statusBar.SetText(fmt.Sprintf(" %s[gray]Updated %s[white] | [yellow]Auto[white]:%s | [yellow]Expired[white]:%s | [yellow]a[white]:Add  [yellow]o[white]:Option  [yellow]c[white]:Cash  [yellow]p[white]:CSP  [yellow]Tab[white]:Switch  [yellow]d[white]:Del  [yellow]r[white]:Refresh  [yellow]R[white]:Auto  [yellow]e[white]:Expired  [yellow]1-4[white]:Statuses  [yellow]w[white]:View  [yellow]b[white]:Beta  [yellow]G[white]:Sectors  [yellow]v[white]:Dividends  [yellow]E[white]:Earnings  [yellow]D[white]:Record Dividend  [yellow]C[white]:Cash Drag  [yellow]g[white]:Performance  [yellow]V[white]:Equity  [yellow]i[white]:Import  [yellow]O[white]:Option History  [yellow]Q[white]:Import Holdings  [yellow]X[white]:Backup  [yellow]S[white]:Settings  [yellow]K[white]:Caps  [yellow]A[white]:Policies  [yellow]M[white]:Margin  [yellow]P[white]:Payoff  [yellow]T[white]:Take Profit  [yellow]I[white]:IV Surface  [yellow]U[white]:Columns  [yellow]/[white]:Filter  [yellow]f[white]:Pin  [yellow]n[white]:Tags  [yellow]l[white]:Tags Column  [yellow]z[white]:Archive  [yellow]Z[white]:Show Archived  [yellow]m[white]:Manual Price  [yellow]y[white]:Retry Halted  [yellow]F[white]:Retry Failed  [yellow]L[white]:Logs  [yellow]N[white]:Reminders  [yellow]W[white]:Watchlist  [yellow]u[white]:Ideas  [yellow]j[white]:Weekly Review  [yellow]J[white]:Splits  [yellow]H[white]:Banner  [yellow]Y[white]:Income  [yellow]B[white]:Accounts  [yellow]h[white]:History  [yellow]^P[white]:Actions  [yellow]q[white]:Quit", notices, refreshTime, autoStatus, expiredStatus))
 }
 
+// maxHoldingsRows caps the height of the holdings table. Larger portfolios
+// scroll inside it, so the options section keeps its room.
+const maxHoldingsRows = 10
+
 func (a *App) updateLayout() {
-	// Calculate exact table height to show all holdings without scrolling
+	// Calculate exact table height to show all holdings without scrolling, up to maxHoldingsRows
 	// Table with borders needs: top border (1) + header (1) + separator rows + data rows + bottom border (1)
 	// With SetBorders(true), each row has a separator, so: 1 + (rows+1)*2 - 1 = rows*2 + 2
 	// Simplified: header + all data rows with separators + borders
-	numRows := len(a.holdings)
+	numRows := min(len(a.book.holdings), maxHoldingsRows)
 	tableHeight := (numRows * 2) + 4 // Each row takes 2 lines (content + separator) + header area
 	if tableHeight < 5 {
 		tableHeight = 5
 	}
 
-	// Holdings section height: summary (3) + table
-	holdingsHeight := 3 + tableHeight
+	// Holdings section height: summary (3, or 4 with the income line) + table
+	holdingsHeight := a.summaryHeight() + tableHeight
 
 	a.holdingsSection.Clear()
 	a.holdingsSection.
-		AddItem(a.summary, 3, 0, false).
-		AddItem(a.table, tableHeight, 0, false)
+		AddItem(a.summaryView.text, a.summaryHeight(), 0, false).
+		AddItem(a.holdingsView.table, tableHeight, 0, false)
 
 	// Calculate timeline height based on active options count
 	numActiveOptions := 0
-	for _, o := range a.options {
+	for _, o := range a.book.options {
 		if o.Status == "ACTIVE" {
 			numActiveOptions++
 		}
 	}
-	// Timeline needs: border (2) + Today marker (1) + header row (1) + separator (1) + one line per option
-	timelineHeight := numActiveOptions + 5
-	if timelineHeight < 6 {
-		timelineHeight = 6
+	// Timeline needs: border (2) + Today marker (1) + header row (1) + premium row (1) + separator (1) + one line per option,
+	// then the cash forecast under them
+	timelineHeight := numActiveOptions + 6
+	if numActiveOptions > 0 {
+		timelineHeight += cashForecastRows
+	}
+	if timelineHeight < 7 {
+		timelineHeight = 7
 	}
 
 	// Rebuild options section with fixed timeline height
 	a.optionsSection.Clear()
 	a.optionsSection.
-		AddItem(a.timeline, 3, 0, false).
-		AddItem(a.optionsTable, 0, 1, false).
-		AddItem(a.expiryTimeline, timelineHeight, 0, false)
+		AddItem(a.optionsView.timeline, 3, 0, false).
+		AddItem(a.optionsView.expiryWeek, 3, 0, false).
+		AddItem(a.optionsView.title, 1, 0, false).
+		AddItem(a.optionsView.table, 0, 1, false).
+		AddItem(a.optionsView.expiryTimeline, timelineHeight, 0, false)
 
 	// Rebuild main flex with fixed holdings height, options takes rest
 	a.mainFlex.Clear()
 	a.mainFlex.
-		AddItem(a.header, 8, 0, false).
+		AddItem(a.header, a.bannerHeight(), 0, false).
 		AddItem(a.holdingsSection, holdingsHeight, 0, false).
 		AddItem(a.optionsSection, 0, 1, false).
-		AddItem(a.statusBar, 1, 0, false)
+		AddItem(a.bottomBar(), 1, 0, a.filterBar != nil)
 }
 
-func (a *App) updateTable() {
-	a.table.Clear()
-
-	// Header row - cyan color scheme
-	headers := []string{"TICKER", "QTY", "AVG COST", "PRICE", "VALUE", "P/L", "P/L %", "WEIGHT", "vs HIGH", "SIGNAL"}
-	for i, h := range headers {
-		cell := tview.NewTableCell(" "+h+" ").
-			SetTextColor(tcell.ColorBlack).
-			SetBackgroundColor(tcell.ColorTeal).
-			SetAlign(tview.AlignLeft).
-			SetSelectable(false).
-			SetExpansion(1)
-		a.table.SetCell(0, i, cell)
-	}
-
-	// Build map of lowest active SELL CALL strike per ticker (for capping value)
-	callCaps := make(map[string]decimal.Decimal)
-	for _, o := range a.options {
-		if o.Status == "ACTIVE" && o.OptionType == "CALL" && o.Action == "SELL" {
-			if existing, ok := callCaps[o.Ticker]; ok {
-				if o.Strike.LessThan(existing) {
-					callCaps[o.Ticker] = o.Strike
-				}
-			} else {
-				callCaps[o.Ticker] = o.Strike
-			}
-		}
-	}
+// settlements are the settlement types offered by the option forms
+var settlements = []string{db.SettlementPhysical, db.SettlementCash}
 
-	// First pass: calculate total portfolio value
-	var totalCost, totalValue decimal.Decimal
-	positionValues := make([]decimal.Decimal, len(a.holdings))
-
-	for i, h := range a.holdings {
-		quote, hasQuote := a.quotes[h.Ticker]
-		costBasis := h.Quantity.Mul(h.AvgCost)
-		totalCost = totalCost.Add(costBasis)
-
-		if hasQuote {
-			price := decimal.NewFromFloat(quote.Price)
-
-			// Cap price at call strike if there's an active covered call
-			if cap, hasCap := callCaps[h.Ticker]; hasCap && price.GreaterThan(cap) {
-				price = cap
-			}
-
-			value := h.Quantity.Mul(price)
-			positionValues[i] = value
-			totalValue = totalValue.Add(value)
-		} else {
-			positionValues[i] = costBasis
-			totalValue = totalValue.Add(costBasis)
-		}
-	}
-
-	// Second pass: populate table with weight %
-	for i, h := range a.holdings {
-		row := i + 1
-		rowBg := tcell.ColorBlack
-
-		// Ticker - magenta/purple for visibility
-		a.table.SetCell(row, 0, tview.NewTableCell(" "+h.Ticker+" ").
-			SetTextColor(tcell.ColorFuchsia).
-			SetBackgroundColor(rowBg).
-			SetAlign(tview.AlignLeft).
-			SetExpansion(1))
-
-		// Quantity
-		a.table.SetCell(row, 1, tview.NewTableCell(" "+formatNumber(h.Quantity.StringFixed(2))+" ").
-			SetTextColor(tcell.ColorWhite).
-			SetBackgroundColor(rowBg).
-			SetAlign(tview.AlignLeft).
-			SetExpansion(1))
-
-		// Avg Cost
-		a.table.SetCell(row, 2, tview.NewTableCell(" $"+formatNumber(h.AvgCost.StringFixed(2))+" ").
-			SetTextColor(tcell.ColorWhite).
-			SetBackgroundColor(rowBg).
-			SetAlign(tview.AlignLeft).
-			SetExpansion(1))
-
-		quote, hasQuote := a.quotes[h.Ticker]
-		costBasis := h.Quantity.Mul(h.AvgCost)
-		value := positionValues[i]
-
-		// Calculate weight
-		weight := decimal.Zero
-		if !totalValue.IsZero() {
-			weight = value.Div(totalValue).Mul(decimal.NewFromInt(100))
-		}
-
-		if hasQuote {
-			price := decimal.NewFromFloat(quote.Price)
-			pl := value.Sub(costBasis)
-			plPct := decimal.Zero
-			if !costBasis.IsZero() {
-				plPct = pl.Div(costBasis).Mul(decimal.NewFromInt(100))
-			}
-
-			// Price - cyan
-			a.table.SetCell(row, 3, tview.NewTableCell(" $"+formatNumber(price.StringFixed(2))+" ").
-				SetTextColor(tcell.ColorAqua).
-				SetBackgroundColor(rowBg).
-				SetAlign(tview.AlignLeft).
-				SetExpansion(1))
-
-			// Value - yellow
-			a.table.SetCell(row, 4, tview.NewTableCell(" $"+formatNumber(value.StringFixed(2))+" ").
-				SetTextColor(tcell.ColorYellow).
-				SetBackgroundColor(rowBg).
-				SetAlign(tview.AlignLeft).
-				SetExpansion(1))
-
-			// P/L
-			plColor := tcell.ColorWhite
-			if pl.IsPositive() {
-				plColor = tcell.ColorLime
-			} else if pl.IsNegative() {
-				plColor = tcell.ColorRed
-			}
-			plSign := ""
-			if pl.IsPositive() {
-				plSign = "+"
-			}
-			a.table.SetCell(row, 5, tview.NewTableCell(" "+plSign+"$"+formatNumber(pl.StringFixed(2))+" ").
-				SetTextColor(plColor).
-				SetBackgroundColor(rowBg).
-				SetAlign(tview.AlignLeft).
-				SetExpansion(1))
-
-			// P/L %
-			pctSign := ""
-			if plPct.IsPositive() {
-				pctSign = "+"
-			}
-			a.table.SetCell(row, 6, tview.NewTableCell(" "+pctSign+formatNumber(plPct.StringFixed(2))+"% ").
-				SetTextColor(plColor).
-				SetBackgroundColor(rowBg).
-				SetAlign(tview.AlignLeft).
-				SetExpansion(1))
-
-			// Weight % - orange if > 25%, red if > 40%
-			weightColor := tcell.ColorWhite
-			if weight.GreaterThan(decimal.NewFromInt(40)) {
-				weightColor = tcell.ColorRed
-			} else if weight.GreaterThan(decimal.NewFromInt(25)) {
-				weightColor = tcell.ColorOrange
-			}
-			a.table.SetCell(row, 7, tview.NewTableCell(" "+formatNumber(weight.StringFixed(1))+"% ").
-				SetTextColor(weightColor).
-				SetBackgroundColor(rowBg).
-				SetAlign(tview.AlignLeft).
-				SetExpansion(1))
-
-			// % from 52-week high - green if big dip (buying opportunity)
-			pctFromHigh := quote.PctFromHigh
-			highPrice := decimal.NewFromFloat(quote.FiftyTwoWeekHigh)
-			highColor := tcell.ColorWhite
-			highText := fmt.Sprintf(" %.1f%% ($%s) ", pctFromHigh, formatNumber(highPrice.StringFixed(2)))
-			if pctFromHigh <= -20 {
-				highColor = tcell.ColorLime // Big dip - potential buy
-			} else if pctFromHigh <= -10 {
-				highColor = tcell.ColorYellow // Moderate dip
-			}
-			a.table.SetCell(row, 8, tview.NewTableCell(highText).
-				SetTextColor(highColor).
-				SetBackgroundColor(rowBg).
-				SetAlign(tview.AlignLeft).
-				SetExpansion(1))
-
-			// SIGNAL - take-profit signals (priority order)
-			signalText := " - "
-			signalColor := tcell.ColorWhite
-
-			// Check signals in priority order (most urgent first)
-			if h.TargetPrice.Valid && price.GreaterThanOrEqual(h.TargetPrice.Decimal) {
-				// Target price hit - highest priority sell signal
-				signalText = " TARGET "
-				signalColor = tcell.ColorRed
-			} else if plPct.GreaterThanOrEqual(decimal.NewFromInt(200)) {
-				signalText = " +200% "
-				signalColor = tcell.ColorRed
-			} else if plPct.GreaterThanOrEqual(decimal.NewFromInt(100)) {
-				signalText = " +100% "
-				signalColor = tcell.ColorRed
-			} else if plPct.GreaterThanOrEqual(decimal.NewFromInt(50)) {
-				signalText = " +50% "
-				signalColor = tcell.ColorOrange
-			} else if plPct.GreaterThanOrEqual(decimal.NewFromInt(25)) {
-				signalText = " +25% "
-				signalColor = tcell.ColorYellow
-			} else if weight.GreaterThan(decimal.NewFromInt(25)) {
-				// Position too large - rebalance signal
-				signalText = " REBAL "
-				signalColor = tcell.ColorOrange
-			} else if pctFromHigh >= -5 && pctFromHigh < 0 {
-				// Within 5% of 52-week high
-				signalText = " PEAK "
-				signalColor = tcell.ColorTeal
-			} else if h.TargetPrice.Valid && price.LessThan(h.TargetPrice.Decimal) {
-				// Below target - buy signal
-				signalText = " BUY "
-				signalColor = tcell.ColorLime
-			}
-
-			a.table.SetCell(row, 9, tview.NewTableCell(signalText).
-				SetTextColor(signalColor).
-				SetBackgroundColor(rowBg).
-				SetAlign(tview.AlignLeft).
-				SetExpansion(1))
-		} else {
-			a.table.SetCell(row, 3, tview.NewTableCell(" - ").SetBackgroundColor(rowBg).SetAlign(tview.AlignLeft).SetExpansion(1))
-			a.table.SetCell(row, 4, tview.NewTableCell(" - ").SetBackgroundColor(rowBg).SetAlign(tview.AlignLeft).SetExpansion(1))
-			a.table.SetCell(row, 5, tview.NewTableCell(" - ").SetBackgroundColor(rowBg).SetAlign(tview.AlignLeft).SetExpansion(1))
-			a.table.SetCell(row, 6, tview.NewTableCell(" - ").SetBackgroundColor(rowBg).SetAlign(tview.AlignLeft).SetExpansion(1))
-			a.table.SetCell(row, 7, tview.NewTableCell(" "+formatNumber(weight.StringFixed(1))+"% ").SetBackgroundColor(rowBg).SetAlign(tview.AlignLeft).SetExpansion(1))
-			a.table.SetCell(row, 8, tview.NewTableCell(" - ").SetBackgroundColor(rowBg).SetAlign(tview.AlignLeft).SetExpansion(1))
-			a.table.SetCell(row, 9, tview.NewTableCell(" - ").SetBackgroundColor(rowBg).SetAlign(tview.AlignLeft).SetExpansion(1))
-		}
-	}
-
-	// Update summary
-	totalPL := totalValue.Sub(totalCost)
-	totalPLPct := decimal.Zero
-	if !totalCost.IsZero() {
-		totalPLPct = totalPL.Div(totalCost).Mul(decimal.NewFromInt(100))
-	}
-
-	plColor := "[white]"
-	if totalPL.IsPositive() {
-		plColor = "[green]"
-	} else if totalPL.IsNegative() {
-		plColor = "[red]"
-	}
-
-	plSign := ""
-	if totalPL.IsPositive() {
-		plSign = "+"
-	}
-
-	// Total portfolio = holdings value + cash
-	totalPortfolio := totalValue.Add(a.cash)
-
-	summaryText := fmt.Sprintf(" [white]Total: [yellow]$%s[white]  |  Holdings: $%s  |  Cash: [aqua]$%s[white]  |  P/L: %s%s$%s (%s%.2f%%)",
-		formatNumber(totalPortfolio.StringFixed(2)),
-		formatNumber(totalValue.StringFixed(2)),
-		formatNumber(a.cash.StringFixed(2)),
-		plColor, plSign, formatNumber(totalPL.Abs().StringFixed(2)),
-		plSign, totalPLPct.InexactFloat64())
-
-	a.summary.SetText(summaryText)
-}
-
-func (a *App) showAddForm() {
-	form := tview.NewForm().
-		AddInputField("Ticker", "", 10, nil, nil).
-		AddInputField("Quantity", "", 15, nil, nil)
-
-	// Auto-uppercase ticker
-	tickerField := form.GetFormItem(0).(*tview.InputField)
-	tickerField.SetChangedFunc(func(text string) {
-		upper := strings.ToUpper(text)
-		if text != upper {
-			tickerField.SetText(upper)
-		}
-	})
-
-	form.
-		AddInputField("Avg Cost ($)", "", 15, nil, nil).
-		AddInputField("Target Price ($)", "", 15, nil, nil).
-		AddInputField("Entry Date (YYYY-MM-DD)", time.Now().Format("2006-01-02"), 15, nil, nil).
-		AddInputField("Notes", "", 30, nil, nil)
-
-	styleForm(form)
-
-	form.AddButton("Save", func() {
-		ticker := strings.ToUpper(form.GetFormItem(0).(*tview.InputField).GetText())
-		qtyStr := form.GetFormItem(1).(*tview.InputField).GetText()
-		costStr := form.GetFormItem(2).(*tview.InputField).GetText()
-		targetStr := form.GetFormItem(3).(*tview.InputField).GetText()
-		dateStr := form.GetFormItem(4).(*tview.InputField).GetText()
-		notes := form.GetFormItem(5).(*tview.InputField).GetText()
-
-		if ticker == "" || qtyStr == "" || costStr == "" {
-			a.statusBar.SetText(" [red]Ticker, Quantity, and Avg Cost are required")
-			return
-		}
-
-		qty, err := decimal.NewFromString(qtyStr)
-		if err != nil {
-			a.statusBar.SetText(" [red]Invalid quantity")
-			return
-		}
-
-		cost, err := decimal.NewFromString(costStr)
-		if err != nil {
-			a.statusBar.SetText(" [red]Invalid cost")
-			return
-		}
-
-		var targetPrice decimal.NullDecimal
-		if targetStr != "" {
-			tp, err := decimal.NewFromString(targetStr)
-			if err != nil {
-				a.statusBar.SetText(" [red]Invalid target price")
-				return
-			}
-			targetPrice = decimal.NullDecimal{Decimal: tp, Valid: true}
-		}
-
-		entryDate, err := time.Parse("2006-01-02", dateStr)
-		if err != nil {
-			a.statusBar.SetText(" [red]Invalid date format")
-			return
-		}
-
-		ctx := context.Background()
-		if err := a.db.AddHolding(ctx, ticker, qty, cost, entryDate, targetPrice, notes); err != nil {
-			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
-			return
-		}
-
-		a.pages.SwitchToPage("main")
-		a.pages.RemovePage("add")
-		a.refreshData()
-	})
-
-	form.AddButton("Cancel", func() {
-		a.pages.SwitchToPage("main")
-		a.pages.RemovePage("add")
-	})
-
-	form.SetBorder(true).SetTitle(" Add Holding ").SetTitleAlign(tview.AlignLeft)
-
-	a.createModalPage("add", form, 50, 15)
-}
-
-func (a *App) showHoldingActions(index int) {
-	h := a.holdings[index]
-
-	modal := tview.NewModal().
-		SetText(fmt.Sprintf("Actions for %s\n%.2f shares @ $%s", h.Ticker, h.Quantity.InexactFloat64(), h.AvgCost.StringFixed(2))).
-		AddButtons([]string{"Edit", "Delete", "Cancel"}).
-		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			switch buttonLabel {
-			case "Edit":
-				a.pages.RemovePage("actions")
-				a.showEditForm(index)
-			case "Delete":
-				a.pages.RemovePage("actions")
-				a.confirmDelete(index)
-			default:
-				a.pages.RemovePage("actions")
-			}
-		})
-
-	a.pages.AddPage("actions", modal, true, true)
-}
-
-func (a *App) showEditForm(index int) {
-	h := a.holdings[index]
-
-	targetStr := ""
-	if h.TargetPrice.Valid {
-		targetStr = h.TargetPrice.Decimal.String()
-	}
-
-	form := tview.NewForm().
-		AddInputField("Quantity", h.Quantity.String(), 15, nil, nil).
-		AddInputField("Avg Cost ($)", h.AvgCost.String(), 15, nil, nil).
-		AddInputField("Target Price ($)", targetStr, 15, nil, nil).
-		AddInputField("Notes", h.Notes, 30, nil, nil)
-
-	styleForm(form)
-
-	form.AddButton("Save", func() {
-		qtyStr := form.GetFormItem(0).(*tview.InputField).GetText()
-		costStr := form.GetFormItem(1).(*tview.InputField).GetText()
-		targetStr := form.GetFormItem(2).(*tview.InputField).GetText()
-		notes := form.GetFormItem(3).(*tview.InputField).GetText()
-
-		qty, err := decimal.NewFromString(qtyStr)
-		if err != nil {
-			a.statusBar.SetText(" [red]Invalid quantity")
-			return
-		}
-
-		cost, err := decimal.NewFromString(costStr)
-		if err != nil {
-			a.statusBar.SetText(" [red]Invalid cost")
-			return
-		}
-
-		var targetPrice decimal.NullDecimal
-		if targetStr != "" {
-			tp, err := decimal.NewFromString(targetStr)
-			if err != nil {
-				a.statusBar.SetText(" [red]Invalid target price")
-				return
-			}
-			targetPrice = decimal.NullDecimal{Decimal: tp, Valid: true}
-		}
-
-		ctx := context.Background()
-		if err := a.db.UpdateHolding(ctx, h.ID, qty, cost, targetPrice, notes); err != nil {
-			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
-			return
-		}
-
-		a.pages.SwitchToPage("main")
-		a.pages.RemovePage("edit")
-		a.refreshData()
-	})
-
-	form.AddButton("Cancel", func() {
-		a.pages.SwitchToPage("main")
-		a.pages.RemovePage("edit")
-	})
-
-	form.SetBorder(true).SetTitle(fmt.Sprintf(" Edit %s ", h.Ticker)).SetTitleAlign(tview.AlignLeft)
-
-	a.createModalPage("edit", form, 50, 12)
-}
-
-func (a *App) confirmDelete(index int) {
-	h := a.holdings[index]
-
-	modal := tview.NewModal().
-		SetText(fmt.Sprintf("Delete %s?\n%.2f shares @ $%s", h.Ticker, h.Quantity.InexactFloat64(), h.AvgCost.StringFixed(2))).
-		AddButtons([]string{"Delete", "Cancel"}).
-		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			if buttonLabel == "Delete" {
-				ctx := context.Background()
-				if err := a.db.DeleteHolding(ctx, h.ID); err != nil {
-					a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
-				}
-				a.refreshData()
-			}
-			a.pages.RemovePage("confirm")
-		})
-
-	a.pages.AddPage("confirm", modal, true, true)
-}
-
-func (a *App) showCashForm() {
-	form := tview.NewForm()
-
-	saveCash := func() {
-		cashStr := form.GetFormItem(0).(*tview.InputField).GetText()
-
-		cash, err := decimal.NewFromString(cashStr)
-		if err != nil {
-			a.statusBar.SetText(" [red]Invalid cash amount")
-			return
-		}
-
-		ctx := context.Background()
-		if err := a.db.SetAvailableCash(ctx, cash); err != nil {
-			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
-			return
-		}
-
-		a.pages.SwitchToPage("main")
-		a.pages.RemovePage("cash")
-		a.refreshData()
-	}
-
-	form.AddInputField("Available Cash ($)", a.cash.StringFixed(2), 15, nil, func(text string) {})
-	form.GetFormItem(0).(*tview.InputField).SetDoneFunc(func(key tcell.Key) {
-		if key == tcell.KeyEnter {
-			saveCash()
-		}
-	})
-
-	styleForm(form)
-
-	form.AddButton("Save", saveCash)
-
-	form.AddButton("Cancel", func() {
-		a.pages.SwitchToPage("main")
-		a.pages.RemovePage("cash")
-	})
-
-	form.SetBorder(true).SetTitle(" Set Available Cash ").SetTitleAlign(tview.AlignLeft)
-
-	a.createModalPage("cash", form, 45, 9)
-}
-
-func (a *App) updateOptionsTable() {
-	a.optionsTable.Clear()
-
-	// Header row
-	headers := []string{"TICKER", "TYPE", "ACTION", "STRIKE", "EXPIRY", "QTY", "PREMIUM", "FEE", "STATUS"}
-	for i, h := range headers {
-		cell := tview.NewTableCell(" "+h+" ").
-			SetTextColor(tcell.ColorBlack).
-			SetBackgroundColor(tcell.ColorTeal).
-			SetAlign(tview.AlignLeft).
-			SetSelectable(false).
-			SetExpansion(1)
-		a.optionsTable.SetCell(0, i, cell)
-	}
-
-	today := time.Now().Truncate(24 * time.Hour)
-
-	row := 0
-	for _, o := range a.options {
-		// Skip expired options if toggle is off
-		if !a.showExpired && o.Status == "EXPIRED" {
-			continue
-		}
-		row++
-		rowBg := tcell.ColorBlack
-
-		// Dim colors for non-active options
-		isActive := o.Status == "ACTIVE"
-		dimColor := tcell.ColorDimGray
-
-		// Ticker
-		tickerColor := tcell.ColorFuchsia
-		if !isActive {
-			tickerColor = dimColor
-		}
-		a.optionsTable.SetCell(row, 0, tview.NewTableCell(" "+o.Ticker+" ").
-			SetTextColor(tickerColor).
-			SetBackgroundColor(rowBg).
-			SetAlign(tview.AlignLeft).
-			SetExpansion(1))
-
-		// Type (CALL/PUT)
-		typeColor := tcell.ColorLime
-		if o.OptionType == "PUT" {
-			typeColor = tcell.ColorRed
-		}
-		if !isActive {
-			typeColor = dimColor
-		}
-		a.optionsTable.SetCell(row, 1, tview.NewTableCell(" "+o.OptionType+" ").
-			SetTextColor(typeColor).
-			SetBackgroundColor(rowBg).
-			SetAlign(tview.AlignLeft).
-			SetExpansion(1))
-
-		// Action (BUY/SELL)
-		actionColor := tcell.ColorYellow
-		if o.Action == "SELL" {
-			actionColor = tcell.ColorAqua
-		}
-		if !isActive {
-			actionColor = dimColor
-		}
-		a.optionsTable.SetCell(row, 2, tview.NewTableCell(" "+o.Action+" ").
-			SetTextColor(actionColor).
-			SetBackgroundColor(rowBg).
-			SetAlign(tview.AlignLeft).
-			SetExpansion(1))
-
-		// Strike
-		strikeColor := tcell.ColorWhite
-		if !isActive {
-			strikeColor = dimColor
-		}
-		a.optionsTable.SetCell(row, 3, tview.NewTableCell(" $"+formatNumber(o.Strike.StringFixed(2))+" ").
-			SetTextColor(strikeColor).
-			SetBackgroundColor(rowBg).
-			SetAlign(tview.AlignLeft).
-			SetExpansion(1))
-
-		// Expiry
-		expiryColor := tcell.ColorWhite
-		if !isActive {
-			expiryColor = dimColor
-		}
-		a.optionsTable.SetCell(row, 4, tview.NewTableCell(" "+o.ExpiryDate.Format("2006-01-02")+" ").
-			SetTextColor(expiryColor).
-			SetBackgroundColor(rowBg).
-			SetAlign(tview.AlignLeft).
-			SetExpansion(1))
-
-		// Quantity
-		qtyColor := tcell.ColorWhite
-		if !isActive {
-			qtyColor = dimColor
-		}
-		a.optionsTable.SetCell(row, 5, tview.NewTableCell(" "+fmt.Sprintf("%d", o.Quantity)+" ").
-			SetTextColor(qtyColor).
-			SetBackgroundColor(rowBg).
-			SetAlign(tview.AlignLeft).
-			SetExpansion(1))
-
-		// Premium
-		premiumColor := tcell.ColorYellow
-		if !isActive {
-			premiumColor = dimColor
-		}
-		a.optionsTable.SetCell(row, 6, tview.NewTableCell(" $"+formatNumber(o.Premium.StringFixed(2))+" ").
-			SetTextColor(premiumColor).
-			SetBackgroundColor(rowBg).
-			SetAlign(tview.AlignLeft).
-			SetExpansion(1))
-
-		// Fee
-		feeText := " - "
-		if !o.OpenFee.IsZero() {
-			feeText = " $" + formatNumber(o.OpenFee.StringFixed(2)) + " "
-		}
-		feeColor := tcell.ColorOrange
-		if !isActive {
-			feeColor = dimColor
-		}
-		a.optionsTable.SetCell(row, 7, tview.NewTableCell(feeText).
-			SetTextColor(feeColor).
-			SetBackgroundColor(rowBg).
-			SetAlign(tview.AlignLeft).
-			SetExpansion(1))
-
-		// Status with color coding
-		statusColor := tcell.ColorLime
-		statusText := o.Status
-		if o.Status == "EXPIRED" {
-			statusColor = tcell.ColorGray
-		} else if o.Status == "ASSIGNED" {
-			statusColor = tcell.ColorYellow
-		} else if o.Status == "CLOSED" {
-			statusColor = tcell.ColorAqua
-		}
-		// Add days left for ACTIVE options
-		if o.Status == "ACTIVE" {
-			daysLeft := int(o.ExpiryDate.Sub(today).Hours() / 24)
-			if daysLeft < 0 {
-				statusText = "EXPD"
-				statusColor = tcell.ColorRed
-			} else {
-				statusText = fmt.Sprintf("%dd", daysLeft)
-				if daysLeft <= 7 {
-					statusColor = tcell.ColorRed
-				} else if daysLeft <= 14 {
-					statusColor = tcell.ColorYellow
-				} else if daysLeft <= 30 {
-					statusColor = tcell.ColorOrange
-				}
-			}
-		}
-		a.optionsTable.SetCell(row, 8, tview.NewTableCell(" "+statusText+" ").
-			SetTextColor(statusColor).
-			SetBackgroundColor(rowBg).
-			SetAlign(tview.AlignLeft).
-			SetExpansion(1))
-	}
-}
-
-func (a *App) updateTimeline() {
-	currentYear := time.Now().Year()
-
-	// Premium summary line with fees and net P&L
-	premiumText := fmt.Sprintf(" [teal]%d Premiums:[white] Calls: [lime]$%s[white]  Puts: [lime]$%s[white]  Gross: [yellow]$%s[white]",
-		currentYear,
-		formatNumber(a.premiums.CallPremiums.StringFixed(2)),
-		formatNumber(a.premiums.PutPremiums.StringFixed(2)),
-		formatNumber(a.premiums.TotalPremiums.StringFixed(2)))
-
-	// Add fees and close costs if any
-	if !a.premiums.TotalFees.IsZero() || !a.premiums.CloseCosts.IsZero() {
-		premiumText += fmt.Sprintf("  Fees: [red]-$%s[white]", formatNumber(a.premiums.TotalFees.StringFixed(2)))
-		if !a.premiums.CloseCosts.IsZero() {
-			premiumText += fmt.Sprintf("  BuyBack: [red]-$%s[white]", formatNumber(a.premiums.CloseCosts.StringFixed(2)))
-		}
-	}
-
-	// Net P&L
-	netColor := "lime"
-	if a.premiums.NetPL.IsNegative() {
-		netColor = "red"
-	}
-	premiumText += fmt.Sprintf("  Net: [%s]$%s[white]", netColor, formatNumber(a.premiums.NetPL.StringFixed(2)))
-
-	// Calculate return % and annualized % based on capital at risk
-	if !a.premiums.CapitalAtRisk.IsZero() {
-		returnPct := a.premiums.NetPL.Div(a.premiums.CapitalAtRisk).Mul(decimal.NewFromInt(100))
-
-		// Days elapsed in current year
-		now := time.Now()
-		startOfYear := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
-		daysElapsed := now.Sub(startOfYear).Hours() / 24
-		if daysElapsed < 1 {
-			daysElapsed = 1 // Avoid division by zero on Jan 1
-		}
-
-		// Annualized return
-		annualizedPct := returnPct.Mul(decimal.NewFromFloat(365.0 / daysElapsed))
-
-		returnColor := "lime"
-		if returnPct.IsNegative() {
-			returnColor = "red"
-		}
-		premiumText += fmt.Sprintf("  Return: [%s]%s%%[white]  Ann: [%s]%s%%[white]",
-			returnColor, returnPct.StringFixed(2),
-			returnColor, annualizedPct.StringFixed(2))
-	}
-
-	a.timeline.SetText(premiumText)
-
-	// Update the visual expiry timeline
-	a.updateExpiryTimeline()
-}
-
-func (a *App) updateExpiryTimeline() {
-	today := time.Now().Truncate(24 * time.Hour)
-
-	// Collect active options
-	var activeOptions []db.Option
-	for _, o := range a.options {
-		if o.Status == "ACTIVE" {
-			activeOptions = append(activeOptions, o)
-		}
-	}
-
-	// Update title based on view mode
-	viewMode := "Monthly"
-	if a.weeklyView {
-		viewMode = "Weekly"
-	}
-	a.expiryTimeline.SetTitle(fmt.Sprintf(" Expiry Timeline [%s] ", viewMode))
-
-	if len(activeOptions) == 0 {
-		a.expiryTimeline.SetText(" [gray]No active options")
-		return
-	}
-
-	// Sort options by expiry date
-	for i := 0; i < len(activeOptions)-1; i++ {
-		for j := i + 1; j < len(activeOptions); j++ {
-			if activeOptions[i].ExpiryDate.After(activeOptions[j].ExpiryDate) {
-				activeOptions[i], activeOptions[j] = activeOptions[j], activeOptions[i]
-			}
-		}
-	}
-
-	// Timeline parameters based on view mode
-	var numPeriods int
-	var periodWidth int
-	var totalWidth int
-
-	if a.weeklyView {
-		numPeriods = 12 // 12 weeks
-		totalWidth = 120
-		periodWidth = totalWidth / numPeriods
-	} else {
-		numPeriods = 6 // 6 months
-		totalWidth = 120
-		periodWidth = totalWidth / numPeriods
-	}
-
-	var output string
-
-	// "Today" marker row
-	output = " [aqua]▼Today[white]\n"
-
-	// Header row with periods
-	output += " "
-	for i := 0; i < numPeriods; i++ {
-		var periodLabel string
-		if a.weeklyView {
-			// Calculate the Friday of each week (options typically expire on Fridays)
-			// Find this week's Friday (could be in the past if today is Sat/Sun)
-			weekday := int(today.Weekday())
-			var daysToFriday int
-			if weekday <= 5 { // Sun(0) to Fri(5)
-				daysToFriday = 5 - weekday
-			} else { // Saturday(6)
-				daysToFriday = -1 // Yesterday was Friday
-			}
-			fridayDate := today.AddDate(0, 0, daysToFriday+(i*7))
-			periodLabel = fridayDate.Format("Jan 02")
-		} else {
-			// Calculate the third Friday of each month (standard options expiry)
-			firstOfMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, i, 0)
-			// Days until first Friday: (Friday=5 - weekday + 7) % 7
-			daysUntilFriday := (5 - int(firstOfMonth.Weekday()) + 7) % 7
-			thirdFriday := firstOfMonth.AddDate(0, 0, daysUntilFriday+14)
-			periodLabel = thirdFriday.Format("Jan 02")
-		}
-		output += fmt.Sprintf("[aqua]%-*s[white]", periodWidth, periodLabel)
-	}
-	output += "\n"
-
-	// Separator line with today marker
-	output += " [aqua]│[white]"
-	for i := 1; i < totalWidth; i++ {
-		if i%periodWidth == 0 {
-			output += "+"
-		} else {
-			output += "-"
-		}
-	}
-	output += "\n"
-
-	// Each contract gets its own row
-	for _, o := range activeOptions {
-		daysLeft := int(o.ExpiryDate.Sub(today).Hours() / 24)
-		if daysLeft < 0 {
-			daysLeft = 0
-		}
-
-		// Color based on days left
-		color := "white"
-		if daysLeft <= 7 {
-			color = "red"
-		} else if daysLeft <= 14 {
-			color = "yellow"
-		} else if daysLeft <= 30 {
-			color = "orange"
-		} else {
-			color = "lime"
-		}
-
-		// Contract label
-		typeSymbol := "C"
-		if o.OptionType == "PUT" {
-			typeSymbol = "P"
-		}
-		contractLabel := fmt.Sprintf("%s %s $%s(%dd)", o.Ticker, typeSymbol, o.Strike.StringFixed(0), daysLeft)
-
-		// Calculate expiry position
-		var expiryPos int
-		if a.weeklyView {
-			// Position based on days (12 weeks = 84 days)
-			maxDays := numPeriods * 7
-			if daysLeft > maxDays {
-				expiryPos = totalWidth - 1
-			} else {
-				expiryPos = (daysLeft * totalWidth) / maxDays
-			}
-		} else {
-			// Position based on months
-			monthsAway := (o.ExpiryDate.Year()-today.Year())*12 + int(o.ExpiryDate.Month()-today.Month())
-			dayInMonth := o.ExpiryDate.Day()
-			daysInMonth := time.Date(o.ExpiryDate.Year(), o.ExpiryDate.Month()+1, 0, 0, 0, 0, 0, time.Local).Day()
-
-			if monthsAway >= numPeriods {
-				expiryPos = totalWidth - 1
-			} else {
-				expiryPos = (monthsAway * periodWidth) + ((dayInMonth * periodWidth) / daysInMonth)
-			}
-		}
-
-		if expiryPos < 1 {
-			expiryPos = 1
-		}
-		if expiryPos >= totalWidth {
-			expiryPos = totalWidth - 1
-		}
-
-		// Build the row: today line, connecting line, marker with label
-		output += " [aqua]├[white]"
-
-		// Draw connecting line from today to expiry marker
-		for i := 1; i < expiryPos; i++ {
-			output += fmt.Sprintf("[%s]─[white]", color)
-		}
-
-		// Draw marker and label
-		output += fmt.Sprintf("[%s]●%s[white]", color, contractLabel)
-
-		output += "\n"
-	}
-
-	// Bottom of today line
-	if len(activeOptions) > 0 {
-		output += " [aqua]│[white]\n"
-	}
-
-	a.expiryTimeline.SetText(output)
-}
-
-func (a *App) showAddOptionForm() {
-	form := tview.NewForm().
-		AddInputField("Ticker", "", 10, nil, nil)
-
-	// Auto-uppercase ticker
-	tickerField := form.GetFormItem(0).(*tview.InputField)
-	tickerField.SetChangedFunc(func(text string) {
-		upper := strings.ToUpper(text)
-		if text != upper {
-			tickerField.SetText(upper)
-		}
-	})
-
-	form.
-		AddDropDown("Type", []string{"CALL", "PUT"}, 0, nil).
-		AddDropDown("Action", []string{"SELL", "BUY"}, 0, nil).
-		AddInputField("Strike ($)", "", 15, nil, nil).
-		AddInputField("Expiry (YYYY-MM-DD)", "", 15, nil, nil).
-		AddInputField("Quantity", "1", 10, nil, nil).
-		AddInputField("Premium ($)", "", 15, nil, nil).
-		AddInputField("Fee ($)", "0", 10, nil, nil).
-		AddInputField("Notes", "", 30, nil, nil)
-
-	styleForm(form)
-
-	form.AddButton("Save", func() {
-		ticker := strings.ToUpper(form.GetFormItem(0).(*tview.InputField).GetText())
-		_, optionType := form.GetFormItem(1).(*tview.DropDown).GetCurrentOption()
-		_, action := form.GetFormItem(2).(*tview.DropDown).GetCurrentOption()
-		strikeStr := form.GetFormItem(3).(*tview.InputField).GetText()
-		expiryStr := form.GetFormItem(4).(*tview.InputField).GetText()
-		qtyStr := form.GetFormItem(5).(*tview.InputField).GetText()
-		premiumStr := form.GetFormItem(6).(*tview.InputField).GetText()
-		feeStr := form.GetFormItem(7).(*tview.InputField).GetText()
-		notes := form.GetFormItem(8).(*tview.InputField).GetText()
-
-		if ticker == "" || strikeStr == "" || expiryStr == "" || premiumStr == "" {
-			a.statusBar.SetText(" [red]Ticker, Strike, Expiry, and Premium are required")
-			return
-		}
-
-		strike, err := decimal.NewFromString(strikeStr)
-		if err != nil {
-			a.statusBar.SetText(" [red]Invalid strike price")
-			return
-		}
-
-		expiry, err := time.Parse("2006-01-02", expiryStr)
-		if err != nil {
-			a.statusBar.SetText(" [red]Invalid expiry date format")
-			return
-		}
-
-		qty, err := strconv.Atoi(qtyStr)
-		if err != nil || qty < 1 {
-			a.statusBar.SetText(" [red]Invalid quantity")
-			return
-		}
-
-		premium, err := decimal.NewFromString(premiumStr)
-		if err != nil {
-			a.statusBar.SetText(" [red]Invalid premium")
-			return
-		}
-
-		openFee := decimal.Zero
-		if feeStr != "" {
-			openFee, err = decimal.NewFromString(feeStr)
-			if err != nil {
-				a.statusBar.SetText(" [red]Invalid fee")
-				return
-			}
-		}
-
-		ctx := context.Background()
-		if err := a.db.AddOption(ctx, ticker, optionType, action, strike, expiry, qty, premium, openFee, notes); err != nil {
-			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
-			return
-		}
-
-		a.pages.SwitchToPage("main")
-		a.pages.RemovePage("addoption")
-		a.refreshData()
-	})
-
-	form.AddButton("Cancel", func() {
-		a.pages.SwitchToPage("main")
-		a.pages.RemovePage("addoption")
-	})
-
-	form.SetBorder(true).SetTitle(" Add Option ").SetTitleAlign(tview.AlignLeft)
-
-	a.createModalPage("addoption", form, 55, 20)
-}
-
-func (a *App) showOptionActions(index int) {
-	o := a.options[index]
-
-	typeStr := o.OptionType
-	actionDesc := "You receive shares"
-	if typeStr == "CALL" {
-		actionDesc = "Your shares get called away"
-	}
-
-	modal := tview.NewModal().
-		SetText(fmt.Sprintf("%s %s %s $%s\nExpires: %s\n\nAssign: %s", o.Action, o.Ticker, typeStr, o.Strike.StringFixed(2), o.ExpiryDate.Format("2006-01-02"), actionDesc)).
-		AddButtons([]string{"Edit", "Close", "Assign", "Expire", "Delete", "Cancel"}).
-		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			switch buttonLabel {
-			case "Edit":
-				a.pages.RemovePage("optionactions")
-				a.showEditOptionForm(index)
-			case "Close":
-				a.pages.RemovePage("optionactions")
-				a.showCloseOptionForm(index)
-			case "Assign":
-				a.pages.RemovePage("optionactions")
-				a.confirmAssignOption(index)
-			case "Expire":
-				a.pages.RemovePage("optionactions")
-				a.confirmExpireOption(index)
-			case "Delete":
-				a.pages.RemovePage("optionactions")
-				a.confirmDeleteOption(index)
-			default:
-				a.pages.RemovePage("optionactions")
-			}
-		})
-
-	a.pages.AddPage("optionactions", modal, true, true)
-}
-
-func (a *App) showEditOptionForm(index int) {
-	o := a.options[index]
-
-	form := tview.NewForm().
-		AddInputField("Strike ($)", o.Strike.String(), 15, nil, nil).
-		AddInputField("Expiry (YYYY-MM-DD)", o.ExpiryDate.Format("2006-01-02"), 15, nil, nil).
-		AddInputField("Quantity", fmt.Sprintf("%d", o.Quantity), 10, nil, nil).
-		AddInputField("Premium ($)", o.Premium.String(), 15, nil, nil).
-		AddInputField("Fee ($)", o.OpenFee.String(), 10, nil, nil).
-		AddInputField("Notes", o.Notes, 30, nil, nil)
-
-	styleForm(form)
-
-	form.AddButton("Save", func() {
-		strikeStr := form.GetFormItem(0).(*tview.InputField).GetText()
-		expiryStr := form.GetFormItem(1).(*tview.InputField).GetText()
-		qtyStr := form.GetFormItem(2).(*tview.InputField).GetText()
-		premiumStr := form.GetFormItem(3).(*tview.InputField).GetText()
-		feeStr := form.GetFormItem(4).(*tview.InputField).GetText()
-		notes := form.GetFormItem(5).(*tview.InputField).GetText()
-
-		strike, err := decimal.NewFromString(strikeStr)
-		if err != nil {
-			a.statusBar.SetText(" [red]Invalid strike price")
-			return
-		}
-
-		expiry, err := time.Parse("2006-01-02", expiryStr)
-		if err != nil {
-			a.statusBar.SetText(" [red]Invalid expiry date format")
-			return
-		}
-
-		qty, err := strconv.Atoi(qtyStr)
-		if err != nil || qty < 1 {
-			a.statusBar.SetText(" [red]Invalid quantity")
-			return
-		}
-
-		premium, err := decimal.NewFromString(premiumStr)
-		if err != nil {
-			a.statusBar.SetText(" [red]Invalid premium")
-			return
-		}
-
-		fee := decimal.Zero
-		if feeStr != "" {
-			fee, err = decimal.NewFromString(feeStr)
-			if err != nil {
-				a.statusBar.SetText(" [red]Invalid fee")
-				return
-			}
-		}
-
-		ctx := context.Background()
-		if err := a.db.UpdateOption(ctx, o.ID, strike, expiry, qty, premium, fee, notes); err != nil {
-			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
-			return
-		}
-
-		a.statusBar.SetText(fmt.Sprintf(" [green]Updated: %s %s $%s", o.Ticker, o.OptionType, strike.StringFixed(2)))
-		a.pages.SwitchToPage("main")
-		a.pages.RemovePage("editoption")
-		a.refreshData()
-	})
-
-	form.AddButton("Cancel", func() {
-		a.pages.SwitchToPage("main")
-		a.pages.RemovePage("editoption")
-	})
-
-	form.SetBorder(true).SetTitle(fmt.Sprintf(" Edit %s %s %s ", o.Action, o.Ticker, o.OptionType)).SetTitleAlign(tview.AlignLeft)
-
-	a.createModalPage("editoption", form, 55, 18)
-}
-
-func (a *App) confirmDeleteOption(index int) {
-	o := a.options[index]
-
-	modal := tview.NewModal().
-		SetText(fmt.Sprintf("Delete %s %s $%s?", o.Ticker, o.OptionType, o.Strike.StringFixed(2))).
-		AddButtons([]string{"Delete", "Cancel"}).
-		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			if buttonLabel == "Delete" {
-				ctx := context.Background()
-				if err := a.db.DeleteOption(ctx, o.ID); err != nil {
-					a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
-				}
-				a.refreshData()
-			}
-			a.pages.RemovePage("confirmoption")
-		})
-
-	a.pages.AddPage("confirmoption", modal, true, true)
-}
-
-func (a *App) confirmAssignOption(index int) {
-	o := a.options[index]
-
-	shares := o.Quantity * 100
-	totalValue := o.Strike.Mul(decimal.NewFromInt(int64(shares)))
-
-	var actionText string
-	if o.OptionType == "PUT" {
-		actionText = fmt.Sprintf("BUY %d shares of %s @ $%s\nCash: -$%s",
-			shares, o.Ticker, o.Strike.StringFixed(2), formatNumber(totalValue.StringFixed(2)))
-	} else {
-		actionText = fmt.Sprintf("SELL %d shares of %s @ $%s\nCash: +$%s",
-			shares, o.Ticker, o.Strike.StringFixed(2), formatNumber(totalValue.StringFixed(2)))
-	}
-
-	modal := tview.NewModal().
-		SetText(fmt.Sprintf("Assign %s %s $%s?\n\n%s", o.Ticker, o.OptionType, o.Strike.StringFixed(2), actionText)).
-		AddButtons([]string{"Confirm", "Cancel"}).
-		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			if buttonLabel == "Confirm" {
-				ctx := context.Background()
-				if err := a.db.AssignOption(ctx, o.ID); err != nil {
-					a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
-				} else {
-					a.statusBar.SetText(fmt.Sprintf(" [green]Option assigned: %s %s", o.Ticker, o.OptionType))
-				}
-				a.refreshData()
-			}
-			a.pages.RemovePage("confirmassign")
-		})
-
-	a.pages.AddPage("confirmassign", modal, true, true)
-}
-
-func (a *App) confirmExpireOption(index int) {
-	o := a.options[index]
-
-	modal := tview.NewModal().
-		SetText(fmt.Sprintf("Mark %s %s $%s as expired?\n\nOption expires worthless, no shares exchanged.", o.Ticker, o.OptionType, o.Strike.StringFixed(2))).
-		AddButtons([]string{"Confirm", "Cancel"}).
-		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			if buttonLabel == "Confirm" {
-				ctx := context.Background()
-				if err := a.db.ExpireOption(ctx, o.ID); err != nil {
-					a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
-				} else {
-					a.statusBar.SetText(fmt.Sprintf(" [green]Option expired: %s %s", o.Ticker, o.OptionType))
-				}
-				a.refreshData()
-			}
-			a.pages.RemovePage("confirmexpire")
-		})
-
-	a.pages.AddPage("confirmexpire", modal, true, true)
-}
-
-func (a *App) showCloseOptionForm(index int) {
-	o := a.options[index]
-
-	closeAction := "Buy back"
-	if o.Action == "BUY" {
-		closeAction = "Sell"
-	}
-
-	form := tview.NewForm().
-		AddInputField("Close Premium ($)", "", 15, nil, nil).
-		AddInputField("Close Fee ($)", "0", 10, nil, nil)
-
-	styleForm(form)
-
-	form.AddButton("Close Position", func() {
-		closePremiumStr := form.GetFormItem(0).(*tview.InputField).GetText()
-		closeFeeStr := form.GetFormItem(1).(*tview.InputField).GetText()
-
-		if closePremiumStr == "" {
-			a.statusBar.SetText(" [red]Close premium is required")
-			return
-		}
-
-		closePremium, err := decimal.NewFromString(closePremiumStr)
-		if err != nil {
-			a.statusBar.SetText(" [red]Invalid close premium")
-			return
-		}
-
-		closeFee := decimal.Zero
-		if closeFeeStr != "" {
-			closeFee, err = decimal.NewFromString(closeFeeStr)
-			if err != nil {
-				a.statusBar.SetText(" [red]Invalid close fee")
-				return
-			}
-		}
-
-		ctx := context.Background()
-		if err := a.db.CloseOption(ctx, o.ID, closePremium, closeFee); err != nil {
-			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
-			return
-		}
-
-		a.statusBar.SetText(fmt.Sprintf(" [green]Position closed: %s %s", o.Ticker, o.OptionType))
-		a.pages.SwitchToPage("main")
-		a.pages.RemovePage("closeoption")
-		a.refreshData()
-	})
-
-	form.AddButton("Cancel", func() {
-		a.pages.SwitchToPage("main")
-		a.pages.RemovePage("closeoption")
-	})
-
-	form.SetBorder(true).SetTitle(fmt.Sprintf(" %s %s %s $%s ", closeAction, o.Ticker, o.OptionType, o.Strike.StringFixed(2))).SetTitleAlign(tview.AlignLeft)
-
-	a.createModalPage("closeoption", form, 50, 10)
-}
-
-func (a *App) processExpiredOptions(ctx context.Context) {
-	// Get expired options that are still ACTIVE
-	expiredOptions, err := a.db.GetExpiredActiveOptions(ctx)
-	if err != nil || len(expiredOptions) == 0 {
-		return
-	}
-
-	// Get unique tickers
-	tickers := make([]string, 0)
-	tickerMap := make(map[string]bool)
-	for _, o := range expiredOptions {
-		if !tickerMap[o.Ticker] {
-			tickers = append(tickers, o.Ticker)
-			tickerMap[o.Ticker] = true
-		}
-	}
-
-	// Fetch current prices
-	quotes, err := a.yahoo.GetQuotes(tickers)
-	if err != nil {
-		return
-	}
-
-	// Process each expired option
-	for _, o := range expiredOptions {
-		quote, hasQuote := quotes[o.Ticker]
-		if !hasQuote {
-			continue
-		}
-
-		currentPrice := decimal.NewFromFloat(quote.Price)
-		isITM := false
-
-		// CALL is ITM if current price > strike (shares get called away)
-		// PUT is ITM if current price < strike (you get assigned shares)
-		if o.OptionType == "CALL" {
-			isITM = currentPrice.GreaterThan(o.Strike)
-		} else {
-			isITM = currentPrice.LessThan(o.Strike)
-		}
-
-		if isITM {
-			// Auto-assign
-			a.db.AssignOption(ctx, o.ID)
-		} else {
-			// Auto-expire (OTM)
-			a.db.ExpireOption(ctx, o.ID)
-		}
-	}
-}
-
-func (a *App) createModalPage(name string, content tview.Primitive, width, height int) {
-	// Create transparent boxes that capture input but don't obscure background
-	leftBox := tview.NewBox()
-	rightBox := tview.NewBox()
-	topBox := tview.NewBox()
-	bottomBox := tview.NewBox()
+func (a *App) createModalPage(name string, content tview.Primitive, width, height int) {
+	// Create transparent boxes that capture input but don't obscure background
+	leftBox := tview.NewBox()
+	rightBox := tview.NewBox()
+	topBox := tview.NewBox()
+	bottomBox := tview.NewBox()
 
 	// Center the content with input-capturing spacers
 	flex := tview.NewFlex().
@@ -1788,34 +1062,6 @@ func styleForm(form *tview.Form) {
 	form.SetTitleColor(tcell.ColorTeal)
 }
 
-func formatNumber(s string) string {
-	parts := strings.Split(s, ".")
-	intPart := parts[0]
-
-	negative := false
-	if strings.HasPrefix(intPart, "-") {
-		negative = true
-		intPart = intPart[1:]
-	}
-
-	var result []byte
-	for i, c := range intPart {
-		if i > 0 && (len(intPart)-i)%3 == 0 {
-			result = append(result, ',')
-		}
-		result = append(result, byte(c))
-	}
-
-	formatted := string(result)
-	if len(parts) > 1 {
-		formatted += "." + parts[1]
-	}
-	if negative {
-		formatted = "-" + formatted
-	}
-	return formatted
-}
-
 // Helper to parse float - not used but kept for potential future use
 func parseFloat(s string) float64 {
 	f, _ := strconv.ParseFloat(s, 64)
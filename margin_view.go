@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	// defaultMarginIV values options whose implied volatility has not been fetched
+	defaultMarginIV = 0.30
+	// marginDominantShare is the share of a regime's requirement above which a
+	// position is flagged as dominating it
+	marginDominantShare = 0.25
+)
+
+// marginBook builds the margin model's view of the book. Underlyings without
+// a quote are valued at cost, or at the strike for options alone. assumedIV
+// counts the options valued at defaultMarginIV.
+func (a *App) marginBook() (book analytics.MarginBook, assumedIV int) {
+	book = analytics.MarginBook{
+		Cash:   a.book.cash.InexactFloat64(),
+		Prices: make(map[string]float64),
+		Shares: make(map[string]float64),
+	}
+	for _, h := range a.book.holdings {
+		book.Shares[h.Ticker] += h.Quantity.InexactFloat64()
+		book.Prices[h.Ticker] = h.AvgCost.InexactFloat64()
+	}
+
+	today := a.now().Truncate(24 * time.Hour)
+	for _, o := range a.book.options {
+		if o.Status != "ACTIVE" {
+			continue
+		}
+		strike := o.Strike.InexactFloat64()
+		if _, ok := book.Prices[o.Ticker]; !ok {
+			book.Prices[o.Ticker] = strike
+		}
+		iv := defaultMarginIV
+		if cached, ok := a.book.optionIVs[contractKey(o.Ticker, o.OptionType, o.ExpiryDate, strike)]; ok && cached.iv > 0 {
+			iv = cached.iv
+		} else {
+			assumedIV++
+		}
+		book.Options = append(book.Options, analytics.MarginOption{
+			Ticker:     o.Ticker,
+			OptionType: o.OptionType,
+			Action:     o.Action,
+			Strike:     strike,
+			Contracts:  o.Quantity,
+			Multiplier: o.Multiplier,
+			DTE:        max(int(o.ExpiryDate.Sub(today).Hours()/24), 0),
+			IV:         iv,
+		})
+	}
+
+	for ticker := range book.Prices {
+		if q, ok := a.book.quotes[ticker]; ok && q.Price > 0 {
+			book.Prices[ticker] = q.Price
+		}
+	}
+	return book, assumedIV
+}
+
+// showMarginView compares the book's margin requirement under Reg-T and
+// portfolio margin
+func (a *App) showMarginView() {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	view.SetBorder(true).
+		SetTitle(" Margin: Reg-T vs Portfolio ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	view.SetText(a.buildMarginReport())
+	a.pages.AddPage("margin", view, true, true)
+}
+
+// buildMarginReport renders both requirements with their utilization of net
+// liquidation value, then each underlying's share, flagging those that
+// dominate either requirement
+func (a *App) buildMarginReport() string {
+	book, assumedIV := a.marginBook()
+	reqs := book.Requirements()
+	netLiq := book.NetLiquidation()
+
+	var regT, pm float64
+	for _, r := range reqs {
+		regT += r.RegT
+		pm += r.Portfolio
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, " [teal]Net liquidation:[white] $%s\n\n", a.book.locale.FormatFloat(netLiq, 2))
+	fmt.Fprintf(&sb, " [yellow]%-18s %14s %12s %14s[white]\n", "REGIME", "REQUIREMENT", "UTILIZATION", "EXCESS")
+	for _, row := range []struct {
+		label string
+		req   float64
+	}{
+		{"Reg-T", regT},
+		{"Portfolio margin", pm},
+	} {
+		fmt.Fprintf(&sb, " %-18s %14s %s %14s\n",
+			row.label,
+			"$"+a.book.locale.FormatFloat(row.req, 0),
+			a.utilizationText(row.req, netLiq),
+			"$"+a.book.locale.FormatFloat(netLiq-row.req, 0))
+	}
+	if regT > 0 {
+		fmt.Fprintf(&sb, "\n Portfolio margin requires [lime]%s%%[white] less\n", a.book.locale.FormatFloat((regT-pm)/regT*100, 1))
+	}
+
+	if len(reqs) == 0 {
+		sb.WriteString("\n No positions.\n")
+	} else {
+		fmt.Fprintf(&sb, "\n [yellow]%-8s %14s %7s %14s %7s[white]\n", "TICKER", "REG-T", "SHARE", "PORTFOLIO", "SHARE")
+		for _, r := range reqs {
+			regTShare, pmShare := share(r.RegT, regT), share(r.Portfolio, pm)
+			flag := ""
+			if regTShare >= marginDominantShare || pmShare >= marginDominantShare {
+				flag = "  [orange]dominant[white]"
+			}
+			fmt.Fprintf(&sb, " %-8s %14s %6s%% %14s %6s%%%s\n",
+				r.Ticker,
+				"$"+a.book.locale.FormatFloat(r.RegT, 0),
+				a.book.locale.FormatFloat(regTShare*100, 1),
+				"$"+a.book.locale.FormatFloat(r.Portfolio, 0),
+				a.book.locale.FormatFloat(pmShare*100, 1),
+				flag)
+		}
+	}
+
+	sb.WriteString("\n [gray]Reg-T: 50% of stock, long options in full, naked shorts at 20% of the underlying less the OTM amount plus premium (10% floor).")
+	fmt.Fprintf(&sb, "\n [gray]Portfolio margin: worst loss per underlying over ±%d%% moves, min $%s per contract.", int(analytics.PMMove*100), a.book.locale.FormatFloat(analytics.PMContractFloor, 2))
+	if assumedIV > 0 {
+		fmt.Fprintf(&sb, "\n [gray]%d option(s) valued at an assumed %d%% IV.", assumedIV, int(defaultMarginIV*100))
+	}
+	sb.WriteString("\n\n [gray]Estimates only; your broker's house rules decide. ESC to close")
+	return sb.String()
+}
+
+// utilizationText shows req as a percent of netLiq, yellow above half and red
+// once it exceeds it
+func (a *App) utilizationText(req, netLiq float64) string {
+	if netLiq <= 0 {
+		return fmt.Sprintf("%12s", "-")
+	}
+	pct := req / netLiq * 100
+	color := "lime"
+	switch {
+	case pct > 100:
+		color = "red"
+	case pct > 50:
+		color = "yellow"
+	}
+	return fmt.Sprintf("[%s]%11s%%[white]", color, a.book.locale.FormatFloat(pct, 1))
+}
+
+// share returns part as a fraction of total, zero when there is no total
+func share(part, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return part / total
+}
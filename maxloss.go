@@ -0,0 +1,113 @@
+package main
+
+import (
+	"sort"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/db"
+
+	"github.com/shopspring/decimal"
+)
+
+// optionRisk is an open option's worst case at expiry, in the base currency
+type optionRisk struct {
+	Loss      decimal.Decimal
+	Unbounded bool // A short call without the shares to deliver
+	Covered   bool // A short call on shares held, which carry the risk
+	Spread    bool // A leg of a vertical; the spread's loss is on its first short leg
+	Counted   bool // Loss is this option's to total; false for the other legs of a spread
+}
+
+// optionRisks works out each active option's maximum loss at expiry, by ID.
+// Short puts risk their collateral less the premium, long options their
+// premium, and short calls are covered by physically settled shares held
+// or unbounded. Options on the same underlying, type, and expiry bought and
+// sold together are a spread with the defined loss of all its legs.
+// Watch-only options have no entry.
+func (b *book) optionRisks() map[string]optionRisk {
+	groups := make(map[string][]db.Option)
+	var keys []string
+	for _, o := range b.options {
+		if o.Status != "ACTIVE" || o.External {
+			continue
+		}
+		key := o.Ticker + "|" + o.OptionType + "|" + o.ExpiryDate.Format("2006-01-02")
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], o)
+	}
+	sort.Strings(keys)
+
+	free := make(map[string]decimal.Decimal)
+	for _, h := range b.holdings {
+		free[h.Ticker] = h.Quantity
+	}
+	risks := make(map[string]optionRisk)
+	for _, key := range keys {
+		legs := groups[key]
+		var sells, buys int
+		for _, o := range legs {
+			if o.Action == "SELL" {
+				sells++
+			} else {
+				buys++
+			}
+		}
+
+		if sells > 0 && buys > 0 {
+			var p analytics.Payoff
+			for _, o := range legs {
+				p.Legs = append(p.Legs, analytics.PayoffLeg{
+					OptionType: o.OptionType, Action: o.Action, Strike: o.Strike.InexactFloat64(),
+					Premium: o.Premium.InexactFloat64(), Contracts: o.Quantity, Multiplier: o.Multiplier,
+				})
+			}
+			loss, bounded := p.MaxLoss()
+			counted := false
+			for _, o := range legs {
+				r := optionRisk{Spread: true}
+				if o.Action == "SELL" && !counted {
+					counted = true
+					r.Counted = true
+					r.Unbounded = !bounded
+					r.Loss = b.toBase(decimal.NewFromFloat(loss), o.Currency).Round(2)
+				}
+				risks[o.ID] = r
+			}
+			continue
+		}
+
+		for _, o := range legs {
+			r := optionRisk{Counted: true}
+			switch {
+			case o.Action == "BUY":
+				r.Loss = b.toBase(o.Premium.Mul(o.Shares()), o.Currency)
+			case o.OptionType == "PUT":
+				r.Loss = b.toBase(decimal.Max(o.Strike.Sub(o.Premium), decimal.Zero).Mul(o.Shares()), o.Currency)
+			case o.Settlement != db.SettlementCash && free[o.Ticker].GreaterThanOrEqual(o.Shares()):
+				free[o.Ticker] = free[o.Ticker].Sub(o.Shares())
+				r.Covered = true
+			default:
+				r.Unbounded = true
+			}
+			risks[o.ID] = r
+		}
+	}
+	return risks
+}
+
+// totalMaxLoss sums the bounded worst cases, and counts the unbounded ones
+func totalMaxLoss(risks map[string]optionRisk) (total decimal.Decimal, unbounded int) {
+	for _, r := range risks {
+		if !r.Counted {
+			continue
+		}
+		if r.Unbounded {
+			unbounded++
+			continue
+		}
+		total = total.Add(r.Loss)
+	}
+	return total, unbounded
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/notify"
+	"anyhowhodl/internal/query"
+	"anyhowhodl/internal/telegram"
+)
+
+// notifyChannels builds the remote notification channels configured in the
+// environment. The terminal and desktop are not among them: the TUI and the
+// daemon each add their own.
+func notifyChannels() []notify.Channel {
+	var channels []notify.Channel
+	if url := os.Getenv("NOTIFY_WEBHOOK_URL"); url != "" {
+		channels = append(channels, &notify.WebhookChannel{URL: url})
+	}
+	if addr, to := os.Getenv("SMTP_ADDR"), os.Getenv("NOTIFY_EMAIL_TO"); addr != "" && to != "" {
+		from := os.Getenv("NOTIFY_EMAIL_FROM")
+		if from == "" {
+			from = os.Getenv("SMTP_USERNAME")
+		}
+		channels = append(channels, &notify.EmailChannel{
+			Addr:     addr,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+			To:       strings.Split(to, ","),
+		})
+	}
+	if token := os.Getenv("TELEGRAM_BOT_TOKEN"); token != "" {
+		if chatID, err := strconv.ParseInt(os.Getenv("TELEGRAM_CHAT_ID"), 10, 64); err == nil {
+			channels = append(channels, &notify.TelegramChannel{Client: telegram.NewClient(token), ChatID: chatID})
+		}
+	}
+	return channels
+}
+
+// loadNotifier routes events over channels as set in settings, or by the
+// default routes if they are unset or cannot be read
+func loadNotifier(ctx context.Context, store db.Store, channels ...notify.Channel) *notify.Dispatcher {
+	text, err := store.GetNotifyRoutes(ctx)
+	if err != nil {
+		slog.Warn("loading notification routes", "err", err)
+	}
+	routes, err := notify.ParseRoutes(text)
+	if err != nil {
+		slog.Warn("invalid notification routes in settings, using default", "routes", text, "err", err)
+		routes, _ = notify.ParseRoutes(notify.DefaultRoutes)
+	}
+	return notify.NewDispatcher(routes, channels...)
+}
+
+// tuiNotifier routes the TUI's alerts over the remote channels and the
+// desktop; the terminal is handled by notifyAlert
+func tuiNotifier(ctx context.Context, store db.Store) *notify.Dispatcher {
+	return loadNotifier(ctx, store, append(notifyChannels(), notify.DesktopChannel{})...)
+}
+
+// notifyAlert announces a new alert on the channels its kind is routed to.
+// The terminal channel is the TUI itself: the alert is logged and the bell
+// rings if it is on. Other channels are sent to in the background.
+func (a *App) notifyAlert(alert query.Alert) {
+	if a.notifier == nil {
+		a.notifier = loadNotifier(context.Background(), a.db)
+	}
+	e := notify.Event{Kind: alert.Kind, Key: alert.Key, Text: alert.Message}
+	routes := a.notifier.Routes(e.Kind)
+	if slices.Contains(routes, notify.Terminal) {
+		slog.Info("alert", "message", alert.Message)
+		if a.alertBell {
+			a.bellPending = true
+		}
+	}
+	if !slices.ContainsFunc(routes, func(name string) bool { return slices.Contains(a.notifier.Registered(), name) }) {
+		return
+	}
+	a.goSafe("notify", func() {
+		if err := a.notifier.Notify(context.Background(), e); err != nil {
+			slog.Warn("sending notification", "kind", e.Kind, "err", err)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/query"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// shortContracts lists the contracts of the open short options once each,
+// by contractKey, as open interest snapshots dated today without a count
+func (a *App) shortContracts() map[string]db.OpenInterest {
+	today := a.today()
+	contracts := make(map[string]db.OpenInterest)
+	for _, o := range a.book.options {
+		if !isShortOption(o) {
+			continue
+		}
+		strike := o.Strike.InexactFloat64()
+		contracts[contractKey(o.Ticker, o.OptionType, o.ExpiryDate, strike)] = db.OpenInterest{
+			Ticker:     o.Ticker,
+			OptionType: o.OptionType,
+			Expiry:     o.ExpiryDate,
+			Strike:     strike,
+			Day:        today,
+		}
+	}
+	return contracts
+}
+
+// trackShortOpenInterest records the open interest of the held contracts
+// found in the fetched chains and returns its change by contractKey
+func trackShortOpenInterest(q *query.Service, held map[string]db.OpenInterest, chains map[string]chainContract) (map[string]query.OIChange, error) {
+	var keys []string
+	var snapshots []db.OpenInterest
+	for key, c := range held {
+		fetched, ok := chains[key]
+		if !ok {
+			continue
+		}
+		c.OpenInterest = fetched.openInterest
+		keys = append(keys, key)
+		snapshots = append(snapshots, c)
+	}
+	changes, err := q.TrackOpenInterest(context.Background(), snapshots)
+	byKey := make(map[string]query.OIChange, len(keys))
+	for i, key := range keys {
+		byKey[key] = changes[i]
+	}
+	return byKey, err
+}
+
+// storeOptionOI caches the open interest of held contracts
+func (a *App) storeOptionOI(changes map[string]query.OIChange) {
+	if a.book.optionOI == nil {
+		a.book.optionOI = make(map[string]query.OIChange)
+	}
+	for key, oi := range changes {
+		a.book.optionOI[key] = oi
+	}
+}
+
+// oiText is open interest with its change since the last day it was
+// recorded, green as contracts build up and red as they unwind
+func (b *book) oiText(oi query.OIChange) (string, tcell.Color) {
+	text := b.locale.FormatFloat(float64(oi.OpenInterest), 0)
+	if !oi.HasChange {
+		return text, tcell.ColorWhite
+	}
+	switch {
+	case oi.Change > 0:
+		return text + " (+" + b.locale.FormatFloat(float64(oi.Change), 0) + ")", tcell.ColorLime
+	case oi.Change < 0:
+		return text + " (-" + b.locale.FormatFloat(float64(-oi.Change), 0) + ")", tcell.ColorRed
+	}
+	return text + " (0)", tcell.ColorWhite
+}
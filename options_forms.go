@@ -0,0 +1,673 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/datespec"
+	"anyhowhodl/internal/db"
+
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// showAddOptionForm opens the new option form, prefilled from prefill if set.
+// onAdded, if set, runs once the option is saved.
+func (a *App) showAddOptionForm(prefill *db.Option, onAdded func()) {
+	// Expiry defaults to the coming weekly Friday
+	ticker, typeIndex, actionIndex, strike, qty, premium, notes := "", 0, 0, "", "1", "", ""
+	multiplier, settlementIndex := strconv.Itoa(db.DefaultMultiplier), 0
+	expiry := a.book.locale.FormatDate(datespec.NextFriday(a.today()))
+	currency := db.DefaultCurrency
+	if prefill != nil {
+		ticker, strike, qty = prefill.Ticker, a.book.locale.EditNumber(prefill.Strike.String()), strconv.Itoa(prefill.Quantity)
+		currency = a.book.tickerCurrency(prefill.Ticker)
+		if prefill.Currency != "" {
+			currency = prefill.Currency
+		}
+		if prefill.Multiplier > 0 {
+			multiplier = strconv.Itoa(prefill.Multiplier)
+		}
+		if prefill.Settlement == db.SettlementCash {
+			settlementIndex = 1
+		}
+		if prefill.OptionType == "PUT" {
+			typeIndex = 1
+		}
+		if prefill.Action == "BUY" {
+			actionIndex = 1
+		}
+		if !prefill.ExpiryDate.IsZero() {
+			expiry = a.book.locale.FormatDate(prefill.ExpiryDate)
+		}
+		if prefill.Premium.IsPositive() {
+			premium = a.book.locale.EditNumber(prefill.Premium.String())
+		}
+		notes = prefill.Notes
+	}
+
+	form := tview.NewForm().
+		AddInputField("Ticker", ticker, 10, nil, nil).
+		AddDropDown("Type", []string{"CALL", "PUT"}, typeIndex, nil).
+		AddDropDown("Action", []string{"SELL", "BUY"}, actionIndex, nil).
+		AddInputField("Strike ($)", strike, 15, nil, nil).
+		AddInputField("Expiry ("+a.book.locale.DateHint+")", expiry, 15, nil, nil).
+		AddInputField("Quantity", qty, 10, nil, nil).
+		AddInputField("Premium ($)", premium, 15, nil, nil).
+		AddInputField("Fee ($)", "0", 10, nil, nil).
+		AddInputField("Notes", notes, 30, nil, nil).
+		AddInputField("Shares/Contract", multiplier, 10, nil, nil).
+		AddDropDown("Settlement", settlements, settlementIndex, nil).
+		AddInputField("Currency", currency, 5, nil, nil).
+		AddCheckbox("Watch-only (other broker)", prefill != nil && prefill.External, nil)
+
+	// Validate as typed; the ticker is upper-cased as typed, and the currency
+	// follows the ticker's when it is known
+	checks := newFormChecks(form)
+	tickerField := form.GetFormItem(0).(*tview.InputField)
+	currencyField := form.GetFormItem(11).(*tview.InputField)
+	checks.add(form, 0, requiredCheck(nil), func(text string) {
+		if upper := strings.ToUpper(text); text != upper {
+			tickerField.SetText(upper)
+			return
+		}
+		currencyField.SetText(a.book.tickerCurrency(text))
+	})
+	a.tickerAutocomplete(tickerField)
+	checks.add(form, 3, requiredCheck(a.numberCheck(false)), nil)
+	checks.add(form, 4, requiredCheck(a.dateCheck(notPast)), nil)
+	a.dateField(form, 4)
+	checks.add(form, 5, requiredCheck(contractsCheck), nil)
+	checks.add(form, 6, requiredCheck(a.numberCheck(true)), nil)
+	checks.add(form, 7, optionalCheck(a.numberCheck(true)), nil)
+	checks.add(form, 9, requiredCheck(contractsCheck), nil)
+	checks.add(form, 11, requiredCheck(currencyCheck), nil)
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		if !checks.valid() {
+			a.statusBar.SetText(" [red]Fix the fields marked in red")
+			return
+		}
+		ticker := strings.ToUpper(form.GetFormItem(0).(*tview.InputField).GetText())
+		_, optionType := form.GetFormItem(1).(*tview.DropDown).GetCurrentOption()
+		_, action := form.GetFormItem(2).(*tview.DropDown).GetCurrentOption()
+		strikeStr := form.GetFormItem(3).(*tview.InputField).GetText()
+		expiryStr := form.GetFormItem(4).(*tview.InputField).GetText()
+		qtyStr := form.GetFormItem(5).(*tview.InputField).GetText()
+		premiumStr := form.GetFormItem(6).(*tview.InputField).GetText()
+		feeStr := form.GetFormItem(7).(*tview.InputField).GetText()
+		notes := form.GetFormItem(8).(*tview.InputField).GetText()
+		multiplier, err := strconv.Atoi(form.GetFormItem(9).(*tview.InputField).GetText())
+		if err != nil || multiplier < 1 {
+			a.statusBar.SetText(" [red]Invalid shares per contract")
+			return
+		}
+		_, settlement := form.GetFormItem(10).(*tview.DropDown).GetCurrentOption()
+		currency, _ := parseCurrency(currencyField.GetText())
+		external := form.GetFormItem(12).(*tview.Checkbox).IsChecked()
+
+		if ticker == "" || strikeStr == "" || expiryStr == "" || premiumStr == "" {
+			a.statusBar.SetText(" [red]Ticker, Strike, Expiry, and Premium are required")
+			return
+		}
+
+		strike, err := a.book.locale.ParseNumber(strikeStr)
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid strike price")
+			return
+		}
+
+		expiry, err := a.parseDate(expiryStr)
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid expiry date format")
+			return
+		}
+
+		qty, err := strconv.Atoi(qtyStr)
+		if err != nil || qty < 1 {
+			a.statusBar.SetText(" [red]Invalid quantity")
+			return
+		}
+
+		premium, err := a.book.locale.ParseNumber(premiumStr)
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid premium")
+			return
+		}
+
+		openFee := decimal.Zero
+		if feeStr != "" {
+			openFee, err = a.book.locale.ParseNumber(feeStr)
+			if err != nil {
+				a.statusBar.SetText(" [red]Invalid fee")
+				return
+			}
+		}
+
+		done := func() {
+			a.pages.SwitchToPage("main")
+			a.pages.RemovePage("addoption")
+			if onAdded != nil {
+				onAdded()
+			}
+			a.refreshData()
+		}
+		save := func() {
+			ctx := context.Background()
+			add := a.db.AddOption
+			if external {
+				// Held at another broker: shown here, but cash is not touched
+				add = a.db.AddExternalOption
+			} else if _, err := ensureFXRate(ctx, a.db, a.yahoo, currency); err != nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Error getting the %s exchange rate: %v", currency, err))
+				return
+			}
+			if err := add(ctx, ticker, optionType, action, strike, expiry, qty, multiplier, settlement, currency, premium, openFee, notes); err != nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+				return
+			}
+			if err := a.db.SetTickerCurrency(ctx, ticker, currency); err != nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Error saving currency: %v", err))
+				return
+			}
+			done()
+		}
+
+		// A short put adds its collateral to the ticker's exposure
+		checkCaps := func(save func()) {
+			if action == "SELL" && optionType == "PUT" && !external {
+				a.confirmRiskCaps(ticker, strike.InexactFloat64()*float64(qty*multiplier), save)
+				return
+			}
+			save()
+		}
+
+		// The same contract already open is likely a double entry
+		if dup := a.duplicateOption(ticker, optionType, action, strike, expiry, external); dup != nil {
+			var merge func()
+			if dup.Multiplier == multiplier && dup.Settlement == settlement {
+				merge = func() {
+					checkCaps(func() {
+						if err := a.db.AddToOption(context.Background(), dup.ID, qty, premium, openFee, notes); err != nil {
+							a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+							return
+						}
+						done()
+					})
+				}
+			}
+			a.confirmDuplicateOption(*dup, qty, premium, merge, func() { checkCaps(save) })
+			return
+		}
+		checkCaps(save)
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.SwitchToPage("main")
+		a.pages.RemovePage("addoption")
+	})
+
+	form.SetBorder(true).SetTitle(" Add Option ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("addoption", form, 60, 31)
+}
+
+// duplicateOption returns the active option on the same contract, bought or
+// sold the same way and in the same book (own or watch-only), or nil
+func (a *App) duplicateOption(ticker, optionType, action string, strike decimal.Decimal, expiry time.Time, external bool) *db.Option {
+	for i, o := range a.book.options {
+		if o.Status == "ACTIVE" && o.Ticker == ticker && o.OptionType == optionType && o.Action == action &&
+			o.Strike.Equal(strike) && o.ExpiryDate.Format("2006-01-02") == expiry.Format("2006-01-02") && o.External == external {
+			return &a.book.options[i]
+		}
+	}
+	return nil
+}
+
+// confirmDuplicateOption warns that dup is already open and offers to add the
+// qty new contracts to it, averaging the premium, or to add them as a separate
+// row. merge is nil when the contracts differ in multiplier or settlement and
+// cannot be combined.
+func (a *App) confirmDuplicateOption(dup db.Option, qty int, premium decimal.Decimal, merge, add func()) {
+	avg := dup.Premium.Mul(decimal.NewFromInt(int64(dup.Quantity))).
+		Add(premium.Mul(decimal.NewFromInt(int64(qty)))).
+		Div(decimal.NewFromInt(int64(dup.Quantity + qty)))
+	text := fmt.Sprintf("%s %s %s $%s %s is already open: %d contract(s) at $%s.\n\n",
+		dup.Action, dup.Ticker, dup.OptionType, a.book.formatPrice(dup.Strike), a.book.locale.FormatDate(dup.ExpiryDate),
+		dup.Quantity, a.book.formatPrice(dup.Premium))
+	buttons := []string{"Add anyway", "Cancel"}
+	if merge != nil {
+		text += fmt.Sprintf("Merge to make it %d contract(s) at an average $%s?", dup.Quantity+qty, a.book.formatPrice(avg))
+		buttons = append([]string{"Merge"}, buttons...)
+	} else {
+		text += "It has a different multiplier or settlement, so it cannot be merged."
+	}
+
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons(buttons).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("duplicate")
+			switch buttonLabel {
+			case "Merge":
+				merge()
+			case "Add anyway":
+				add()
+			}
+		})
+	a.pages.AddPage("duplicate", modal, true, true)
+}
+
+func (a *App) showOptionActions(index int) {
+	if a.readOnly() {
+		return
+	}
+	o := a.book.options[index]
+
+	typeStr := o.OptionType
+	actionDesc := "You receive shares"
+	if typeStr == "CALL" {
+		actionDesc = "Your shares get called away"
+	}
+	if o.Settlement == db.SettlementCash {
+		actionDesc = "Cash-settled at intrinsic value, no shares move"
+	}
+	if o.External {
+		actionDesc = "Marked assigned only; shares and cash are at the other broker"
+	}
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("%s %s %s $%s\nExpires: %s\n\nAssign: %s", o.Action, o.Ticker, typeStr, a.book.formatPrice(o.Strike), a.book.locale.FormatDate(o.ExpiryDate), actionDesc)).
+		AddButtons([]string{"Edit", "Close", "Assign", "Expire", "Remind", "Delete", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			switch buttonLabel {
+			case "Edit":
+				a.pages.RemovePage("optionactions")
+				a.showEditOptionForm(index)
+			case "Remind":
+				a.pages.RemovePage("optionactions")
+				a.showReminderForm(index)
+			case "Close":
+				a.pages.RemovePage("optionactions")
+				a.showCloseOptionForm(index, "", nil)
+			case "Assign":
+				a.pages.RemovePage("optionactions")
+				a.confirmAssignOption(index)
+			case "Expire":
+				a.pages.RemovePage("optionactions")
+				a.confirmExpireOption(index)
+			case "Delete":
+				a.pages.RemovePage("optionactions")
+				a.confirmDeleteOption(index)
+			default:
+				a.pages.RemovePage("optionactions")
+			}
+		})
+
+	a.pages.AddPage("optionactions", modal, true, true)
+}
+
+func (a *App) showEditOptionForm(index int) {
+	o := a.book.options[index]
+
+	form := tview.NewForm().
+		AddInputField("Strike ($)", a.book.locale.EditNumber(o.Strike.String()), 15, nil, nil).
+		AddInputField("Expiry ("+a.book.locale.DateHint+")", a.book.locale.FormatDate(o.ExpiryDate), 15, nil, nil).
+		AddInputField("Quantity", fmt.Sprintf("%d", o.Quantity), 10, nil, nil).
+		AddInputField("Premium ($)", a.book.locale.EditNumber(o.Premium.String()), 15, nil, nil).
+		AddInputField("Fee ($)", a.book.locale.EditNumber(o.OpenFee.String()), 10, nil, nil).
+		AddInputField("Notes", o.Notes, 30, nil, nil).
+		AddInputField("Shares/Contract", strconv.Itoa(o.Multiplier), 10, nil, nil).
+		AddDropDown("Settlement", settlements, max(slices.Index(settlements, o.Settlement), 0), nil)
+
+	// An active option cannot be moved to an expiry already past; closed and
+	// expired ones keep whatever date they had
+	var expiryBounds []dateBound
+	if o.Status == "ACTIVE" {
+		expiryBounds = append(expiryBounds, notPast)
+	}
+	checks := newFormChecks(form)
+	checks.add(form, 0, requiredCheck(a.numberCheck(false)), nil)
+	checks.add(form, 1, requiredCheck(a.dateCheck(expiryBounds...)), nil)
+	a.dateField(form, 1)
+	checks.add(form, 2, requiredCheck(contractsCheck), nil)
+	checks.add(form, 3, requiredCheck(a.numberCheck(true)), nil)
+	checks.add(form, 4, optionalCheck(a.numberCheck(true)), nil)
+	checks.add(form, 6, requiredCheck(contractsCheck), nil)
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		if !checks.valid() {
+			a.statusBar.SetText(" [red]Fix the fields marked in red")
+			return
+		}
+		strikeStr := form.GetFormItem(0).(*tview.InputField).GetText()
+		expiryStr := form.GetFormItem(1).(*tview.InputField).GetText()
+		qtyStr := form.GetFormItem(2).(*tview.InputField).GetText()
+		premiumStr := form.GetFormItem(3).(*tview.InputField).GetText()
+		feeStr := form.GetFormItem(4).(*tview.InputField).GetText()
+		notes := form.GetFormItem(5).(*tview.InputField).GetText()
+		multiplier, err := strconv.Atoi(form.GetFormItem(6).(*tview.InputField).GetText())
+		if err != nil || multiplier < 1 {
+			a.statusBar.SetText(" [red]Invalid shares per contract")
+			return
+		}
+		_, settlement := form.GetFormItem(7).(*tview.DropDown).GetCurrentOption()
+
+		strike, err := a.book.locale.ParseNumber(strikeStr)
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid strike price")
+			return
+		}
+
+		expiry, err := a.parseDate(expiryStr)
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid expiry date format")
+			return
+		}
+
+		qty, err := strconv.Atoi(qtyStr)
+		if err != nil || qty < 1 {
+			a.statusBar.SetText(" [red]Invalid quantity")
+			return
+		}
+
+		premium, err := a.book.locale.ParseNumber(premiumStr)
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid premium")
+			return
+		}
+
+		fee := decimal.Zero
+		if feeStr != "" {
+			fee, err = a.book.locale.ParseNumber(feeStr)
+			if err != nil {
+				a.statusBar.SetText(" [red]Invalid fee")
+				return
+			}
+		}
+
+		saved := func(err error) {
+			if err != nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+				return
+			}
+			a.statusBar.SetText(fmt.Sprintf(" [green]Updated: %s %s $%s", o.Ticker, o.OptionType, a.book.formatPrice(strike)))
+			a.pages.SwitchToPage("main")
+			a.pages.RemovePage("editoption")
+			a.refreshData()
+		}
+
+		ctx := context.Background()
+		err = a.db.UpdateOptionIfUnchanged(ctx, o.ID, o.UpdatedAt, strike, expiry, qty, multiplier, settlement, premium, fee, notes)
+		if errors.Is(err, db.ErrConflict) {
+			a.showConflictPrompt(fmt.Sprintf("%s %s $%s", o.Ticker, o.OptionType, a.book.formatPrice(o.Strike)), func() {
+				saved(a.db.UpdateOption(ctx, o.ID, strike, expiry, qty, multiplier, settlement, premium, fee, notes))
+			}, func() {
+				a.pages.RemovePage("editoption")
+				a.refreshData()
+				for i := range a.book.options {
+					if a.book.options[i].ID == o.ID {
+						a.showEditOptionForm(i)
+					}
+				}
+			})
+			return
+		}
+		saved(err)
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.SwitchToPage("main")
+		a.pages.RemovePage("editoption")
+	})
+
+	form.SetBorder(true).SetTitle(fmt.Sprintf(" Edit %s %s %s ", o.Action, o.Ticker, o.OptionType)).SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("editoption", form, 60, 25)
+}
+
+func (a *App) confirmDeleteOption(index int) {
+	o := a.book.options[index]
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Delete %s %s $%s?", o.Ticker, o.OptionType, a.book.formatPrice(o.Strike))).
+		AddButtons([]string{"Delete", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel == "Delete" {
+				ctx := context.Background()
+				if err := a.db.DeleteOption(ctx, o.ID); err != nil {
+					a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+				}
+				a.refreshData()
+			}
+			a.pages.RemovePage("confirmoption")
+		})
+
+	a.pages.AddPage("confirmoption", modal, true, true)
+}
+
+func (a *App) confirmAssignOption(index int) {
+	o := a.book.options[index]
+	if o.Settlement == db.SettlementCash {
+		a.confirmSettleOption(o)
+		return
+	}
+
+	shares := o.Quantity * o.Multiplier
+	totalValue := o.Strike.Mul(o.Shares())
+
+	ctx := context.Background()
+	fee := a.assignmentFee(ctx, o)
+
+	var actionText string
+	if o.OptionType == "PUT" {
+		actionText = fmt.Sprintf("BUY %d shares of %s @ $%s\nCash: -$%s",
+			shares, o.Ticker, a.book.formatPrice(o.Strike), a.book.locale.FormatFixed(totalValue.Add(fee), 2))
+	} else {
+		actionText = fmt.Sprintf("SELL %d shares of %s @ $%s\nCash: +$%s",
+			shares, o.Ticker, a.book.formatPrice(o.Strike), a.book.locale.FormatFixed(totalValue.Sub(fee), 2))
+	}
+	if fee.IsPositive() {
+		actionText += fmt.Sprintf(" (after $%s assignment fee)", a.book.locale.FormatFixed(fee, 2))
+	}
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Assign %s %s $%s?\n\n%s", o.Ticker, o.OptionType, a.book.formatPrice(o.Strike), actionText)).
+		AddButtons([]string{"Confirm", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel == "Confirm" {
+				if err := a.db.AssignOption(ctx, o.ID, fee); err != nil {
+					a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+				} else {
+					a.statusBar.SetText(fmt.Sprintf(" [green]Option assigned: %s %s", o.Ticker, o.OptionType))
+				}
+				a.refreshData()
+			}
+			a.pages.RemovePage("confirmassign")
+		})
+
+	a.pages.AddPage("confirmassign", modal, true, true)
+}
+
+// confirmSettleOption assigns a cash-settled option at the underlying's
+// current price: its intrinsic value changes hands and no shares move
+func (a *App) confirmSettleOption(o db.Option) {
+	q, ok := a.book.quotes[o.Ticker]
+	if !ok || q.Price <= 0 {
+		a.statusBar.SetText(fmt.Sprintf(" [red]No quote for %s to settle against", o.Ticker))
+		return
+	}
+	price := decimal.NewFromFloat(q.Price)
+	intrinsic := o.IntrinsicValue(price)
+	settlement := intrinsic.Mul(o.Shares())
+
+	ctx := context.Background()
+	fee := a.assignmentFee(ctx, o)
+
+	cashText := "+$" + a.book.locale.FormatFixed(settlement.Sub(fee), 2)
+	if o.Action == "SELL" {
+		cashText = "-$" + a.book.locale.FormatFixed(settlement.Add(fee), 2)
+	}
+	if o.External {
+		cashText = "unchanged, settled at the other broker"
+	}
+	text := fmt.Sprintf("Cash-settle %s %s $%s at $%s?\n\nIntrinsic value $%s/share, no shares exchanged\nCash: %s",
+		o.Ticker, o.OptionType, a.book.formatPrice(o.Strike), a.book.formatPrice(price), a.book.formatPrice(intrinsic), cashText)
+	if fee.IsPositive() && !o.External {
+		text += fmt.Sprintf(" (including $%s assignment fee)", a.book.locale.FormatFixed(fee, 2))
+	}
+
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"Confirm", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel == "Confirm" {
+				if err := a.db.SettleOption(ctx, o.ID, price, fee); err != nil {
+					a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+				} else {
+					a.statusBar.SetText(fmt.Sprintf(" [green]Option cash-settled: %s %s", o.Ticker, o.OptionType))
+				}
+				a.refreshData()
+			}
+			a.pages.RemovePage("confirmassign")
+		})
+
+	a.pages.AddPage("confirmassign", modal, true, true)
+}
+
+func (a *App) confirmExpireOption(index int) {
+	o := a.book.options[index]
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Mark %s %s $%s as expired?\n\nOption expires worthless, no shares exchanged.", o.Ticker, o.OptionType, a.book.formatPrice(o.Strike))).
+		AddButtons([]string{"Confirm", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel == "Confirm" {
+				ctx := context.Background()
+				if err := a.db.ExpireOption(ctx, o.ID); err != nil {
+					a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+				} else {
+					a.statusBar.SetText(fmt.Sprintf(" [green]Option expired: %s %s", o.Ticker, o.OptionType))
+				}
+				a.refreshData()
+			}
+			a.pages.RemovePage("confirmexpire")
+		})
+
+	a.pages.AddPage("confirmexpire", modal, true, true)
+}
+
+// showCloseOptionForm closes a position, prefilled with closePremium if set.
+// onClosed, if set, runs after the close is saved and data refreshed.
+func (a *App) showCloseOptionForm(index int, closePremium string, onClosed func()) {
+	o := a.book.options[index]
+
+	closeAction := "Buy back"
+	if o.Action == "BUY" {
+		closeAction = "Sell"
+	}
+
+	form := tview.NewForm().
+		AddInputField("Close Premium ($)", closePremium, 15, nil, nil).
+		AddInputField("Close Fee ($)", "0", 10, nil, nil)
+
+	styleForm(form)
+
+	form.AddButton("Close Position", func() {
+		closePremiumStr := form.GetFormItem(0).(*tview.InputField).GetText()
+		closeFeeStr := form.GetFormItem(1).(*tview.InputField).GetText()
+
+		if closePremiumStr == "" {
+			a.statusBar.SetText(" [red]Close premium is required")
+			return
+		}
+
+		closePremium, err := a.book.locale.ParseNumber(closePremiumStr)
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid close premium")
+			return
+		}
+
+		closeFee := decimal.Zero
+		if closeFeeStr != "" {
+			closeFee, err = a.book.locale.ParseNumber(closeFeeStr)
+			if err != nil {
+				a.statusBar.SetText(" [red]Invalid close fee")
+				return
+			}
+		}
+
+		ctx := context.Background()
+		if err := a.db.CloseOption(ctx, o.ID, closePremium, closeFee); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+
+		a.statusBar.SetText(fmt.Sprintf(" [green]Position closed: %s %s", o.Ticker, o.OptionType))
+		a.pages.SwitchToPage("main")
+		a.pages.RemovePage("closeoption")
+		a.refreshData()
+		if onClosed != nil {
+			onClosed()
+		}
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.SwitchToPage("main")
+		a.pages.RemovePage("closeoption")
+	})
+
+	form.SetBorder(true).SetTitle(fmt.Sprintf(" %s %s %s $%s ", closeAction, o.Ticker, o.OptionType, a.book.formatPrice(o.Strike))).SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("closeoption", form, 50, 10)
+}
+
+// profitTargets are the shares of a short option's premium offered to lock
+// in by buying it back, in percent
+var profitTargets = []int{50, 75}
+
+// profitClosePremium is the buy-back price that keeps pct percent of the
+// premium received, to the cent
+func profitClosePremium(premium decimal.Decimal, pct int) decimal.Decimal {
+	return premium.Mul(decimal.NewFromInt(int64(100 - pct))).Div(decimal.NewFromInt(100)).Round(2)
+}
+
+// showTakeProfit offers to close a short option at one of the profitTargets,
+// opening the close form prefilled with that buy-back price
+func (a *App) showTakeProfit(index int) {
+	o := a.book.options[index]
+	if o.Status != "ACTIVE" || o.Action != "SELL" {
+		a.statusBar.SetText(" [yellow]Take profit is for active short options")
+		return
+	}
+
+	buttons := make([]string, 0, len(profitTargets)+1)
+	prices := make(map[string]decimal.Decimal)
+	for _, pct := range profitTargets {
+		price := profitClosePremium(o.Premium, pct)
+		label := fmt.Sprintf("%d%% ($%s)", pct, a.book.locale.FormatFixed(price, 2))
+		buttons = append(buttons, label)
+		prices[label] = price
+	}
+	buttons = append(buttons, "Cancel")
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Take profit on %s %s %s $%s\nSold at $%s: buy back at", o.Action, o.Ticker, o.OptionType, a.book.formatPrice(o.Strike), a.book.locale.FormatFixed(o.Premium, 2))).
+		AddButtons(buttons).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("takeprofit")
+			if price, ok := prices[buttonLabel]; ok {
+				a.showCloseOptionForm(index, a.book.locale.EditNumber(price.StringFixed(2)), nil)
+			}
+		})
+	a.pages.AddPage("takeprofit", modal, true, true)
+}
@@ -0,0 +1,727 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/db"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// OptionsView is the options section: premium stats, the expiration week
+// panel, the options table with its status counts, and the expiry timeline
+type OptionsView struct {
+	table          *tview.Table
+	title          *tview.TextView // Status counts and filter above the table
+	timeline       *tview.TextView // Premium stats
+	expiryWeek     *tview.TextView // Nearest expiration week risk
+	expiryTimeline *tview.TextView // Visual expiry timeline
+	filter         string          // Quick filter on the options table
+	rows           []int           // Index in options of each table row
+	weeklyView     bool            // Toggle between weekly and monthly timeline view
+	showExpired    bool            // Show expired options toggle
+	hiddenStatuses map[string]bool // Option statuses other than EXPIRED toggled off
+
+	book  *book
+	cells optionsCells
+}
+
+// optionsCells are the options table's cells other features fill in, and the
+// clock days to expiry count from
+type optionsCells interface {
+	rowBackground(id string) tcell.Color
+	optionEarningsCell(o db.Option, bg tcell.Color) *tview.TableCell
+	now() time.Time
+}
+
+// newOptionsView builds the options section of b, redrawn whenever the data
+// loads, starting with the weekly timeline and expired options shown; the
+// App wires its selection
+func newOptionsView(b *book, cells optionsCells, bus *eventBus) *OptionsView {
+	v := &OptionsView{
+		table: tview.NewTable().
+			SetBorders(true).
+			SetSelectable(true, false).
+			SetFixed(1, 0).
+			SetSeparator(' ').
+			SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorDarkSlateGray)),
+		timeline:       tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignLeft),
+		expiryTimeline: tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignLeft),
+		expiryWeek:     tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignLeft),
+		title:          tview.NewTextView().SetDynamicColors(true),
+		weeklyView:     true,
+		showExpired:    true,
+		hiddenStatuses: make(map[string]bool),
+		book:           b,
+		cells:          cells,
+	}
+	v.timeline.SetBorder(true).SetTitle(" Option Premium Stats ").SetTitleAlign(tview.AlignLeft).SetBorderColor(tcell.ColorTeal)
+	v.expiryTimeline.SetBorder(true).SetTitle(" Expiry Timeline ").SetTitleAlign(tview.AlignLeft).SetBorderColor(tcell.ColorTeal)
+	v.expiryWeek.SetBorder(true).SetTitle(" Expiration Week ").SetTitleAlign(tview.AlignLeft).SetBorderColor(tcell.ColorTeal)
+
+	bus.subscribe(eventDataLoaded, v.update)
+	bus.subscribe(eventDataLoaded, v.updateTimeline)
+	return v
+}
+
+// update redraws the options table and the status counts above it
+func (v *OptionsView) update() {
+	v.table.Clear()
+
+	// Header row
+	headers := []string{"TICKER", "TYPE", "ACTION", "STRIKE", "EXPIRY", "QTY", "PREMIUM", "FEE", "STATUS", "OI (CHG)", "MAX LOSS", "EARNINGS"}
+	for i, h := range headers {
+		cell := tview.NewTableCell(" " + h + " ").
+			SetTextColor(tcell.ColorBlack).
+			SetBackgroundColor(tcell.ColorTeal).
+			SetAlign(tview.AlignLeft).
+			SetSelectable(false).
+			SetExpansion(1)
+		v.table.SetCell(0, i, cell)
+	}
+	columns := v.book.columnsFor(db.ColumnTableOptions)
+	custom := len(headers)
+	if v.book.showTags {
+		setTagsHeader(v.table, custom)
+		custom++
+	}
+	setCustomHeaders(v.table, columns, custom)
+
+	today := v.cells.now().Truncate(24 * time.Hour)
+	risks := v.book.optionRisks()
+
+	row := 0
+	v.rows = v.rows[:0]
+	for i, o := range v.book.options {
+		// Skip hidden statuses, and the options the filter hides
+		if !v.visible(o) {
+			continue
+		}
+		v.rows = append(v.rows, i)
+		row++
+		rowBg := v.cells.rowBackground(o.ID)
+
+		// Dim colors for non-active options
+		isActive := o.Status == "ACTIVE"
+		dimColor := tcell.ColorDimGray
+
+		// Ticker, in silver and marked ext when watch-only, and marked cash
+		// when cash-settled
+		tickerColor := tcell.ColorFuchsia
+		tickerText := o.Ticker
+		if o.External {
+			tickerColor = tcell.ColorSilver
+			tickerText += " ext"
+		}
+		if o.Settlement == db.SettlementCash {
+			tickerText += " cash"
+		}
+		if !isActive {
+			tickerColor = dimColor
+		}
+		v.table.SetCell(row, 0, tview.NewTableCell(v.book.tickerLabel(o.ID, tickerText)).
+			SetTextColor(tickerColor).
+			SetBackgroundColor(rowBg).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		// Type (CALL/PUT)
+		typeColor := tcell.ColorLime
+		if o.OptionType == "PUT" {
+			typeColor = tcell.ColorRed
+		}
+		if !isActive {
+			typeColor = dimColor
+		}
+		v.table.SetCell(row, 1, tview.NewTableCell(" "+o.OptionType+" ").
+			SetTextColor(typeColor).
+			SetBackgroundColor(rowBg).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		// Action (BUY/SELL)
+		actionColor := tcell.ColorYellow
+		if o.Action == "SELL" {
+			actionColor = tcell.ColorAqua
+		}
+		if !isActive {
+			actionColor = dimColor
+		}
+		v.table.SetCell(row, 2, tview.NewTableCell(" "+o.Action+" ").
+			SetTextColor(actionColor).
+			SetBackgroundColor(rowBg).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		// Strike
+		strikeColor := tcell.ColorWhite
+		if !isActive {
+			strikeColor = dimColor
+		}
+		// Strike, premium, and fee are in the option's currency
+		symbol := currencySymbol(rowCurrency(o.Currency))
+		v.table.SetCell(row, 3, tview.NewTableCell(" "+symbol+v.book.formatPrice(o.Strike)+" ").
+			SetTextColor(strikeColor).
+			SetBackgroundColor(rowBg).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		// Expiry
+		expiryColor := tcell.ColorWhite
+		if !isActive {
+			expiryColor = dimColor
+		}
+		v.table.SetCell(row, 4, tview.NewTableCell(" "+v.book.locale.FormatDate(o.ExpiryDate)+" ").
+			SetTextColor(expiryColor).
+			SetBackgroundColor(rowBg).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		// Quantity
+		qtyColor := tcell.ColorWhite
+		if !isActive {
+			qtyColor = dimColor
+		}
+		qtyText := fmt.Sprintf("%d", o.Quantity)
+		if o.Multiplier != db.DefaultMultiplier {
+			qtyText += fmt.Sprintf(" ×%d", o.Multiplier)
+		}
+		v.table.SetCell(row, 5, tview.NewTableCell(" "+qtyText+" ").
+			SetTextColor(qtyColor).
+			SetBackgroundColor(rowBg).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		// Premium
+		premiumColor := tcell.ColorYellow
+		if !isActive {
+			premiumColor = dimColor
+		}
+		v.table.SetCell(row, 6, tview.NewTableCell(" "+symbol+v.book.formatPrice(o.Premium)+" ").
+			SetTextColor(premiumColor).
+			SetBackgroundColor(rowBg).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		// Fee
+		feeText := " - "
+		if !o.OpenFee.IsZero() {
+			feeText = " " + symbol + v.book.locale.FormatFixed(o.OpenFee, 2) + " "
+		}
+		feeColor := tcell.ColorOrange
+		if !isActive {
+			feeColor = dimColor
+		}
+		v.table.SetCell(row, 7, tview.NewTableCell(feeText).
+			SetTextColor(feeColor).
+			SetBackgroundColor(rowBg).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		// Status with color coding
+		statusColor := tcell.ColorLime
+		statusText := o.Status
+		if o.Status == "EXPIRED" {
+			statusColor = tcell.ColorGray
+		} else if o.Status == "ASSIGNED" {
+			statusColor = tcell.ColorYellow
+		} else if o.Status == "CLOSED" {
+			statusColor = tcell.ColorAqua
+		}
+		// Add days left for ACTIVE options
+		if o.Status == "ACTIVE" {
+			daysLeft := int(o.ExpiryDate.Sub(today).Hours() / 24)
+			if daysLeft < 0 {
+				statusText = "EXPD"
+				statusColor = tcell.ColorRed
+			} else {
+				statusText = fmt.Sprintf("%dd", daysLeft)
+				if daysLeft <= 7 {
+					statusColor = tcell.ColorRed
+				} else if daysLeft <= 14 {
+					statusColor = tcell.ColorYellow
+				} else if daysLeft <= 30 {
+					statusColor = tcell.ColorOrange
+				}
+			}
+		}
+		v.table.SetCell(row, 8, tview.NewTableCell(" "+statusText+" ").
+			SetTextColor(statusColor).
+			SetBackgroundColor(rowBg).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		// Open interest of held short contracts, with its daily change
+		oiText, oiColor := " - ", tcell.ColorWhite
+		if oi, ok := v.book.optionOI[contractKey(o.Ticker, o.OptionType, o.ExpiryDate, o.Strike.InexactFloat64())]; ok && isShortOption(o) {
+			oiText, oiColor = v.book.oiText(oi)
+			oiText = " " + oiText + " "
+		}
+		if !isActive {
+			oiColor = dimColor
+		}
+		v.table.SetCell(row, 9, tview.NewTableCell(oiText).
+			SetTextColor(oiColor).
+			SetBackgroundColor(rowBg).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		// Worst case at expiry: collateral for short puts, a spread's defined loss
+		lossText, lossColor := " - ", tcell.ColorWhite
+		if r, ok := risks[o.ID]; ok {
+			switch {
+			case r.Unbounded:
+				lossText, lossColor = " unlimited ", tcell.ColorOrange
+			case r.Covered:
+				lossText, lossColor = " covered ", tcell.ColorSilver
+			case !r.Counted:
+				lossText, lossColor = " in spread ", tcell.ColorSilver
+			default:
+				lossText = " " + v.book.baseSymbol() + v.book.locale.FormatFixed(r.Loss, 2) + " "
+				if r.Spread {
+					lossText = " " + v.book.baseSymbol() + v.book.locale.FormatFixed(r.Loss, 2) + " spr "
+				}
+			}
+		}
+		v.table.SetCell(row, 10, tview.NewTableCell(lossText).
+			SetTextColor(lossColor).
+			SetBackgroundColor(rowBg).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		// Next earnings date, red when a short is open through it
+		v.table.SetCell(row, 11, v.cells.optionEarningsCell(o, rowBg))
+
+		if v.book.showTags {
+			v.table.SetCell(row, custom-1, v.book.tagsCell(o.ID, rowBg))
+		}
+
+		// User-defined columns
+		v.book.setCustomCells(v.table, row, columns, custom, v.book.optionVars(o, today), rowBg)
+	}
+	v.setTitle(row)
+}
+
+func (v *OptionsView) updateTimeline() {
+	now := v.cells.now()
+	yearStart, _ := v.book.taxYear.Bounds(now)
+
+	// Premium summary line with fees and net P&L, in the base currency
+	symbol := v.book.baseSymbol()
+	premiumText := fmt.Sprintf(" [teal]%s Premiums:[white] Calls: [lime]%s%s[white]  Puts: [lime]%s%s[white]  Gross: [yellow]%s%s[white]",
+		v.book.taxYear.Label(yearStart),
+		symbol, v.book.locale.FormatFixed(v.book.premiums.CallPremiums, 2),
+		symbol, v.book.locale.FormatFixed(v.book.premiums.PutPremiums, 2),
+		symbol, v.book.locale.FormatFixed(v.book.premiums.TotalPremiums, 2))
+
+	// Add fees and close costs if any
+	if !v.book.premiums.TotalFees.IsZero() || !v.book.premiums.CloseCosts.IsZero() {
+		premiumText += fmt.Sprintf("  Fees: [red]-%s%s[white]", symbol, v.book.locale.FormatFixed(v.book.premiums.TotalFees, 2))
+		if !v.book.premiums.CloseCosts.IsZero() {
+			premiumText += fmt.Sprintf("  BuyBack: [red]-%s%s[white]", symbol, v.book.locale.FormatFixed(v.book.premiums.CloseCosts, 2))
+		}
+	}
+
+	// Net P&L
+	netColor := "lime"
+	if v.book.premiums.NetPL.IsNegative() {
+		netColor = "red"
+	}
+	premiumText += fmt.Sprintf("  Net: [%s]%s%s[white]", netColor, symbol, v.book.locale.FormatFixed(v.book.premiums.NetPL, 2))
+
+	// Calculate return % and annualized % based on capital at risk
+	if !v.book.premiums.CapitalAtRisk.IsZero() {
+		returnPct := v.book.premiums.NetPL.Div(v.book.premiums.CapitalAtRisk).Mul(decimal.NewFromInt(100))
+
+		// Days elapsed in current tax year
+		daysElapsed := now.Sub(yearStart).Hours() / 24
+		if daysElapsed < 1 {
+			daysElapsed = 1 // Avoid division by zero on the first day
+		}
+
+		// Annualized return
+		annualizedPct := returnPct.Mul(decimal.NewFromFloat(365.0 / daysElapsed))
+
+		returnColor := "lime"
+		if returnPct.IsNegative() {
+			returnColor = "red"
+		}
+		premiumText += fmt.Sprintf("  Return: [%s]%s%%[white]  Ann: [%s]%s%%[white]",
+			returnColor, v.book.locale.FormatFixed(returnPct, 2),
+			returnColor, v.book.locale.FormatFixed(annualizedPct, 2))
+	}
+
+	// Share of the year's settled short options that kept a net credit
+	if wins, settled := v.premiumWinRate(yearStart, now); settled > 0 {
+		premiumText += fmt.Sprintf("  Win: [yellow]%d%%[white] [gray](%d/%d)[white]", wins*100/settled, wins, settled)
+	}
+
+	// Time decay the short options earn per day at current prices
+	if income, priced, shorts := v.book.dailyThetaIncome(v.cells.now()); shorts > 0 {
+		premiumText += fmt.Sprintf("  Theta: [lime]$%s/day[white]", v.book.locale.FormatFloat(income, 2))
+		if priced < shorts {
+			// Some positions are unquoted or their IV has not loaded yet
+			premiumText += fmt.Sprintf(" [gray](%d/%d)[white]", priced, shorts)
+		}
+	}
+
+	// Worst case across the open options, those with unlimited risk apart
+	if risks := v.book.optionRisks(); len(risks) > 0 {
+		loss, unbounded := totalMaxLoss(risks)
+		premiumText += fmt.Sprintf("  Max loss: [red]%s%s[white]", symbol, v.book.locale.FormatFixed(loss, 2))
+		if unbounded > 0 {
+			premiumText += fmt.Sprintf(" [orange]+%d unlimited[white]", unbounded)
+		}
+	}
+
+	v.timeline.SetText(premiumText)
+
+	// Update the visual expiry timeline
+	v.updateExpiryTimeline()
+	v.updateExpiryWeek()
+}
+
+// premiumWinRate counts the short options opened in [from, to) that are no
+// longer active, and the wins among them: those whose premium exceeded the
+// cost of closing and the fees. Assigned options keep their premium, so they
+// count as wins; the shares' own P/L is tracked with the holding.
+func (v *OptionsView) premiumWinRate(from, to time.Time) (wins, settled int) {
+	for _, o := range v.book.options {
+		if o.Action != "SELL" || o.Status == "ACTIVE" || o.CreatedAt.Before(from) || !o.CreatedAt.Before(to) {
+			continue
+		}
+		settled++
+		net := o.Premium.Sub(o.ClosePremium.Decimal).Mul(o.Shares()).Sub(o.OpenFee).Sub(o.CloseFee.Decimal)
+		if net.IsPositive() {
+			wins++
+		}
+	}
+	return wins, settled
+}
+
+func (v *OptionsView) updateExpiryWeek() {
+	var positions []analytics.OptionPosition
+	for _, o := range v.book.options {
+		if o.Status == "ACTIVE" {
+			positions = append(positions, optionPosition(o))
+		}
+	}
+
+	risk, ok := analytics.NearestExpiryWeek(positions, v.book.quotePrices(), v.cells.now())
+	if !ok {
+		v.expiryWeek.SetText(" [gray]No upcoming expirations")
+		return
+	}
+
+	cashColor := "lime"
+	if risk.NetAssignmentCash < 0 {
+		cashColor = "red"
+	}
+	v.expiryWeek.SetText(fmt.Sprintf(" [teal]%s - %s:[white] Contracts: [yellow]%d[white]  Collateral at risk: [aqua]$%s[white]  Callable: [yellow]%s sh[white]  ITM: [yellow]%d[white]  Net cash if ITM assigned: [%s]%s[white]",
+		v.book.locale.FormatMonthDay(risk.WeekStart),
+		v.book.locale.FormatMonthDay(risk.WeekEnd.AddDate(0, 0, -1)),
+		risk.Contracts,
+		v.book.locale.FormatFloat(risk.CollateralAtRisk, 2),
+		v.book.locale.FormatNumber(strconv.Itoa(risk.SharesCallable)),
+		risk.ITMContracts,
+		cashColor, signedDollars(v.book.locale, risk.NetAssignmentCash)))
+}
+
+func (v *OptionsView) updateExpiryTimeline() {
+	today := v.cells.now().Truncate(24 * time.Hour)
+
+	// Collect active options
+	var activeOptions []db.Option
+	for _, o := range v.book.options {
+		if o.Status == "ACTIVE" {
+			activeOptions = append(activeOptions, o)
+		}
+	}
+
+	// Update title based on view mode
+	viewMode := "Monthly"
+	if v.weeklyView {
+		viewMode = "Weekly"
+	}
+	v.expiryTimeline.SetTitle(fmt.Sprintf(" Expiry Timeline [%s] ", viewMode))
+
+	if len(activeOptions) == 0 {
+		v.expiryTimeline.SetText(" [gray]No active options")
+		return
+	}
+
+	// Sort options by expiry date
+	for i := 0; i < len(activeOptions)-1; i++ {
+		for j := i + 1; j < len(activeOptions); j++ {
+			if activeOptions[i].ExpiryDate.After(activeOptions[j].ExpiryDate) {
+				activeOptions[i], activeOptions[j] = activeOptions[j], activeOptions[i]
+			}
+		}
+	}
+
+	// Timeline parameters based on view mode
+	var numPeriods int
+	var periodWidth int
+	var totalWidth int
+
+	if v.weeklyView {
+		numPeriods = 12 // 12 weeks
+		totalWidth = 120
+		periodWidth = totalWidth / numPeriods
+	} else {
+		numPeriods = 6 // 6 months
+		totalWidth = 120
+		periodWidth = totalWidth / numPeriods
+	}
+
+	var output string
+
+	// "Today" marker row
+	output = " [aqua]▼Today[white]\n"
+
+	// Header row with periods
+	var firstFriday time.Time // Expiry the first weekly period is labelled with
+	output += " "
+	for i := 0; i < numPeriods; i++ {
+		var periodLabel string
+		if v.weeklyView {
+			// Calculate the Friday of each week (options typically expire on Fridays)
+			// Find this week's Friday (could be in the past if today is Sat/Sun)
+			weekday := int(today.Weekday())
+			var daysToFriday int
+			if weekday <= 5 { // Sun(0) to Fri(5)
+				daysToFriday = 5 - weekday
+			} else { // Saturday(6)
+				daysToFriday = -1 // Yesterday was Friday
+			}
+			fridayDate := today.AddDate(0, 0, daysToFriday+(i*7))
+			if i == 0 {
+				firstFriday = fridayDate
+			}
+			periodLabel = v.book.locale.FormatMonthDay(fridayDate)
+		} else {
+			// Calculate the third Friday of each month (standard options expiry)
+			firstOfMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, i, 0)
+			// Days until first Friday: (Friday=5 - weekday + 7) % 7
+			daysUntilFriday := (5 - int(firstOfMonth.Weekday()) + 7) % 7
+			thirdFriday := firstOfMonth.AddDate(0, 0, daysUntilFriday+14)
+			periodLabel = v.book.locale.FormatMonthDay(thirdFriday)
+		}
+		output += fmt.Sprintf("[aqua]%-*s[white]", periodWidth, periodLabel)
+	}
+	output += "\n"
+
+	// Premium landing in each period, under its label
+	subtotals := make([]decimal.Decimal, numPeriods)
+	for _, o := range activeOptions {
+		period := 0
+		if v.weeklyView {
+			period = (int(o.ExpiryDate.Sub(firstFriday).Hours()/24) + 6) / 7
+		} else {
+			period = (o.ExpiryDate.Year()-today.Year())*12 + int(o.ExpiryDate.Month()-today.Month())
+		}
+		if period < 0 {
+			period = 0
+		}
+		if period < numPeriods {
+			subtotals[period] = subtotals[period].Add(optionPremium(o))
+		}
+	}
+	output += " "
+	for _, subtotal := range subtotals {
+		text := ""
+		if !subtotal.IsZero() {
+			text = v.book.premiumText(subtotal)
+		}
+		output += fmt.Sprintf("[yellow]%-*s[white]", periodWidth, text)
+	}
+	output += "\n"
+
+	// Separator line with today marker
+	output += " [aqua]│[white]"
+	for i := 1; i < totalWidth; i++ {
+		if i%periodWidth == 0 {
+			output += "+"
+		} else {
+			output += "-"
+		}
+	}
+	output += "\n"
+
+	// Each contract gets its own row
+	for _, o := range activeOptions {
+		daysLeft := int(o.ExpiryDate.Sub(today).Hours() / 24)
+		if daysLeft < 0 {
+			daysLeft = 0
+		}
+
+		// Color based on days left
+		color := "white"
+		if daysLeft <= 7 {
+			color = "red"
+		} else if daysLeft <= 14 {
+			color = "yellow"
+		} else if daysLeft <= 30 {
+			color = "orange"
+		} else {
+			color = "lime"
+		}
+
+		// Contract label
+		typeSymbol := "C"
+		if o.OptionType == "PUT" {
+			typeSymbol = "P"
+		}
+		contractLabel := fmt.Sprintf("%s %s $%s(%dd) %s", o.Ticker, typeSymbol, v.book.locale.FormatFixed(o.Strike, 0), daysLeft, v.book.premiumText(optionPremium(o)))
+		if o.External {
+			contractLabel += " ext"
+		}
+
+		// Calculate expiry position
+		var expiryPos int
+		if v.weeklyView {
+			// Position based on days (12 weeks = 84 days)
+			maxDays := numPeriods * 7
+			if daysLeft > maxDays {
+				expiryPos = totalWidth - 1
+			} else {
+				expiryPos = (daysLeft * totalWidth) / maxDays
+			}
+		} else {
+			// Position based on months
+			monthsAway := (o.ExpiryDate.Year()-today.Year())*12 + int(o.ExpiryDate.Month()-today.Month())
+			dayInMonth := o.ExpiryDate.Day()
+			daysInMonth := time.Date(o.ExpiryDate.Year(), o.ExpiryDate.Month()+1, 0, 0, 0, 0, 0, time.Local).Day()
+
+			if monthsAway >= numPeriods {
+				expiryPos = totalWidth - 1
+			} else {
+				expiryPos = (monthsAway * periodWidth) + ((dayInMonth * periodWidth) / daysInMonth)
+			}
+		}
+
+		if expiryPos < 1 {
+			expiryPos = 1
+		}
+		if expiryPos >= totalWidth {
+			expiryPos = totalWidth - 1
+		}
+
+		// Build the row: today line, connecting line, marker with label
+		output += " [aqua]├[white]"
+
+		// Draw connecting line from today to expiry marker
+		for i := 1; i < expiryPos; i++ {
+			output += fmt.Sprintf("[%s]─[white]", color)
+		}
+
+		// Draw marker and label
+		output += fmt.Sprintf("[%s]●%s[white]", color, contractLabel)
+
+		output += "\n"
+	}
+
+	// Bottom of today line
+	if len(activeOptions) > 0 {
+		output += " [aqua]│[white]\n"
+	}
+
+	output += v.cashForecastText()
+
+	v.expiryTimeline.SetText(output)
+}
+
+// optionPosition is o as the analytics package's expiry summaries take it
+func optionPosition(o db.Option) analytics.OptionPosition {
+	return analytics.OptionPosition{
+		Ticker:     o.Ticker,
+		OptionType: o.OptionType,
+		Action:     o.Action,
+		Strike:     o.Strike.InexactFloat64(),
+		Expiry:     o.ExpiryDate,
+		Contracts:  o.Quantity,
+		Multiplier: o.Multiplier,
+	}
+}
+
+const (
+	// cashForecastWeeks is how far ahead the cash curve under the expiry
+	// timeline looks
+	cashForecastWeeks = 8
+	// cashForecastRows is the lines the cash curve adds to the timeline
+	cashForecastRows = 4
+)
+
+// cashForecastText renders the cash curve under the expiry timeline: cash at
+// the end of each of the next cashForecastWeeks if the options in the money
+// now are assigned at expiry and the rest expire. Weeks it would go negative
+// are red. Watch-only options are left out, as their cash is elsewhere.
+func (v *OptionsView) cashForecastText() string {
+	var positions []analytics.OptionPosition
+	for _, o := range v.book.options {
+		if o.Status == "ACTIVE" && !o.External {
+			positions = append(positions, optionPosition(o))
+		}
+	}
+	weeks := analytics.AssignmentCashForecast(positions, v.book.quotePrices(), v.book.cash.InexactFloat64(), v.cells.now(), cashForecastWeeks)
+
+	// Cash now, then at the end of each week
+	labels := []string{"Now"}
+	points := []float64{v.book.cash.InexactFloat64()}
+	for _, w := range weeks {
+		label := v.book.locale.FormatMonthDay(w.WeekStart.AddDate(0, 0, 4)) // The week's Friday
+		if w.Assignments > 0 {
+			label += fmt.Sprintf(" (%d)", w.Assignments)
+		}
+		labels = append(labels, label)
+		points = append(points, w.Balance)
+	}
+
+	low, high := 0.0, 0.0
+	for _, point := range points {
+		low, high = min(low, point), max(high, point)
+	}
+	const width = 12
+	bars := []rune("▁▂▃▄▅▆▇█")
+
+	var header, curve, balances strings.Builder
+	for i, point := range points {
+		color := "lime"
+		if point < 0 {
+			color = "red"
+		}
+		fmt.Fprintf(&header, "[aqua]%-*s[white]", width, labels[i])
+
+		level := len(bars) - 1
+		if high > low {
+			level = int((point - low) / (high - low) * float64(len(bars)-1))
+		}
+		fmt.Fprintf(&curve, "[%s]%-*s[white]", color, width, strings.Repeat(string(bars[level]), width-2))
+
+		balance := "$" + v.book.locale.FormatFloat(math.Abs(point), 0)
+		if point < 0 {
+			balance = "-" + balance
+		}
+		fmt.Fprintf(&balances, "[%s]%-*s[white]", color, width, balance)
+	}
+
+	return fmt.Sprintf(" [teal]Cash if ITM assigned at expiry[white] [gray](contracts assigned)[white]\n %s\n %s\n %s\n",
+		header.String(), curve.String(), balances.String())
+}
+
+// optionPremium is the premium of o's contracts: received for a sale,
+// negative if paid for a purchase
+func optionPremium(o db.Option) decimal.Decimal {
+	premium := o.Premium.Mul(o.Shares())
+	if o.Action == "BUY" {
+		return premium.Neg()
+	}
+	return premium
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"anyhowhodl/internal/db"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Where a palette action applies
+const (
+	paletteAnyView = iota
+	paletteMainView
+	paletteCSPView
+)
+
+// paletteAction is an entry in the command palette. Running it presses its
+// key on the main screen, so the palette and the key bindings never disagree.
+type paletteAction struct {
+	name  string
+	key   tcell.Key // Set for special keys; runes use ch
+	ch    rune
+	view  int
+	write bool // Hidden from viewers
+}
+
+var paletteActions = []paletteAction{
+	{name: "Add holding", ch: 'a', view: paletteMainView, write: true},
+	{name: "Add option", ch: 'o', view: paletteMainView, write: true},
+	{name: "Update cash", ch: 'c', view: paletteMainView, write: true},
+	{name: "Delete selected row", ch: 'd', view: paletteMainView, write: true},
+	{name: "Pin or unpin selected row", ch: 'f', view: paletteMainView, write: true},
+	{name: "Edit tags of selected row", ch: 'n', view: paletteMainView, write: true},
+	{name: "Show or hide tags column", ch: 'l', view: paletteMainView},
+	{name: "Archive or restore selected holding", ch: 'z', view: paletteMainView, write: true},
+	{name: "Show or hide archived holdings", ch: 'Z', view: paletteMainView},
+	{name: "Set manual price", ch: 'm', view: paletteMainView, write: true},
+	{name: "Retry halted symbols", ch: 'y', view: paletteMainView, write: true},
+	{name: "Retry failed quotes", ch: 'F', view: paletteMainView},
+	{name: "Paste import", ch: 'i', view: paletteMainView, write: true},
+	{name: "Import option history (CSV)", ch: 'O', view: paletteMainView, write: true},
+	{name: "Import holdings (CSV)", ch: 'Q', view: paletteMainView, write: true},
+	{name: "Settings", ch: 'S', view: paletteMainView, write: true},
+	{name: "Refresh", ch: 'r'},
+	{name: "Toggle auto-refresh", ch: 'R', view: paletteMainView},
+	{name: "Switch table", key: tcell.KeyTab, view: paletteMainView},
+	{name: "Filter table", ch: '/', view: paletteMainView},
+	{name: "Show or hide active options", ch: '1', view: paletteMainView},
+	{name: "Show or hide closed options", ch: '2', view: paletteMainView},
+	{name: "Show or hide expired options", ch: 'e', view: paletteMainView},
+	{name: "Show or hide assigned options", ch: '4', view: paletteMainView},
+	{name: "Switch weekly/monthly timeline", ch: 'w', view: paletteMainView},
+	{name: "Option payoff diagram", ch: 'P', view: paletteMainView},
+	{name: "Take profit on selected option", ch: 'T', view: paletteMainView, write: true},
+	{name: "Portfolio beta", ch: 'b', view: paletteMainView},
+	{name: "Sector allocation", ch: 'G', view: paletteMainView},
+	{name: "Dividends", ch: 'v', view: paletteMainView},
+	{name: "Earnings calendar", ch: 'E'},
+	{name: "Record dividend on selected holding", ch: 'D', view: paletteMainView, write: true},
+	{name: "Cash drag", ch: 'C', view: paletteMainView},
+	{name: "Performance", ch: 'g', view: paletteMainView},
+	{name: "Equity curve", ch: 'V', view: paletteMainView},
+	{name: "Margin comparison", ch: 'M', view: paletteMainView},
+	{name: "Backup and export", ch: 'X', view: paletteMainView},
+	{name: "Risk caps", ch: 'K'},
+	{name: "Policy actions", ch: 'A'},
+	{name: "Custom columns", ch: 'U'},
+	{name: "IV surface", ch: 'I'},
+	{name: "Logs", ch: 'L'},
+	{name: "Reminders inbox", ch: 'N'},
+	{name: "Stock watchlist", ch: 'W', view: paletteMainView},
+	{name: "Trade ideas", ch: 'u'},
+	{name: "Weekly review", ch: 'j'},
+	{name: "Review stock splits", ch: 'J', view: paletteMainView, write: true},
+	{name: "Hide or show banner", ch: 'H'},
+	{name: "Show or hide YTD income line", ch: 'Y', view: paletteMainView},
+	{name: "Accounts", ch: 'B', view: paletteMainView},
+	{name: "Transaction history", ch: 'h', view: paletteMainView},
+	{name: "CSP advisor", ch: 'p', view: paletteMainView},
+	{name: "Back to portfolio", ch: 'p', view: paletteCSPView},
+	{name: "Add CSP watchlist ticker", ch: 'a', view: paletteCSPView, write: true},
+	{name: "Remove CSP watchlist ticker", ch: 'd', view: paletteCSPView, write: true},
+	{name: "Cycle CSP ticker expiries (all, weeklies, monthlies)", ch: 'e', view: paletteCSPView, write: true},
+	{name: "Export CSP CSV", ch: 'x', view: paletteCSPView},
+	{name: "Open in TradingView", ch: 't', view: paletteCSPView},
+	{name: "CSP signals", ch: 's', view: paletteCSPView},
+	{name: "Queue selected CSP as trade idea", ch: 'i', view: paletteCSPView, write: true},
+	{name: "Quit", ch: 'q'},
+}
+
+// keyLabel is how the action's key is shown
+func (p paletteAction) keyLabel() string {
+	if p.key == tcell.KeyTab {
+		return "Tab"
+	}
+	return string(p.ch)
+}
+
+// event is the key press that runs the action
+func (p paletteAction) event() *tcell.EventKey {
+	if p.key != 0 {
+		return tcell.NewEventKey(p.key, 0, tcell.ModNone)
+	}
+	return tcell.NewEventKey(tcell.KeyRune, p.ch, tcell.ModNone)
+}
+
+// fuzzyScore matches query against text as a subsequence, ignoring case.
+// Letters matched in a row or at the start of a word score higher; ok is
+// false if text does not contain query's letters in order.
+func fuzzyScore(query, text string) (int, bool) {
+	q := []rune(strings.ToLower(strings.Join(strings.Fields(query), "")))
+	score, qi := 0, 0
+	prevMatched := false
+	prev := ' '
+	for _, r := range strings.ToLower(text) {
+		if qi < len(q) && r == q[qi] {
+			score++
+			if prevMatched {
+				score += 3
+			}
+			if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+				score += 2
+			}
+			qi++
+			prevMatched = true
+		} else {
+			prevMatched = false
+		}
+		prev = r
+	}
+	return score, qi == len(q)
+}
+
+// paletteMatches returns the actions available in the current view that
+// match query, best first
+func (a *App) paletteMatches(query string) []paletteAction {
+	type scored struct {
+		action paletteAction
+		score  int
+	}
+	var matches []scored
+	for _, action := range paletteActions {
+		if action.view == paletteMainView && a.showCSP || action.view == paletteCSPView && !a.showCSP {
+			continue
+		}
+		if action.write && a.role == db.RoleViewer {
+			continue
+		}
+		if score, ok := fuzzyScore(query, action.name); ok {
+			matches = append(matches, scored{action, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	actions := make([]paletteAction, len(matches))
+	for i, m := range matches {
+		actions[i] = m.action
+	}
+	return actions
+}
+
+// showPalette opens the command palette: type to search the actions, Up and
+// Down to choose, Enter to run
+func (a *App) showPalette() {
+	input := tview.NewInputField().
+		SetLabel("> ").
+		SetFieldBackgroundColor(tcell.ColorBlack).
+		SetFieldTextColor(tcell.ColorWhite).
+		SetLabelColor(tcell.ColorTeal)
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(tcell.ColorDarkSlateGray)
+
+	var matches []paletteAction
+	fill := func(query string) {
+		matches = a.paletteMatches(query)
+		list.Clear()
+		for _, action := range matches {
+			list.AddItem(fmt.Sprintf("%-34s [gray]%s", action.name, tview.Escape(action.keyLabel())), "", 0, nil)
+		}
+	}
+	fill("")
+
+	input.SetChangedFunc(fill)
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyUp:
+			if i := list.GetCurrentItem(); i > 0 {
+				list.SetCurrentItem(i - 1)
+			}
+			return nil
+		case tcell.KeyDown:
+			if i := list.GetCurrentItem(); i < list.GetItemCount()-1 {
+				list.SetCurrentItem(i + 1)
+			}
+			return nil
+		}
+		return event
+	})
+	input.SetDoneFunc(func(key tcell.Key) {
+		if key != tcell.KeyEnter || len(matches) == 0 {
+			return
+		}
+		action := matches[list.GetCurrentItem()]
+		a.pages.RemovePage("palette")
+		a.handleKey(action.event())
+	})
+
+	content := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(list, 0, 1, false)
+	content.SetBorder(true).
+		SetTitle(" Actions ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	a.createModalPage("palette", content, 50, 20)
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/db"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Payoff chart size in characters, excluding the axis labels
+const (
+	payoffChartWidth  = 60
+	payoffChartHeight = 15
+)
+
+// payoffState is what the payoff view shows: the selected option alone or
+// every active leg on its underlying, with or without the shares held
+type payoffState struct {
+	option  db.Option
+	allLegs bool
+	shares  bool
+}
+
+// showPayoffView opens the payoff-at-expiry diagram for the option at index.
+// A short call starts combined with the shares it covers.
+func (a *App) showPayoffView(index int) {
+	state := &payoffState{option: a.book.options[index]}
+	if o := state.option; o.OptionType == "CALL" && o.Action == "SELL" {
+		state.shares = a.holdingFor(o.Ticker) != nil
+	}
+
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	view.SetBorder(true).
+		SetTitle(" Payoff at Expiry ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'l':
+			state.allLegs = !state.allLegs
+		case 's':
+			state.shares = !state.shares
+		default:
+			return event
+		}
+		view.SetText(a.buildPayoffReport(state))
+		return nil
+	})
+
+	view.SetText(a.buildPayoffReport(state))
+	a.pages.AddPage("payoff", view, true, true)
+}
+
+// holdingFor returns the holding in ticker, or nil
+func (a *App) holdingFor(ticker string) *db.Holding {
+	for i := range a.book.holdings {
+		if a.book.holdings[i].Ticker == ticker {
+			return &a.book.holdings[i]
+		}
+	}
+	return nil
+}
+
+// payoffFor assembles the strategy state describes
+func (a *App) payoffFor(state *payoffState) analytics.Payoff {
+	var legs []db.Option
+	if state.allLegs {
+		for _, o := range a.book.options {
+			if o.Ticker == state.option.Ticker && o.Status == "ACTIVE" {
+				legs = append(legs, o)
+			}
+		}
+	} else {
+		legs = []db.Option{state.option}
+	}
+
+	var p analytics.Payoff
+	for _, o := range legs {
+		p.Legs = append(p.Legs, analytics.PayoffLeg{
+			OptionType: o.OptionType,
+			Action:     o.Action,
+			Strike:     o.Strike.InexactFloat64(),
+			Premium:    o.Premium.InexactFloat64(),
+			Contracts:  o.Quantity,
+			Multiplier: o.Multiplier,
+		})
+	}
+	if h := a.holdingFor(state.option.Ticker); state.shares && h != nil {
+		p.Shares = h.Quantity.InexactFloat64()
+		p.CostBasis = h.AvgCost.InexactFloat64()
+	}
+	return p
+}
+
+// buildPayoffReport renders the legs, the chart, and the strategy's
+// breakevens and best and worst outcomes
+func (a *App) buildPayoffReport(state *payoffState) string {
+	o := state.option
+	p := a.payoffFor(state)
+
+	current := o.Strike.InexactFloat64()
+	if q, ok := a.book.quotes[o.Ticker]; ok && q.Price > 0 {
+		current = q.Price
+	}
+	lo, hi := p.PriceRange(current)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, " [teal]%s[white] at $%s\n", o.Ticker, a.book.locale.FormatFloat(current, 2))
+	for _, l := range p.Legs {
+		fmt.Fprintf(&sb, "   %s %d %s $%s @ $%s\n", l.Action, l.Contracts, l.OptionType, a.book.locale.FormatFloat(l.Strike, int(a.book.precision.Prices)), a.book.locale.FormatFloat(l.Premium, int(a.book.precision.Prices)))
+	}
+	if p.Shares != 0 {
+		fmt.Fprintf(&sb, "   %s shares @ $%s\n", a.book.locale.FormatFloat(p.Shares, 0), a.book.locale.FormatFloat(p.CostBasis, 2))
+	}
+	sb.WriteString("\n")
+
+	evens := p.Breakevens()
+	sb.WriteString(a.renderPayoffChart(p, lo, hi, current, evens))
+
+	sb.WriteString("\n [teal]Breakeven:[white] ")
+	if len(evens) == 0 {
+		sb.WriteString("none")
+	}
+	for i, x := range evens {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("$" + a.book.locale.FormatFloat(x, 2))
+	}
+
+	best, worst := payoffExtremes(p, hi)
+	fmt.Fprintf(&sb, "\n [teal]Now:[white] %s  [teal]Max profit:[white] %s  [teal]Max loss:[white] %s\n",
+		a.payoffText(p.At(current)), a.payoffLimitText(best), a.payoffLimitText(worst))
+
+	legsLabel, sharesLabel := "all legs", "with shares"
+	if state.allLegs {
+		legsLabel = "selected leg"
+	}
+	if state.shares {
+		sharesLabel = "without shares"
+	}
+	fmt.Fprintf(&sb, "\n [yellow]l[white]:Show %s  [yellow]s[white]:Show %s  [gray]ESC to close", legsLabel, sharesLabel)
+	return sb.String()
+}
+
+// payoffExtremes returns the best and worst profit at expiry, checked at zero
+// and every strike, or an infinite value if the payoff keeps rising or
+// falling past the last strike
+func payoffExtremes(p analytics.Payoff, hi float64) (best, worst float64) {
+	best, worst = math.Inf(-1), math.Inf(1)
+	points := []float64{0, hi}
+	for _, l := range p.Legs {
+		points = append(points, l.Strike)
+	}
+	last := 0.0
+	for _, x := range points {
+		v := p.At(x)
+		best = math.Max(best, v)
+		worst = math.Min(worst, v)
+		last = math.Max(last, x)
+	}
+	switch slope := p.At(last+1) - p.At(last); {
+	case slope > 0:
+		best = math.Inf(1)
+	case slope < 0:
+		worst = math.Inf(-1)
+	}
+	return best, worst
+}
+
+// payoffText shows a dollar profit green or a loss red
+func (a *App) payoffText(v float64) string {
+	if v < 0 {
+		return "[red]-$" + a.book.locale.FormatFloat(-v, 2) + "[white]"
+	}
+	return "[lime]$" + a.book.locale.FormatFloat(v, 2) + "[white]"
+}
+
+// payoffLimitText is payoffText that shows an unbounded outcome as such
+func (a *App) payoffLimitText(v float64) string {
+	if math.IsInf(v, 0) {
+		return "[orange]unlimited[white]"
+	}
+	return a.payoffText(v)
+}
+
+// renderPayoffChart plots profit at expiry across [lo, hi]: profit in green,
+// loss in red, breakevens marked X on the zero line and the current price
+// marked ^ under the chart
+func (a *App) renderPayoffChart(p analytics.Payoff, lo, hi, current float64, evens []float64) string {
+	column := func(x float64) int {
+		return int(math.Round((x - lo) / (hi - lo) * float64(payoffChartWidth-1)))
+	}
+
+	values := make([]float64, payoffChartWidth)
+	top, bottom := 0.0, 0.0
+	for c := range values {
+		values[c] = p.At(lo + (hi-lo)*float64(c)/float64(payoffChartWidth-1))
+		top = math.Max(top, values[c])
+		bottom = math.Min(bottom, values[c])
+	}
+	if top == bottom {
+		top, bottom = 1, -1
+	}
+	row := func(v float64) int {
+		return int(math.Round((top - v) / (top - bottom) * float64(payoffChartHeight-1)))
+	}
+	zero := row(0)
+
+	breakeven := make(map[int]bool)
+	for _, x := range evens {
+		breakeven[column(x)] = true
+	}
+
+	labels := map[int]string{
+		0:                     a.payoffAxisLabel(top),
+		zero:                  "$0",
+		payoffChartHeight - 1: a.payoffAxisLabel(bottom),
+	}
+
+	var sb strings.Builder
+	for r := 0; r < payoffChartHeight; r++ {
+		fmt.Fprintf(&sb, " %10s [gray]|", labels[r])
+		for c, v := range values {
+			switch {
+			case r == zero && breakeven[c]:
+				sb.WriteString("[yellow]X")
+			case row(v) == r && v > 0:
+				sb.WriteString("[lime]*")
+			case row(v) == r && v < 0:
+				sb.WriteString("[red]*")
+			case row(v) == r:
+				sb.WriteString("[white]*")
+			case r == zero:
+				sb.WriteString("[gray]-")
+			default:
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString("[white]\n")
+	}
+
+	marker := []rune(strings.Repeat(" ", payoffChartWidth))
+	if c := column(current); c >= 0 && c < payoffChartWidth {
+		marker[c] = '^'
+	}
+	fmt.Fprintf(&sb, " %10s  [aqua]%s[white]\n", "", string(marker))
+
+	left := "$" + a.book.locale.FormatFloat(lo, 2)
+	right := "$" + a.book.locale.FormatFloat(hi, 2)
+	gap := max(payoffChartWidth-len(left)-len(right), 1)
+	fmt.Fprintf(&sb, " %10s  [gray]%s%s%s[white]\n", "", left, strings.Repeat(" ", gap), right)
+	return sb.String()
+}
+
+// payoffAxisLabel is a compact signed dollar amount for the chart's axis
+func (a *App) payoffAxisLabel(v float64) string {
+	if v < 0 {
+		return "-$" + a.book.locale.FormatFloat(-v, 0)
+	}
+	return "+$" + a.book.locale.FormatFloat(v, 0)
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/locale"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// performanceQuarters is how many calendar quarters (including the current one) are decomposed
+const performanceQuarters = 4
+
+// growthBarWidth is the character width of the largest stacked bar
+const growthBarWidth = 50
+
+// growthColors are the bar colors for contributions, dividends, option income, market
+var growthColors = []string{"aqua", "lime", "yellow", "fuchsia"}
+
+// showPerformanceView opens the performance page and loads data in the background
+func (a *App) showPerformanceView() {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	view.SetBorder(true).
+		SetTitle(" Performance ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	reload := func() {
+		view.SetText(" [yellow]Loading performance data...")
+		a.goSafe("performance report", func() {
+			text := a.buildPerformanceReport()
+			a.queueUpdateDraw(func() {
+				view.SetText(text)
+			})
+		})
+	}
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'n' {
+			a.showContributionForm(reload)
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("performance", view, true, true)
+	reload()
+}
+
+// buildPerformanceReport decomposes quarterly growth and renders stacked bars
+func (a *App) buildPerformanceReport() string {
+	ctx := context.Background()
+	now := time.Now()
+
+	first := analytics.QuarterOf(now)
+	for i := 1; i < performanceQuarters; i++ {
+		first = first.Prev()
+	}
+	since := first.Start(now.Location())
+
+	// Include the prior quarter so its closing snapshot can seed the first start value
+	snapshots, err := a.db.GetPortfolioSnapshots(ctx, since.AddDate(0, -3, 0))
+	if err != nil {
+		return fmt.Sprintf(" [red]Error loading snapshots: %v", err)
+	}
+	contributions, err := a.db.GetContributionsByQuarter(ctx, since)
+	if err != nil {
+		return fmt.Sprintf(" [red]Error loading contributions: %v", err)
+	}
+	// The snapshots, contributions, and interest are kept across accounts,
+	// so option income and dividends are counted across them too
+	var optionIncome []db.QuarterAmount
+	err = forEachAccount(ctx, a.db, func(_ string, store db.Store) error {
+		amounts, err := store.GetOptionIncomeByQuarter(ctx, since)
+		optionIncome = append(optionIncome, amounts...)
+		return err
+	})
+	if err != nil {
+		return fmt.Sprintf(" [red]Error loading option income: %v", err)
+	}
+	interest, err := a.db.GetInterestByQuarter(ctx, since)
+	if err != nil {
+		interest = nil
+	}
+
+	// Estimated dividends: ex-dates in each quarter at current share counts
+	dividends := make(map[analytics.Quarter]float64)
+	for _, h := range a.book.allHoldings() {
+		history, err := a.yahoo.FetchDividends(h.Ticker)
+		if err != nil {
+			continue
+		}
+		for _, d := range history {
+			if d.ExDate.Before(since) {
+				continue
+			}
+			dividends[analytics.QuarterOf(d.ExDate)] += d.Amount * h.Quantity.InexactFloat64()
+		}
+	}
+	for _, q := range interest {
+		dividends[analytics.QuarterOf(q.Start)] += q.Amount.InexactFloat64()
+	}
+
+	var rows []analytics.GrowthDecomposition
+	var missing []analytics.Quarter
+	for q := first; len(rows)+len(missing) < performanceQuarters; q = q.Next() {
+		start := q.Start(now.Location())
+		end := q.Next().Start(now.Location())
+		startValue, endValue, ok := quarterValues(snapshots, start, end)
+		if !ok {
+			missing = append(missing, q)
+			continue
+		}
+		rows = append(rows, analytics.DecomposeGrowth(q, startValue, endValue,
+			quarterAmount(contributions, q),
+			dividends[q],
+			quarterAmount(optionIncome, q)))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, " [teal]Growth decomposition by quarter%s[white]\n", a.book.acrossAccounts())
+	fmt.Fprintf(&sb, " [%s]█[white] Contributions  [%s]█[white] Dividends/Interest  [%s]█[white] Option Income  [%s]█[white] Market   [gray]▒ = negative[white]\n\n",
+		growthColors[0], growthColors[1], growthColors[2], growthColors[3])
+
+	if len(rows) == 0 {
+		sb.WriteString(" [gray]No portfolio snapshots yet. A snapshot is recorded on every refresh.[white]\n")
+	} else {
+		scale := 0.0
+		for _, r := range rows {
+			total := 0.0
+			for _, c := range r.Components() {
+				if c < 0 {
+					total -= c
+				} else {
+					total += c
+				}
+			}
+			if total > scale {
+				scale = total
+			}
+		}
+
+		for _, r := range rows {
+			fmt.Fprintf(&sb, " [aqua]%-8s[white] ", r.Quarter)
+			components := r.Components()
+			widths := analytics.StackedBarWidths(components, scale, growthBarWidth)
+			drawn := 0
+			for i, w := range widths {
+				char := "█"
+				if components[i] < 0 {
+					char = "▒"
+				}
+				fmt.Fprintf(&sb, "[%s]%s", growthColors[i], strings.Repeat(char, w))
+				drawn += w
+			}
+			change := r.EndValue - r.StartValue
+			fmt.Fprintf(&sb, "[white]%s %s\n", strings.Repeat(" ", max(0, growthBarWidth-drawn)), signedDollars(a.book.locale, change))
+		}
+
+		sb.WriteString("\n")
+		fmt.Fprintf(&sb, " [teal]%-8s %14s %14s %14s %14s %14s %14s[white]\n", "QUARTER", "START", "END", "CONTRIB", "DIV/INT", "OPTIONS", "MARKET")
+		for _, r := range rows {
+			fmt.Fprintf(&sb, " %-8s %14s %14s %14s %14s %14s %14s\n", r.Quarter,
+				"$"+a.book.locale.FormatFloat(r.StartValue, 2),
+				"$"+a.book.locale.FormatFloat(r.EndValue, 2),
+				signedDollars(a.book.locale, r.Contributions),
+				signedDollars(a.book.locale, r.Dividends),
+				signedDollars(a.book.locale, r.OptionIncome),
+				signedDollars(a.book.locale, r.MarketAppreciation))
+		}
+	}
+
+	if len(missing) > 0 {
+		names := make([]string, len(missing))
+		for i, q := range missing {
+			names[i] = q.String()
+		}
+		fmt.Fprintf(&sb, "\n [gray]No snapshots for: %s[white]\n", strings.Join(names, ", "))
+	}
+
+	sb.WriteString("\n" + a.returnsReport(ctx, now))
+
+	sb.WriteString("\n [gray]Dividends estimated from ex-dates at current share counts; market is the residual.[white]")
+	sb.WriteString("\n [yellow]n[white]:Record Contribution  [gray]ESC to close")
+	return sb.String()
+}
+
+// quarterValues returns the portfolio value entering and leaving [start, end).
+// The entering value is the last snapshot before start, or the first inside the
+// quarter if none exists.
+func quarterValues(snapshots []db.PortfolioSnapshot, start, end time.Time) (float64, float64, bool) {
+	var startSnap, endSnap *db.PortfolioSnapshot
+	for i := range snapshots {
+		s := &snapshots[i]
+		if s.Date.Before(start) {
+			startSnap = s
+			continue
+		}
+		if !s.Date.Before(end) {
+			break
+		}
+		if startSnap == nil {
+			startSnap = s
+		}
+		endSnap = s
+	}
+	if startSnap == nil || endSnap == nil {
+		return 0, 0, false
+	}
+	return startSnap.Total().InexactFloat64(), endSnap.Total().InexactFloat64(), true
+}
+
+// quarterAmount sums the amounts for q in quarterly results, which may hold
+// several per quarter when gathered from more than one account
+func quarterAmount(amounts []db.QuarterAmount, q analytics.Quarter) float64 {
+	total := 0.0
+	for _, a := range amounts {
+		if analytics.QuarterOf(a.Start) == q {
+			total += a.Amount.InexactFloat64()
+		}
+	}
+	return total
+}
+
+// signedDollars formats a value as +$1,234.56 / -$1,234.56
+func signedDollars(loc locale.Locale, v float64) string {
+	sign := "+"
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return sign + "$" + loc.FormatFloat(v, 2)
+}
+
+// showContributionForm records a deposit or withdrawal
+func (a *App) showContributionForm(onSaved func()) {
+	form := tview.NewForm().
+		AddInputField("Amount ($, negative = withdrawal)", "", 15, nil, nil).
+		AddInputField("Date ("+a.book.locale.DateHint+")", a.book.locale.FormatDate(time.Now()), 15, nil, nil).
+		AddInputField("Notes", "", 30, nil, nil)
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		amountStr := form.GetFormItem(0).(*tview.InputField).GetText()
+		dateStr := form.GetFormItem(1).(*tview.InputField).GetText()
+		notes := form.GetFormItem(2).(*tview.InputField).GetText()
+
+		amount, err := a.book.locale.ParseNumber(amountStr)
+		if err != nil || amount.IsZero() {
+			a.statusBar.SetText(" [red]Invalid contribution amount")
+			return
+		}
+
+		contributedOn, err := a.book.locale.ParseDate(dateStr)
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid date format")
+			return
+		}
+
+		ctx := context.Background()
+		if err := a.db.AddContribution(ctx, amount, contributedOn, notes); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+
+		a.pages.RemovePage("contribution")
+		a.refreshData()
+		onSaved()
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("contribution")
+	})
+
+	form.SetBorder(true).SetTitle(" Record Contribution ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("contribution", form, 60, 11)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+)
+
+// loadPinned reads the pinned holdings and options and moves them to the top
+// of their tables. Call it on freshly loaded rows: their order is the one
+// unpinned rows go back to.
+func (a *App) loadPinned(ctx context.Context) {
+	ids, err := a.db.GetPinned(ctx)
+	if err != nil {
+		slog.Warn("loading pinned rows", "err", err)
+	}
+	a.book.pinned = make(map[string]bool, len(ids))
+	for _, id := range ids {
+		a.book.pinned[id] = true
+	}
+	a.loadOrder = make(map[string]int, len(a.book.holdings)+len(a.book.options))
+	for i, h := range a.book.holdings {
+		a.loadOrder[h.ID] = i
+	}
+	for i, o := range a.book.options {
+		a.loadOrder[o.ID] = i
+	}
+	a.sortPinned()
+}
+
+// sortPinned puts pinned rows ahead of the others, each group in load order
+func (a *App) sortPinned() {
+	before := func(idI, idJ string) bool {
+		if a.book.pinned[idI] != a.book.pinned[idJ] {
+			return a.book.pinned[idI]
+		}
+		return a.loadOrder[idI] < a.loadOrder[idJ]
+	}
+	sort.SliceStable(a.book.holdings, func(i, j int) bool {
+		return before(a.book.holdings[i].ID, a.book.holdings[j].ID)
+	})
+	sort.SliceStable(a.book.options, func(i, j int) bool {
+		return before(a.book.options[i].ID, a.book.options[j].ID)
+	})
+}
+
+// tickerLabel is a table's ticker cell text, marked with * when the row is
+// pinned. It keeps the width of the unmarked label.
+func (b *book) tickerLabel(id, ticker string) string {
+	if b.pinned[id] {
+		return "*" + ticker + " "
+	}
+	return " " + ticker + " "
+}
+
+// togglePin pins or unpins the selected holding or option and keeps it
+// selected as it moves
+func (a *App) togglePin() {
+	var id, label string
+	if a.focusIndex == 0 {
+		i, ok := a.holdingsView.selected()
+		if !ok {
+			return
+		}
+		id, label = a.book.holdings[i].ID, a.book.holdings[i].Ticker
+	} else {
+		i, ok := a.optionsView.selected()
+		if !ok {
+			return
+		}
+		o := a.book.options[i]
+		id, label = o.ID, fmt.Sprintf("%s %s $%s", o.Ticker, o.OptionType, a.book.formatPrice(o.Strike))
+	}
+
+	pinned := !a.book.pinned[id]
+	ids := a.pinnedIDs(id, pinned)
+	if err := a.db.SetPinned(context.Background(), ids); err != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+		return
+	}
+	if pinned {
+		a.book.pinned[id] = true
+	} else {
+		delete(a.book.pinned, id)
+	}
+
+	a.sortPinned()
+	a.holdingsView.update()
+	a.optionsView.update()
+	a.selectRowOf(id)
+
+	if pinned {
+		a.statusBar.SetText(fmt.Sprintf(" [green]Pinned %s", label))
+	} else {
+		a.statusBar.SetText(fmt.Sprintf(" [green]Unpinned %s", label))
+	}
+}
+
+// pinnedIDs returns the IDs to store with id pinned or not. IDs of rows that
+// no longer exist are dropped.
+func (a *App) pinnedIDs(id string, pinned bool) []string {
+	var ids []string
+	for _, h := range a.book.holdings {
+		if h.ID == id && pinned || h.ID != id && a.book.pinned[h.ID] {
+			ids = append(ids, h.ID)
+		}
+	}
+	for _, o := range a.book.options {
+		if o.ID == id && pinned || o.ID != id && a.book.pinned[o.ID] {
+			ids = append(ids, o.ID)
+		}
+	}
+	return ids
+}
+
+// selectRowOf selects the row showing the holding or option with id
+func (a *App) selectRowOf(id string) {
+	for row, i := range a.holdingsView.rows {
+		if a.book.holdings[i].ID == id {
+			a.holdingsView.table.Select(row+1, 0)
+			return
+		}
+	}
+	for row, i := range a.optionsView.rows {
+		if a.book.options[i].ID == id {
+			a.optionsView.table.Select(row+1, 0)
+			return
+		}
+	}
+}
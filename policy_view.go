@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/db"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// policyKinds are the policy kinds offered in the form, with their labels
+var policyKinds = []struct {
+	kind, label string
+}{
+	{analytics.PolicyRollAtDTE, "Roll at DTE"},
+	{analytics.PolicyCloseAtProfit, "Close at % profit"},
+}
+
+// loadPolicies reads the option policies and the actions waiting for
+// confirmation. Without schema_policies.sql both are simply empty.
+func (a *App) loadPolicies(ctx context.Context) {
+	policies, err := a.db.GetOptionPolicies(ctx)
+	if err != nil {
+		slog.Debug("loading option policies", "err", err)
+	}
+	actions, err := a.db.GetPendingPolicyActions(ctx)
+	if err != nil {
+		slog.Debug("loading policy actions", "err", err)
+	}
+	a.policies = policies
+	a.policyActions = actions
+}
+
+// optionIndex returns the position of the option with id in the book's options, or -1
+func (a *App) optionIndex(id string) int {
+	for i, o := range a.book.options {
+		if o.ID == id && o.Status == "ACTIVE" {
+			return i
+		}
+	}
+	return -1
+}
+
+// optionLabel names an option, e.g. "SELL AAPL PUT $200.00 03-20"
+func (a *App) optionLabel(id string) string {
+	i := a.optionIndex(id)
+	if i < 0 {
+		return "(closed option)"
+	}
+	o := a.book.options[i]
+	return fmt.Sprintf("%s %s %s $%s %s", o.Action, o.Ticker, o.OptionType, a.book.formatPrice(o.Strike), a.book.locale.FormatMonthDay(o.ExpiryDate))
+}
+
+// policyLabel describes a policy, e.g. "Roll at 21 DTE if ITM"
+func (a *App) policyLabel(p db.OptionPolicy) string {
+	var label string
+	switch p.Kind {
+	case analytics.PolicyRollAtDTE:
+		label = fmt.Sprintf("Roll at %s DTE", p.Threshold.String())
+	case analytics.PolicyCloseAtProfit:
+		label = fmt.Sprintf("Close at %s%% profit", a.book.locale.FormatNumber(p.Threshold.String()))
+	default:
+		label = p.Kind
+	}
+	if p.ITMOnly {
+		label += " if ITM"
+	}
+	return label
+}
+
+// showPolicyActionsView lists queued policy actions for one-key confirmation,
+// above the policies that produce them
+func (a *App) showPolicyActionsView() {
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	table.SetBorder(true).
+		SetTitle(" Policy Actions ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	policies := tview.NewTextView().
+		SetDynamicColors(true)
+	policies.SetBorder(true).
+		SetTitle(" Policies ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	redraw := func() {
+		a.fillPolicyActionsTable(table)
+		policies.SetText(a.buildPoliciesReport())
+	}
+	redraw()
+
+	selected := func() (db.PolicyAction, bool) {
+		row, _ := table.GetSelection()
+		if row < 1 || row > len(a.policyActions) {
+			return db.PolicyAction{}, false
+		}
+		return a.policyActions[row-1], true
+	}
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEnter {
+			event = tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone)
+		}
+		switch event.Rune() {
+		case 'y':
+			if act, ok := selected(); ok && !a.readOnly() {
+				a.confirmPolicyAction(act)
+			}
+			return nil
+		case 'n':
+			if act, ok := selected(); ok && !a.readOnly() {
+				if err := a.db.ResolvePolicyAction(context.Background(), act.ID, db.PolicyActionDismissed); err != nil {
+					a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+					return nil
+				}
+				a.loadPolicies(context.Background())
+				redraw()
+			}
+			return nil
+		case 'a':
+			if !a.readOnly() {
+				a.showPolicyForm(redraw)
+			}
+			return nil
+		case 'd':
+			if !a.readOnly() && len(a.policies) > 0 {
+				a.showDeletePolicyForm(redraw)
+			}
+			return nil
+		case 'e':
+			if !a.readOnly() {
+				a.evaluatePolicies(redraw)
+			}
+			return nil
+		}
+		return event
+	})
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(policies, 0, 1, false)
+	a.pages.AddPage("policyactions", flex, true, true)
+}
+
+// fillPolicyActionsTable lists the pending actions, oldest first
+func (a *App) fillPolicyActionsTable(table *tview.Table) {
+	table.Clear()
+	for i, h := range []string{"ACTION", "OPTION", "REASON", "MARK", "ROLL TO", "QUEUED"} {
+		table.SetCell(0, i, tview.NewTableCell(" "+h+" ").
+			SetTextColor(tcell.ColorBlack).
+			SetBackgroundColor(tcell.ColorTeal).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+	if len(a.policyActions) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell(" No actions queued").
+			SetTextColor(tcell.ColorGray).
+			SetSelectable(false))
+		return
+	}
+	for i, act := range a.policyActions {
+		row := i + 1
+		color := tcell.ColorYellow
+		if act.Action == db.PolicyActionRoll {
+			color = tcell.ColorOrange
+		}
+		mark, rollTo := "-", "-"
+		if act.Price.Valid {
+			mark = "$" + a.book.formatPrice(act.Price.Decimal)
+		}
+		if !act.RollExpiry.IsZero() {
+			rollTo = a.book.locale.FormatDate(act.RollExpiry)
+		}
+		table.SetCell(row, 0, tview.NewTableCell(" "+act.Action+" ").SetTextColor(color).SetExpansion(1))
+		table.SetCell(row, 1, tview.NewTableCell(" "+a.optionLabel(act.OptionID)+" ").SetTextColor(tcell.ColorFuchsia).SetExpansion(1))
+		table.SetCell(row, 2, tview.NewTableCell(" "+act.Reason+" ").SetExpansion(1))
+		table.SetCell(row, 3, tview.NewTableCell(" "+mark+" ").SetTextColor(tcell.ColorAqua).SetExpansion(1))
+		table.SetCell(row, 4, tview.NewTableCell(" "+rollTo+" ").SetExpansion(1))
+		table.SetCell(row, 5, tview.NewTableCell(" "+a.book.locale.FormatDate(act.CreatedAt)+" ").SetTextColor(tcell.ColorGray).SetExpansion(1))
+	}
+	table.Select(1, 0)
+}
+
+// buildPoliciesReport lists every policy with its option, then the keys
+func (a *App) buildPoliciesReport() string {
+	var sb strings.Builder
+	if len(a.policies) == 0 {
+		sb.WriteString(" No policies. Press [yellow]a[white] to add one.\n")
+	}
+	for _, p := range a.policies {
+		fmt.Fprintf(&sb, " %-34s [teal]%s[white]\n", a.optionLabel(p.OptionID), a.policyLabel(p))
+	}
+	sb.WriteString("\n [gray]The daemon evaluates policies daily; [yellow]e[gray] evaluates now.\n")
+	sb.WriteString("\n [yellow]y/Enter[white]:Confirm  [yellow]n[white]:Dismiss  [yellow]a[white]:Add Policy  [yellow]d[white]:Delete Policy  [yellow]e[white]:Evaluate  [gray]ESC to close")
+	return sb.String()
+}
+
+// confirmPolicyAction opens the close form prefilled at the queued mark. A
+// roll then opens the new option form for the same contract at the suggested
+// expiry.
+func (a *App) confirmPolicyAction(act db.PolicyAction) {
+	ctx := context.Background()
+	index := a.optionIndex(act.OptionID)
+	if index < 0 {
+		// The position was closed some other way; nothing is left to do
+		if err := a.db.ResolvePolicyAction(ctx, act.ID, db.PolicyActionDismissed); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+		}
+		a.pages.RemovePage("policyactions")
+		a.refreshData()
+		return
+	}
+	o := a.book.options[index]
+
+	price := ""
+	if act.Price.Valid {
+		price = a.book.locale.EditNumber(act.Price.Decimal.StringFixed(2))
+	}
+	a.pages.RemovePage("policyactions")
+	a.showCloseOptionForm(index, price, func() {
+		if err := a.db.ResolvePolicyAction(ctx, act.ID, db.PolicyActionDone); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+		a.loadPolicies(ctx)
+		a.updateStatusBar()
+		if act.Action == db.PolicyActionRoll {
+			roll := db.Option{Ticker: o.Ticker, OptionType: o.OptionType, Action: o.Action, Strike: o.Strike,
+				ExpiryDate: act.RollExpiry, Quantity: o.Quantity, Multiplier: o.Multiplier, Settlement: o.Settlement,
+				Currency: o.Currency, External: o.External}
+			a.showAddOptionForm(&roll, nil)
+		}
+	})
+}
+
+// evaluatePolicies runs the daemon's policy check now, in the background
+func (a *App) evaluatePolicies(redraw func()) {
+	a.statusBar.SetText(" [yellow]Evaluating policies...")
+	a.goSafe("policy evaluation", func() {
+		ctx := context.Background()
+		queued, err := a.query.QueuePolicyActions(ctx, a.now())
+		a.queueUpdateDraw(func() {
+			if err != nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+				return
+			}
+			a.loadPolicies(ctx)
+			redraw()
+			a.statusBar.SetText(fmt.Sprintf(" [green]Policies evaluated: %d new action(s)", len(queued)))
+		})
+	})
+}
+
+// showPolicyForm attaches a policy to an active option
+func (a *App) showPolicyForm(redraw func()) {
+	var ids, labels []string
+	for _, o := range a.book.options {
+		if o.Status == "ACTIVE" {
+			ids = append(ids, o.ID)
+			labels = append(labels, a.optionLabel(o.ID))
+		}
+	}
+	if len(ids) == 0 {
+		a.statusBar.SetText(" [yellow]No active options to attach a policy to")
+		return
+	}
+	kinds := make([]string, len(policyKinds))
+	for i, k := range policyKinds {
+		kinds[i] = k.label
+	}
+
+	form := tview.NewForm().
+		AddDropDown("Option", labels, 0, nil).
+		AddDropDown("Policy", kinds, 0, nil).
+		AddInputField("DTE or profit %", "21", 8, nil, nil).
+		AddCheckbox("Only if ITM", false, nil)
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		optionIdx, _ := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+		kindIdx, _ := form.GetFormItem(1).(*tview.DropDown).GetCurrentOption()
+		threshold, err := a.book.locale.ParseNumber(form.GetFormItem(2).(*tview.InputField).GetText())
+		if err != nil || threshold.IsNegative() {
+			a.statusBar.SetText(" [red]Invalid DTE or profit %")
+			return
+		}
+		itmOnly := form.GetFormItem(3).(*tview.Checkbox).IsChecked()
+
+		ctx := context.Background()
+		if err := a.db.AddOptionPolicy(ctx, ids[optionIdx], policyKinds[kindIdx].kind, threshold, itmOnly); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+
+		a.pages.RemovePage("policyform")
+		a.loadPolicies(ctx)
+		redraw()
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("policyform")
+	})
+
+	form.SetBorder(true).SetTitle(" Add Policy ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("policyform", form, 64, 13)
+}
+
+// showDeletePolicyForm removes one policy along with its queued actions
+func (a *App) showDeletePolicyForm(redraw func()) {
+	policies := a.policies
+	labels := make([]string, len(policies))
+	for i, p := range policies {
+		labels[i] = a.optionLabel(p.OptionID) + ": " + a.policyLabel(p)
+	}
+
+	form := tview.NewForm().
+		AddDropDown("Policy", labels, 0, nil)
+
+	styleForm(form)
+
+	form.AddButton("Delete", func() {
+		i, _ := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+
+		ctx := context.Background()
+		if err := a.db.DeleteOptionPolicy(ctx, policies[i].ID); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+
+		a.pages.RemovePage("policydelete")
+		a.loadPolicies(ctx)
+		redraw()
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("policydelete")
+	})
+
+	form.SetBorder(true).SetTitle(" Delete Policy ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("policydelete", form, 70, 7)
+}
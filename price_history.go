@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/yahoo"
+)
+
+// priceHistoryYears is how far back a ticker's history is downloaded the
+// first time it is synced
+const priceHistoryYears = 2
+
+// storedHistoryMaxAge is how old a ticker's latest stored bar may be before
+// history is fetched from Yahoo again; it spans a long weekend
+const storedHistoryMaxAge = 5 * 24 * time.Hour
+
+// priceSyncResult counts what a price sync stored
+type priceSyncResult struct {
+	Tickers int
+	Bars    int
+}
+
+// syncTickers lists the tickers whose history is kept: every account's
+// holdings and open options, the CSP watchlist, and the beta benchmarks
+func syncTickers(ctx context.Context, store db.Store) ([]string, error) {
+	tickers := slices.Clone(betaBenchmarks)
+	err := forEachAccount(ctx, store, func(_ string, store db.Store) error {
+		holdings, err := store.GetHoldings(ctx)
+		if err != nil {
+			return fmt.Errorf("loading holdings: %w", err)
+		}
+		options, err := store.GetActiveOptions(ctx)
+		if err != nil {
+			return fmt.Errorf("loading options: %w", err)
+		}
+		for _, h := range holdings {
+			tickers = append(tickers, h.Ticker)
+		}
+		for _, o := range options {
+			if o.Status == "ACTIVE" {
+				tickers = append(tickers, o.Ticker)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	watchlist, err := store.GetCSPWatchlist(ctx)
+	if err != nil {
+		slog.Debug("loading CSP watchlist for price sync", "err", err)
+	}
+	for _, w := range watchlist {
+		tickers = append(tickers, w.Ticker)
+	}
+	slices.Sort(tickers)
+	return slices.Compact(tickers), nil
+}
+
+// syncPriceHistory downloads the daily bars each ticker is missing, from the
+// day after its latest stored bar, or priceHistoryYears back for a ticker
+// not stored yet. A ticker that fails is skipped and tried again next sync.
+func syncPriceHistory(ctx context.Context, store db.Store, market yahoo.Provider, now time.Time) (priceSyncResult, error) {
+	var result priceSyncResult
+	tickers, err := syncTickers(ctx, store)
+	if err != nil {
+		return result, err
+	}
+	latest, err := store.GetPriceHistoryLatest(ctx)
+	if err != nil {
+		return result, fmt.Errorf("reading price history (is schema_history.sql applied?): %w", err)
+	}
+
+	var errs []error
+	for _, ticker := range tickers {
+		since := now.AddDate(-priceHistoryYears, 0, 0)
+		if day, ok := latest[ticker]; ok {
+			since = day.AddDate(0, 0, 1)
+		}
+		if since.After(now) {
+			continue
+		}
+		bars, err := market.FetchDailyBars(ticker, since)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ticker, err))
+			continue
+		}
+		if len(bars) == 0 {
+			continue
+		}
+		if err := store.SavePriceBars(ctx, ticker, bars); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ticker, err))
+			continue
+		}
+		result.Tickers++
+		result.Bars += len(bars)
+	}
+	return result, errors.Join(errs...)
+}
+
+// runPriceSync implements the prices sync command
+func runPriceSync(store db.Store, market yahoo.Provider, args []string) error {
+	if len(args) == 0 || args[0] != "sync" {
+		return errors.New("usage: anyhowhodl prices sync")
+	}
+	result, err := syncPriceHistory(context.Background(), store, market, time.Now())
+	fmt.Printf("Stored %d bar(s) for %d ticker(s)\n", result.Bars, result.Tickers)
+	return err
+}
+
+// storedHistory is a yahoo.Provider that serves price history from the
+// price_history table, falling back to Yahoo for tickers not synced or
+// synced too long ago
+type storedHistory struct {
+	yahoo.Provider
+	db db.Store
+}
+
+// withStoredHistory wraps market so history comes from store when it can
+func withStoredHistory(store db.Store, market yahoo.Provider) yahoo.Provider {
+	return storedHistory{Provider: market, db: store}
+}
+
+// FetchPriceSeries returns the last year of stored closes, or fetches them
+func (s storedHistory) FetchPriceSeries(ticker string) ([]analytics.PricePoint, error) {
+	now := time.Now()
+	bars, err := s.db.GetPriceBars(context.Background(), ticker, now.AddDate(-1, 0, 0))
+	if err != nil || len(bars) == 0 || now.Sub(time.Unix(bars[len(bars)-1].Time, 0)) > storedHistoryMaxAge {
+		return s.Provider.FetchPriceSeries(ticker)
+	}
+	points := make([]analytics.PricePoint, len(bars))
+	for i, b := range bars {
+		points[i] = b.Point()
+	}
+	return points, nil
+}
+
+// FetchCloseHistory returns the closes over h from the stored bars, or
+// fetches them when the range is not stored
+func (s storedHistory) FetchCloseHistory(ticker string, h yahoo.History) ([]float64, error) {
+	now := time.Now()
+	since := h.Since(now)
+	bars, err := s.db.GetPriceBars(context.Background(), ticker, since)
+	if err != nil || len(bars) == 0 || now.Sub(time.Unix(bars[len(bars)-1].Time, 0)) > storedHistoryMaxAge ||
+		time.Unix(bars[0].Time, 0).Sub(since) > storedHistoryMaxAge {
+		return s.Provider.FetchCloseHistory(ticker, h)
+	}
+	points := make([]analytics.PricePoint, len(bars))
+	for i, b := range bars {
+		points[i] = b.Point()
+	}
+	if h.Weekly() {
+		points = analytics.WeeklyCloses(points)
+	}
+	closes := make([]float64, len(points))
+	for i, p := range points {
+		closes[i] = p.Close
+	}
+	return closes, nil
+}
+
+// FetchPriceHistory returns the last year of closes, stored or fetched
+func (s storedHistory) FetchPriceHistory(ticker string) ([]float64, error) {
+	series, err := s.FetchPriceSeries(ticker)
+	if err != nil {
+		return nil, err
+	}
+	closes := make([]float64, len(series))
+	for i, p := range series {
+		closes[i] = p.Close
+	}
+	return closes, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/query"
+	"anyhowhodl/internal/yahoo"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// manualPriceStaleAfter is the age at which a manual price shows red
+const manualPriceStaleAfter = 30 * 24 * time.Hour
+
+// applyManualPrices fills in quotes Yahoo had no price for from the manual
+// prices. Quotes the previous refresh filled in survive a failed Yahoo fetch,
+// so they are dropped first and a cleared price does not linger.
+func (a *App) applyManualPrices(ctx context.Context) {
+	for ticker, p := range a.book.manualPrices {
+		if a.book.quotes[ticker] == (yahoo.Quote{Symbol: ticker, Price: p.Price.InexactFloat64()}) {
+			delete(a.book.quotes, ticker)
+		}
+	}
+	manual, _ := a.db.GetManualPrices(ctx) // None without schema_prices.sql
+	a.book.quotes, a.book.manualPrices = query.WithManualPrices(a.book.quotes, manual)
+}
+
+// manualPriceCell shows a manual price with its age, orange while fresh and
+// red once stale
+func (v *HoldingsView) manualPriceCell(p db.ManualPrice, bg tcell.Color) *tview.TableCell {
+	age := v.cells.now().Sub(p.UpdatedAt)
+	color := tcell.ColorOrange
+	if age > manualPriceStaleAfter {
+		color = tcell.ColorRed
+	}
+	return tview.NewTableCell(fmt.Sprintf(" $%s manual %dd ", v.book.formatPrice(p.Price), int(age.Hours()/24))).
+		SetTextColor(color).
+		SetBackgroundColor(bg).
+		SetAlign(tview.AlignLeft).
+		SetExpansion(1)
+}
+
+// showManualPriceForm sets or clears the manual price used for ticker while
+// Yahoo has no data for it
+func (a *App) showManualPriceForm(ticker string) {
+	current := ""
+	if p, ok := a.book.manualPrices[ticker]; ok {
+		current = a.book.locale.EditNumber(p.Price.String())
+	}
+
+	form := tview.NewForm().
+		AddInputField("Ticker", ticker, 10, nil, nil).
+		AddInputField("Price (empty to clear)", current, 15, nil, nil)
+	a.tickerAutocomplete(form.GetFormItem(0).(*tview.InputField))
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		ticker := strings.ToUpper(strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText()))
+		priceStr := strings.TrimSpace(form.GetFormItem(1).(*tview.InputField).GetText())
+		if ticker == "" {
+			a.statusBar.SetText(" [red]Ticker is required")
+			return
+		}
+
+		ctx := context.Background()
+		var err error
+		if priceStr == "" {
+			err = a.db.DeleteManualPrice(ctx, ticker)
+		} else {
+			var price decimal.Decimal
+			price, err = a.book.locale.ParseNumber(priceStr)
+			if err != nil || !price.IsPositive() {
+				a.statusBar.SetText(" [red]Invalid price")
+				return
+			}
+			err = a.db.SetManualPrice(ctx, ticker, price)
+		}
+		if err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+
+		a.pages.RemovePage("manualprice")
+		a.refreshData()
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("manualprice")
+	})
+
+	form.SetBorder(true).SetTitle(" Manual Price ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("manualprice", form, 50, 9)
+}
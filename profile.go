@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime/pprof"
+)
+
+// startCPUProfile writes a CPU profile to CPU_PROFILE for the life of the
+// process, so a real CSP scan in the TUI or daemon can be inspected with
+// `go tool pprof`. Returns a nil closer when profiling is off or failed.
+func startCPUProfile() io.Closer {
+	path := os.Getenv("CPU_PROFILE")
+	if path == "" {
+		return nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cpu profile disabled: %v\n", err)
+		return nil
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		fmt.Fprintf(os.Stderr, "cpu profile disabled: %v\n", err)
+		return nil
+	}
+	slog.Info("cpu profiling", "path", path)
+	return cpuProfile{f}
+}
+
+type cpuProfile struct{ f *os.File }
+
+func (p cpuProfile) Close() error {
+	pprof.StopCPUProfile()
+	return p.f.Close()
+}
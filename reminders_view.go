@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// loadReminders reads the reminders not yet dismissed. Without
+// schema_reminders.sql there are none.
+func (a *App) loadReminders(ctx context.Context) {
+	reminders, err := a.db.GetReminders(ctx)
+	if err != nil {
+		slog.Debug("loading reminders", "err", err)
+	}
+	a.reminders = reminders
+}
+
+// dueReminders counts the reminders due today or earlier
+func (a *App) dueReminders() int {
+	today := a.today()
+	n := 0
+	for _, r := range a.reminders {
+		if r.Due(today) {
+			n++
+		}
+	}
+	return n
+}
+
+// showReminderForm attaches a dated reminder to the option at index
+func (a *App) showReminderForm(index int) {
+	o := a.book.options[index]
+
+	form := tview.NewForm().
+		AddInputField("Remind on ("+a.book.locale.DateHint+")", a.book.locale.FormatDate(a.today().AddDate(0, 0, 7)), 15, nil, nil).
+		AddInputField("Note", "", 40, nil, nil)
+
+	checks := newFormChecks(form)
+	checks.add(form, 0, requiredCheck(a.dateCheck(notPast)), nil)
+	checks.add(form, 1, requiredCheck(nil), nil)
+	a.dateField(form, 0)
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		if !checks.valid() {
+			a.statusBar.SetText(" [red]Fix the fields marked in red")
+			return
+		}
+		remindOn, err := a.parseDate(form.GetFormItem(0).(*tview.InputField).GetText())
+		if err != nil {
+			a.statusBar.SetText(" [red]Invalid date")
+			return
+		}
+		note := strings.TrimSpace(form.GetFormItem(1).(*tview.InputField).GetText())
+
+		ctx := context.Background()
+		if err := a.db.AddReminder(ctx, o.ID, remindOn, note); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+		a.pages.RemovePage("reminderform")
+		a.loadReminders(ctx)
+		a.checkAlerts()
+		a.updateStatusBar()
+		a.statusBar.SetText(fmt.Sprintf(" [green]Reminder set for %s %s on %s", o.Ticker, o.OptionType, a.book.locale.FormatDate(remindOn)))
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("reminderform")
+	})
+
+	form.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Reminder: %s %s $%s ", o.Ticker, o.OptionType, a.book.formatPrice(o.Strike))).
+		SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("reminderform", form, 64, 12)
+}
+
+// showRemindersView is the reminders inbox: reminders due now first, then
+// upcoming ones in gray, each dismissed with d or Enter
+func (a *App) showRemindersView() {
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	table.SetBorder(true).
+		SetTitle(" Reminders ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(" [yellow]d/Enter[white]:Dismiss  [gray]Add reminders from an option's actions (Enter on the options table). ESC to close")
+
+	a.fillRemindersTable(table)
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() != tcell.KeyEnter && event.Rune() != 'd' {
+			return event
+		}
+		row, _ := table.GetSelection()
+		if row < 1 || row > len(a.reminders) || a.readOnly() {
+			return nil
+		}
+		ctx := context.Background()
+		if err := a.db.DismissReminder(ctx, a.reminders[row-1].ID); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return nil
+		}
+		a.loadReminders(ctx)
+		a.checkAlerts()
+		a.updateStatusBar()
+		a.fillRemindersTable(table)
+		return nil
+	})
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(help, 1, 0, false)
+	a.pages.AddPage("reminders", flex, true, true)
+}
+
+// fillRemindersTable lists the open reminders, soonest first
+func (a *App) fillRemindersTable(table *tview.Table) {
+	table.Clear()
+	for i, h := range []string{"DUE", "OPTION", "NOTE"} {
+		table.SetCell(0, i, tview.NewTableCell(" "+h+" ").
+			SetTextColor(tcell.ColorBlack).
+			SetBackgroundColor(tcell.ColorTeal).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+	if len(a.reminders) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell(" No reminders").
+			SetTextColor(tcell.ColorGray).
+			SetSelectable(false))
+		return
+	}
+	today := a.today()
+	for i, r := range a.reminders {
+		row := i + 1
+		dateColor, noteColor := tcell.ColorYellow, tcell.ColorWhite
+		if r.RemindOn.Before(today) {
+			dateColor = tcell.ColorRed
+		}
+		if !r.Due(today) {
+			dateColor, noteColor = tcell.ColorGray, tcell.ColorGray
+		}
+		table.SetCell(row, 0, tview.NewTableCell(" "+a.book.locale.FormatDate(r.RemindOn)+" ").SetTextColor(dateColor).SetExpansion(1))
+		table.SetCell(row, 1, tview.NewTableCell(" "+a.optionLabel(r.OptionID)+" ").SetTextColor(tcell.ColorFuchsia).SetExpansion(1))
+		table.SetCell(row, 2, tview.NewTableCell(" "+r.Note+" ").SetTextColor(noteColor).SetExpansion(2))
+	}
+	table.Select(1, 0)
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/fake"
+	"anyhowhodl/internal/locale"
+	"anyhowhodl/internal/yahoo"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden screens in testdata/golden")
+
+// renderFixture is the fixed clock for golden screens: a Monday, mid-session
+var renderFixture = time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+
+// newRenderApp loads a small fixed book through refreshData with every widget
+// built but no terminal attached
+func newRenderApp(t *testing.T) *App {
+	t.Helper()
+	ctx := context.Background()
+	store := fake.NewStore()
+	store.Now = func() time.Time { return renderFixture }
+	market := fake.NewMarket()
+
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	dec := decimal.RequireFromString
+
+	store.AddHolding(ctx, "AAPL", dec("200"), dec("150.25"), db.DefaultCurrency, day(2024, 5, 1), decimal.NullDecimal{}, "")
+	store.AddHolding(ctx, "MSFT", dec("50"), dec("410"), db.DefaultCurrency, day(2025, 1, 15), decimal.NewNullDecimal(dec("450")), "")
+	store.AddHolding(ctx, "NVDA", dec("120"), dec("95.5"), db.DefaultCurrency, day(2025, 8, 4), decimal.NullDecimal{}, "")
+	store.SetAvailableCash(ctx, dec("25000"))
+
+	store.AddOption(ctx, "AAPL", "CALL", "SELL", dec("230"), day(2026, 3, 6), 2, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, dec("1.85"), dec("1.30"), "")
+	store.AddOption(ctx, "MSFT", "PUT", "SELL", dec("380"), day(2026, 3, 20), 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, dec("6.40"), dec("0.65"), "")
+	store.AddOption(ctx, "NVDA", "CALL", "SELL", dec("140"), day(2026, 4, 17), 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, dec("3.10"), dec("0.65"), "")
+	store.AddOption(ctx, "TSLA", "PUT", "SELL", dec("200"), day(2026, 6, 18), 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, dec("9.75"), dec("0.65"), "")
+	store.AddOption(ctx, "NVDA", "PUT", "SELL", dec("110"), day(2026, 2, 20), 1, db.DefaultMultiplier, db.SettlementPhysical, db.DefaultCurrency, dec("2.05"), dec("0.65"), "") // Expired OTM
+
+	market.Quotes["AAPL"] = yahoo.Quote{Symbol: "AAPL", Price: 241.80, FiftyTwoWeekHigh: 260.10, PctFromHigh: -7.0, FiftyTwoWeekLow: 164.08, PctFromLow: 47.4}
+	market.Quotes["MSFT"] = yahoo.Quote{Symbol: "MSFT", Price: 452.35, FiftyTwoWeekHigh: 468.00, PctFromHigh: -3.3, FiftyTwoWeekLow: 344.79, PctFromLow: 31.2}
+	market.Quotes["NVDA"] = yahoo.Quote{Symbol: "NVDA", Price: 128.40, FiftyTwoWeekHigh: 153.13, PctFromHigh: -16.1, FiftyTwoWeekLow: 86.62, PctFromLow: 48.2}
+	market.Quotes["TSLA"] = yahoo.Quote{Symbol: "TSLA", Price: 188.20, FiftyTwoWeekHigh: 488.54, PctFromHigh: -61.5, FiftyTwoWeekLow: 182.00, PctFromLow: 3.4}
+
+	a := newTestApp(store, market)
+	a.clock = func() time.Time { return renderFixture }
+	a.book.quotes = make(map[string]yahoo.Quote)
+	a.optionsView.weeklyView = true
+	a.optionsView.showExpired = true
+	a.app = tview.NewApplication()
+	a.initWidgets()
+	a.refreshData()
+	return a
+}
+
+// renderText draws p onto a simulated screen and returns its text, one line per
+// row with trailing spaces trimmed
+func renderText(t *testing.T, p tview.Primitive, width, height int) string {
+	t.Helper()
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	screen.SetSize(width, height)
+
+	p.SetRect(0, 0, width, height)
+	p.Draw(screen)
+	screen.Show()
+
+	cells, w, h := screen.GetContents()
+	var sb strings.Builder
+	for y := 0; y < h; y++ {
+		var line strings.Builder
+		for x := 0; x < w; x++ {
+			b := cells[y*w+x].Bytes
+			if len(b) == 0 {
+				b = []byte{' '}
+			}
+			line.Write(b)
+		}
+		sb.WriteString(strings.TrimRight(line.String(), " "))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// checkGolden compares got with testdata/golden/<name>.txt, rewriting it when
+// the test is run with -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name+".txt")
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s does not match golden screen (run with -update after checking the change)\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func TestRenderGolden(t *testing.T) {
+	a := newRenderApp(t)
+
+	tests := []struct {
+		name          string
+		widget        tview.Primitive
+		width, height int
+	}{
+		{"holdings", a.holdingsView.table, 150, 10},
+		{"options", a.optionsView.table, 120, 14},
+		{"expiry_timeline", a.optionsView.expiryTimeline, 130, 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkGolden(t, tt.name, renderText(t, tt.widget, tt.width, tt.height))
+		})
+	}
+}
+
+// TestHoldingSignalNearLow checks the SIGNAL column, which the holdings
+// golden file is too narrow to show, at and around the near-low threshold
+func TestHoldingSignalNearLow(t *testing.T) {
+	a := newRenderApp(t)
+	market := a.yahoo.(*fake.Market)
+
+	tests := []struct {
+		pctFromLow float64
+		want       string
+	}{
+		{nearLowPct - 0.1, "BUY"},
+		{nearLowPct, "BUY"},
+		{nearLowPct + 0.1, "-"},
+	}
+	for _, tt := range tests {
+		// Up under 25% and well off the high, so no other signal applies
+		market.Quotes["NVDA"] = yahoo.Quote{Symbol: "NVDA", Price: 100, FiftyTwoWeekHigh: 153.13, PctFromHigh: -34.7,
+			FiftyTwoWeekLow: 100 / (1 + tt.pctFromLow/100), PctFromLow: tt.pctFromLow}
+		a.refreshData()
+
+		row := -1
+		for r := 1; r < a.holdingsView.table.GetRowCount(); r++ {
+			if strings.TrimSpace(a.holdingsView.table.GetCell(r, 0).Text) == "NVDA" {
+				row = r
+			}
+		}
+		if row < 0 {
+			t.Fatal("NVDA not in the holdings table")
+		}
+		cell := a.holdingsView.table.GetCell(row, 13)
+		if got := strings.TrimSpace(cell.Text); got != tt.want {
+			t.Errorf("%.1f%% above the low: SIGNAL = %q, want %q", tt.pctFromLow, got, tt.want)
+		}
+		if fg, _, _ := cell.Style.Decompose(); tt.want == "BUY" && fg != tcell.ColorLime {
+			t.Errorf("%.1f%% above the low: BUY in %v, want lime", tt.pctFromLow, fg)
+		}
+	}
+}
+
+func TestRenderMonthlyTimeline(t *testing.T) {
+	a := newRenderApp(t)
+	a.optionsView.weeklyView = false
+	a.optionsView.updateExpiryTimeline()
+	checkGolden(t, "expiry_timeline_monthly", renderText(t, a.optionsView.expiryTimeline, 130, 16))
+}
+
+func TestRenderLocale(t *testing.T) {
+	a := newRenderApp(t)
+	a.book.locale, _ = locale.Lookup("de-DE")
+	a.refreshData()
+	checkGolden(t, "holdings_de", renderText(t, a.holdingsView.table, 150, 10))
+	checkGolden(t, "options_de", renderText(t, a.optionsView.table, 120, 14))
+}
+
+// blankCells stands in for the cells and lines other features fill in
+type blankCells struct{}
+
+func (blankCells) rowBackground(string) tcell.Color { return tcell.ColorBlack }
+func (blankCells) weightCell(string, decimal.Decimal, tcell.Color) *tview.TableCell {
+	return tview.NewTableCell(" - ")
+}
+func (blankCells) dividendCell(db.Holding, tcell.Color) *tview.TableCell {
+	return tview.NewTableCell(" - ")
+}
+func (blankCells) holdingEarningsCell(string, tcell.Color) *tview.TableCell {
+	return tview.NewTableCell(" - ")
+}
+func (blankCells) now() time.Time                       { return renderFixture }
+func (blankCells) dividendsLine(decimal.Decimal) string { return "" }
+func (blankCells) incomeLine(decimal.Decimal) string    { return "" }
+
+// TestViewsWithoutApp renders the holdings table and the summary from a book
+// alone: the views redraw on the bus without an App behind them
+func TestViewsWithoutApp(t *testing.T) {
+	b := newBook()
+	b.holdings = []db.Holding{
+		{ID: "1", Ticker: "AAPL", Quantity: decimal.NewFromInt(10), AvgCost: decimal.NewFromInt(150)},
+		{ID: "2", Ticker: "KO", Quantity: decimal.NewFromInt(20), AvgCost: decimal.NewFromInt(60)},
+	}
+	b.quotes["AAPL"] = yahoo.Quote{Symbol: "AAPL", Price: 200}
+	b.cash = decimal.NewFromInt(500)
+
+	var bus eventBus
+	holdings := newHoldingsView(b, blankCells{}, &bus)
+	summary := newSummaryView(b, holdings, blankCells{}, &bus)
+	bus.publish(eventDataLoaded)
+
+	if rows := holdings.table.GetRowCount(); rows != 3 {
+		t.Errorf("holdings table rows = %d, want a header and 2", rows)
+	}
+	// AAPL at its quote, KO at cost without one
+	if want := decimal.NewFromInt(3200); !holdings.value.Equal(want) {
+		t.Errorf("holdings value = %s, want %s", holdings.value, want)
+	}
+	if got := summary.text.GetText(true); !strings.Contains(got, "Total: $3,700.00") {
+		t.Errorf("summary = %q, want the holdings and cash total", got)
+	}
+}
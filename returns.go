@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/db"
+
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// positionReturn is a ticker's money- and time-weighted return over the
+// ledger's entries for it
+type positionReturn struct {
+	Ticker       string
+	XIRR, TWR    float64
+	HasXIRR      bool
+	HasTWR       bool
+	Value        decimal.Decimal // Held now, as the holdings table values it
+	NetCashFlows decimal.Decimal // Premiums, dividends, sales less purchases and fees
+}
+
+// shareMoves are the ledger kinds whose quantity is shares bought (positive)
+// or sold (negative)
+var shareMoves = map[string]bool{db.TxBuy: true, db.TxSell: true, db.TxAssignment: true}
+
+// portfolioReturns computes the portfolio's XIRR and TWR from the snapshots,
+// oldest first, treating contributions and withdrawals in the ledger as the
+// only money moved in or out. Everything else, premiums included, is return.
+func portfolioReturns(snapshots []db.PortfolioSnapshot, txs []db.Transaction) (xirr, twr float64, hasXIRR, hasTWR bool) {
+	if len(snapshots) == 0 {
+		return 0, 0, false, false
+	}
+	var contributions []db.Transaction
+	for _, t := range txs {
+		if t.Kind == db.TxContribution {
+			contributions = append(contributions, t)
+		}
+	}
+	sort.SliceStable(contributions, func(i, j int) bool { return contributions[i].CreatedAt.Before(contributions[j].CreatedAt) })
+
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+	flows := []analytics.CashFlow{{Date: first.Date, Amount: -first.Total().InexactFloat64()}}
+	points := make([]analytics.ValuePoint, len(snapshots))
+	next := 0
+	for i, s := range snapshots {
+		// Contributions up to and including the snapshot's day; those on
+		// days without one count toward the next
+		day := s.Date.Format(time.DateOnly)
+		flow := 0.0
+		for next < len(contributions) && contributions[next].CreatedAt.Format(time.DateOnly) <= day {
+			c := contributions[next]
+			next++
+			if i == 0 {
+				continue // Already in the first snapshot's value
+			}
+			flow += c.Amount.InexactFloat64()
+			flows = append(flows, analytics.CashFlow{Date: c.CreatedAt, Amount: -c.Amount.InexactFloat64()})
+		}
+		points[i] = analytics.ValuePoint{Date: s.Date, Value: s.Total().InexactFloat64(), Flow: flow}
+	}
+	flows = append(flows, analytics.CashFlow{Date: last.Date, Amount: last.Total().InexactFloat64()})
+
+	xirr, hasXIRR = analytics.XIRR(flows)
+	twr, hasTWR = analytics.TWR(points)
+	return xirr, twr, hasXIRR, hasTWR
+}
+
+// accountsLedger is the ledger of every account. The portfolio snapshots
+// are taken across accounts, so the contributions measured against them
+// must be too.
+func accountsLedger(ctx context.Context, store db.Store) ([]db.Transaction, error) {
+	var txs []db.Transaction
+	err := forEachAccount(ctx, store, func(_ string, store db.Store) error {
+		ledger, err := store.GetTransactions(ctx)
+		txs = append(txs, ledger...)
+		return err
+	})
+	return txs, err
+}
+
+// positionReturns computes each ticker's returns from its ledger entries,
+// oldest first, and what is held now. The XIRR treats every entry as cash
+// paid into or taken out of the position and the current value as the last;
+// the TWR also needs the stored daily closes to value the shares held
+// between entries, and is left out for tickers without them.
+func (a *App) positionReturns(ctx context.Context, txs []db.Transaction, now time.Time) []positionReturn {
+	byTicker := make(map[string][]db.Transaction)
+	for _, t := range txs {
+		if t.Ticker != "" {
+			byTicker[t.Ticker] = append(byTicker[t.Ticker], t)
+		}
+	}
+	held := make(map[string]db.Holding)
+	values, _, _ := a.book.positionValues()
+	current := make(map[string]decimal.Decimal)
+	for i, h := range a.book.holdings {
+		held[h.Ticker] = h
+		current[h.Ticker] = values[i]
+	}
+
+	var out []positionReturn
+	for ticker, entries := range byTicker {
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].CreatedAt.Before(entries[j].CreatedAt) })
+		r := positionReturn{Ticker: ticker, Value: current[ticker]}
+
+		flows := make([]analytics.CashFlow, 0, len(entries)+1)
+		for _, t := range entries {
+			flows = append(flows, analytics.CashFlow{Date: t.CreatedAt, Amount: t.Amount.InexactFloat64()})
+			r.NetCashFlows = r.NetCashFlows.Add(t.Amount)
+		}
+		flows = append(flows, analytics.CashFlow{Date: now, Amount: r.Value.InexactFloat64()})
+		r.XIRR, r.HasXIRR = analytics.XIRR(flows)
+
+		bars, err := a.db.GetPriceBars(ctx, ticker, entries[0].CreatedAt.AddDate(0, 0, -7))
+		if err == nil && len(bars) > 0 {
+			points := positionValuePoints(entries, held[ticker].Quantity, bars)
+			points = append(points, analytics.ValuePoint{Date: now, Value: r.Value.InexactFloat64()})
+			r.TWR, r.HasTWR = analytics.TWR(points)
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Ticker < out[j].Ticker })
+	return out
+}
+
+// positionValuePoints values a position at the close of each day it has
+// ledger entries, with the shares held after them. Shares are worked back
+// from the quantity held now, so entries recorded without moving cash (such
+// as imported holdings) shift earlier counts rather than being lost.
+func positionValuePoints(entries []db.Transaction, sharesNow decimal.Decimal, bars []analytics.DailyBar) []analytics.ValuePoint {
+	type day struct {
+		date   time.Time
+		flow   decimal.Decimal
+		shares decimal.Decimal // After the day's entries
+	}
+	var days []day
+	for _, t := range entries {
+		key := t.CreatedAt.Format(time.DateOnly)
+		if len(days) == 0 || days[len(days)-1].date.Format(time.DateOnly) != key {
+			days = append(days, day{date: t.CreatedAt})
+		}
+		// Cash taken out of the position is money put into it
+		days[len(days)-1].flow = days[len(days)-1].flow.Sub(t.Amount)
+	}
+	shares := sharesNow
+	e := len(entries) - 1
+	for i := len(days) - 1; i >= 0; i-- {
+		days[i].shares = decimal.Max(shares, decimal.Zero)
+		for ; e >= 0 && entries[e].CreatedAt.Format(time.DateOnly) == days[i].date.Format(time.DateOnly); e-- {
+			if shareMoves[entries[e].Kind] {
+				shares = shares.Sub(entries[e].Quantity)
+			}
+		}
+	}
+
+	points := make([]analytics.ValuePoint, 0, len(days))
+	b := -1
+	for _, d := range days {
+		for b+1 < len(bars) && time.Unix(bars[b+1].Time, 0).Format(time.DateOnly) <= d.date.Format(time.DateOnly) {
+			b++
+		}
+		price := bars[0].Close // Before the stored history, the earliest close stands in
+		if b >= 0 {
+			price = bars[b].Close
+		}
+		points = append(points, analytics.ValuePoint{
+			Date:  d.date,
+			Value: d.shares.InexactFloat64() * price,
+			Flow:  d.flow.InexactFloat64(),
+		})
+	}
+	return points
+}
+
+// returnsReport renders the portfolio's and each ticker's XIRR and TWR
+func (a *App) returnsReport(ctx context.Context, now time.Time) string {
+	var sb strings.Builder
+	snapshots, err := a.db.GetPortfolioSnapshots(ctx, time.Time{})
+	if err != nil {
+		return fmt.Sprintf(" [red]Error loading snapshots: %v[white]\n", err)
+	}
+	txs, err := a.db.GetTransactions(ctx)
+	if err != nil {
+		return fmt.Sprintf(" [red]Error loading transactions: %v[white]\n", err)
+	}
+	ledgers, err := accountsLedger(ctx, a.db)
+	if err != nil {
+		return fmt.Sprintf(" [red]Error loading transactions: %v[white]\n", err)
+	}
+
+	pct := func(v float64, ok bool, suffix string) string {
+		if !ok {
+			return "—"
+		}
+		sign := "+"
+		if v < 0 {
+			sign = "-"
+			v = -v
+		}
+		return sign + a.book.locale.FormatFloat(v*100, 2) + "%" + suffix
+	}
+
+	if len(snapshots) > 0 {
+		xirr, twr, hasXIRR, hasTWR := portfolioReturns(snapshots, ledgers)
+		fmt.Fprintf(&sb, " [teal]Returns since %s%s[white]\n", snapshots[0].Date.Format("Jan 2, 2006"), a.book.acrossAccounts())
+		fmt.Fprintf(&sb, " Portfolio  [teal]XIRR[white] %s  [teal]TWR[white] %s\n", pct(xirr, hasXIRR, "/yr"), pct(twr, hasTWR, ""))
+	} else {
+		sb.WriteString(" [teal]Returns[white]\n")
+	}
+
+	positions := a.positionReturns(ctx, txs, now)
+	if len(positions) > 0 {
+		if len(a.book.accounts) > 0 {
+			fmt.Fprintf(&sb, "\n [teal]%s[white]", tview.Escape(a.book.accountName(a.book.account)))
+		}
+		fmt.Fprintf(&sb, "\n [teal]%-8s %14s %14s %14s %12s[white]\n", "TICKER", "CASH FLOWS", "VALUE", "XIRR/YR", "TWR")
+		for _, r := range positions {
+			fmt.Fprintf(&sb, " %-8s %14s %14s %14s %12s\n", r.Ticker,
+				signedDollars(a.book.locale, r.NetCashFlows.InexactFloat64()),
+				"$"+a.book.locale.FormatFloat(r.Value.InexactFloat64(), 2),
+				pct(r.XIRR, r.HasXIRR, ""),
+				pct(r.TWR, r.HasTWR, ""))
+		}
+	}
+	sb.WriteString("\n [gray]XIRR weighs returns by the money invested when; TWR chains period returns so deposits and withdrawals do not count.")
+	sb.WriteString("\n [gray]Premiums and dividends are returns. Per-ticker TWR needs stored daily closes (prices sync).[white]\n")
+	return sb.String()
+}
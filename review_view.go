@@ -0,0 +1,379 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/db"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// reviewAheadDays is how far ahead the weekly review lists expiries and
+// earnings
+const reviewAheadDays = 7
+
+// reviewSection is one heading of the weekly review with its lines, in plain
+// text so they can be shown in the TUI or written as Markdown. empty is shown
+// when there are no lines.
+type reviewSection struct {
+	title string
+	lines []string
+	empty string
+}
+
+// reviewWeekStart is the Monday of t's week
+func reviewWeekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// showReviewView opens the weekly review of the current week; [ and ] step
+// back and forward a week, w writes it to a Markdown file
+func (a *App) showReviewView() {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	view.SetBorder(true).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	current := reviewWeekStart(a.today())
+	from := current
+	show := func() {
+		view.SetTitle(" " + a.reviewTitle(from) + " ")
+		view.SetText(a.reviewText(a.buildWeeklyReview(context.Background(), from)))
+		view.ScrollToBeginning()
+	}
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case '[':
+			from = from.AddDate(0, 0, -7)
+			show()
+			return nil
+		case ']':
+			if from.Before(current) {
+				from = from.AddDate(0, 0, 7)
+				show()
+			}
+			return nil
+		case 'w':
+			a.showReviewExportForm(view, from)
+			return nil
+		}
+		return event
+	})
+
+	show()
+	a.pages.AddPage("review", view, true, true)
+}
+
+// reviewTitle names the week starting from, "Weekly Review: Mar 2 - Mar 8"
+func (a *App) reviewTitle(from time.Time) string {
+	return fmt.Sprintf("Weekly Review: %s - %s", a.book.locale.FormatMonthDay(from), a.book.locale.FormatMonthDay(from.AddDate(0, 0, 6)))
+}
+
+// buildWeeklyReview gathers the week starting from: the options opened and
+// closed in it, assignments, share trades, premium and income, the alerts
+// that fired, and how the CSP watchlist's scores moved, then the expiries and
+// earnings of the next reviewAheadDays from today
+func (a *App) buildWeeklyReview(ctx context.Context, from time.Time) []reviewSection {
+	to := from.AddDate(0, 0, 7)
+	in := func(t time.Time) bool { return !t.Before(from) && t.Before(to) }
+	cash := func(d decimal.Decimal) string {
+		if d.IsNegative() {
+			return "-$" + a.book.locale.FormatFixed(d.Abs(), 2)
+		}
+		return "$" + a.book.locale.FormatFixed(d, 2)
+	}
+	contract := func(o db.Option) string {
+		return fmt.Sprintf("%s %d %s $%s %s %s", o.Action, o.Quantity, o.Ticker, a.book.formatPrice(o.Strike), o.OptionType,
+			a.book.locale.FormatDate(o.ExpiryDate))
+	}
+
+	// Options by what happened to them this week. Closing dates are when the
+	// option last changed, which is when it was closed.
+	opened := reviewSection{title: "Options opened", empty: "None"}
+	closed := reviewSection{title: "Options closed", empty: "None"}
+	assigned := reviewSection{title: "Assignments", empty: "None"}
+	counts := make(map[string]int)
+	for _, o := range a.book.options {
+		if in(o.CreatedAt) {
+			premium := o.Premium.Mul(o.Shares())
+			if o.Action != "SELL" {
+				premium = premium.Neg()
+			}
+			opened.lines = append(opened.lines, fmt.Sprintf("%s at $%s (%s)", contract(o), a.book.formatPrice(o.Premium), cash(premium)))
+			counts["OPENED"]++
+		}
+		if o.Status == "ACTIVE" || !in(o.UpdatedAt) {
+			continue
+		}
+		counts[o.Status]++
+		switch o.Status {
+		case "CLOSED":
+			line := contract(o)
+			if o.ClosePremium.Valid {
+				pl := o.Premium.Sub(o.ClosePremium.Decimal).Mul(o.Shares())
+				if o.Action != "SELL" {
+					pl = pl.Neg()
+				}
+				line += fmt.Sprintf(" closed at $%s (P/L %s)", a.book.formatPrice(o.ClosePremium.Decimal), cash(pl))
+			}
+			closed.lines = append(closed.lines, line)
+		case "EXPIRED":
+			closed.lines = append(closed.lines, contract(o)+" expired")
+		case "ASSIGNED":
+			verb := "bought"
+			if o.OptionType == "CALL" {
+				verb = "called away"
+			}
+			if o.Settlement == db.SettlementCash {
+				verb = "cash-settled"
+			}
+			assigned.lines = append(assigned.lines, fmt.Sprintf("%s: %s shares %s at $%s", contract(o),
+				a.book.formatShares(o.Shares()), verb, a.book.formatPrice(o.Strike)))
+		}
+	}
+
+	// Share trades and income from the ledger
+	trades := reviewSection{title: "Share trades", empty: "None"}
+	var income decimal.Decimal
+	if txs, err := a.db.GetTransactions(ctx); err == nil {
+		for _, tx := range txs {
+			if !in(tx.CreatedAt) {
+				continue
+			}
+			switch tx.Kind {
+			case db.TxBuy, db.TxSell:
+				trades.lines = append(trades.lines, fmt.Sprintf("%s %s %s %s (%s)", a.book.locale.FormatDate(tx.CreatedAt), tx.Kind,
+					a.book.formatShares(tx.Quantity), tx.Ticker, cash(tx.Amount)))
+			case db.TxDividend, db.TxInterest:
+				income = income.Add(tx.Amount)
+			}
+		}
+	}
+
+	summary := reviewSection{title: "Summary"}
+	if premiums, err := a.db.GetPremiumsBetween(ctx, from, to); err == nil {
+		summary.lines = append(summary.lines, fmt.Sprintf("Premium collected: %s net (%s calls, %s puts, %s fees, %s to close)",
+			cash(premiums.NetPL), cash(premiums.CallPremiums), cash(premiums.PutPremiums), cash(premiums.TotalFees), cash(premiums.CloseCosts)))
+	}
+	summary.lines = append(summary.lines,
+		fmt.Sprintf("Options: %d opened, %d closed, %d expired, %d assigned", counts["OPENED"], counts["CLOSED"], counts["EXPIRED"], counts["ASSIGNED"]),
+		"Dividends and interest: "+cash(income))
+
+	alerts := reviewSection{title: "Alerts", empty: "None fired"}
+	if log, err := a.db.GetAlertLog(ctx); err == nil {
+		for _, l := range log {
+			if in(l.FiredAt) {
+				alerts.lines = append(alerts.lines, a.book.locale.FormatDate(l.FiredAt)+" "+l.Message)
+			}
+		}
+	}
+
+	return []reviewSection{summary, opened, closed, assigned, trades, alerts, a.reviewScores(ctx, from, to),
+		a.reviewExpiries(), a.reviewEarnings()}
+}
+
+// reviewScores compares each CSP watchlist ticker's latest score in the week
+// with its latest before it, biggest moves first
+func (a *App) reviewScores(ctx context.Context, from, to time.Time) reviewSection {
+	section := reviewSection{title: "Watchlist scores",
+		empty: "No scores recorded this week; refresh the CSP advisor (p), or run the daemon with CSP_HISTORY_PATH"}
+	history, err := a.db.GetScoreHistory(ctx)
+	if err != nil {
+		return section
+	}
+	var before, latest map[string]float64
+	for _, h := range history {
+		switch {
+		case h.Day.Before(from):
+			before = h.Scores
+		case h.Day.Before(to):
+			latest = h.Scores
+		}
+	}
+
+	type move struct {
+		ticker      string
+		score, prev float64
+		known       bool
+	}
+	var moves []move
+	for ticker, score := range latest {
+		prev, known := before[ticker]
+		if !known {
+			prev = score // New tickers sort after the ones that moved
+		}
+		moves = append(moves, move{ticker, score, prev, known})
+	}
+	slices.SortFunc(moves, func(x, y move) int {
+		if c := cmp.Compare(math.Abs(y.score-y.prev), math.Abs(x.score-x.prev)); c != 0 {
+			return c
+		}
+		return strings.Compare(x.ticker, y.ticker)
+	})
+	for _, m := range moves {
+		if !m.known {
+			section.lines = append(section.lines, fmt.Sprintf("%s %.0f (new)", m.ticker, m.score))
+			continue
+		}
+		section.lines = append(section.lines, fmt.Sprintf("%s %.0f -> %.0f (%+.0f)", m.ticker, m.prev, m.score, m.score-m.prev))
+	}
+	return section
+}
+
+// reviewExpiries lists the active options expiring in the next
+// reviewAheadDays
+func (a *App) reviewExpiries() reviewSection {
+	section := reviewSection{title: fmt.Sprintf("Expiring in the next %d days", reviewAheadDays), empty: "None"}
+	today := a.today()
+	end := today.AddDate(0, 0, reviewAheadDays)
+	var expiring []db.Option
+	for _, o := range a.book.options {
+		if o.Status == "ACTIVE" && !o.ExpiryDate.Before(today) && o.ExpiryDate.Before(end) {
+			expiring = append(expiring, o)
+		}
+	}
+	slices.SortStableFunc(expiring, func(x, y db.Option) int { return x.ExpiryDate.Compare(y.ExpiryDate) })
+	for _, o := range expiring {
+		line := fmt.Sprintf("%s %s %d %s $%s %s", a.book.locale.FormatDate(o.ExpiryDate), o.Action, o.Quantity, o.Ticker,
+			a.book.formatPrice(o.Strike), o.OptionType)
+		if q, ok := a.book.quotes[o.Ticker]; ok && q.Price > 0 {
+			line += fmt.Sprintf(" (price $%s)", a.book.locale.FormatFloat(q.Price, 2))
+		}
+		section.lines = append(section.lines, line)
+	}
+	return section
+}
+
+// reviewEarnings lists the earnings of held and optioned tickers in the next
+// reviewAheadDays, with the short options that span them
+func (a *App) reviewEarnings() reviewSection {
+	section := reviewSection{title: fmt.Sprintf("Earnings in the next %d days", reviewAheadDays),
+		empty: "None known; dates are fetched with the holdings and options tables"}
+	end := a.today().AddDate(0, 0, reviewAheadDays)
+	type report struct {
+		ticker string
+		day    time.Time
+		line   string
+	}
+	var reports []report
+	seen := make(map[string]bool)
+	add := func(ticker string) {
+		if seen[ticker] {
+			return
+		}
+		seen[ticker] = true
+		day, estimate, ok := a.nextEarnings(ticker)
+		if !ok || !day.Before(end) {
+			return
+		}
+		line := a.book.locale.FormatDate(day) + " " + ticker
+		if estimate {
+			line += " (estimated)"
+		}
+		var spanning []string
+		for _, o := range a.book.options {
+			if o.Ticker == ticker && spansEarnings(o, day) {
+				spanning = append(spanning, fmt.Sprintf("$%s %s", a.book.formatPrice(o.Strike), o.OptionType))
+			}
+		}
+		if len(spanning) > 0 {
+			line += ": short " + strings.Join(spanning, ", ") + " open through it"
+		}
+		reports = append(reports, report{ticker, day, line})
+	}
+	for _, h := range a.book.holdings {
+		add(h.Ticker)
+	}
+	for _, o := range a.book.options {
+		if o.Status == "ACTIVE" {
+			add(o.Ticker)
+		}
+	}
+	slices.SortFunc(reports, func(x, y report) int {
+		if c := x.day.Compare(y.day); c != 0 {
+			return c
+		}
+		return strings.Compare(x.ticker, y.ticker)
+	})
+	for _, r := range reports {
+		section.lines = append(section.lines, r.line)
+	}
+	return section
+}
+
+// reviewText renders the review for the TUI
+func (a *App) reviewText(sections []reviewSection) string {
+	var sb strings.Builder
+	for _, s := range sections {
+		fmt.Fprintf(&sb, " [teal]%s[white]\n", s.title)
+		if len(s.lines) == 0 {
+			fmt.Fprintf(&sb, "   [gray]%s[white]\n", tview.Escape(s.empty))
+		}
+		for _, line := range s.lines {
+			fmt.Fprintf(&sb, "   %s\n", tview.Escape(line))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(" [yellow][[white]/[yellow]][white]:Previous/next week  [yellow]w[white]:Write Markdown  [gray]ESC to close")
+	return sb.String()
+}
+
+// reviewMarkdown renders the review as a Markdown document
+func reviewMarkdown(title string, sections []reviewSection) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n", title)
+	for _, s := range sections {
+		fmt.Fprintf(&sb, "\n## %s\n\n", s.title)
+		if len(s.lines) == 0 {
+			fmt.Fprintf(&sb, "_%s_\n", s.empty)
+		}
+		for _, line := range s.lines {
+			fmt.Fprintf(&sb, "- %s\n", line)
+		}
+	}
+	return sb.String()
+}
+
+// showReviewExportForm writes the review of the week starting from to a
+// Markdown file
+func (a *App) showReviewExportForm(view *tview.TextView, from time.Time) {
+	defaultPath := fmt.Sprintf("weekly-review-%s.md", from.Format("2006-01-02"))
+	form := tview.NewForm().
+		AddInputField("File", defaultPath, 40, nil, nil)
+	styleForm(form)
+
+	form.AddButton("Write", func() {
+		path := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		if path == "" {
+			return
+		}
+		text := reviewMarkdown(a.reviewTitle(from), a.buildWeeklyReview(context.Background(), from))
+		a.pages.RemovePage("review_export")
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			view.SetTitle(fmt.Sprintf(" [red]Write failed: %v ", err))
+			return
+		}
+		view.SetTitle(fmt.Sprintf(" %s [lime](written to %s) ", a.reviewTitle(from), path))
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("review_export")
+	})
+
+	form.SetBorder(true).SetTitle(" Write Weekly Review ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("review_export", form, 60, 7)
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/db"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// hundredPct bounds a cap's max percent
+var hundredPct = decimal.NewFromInt(100)
+
+// loadRiskCaps reads the exposure caps and ticker sectors. Without
+// schema_risk.sql both are simply empty.
+func (a *App) loadRiskCaps(ctx context.Context) {
+	caps, err := a.db.GetRiskCaps(ctx)
+	if err != nil {
+		slog.Debug("loading risk caps", "err", err)
+	}
+	sectors, err := a.db.GetTickerSectors(ctx)
+	if err != nil {
+		sectors = map[string]string{}
+	}
+	a.riskCaps = caps
+	a.sectors = sectors
+}
+
+// capsForAnalytics converts the stored caps
+func (a *App) capsForAnalytics() []analytics.RiskCap {
+	caps := make([]analytics.RiskCap, len(a.riskCaps))
+	for i, c := range a.riskCaps {
+		caps[i] = analytics.RiskCap{
+			Sector: c.Scope == db.CapScopeSector,
+			Name:   c.Name,
+			MaxPct: c.MaxPct.InexactFloat64(),
+		}
+	}
+	return caps
+}
+
+// riskBook measures current exposure: shares at the last quote (cost if
+// unquoted) plus short put collateral, against holdings value plus cash
+func (a *App) riskBook() analytics.RiskBook {
+	book := analytics.RiskBook{
+		ByTicker: make(map[string]float64),
+		Sectors:  a.sectors,
+	}
+	for _, h := range a.book.holdings {
+		price := h.AvgCost.InexactFloat64()
+		if q, ok := a.book.quotes[h.Ticker]; ok && q.Price > 0 {
+			price = q.Price
+		}
+		value := a.book.toBase(h.Quantity.Mul(decimal.NewFromFloat(price)), h.Currency).InexactFloat64()
+		book.ByTicker[h.Ticker] += value
+		book.Total += value
+	}
+	for _, o := range a.book.options {
+		// Watch-only puts are secured by cash at another broker
+		if o.Status == "ACTIVE" && o.Action == "SELL" && o.OptionType == "PUT" && !o.External {
+			book.ByTicker[o.Ticker] += a.book.toBase(o.Strike.Mul(o.Shares()), o.Currency).InexactFloat64()
+		}
+	}
+	book.Total += a.book.cash.InexactFloat64()
+	return book
+}
+
+// confirmRiskCaps runs save directly, or after confirmation if adding
+// exposure of add to ticker would breach one of its caps
+func (a *App) confirmRiskCaps(ticker string, add float64, save func()) {
+	breaches := a.riskBook().Breaches(a.capsForAnalytics(), ticker, add)
+	if len(breaches) == 0 {
+		save()
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Adding $%s of %s exposure breaches:\n\n", a.book.locale.FormatFloat(add, 0), ticker)
+	for _, b := range breaches {
+		fmt.Fprintf(&sb, "%s cap %s%%: headroom $%s\n", capLabel(b.Cap), a.book.locale.FormatFloat(b.Cap.MaxPct, 1), a.book.locale.FormatFloat(b.Headroom(), 0))
+	}
+
+	modal := tview.NewModal().
+		SetText(sb.String()).
+		AddButtons([]string{"Add anyway", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("riskcap")
+			if buttonLabel == "Add anyway" {
+				save()
+			}
+		})
+	a.pages.AddPage("riskcap", modal, true, true)
+}
+
+// capLabel names a cap, e.g. "AAPL" or "Tech sector"
+func capLabel(c analytics.RiskCap) string {
+	if c.Sector {
+		return c.Name + " sector"
+	}
+	return c.Name
+}
+
+// showRiskCapsView lists caps with current exposure and headroom
+func (a *App) showRiskCapsView() {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	view.SetBorder(true).
+		SetTitle(" Risk Caps ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'a':
+			if !a.readOnly() {
+				a.showRiskCapForm(view)
+			}
+			return nil
+		case 'd':
+			if !a.readOnly() && len(a.riskCaps) > 0 {
+				a.showDeleteRiskCapForm(view)
+			}
+			return nil
+		case 's':
+			if !a.readOnly() {
+				a.showSectorForm(view)
+			}
+			return nil
+		}
+		return event
+	})
+
+	view.SetText(a.buildRiskCapsReport())
+	a.pages.AddPage("riskcaps", view, true, true)
+}
+
+// buildRiskCapsReport renders each cap's exposure and headroom, then the
+// sector of every position
+func (a *App) buildRiskCapsReport() string {
+	book := a.riskBook()
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, " [teal]Portfolio:[white] $%s  [gray](holdings at market plus cash; exposure includes short put collateral)\n\n", a.book.locale.FormatFloat(book.Total, 2))
+
+	if len(a.riskCaps) == 0 {
+		sb.WriteString(" No caps set. Press [yellow]a[white] to add one.\n")
+	} else {
+		fmt.Fprintf(&sb, " [yellow]%-22s %8s %14s %8s %14s[white]\n", "CAP", "MAX", "EXPOSURE", "NOW", "HEADROOM")
+		for _, c := range a.capsForAnalytics() {
+			exposure := book.ByTicker[c.Name]
+			if c.Sector {
+				exposure = book.SectorExposure(c.Name)
+			}
+			check := analytics.CapCheck{Cap: c, Exposure: exposure, Limit: book.Total * c.MaxPct / 100}
+			pct := 0.0
+			if book.Total > 0 {
+				pct = exposure / book.Total * 100
+			}
+			color := "lime"
+			if check.Headroom() < 0 {
+				color = "red"
+			}
+			fmt.Fprintf(&sb, " %-22s %7s%% %14s %7s%% [%s]%14s[white]\n",
+				capLabel(c),
+				a.book.locale.FormatFloat(c.MaxPct, 1),
+				"$"+a.book.locale.FormatFloat(exposure, 0),
+				a.book.locale.FormatFloat(pct, 1),
+				color, "$"+a.book.locale.FormatFloat(check.Headroom(), 0))
+		}
+	}
+
+	tickers := make([]string, 0, len(book.ByTicker))
+	for ticker := range book.ByTicker {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+	if len(tickers) > 0 {
+		sb.WriteString("\n [teal]Sectors:[white] ")
+		for i, ticker := range tickers {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sector := a.sectors[ticker]
+			if sector == "" {
+				sector = "[gray]unset[white]"
+			}
+			fmt.Fprintf(&sb, "%s %s", ticker, sector)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n [yellow]a[white]:Add/Update Cap  [yellow]d[white]:Delete Cap  [yellow]s[white]:Set Sector  [gray]ESC to close")
+	return sb.String()
+}
+
+// showRiskCapForm adds a cap or changes the limit of an existing one
+func (a *App) showRiskCapForm(report *tview.TextView) {
+	form := tview.NewForm().
+		AddDropDown("Scope", []string{db.CapScopeTicker, db.CapScopeSector}, 0, nil).
+		AddInputField("Ticker or sector", "", 20, nil, nil).
+		AddInputField("Max % of portfolio", "", 10, nil, nil)
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		_, scope := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+		name := strings.TrimSpace(form.GetFormItem(1).(*tview.InputField).GetText())
+		if scope == db.CapScopeTicker {
+			name = strings.ToUpper(name)
+		}
+		if name == "" {
+			a.statusBar.SetText(" [red]Ticker or sector is required")
+			return
+		}
+
+		pct, err := a.book.locale.ParseNumber(form.GetFormItem(2).(*tview.InputField).GetText())
+		if err != nil || !pct.IsPositive() || pct.GreaterThan(hundredPct) {
+			a.statusBar.SetText(" [red]Max % must be between 0 and 100")
+			return
+		}
+
+		ctx := context.Background()
+		if err := a.db.SetRiskCap(ctx, scope, name, pct); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+
+		a.pages.RemovePage("riskcapform")
+		a.loadRiskCaps(ctx)
+		report.SetText(a.buildRiskCapsReport())
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("riskcapform")
+	})
+
+	form.SetBorder(true).SetTitle(" Set Risk Cap ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("riskcapform", form, 50, 11)
+}
+
+// showDeleteRiskCapForm removes one cap
+func (a *App) showDeleteRiskCapForm(report *tview.TextView) {
+	labels := make([]string, len(a.riskCaps))
+	for i, c := range a.capsForAnalytics() {
+		labels[i] = capLabel(c)
+	}
+	caps := a.riskCaps
+
+	form := tview.NewForm().
+		AddDropDown("Cap", labels, 0, nil)
+
+	styleForm(form)
+
+	form.AddButton("Delete", func() {
+		i, _ := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+
+		ctx := context.Background()
+		if err := a.db.DeleteRiskCap(ctx, caps[i].ID); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+
+		a.pages.RemovePage("riskcapdelete")
+		a.loadRiskCaps(ctx)
+		report.SetText(a.buildRiskCapsReport())
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("riskcapdelete")
+	})
+
+	form.SetBorder(true).SetTitle(" Delete Risk Cap ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("riskcapdelete", form, 50, 7)
+}
+
+// showSectorForm assigns a ticker to a sector; an empty sector clears it
+func (a *App) showSectorForm(report *tview.TextView) {
+	form := tview.NewForm().
+		AddInputField("Ticker", "", 10, nil, nil).
+		AddInputField("Sector (empty to clear)", "", 20, nil, nil)
+	a.tickerAutocomplete(form.GetFormItem(0).(*tview.InputField))
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		ticker := strings.ToUpper(strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText()))
+		sector := strings.TrimSpace(form.GetFormItem(1).(*tview.InputField).GetText())
+		if ticker == "" {
+			a.statusBar.SetText(" [red]Ticker is required")
+			return
+		}
+
+		ctx := context.Background()
+		if err := a.db.SetTickerSector(ctx, ticker, sector); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+
+		a.pages.RemovePage("sector")
+		a.loadRiskCaps(ctx)
+		report.SetText(a.buildRiskCapsReport())
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("sector")
+	})
+
+	form.SetBorder(true).SetTitle(" Set Sector ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("sector", form, 50, 9)
+}
@@ -1,5 +1,13 @@
 -- Run this in your Supabase SQL Editor to create the holdings table
 
+-- Brokerage accounts besides the main one, e.g. an IRA. Holdings and options
+-- with a NULL account_id belong to the main account.
+CREATE TABLE IF NOT EXISTS accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(40) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
 CREATE TABLE IF NOT EXISTS holdings (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     ticker VARCHAR(10) NOT NULL,
@@ -7,11 +15,25 @@ CREATE TABLE IF NOT EXISTS holdings (
     avg_cost DECIMAL(18, 4) NOT NULL,
     entry_date DATE NOT NULL DEFAULT CURRENT_DATE,
     target_price DECIMAL(18, 4),
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',  -- Listing currency of the ticker
     notes TEXT,
+    archived BOOLEAN NOT NULL DEFAULT FALSE,  -- Hidden from the holdings table unless shown
+    account_id UUID REFERENCES accounts(id),
     created_at TIMESTAMPTZ DEFAULT NOW(),
     updated_at TIMESTAMPTZ DEFAULT NOW()
 );
 
+-- Migration: Add archived holdings
+-- ALTER TABLE holdings ADD COLUMN IF NOT EXISTS archived BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Migration: Add accounts (run the accounts table above first)
+-- ALTER TABLE holdings ADD COLUMN IF NOT EXISTS account_id UUID REFERENCES accounts(id);
+-- ALTER TABLE options ADD COLUMN IF NOT EXISTS account_id UUID REFERENCES accounts(id);
+
+-- Migration: Add currencies
+-- ALTER TABLE holdings ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+-- ALTER TABLE options ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+
 -- Migration: Add target_price column if it doesn't exist
 -- Run this if you already have the table:
 -- ALTER TABLE holdings ADD COLUMN IF NOT EXISTS target_price DECIMAL(18, 4);
@@ -34,16 +56,70 @@ CREATE TRIGGER update_holdings_updated_at
     FOR EACH ROW
     EXECUTE FUNCTION update_updated_at_column();
 
--- Settings table for portfolio-level settings like available cash
+-- Settings table for portfolio-level settings like the risk-free rate
 CREATE TABLE IF NOT EXISTS settings (
     key VARCHAR(50) PRIMARY KEY,
     value TEXT NOT NULL,
     updated_at TIMESTAMPTZ DEFAULT NOW()
 );
 
--- Initialize available cash to 0
-INSERT INTO settings (key, value) VALUES ('available_cash', '0')
-ON CONFLICT (key) DO NOTHING;
+-- Cash ledger: every change to an account's cash (trades, premiums, fees,
+-- assignments, interest, deposits, manual edits) with the balance it left,
+-- and stock splits with the shares they added.
+-- An account's available cash is the sum of its entries.
+CREATE TABLE IF NOT EXISTS transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    seq BIGSERIAL,  -- Order of entries made in the same instant
+    kind VARCHAR(12) NOT NULL CHECK (kind IN ('BUY', 'SELL', 'PREMIUM', 'CLOSE', 'ASSIGNMENT', 'SETTLEMENT', 'FEE', 'INTEREST', 'DIVIDEND', 'CONTRIBUTION', 'ADJUSTMENT', 'SPLIT')),
+    ticker VARCHAR(10),
+    quantity DECIMAL(18, 8) NOT NULL DEFAULT 0,
+    amount DECIMAL(18, 4) NOT NULL,
+    balance DECIMAL(18, 4) NOT NULL,
+    notes TEXT,
+    account_id UUID REFERENCES accounts(id),
+    option_id UUID,  -- The option an assignment came from
+    split_date DATE,  -- The split a SPLIT entry applied
+    split_ratio DECIMAL(18, 8),  -- New shares per old, for SPLIT entries
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_created ON transactions(created_at);
+
+-- Migration: Derive cash from the ledger
+-- Cash used to be kept in settings (available_cash for the main account,
+-- cash:<account id> for others). This carries each balance into the ledger
+-- as an opening adjustment before its first entry, so the ledger sums to it;
+-- the settings keys are unused afterwards.
+-- INSERT INTO transactions (kind, amount, balance, notes, account_id, created_at)
+-- SELECT 'ADJUSTMENT', c.cash - c.ledger, c.cash - c.ledger, 'Opening balance', c.account_id, COALESCE(c.first_at, NOW()) - INTERVAL '1 second'
+-- FROM (
+--     SELECT s.value::numeric AS cash, acct.id AS account_id,
+--            (SELECT COALESCE(SUM(t.amount), 0) FROM transactions t WHERE t.account_id IS NOT DISTINCT FROM acct.id) AS ledger,
+--            (SELECT MIN(t.created_at) FROM transactions t WHERE t.account_id IS NOT DISTINCT FROM acct.id) AS first_at
+--     FROM settings s
+--     LEFT JOIN accounts acct ON s.key = 'cash:' || acct.id::text
+--     WHERE s.key = 'available_cash' OR acct.id IS NOT NULL
+-- ) c
+-- WHERE c.cash <> c.ledger;
+
+-- Migration: Link assignments to their option
+-- ALTER TABLE transactions ADD COLUMN IF NOT EXISTS option_id UUID;
+
+-- Migration: Add the cash ledger
+-- Run the CREATE TABLE transactions statement above, then the opening
+-- balance migration under "Derive cash from the ledger".
+
+-- Migration: Record dividends in the ledger
+-- ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_kind_check;
+-- ALTER TABLE transactions ADD CONSTRAINT transactions_kind_check CHECK (kind IN ('BUY', 'SELL', 'PREMIUM', 'CLOSE', 'ASSIGNMENT', 'SETTLEMENT', 'FEE', 'INTEREST', 'DIVIDEND', 'CONTRIBUTION', 'ADJUSTMENT'));
+
+-- Migration: Record stock splits in the ledger
+-- ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_kind_check;
+-- ALTER TABLE transactions ADD CONSTRAINT transactions_kind_check CHECK (kind IN ('BUY', 'SELL', 'PREMIUM', 'CLOSE', 'ASSIGNMENT', 'SETTLEMENT', 'FEE', 'INTEREST', 'DIVIDEND', 'CONTRIBUTION', 'ADJUSTMENT', 'SPLIT'));
+
+-- Migration: Identify applied splits by date and ratio
+-- ALTER TABLE transactions ADD COLUMN IF NOT EXISTS split_date DATE;
+-- ALTER TABLE transactions ADD COLUMN IF NOT EXISTS split_ratio DECIMAL(18, 8);
 
 -- Options table for tracking option contracts
 CREATE TABLE IF NOT EXISTS options (
@@ -54,16 +130,30 @@ CREATE TABLE IF NOT EXISTS options (
     strike DECIMAL(18, 2) NOT NULL,
     expiry_date DATE NOT NULL,
     quantity INTEGER NOT NULL,
+    multiplier INTEGER NOT NULL DEFAULT 100 CHECK (multiplier > 0),  -- Shares per contract
+    settlement VARCHAR(8) NOT NULL DEFAULT 'PHYSICAL' CHECK (settlement IN ('PHYSICAL', 'CASH')),
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',  -- Currency of the strike and premiums
     premium DECIMAL(18, 4) NOT NULL,
     open_fee DECIMAL(18, 4) DEFAULT 0,
     close_premium DECIMAL(18, 4),
     close_fee DECIMAL(18, 4),
     status VARCHAR(10) NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'EXPIRED', 'ASSIGNED', 'CLOSED')),
     notes TEXT,
+    external BOOLEAN NOT NULL DEFAULT FALSE,  -- Watch-only, held at another broker
+    account_id UUID REFERENCES accounts(id),
     created_at TIMESTAMPTZ DEFAULT NOW(),
     updated_at TIMESTAMPTZ DEFAULT NOW()
 );
 
+-- Migration: Add settlement type
+-- ALTER TABLE options ADD COLUMN IF NOT EXISTS settlement VARCHAR(8) NOT NULL DEFAULT 'PHYSICAL' CHECK (settlement IN ('PHYSICAL', 'CASH'));
+
+-- Migration: Add contract multiplier
+-- ALTER TABLE options ADD COLUMN IF NOT EXISTS multiplier INTEGER NOT NULL DEFAULT 100 CHECK (multiplier > 0);
+
+-- Migration: Add watch-only flag
+-- ALTER TABLE options ADD COLUMN IF NOT EXISTS external BOOLEAN NOT NULL DEFAULT FALSE;
+
 -- Migration: Add fee columns
 -- ALTER TABLE options ADD COLUMN IF NOT EXISTS open_fee DECIMAL(18, 4) DEFAULT 0;
 -- ALTER TABLE options ADD COLUMN IF NOT EXISTS close_premium DECIMAL(18, 4);
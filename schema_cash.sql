@@ -0,0 +1,20 @@
+-- Cash tracking tables (idle cash history + interest received)
+-- Run this in your Supabase SQL Editor
+
+-- One row per day with the cash balance, across accounts, seen at the last refresh
+CREATE TABLE IF NOT EXISTS cash_snapshots (
+    snapshot_date DATE PRIMARY KEY DEFAULT CURRENT_DATE,
+    amount DECIMAL(18, 4) NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Interest credited on idle cash (sweep, money market, etc.)
+CREATE TABLE IF NOT EXISTS interest_payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    amount DECIMAL(18, 4) NOT NULL,
+    received_on DATE NOT NULL DEFAULT CURRENT_DATE,
+    notes TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_interest_payments_received_on ON interest_payments(received_on);
@@ -5,10 +5,14 @@ CREATE TABLE IF NOT EXISTS csp_watchlist (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     ticker VARCHAR(10) NOT NULL UNIQUE,
     notes TEXT,
+    expiries VARCHAR(8) NOT NULL DEFAULT 'ALL' CHECK (expiries IN ('ALL', 'WEEKLY', 'MONTHLY')),  -- Expirations the advisor targets
     created_at TIMESTAMPTZ DEFAULT NOW(),
     updated_at TIMESTAMPTZ DEFAULT NOW()
 );
 
+-- Migration: Per-ticker expiry cycle (weeklies or monthlies only)
+-- ALTER TABLE csp_watchlist ADD COLUMN IF NOT EXISTS expiries VARCHAR(8) NOT NULL DEFAULT 'ALL' CHECK (expiries IN ('ALL', 'WEEKLY', 'MONTHLY'));
+
 -- Index for faster ticker lookups
 CREATE INDEX IF NOT EXISTS idx_csp_watchlist_ticker ON csp_watchlist(ticker);
 
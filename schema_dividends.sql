@@ -0,0 +1,14 @@
+-- Dividends received on holdings
+-- Run this in your Supabase SQL Editor
+
+CREATE TABLE IF NOT EXISTS dividends (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ticker VARCHAR(10) NOT NULL,
+    amount DECIMAL(18, 4) NOT NULL,
+    paid_on DATE NOT NULL DEFAULT CURRENT_DATE,
+    notes TEXT,
+    account_id UUID REFERENCES accounts(id),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dividends_paid_on ON dividends(paid_on);
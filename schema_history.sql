@@ -0,0 +1,13 @@
+-- Daily price history, kept by `prices sync` (or the daemon) so charts, RSI,
+-- and beta read it instead of fetching a year of history on every refresh
+-- Run this in your Supabase SQL Editor
+
+CREATE TABLE IF NOT EXISTS price_history (
+    ticker VARCHAR(10) NOT NULL,
+    day DATE NOT NULL,
+    open DECIMAL(12, 4) NOT NULL,
+    high DECIMAL(12, 4) NOT NULL,
+    low DECIMAL(12, 4) NOT NULL,
+    close DECIMAL(12, 4) NOT NULL,
+    PRIMARY KEY (ticker, day)
+);
@@ -0,0 +1,23 @@
+-- Trade ideas: advisor suggestions accepted but not yet filled at the broker
+-- Run this in your Supabase SQL Editor
+
+CREATE TABLE IF NOT EXISTS trade_ideas (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ticker VARCHAR(10) NOT NULL,
+    option_type VARCHAR(4) NOT NULL CHECK (option_type IN ('CALL', 'PUT')),
+    strike DECIMAL(18, 4) NOT NULL,
+    expiry_date DATE NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+    premium DECIMAL(18, 4) NOT NULL,  -- Intended premium per share
+    notes TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Trigger to auto-update updated_at
+-- (uses update_updated_at_column function from schema.sql)
+DROP TRIGGER IF EXISTS update_trade_ideas_updated_at ON trade_ideas;
+CREATE TRIGGER update_trade_ideas_updated_at
+    BEFORE UPDATE ON trade_ideas
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,15 @@
+-- Daily open interest of tracked option contracts (CSP advisor targets and
+-- open short positions), for the day-over-day change
+-- Run this in your Supabase SQL Editor
+
+CREATE TABLE IF NOT EXISTS option_open_interest (
+    ticker VARCHAR(10) NOT NULL,
+    option_type VARCHAR(4) NOT NULL CHECK (option_type IN ('CALL', 'PUT')),
+    expiry DATE NOT NULL,
+    strike DECIMAL(18, 2) NOT NULL,
+    day DATE NOT NULL,
+    open_interest INTEGER NOT NULL,
+    PRIMARY KEY (ticker, option_type, expiry, strike, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_option_open_interest_day ON option_open_interest(day);
@@ -0,0 +1,25 @@
+-- Performance tracking tables
+-- Run this in your Supabase SQL Editor
+
+-- One row per day with portfolio value, across accounts, seen at the last refresh
+CREATE TABLE IF NOT EXISTS portfolio_snapshots (
+    snapshot_date DATE PRIMARY KEY DEFAULT CURRENT_DATE,
+    holdings_value DECIMAL(18, 4) NOT NULL,
+    cash DECIMAL(18, 4) NOT NULL,
+    options_credit DECIMAL(18, 4) NOT NULL DEFAULT 0,  -- Net premium held on open options
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Migration: Add the options credit
+-- ALTER TABLE portfolio_snapshots ADD COLUMN IF NOT EXISTS options_credit DECIMAL(18, 4) NOT NULL DEFAULT 0;
+
+-- External money added to (positive) or withdrawn from (negative) any account
+CREATE TABLE IF NOT EXISTS contributions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    amount DECIMAL(18, 4) NOT NULL,
+    contributed_on DATE NOT NULL DEFAULT CURRENT_DATE,
+    notes TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_contributions_contributed_on ON contributions(contributed_on);
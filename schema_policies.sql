@@ -0,0 +1,28 @@
+-- Per-position option policies and the actions they queue for confirmation
+-- Run this in your Supabase SQL Editor
+
+CREATE TABLE IF NOT EXISTS option_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    option_id UUID NOT NULL REFERENCES options(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('ROLL_AT_DTE', 'CLOSE_AT_PROFIT')),
+    threshold DECIMAL(8, 2) NOT NULL CHECK (threshold >= 0),  -- Days or percent
+    itm_only BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Suggestions from policy evaluation, waiting for the owner to confirm or dismiss
+CREATE TABLE IF NOT EXISTS policy_actions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    policy_id UUID NOT NULL REFERENCES option_policies(id) ON DELETE CASCADE,
+    option_id UUID NOT NULL REFERENCES options(id) ON DELETE CASCADE,
+    action VARCHAR(5) NOT NULL CHECK (action IN ('CLOSE', 'ROLL')),
+    reason TEXT NOT NULL,
+    price DECIMAL(18, 4),  -- Option mark when evaluated
+    roll_expiry DATE,      -- Suggested expiry to roll to
+    status VARCHAR(10) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'DONE', 'DISMISSED')),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- At most one pending action per policy
+CREATE UNIQUE INDEX IF NOT EXISTS idx_policy_actions_pending
+    ON policy_actions(policy_id) WHERE status = 'PENDING';
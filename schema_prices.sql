@@ -0,0 +1,18 @@
+-- Manual prices and stale symbols, for symbols Yahoo has no data for
+-- Run this in your Supabase SQL Editor
+
+CREATE TABLE IF NOT EXISTS manual_prices (
+    ticker VARCHAR(10) PRIMARY KEY,
+    price DECIMAL(12, 4) NOT NULL CHECK (price > 0),
+    updated_at TIMESTAMPTZ DEFAULT NOW()  -- When the price was last set, shown as its age
+);
+
+-- Symbols Yahoo keeps returning no data for (delisted or halted). After
+-- repeated failures the app stops refreshing them until retried by hand.
+CREATE TABLE IF NOT EXISTS stale_symbols (
+    ticker VARCHAR(10) PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 1,
+    last_price DECIMAL(12, 4),    -- Last price seen before the failures began
+    last_price_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
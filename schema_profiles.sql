@@ -0,0 +1,9 @@
+-- Sector and industry of each ticker, cached from Yahoo for the sector allocation view
+-- Run this in your Supabase SQL Editor
+
+CREATE TABLE IF NOT EXISTS ticker_profiles (
+    ticker VARCHAR(10) PRIMARY KEY,
+    sector VARCHAR(50) NOT NULL DEFAULT '',    -- Empty if Yahoo has none
+    industry VARCHAR(100) NOT NULL DEFAULT '',
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
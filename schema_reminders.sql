@@ -0,0 +1,14 @@
+-- Dated reminders attached to option positions
+-- Run this in your Supabase SQL Editor
+
+CREATE TABLE IF NOT EXISTS option_reminders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    option_id UUID NOT NULL REFERENCES options(id) ON DELETE CASCADE,
+    remind_on DATE NOT NULL,
+    note TEXT NOT NULL,
+    dismissed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_option_reminders_open
+    ON option_reminders(remind_on) WHERE NOT dismissed;
@@ -0,0 +1,27 @@
+-- Exposure caps (percent of portfolio) and the sectors they group tickers by
+-- Run this in your Supabase SQL Editor
+
+CREATE TABLE IF NOT EXISTS risk_caps (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scope VARCHAR(10) NOT NULL CHECK (scope IN ('TICKER', 'SECTOR')),
+    name VARCHAR(50) NOT NULL,  -- Ticker symbol or sector name
+    max_pct DECIMAL(6, 2) NOT NULL CHECK (max_pct > 0 AND max_pct <= 100),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (scope, name)
+);
+
+-- Sector of each ticker, set by hand in the Risk Caps view
+CREATE TABLE IF NOT EXISTS ticker_sectors (
+    ticker VARCHAR(10) PRIMARY KEY,
+    sector VARCHAR(50) NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Trigger to auto-update updated_at
+-- (uses update_updated_at_column function from schema.sql)
+DROP TRIGGER IF EXISTS update_risk_caps_updated_at ON risk_caps;
+CREATE TRIGGER update_risk_caps_updated_at
+    BEFORE UPDATE ON risk_caps
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
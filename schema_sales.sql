@@ -0,0 +1,17 @@
+-- Shares sold from holdings, with the cost they were held at
+-- Run this in your Supabase SQL Editor
+
+CREATE TABLE IF NOT EXISTS stock_sales (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ticker VARCHAR(10) NOT NULL,
+    quantity DECIMAL(18, 8) NOT NULL CHECK (quantity > 0),
+    price DECIMAL(18, 4) NOT NULL,     -- Sale price per share
+    avg_cost DECIMAL(18, 4) NOT NULL,  -- Holding's average cost at the sale
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    sold_on DATE NOT NULL DEFAULT CURRENT_DATE,
+    notes TEXT,
+    account_id UUID REFERENCES accounts(id),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_sales_sold_on ON stock_sales(sold_on);
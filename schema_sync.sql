@@ -0,0 +1,33 @@
+-- Live sync between running instances
+-- Run this in your Supabase SQL Editor (optional; without it instances poll)
+
+-- Raise a notification on every change to holdings, options, or cash so other
+-- TUIs can reload. The payload is the table name.
+CREATE OR REPLACE FUNCTION notify_anyhowhodl_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('anyhowhodl_changes', TG_TABLE_NAME);
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS notify_holdings_change ON holdings;
+CREATE TRIGGER notify_holdings_change
+    AFTER INSERT OR UPDATE OR DELETE ON holdings
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION notify_anyhowhodl_change();
+
+DROP TRIGGER IF EXISTS notify_options_change ON options;
+CREATE TRIGGER notify_options_change
+    AFTER INSERT OR UPDATE OR DELETE ON options
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION notify_anyhowhodl_change();
+
+-- Cash is the sum of the ledger, so new entries are cash changes. Databases
+-- set up before cash moved to the ledger also drop the old settings trigger.
+DROP TRIGGER IF EXISTS notify_cash_change ON settings;
+DROP TRIGGER IF EXISTS notify_cash_change ON transactions;
+CREATE TRIGGER notify_cash_change
+    AFTER INSERT ON transactions
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION notify_anyhowhodl_change();
@@ -0,0 +1,13 @@
+-- Tags: user-defined labels on holdings and options, such as wheel or long-term
+-- Run this in your Supabase SQL Editor
+
+CREATE TABLE IF NOT EXISTS item_tags (
+    item_id UUID NOT NULL,  -- The holding or option tagged
+    tag VARCHAR(40) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (item_id, tag)
+);
+
+-- Tags are deleted with their holding or option. To clear tags left behind
+-- by items deleted before that:
+-- DELETE FROM item_tags WHERE item_id NOT IN (SELECT id FROM holdings UNION ALL SELECT id FROM options);
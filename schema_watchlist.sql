@@ -0,0 +1,19 @@
+-- Stock watchlist: tickers not yet held, with the price to buy at
+-- Run this in your Supabase SQL Editor
+
+CREATE TABLE IF NOT EXISTS watchlist (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ticker VARCHAR(10) NOT NULL UNIQUE,
+    target_price DECIMAL(18, 4),  -- Price to buy at, NULL for none
+    notes TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Trigger to auto-update updated_at
+-- (uses update_updated_at_column function from schema.sql)
+DROP TRIGGER IF EXISTS update_watchlist_updated_at ON watchlist;
+CREATE TRIGGER update_watchlist_updated_at
+    BEFORE UPDATE ON watchlist
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,10 @@
+-- Fills recorded from the trade webhook
+-- Run this in your Supabase SQL Editor
+
+-- The broker's fill ID of each recorded fill, so a retried POST is not
+-- booked twice
+CREATE TABLE IF NOT EXISTS webhook_fills (
+    fill_id TEXT PRIMARY KEY,
+    account_id UUID REFERENCES accounts(id),
+    recorded_at TIMESTAMPTZ DEFAULT NOW()
+);
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/db"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// profileMaxAge is how long a cached sector and industry is used before it
+// is fetched again; classifications rarely change
+const profileMaxAge = 90 * 24 * time.Hour
+
+// unclassified groups holdings without a known sector or industry
+const unclassified = "Unclassified"
+
+// sectorAllocation is a sector's share of the holdings value
+type sectorAllocation struct {
+	Name       string
+	Value      decimal.Decimal
+	Weight     decimal.Decimal
+	Tickers    []string
+	Industries []sectorAllocation // Within the sector, largest first
+}
+
+// showSectorView opens the sector allocation and loads profiles in the background
+func (a *App) showSectorView() {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	view.SetBorder(true).
+		SetTitle(" Sector Allocation ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+	view.SetText(" [yellow]Loading sectors...")
+
+	a.pages.AddPage("sectors", view, true, true)
+
+	// Valued now, on the UI goroutine, as the holdings table values them
+	values, total, _ := a.book.positionValues()
+	holdings := a.book.holdings
+	manual := a.sectors
+	a.goSafe("sector allocation", func() {
+		profiles := a.tickerProfiles(context.Background(), holdings)
+		text := a.sectorReport(sectorAllocations(holdings, values, total, profiles, manual))
+		a.queueUpdateDraw(func() {
+			view.SetText(text)
+		})
+	})
+}
+
+// tickerProfiles returns the sector and industry of each holding, from the
+// cache in the database when fresh and from Yahoo otherwise, saving what it
+// fetches. A ticker that cannot be fetched keeps its stale profile, if any.
+func (a *App) tickerProfiles(ctx context.Context, holdings []db.Holding) map[string]db.TickerProfile {
+	profiles, err := a.db.GetTickerProfiles(ctx)
+	if err != nil {
+		slog.Debug("loading ticker profiles", "err", err)
+		profiles = make(map[string]db.TickerProfile)
+	}
+	now := a.now()
+	for _, h := range holdings {
+		if p, ok := profiles[h.Ticker]; ok && now.Sub(p.FetchedAt) < profileMaxAge {
+			continue
+		}
+		fetched, err := a.yahoo.FetchProfile(h.Ticker)
+		if err != nil {
+			slog.Warn("fetching ticker profile", "ticker", h.Ticker, "err", err)
+			continue
+		}
+		p := db.TickerProfile{Ticker: h.Ticker, Sector: fetched.Sector, Industry: fetched.Industry, FetchedAt: now}
+		profiles[h.Ticker] = p
+		if err := a.db.SaveTickerProfile(ctx, p); err != nil {
+			slog.Debug("caching ticker profile", "ticker", h.Ticker, "err", err)
+		}
+	}
+	return profiles
+}
+
+// sectorAllocations groups the holdings' values by sector and industry,
+// largest first. A sector assigned by hand for risk caps takes precedence
+// over Yahoo's; holdings with neither are unclassified.
+func sectorAllocations(holdings []db.Holding, values []decimal.Decimal, total decimal.Decimal, profiles map[string]db.TickerProfile, manual map[string]string) []sectorAllocation {
+	hundred := decimal.NewFromInt(100)
+	weigh := func(value decimal.Decimal) decimal.Decimal {
+		if total.IsZero() {
+			return decimal.Zero
+		}
+		return value.Div(total).Mul(hundred)
+	}
+
+	type group struct {
+		sectorAllocation
+		industries map[string]*sectorAllocation
+	}
+	sectors := make(map[string]*group)
+	for i, h := range holdings {
+		p := profiles[h.Ticker]
+		sector, industry := p.Sector, p.Industry
+		if s, ok := manual[h.Ticker]; ok {
+			sector = s
+		}
+		if sector == "" {
+			sector = unclassified
+		}
+		if industry == "" {
+			industry = unclassified
+		}
+
+		g, ok := sectors[sector]
+		if !ok {
+			g = &group{sectorAllocation: sectorAllocation{Name: sector}, industries: make(map[string]*sectorAllocation)}
+			sectors[sector] = g
+		}
+		g.Value = g.Value.Add(values[i])
+		g.Tickers = append(g.Tickers, h.Ticker)
+		ind, ok := g.industries[industry]
+		if !ok {
+			ind = &sectorAllocation{Name: industry}
+			g.industries[industry] = ind
+		}
+		ind.Value = ind.Value.Add(values[i])
+		ind.Tickers = append(ind.Tickers, h.Ticker)
+	}
+
+	byValue := func(s []sectorAllocation) {
+		sort.Slice(s, func(i, j int) bool {
+			if !s[i].Value.Equal(s[j].Value) {
+				return s[i].Value.GreaterThan(s[j].Value)
+			}
+			return s[i].Name < s[j].Name
+		})
+	}
+	var out []sectorAllocation
+	for _, g := range sectors {
+		s := g.sectorAllocation
+		s.Weight = weigh(s.Value)
+		for _, ind := range g.industries {
+			ind.Weight = weigh(ind.Value)
+			s.Industries = append(s.Industries, *ind)
+		}
+		byValue(s.Industries)
+		out = append(out, s)
+	}
+	byValue(out)
+	return out
+}
+
+// sectorReport lists each sector's weight with its industries beneath,
+// colored and warned about like concentrated holdings
+func (a *App) sectorReport(sectors []sectorAllocation) string {
+	if len(sectors) == 0 {
+		return " [gray]No holdings"
+	}
+	color := func(weight decimal.Decimal) string {
+		switch {
+		case weight.GreaterThan(overConcentratedPct):
+			return "red"
+		case weight.GreaterThan(concentratedPct):
+			return "orange"
+		}
+		return "white"
+	}
+	money := func(d decimal.Decimal) string {
+		return a.book.baseSymbol() + a.book.locale.FormatFixed(d, 2)
+	}
+	bar := func(weight decimal.Decimal) string {
+		return strings.Repeat("█", int(weight.Div(decimal.NewFromInt(2)).Round(0).IntPart()))
+	}
+
+	var sb strings.Builder
+	var warnings []string
+	fmt.Fprintf(&sb, " [teal]%-34s %14s %8s[white]\n", "SECTOR / INDUSTRY", "VALUE", "WEIGHT")
+	for _, s := range sectors {
+		c := color(s.Weight)
+		fmt.Fprintf(&sb, " [%s]%-34s %14s %7s%%  %s[white]\n", c, s.Name, money(s.Value), a.book.locale.FormatFixed(s.Weight, 1), bar(s.Weight))
+		for _, ind := range s.Industries {
+			fmt.Fprintf(&sb, "   [gray]%-32s %14s %7s%%  %s[white]\n", ind.Name, money(ind.Value), a.book.locale.FormatFixed(ind.Weight, 1), strings.Join(ind.Tickers, " "))
+		}
+		if c != "white" && s.Name != unclassified {
+			limit := concentratedPct
+			if c == "red" {
+				limit = overConcentratedPct
+			}
+			warnings = append(warnings, fmt.Sprintf(" [%s]! %s is %s%% of holdings, over %s%%[white]", c, s.Name, a.book.locale.FormatFixed(s.Weight, 1), limit.String()))
+		}
+	}
+	if len(warnings) > 0 {
+		sb.WriteString("\n" + strings.Join(warnings, "\n") + "\n")
+	}
+	sb.WriteString("\n [gray]Weights of holdings value; sectors from Yahoo unless assigned in Risk caps (K)")
+	return sb.String()
+}
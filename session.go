@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"anyhowhodl/internal/db"
+)
+
+// shutdownTimeout bounds how long quitting waits for background tasks
+const shutdownTimeout = 3 * time.Second
+
+// sessionState is the UI state saved on quit and restored on the next launch
+type sessionState struct {
+	Page           string   `json:"page"` // "portfolio" or "csp"
+	Focus          int      `json:"focus"`
+	HoldingsRow    int      `json:"holdings_row"`
+	HoldingsOffset int      `json:"holdings_offset"`
+	OptionsRow     int      `json:"options_row"`
+	OptionsOffset  int      `json:"options_offset"`
+	WeeklyView     bool     `json:"weekly_view"`
+	AutoRefresh    bool     `json:"auto_refresh"`
+	ShowExpired    bool     `json:"show_expired"`
+	HiddenStatuses []string `json:"hidden_statuses,omitempty"` // Statuses other than EXPIRED toggled off
+	HideBanner     bool     `json:"hide_banner,omitempty"`
+	IncomeLine     bool     `json:"income_line,omitempty"`
+	ShowTags       bool     `json:"show_tags,omitempty"`
+	ShowArchived   bool     `json:"show_archived,omitempty"`
+	Account        string   `json:"account,omitempty"` // Selected account, "" for the main one
+}
+
+// captureSession reads the current page, selections, and view toggles
+func (a *App) captureSession() sessionState {
+	s := sessionState{
+		Page:         "portfolio",
+		Focus:        a.focusIndex,
+		WeeklyView:   a.optionsView.weeklyView,
+		AutoRefresh:  a.autoRefresh,
+		ShowExpired:  a.optionsView.showExpired,
+		HideBanner:   a.hideBanner,
+		IncomeLine:   a.summaryView.showIncome,
+		ShowTags:     a.book.showTags,
+		ShowArchived: a.holdingsView.showArchived,
+		Account:      a.book.account,
+	}
+	if a.showCSP {
+		s.Page = "csp"
+	}
+	for _, status := range optionStatuses {
+		if a.optionsView.hiddenStatuses[status] {
+			s.HiddenStatuses = append(s.HiddenStatuses, status)
+		}
+	}
+	s.HoldingsRow, _ = a.holdingsView.table.GetSelection()
+	s.HoldingsOffset, _ = a.holdingsView.table.GetOffset()
+	s.OptionsRow, _ = a.optionsView.table.GetSelection()
+	s.OptionsOffset, _ = a.optionsView.table.GetOffset()
+	return s
+}
+
+// loadSession reads the saved session and applies its view toggles, which must
+// be set before the first refresh renders. Returns nil if nothing was saved.
+func (a *App) loadSession(ctx context.Context) *sessionState {
+	raw, err := a.db.GetUIState(ctx)
+	if err != nil {
+		slog.Warn("loading session state", "err", err)
+		return nil
+	}
+	if raw == "" {
+		return nil
+	}
+	var s sessionState
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		slog.Warn("ignoring unreadable session state", "err", err)
+		return nil
+	}
+	a.optionsView.weeklyView = s.WeeklyView
+	a.autoRefresh = s.AutoRefresh
+	a.optionsView.showExpired = s.ShowExpired
+	a.hideBanner = s.HideBanner
+	a.summaryView.showIncome = s.IncomeLine
+	a.book.showTags = s.ShowTags
+	a.holdingsView.showArchived = s.ShowArchived
+	if s.Account != "" {
+		a.setAccount(s.Account)
+	}
+	for _, status := range s.HiddenStatuses {
+		a.optionsView.hiddenStatuses[status] = true
+	}
+	return &s
+}
+
+// restoreSession reapplies selections, scroll positions, focus, and page once
+// the tables have been filled. Rows past the end of a table are clamped.
+func (a *App) restoreSession(s *sessionState) {
+	if s == nil {
+		return
+	}
+	restoreTable := func(row, offset, n int, selectRow func(int), setOffset func(int)) {
+		if row < 1 || n == 0 {
+			return
+		}
+		if row > n {
+			row = n
+		}
+		selectRow(row)
+		if offset >= 0 && offset < row {
+			setOffset(offset)
+		}
+	}
+	restoreTable(s.HoldingsRow, s.HoldingsOffset, len(a.book.holdings),
+		func(r int) { a.holdingsView.table.Select(r, 0) }, func(o int) { a.holdingsView.table.SetOffset(o, 0) })
+	restoreTable(s.OptionsRow, s.OptionsOffset, a.optionsView.table.GetRowCount()-1,
+		func(r int) { a.optionsView.table.Select(r, 0) }, func(o int) { a.optionsView.table.SetOffset(o, 0) })
+
+	a.updateStatusBar()
+	if s.Page == "csp" {
+		a.setCSPView(true)
+	} else if s.Focus == 1 {
+		a.setFocusIndex(1)
+	}
+}
+
+// saveSession persists the current session state to settings
+func (a *App) saveSession(ctx context.Context) {
+	data, err := json.Marshal(a.captureSession())
+	if err != nil {
+		return
+	}
+	if err := a.db.SetUIState(ctx, string(data)); err != nil {
+		slog.Warn("saving session state", "err", err)
+	}
+}
+
+// shutdown runs after the event loop exits: it stops the auto-refresh loop,
+// waits for background tasks to finish, and saves the session state. Database
+// writes from forms run on the event loop, so none are in flight by now.
+func (a *App) shutdown() {
+	a.tasksMu.Lock()
+	a.stopping.Store(true)
+	a.tasksMu.Unlock()
+	close(a.stopAutoRefresh)
+
+	done := make(chan struct{})
+	go func() {
+		a.tasks.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(shutdownTimeout):
+		slog.Warn("shutdown: background tasks still running, exiting anyway")
+	}
+
+	// Viewers cannot write settings, so their session is not resumed
+	if a.role != db.RoleViewer {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		a.saveSession(ctx)
+	}
+	slog.Info("shutdown complete")
+}
+
+// queueUpdateDraw is QueueUpdateDraw for background tasks. Once the TUI is
+// shutting down the update is dropped, since nothing would run it and the
+// task would block forever.
+func (a *App) queueUpdateDraw(f func()) {
+	if a.stopping.Load() {
+		return
+	}
+	a.app.QueueUpdateDraw(f)
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/analytics"
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/locale"
+	"anyhowhodl/internal/notify"
+	"anyhowhodl/internal/yahoo"
+
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// loadLocale returns the display locale chosen in settings, or the default if
+// none is set or it cannot be read
+func loadLocale(ctx context.Context, store db.Store) locale.Locale {
+	name, err := store.GetLocale(ctx)
+	if err != nil {
+		slog.Warn("loading locale", "err", err)
+		return locale.Default
+	}
+	if name == "" {
+		return locale.Default
+	}
+	l, ok := locale.Lookup(name)
+	if !ok {
+		slog.Warn("unknown locale in settings, using default", "locale", name)
+		return locale.Default
+	}
+	return l
+}
+
+// loadPrecision returns the display precision chosen in settings, or the
+// default if it cannot be read
+func loadPrecision(ctx context.Context, store db.Store) db.Precision {
+	p, err := store.GetPrecision(ctx)
+	if err != nil {
+		slog.Warn("loading precision", "err", err)
+		return db.DefaultPrecision
+	}
+	return p
+}
+
+// loadChainTTL returns how long fetched option chains are reused, the
+// default if none is set or it cannot be read
+func loadChainTTL(ctx context.Context, store db.Store) time.Duration {
+	text, err := store.GetChainCacheTTL(ctx)
+	if err != nil {
+		slog.Warn("loading chain cache TTL", "err", err)
+		return yahoo.DefaultChainTTL
+	}
+	if text == "" {
+		return yahoo.DefaultChainTTL
+	}
+	ttl, err := time.ParseDuration(text)
+	if err != nil || ttl < 0 {
+		slog.Warn("invalid chain cache TTL in settings, using default", "ttl", text)
+		return yahoo.DefaultChainTTL
+	}
+	return ttl
+}
+
+// formatShares formats a share quantity at the configured precision
+func (b *book) formatShares(d decimal.Decimal) string {
+	return b.locale.FormatFixed(d, b.precision.Shares)
+}
+
+// formatPrice formats a per-share price, strike or premium at the
+// configured precision
+func (b *book) formatPrice(d decimal.Decimal) string {
+	return b.locale.FormatFixed(d, b.precision.Prices)
+}
+
+// Precision choices offered in settings
+var (
+	shareDecimals = []int32{0, 2, 4}
+	priceDecimals = []int32{2, 4}
+)
+
+// decimalsOptions labels the choices for a precision dropdown and finds the
+// current one
+func decimalsOptions(choices []int32, current int32) ([]string, int) {
+	labels := make([]string, len(choices))
+	index := 0
+	for i, n := range choices {
+		labels[i] = strconv.Itoa(int(n))
+		if n == current {
+			index = i
+		}
+	}
+	return labels, index
+}
+
+// historyOptions lists the price histories indicators may use, daily before
+// weekly, labels them for a dropdown, and finds the current one
+func historyOptions(current yahoo.History) ([]yahoo.History, []string, int) {
+	var choices []yahoo.History
+	var labels []string
+	index := 0
+	for _, interval := range yahoo.HistoryIntervals {
+		for _, rng := range yahoo.HistoryRanges {
+			h, err := yahoo.ParseHistory(rng + "/" + interval)
+			if err != nil {
+				continue
+			}
+			if h == current {
+				index = len(choices)
+			}
+			label := rng + " daily"
+			if h.Weekly() {
+				label = rng + " weekly"
+			}
+			choices = append(choices, h)
+			labels = append(labels, label)
+		}
+	}
+	return choices, labels, index
+}
+
+// assignmentFee returns the broker fee for assigning or exercising o, zero if
+// none is configured or it cannot be read
+func (a *App) assignmentFee(ctx context.Context, o db.Option) decimal.Decimal {
+	fee, err := a.db.GetAssignmentFee(ctx)
+	if err != nil {
+		slog.Warn("loading assignment fee", "err", err)
+		return decimal.Zero
+	}
+	return fee.For(o.Quantity)
+}
+
+// showSettingsForm edits the display and reporting preferences stored in settings
+func (a *App) showSettingsForm() {
+	locales := locale.All()
+	labels := make([]string, len(locales))
+	current := 0
+	for i, l := range locales {
+		labels[i] = fmt.Sprintf("%-6s %s", l.Name, l.Description)
+		if l.Name == a.book.locale.Name {
+			current = i
+		}
+	}
+
+	fee, err := a.db.GetAssignmentFee(context.Background())
+	if err != nil {
+		slog.Warn("loading assignment fee", "err", err)
+	}
+
+	shareLabels, shareIndex := decimalsOptions(shareDecimals, a.book.precision.Shares)
+	priceLabels, priceIndex := decimalsOptions(priceDecimals, a.book.precision.Prices)
+	histories, historyLabels, historyIndex := historyOptions(a.query.IndicatorHistory(context.Background()))
+	routes, err := a.db.GetNotifyRoutes(context.Background())
+	if err != nil {
+		slog.Warn("loading notification routes", "err", err)
+	}
+	if routes == "" {
+		routes = notify.DefaultRoutes
+	}
+
+	chainTTL := loadChainTTL(context.Background(), a.db)
+	vixPercentile, err := a.db.GetVIXPercentileScoring(context.Background())
+	if err != nil {
+		slog.Warn("loading VIX percentile scoring", "err", err)
+	}
+
+	form := tview.NewForm().
+		AddDropDown("Number/date format", labels, current, nil).
+		AddInputField("Tax year starts (MM-DD)", a.book.taxYear.String(), 8, nil, nil).
+		AddInputField("Assignment fee ($ each)", a.book.locale.EditNumber(fee.PerAssignment.String()), 10, nil, nil).
+		AddInputField("Assignment fee ($ per contract)", a.book.locale.EditNumber(fee.PerContract.String()), 10, nil, nil).
+		AddCheckbox("Bell on new alerts", a.alertBell, nil).
+		AddDropDown("Share decimals", shareLabels, shareIndex, nil).
+		AddDropDown("Price decimals", priceLabels, priceIndex, nil).
+		AddInputField("Weight drift tolerance (± % points)", a.book.locale.EditNumber(a.targetWeights.Tolerance.String()), 8, nil, nil).
+		AddDropDown("RSI price history", historyLabels, historyIndex, nil).
+		AddInputField("Base currency", a.book.base(), 5, nil, nil).
+		AddInputField("Notify (kind=channels; ...)", routes, 40, nil, nil).
+		AddInputField("Reuse option chains for (min, 0 off)", strconv.Itoa(int(chainTTL.Minutes())), 6, nil, nil).
+		AddCheckbox("Score VIX by 5y percentile", vixPercentile, nil)
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		i, _ := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+		chosen := locales[i]
+
+		taxYear, err := analytics.ParseTaxYear(strings.TrimSpace(form.GetFormItem(1).(*tview.InputField).GetText()))
+		if err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]%v", err))
+			return
+		}
+
+		var fee db.AssignmentFee
+		for i, dst := range []*decimal.Decimal{&fee.PerAssignment, &fee.PerContract} {
+			text := strings.TrimSpace(form.GetFormItem(2 + i).(*tview.InputField).GetText())
+			if text == "" {
+				continue
+			}
+			value, err := a.book.locale.ParseNumber(text)
+			if err != nil || value.IsNegative() {
+				a.statusBar.SetText(" [red]Invalid assignment fee")
+				return
+			}
+			*dst = value
+		}
+		bell := form.GetFormItem(4).(*tview.Checkbox).IsChecked()
+		shareIndex, _ := form.GetFormItem(5).(*tview.DropDown).GetCurrentOption()
+		priceIndex, _ := form.GetFormItem(6).(*tview.DropDown).GetCurrentOption()
+		precision := db.Precision{Shares: shareDecimals[shareIndex], Prices: priceDecimals[priceIndex]}
+		tolerance, err := a.book.locale.ParseNumber(strings.TrimSpace(form.GetFormItem(7).(*tview.InputField).GetText()))
+		if err != nil || tolerance.IsNegative() {
+			a.statusBar.SetText(" [red]Invalid weight drift tolerance")
+			return
+		}
+		historyIndex, _ := form.GetFormItem(8).(*tview.DropDown).GetCurrentOption()
+		history := histories[historyIndex]
+		base, ok := parseCurrency(form.GetFormItem(9).(*tview.InputField).GetText())
+		if !ok {
+			a.statusBar.SetText(" [red]Invalid base currency, use a 3-letter code like USD")
+			return
+		}
+		parsedRoutes, err := notify.ParseRoutes(form.GetFormItem(10).(*tview.InputField).GetText())
+		if err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Invalid notification routes: %v", err))
+			return
+		}
+		minutes, err := strconv.Atoi(strings.TrimSpace(form.GetFormItem(11).(*tview.InputField).GetText()))
+		if err != nil || minutes < 0 {
+			a.statusBar.SetText(" [red]Invalid option chain reuse, use whole minutes")
+			return
+		}
+		chainTTL := time.Duration(minutes) * time.Minute
+		vixPercentile := form.GetFormItem(12).(*tview.Checkbox).IsChecked()
+
+		ctx := context.Background()
+		if err := a.db.SetLocale(ctx, chosen.Name); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+		if err := a.db.SetTaxYearStart(ctx, taxYear.String()); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+		if err := a.db.SetAssignmentFee(ctx, fee); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+		if err := a.db.SetAlertBell(ctx, bell); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+		if err := a.db.SetPrecision(ctx, precision); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+		if err := a.db.SetIndicatorHistory(ctx, history.String()); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+		if err := a.db.SetBaseCurrency(ctx, base); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+		if err := a.db.SetNotifyRoutes(ctx, notify.FormatRoutes(parsedRoutes)); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+		if err := a.db.SetChainCacheTTL(ctx, chainTTL.String()); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+		if err := a.db.SetVIXPercentileScoring(ctx, vixPercentile); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+		targets, err := a.db.GetTargetWeights(ctx)
+		if err == nil {
+			targets.Tolerance = tolerance
+			err = a.db.SetTargetWeights(ctx, targets)
+		}
+		if err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+
+		a.pages.RemovePage("settings")
+		a.book.locale = chosen
+		a.book.taxYear = taxYear
+		a.alertBell = bell
+		a.book.precision = precision
+		a.book.baseCurrency = base
+		a.notifier = tuiNotifier(ctx, a.db)
+		if a.chains != nil {
+			a.chains.SetTTL(chainTTL)
+		}
+		a.refreshData()
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("settings")
+	})
+
+	form.SetBorder(true).SetTitle(" Settings ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("settings", form, 80, 31)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/yahoo"
+
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// tickerSplit is a stock split on a ticker the portfolio holds or has
+// options on
+type tickerSplit struct {
+	Ticker string
+	yahoo.Split
+}
+
+// ratio is the new shares per old share
+func (s tickerSplit) ratio() decimal.Decimal {
+	return decimal.NewFromFloat(s.Numerator).Div(decimal.NewFromFloat(s.Denominator))
+}
+
+// label describes the split, e.g. "4-for-1"
+func (s tickerSplit) label() string {
+	return strconv.FormatFloat(s.Numerator, 'f', -1, 64) + "-for-" + strconv.FormatFloat(s.Denominator, 'f', -1, 64)
+}
+
+// splitTickers lists the tickers held or with active options, which stock
+// splits are checked for
+func (a *App) splitTickers() []string {
+	seen := make(map[string]bool)
+	var tickers []string
+	add := func(ticker string) {
+		if !seen[ticker] {
+			seen[ticker] = true
+			tickers = append(tickers, ticker)
+		}
+	}
+	for _, h := range a.book.holdings {
+		add(h.Ticker)
+	}
+	for _, o := range a.book.options {
+		if o.Status == "ACTIVE" {
+			add(o.Ticker)
+		}
+	}
+	return tickers
+}
+
+// fetchSplits fetches the recent splits of each ticker. Tickers whose
+// splits cannot be fetched are skipped. Runs off the event loop.
+func (a *App) fetchSplits(tickers []string) map[string][]yahoo.Split {
+	splits := make(map[string][]yahoo.Split)
+	for _, ticker := range tickers {
+		fetched, err := a.yahoo.FetchSplits(ticker)
+		if err != nil {
+			continue
+		}
+		splits[ticker] = fetched
+	}
+	return splits
+}
+
+// splitsToApply picks the splits that came after the position on their
+// ticker was opened (the holding's entry date or the earliest active
+// option's creation) and that the ledger has no entry for, oldest first
+func (a *App) splitsToApply(fetched map[string][]yahoo.Split, txs []db.Transaction) []tickerSplit {
+	opened := make(map[string]time.Time)
+	since := func(ticker string, t time.Time) {
+		if first, ok := opened[ticker]; !ok || t.Before(first) {
+			opened[ticker] = t
+		}
+	}
+	for _, h := range a.book.holdings {
+		since(h.Ticker, h.EntryDate)
+	}
+	for _, o := range a.book.options {
+		if o.Status == "ACTIVE" {
+			since(o.Ticker, o.CreatedAt)
+		}
+	}
+
+	var pending []tickerSplit
+	for ticker, splits := range fetched {
+		first, ok := opened[ticker]
+		if !ok {
+			continue
+		}
+		for _, s := range splits {
+			if !s.Date.After(first) || splitApplied(ticker, s, txs) {
+				continue
+			}
+			pending = append(pending, tickerSplit{Ticker: ticker, Split: s})
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Date.Before(pending[j].Date) })
+	return pending
+}
+
+// splitApplied reports whether the ledger records split on ticker: an entry
+// with its date and ratio, or, for entries made before splits were recorded
+// with them, any split entry made on or after its date
+func splitApplied(ticker string, split yahoo.Split, txs []db.Transaction) bool {
+	ratio := tickerSplit{Ticker: ticker, Split: split}.ratio().Round(8)
+	for _, t := range txs {
+		if t.Kind != db.TxSplit || t.Ticker != ticker {
+			continue
+		}
+		if t.SplitDate.IsZero() {
+			if !t.CreatedAt.Before(split.Date) {
+				return true
+			}
+			continue
+		}
+		if t.SplitDate.Format(time.DateOnly) == split.Date.Format(time.DateOnly) && t.SplitRatio.Round(8).Equal(ratio) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSplits looks for stock splits on the portfolio's tickers in the
+// background and offers the ones not yet applied through the status bar
+func (a *App) checkSplits() {
+	if a.readOnly() {
+		return
+	}
+	tickers := a.splitTickers()
+	if len(tickers) == 0 {
+		return
+	}
+	a.goSafe("split check", func() {
+		fetched := a.fetchSplits(tickers)
+		a.queueUpdateDraw(func() {
+			txs, err := a.db.GetTransactions(context.Background())
+			if err != nil {
+				return
+			}
+			a.pendingSplits = a.splitsToApply(fetched, txs)
+			a.updateStatusBar()
+		})
+	})
+}
+
+// reviewSplits offers the oldest pending split: Apply adjusts the holding
+// and the active options opened before it and records it in the ledger,
+// Dismiss skips it for the session. Either moves on to the next.
+func (a *App) reviewSplits() {
+	if len(a.pendingSplits) == 0 {
+		a.statusBar.SetText(" [gray]No stock splits to apply")
+		return
+	}
+	s := a.pendingSplits[0]
+	ratio := s.ratio()
+
+	text := fmt.Sprintf("%s split %s on %s\n", s.Ticker, s.label(), a.book.locale.FormatDate(s.Date))
+	for _, h := range a.book.holdings {
+		if h.Ticker == s.Ticker {
+			split := h.Split(ratio)
+			text += fmt.Sprintf("\nHolding: %s sh @ $%s → %s sh @ $%s",
+				a.book.locale.FormatFixed(h.Quantity, 0), a.book.locale.FormatFixed(h.AvgCost, 2),
+				a.book.locale.FormatFixed(split.Quantity, 0), a.book.locale.FormatFixed(split.AvgCost, 2))
+		}
+	}
+	for _, o := range a.book.options {
+		if o.Ticker != s.Ticker || o.Status != "ACTIVE" || !o.CreatedAt.Before(s.Date) {
+			continue
+		}
+		split, err := o.Split(ratio)
+		if err != nil {
+			text += fmt.Sprintf("\n%s %s $%s: cannot be adjusted", o.Action, o.OptionType, a.book.formatPrice(o.Strike))
+			continue
+		}
+		text += fmt.Sprintf("\n%s %s $%s ×%d → $%s ×%d", o.Action, o.OptionType, a.book.formatPrice(o.Strike), o.Quantity,
+			a.book.formatPrice(split.Strike), split.Quantity)
+		if split.Multiplier != o.Multiplier {
+			text += fmt.Sprintf(" (%d sh each)", split.Multiplier)
+		}
+	}
+
+	next := func() {
+		a.pages.RemovePage("split")
+		a.pendingSplits = a.pendingSplits[1:]
+		a.updateStatusBar()
+		if len(a.pendingSplits) > 0 {
+			a.reviewSplits()
+		}
+	}
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"Apply", "Dismiss", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			switch buttonLabel {
+			case "Apply":
+				notes := fmt.Sprintf("%s split on %s", s.label(), s.Date.Format(time.DateOnly))
+				if err := a.db.ApplySplit(context.Background(), s.Ticker, ratio, s.Date, notes); err != nil {
+					a.pages.RemovePage("split")
+					a.statusBar.SetText(fmt.Sprintf(" [red]Error applying split: %v", err))
+					return
+				}
+				a.refreshData()
+				next()
+			case "Dismiss":
+				next()
+			default:
+				a.pages.RemovePage("split")
+			}
+		})
+	a.pages.AddPage("split", modal, true, true)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/yahoo"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// maxQuoteFailures is how many refreshes in a row a symbol may return no data
+// before it is treated as delisted or halted and no longer fetched
+const maxQuoteFailures = 5
+
+// loadStaleSymbols reads the symbols quotes have been failing for. Without
+// schema_prices.sql there are none.
+func (a *App) loadStaleSymbols(ctx context.Context) {
+	stale, err := a.db.GetStaleSymbols(ctx)
+	if err != nil {
+		stale = map[string]db.StaleSymbol{}
+	}
+	a.book.staleSymbols = stale
+}
+
+// isHalted reports whether ticker has failed often enough to stop fetching it
+func (b *book) isHalted(ticker string) bool {
+	return b.staleSymbols[ticker].Failures >= maxQuoteFailures
+}
+
+// liveTickers drops the halted symbols from a quote request
+func (a *App) liveTickers(tickers []string) []string {
+	var live []string
+	for _, t := range tickers {
+		if !a.book.isHalted(t) {
+			live = append(live, t)
+		}
+	}
+	return live
+}
+
+// trackQuoteFailures counts a failure for each requested symbol missing from
+// quotes and clears the record of those that quote again. The first failure
+// keeps the price the previous refresh showed. A batch in which every symbol
+// failed is taken for an outage and not counted.
+func (a *App) trackQuoteFailures(ctx context.Context, requested []string, quotes map[string]yahoo.Quote) {
+	if len(quotes) == 0 {
+		return
+	}
+	for _, t := range requested {
+		if _, ok := quotes[t]; ok {
+			if _, tracked := a.book.staleSymbols[t]; tracked {
+				if err := a.db.ClearStaleSymbol(ctx, t); err != nil {
+					slog.Debug("clearing stale symbol", "ticker", t, "err", err)
+				}
+			}
+			continue
+		}
+
+		var last decimal.NullDecimal
+		if q, ok := a.book.quotes[t]; ok && q.Price > 0 && !a.book.isFilledQuote(t) {
+			last = decimal.NewNullDecimal(decimal.NewFromFloat(q.Price))
+		}
+		if err := a.db.RecordQuoteFailure(ctx, t, last, a.lastRefresh); err != nil {
+			slog.Debug("recording quote failure", "ticker", t, "err", err)
+		}
+	}
+}
+
+// isFilledQuote reports whether the quote for ticker was filled in from a
+// manual or last-known price rather than fetched
+func (b *book) isFilledQuote(ticker string) bool {
+	q := b.quotes[ticker]
+	if p, ok := b.manualPrices[ticker]; ok && q == (yahoo.Quote{Symbol: ticker, Price: p.Price.InexactFloat64()}) {
+		return true
+	}
+	if s, ok := b.staleSymbols[ticker]; ok && s.LastPrice.Valid && q == (yahoo.Quote{Symbol: ticker, Price: s.LastPrice.Decimal.InexactFloat64()}) {
+		return true
+	}
+	return false
+}
+
+// applyStalePrices values symbols without a quote or manual price at their
+// last-known price
+func (a *App) applyStalePrices() {
+	for ticker, s := range a.book.staleSymbols {
+		if _, ok := a.book.quotes[ticker]; ok || !s.LastPrice.Valid {
+			continue
+		}
+		if a.book.quotes == nil {
+			a.book.quotes = make(map[string]yahoo.Quote)
+		}
+		a.book.quotes[ticker] = yahoo.Quote{Symbol: ticker, Price: s.LastPrice.Decimal.InexactFloat64()}
+	}
+}
+
+// stalePriceCell shows a last-known price with its date, red once the symbol
+// is no longer refreshed
+func (v *HoldingsView) stalePriceCell(s db.StaleSymbol, bg tcell.Color) *tview.TableCell {
+	color := tcell.ColorYellow
+	if s.Failures >= maxQuoteFailures {
+		color = tcell.ColorRed
+	}
+	return tview.NewTableCell(fmt.Sprintf(" $%s stale %s ", v.book.formatPrice(s.LastPrice.Decimal), v.book.locale.FormatMonthDay(s.LastPriceAt))).
+		SetTextColor(color).
+		SetBackgroundColor(bg).
+		SetAlign(tview.AlignLeft).
+		SetExpansion(1)
+}
+
+// retryStaleSymbols fetches the halted symbols once more. Those that quote
+// again are refreshed as usual from now on; the rest stay halted.
+func (a *App) retryStaleSymbols() {
+	var halted []string
+	for ticker := range a.book.staleSymbols {
+		if a.book.isHalted(ticker) {
+			halted = append(halted, ticker)
+		}
+	}
+	if len(halted) == 0 {
+		a.statusBar.SetText(" [yellow]No halted symbols to retry")
+		return
+	}
+
+	quotes, err := a.yahoo.GetQuotes(halted)
+	if err != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Retry failed: %v", err))
+		return
+	}
+	ctx := context.Background()
+	for _, t := range halted {
+		if _, ok := quotes[t]; ok {
+			if err := a.db.ClearStaleSymbol(ctx, t); err != nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+				return
+			}
+		}
+	}
+	a.refreshData()
+	a.statusBar.SetText(fmt.Sprintf(" [yellow]Retried %d halted symbols: %d quoting again", len(halted), len(quotes)))
+}
+
+// missingQuotes returns the requested symbols quotes has nothing for
+func missingQuotes(requested []string, quotes map[string]yahoo.Quote) []string {
+	var missing []string
+	for _, t := range requested {
+		if _, ok := quotes[t]; !ok {
+			missing = append(missing, t)
+		}
+	}
+	return missing
+}
+
+// failedChip names the symbols that failed to quote, the first few in full
+func failedChip(failed []string) string {
+	const shown = 3
+	if len(failed) <= shown {
+		return "Failed: " + strings.Join(failed, ", ")
+	}
+	return fmt.Sprintf("Failed: %s +%d more", strings.Join(failed[:shown], ", "), len(failed)-shown)
+}
+
+// retryFailedQuotes fetches the symbols that failed to quote on the last
+// refresh again, without reloading everything else
+func (a *App) retryFailedQuotes() {
+	failed := a.book.failedQuotes
+	if len(failed) == 0 {
+		a.statusBar.SetText(" [yellow]No failed quotes to retry")
+		return
+	}
+
+	quotes, err := a.yahoo.GetQuotes(failed)
+	if err != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Retry failed: %v", err))
+		return
+	}
+	ctx := context.Background()
+	if a.book.quotes == nil {
+		a.book.quotes = make(map[string]yahoo.Quote)
+	}
+	for t, q := range quotes {
+		a.book.quotes[t] = q
+		if _, tracked := a.book.staleSymbols[t]; tracked && a.role != db.RoleViewer {
+			if err := a.db.ClearStaleSymbol(ctx, t); err != nil {
+				slog.Debug("clearing stale symbol", "ticker", t, "err", err)
+			}
+		}
+	}
+	a.book.failedQuotes = missingQuotes(failed, quotes)
+	a.loadStaleSymbols(ctx)
+	a.renderData(ctx)
+	a.updateStatusBar()
+	if len(a.book.failedQuotes) > 0 {
+		a.statusBar.SetText(fmt.Sprintf(" [yellow]Retried %d symbols: %d still failing", len(failed), len(a.book.failedQuotes)))
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/yahoo"
+)
+
+// quoteCache is the last fetched quotes, saved so the next launch can paint
+// prices before the network answers
+type quoteCache struct {
+	At     time.Time              `json:"at"`
+	Quotes map[string]yahoo.Quote `json:"quotes"`
+}
+
+// saveQuoteCache stores quotes as the cache for the next launch
+func (a *App) saveQuoteCache(ctx context.Context, quotes map[string]yahoo.Quote) {
+	writeQuoteCache(ctx, a.db, quoteCache{At: a.now(), Quotes: quotes})
+}
+
+// loadQuoteCache reads the quotes saved by the last refresh. The cache is
+// empty if none were saved.
+func (a *App) loadQuoteCache(ctx context.Context) quoteCache {
+	return readQuoteCache(ctx, a.db)
+}
+
+// writeQuoteCache stores cache in settings
+func writeQuoteCache(ctx context.Context, store db.Store, cache quoteCache) {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	if err := store.SetQuoteCache(ctx, string(data)); err != nil {
+		slog.Debug("saving quote cache", "err", err)
+	}
+}
+
+// readQuoteCache reads the cache from settings, empty if there is none or it
+// cannot be read
+func readQuoteCache(ctx context.Context, store db.Store) quoteCache {
+	raw, err := store.GetQuoteCache(ctx)
+	if err != nil {
+		slog.Warn("loading quote cache", "err", err)
+		return quoteCache{}
+	}
+	var cache quoteCache
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cache); err != nil {
+			slog.Warn("ignoring unreadable quote cache", "err", err)
+			return quoteCache{}
+		}
+	}
+	return cache
+}
+
+// firstLoad paints the portfolio from the database and the cached quotes
+// without waiting on the network, then refreshes it in the background: the
+// quotes are fetched off the event loop and applied, with expired options
+// processed, once they arrive. Stock splits are looked for alongside.
+func (a *App) firstLoad() {
+	ctx := context.Background()
+	live, ok := a.loadCached(ctx)
+	if !ok {
+		return
+	}
+	a.checkSplits()
+
+	a.goSafe("first load", func() {
+		quotes, err := a.fetchQuotes(live)
+		a.queueUpdateDraw(func() {
+			ctx := context.Background()
+			if _, ok := a.loadData(ctx, true); !ok {
+				return
+			}
+			a.applyQuotes(ctx, live, quotes, err)
+			a.finishRefresh(ctx)
+		})
+	})
+}
+
+// loadCached renders the database's view of the portfolio priced at the
+// cached quotes, and returns the tickers to fetch fresh quotes for
+func (a *App) loadCached(ctx context.Context) ([]string, bool) {
+	live, ok := a.loadData(ctx, false)
+	if !ok {
+		return nil, false
+	}
+	cache := a.loadQuoteCache(ctx)
+	a.book.quotes = cache.Quotes
+	a.renderData(ctx)
+
+	if cache.At.IsZero() {
+		a.statusBar.SetText(" [yellow]Fetching prices...")
+	} else {
+		a.statusBar.SetText(fmt.Sprintf(" [yellow]Prices as of %s %s, fetching fresh quotes...",
+			a.book.locale.FormatDate(cache.At.Local()), cache.At.Local().Format("15:04")))
+	}
+	return live, true
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/locale"
+	"anyhowhodl/internal/query"
+	"anyhowhodl/internal/yahoo"
+
+	"github.com/shopspring/decimal"
+)
+
+// statusMaxAge is how old the cached quotes may be before the status command
+// fetches fresh ones. Status bars poll every few seconds, so most runs are
+// served from the cache the TUI and earlier runs saved.
+const statusMaxAge = 15 * time.Minute
+
+// Markup the status line can be colored with
+var statusStyles = []string{"plain", "tmux", "ansi", "pango"}
+
+// cachedQuotes serves quotes from a fixed set instead of fetching them
+type cachedQuotes struct {
+	yahoo.Provider
+	quotes map[string]yahoo.Quote
+}
+
+func (c cachedQuotes) GetQuotes(symbols []string) (map[string]yahoo.Quote, error) {
+	out := make(map[string]yahoo.Quote, len(symbols))
+	for _, s := range symbols {
+		if q, ok := c.quotes[s]; ok {
+			out[s] = q
+		}
+	}
+	return out, nil
+}
+
+// runStatus implements the status command
+func runStatus(store db.Store, market yahoo.Provider, args []string) error {
+	style := "plain"
+	if len(args) > 0 {
+		style = args[0]
+	}
+	line, err := statusText(context.Background(), store, market, style, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Println(line)
+	return nil
+}
+
+// statusText is the one-line summary for status bars: portfolio value, the
+// holdings' change today, and this tax year's net premium. Quotes come from
+// the quote cache, refreshed and saved back when older than statusMaxAge; if
+// the refresh fails the line is marked with the time of the quotes used.
+func statusText(ctx context.Context, store db.Store, market yahoo.Provider, style string, now time.Time) (string, error) {
+	color, ok := statusColorizer(style)
+	if !ok {
+		return "", fmt.Errorf("unknown style %q, use %s", style, strings.Join(statusStyles, ", "))
+	}
+	holdings, err := store.GetHoldings(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	cache := readQuoteCache(ctx, store)
+	if now.Sub(cache.At) > statusMaxAge {
+		tickers := make([]string, len(holdings))
+		for i, h := range holdings {
+			tickers[i] = h.Ticker
+		}
+		if fresh, err := market.GetQuotes(tickers); err == nil && len(fresh) > 0 {
+			// Kept with the cached quotes the TUI needs for options and FX
+			quotes := make(map[string]yahoo.Quote)
+			maps.Copy(quotes, cache.Quotes)
+			maps.Copy(quotes, fresh)
+			cache = quoteCache{At: now, Quotes: quotes}
+			writeQuoteCache(ctx, store, cache)
+		}
+	}
+
+	svc := query.New(store, cachedQuotes{Provider: market, quotes: cache.Quotes})
+	sum, err := svc.Summary(ctx)
+	if err != nil {
+		return "", err
+	}
+	// Each holding's change is in its own currency, so converted before summing
+	toBase := svc.ToBase(ctx)
+	var change decimal.Decimal
+	for _, h := range holdings {
+		if q, ok := cache.Quotes[h.Ticker]; ok {
+			change = change.Add(toBase(h.Quantity.Mul(decimal.NewFromFloat(q.Change)), h.Currency))
+		}
+	}
+	line := statusLine(loadLocale(ctx, store), sum, change, color)
+	if now.Sub(cache.At) > statusMaxAge && !cache.At.IsZero() {
+		line += " (" + cache.At.Local().Format("Jan 2 15:04") + ")"
+	}
+	return line, nil
+}
+
+// statusLine formats the summary as "$123,456.78 ▲ +$1,234.56 (+1.01%) · 2026 prem $4,321.00",
+// with color marking the change and premium green or red
+func statusLine(loc locale.Locale, sum *query.PortfolioSummary, change decimal.Decimal, color func(text string, up bool) string) string {
+	money := func(d decimal.Decimal) string {
+		if d.IsNegative() {
+			return "-$" + loc.FormatFixed(d.Abs(), 2)
+		}
+		return "$" + loc.FormatFixed(d, 2)
+	}
+	signed := func(d decimal.Decimal) string {
+		if d.IsNegative() {
+			return money(d)
+		}
+		return "+" + money(d)
+	}
+
+	arrow := "▲"
+	if change.IsNegative() {
+		arrow = "▼"
+	}
+	changeText := arrow + " " + signed(change)
+	if before := sum.HoldingsValue.Sub(change); before.IsPositive() {
+		pct := change.Div(before).Mul(decimal.NewFromInt(100))
+		sign := "+"
+		if pct.IsNegative() {
+			sign = "-"
+		}
+		changeText += fmt.Sprintf(" (%s%s%%)", sign, loc.FormatFixed(pct.Abs(), 2))
+	}
+
+	premium := decimal.Zero
+	if sum.Premiums != nil {
+		premium = sum.Premiums.NetPL
+	}
+	return fmt.Sprintf("%s %s · %s prem %s", money(sum.Total),
+		color(changeText, !change.IsNegative()),
+		sum.PremiumYear, color(money(premium), !premium.IsNegative()))
+}
+
+// statusColorizer returns how style colors text green if up or red if not
+func statusColorizer(style string) (func(text string, up bool) string, bool) {
+	pick := func(up bool, green, red string) string {
+		if up {
+			return green
+		}
+		return red
+	}
+	switch style {
+	case "plain":
+		return func(text string, up bool) string { return text }, true
+	case "tmux":
+		return func(text string, up bool) string {
+			return "#[fg=" + pick(up, "green", "red") + "]" + text + "#[default]"
+		}, true
+	case "ansi":
+		return func(text string, up bool) string {
+			return pick(up, "\x1b[32m", "\x1b[31m") + text + "\x1b[0m"
+		}, true
+	case "pango":
+		// i3blocks and waybar with markup enabled
+		return func(text string, up bool) string {
+			return `<span foreground="` + pick(up, "#50fa7b", "#ff5555") + `">` + text + "</span>"
+		}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// SummaryView is the portfolio summary above the holdings table: totals, P/L,
+// price warnings, projected dividends, and the optional income and accounts lines
+type SummaryView struct {
+	text       *tview.TextView
+	showIncome bool // YTD income line under the totals
+
+	book     *book
+	holdings *HoldingsView // Its totals are summed up and its filter shown in the title
+	lines    summaryLines
+}
+
+// summaryLines are the summary's lines other features write
+type summaryLines interface {
+	dividendsLine(holdingsValue decimal.Decimal) string
+	incomeLine(portfolioValue decimal.Decimal) string
+}
+
+// newSummaryView builds the summary of b, redrawn whenever the holdings
+// table is
+func newSummaryView(b *book, holdings *HoldingsView, lines summaryLines, bus *eventBus) *SummaryView {
+	v := &SummaryView{text: tview.NewTextView().SetDynamicColors(true), book: b, holdings: holdings, lines: lines}
+	v.text.SetBorder(true).SetTitle(" Portfolio ").SetTitleAlign(tview.AlignLeft).SetBorderColor(tcell.ColorTeal)
+	bus.subscribe(eventHoldingsChanged, v.update)
+	bus.subscribe(eventHoldingsChanged, v.setTitle)
+	return v
+}
+
+// update shows the totals from the last holdings table update
+func (v *SummaryView) update() {
+	totalValue, totalCost := v.holdings.value, v.holdings.cost
+	totalPL := totalValue.Sub(totalCost)
+	totalPLPct := decimal.Zero
+	if !totalCost.IsZero() {
+		totalPLPct = totalPL.Div(totalCost).Mul(decimal.NewFromInt(100))
+	}
+
+	plColor := "[white]"
+	if totalPL.IsPositive() {
+		plColor = "[green]"
+	} else if totalPL.IsNegative() {
+		plColor = "[red]"
+	}
+
+	plSign := ""
+	if totalPL.IsPositive() {
+		plSign = "+"
+	}
+
+	// Total portfolio = holdings value + cash
+	totalPortfolio := totalValue.Add(v.book.cash)
+
+	// All in the base currency; cash is kept in it
+	symbol := v.book.baseSymbol()
+	summaryText := fmt.Sprintf(" [white]Total: [yellow]%s%s[white]  |  Holdings: %s%s  |  Cash: [aqua]%s%s[white]  |  P/L: %s%s%s%s (%s%s%%)",
+		symbol, v.book.locale.FormatFixed(totalPortfolio, 2),
+		symbol, v.book.locale.FormatFixed(totalValue, 2),
+		symbol, v.book.locale.FormatFixed(v.book.cash, 2),
+		plColor, plSign, symbol, v.book.locale.FormatFixed(totalPL.Abs(), 2),
+		plSign, v.book.locale.FormatFixed(totalPLPct, 2))
+
+	// Cash held against short puts is not free to secure another
+	if collateral := v.book.putCollateral(); collateral.IsPositive() {
+		free := v.book.cash.Sub(collateral)
+		freeColor, freeSign := "[aqua]", ""
+		if free.IsNegative() {
+			freeColor, freeSign = "[red]", "-"
+		}
+		summaryText += fmt.Sprintf("  |  Collateral: %s%s  |  Free Cash: %s%s%s%s[white]",
+			symbol, v.book.locale.FormatFixed(collateral, 2),
+			freeColor, freeSign, symbol, v.book.locale.FormatFixed(free.Abs(), 2))
+	}
+
+	// Say how many positions are not at a market price
+	manualCount, halted, unpriced := 0, 0, 0
+	for _, h := range v.book.holdings {
+		if _, ok := v.book.manualPrices[h.Ticker]; ok {
+			manualCount++
+		} else if _, ok := v.book.quotes[h.Ticker]; !ok {
+			unpriced++
+		}
+		if v.book.isHalted(h.Ticker) {
+			halted++
+		}
+	}
+	if manualCount > 0 {
+		summaryText += fmt.Sprintf("  |  [orange]%d at manual price[white]", manualCount)
+	}
+	if halted > 0 {
+		summaryText += fmt.Sprintf("  |  [red]%d halted (y to retry)[white]", halted)
+	}
+	if len(v.book.failedQuotes) > 0 {
+		summaryText += "  |  [red]" + failedChip(v.book.failedQuotes) + " (F to retry)[white]"
+	}
+	if missing := v.book.missingFX(); len(missing) > 0 {
+		summaryText += fmt.Sprintf("  |  [red]no %s rate for %s, counted 1:1[white]", v.book.base(), strings.Join(missing, ", "))
+	}
+	if unpriced > 0 {
+		summaryText += fmt.Sprintf("  |  [red]%d at cost (no price, m to set)[white]", unpriced)
+	}
+	if len(v.book.holdings) > 0 {
+		summaryText += "\n" + v.lines.dividendsLine(totalValue)
+	}
+	if v.showIncome {
+		summaryText += "\n" + v.lines.incomeLine(totalPortfolio)
+	}
+	if len(v.book.accounts) > 0 {
+		summaryText += "\n" + v.book.accountsLine(totalPortfolio)
+	}
+
+	v.text.SetText(summaryText)
+}
+
+// putCollateral is the cash securing the active short puts, strike × shares
+// each in the base currency. Watch-only puts are secured elsewhere.
+func (b *book) putCollateral() decimal.Decimal {
+	collateral := decimal.Zero
+	for _, o := range b.options {
+		if o.Status != "ACTIVE" || o.External || o.Action != "SELL" || o.OptionType != "PUT" {
+			continue
+		}
+		collateral = collateral.Add(b.toBase(o.Strike.Mul(o.Shares()), o.Currency))
+	}
+	return collateral
+}
+
+// setTitle shows the active holdings filter in the portfolio
+// section's title, with how many holdings pass it, and the selected row when
+// there are more rows than the table shows at once
+func (v *SummaryView) setTitle() {
+	title := " Portfolio "
+	if len(v.book.accounts) > 0 {
+		title = " Portfolio: " + tview.Escape(v.book.accountName(v.book.account)) + " "
+	}
+	if v.holdings.filter != "" {
+		title += " [yellow]" + filterTitle(v.holdings.filter, len(v.holdings.rows), len(v.book.holdings)) + "[-] "
+	}
+	if n := v.holdings.archivedHidden(); n > 0 {
+		title += fmt.Sprintf(" [gray]%d archived[-] ", n)
+	}
+	if rows := len(v.holdings.rows); rows > maxHoldingsRows {
+		selected, _ := v.holdings.table.GetSelection()
+		title += fmt.Sprintf(" [gray]Row %d of %d, %d shown[-] ", max(selected, 1), rows, maxHoldingsRows)
+	}
+	v.text.SetTitle(title)
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/csp"
+	"anyhowhodl/internal/query"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// surfaceState is what the volatility surface shows: puts or calls, by
+// implied volatility or by annualized premium yield
+type surfaceState struct {
+	surface *query.IVSurface
+	calls   bool
+	yield   bool
+}
+
+// selectedTicker returns the ticker of the selected row in whichever table
+// has focus, or an empty string
+func (a *App) selectedTicker() string {
+	if a.showCSP {
+		if row, _ := a.cspView.table.GetSelection(); row > 0 && row <= len(a.cspView.watchlist) {
+			return a.cspView.watchlist[row-1].Ticker
+		}
+		return ""
+	}
+	if a.focusIndex == 0 {
+		if i, ok := a.holdingsView.selected(); ok {
+			return a.book.holdings[i].Ticker
+		}
+		return ""
+	}
+	if i, ok := a.optionsView.selected(); ok {
+		return a.book.options[i].Ticker
+	}
+	return ""
+}
+
+// showSurfaceForm asks which ticker's volatility surface to load
+func (a *App) showSurfaceForm() {
+	form := tview.NewForm().
+		AddInputField("Ticker", a.selectedTicker(), 10, nil, nil)
+	a.tickerAutocomplete(form.GetFormItem(0).(*tview.InputField))
+
+	styleForm(form)
+
+	form.AddButton("Load", func() {
+		ticker := strings.ToUpper(strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText()))
+		if ticker == "" {
+			a.statusBar.SetText(" [red]Ticker is required")
+			return
+		}
+		a.pages.RemovePage("surfaceform")
+		a.showSurfaceView(ticker)
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("surfaceform")
+	})
+
+	form.SetBorder(true).SetTitle(" IV Surface ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("surfaceform", form, 40, 7)
+}
+
+// showSurfaceView opens the strike-by-expiry heatmap for ticker and loads the
+// chains in the background
+func (a *App) showSurfaceView(ticker string) {
+	header := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	header.SetText(fmt.Sprintf(" [yellow]Loading %s option chains...", ticker))
+
+	table := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(true, true).
+		SetFixed(1, 1).
+		SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorDarkSlateGray))
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(header, 4, 0, false).
+		AddItem(table, 0, 1, true)
+	layout.SetBorder(true).
+		SetTitle(fmt.Sprintf(" IV Surface: %s ", ticker)).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	state := &surfaceState{}
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if state.surface == nil {
+			return event
+		}
+		switch event.Rune() {
+		case 'c':
+			state.calls = !state.calls
+		case 'y':
+			state.yield = !state.yield
+		default:
+			return event
+		}
+		a.fillSurface(header, table, state)
+		return nil
+	})
+
+	a.pages.AddPage("ivsurface", layout, true, true)
+
+	service := a.query
+	a.goSafe("iv surface", func() {
+		surface, err := service.IVSurface(ticker)
+		a.queueUpdateDraw(func() {
+			if err != nil {
+				header.SetText(fmt.Sprintf(" [red]Error loading %s: %v", ticker, err))
+				return
+			}
+			state.surface = surface
+			a.fillSurface(header, table, state)
+		})
+	})
+}
+
+// surfaceValue is what a contract's cell shows: its implied volatility, or
+// the annualized yield of selling it at the bid/ask midpoint, both in percent.
+// ok is false if there is nothing to show.
+func surfaceValue(c *csp.OptionContract, dte int, yield bool) (v float64, ok bool) {
+	if c == nil {
+		return 0, false
+	}
+	if !yield {
+		return c.ImpliedVolatility * 100, c.ImpliedVolatility > 0
+	}
+	mid := c.LastPrice
+	if c.Bid > 0 && c.Ask > 0 {
+		mid = (c.Bid + c.Ask) / 2
+	}
+	if mid <= 0 || dte <= 0 {
+		return 0, false
+	}
+	return csp.CalculatePremiumYield(mid, c.Strike, dte), true
+}
+
+// heatColor shades t from cool (0) to hot (1)
+func heatColor(t float64) tcell.Color {
+	switch {
+	case t < 0.25:
+		return tcell.ColorSteelBlue
+	case t < 0.5:
+		return tcell.ColorLime
+	case t < 0.75:
+		return tcell.ColorYellow
+	default:
+		return tcell.ColorRed
+	}
+}
+
+// fillSurface renders the surface as a heatmap, strikes down and expiries
+// across, and highlights its richest cell
+func (a *App) fillSurface(header *tview.TextView, table *tview.Table, state *surfaceState) {
+	s := state.surface
+	contracts, side := s.Puts, "PUT"
+	if state.calls {
+		contracts, side = s.Calls, "CALL"
+	}
+	measure := "implied volatility"
+	if state.yield {
+		measure = "annualized yield at mid"
+	}
+
+	today := a.now().Truncate(24 * time.Hour)
+	dtes := make([]int, len(s.Expiries))
+	for j, expiry := range s.Expiries {
+		dtes[j] = int(time.Unix(expiry, 0).Sub(today).Hours() / 24)
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	richI, richJ := -1, -1
+	for i := range s.Strikes {
+		for j := range s.Expiries {
+			v, ok := surfaceValue(contracts[i][j], dtes[j], state.yield)
+			if !ok {
+				continue
+			}
+			lo = math.Min(lo, v)
+			if v > hi {
+				hi, richI, richJ = v, i, j
+			}
+		}
+	}
+
+	table.Clear()
+	table.SetCell(0, 0, tview.NewTableCell(" STRIKE ").
+		SetTextColor(tcell.ColorBlack).
+		SetBackgroundColor(tcell.ColorTeal).
+		SetSelectable(false))
+	for j, expiry := range s.Expiries {
+		label := fmt.Sprintf(" %s %dd ", a.book.locale.FormatMonthDay(time.Unix(expiry, 0).UTC()), dtes[j])
+		table.SetCell(0, j+1, tview.NewTableCell(label).
+			SetTextColor(tcell.ColorBlack).
+			SetBackgroundColor(tcell.ColorTeal).
+			SetAlign(tview.AlignRight).
+			SetSelectable(false))
+	}
+
+	// The strike closest to the underlying marks the money
+	atm := 0
+	for i, k := range s.Strikes {
+		if math.Abs(k-s.Underlying) < math.Abs(s.Strikes[atm]-s.Underlying) {
+			atm = i
+		}
+	}
+
+	for i, k := range s.Strikes {
+		strikeColor := tcell.ColorWhite
+		if i == atm {
+			strikeColor = tcell.ColorYellow
+		}
+		table.SetCell(i+1, 0, tview.NewTableCell(" $"+a.book.locale.FormatFloat(k, 2)+" ").
+			SetTextColor(strikeColor).
+			SetAlign(tview.AlignRight).
+			SetSelectable(false))
+
+		for j := range s.Expiries {
+			v, ok := surfaceValue(contracts[i][j], dtes[j], state.yield)
+			if !ok {
+				table.SetCell(i+1, j+1, tview.NewTableCell(" - ").
+					SetTextColor(tcell.ColorDimGray).
+					SetAlign(tview.AlignRight))
+				continue
+			}
+			t := 0.0
+			if hi > lo {
+				t = (v - lo) / (hi - lo)
+			}
+			cell := tview.NewTableCell(" " + a.book.locale.FormatFloat(v, 1) + "% ").
+				SetTextColor(heatColor(t)).
+				SetAlign(tview.AlignRight)
+			if i == richI && j == richJ {
+				cell.SetTextColor(tcell.ColorBlack).SetBackgroundColor(tcell.ColorRed)
+			}
+			table.SetCell(i+1, j+1, cell)
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, " [teal]%s[white] at $%s  [teal]%ss[white] by %s\n", s.Ticker, a.book.locale.FormatFloat(s.Underlying, 2), side, measure)
+	if richI >= 0 {
+		fmt.Fprintf(&sb, " [teal]Richest:[white] $%s %s %s at [red]%s%%[white]\n",
+			a.book.locale.FormatFloat(s.Strikes[richI], 2), side,
+			a.book.locale.FormatDate(time.Unix(s.Expiries[richJ], 0).UTC()),
+			a.book.locale.FormatFloat(hi, 1))
+	} else {
+		sb.WriteString(" [gray]No quoted contracts\n")
+	}
+	sb.WriteString(" [steelblue]low [lime]■ [yellow]■ [red]high[white]  strike nearest the underlying in [yellow]yellow[white]\n")
+	sb.WriteString(" [yellow]c[white]:Puts/Calls  [yellow]y[white]:IV/Yield  [gray]ESC to close")
+	header.SetText(sb.String())
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// maxTagLength is the longest tag item_tags stores
+const maxTagLength = 40
+
+// loadTags reads the tags of the holdings and options. Without the item_tags
+// table there are none.
+func (a *App) loadTags(ctx context.Context) {
+	tags, err := a.db.GetTags(ctx)
+	if err != nil {
+		slog.Debug("loading tags", "err", err)
+	}
+	a.book.tags = tags
+}
+
+// parseTags reads comma-separated tags as stored: trimmed, lowercase, inner
+// spaces as dashes, without duplicates, in alphabetical order
+func parseTags(text string) []string {
+	var tags []string
+	for _, field := range strings.Split(text, ",") {
+		tag := strings.Join(strings.Fields(strings.ToLower(field)), "-")
+		tag = strings.TrimPrefix(tag, "#")
+		if tag == "" || len(tag) > maxTagLength || slices.Contains(tags, tag) {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	return tags
+}
+
+// allTags lists every tag in use, for suggestions
+func (a *App) allTags() []string {
+	var all []string
+	for _, tags := range a.book.tags {
+		for _, tag := range tags {
+			if !slices.Contains(all, tag) {
+				all = append(all, tag)
+			}
+		}
+	}
+	slices.Sort(all)
+	return all
+}
+
+// tagFilterValues are a row's tags for the quick filter: each matches as a
+// word of the row's text, or exactly with a leading #
+func (b *book) tagFilterValues(id string) (exact, text []string) {
+	for _, tag := range b.tags[id] {
+		exact = append(exact, "#"+tag)
+	}
+	return exact, slices.Clone(b.tags[id])
+}
+
+// setTagsHeader sets the TAGS column header of t at col
+func setTagsHeader(t *tview.Table, col int) {
+	t.SetCell(0, col, tview.NewTableCell(" TAGS ").
+		SetTextColor(tcell.ColorBlack).
+		SetBackgroundColor(tcell.ColorTeal).
+		SetAlign(tview.AlignLeft).
+		SetSelectable(false).
+		SetExpansion(1))
+}
+
+// tagsCell shows the tags of the holding or option with id
+func (b *book) tagsCell(id string, bg tcell.Color) *tview.TableCell {
+	text := " - "
+	if tags := b.tags[id]; len(tags) > 0 {
+		text = " " + tview.Escape(strings.Join(tags, ", ")) + " "
+	}
+	return tview.NewTableCell(text).
+		SetTextColor(tcell.ColorAqua).
+		SetBackgroundColor(bg).
+		SetAlign(tview.AlignLeft).
+		SetExpansion(1)
+}
+
+// toggleTagsColumn shows or hides the TAGS column of both tables
+func (a *App) toggleTagsColumn() {
+	a.book.showTags = !a.book.showTags
+	a.holdingsView.update()
+	a.optionsView.update()
+}
+
+// showTagsForm edits the tags of the selected holding or option
+func (a *App) showTagsForm() {
+	var id, label string
+	if a.focusIndex == 0 {
+		i, ok := a.holdingsView.selected()
+		if !ok {
+			return
+		}
+		id, label = a.book.holdings[i].ID, a.book.holdings[i].Ticker
+	} else {
+		i, ok := a.optionsView.selected()
+		if !ok {
+			return
+		}
+		o := a.book.options[i]
+		id, label = o.ID, fmt.Sprintf("%s %s $%s", o.Ticker, o.OptionType, a.book.formatPrice(o.Strike))
+	}
+
+	form := tview.NewForm().
+		AddInputField("Tags (comma-separated)", strings.Join(a.book.tags[id], ", "), 40, nil, nil)
+	field := form.GetFormItem(0).(*tview.InputField)
+
+	// Suggest tags in use for the one being typed
+	known := a.allTags()
+	field.SetAutocompleteFunc(func(text string) []string {
+		cut := strings.LastIndex(text, ",") + 1
+		prefix, word := text[:cut], strings.ToLower(strings.TrimSpace(text[cut:]))
+		if word == "" {
+			return nil
+		}
+		var entries []string
+		for _, tag := range known {
+			if strings.HasPrefix(tag, word) && tag != word {
+				entries = append(entries, strings.TrimSpace(prefix+" "+tag))
+			}
+		}
+		return entries
+	})
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		tags := parseTags(field.GetText())
+		if err := a.db.SetTags(context.Background(), id, tags); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error saving tags (run schema_tags.sql): %v", err))
+			return
+		}
+		if a.book.tags == nil {
+			a.book.tags = make(map[string][]string)
+		}
+		if len(tags) == 0 {
+			delete(a.book.tags, id)
+		} else {
+			a.book.tags[id] = tags
+		}
+		a.pages.RemovePage("tags")
+		a.holdingsView.update()
+		a.optionsView.update()
+		a.selectRowOf(id)
+		a.statusBar.SetText(fmt.Sprintf(" [green]Tagged %s: %s", label, strings.Join(tags, ", ")))
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("tags")
+	})
+
+	form.SetBorder(true).SetTitle(" Tags: " + label + " ").SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("tags", form, 70, 7)
+}
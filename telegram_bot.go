@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"anyhowhodl/internal/locale"
+	"anyhowhodl/internal/query"
+	"anyhowhodl/internal/telegram"
+)
+
+// telegramBot answers chat commands from a single chat. Alerts reach the
+// chat through the telegram notification channel.
+type telegramBot struct {
+	client *telegram.Client
+	chatID int64 // Only this chat is answered
+	query  *query.Service
+	locale locale.Locale // Read from settings when the daemon starts
+}
+
+func newTelegramBot(token string, chatID int64, q *query.Service, loc locale.Locale) *telegramBot {
+	return &telegramBot{
+		client: telegram.NewClient(token),
+		chatID: chatID,
+		query:  q,
+		locale: loc,
+	}
+}
+
+// poll answers commands until ctx is cancelled
+func (b *telegramBot) poll(ctx context.Context) {
+	var offset int64
+	for ctx.Err() == nil {
+		updates, err := b.client.GetUpdates(ctx, offset)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("telegram: %v", err)
+				time.Sleep(5 * time.Second)
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message == nil || u.Message.Chat.ID != b.chatID {
+				continue
+			}
+			cmd, _, ok := telegram.ParseCommand(u.Message.Text)
+			if !ok {
+				continue
+			}
+			reply := b.handle(ctx, cmd)
+			if err := b.client.SendMessage(ctx, b.chatID, reply); err != nil {
+				log.Printf("telegram: send: %v", err)
+			}
+		}
+	}
+}
+
+func (b *telegramBot) handle(ctx context.Context, cmd string) string {
+	switch cmd {
+	case "summary":
+		return b.summaryText(ctx)
+	case "options":
+		return b.optionsText(ctx)
+	case "csp":
+		return b.cspText(ctx)
+	case "alerts":
+		alerts, err := b.query.Alerts(ctx)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		if len(alerts) == 0 {
+			return "No alerts."
+		}
+		lines := make([]string, len(alerts))
+		for i, al := range alerts {
+			lines[i] = al.Message
+		}
+		return strings.Join(lines, "\n")
+	default:
+		return "Commands: /summary /options /csp /alerts"
+	}
+}
+
+func (b *telegramBot) summaryText(ctx context.Context) string {
+	s, err := b.query.Summary(ctx)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Portfolio: $%s\n", b.locale.FormatFixed(s.Total, 2))
+	fmt.Fprintf(&sb, "Holdings: $%s (%d positions)\n", b.locale.FormatFixed(s.HoldingsValue, 2), s.Positions)
+	if s.ManualPriced > 0 || s.Unpriced > 0 {
+		fmt.Fprintf(&sb, "Not at market: %d manual price, %d at cost\n", s.ManualPriced, s.Unpriced)
+	}
+	fmt.Fprintf(&sb, "Cash: $%s\n", b.locale.FormatFixed(s.Cash, 2))
+	fmt.Fprintf(&sb, "P/L: %s (%s%%)\n", signedDollars(b.locale, s.PL.InexactFloat64()), b.locale.FormatFixed(s.PLPct, 1))
+	fmt.Fprintf(&sb, "Active options: %d\n", s.ActiveOptions)
+	fmt.Fprintf(&sb, "%s net premium: $%s", s.PremiumYear, b.locale.FormatFixed(s.Premiums.NetPL, 2))
+	return sb.String()
+}
+
+func (b *telegramBot) optionsText(ctx context.Context) string {
+	options, err := b.query.ActiveOptions(ctx)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if len(options) == 0 {
+		return "No active options."
+	}
+	var sb strings.Builder
+	for _, o := range options {
+		fmt.Fprintf(&sb, "%s %s %s $%s x%d exp %s\n", o.Ticker, o.Action, o.OptionType,
+			b.locale.FormatFixed(o.Strike, 2), o.Quantity, b.locale.FormatMonthDay(o.ExpiryDate))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func (b *telegramBot) cspText(ctx context.Context) string {
+	results, err := b.query.ScanCSP(ctx)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if len(results) == 0 {
+		return "No CSP results. Add tickers to the watchlist in the TUI."
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score.CompositeScore > results[j].Score.CompositeScore
+	})
+	var sb strings.Builder
+	for _, r := range results {
+		fmt.Fprintf(&sb, "%s %.0f %s — $%s put, %dd, yield %s%%\n", r.Ticker,
+			r.Score.CompositeScore, r.Score.Signal, b.locale.FormatFloat(r.Strike, 2), r.DTE, b.locale.FormatFloat(r.Score.RawPremiumYield, 1))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
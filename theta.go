@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"anyhowhodl/internal/csp"
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/yahoo"
+)
+
+// optionIVMaxAge is how long a fetched implied volatility is used before the
+// contract's chain is fetched again
+const optionIVMaxAge = time.Hour
+
+// optionIV is the implied volatility of one open contract
+type optionIV struct {
+	iv      float64
+	fetched time.Time
+}
+
+// chainContract is what the chains fetched for open contracts say of each
+type chainContract struct {
+	iv           float64
+	openInterest int
+}
+
+// ivRequest is one chain to fetch: an underlying and a Yahoo expiry timestamp
+type ivRequest struct {
+	ticker string
+	expiry int64
+}
+
+// contractKey identifies a listed contract, shared by every position in it
+func contractKey(ticker, optionType string, expiry time.Time, strike float64) string {
+	return fmt.Sprintf("%s|%s|%s|%g", ticker, optionType, expiry.Format("2006-01-02"), strike)
+}
+
+// yahooExpiry is the timestamp Yahoo lists an expiration date under: midnight UTC
+func yahooExpiry(date time.Time) int64 {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).Unix()
+}
+
+// isShortOption reports whether o is an open written contract
+func isShortOption(o db.Option) bool {
+	return o.Status == "ACTIVE" && o.Action == "SELL"
+}
+
+// ivRequests lists the chains needed for short options whose implied
+// volatility is missing or stale, one request per underlying and expiry
+func (a *App) ivRequests() []ivRequest {
+	now := a.now()
+	seen := make(map[ivRequest]bool)
+	var reqs []ivRequest
+	for _, o := range a.book.options {
+		if !isShortOption(o) {
+			continue
+		}
+		key := contractKey(o.Ticker, o.OptionType, o.ExpiryDate, o.Strike.InexactFloat64())
+		if cached, ok := a.book.optionIVs[key]; ok && now.Sub(cached.fetched) < optionIVMaxAge {
+			continue
+		}
+		req := ivRequest{ticker: o.Ticker, expiry: yahooExpiry(o.ExpiryDate)}
+		if !seen[req] {
+			seen[req] = true
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs
+}
+
+// fetchOptionIVs fetches each requested chain and returns the implied
+// volatility and open interest of every contract in it by contractKey.
+// Chains that fail to load are skipped.
+func fetchOptionIVs(market yahoo.Provider, reqs []ivRequest) map[string]chainContract {
+	ivs := make(map[string]chainContract)
+	for _, req := range reqs {
+		chain, err := market.FetchOptionsChainForExpiry(req.ticker, req.expiry)
+		if err != nil {
+			slog.Debug("fetching chain for theta", "ticker", req.ticker, "err", err)
+			continue
+		}
+		expiry := time.Unix(req.expiry, 0).UTC()
+		for _, c := range chain.Puts {
+			ivs[contractKey(req.ticker, "PUT", expiry, c.Strike)] = chainContract{iv: c.ImpliedVolatility, openInterest: c.OpenInterest}
+		}
+		for _, c := range chain.Calls {
+			ivs[contractKey(req.ticker, "CALL", expiry, c.Strike)] = chainContract{iv: c.ImpliedVolatility, openInterest: c.OpenInterest}
+		}
+	}
+	return ivs
+}
+
+// refreshOptionIVs loads missing implied volatilities in the background,
+// tracking the held contracts' open interest from the same chains, and
+// redraws the premium stats with the new theta once they arrive
+func (a *App) refreshOptionIVs() {
+	reqs := a.ivRequests()
+	if len(reqs) == 0 {
+		return
+	}
+	market, q, held := a.yahoo, a.query, a.shortContracts()
+	a.goSafe("option iv", func() {
+		ivs := fetchOptionIVs(market, reqs)
+		oi, err := trackShortOpenInterest(q, held, ivs)
+		if err != nil {
+			slog.Debug("tracking option open interest", "err", err)
+		}
+		a.queueUpdateDraw(func() {
+			a.storeOptionIVs(ivs)
+			a.storeOptionOI(oi)
+			a.optionsView.update()
+			a.optionsView.updateTimeline()
+		})
+	})
+}
+
+// storeOptionIVs caches fetched implied volatilities
+func (a *App) storeOptionIVs(ivs map[string]chainContract) {
+	if a.book.optionIVs == nil {
+		a.book.optionIVs = make(map[string]optionIV)
+	}
+	now := a.now()
+	for key, c := range ivs {
+		a.book.optionIVs[key] = optionIV{iv: c.iv, fetched: now}
+	}
+}
+
+// dailyThetaIncome estimates what the short options earn per day from time
+// decay alone. priced counts the positions with a quote and implied
+// volatility; shorts counts all open short positions. Days to expiry count
+// from now.
+func (b *book) dailyThetaIncome(now time.Time) (income float64, priced, shorts int) {
+	today := now.Truncate(24 * time.Hour)
+	for _, o := range b.options {
+		if !isShortOption(o) {
+			continue
+		}
+		shorts++
+
+		q, ok := b.quotes[o.Ticker]
+		if !ok || q.Price <= 0 {
+			continue
+		}
+		strike := o.Strike.InexactFloat64()
+		cached, ok := b.optionIVs[contractKey(o.Ticker, o.OptionType, o.ExpiryDate, strike)]
+		if !ok || cached.iv <= 0 {
+			continue
+		}
+
+		// A contract expiring today still has the rest of the day to decay
+		dte := max(int(o.ExpiryDate.Sub(today).Hours()/24), 1)
+		theta := csp.CalculateTheta(o.OptionType, q.Price, strike, cached.iv, dte)
+		income -= theta * o.Shares().InexactFloat64()
+		priced++
+	}
+	return income, priced, shorts
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// maxTickerSuggestions caps the ticker autocomplete list
+const maxTickerSuggestions = 8
+
+// recentTickers returns the tickers of the holdings, options, and CSP
+// watchlist, most recently added or changed first
+func (a *App) recentTickers() []string {
+	latest := make(map[string]time.Time)
+	touch := func(ticker string, t time.Time) {
+		if seen, ok := latest[ticker]; !ok || t.After(seen) {
+			latest[ticker] = t
+		}
+	}
+	for _, h := range a.book.holdings {
+		touch(h.Ticker, h.UpdatedAt)
+	}
+	for _, o := range a.book.options {
+		touch(o.Ticker, o.UpdatedAt)
+	}
+	watchlist, err := a.db.GetCSPWatchlist(context.Background())
+	if err != nil {
+		slog.Warn("loading watchlist for ticker suggestions", "err", err)
+	}
+	for _, w := range watchlist {
+		touch(w.Ticker, w.UpdatedAt)
+	}
+
+	tickers := make([]string, 0, len(latest))
+	for ticker := range latest {
+		tickers = append(tickers, ticker)
+	}
+	sort.Slice(tickers, func(i, j int) bool {
+		ti, tj := latest[tickers[i]], latest[tickers[j]]
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return tickers[i] < tickers[j]
+	})
+	return tickers
+}
+
+// suggestTickers returns the known tickers starting with typed, in the order
+// given. Nothing is suggested once typed is the only match.
+func suggestTickers(known []string, typed string) []string {
+	typed = strings.ToUpper(strings.TrimSpace(typed))
+	if typed == "" {
+		return nil
+	}
+	var matches []string
+	for _, ticker := range known {
+		if strings.HasPrefix(ticker, typed) {
+			matches = append(matches, ticker)
+			if len(matches) == maxTickerSuggestions {
+				break
+			}
+		}
+	}
+	if len(matches) == 1 && matches[0] == typed {
+		return nil
+	}
+	return matches
+}
+
+// tickerAutocomplete suggests known tickers as input is typed; Enter or Tab
+// takes the highlighted one
+func (a *App) tickerAutocomplete(input *tview.InputField) {
+	known := a.recentTickers()
+	input.SetAutocompleteFunc(func(text string) []string {
+		return suggestTickers(known, text)
+	})
+	input.SetAutocompleteStyles(tcell.ColorDarkSlateGray,
+		tcell.StyleDefault.Background(tcell.ColorDarkSlateGray).Foreground(tcell.ColorWhite),
+		tcell.StyleDefault.Background(tcell.ColorTeal).Foreground(tcell.ColorBlack))
+}
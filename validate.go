@@ -0,0 +1,190 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// fieldCheck validates an input's text, returning what is wrong with it or ""
+type fieldCheck func(text string) string
+
+// formChecks validates a form's inputs as they are typed. An invalid input's
+// label turns red and the hint line under the fields says what is wrong with
+// the first one. Inputs are only checked once edited, or on Save.
+type formChecks struct {
+	hint   *tview.TextView
+	fields []checkedField
+}
+
+type checkedField struct {
+	input   *tview.InputField
+	label   string
+	check   fieldCheck
+	touched bool
+}
+
+// newFormChecks adds the hint under the form's items, so their indexes are
+// unchanged; the form needs three more rows.
+func newFormChecks(form *tview.Form) *formChecks {
+	form.AddTextView("", "", 0, 2, true, false)
+	return &formChecks{hint: form.GetFormItem(form.GetFormItemCount() - 1).(*tview.TextView)}
+}
+
+// add checks the input at index of form as it changes. changed, if not nil,
+// runs first, as the input's own changed func would.
+func (f *formChecks) add(form *tview.Form, index int, check fieldCheck, changed func(text string)) {
+	input := form.GetFormItem(index).(*tview.InputField)
+	f.fields = append(f.fields, checkedField{input: input, label: input.GetLabel(), check: check})
+	i := len(f.fields) - 1
+	input.SetChangedFunc(func(text string) {
+		if changed != nil {
+			changed(text)
+		}
+		f.fields[i].touched = true
+		f.update()
+	})
+}
+
+// valid checks every input, edited or not, and reports whether all pass
+func (f *formChecks) valid() bool {
+	for i := range f.fields {
+		f.fields[i].touched = true
+	}
+	return f.update()
+}
+
+func (f *formChecks) update() bool {
+	hint := ""
+	for _, field := range f.fields {
+		problem := ""
+		if field.touched {
+			problem = field.check(strings.TrimSpace(field.input.GetText()))
+		}
+		if problem == "" {
+			field.input.SetLabel(field.label)
+			continue
+		}
+		field.input.SetLabel("[red]" + field.label)
+		if hint == "" {
+			hint = strings.TrimSpace(strings.SplitN(field.label, "(", 2)[0]) + ": " + problem
+		}
+	}
+	if hint != "" {
+		f.hint.SetText("[red]" + tview.Escape(hint))
+	} else {
+		f.hint.SetText("")
+	}
+	return hint == ""
+}
+
+// requiredCheck wraps check to reject empty text; check sees only non-empty
+// text. A nil check accepts anything non-empty.
+func requiredCheck(check fieldCheck) fieldCheck {
+	return func(text string) string {
+		if text == "" {
+			return "required"
+		}
+		if check == nil {
+			return ""
+		}
+		return check(text)
+	}
+}
+
+// optionalCheck wraps check to accept empty text
+func optionalCheck(check fieldCheck) fieldCheck {
+	return func(text string) string {
+		if text == "" {
+			return ""
+		}
+		return check(text)
+	}
+}
+
+// numberCheck accepts numbers in the locale's format that are greater than
+// zero, or with allowZero at least zero
+func (a *App) numberCheck(allowZero bool) fieldCheck {
+	return func(text string) string {
+		n, err := a.book.locale.ParseNumber(text)
+		switch {
+		case err != nil:
+			return "not a number"
+		case allowZero && n.IsNegative():
+			return "must not be negative"
+		case !allowZero && !n.IsPositive():
+			return "must be greater than 0"
+		}
+		return ""
+	}
+}
+
+// sharesCheck accepts a share quantity greater than zero with no more
+// decimal places than shares are shown with
+func (a *App) sharesCheck() fieldCheck {
+	number := a.numberCheck(false)
+	return func(text string) string {
+		if problem := number(text); problem != "" {
+			return problem
+		}
+		n, _ := a.book.locale.ParseNumber(text)
+		switch places := a.book.precision.Shares; {
+		case n.Equal(n.Round(places)):
+			return ""
+		case places == 0:
+			return "must be whole shares"
+		default:
+			return "at most " + strconv.Itoa(int(places)) + " decimal places"
+		}
+	}
+}
+
+// contractsCheck accepts a whole number of contracts, at least one
+func contractsCheck(text string) string {
+	n, err := strconv.Atoi(text)
+	if err != nil || n < 1 {
+		return "must be a whole number of at least 1"
+	}
+	return ""
+}
+
+// dateBound limits a date relative to today, returning what is wrong or ""
+type dateBound func(d, today time.Time) string
+
+// notPast accepts today or later, as for an expiry
+func notPast(d, today time.Time) string {
+	if d.Before(today) {
+		return "must not be in the past"
+	}
+	return ""
+}
+
+// notFuture accepts today or earlier, as for an entry date
+func notFuture(d, today time.Time) string {
+	if d.After(today) {
+		return "must not be in the future"
+	}
+	return ""
+}
+
+// dateCheck accepts a sensible date in the locale's format that meets bounds
+func (a *App) dateCheck(bounds ...dateBound) fieldCheck {
+	return func(text string) string {
+		d, err := a.parseDate(text)
+		if err != nil {
+			return "use " + a.book.locale.DateHint + " or a shortcut like +30d, fri, 3rd fri"
+		}
+		if d.Year() < 1970 || d.Year() > 2100 {
+			return "year out of range"
+		}
+		today := a.today()
+		for _, bound := range bounds {
+			if problem := bound(d, today); problem != "" {
+				return problem
+			}
+		}
+		return ""
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/yahoo"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+// showWatchlistView lists the stock watchlist with live quotes, fetched in
+// the background
+func (a *App) showWatchlistView() {
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	table.SetBorder(true).
+		SetTitle(" Watchlist ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorTeal)
+
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(" [yellow]a[white]:Add  [yellow]e/Enter[white]:Edit  [yellow]d[white]:Remove  [yellow]h[white]:Convert to holding  [gray]ESC to close")
+
+	a.loadWatchlist(context.Background())
+	a.fillWatchlistTable(table)
+	a.fetchWatchQuotes(table)
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		row, _ := table.GetSelection()
+		var selected *db.WatchItem
+		if row >= 1 && row <= len(a.watchlist) {
+			selected = &a.watchlist[row-1]
+		}
+		switch {
+		case event.Rune() == 'a':
+			if !a.readOnly() {
+				a.showWatchForm(table, nil)
+			}
+			return nil
+		case event.Rune() == 'e' || event.Key() == tcell.KeyEnter:
+			if !a.readOnly() && selected != nil {
+				a.showWatchForm(table, selected)
+			}
+			return nil
+		case event.Rune() == 'd':
+			if !a.readOnly() && selected != nil {
+				a.confirmRemoveWatch(table, selected.Ticker)
+			}
+			return nil
+		case event.Rune() == 'h':
+			if !a.readOnly() && selected != nil {
+				a.convertWatchToHolding(*selected)
+			}
+			return nil
+		}
+		return event
+	})
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(help, 1, 0, false)
+	a.pages.AddPage("watchlist", flex, true, true)
+}
+
+// loadWatchlist reads the stock watchlist
+func (a *App) loadWatchlist(ctx context.Context) {
+	items, err := a.db.GetWatchlist(ctx)
+	if err != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error loading watchlist: %v", err))
+		return
+	}
+	a.watchlist = items
+}
+
+// fetchWatchQuotes quotes the watchlist's tickers in the background and
+// redraws table with them
+func (a *App) fetchWatchQuotes(table *tview.Table) {
+	tickers := make([]string, len(a.watchlist))
+	for i, w := range a.watchlist {
+		tickers[i] = w.Ticker
+	}
+	if len(tickers) == 0 {
+		return
+	}
+	a.goSafe("watchlist quotes", func() {
+		quotes, _ := a.yahoo.GetQuotes(tickers)
+		a.queueUpdateDraw(func() {
+			a.setWatchQuotes(quotes)
+			a.fillWatchlistTable(table)
+		})
+	})
+}
+
+// setWatchQuotes keeps the watchlist quotes fetched, adding to those
+// already known
+func (a *App) setWatchQuotes(quotes map[string]yahoo.Quote) {
+	if a.watchQuotes == nil {
+		a.watchQuotes = make(map[string]yahoo.Quote)
+	}
+	for ticker, q := range quotes {
+		a.watchQuotes[ticker] = q
+	}
+}
+
+// watchQuote returns ticker's quote from the watchlist's or the portfolio's
+// quotes
+func (a *App) watchQuote(ticker string) (yahoo.Quote, bool) {
+	if q, ok := a.watchQuotes[ticker]; ok && q.Price > 0 {
+		return q, true
+	}
+	q, ok := a.book.quotes[ticker]
+	return q, ok && q.Price > 0
+}
+
+// fillWatchlistTable lists the watchlist by ticker with each ticker's price
+// against its target buy price and its 52-week high
+func (a *App) fillWatchlistTable(table *tview.Table) {
+	row, _ := table.GetSelection()
+	table.Clear()
+	for i, h := range []string{"TICKER", "PRICE", "TARGET", "TO TARGET", "vs HIGH", "vs LOW", "NOTES"} {
+		table.SetCell(0, i, tview.NewTableCell(" "+h+" ").
+			SetTextColor(tcell.ColorBlack).
+			SetBackgroundColor(tcell.ColorTeal).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+	if len(a.watchlist) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell(" No tickers on the watchlist. Press a to add one.").
+			SetTextColor(tcell.ColorGray).
+			SetSelectable(false))
+		return
+	}
+
+	for i, w := range a.watchlist {
+		r := i + 1
+		cells := []string{"-", "-", "-", "-", "-", "-", w.Notes}
+		colors := []tcell.Color{tcell.ColorFuchsia, tcell.ColorWhite, tcell.ColorWhite, tcell.ColorWhite, tcell.ColorWhite, tcell.ColorWhite, tcell.ColorGray}
+		cells[0] = w.Ticker
+		if w.TargetPrice.Valid {
+			cells[2] = "$" + a.book.formatPrice(w.TargetPrice.Decimal)
+		}
+		if q, ok := a.watchQuote(w.Ticker); ok {
+			price := decimal.NewFromFloat(q.Price)
+			cells[1] = "$" + a.book.formatPrice(price)
+			if w.TargetPrice.Valid && w.TargetPrice.Decimal.IsPositive() {
+				// How far the price must fall to the target; at or below it is a buy
+				pct := price.Sub(w.TargetPrice.Decimal).Div(w.TargetPrice.Decimal).Mul(decimal.NewFromInt(100))
+				cells[3] = "+" + a.book.locale.FormatFixed(pct, 1) + "%"
+				switch {
+				case !price.GreaterThan(w.TargetPrice.Decimal):
+					cells[3], colors[3] = "BUY", tcell.ColorLime
+				case pct.LessThan(decimal.NewFromInt(5)):
+					colors[3] = tcell.ColorYellow // Within 5% of the target
+				}
+			}
+			if q.FiftyTwoWeekHigh > 0 {
+				cells[4] = fmt.Sprintf("%s%% ($%s)", a.book.locale.FormatFloat(q.PctFromHigh, 1), a.book.formatPrice(decimal.NewFromFloat(q.FiftyTwoWeekHigh)))
+				if q.PctFromHigh <= -20 {
+					colors[4] = tcell.ColorLime
+				} else if q.PctFromHigh <= -10 {
+					colors[4] = tcell.ColorYellow
+				}
+			}
+			if q.FiftyTwoWeekLow > 0 {
+				cells[5] = fmt.Sprintf("+%s%% ($%s)", a.book.locale.FormatFloat(q.PctFromLow, 1), a.book.formatPrice(decimal.NewFromFloat(q.FiftyTwoWeekLow)))
+				if nearYearLow(q) {
+					colors[5] = tcell.ColorLime
+				} else if q.PctFromLow <= 2*nearLowPct {
+					colors[5] = tcell.ColorYellow
+				}
+			}
+		}
+		for c, text := range cells {
+			table.SetCell(r, c, tview.NewTableCell(" "+tview.Escape(text)+" ").
+				SetTextColor(colors[c]).
+				SetExpansion(1))
+		}
+	}
+	table.Select(max(1, min(row, len(a.watchlist))), 0)
+}
+
+// showWatchForm adds a ticker to the watchlist, or edits the target price
+// and notes of item
+func (a *App) showWatchForm(table *tview.Table, item *db.WatchItem) {
+	ticker, target, notes, title := "", "", "", " Add to Watchlist "
+	if item != nil {
+		ticker, notes, title = item.Ticker, item.Notes, " Edit "+item.Ticker+" "
+		if item.TargetPrice.Valid {
+			target = a.book.locale.EditNumber(item.TargetPrice.Decimal.String())
+		}
+	}
+	form := tview.NewForm().
+		AddInputField("Ticker", ticker, 10, nil, nil).
+		AddInputField("Target buy price ($)", target, 15, nil, nil).
+		AddInputField("Notes", notes, 40, nil, nil)
+	tickerField := form.GetFormItem(0).(*tview.InputField)
+	if item != nil {
+		tickerField.SetDisabled(true)
+	} else {
+		a.tickerAutocomplete(tickerField)
+	}
+
+	styleForm(form)
+
+	form.AddButton("Save", func() {
+		ticker := strings.ToUpper(strings.TrimSpace(tickerField.GetText()))
+		if ticker == "" {
+			a.statusBar.SetText(" [red]Ticker is required")
+			return
+		}
+		var targetPrice decimal.NullDecimal
+		if text := strings.TrimSpace(form.GetFormItem(1).(*tview.InputField).GetText()); text != "" {
+			tp, err := a.book.locale.ParseNumber(text)
+			if err != nil || !tp.IsPositive() {
+				a.statusBar.SetText(" [red]Invalid target price")
+				return
+			}
+			targetPrice = decimal.NullDecimal{Decimal: tp, Valid: true}
+		}
+		notes := strings.TrimSpace(form.GetFormItem(2).(*tview.InputField).GetText())
+
+		ctx := context.Background()
+		if err := a.db.SetWatchTicker(ctx, ticker, targetPrice, notes); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+			return
+		}
+		a.pages.RemovePage("watchform")
+		a.loadWatchlist(ctx)
+		a.fillWatchlistTable(table)
+		if _, ok := a.watchQuote(ticker); !ok {
+			a.fetchWatchQuotes(table)
+		}
+	})
+
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("watchform")
+	})
+
+	form.SetBorder(true).SetTitle(title).SetTitleAlign(tview.AlignLeft)
+
+	a.createModalPage("watchform", form, 60, 11)
+}
+
+// confirmRemoveWatch takes ticker off the watchlist once confirmed
+func (a *App) confirmRemoveWatch(table *tview.Table, ticker string) {
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Remove %s from the watchlist?", ticker)).
+		AddButtons([]string{"Remove", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("confirm_remove_watch")
+			if buttonLabel != "Remove" {
+				return
+			}
+			ctx := context.Background()
+			if err := a.db.RemoveWatchTicker(ctx, ticker); err != nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Error: %v", err))
+				return
+			}
+			a.loadWatchlist(ctx)
+			a.fillWatchlistTable(table)
+		})
+	a.pages.AddPage("confirm_remove_watch", modal, true, true)
+}
+
+// convertWatchToHolding opens the new holding form for a watched ticker at
+// its current price, or its target without a quote, and takes the ticker off
+// the watchlist once the holding is saved
+func (a *App) convertWatchToHolding(item db.WatchItem) {
+	prefill := db.Holding{Ticker: item.Ticker, Notes: item.Notes}
+	if q, ok := a.watchQuote(item.Ticker); ok {
+		prefill.AvgCost = decimal.NewFromFloat(q.Price)
+	} else if item.TargetPrice.Valid {
+		prefill.AvgCost = item.TargetPrice.Decimal
+	}
+	a.showAddForm(&prefill, func() {
+		if err := a.db.RemoveWatchTicker(context.Background(), item.Ticker); err != nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error removing %s from the watchlist: %v", item.Ticker, err))
+		}
+		a.pages.RemovePage("watchlist")
+	})
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"anyhowhodl/internal/db"
+	"anyhowhodl/internal/notify"
+	"anyhowhodl/internal/webhook"
+
+	"github.com/shopspring/decimal"
+)
+
+// defaultWebhookAddr is used when WEBHOOK_ADDR is unset
+const defaultWebhookAddr = ":8080"
+
+// enableWebhooks configures the webhook server from WEBHOOK_ADDR and WEBHOOK_SECRET
+func (d *Daemon) enableWebhooks() error {
+	secret := os.Getenv("WEBHOOK_SECRET")
+	if secret == "" {
+		return errors.New("WEBHOOK_SECRET must be set for serve mode")
+	}
+	addr := os.Getenv("WEBHOOK_ADDR")
+	if addr == "" {
+		addr = defaultWebhookAddr
+	}
+
+	d.webhook = &http.Server{
+		Addr: addr,
+		Handler: &webhook.Handler{
+			Secret:  secret,
+			OnTrade: d.recordWebhookTrade,
+			OnAlert: d.forwardWebhookAlert,
+		},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return nil
+}
+
+// recordWebhookTrade records a fill the same way the TUI forms do, including
+// cash adjustments. A fill with the broker's fill ID is recorded once: a
+// retried POST for it returns webhook.ErrDuplicateFill.
+func (d *Daemon) recordWebhookTrade(ctx context.Context, t webhook.Trade) (err error) {
+	if t.FillID != "" {
+		claimed, claimErr := d.db.ClaimFill(ctx, t.FillID)
+		if claimErr != nil {
+			return claimErr
+		}
+		if !claimed {
+			log.Printf("webhook: fill %s already recorded", t.FillID)
+			return webhook.ErrDuplicateFill
+		}
+		defer func() {
+			if err != nil {
+				// Let the broker's retry record it
+				if releaseErr := d.db.ReleaseFill(context.WithoutCancel(ctx), t.FillID); releaseErr != nil {
+					log.Printf("webhook: releasing fill %s: %v", t.FillID, releaseErr)
+				}
+			}
+		}()
+	}
+
+	notes := t.Notes
+	if notes == "" {
+		notes = "Recorded via webhook"
+	}
+
+	currency, err := d.tradeCurrency(ctx, t)
+	if err != nil {
+		return err
+	}
+	rate, err := ensureFXRate(ctx, d.db, d.yahoo, currency)
+	if err != nil {
+		return fmt.Errorf("exchange rate: %w", err)
+	}
+
+	switch t.Kind {
+	case "OPTION":
+		expiry, _ := t.ExpiryDate()
+		open, err := d.openPosition(ctx, t, expiry)
+		if err != nil {
+			return err
+		}
+		if open != nil {
+			// Buying back a short or selling a long closes it
+			if int64(open.Quantity) != t.Quantity.IntPart() {
+				return fmt.Errorf("fill is %s of the %d contracts open; record partial closes in the app", t.Quantity, open.Quantity)
+			}
+			if err := d.db.CloseOption(ctx, open.ID, t.Price, t.Fee); err != nil {
+				return err
+			}
+			break
+		}
+		if err := d.db.AddOption(ctx, t.Ticker, t.OptionType, t.Action, t.Strike, expiry,
+			int(t.Quantity.IntPart()), db.DefaultMultiplier, db.SettlementPhysical, currency, t.Price, t.Fee, notes); err != nil {
+			return err
+		}
+	case "STOCK":
+		if t.Action == "SELL" {
+			return errors.New("stock sells are not supported; record them in the app")
+		}
+		if err := d.db.AddHolding(ctx, t.Ticker, t.Quantity, t.Price, currency, time.Now(), decimal.NullDecimal{}, notes); err != nil {
+			return err
+		}
+		if t.Fee.IsPositive() {
+			// The commission is in the trade's currency too
+			if err := d.db.AdjustCash(ctx, db.TxFee, t.Ticker, t.Fee.Mul(rate).Neg(), notes); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Printf("webhook: recorded %s %s %s %s @ %s%s", t.Kind, t.Action, t.Quantity, t.Ticker, currencySymbol(currency), t.Price.StringFixed(2))
+	return nil
+}
+
+// tradeCurrency is the currency a fill is in: as sent, or else that of the
+// ticker's holding, or DefaultCurrency for a ticker not held
+func (d *Daemon) tradeCurrency(ctx context.Context, t webhook.Trade) (string, error) {
+	if t.Currency != "" {
+		return t.Currency, nil
+	}
+	h, err := d.db.GetHoldingByTicker(ctx, t.Ticker)
+	if err != nil || h == nil || h.Currency == "" {
+		return db.DefaultCurrency, err
+	}
+	return h.Currency, nil
+}
+
+// openPosition finds the active option a fill closes: the same contract on the
+// other side, held at this broker. Returns nil if the fill opens a position.
+func (d *Daemon) openPosition(ctx context.Context, t webhook.Trade, expiry time.Time) (*db.Option, error) {
+	options, err := d.db.GetActiveOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var match *db.Option
+	for i, o := range options {
+		if o.Status != "ACTIVE" || o.External || o.Action == t.Action || o.Ticker != t.Ticker ||
+			o.OptionType != t.OptionType || !o.Strike.Equal(t.Strike) || !o.ExpiryDate.Equal(expiry) {
+			continue
+		}
+		// Prefer the position the fill closes in full
+		if match == nil || int64(o.Quantity) == t.Quantity.IntPart() {
+			match = &options[i]
+		}
+	}
+	return match, nil
+}
+
+// forwardWebhookAlert logs an inbound alert and sends it to the notification
+// channels
+func (d *Daemon) forwardWebhookAlert(ctx context.Context, a webhook.Alert) error {
+	msg := a.Message
+	if a.Ticker != "" {
+		msg = fmt.Sprintf("%s: %s", a.Ticker, a.Message)
+	}
+	log.Printf("webhook: alert: %s", msg)
+
+	return d.notifier.Notify(ctx, notify.Event{Kind: notify.KindInbound, Key: "inbound:" + msg, Text: "🔔 " + msg})
+}